@@ -0,0 +1,3 @@
+module github.com/RajaSunrise/toko/clients/go
+
+go 1.21
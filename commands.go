@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"toko/internal/backup"
+	"toko/internal/demo"
+	"toko/internal/doctor"
+	"toko/internal/events"
+	"toko/internal/importer"
+	"toko/internal/jobs"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/internal/worker"
+	"toko/pkg/mailer"
+	"toko/pkg/rabbitmq"
+	"toko/pkg/sdkgen"
+	"toko/pkg/storage"
+)
+
+// runCommand runs a command-line subcommand and returns the process exit code,
+// or -1 when the server should start.
+func runCommand(args []string) int {
+	switch args[0] {
+	case "serve":
+		return -1
+	case "import":
+		return runImport(args[1:])
+	case "backup":
+		return runBackup(args[1:])
+	case "restore":
+		return runRestore(args[1:])
+	case "worker":
+		return runWorker(args[1:])
+	case "demo":
+		return runDemo(args[1:])
+	case "doctor":
+		return runDoctor(args[1:])
+	case "sdk":
+		return runSDK(args[1:])
+	case "help", "-h", "--help":
+		printUsage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		printUsage()
+		return 2
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: toko [command]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  serve    Start the HTTP server (default)")
+	fmt.Fprintln(os.Stderr, "  import   Import products, customers and orders from another platform")
+	fmt.Fprintln(os.Stderr, "  backup   Write the store's data and product files to an archive in storage")
+	fmt.Fprintln(os.Stderr, "  restore  Load a backup archive from storage")
+	fmt.Fprintln(os.Stderr, "  worker   Consume order events from RabbitMQ by priority tier")
+	fmt.Fprintln(os.Stderr, "  demo     Generate a randomized demo catalog and order history")
+	fmt.Fprintln(os.Stderr, "  doctor   Check the configuration and every service the store depends on")
+	fmt.Fprintln(os.Stderr, "  sdk      Generate the Go and TypeScript API clients from the OpenAPI spec")
+}
+
+// runImport implements "toko import --source shopify --file export.zip".
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	source := fs.String("source", "", "platform the export comes from ("+strings.Join(importer.SupportedSources(), ", ")+")")
+	file := fs.String("file", "", "export file: a CSV or a zip archive of CSVs")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *source == "" || *file == "" {
+		fmt.Fprintln(os.Stderr, "import: --source and --file are required")
+		fs.Usage()
+		return 2
+	}
+
+	db, _, ok := openStore("import")
+	if !ok {
+		return 1
+	}
+
+	imp := importer.NewImporter(
+		repositories.NewGORMProductRepository(db),
+		repositories.NewGORMUserRepository(db),
+		repositories.NewGORMOrderRepository(db),
+	)
+	result, err := imp.ImportFile(*source, *file)
+	if result != nil {
+		fmt.Printf("Products:  %d created, %d skipped\n", result.ProductsCreated, result.ProductsSkipped)
+		fmt.Printf("Customers: %d created, %d skipped\n", result.CustomersCreated, result.CustomersSkipped)
+		fmt.Printf("Orders:    %d created, %d skipped\n", result.OrdersCreated, result.OrdersSkipped)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runDemo implements "toko demo --products 10000 --orders 50000".
+func runDemo(args []string) int {
+	fs := flag.NewFlagSet("demo", flag.ContinueOnError)
+	products := fs.Int("products", 1000, "number of products to generate")
+	orders := fs.Int("orders", 5000, "number of orders to generate")
+	customers := fs.Int("customers", 0, "number of customers to generate (default one per ten orders)")
+	history := fs.Duration("history", 365*24*time.Hour, "period the orders are spread over")
+	seed := fs.Int64("seed", 1, "random seed; the same seed generates the same data")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	db, _, ok := openStore("demo")
+	if !ok {
+		return 1
+	}
+	if viper.GetString("APP_ENV") == "production" {
+		fmt.Fprintln(os.Stderr, "demo: refusing to generate demo data in the production profile")
+		return 1
+	}
+
+	started := time.Now()
+	result, err := demo.Generate(db, demo.Options{
+		Products:  *products,
+		Customers: *customers,
+		Orders:    *orders,
+		History:   *history,
+		Seed:      *seed,
+	})
+	if result != nil {
+		fmt.Printf("Products:  %d created\n", result.Products)
+		fmt.Printf("Customers: %d created\n", result.Customers)
+		fmt.Printf("Orders:    %d created\n", result.Orders)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "demo: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Generated in %s\n", time.Since(started).Round(time.Millisecond))
+	return 0
+}
+
+// openStore loads the configuration and opens the database and file storage.
+func openStore(command string) (*gorm.DB, storage.Storage, bool) {
+	if err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", command, err)
+		return nil, nil, false
+	}
+	db, err := openDatabase(viper.GetString("DATABASE_DSN"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", command, err)
+		return nil, nil, false
+	}
+	fileStorage, err := storage.NewLocalStorage(viper.GetString("STORAGE_DIR"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", command, err)
+		return nil, nil, false
+	}
+	return db, fileStorage, true
+}
+
+// runBackup implements "toko backup [--key backups/name.tar.gz]".
+// The store is single-tenant, so an archive always covers all of its data.
+func runBackup(args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	key := fs.String("key", "", "storage key of the archive (default backups/toko-<timestamp>.tar.gz)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *key == "" {
+		*key = fmt.Sprintf("backups/toko-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	db, fileStorage, ok := openStore("backup")
+	if !ok {
+		return 1
+	}
+	manifest, err := backup.Backup(db, fileStorage, *key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup: %v\n", err)
+		return 1
+	}
+	for table, rows := range manifest.Tables {
+		fmt.Printf("%-20s %d rows\n", table, rows)
+	}
+	fmt.Printf("%-20s %d files\n", "product files", len(manifest.Files))
+	fmt.Printf("Backup written to %s\n", *key)
+	return 0
+}
+
+// runRestore implements "toko restore --key backups/name.tar.gz [--force]".
+func runRestore(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	key := fs.String("key", "", "storage key of the archive to restore")
+	force := fs.Bool("force", false, "delete existing data before restoring")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *key == "" {
+		fmt.Fprintln(os.Stderr, "restore: --key is required")
+		fs.Usage()
+		return 2
+	}
+
+	db, fileStorage, ok := openStore("restore")
+	if !ok {
+		return 1
+	}
+	manifest, err := backup.Restore(db, fileStorage, *key, backup.RestoreOptions{Replace: *force})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "restore: %v\n", err)
+		if strings.Contains(err.Error(), "not empty") {
+			fmt.Fprintln(os.Stderr, "restore: use --force to replace the existing data")
+		}
+		return 1
+	}
+	fmt.Printf("Restored backup from %s (created %s)\n", *key, manifest.CreatedAt.Format(time.RFC3339))
+	return 0
+}
+
+// runWorker implements "toko worker": it consumes order events with weighted
+// priority tiers until interrupted.
+func runWorker(args []string) int {
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	db, fileStorage, ok := openStore("worker")
+	if !ok {
+		return 1
+	}
+	mqClient, err := rabbitmq.NewClient(rabbitmq.Config{URL: viper.GetString("RABBITMQ_URL")})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "worker: %v\n", err)
+		return 1
+	}
+	defer mqClient.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Processed message IDs live in Redis when available so that TTLs are handled there
+	var dedupeStore repositories.ProcessedMessageRepository
+	if redisURL := viper.GetString("REDIS_URL"); redisURL != "" {
+		redisOpts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "worker: invalid REDIS_URL: %v\n", err)
+			return 1
+		}
+		dedupeStore = repositories.NewRedisProcessedMessageRepository(redis.NewClient(redisOpts))
+	} else {
+		dedupeStore = repositories.NewGORMProcessedMessageRepository(db)
+	}
+	scheduler := jobs.NewScheduler()
+	scheduler.Every(time.Hour, "processed-message-purge", func(ctx context.Context) error {
+		_, err := dedupeStore.PurgeExpired(time.Now())
+		return err
+	})
+	scheduler.Start(ctx)
+
+	mailSender, err := newMailer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "worker: %v\n", err)
+		return 1
+	}
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	productRepo := repositories.NewGORMProductRepository(db)
+	userRepo := repositories.NewGORMUserRepository(db)
+	storeName := viper.GetString("STORE_NAME")
+	// Emails go through the task queue so SMTP failures are retried with backoff
+	notificationService := services.NewNotificationService(orderRepo, productRepo, userRepo,
+		newOrderStatusPageService(orderRepo, productRepo),
+		services.NewInvoiceService(orderRepo, productRepo, userRepo, fileStorage, storeName),
+		mailSender, newTaskQueue(db), storeName)
+
+	router := worker.NewRouter()
+	router.On("order.created", notificationService.HandleOrderCreated)
+	router.On("order.receipt_requested", notificationService.HandleReceiptRequested)
+	router.On("order.payment_confirmed", notificationService.HandlePaymentConfirmed)
+	router.On("order.cancelled", notificationService.HandleOrderCancelled)
+	handler := worker.LogCorrelation(worker.Idempotent(dedupeStore, worker.IdempotencyConfig{
+		Consumer: "order-worker",
+		Lease:    viper.GetDuration("EVENT_DEDUPE_LEASE"),
+		TTL:      viper.GetDuration("EVENT_DEDUPE_TTL"),
+	}, worker.ValidateSchema(events.DefaultRegistry(), router.Handle)))
+	err = mqClient.ConsumePriority(ctx, rabbitmq.PriorityConfig{
+		Exchange: "order",
+		Tiers:    rabbitmq.DefaultOrderTiers,
+		Retry: rabbitmq.RetryPolicy{
+			MaxAttempts: viper.GetInt("EVENT_RETRY_MAX_ATTEMPTS"),
+			Delay:       viper.GetDuration("EVENT_RETRY_DELAY"),
+		},
+	}, handler)
+	stop()
+	scheduler.Wait()
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "worker: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runDoctor implements "toko doctor": it checks the configuration and
+// connects to every dependency, printing a pass/fail line for each. The
+// exit code is 1 when any check failed.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "time allowed for each check")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var db *gorm.DB
+	checks := []doctor.Check{
+		{Name: "config", Run: func(ctx context.Context) (string, error) {
+			if err := loadConfig(); err != nil {
+				return "", err
+			}
+			if problems := configProblems(); len(problems) > 0 {
+				return "", fmt.Errorf("%s", strings.Join(problems, "; "))
+			}
+			return "profile " + viper.GetString("APP_ENV"), nil
+		}},
+		{Name: "database", Run: func(ctx context.Context) (string, error) {
+			var err error
+			if db, err = connectDatabase(viper.GetString("DATABASE_DSN")); err != nil {
+				return "", err
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				return "", err
+			}
+			if err := sqlDB.PingContext(ctx); err != nil {
+				db = nil
+				return "", fmt.Errorf("failed to reach database: %w", err)
+			}
+			return "connected", nil
+		}},
+		{Name: "migrations", Run: func(ctx context.Context) (string, error) {
+			if db == nil {
+				return "", doctor.Skip("no database connection")
+			}
+			return doctor.Schema(db.WithContext(ctx), schemaModels()...)
+		}},
+		{Name: "rabbitmq", Run: func(ctx context.Context) (string, error) {
+			if viper.GetString("RABBITMQ_URL") == "memory://" {
+				return "", doctor.Skip("RABBITMQ_URL is memory://, events stay in the API process")
+			}
+			client, err := rabbitmq.NewClient(rabbitmq.Config{URL: viper.GetString("RABBITMQ_URL")})
+			if err != nil {
+				return "", err
+			}
+			return "connected", client.Close()
+		}},
+		{Name: "redis", Run: func(ctx context.Context) (string, error) {
+			redisURL := viper.GetString("REDIS_URL")
+			if redisURL == "" {
+				return "", doctor.Skip("REDIS_URL is not set, shared state is kept in memory")
+			}
+			redisOpts, err := redis.ParseURL(redisURL)
+			if err != nil {
+				return "", fmt.Errorf("invalid REDIS_URL: %w", err)
+			}
+			client := redis.NewClient(redisOpts)
+			defer client.Close()
+			if err := client.Ping(ctx).Err(); err != nil {
+				return "", fmt.Errorf("failed to reach redis: %w", err)
+			}
+			return "connected", nil
+		}},
+		{Name: "storage", Run: func(ctx context.Context) (string, error) {
+			fileStorage, err := storage.NewLocalStorage(viper.GetString("STORAGE_DIR"))
+			if err != nil {
+				return "", err
+			}
+			return doctor.Storage(fileStorage)
+		}},
+		{Name: "smtp", Run: func(ctx context.Context) (string, error) {
+			mailSender, err := newMailer()
+			if err != nil {
+				return "", err
+			}
+			smtpMailer, ok := mailSender.(*mailer.SMTPMailer)
+			if !ok {
+				return "", doctor.Skip("MAIL_DRIVER is not smtp, emails are only logged")
+			}
+			if err := smtpMailer.Verify(ctx); err != nil {
+				return "", err
+			}
+			return "authenticated with " + viper.GetString("SMTP_HOST"), nil
+		}},
+	}
+
+	_, ok := doctor.Run(context.Background(), checks, *timeout, os.Stdout)
+	if !ok {
+		fmt.Println("Some checks failed")
+		return 1
+	}
+	fmt.Println("All checks passed")
+	return 0
+}
+
+// configProblems returns settings that would keep the server from starting
+// or that are unsafe in the production profile.
+func configProblems() []string {
+	var problems []string
+	if viper.GetString("APP_ENV") == "production" && viper.GetString("JWT_SECRET") == "supersecretjwtkey" {
+		problems = append(problems, "JWT_SECRET is still the default")
+	}
+	switch viper.GetString("MAIL_DRIVER") {
+	case "smtp":
+		if viper.GetString("SMTP_HOST") == "" || viper.GetString("MAIL_FROM") == "" {
+			problems = append(problems, "MAIL_DRIVER smtp needs SMTP_HOST and MAIL_FROM")
+		}
+	case "log", "":
+	default:
+		problems = append(problems, fmt.Sprintf("unknown MAIL_DRIVER %q", viper.GetString("MAIL_DRIVER")))
+	}
+	if viper.GetString("DATABASE_DSN") == "" {
+		problems = append(problems, "DATABASE_DSN is empty")
+	}
+	return problems
+}
+
+// runSDK implements "toko sdk --spec api/openapi.yaml --out clients", which
+// regenerates the client packages committed under clients/.
+func runSDK(args []string) int {
+	fs := flag.NewFlagSet("sdk", flag.ContinueOnError)
+	specPath := fs.String("spec", "api/openapi.yaml", "OpenAPI spec to generate the clients from")
+	out := fs.String("out", "clients", "directory to write the clients to")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	spec, err := sdkgen.Load(*specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sdk: %v\n", err)
+		return 1
+	}
+	source := filepath.ToSlash(*specPath)
+	goClient, err := sdkgen.GenerateGo(spec, "toko", source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sdk: %v\n", err)
+		return 1
+	}
+	tsClient, err := sdkgen.GenerateTypeScript(spec, source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sdk: %v\n", err)
+		return 1
+	}
+
+	for _, file := range []struct {
+		path string
+		data []byte
+	}{
+		{filepath.Join(*out, "go", "client.go"), goClient},
+		{filepath.Join(*out, "typescript", "src", "index.ts"), tsClient},
+	} {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "sdk: %v\n", err)
+			return 1
+		}
+		if err := os.WriteFile(file.path, file.data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "sdk: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Wrote %s\n", file.path)
+	}
+	return 0
+}
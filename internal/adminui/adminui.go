@@ -0,0 +1,30 @@
+// Package adminui embeds a minimal single-page admin UI that manages the store
+// through the admin API, so small deployments need no separate frontend.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the UI's files. Unknown paths fall back to index.html so
+// client-side routes survive a page reload.
+func Handler() fiber.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The embedded directory is fixed at build time
+	}
+	return filesystem.New(filesystem.Config{
+		Root:         http.FS(files),
+		Index:        "index.html",
+		NotFoundFile: "index.html",
+		MaxAge:       300,
+	})
+}
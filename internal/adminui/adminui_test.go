@@ -0,0 +1,31 @@
+package adminui_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"toko/internal/adminui"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ServesEmbeddedUI(t *testing.T) {
+	app := fiber.New()
+	app.Use("/admin", adminui.Handler())
+
+	for path, contentType := range map[string]string{
+		"/admin":        "text/html",
+		"/admin/app.js": "javascript",
+		"/admin/orders": "text/html", // Client-side routes fall back to the index
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode, path)
+		assert.Contains(t, resp.Header.Get(fiber.HeaderContentType), contentType, path)
+		body, _ := io.ReadAll(resp.Body)
+		assert.NotEmpty(t, body, path)
+	}
+}
@@ -0,0 +1,183 @@
+// Minimal admin SPA. The JWT from /api/v1/auth/login is kept in
+// sessionStorage and sent with every admin API call.
+(function () {
+  "use strict";
+
+  var API = "/api/v1";
+  var ORDER_STATUSES = ["pending", "payment_failed", "processing", "preorder", "partially_shipped", "shipped", "delivered", "cancelled"];
+  var view = document.getElementById("view");
+  var statusLine = document.getElementById("status");
+
+  function token() { return sessionStorage.getItem("toko_admin_token"); }
+
+  function api(method, path, body) {
+    var options = { method: method, headers: { Authorization: "Bearer " + token() } };
+    if (body !== undefined) {
+      options.headers["Content-Type"] = "application/json";
+      options.body = JSON.stringify(body);
+    }
+    return fetch(API + path, options).then(function (res) {
+      if (res.status === 401) {
+        logout();
+        throw new Error("Session expired, sign in again");
+      }
+      if (res.status === 204) { return null; }
+      return res.json().then(function (data) {
+        if (!res.ok) { throw new Error(data.message || res.statusText); }
+        return data;
+      });
+    });
+  }
+
+  function el(tag, attrs, children) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (key.slice(0, 2) === "on") { node.addEventListener(key.slice(2), attrs[key]); }
+      else { node[key] = attrs[key]; }
+    });
+    (children || []).forEach(function (child) {
+      node.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+    });
+    return node;
+  }
+
+  function table(headers, rows) {
+    return el("table", {}, [
+      el("thead", {}, [el("tr", {}, headers.map(function (h) { return el("th", {}, [h]); }))]),
+      el("tbody", {}, rows.map(function (cells) {
+        return el("tr", {}, cells.map(function (c) { return el("td", {}, [c]); }));
+      }))
+    ]);
+  }
+
+  function show(title, nodes) {
+    view.replaceChildren.apply(view, [el("h2", {}, [title])].concat(nodes));
+  }
+
+  function fail(err) { statusLine.textContent = err.message; }
+
+  var views = {
+    orders: function () {
+      var filter = el("select", { onchange: function () { load(); } },
+        [el("option", { value: "" }, ["All statuses"])].concat(ORDER_STATUSES.map(function (s) {
+          return el("option", { value: s }, [s]);
+        })));
+      var holder = el("div");
+      function load() {
+        api("GET", "/orders?page_size=100&status=" + filter.value).then(function (orders) {
+          holder.replaceChildren(table(["ID", "Created", "Customer", "Total", "Status"], orders.map(function (o) {
+            var select = el("select", {
+              onchange: function () {
+                api("PATCH", "/orders/" + o.id + "/status", { status: select.value }).then(load, fail);
+              }
+            }, ORDER_STATUSES.filter(function (s) {
+              // Partial shipment and preorders follow from the items and cannot be chosen
+              return (s !== "partially_shipped" && s !== "preorder") || s === o.status;
+            }).map(function (s) { return el("option", { value: s, selected: s === o.status }, [s]); }));
+            return [o.id, new Date(o.created_at).toLocaleString(), o.user_id, o.grand_total.toFixed(2), select];
+          })));
+        }, fail);
+      }
+      show("Orders", [el("div", { className: "toolbar" }, [filter,
+        el("a", { href: API + "/admin/orders/export", onclick: downloadExport }, ["Export CSV"])]), holder]);
+      load();
+    },
+
+    products: function () {
+      api("GET", "/admin/products").then(function (products) {
+        show("Products", [table(["Name", "SKU", "Price", "Stock", "Status", ""], products.map(function (p) {
+          var archived = p.status === "archived";
+          var action = el("button", {
+            type: "button",
+            onclick: function () {
+              api("POST", "/admin/products/" + p.id + (archived ? "/unarchive" : "/archive")).then(views.products, fail);
+            }
+          }, [archived ? "Unarchive" : "Archive"]);
+          return [p.name, p.sku || "", p.price.toFixed(2), String(p.stock), p.status || "active", action];
+        }))]);
+      }, fail);
+    },
+
+    tasks: function () {
+      api("GET", "/admin/tasks?status=dead").then(function (tasks) {
+        show("Dead letters", [table(["ID", "Type", "Attempts", "Last error", ""], tasks.map(function (t) {
+          var retry = el("button", {
+            type: "button",
+            onclick: function () { api("POST", "/admin/tasks/" + t.id + "/retry").then(views.tasks, fail); }
+          }, ["Retry"]);
+          return [String(t.id), t.type, String(t.attempts), t.last_error || "", retry];
+        }))]);
+      }, fail);
+    },
+
+    flags: function () {
+      api("GET", "/admin/read-only").then(function (mode) {
+        var enabled = el("input", { type: "checkbox", checked: mode.enabled });
+        var reason = el("input", { value: mode.reason || "" });
+        var save = el("button", {
+          type: "button",
+          onclick: function () {
+            api("PUT", "/admin/read-only", { enabled: enabled.checked, reason: reason.value }).then(views.flags, fail);
+          }
+        }, ["Save"]);
+        show("Flags", [el("div", { className: "toolbar" }, [
+          el("label", {}, [enabled, " Read-only mode"]), el("label", {}, ["Reason ", reason]), save
+        ])]);
+      }, fail);
+    }
+  };
+
+  // The export needs the Authorization header, so it is fetched rather than linked
+  function downloadExport(event) {
+    event.preventDefault();
+    fetch(event.target.href, { headers: { Authorization: "Bearer " + token() } })
+      .then(function (res) {
+        if (!res.ok) { throw new Error("Export failed: " + res.statusText); }
+        return res.blob();
+      })
+      .then(function (blob) {
+        var link = el("a", { href: URL.createObjectURL(blob), download: "orders.csv" });
+        link.click();
+        URL.revokeObjectURL(link.href);
+      }, fail);
+  }
+
+  function route() {
+    statusLine.textContent = "";
+    var signedIn = !!token();
+    document.getElementById("login").hidden = signedIn;
+    document.getElementById("nav").hidden = !signedIn;
+    if (!signedIn) { view.replaceChildren(); return; }
+    var name = location.hash.slice(1) || "orders";
+    document.querySelectorAll("nav a").forEach(function (a) {
+      a.classList.toggle("active", a.getAttribute("href") === "#" + name);
+    });
+    (views[name] || views.orders)();
+  }
+
+  function logout() {
+    sessionStorage.removeItem("toko_admin_token");
+    route();
+  }
+
+  document.getElementById("login").addEventListener("submit", function (event) {
+    event.preventDefault();
+    var form = event.target;
+    fetch(API + "/auth/login", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ username: form.username.value, password: form.password.value })
+    })
+      .then(function (res) { return res.json().then(function (data) { return { ok: res.ok, data: data }; }); })
+      .then(function (result) {
+        if (!result.ok) { throw new Error(result.data.message || "Sign in failed"); }
+        sessionStorage.setItem("toko_admin_token", result.data.token);
+        form.reset();
+        route();
+      })
+      .catch(fail);
+  });
+  document.getElementById("logout").addEventListener("click", logout);
+  window.addEventListener("hashchange", route);
+  route();
+})();
@@ -0,0 +1,348 @@
+// Package backup dumps a store's data and uploaded files into a portable
+// archive and loads it back, for disaster recovery and environment cloning.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"toko/internal/models"
+	"toko/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+// formatVersion is bumped whenever the archive layout changes incompatibly.
+const formatVersion = 1
+
+// table describes a backed-up table.
+type table struct {
+	model  interface{}
+	serial bool // Integer auto-increment primary key whose sequence must be reset after a restore
+}
+
+// tables lists the backed-up tables with parents before children.
+// Transient data such as background tasks and export jobs is not included.
+var tables = []table{
+	{model: &models.User{}},
+	{model: &models.Address{}},
+	{model: &models.BetaInvite{}},
+	{model: &models.TaxClass{}},
+	{model: &models.ShippingClass{}},
+	{model: &models.DeliveryZone{}},
+	{model: &models.DeliverySlot{}},
+	{model: &models.Category{}},
+	{model: &models.Product{}},
+	{model: &models.CatalogChange{}, serial: true},
+	{model: &models.BinLocation{}},
+	{model: &models.Coupon{}},
+	{model: &models.Order{}},
+	{model: &models.OrderItem{}, serial: true},
+	{model: &models.ArchivedOrder{}},
+	{model: &models.StockReservation{}, serial: true},
+	{model: &models.ReturnRequest{}},
+	{model: &models.CouponRedemption{}, serial: true},
+	{model: &models.LicenseKey{}, serial: true},
+	{model: &models.Cart{}},
+	{model: &models.CartItem{}, serial: true},
+	{model: &models.WishlistItem{}},
+	{model: &models.CustomerTag{}},
+	{model: &models.CustomerNote{}, serial: true},
+}
+
+// Manifest describes the content of an archive.
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Tables    map[string]int `json:"tables"` // Row count per table
+	Files     []string       `json:"files"`  // Storage keys of product files
+}
+
+// RestoreOptions controls how an archive is loaded.
+type RestoreOptions struct {
+	// Replace allows restoring into a database that already has data, which is deleted first.
+	Replace bool
+}
+
+func tableName(db *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", fmt.Errorf("failed to resolve table for %T: %w", model, err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// Backup writes every table and the product files referenced by the catalog
+// into a gzipped tar archive stored under key in store.
+func Backup(db *gorm.DB, store storage.Storage, key string) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:   formatVersion,
+		CreatedAt: time.Now().UTC(),
+		Tables:    make(map[string]int),
+	}
+
+	dumps := make([]entry, 0, len(tables))
+	for _, t := range tables {
+		name, err := tableName(db, t.model)
+		if err != nil {
+			return nil, err
+		}
+		var rows []map[string]interface{}
+		if err := db.Unscoped().Model(t.model).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read table %s: %w", name, err)
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return nil, fmt.Errorf("failed to encode row of %s: %w", name, err)
+			}
+		}
+		manifest.Tables[name] = len(rows)
+		dumps = append(dumps, entry{name: "tables/" + name + ".jsonl", data: buf.Bytes()})
+	}
+
+	var fileKeys []string
+	if err := db.Unscoped().Model(&models.Product{}).Where("file_key <> ''").Pluck("file_key", &fileKeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list product files: %w", err)
+	}
+	for _, fileKey := range fileKeys {
+		data, err := readObject(store, fileKey)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, fileKey)
+		dumps = append(dumps, entry{name: "files/" + fileKey, data: data})
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	dumps = append([]entry{{name: "manifest.json", data: manifestJSON}}, dumps...)
+
+	archive, err := writeArchive(dumps)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Put(key, bytes.NewReader(archive)); err != nil {
+		return nil, fmt.Errorf("failed to store backup: %w", err)
+	}
+	return manifest, nil
+}
+
+// Restore loads an archive created by Backup. All tables are replaced in a
+// single transaction; product files are written back to store.
+func Restore(db *gorm.DB, store storage.Storage, key string, opts RestoreOptions) (*Manifest, error) {
+	rc, err := store.Open(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer rc.Close()
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var manifest Manifest
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != "manifest.json" {
+		return nil, fmt.Errorf("invalid backup archive: missing manifest")
+	}
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if manifest.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+
+	known := make(map[string]table, len(tables))
+	for _, t := range tables {
+		name, err := tableName(db, t.model)
+		if err != nil {
+			return nil, err
+		}
+		known[name] = t
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := clearTables(tx, opts.Replace); err != nil {
+			return err
+		}
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read backup: %w", err)
+			}
+
+			switch {
+			case strings.HasPrefix(hdr.Name, "tables/"):
+				name := strings.TrimSuffix(path.Base(hdr.Name), ".jsonl")
+				t, ok := known[name]
+				if !ok {
+					return fmt.Errorf("backup contains unknown table %s", name)
+				}
+				if err := loadTable(tx, name, t, tr); err != nil {
+					return err
+				}
+			case strings.HasPrefix(hdr.Name, "files/"):
+				fileKey := strings.TrimPrefix(hdr.Name, "files/")
+				if err := store.Put(fileKey, tr); err != nil {
+					return fmt.Errorf("failed to restore file %s: %w", fileKey, err)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// clearTables empties every backed-up table, children first. Without replace
+// it refuses to touch a database that already holds data.
+func clearTables(tx *gorm.DB, replace bool) error {
+	for i := len(tables) - 1; i >= 0; i-- {
+		name, err := tableName(tx, tables[i].model)
+		if err != nil {
+			return err
+		}
+		if !replace {
+			var count int64
+			if err := tx.Unscoped().Model(tables[i].model).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to inspect table %s: %w", name, err)
+			}
+			if count > 0 {
+				return fmt.Errorf("target database is not empty (table %s has %d rows)", name, count)
+			}
+			continue
+		}
+		if err := tx.Exec("DELETE FROM " + name).Error; err != nil {
+			return fmt.Errorf("failed to clear table %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// loadTable inserts the JSON lines of one table.
+func loadTable(tx *gorm.DB, name string, t table, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var batch []map[string]interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(name).Create(&batch).Error; err != nil {
+			return fmt.Errorf("failed to restore table %s: %w", name, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		var row map[string]interface{}
+		if err := dec.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to decode row of %s: %w", name, err)
+		}
+		batch = append(batch, normalizeRow(row))
+		if len(batch) == 500 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// Explicit IDs were inserted, so Postgres sequences must catch up
+	if t.serial && tx.Dialector.Name() == "postgres" {
+		if err := tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)", name, name)).Error; err != nil {
+			return fmt.Errorf("failed to reset sequence of %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// normalizeRow turns JSON values back into database values: numbers into
+// integers or floats and "*_at" columns into timestamps.
+func normalizeRow(row map[string]interface{}) map[string]interface{} {
+	for column, value := range row {
+		switch v := value.(type) {
+		case json.Number:
+			if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+				row[column] = i
+			} else if f, err := v.Float64(); err == nil {
+				row[column] = f
+			}
+		case string:
+			if strings.HasSuffix(column, "_at") {
+				if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+					row[column] = t
+				}
+			}
+		case map[string]interface{}, []interface{}:
+			// JSON columns are dumped as nested JSON and stored as text again
+			if data, err := json.Marshal(v); err == nil {
+				row[column] = string(data)
+			}
+		}
+	}
+	return row
+}
+
+// entry is a file inside the archive.
+type entry struct {
+	name string
+	data []byte
+}
+
+func writeArchive(entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("failed to write backup: %w", err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return nil, fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func readObject(store storage.Storage, key string) ([]byte, error) {
+	rc, err := store.Open(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read product file %s: %w", key, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read product file %s: %w", key, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,82 @@
+package backup_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"toko/internal/backup"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openDB(t *testing.T, name string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s_%s?mode=memory&cache=shared", t.Name(), name)), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}, &models.Order{}, &models.OrderItem{}, &models.StockReservation{}, &models.BetaInvite{}, &models.Address{}, &models.ReturnRequest{}, &models.TaxClass{}, &models.ShippingClass{}, &models.DeliveryZone{}, &models.DeliverySlot{}, &models.Category{}, &models.BinLocation{}, &models.Coupon{}, &models.CouponRedemption{}, &models.CustomerTag{}, &models.CustomerNote{}, &models.LicenseKey{}, &models.Cart{}, &models.CartItem{}, &models.WishlistItem{}, &models.ArchivedOrder{}))
+	return db
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	source := openDB(t, "source")
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	productRepo := repositories.NewGORMProductRepository(source)
+	userRepo := repositories.NewGORMUserRepository(source)
+	orderRepo := repositories.NewGORMOrderRepository(source)
+
+	require.NoError(t, store.Put("products/guide.pdf", strings.NewReader("guide content")))
+	ebook := &models.Product{Name: "Guide", Price: 10, Type: models.ProductTypeDigital, FileKey: "products/guide.pdf"}
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 7}
+	require.NoError(t, productRepo.Create(ebook))
+	require.NoError(t, productRepo.Create(lamp))
+	user := &models.User{Username: "ani", Email: "ani@example.com", Password: "hash"}
+	require.NoError(t, userRepo.Create(user))
+	require.NoError(t, orderRepo.Create(&models.Order{
+		UserID: user.ID,
+		Status: "delivered",
+		Items:  []models.OrderItem{{ProductID: lamp.ID, Quantity: 2, Price: 40}},
+	}))
+
+	manifest, err := backup.Backup(source, store, "backups/test.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, 2, manifest.Tables["products"])
+	assert.Equal(t, []string{"products/guide.pdf"}, manifest.Files)
+
+	// Restore into a fresh database after the original file is gone
+	require.NoError(t, store.Delete("products/guide.pdf"))
+	target := openDB(t, "target")
+	_, err = backup.Restore(target, store, "backups/test.tar.gz", backup.RestoreOptions{})
+	require.NoError(t, err)
+
+	restoredProduct, err := repositories.NewGORMProductRepository(target).GetByID(ebook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "products/guide.pdf", restoredProduct.FileKey)
+	restoredUser, err := repositories.NewGORMUserRepository(target).GetByEmail("ani@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", restoredUser.Password)
+	orders, err := repositories.NewGORMOrderRepository(target).GetAll()
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Len(t, orders[0].Items, 1)
+
+	rc, err := store.Open("products/guide.pdf")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "guide content", string(content))
+
+	// A second restore needs Replace because the database now has data
+	_, err = backup.Restore(target, store, "backups/test.tar.gz", backup.RestoreOptions{})
+	assert.ErrorContains(t, err, "not empty")
+	_, err = backup.Restore(target, store, "backups/test.tar.gz", backup.RestoreOptions{Replace: true})
+	assert.NoError(t, err)
+}
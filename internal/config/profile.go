@@ -0,0 +1,60 @@
+// Package config resolves layered configuration profiles: a base file shared
+// by every environment overlaid with the file of the selected environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is used when APP_ENV is not set.
+const DefaultProfile = "development"
+
+// Profiles are the environments the application can run in.
+var Profiles = []string{"development", "staging", "production"}
+
+// ResolveProfile normalizes an APP_ENV value, defaulting to DefaultProfile.
+func ResolveProfile(env string) (string, error) {
+	env = strings.ToLower(strings.TrimSpace(env))
+	if env == "" {
+		return DefaultProfile, nil
+	}
+	for _, profile := range Profiles {
+		if env == profile {
+			return profile, nil
+		}
+	}
+	return "", fmt.Errorf("unknown APP_ENV %q: must be one of %s", env, strings.Join(Profiles, ", "))
+}
+
+// LoadProfile merges dir/config.yaml and then dir/config.<profile>.yaml into
+// v, so profile values override base values. Either file may be missing.
+// Defaults registered on v stay below both, and environment variables bound
+// with AutomaticEnv stay above both. It returns the files that were loaded.
+func LoadProfile(v *viper.Viper, dir, profile string) ([]string, error) {
+	var loaded []string
+	for _, name := range []string{"config.yaml", fmt.Sprintf("config.%s.yaml", profile)} {
+		path := filepath.Join(dir, name)
+		file, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return loaded, fmt.Errorf("failed to open config file %s: %w", path, err)
+		}
+		v.SetConfigType("yaml")
+		err = v.MergeConfig(file)
+		file.Close()
+		if err != nil {
+			return loaded, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		loaded = append(loaded, path)
+	}
+	return loaded, nil
+}
@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"toko/internal/config"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProfile(t *testing.T) {
+	profile, err := config.ResolveProfile("")
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultProfile, profile)
+
+	profile, err = config.ResolveProfile(" Production ")
+	require.NoError(t, err)
+	assert.Equal(t, "production", profile)
+
+	_, err = config.ResolveProfile("prod")
+	assert.Error(t, err)
+}
+
+func TestLoadProfile_OverlaysBaseAndKeepsEnvOnTop(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("DB_MAX_OPEN_CONNS: 10\nSTORE_NAME: Toko\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.production.yaml"), []byte("DB_MAX_OPEN_CONNS: 50\nCATALOG_MAX_IN_FLIGHT: 400\n"), 0o644))
+	t.Setenv("CATALOG_MAX_IN_FLIGHT", "800")
+
+	v := viper.New()
+	v.SetDefault("DB_MAX_OPEN_CONNS", 5)
+	v.SetDefault("MAIL_DRIVER", "log")
+	v.AutomaticEnv()
+	loaded, err := config.LoadProfile(v, dir, "production")
+	require.NoError(t, err)
+	assert.Len(t, loaded, 2)
+
+	assert.Equal(t, 50, v.GetInt("DB_MAX_OPEN_CONNS"))
+	assert.Equal(t, "Toko", v.GetString("STORE_NAME"))
+	assert.Equal(t, 800, v.GetInt("CATALOG_MAX_IN_FLIGHT"))
+	assert.Equal(t, "log", v.GetString("MAIL_DRIVER"))
+
+	// Profiles without an overlay file use the base file alone
+	v = viper.New()
+	loaded, err = config.LoadProfile(v, dir, "staging")
+	require.NoError(t, err)
+	assert.Len(t, loaded, 1)
+	assert.Equal(t, 10, v.GetInt("DB_MAX_OPEN_CONNS"))
+}
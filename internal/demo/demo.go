@@ -0,0 +1,324 @@
+// Package demo fills a store with randomized catalog and order history for
+// load tests and sales demos, without any real customer data.
+package demo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	mathrand "math/rand"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Options controls how much data is generated.
+type Options struct {
+	Products  int
+	Customers int // Defaults to one customer per ten orders
+	Orders    int
+	History   time.Duration // Orders are spread over this period up to now
+	Seed      int64         // The same seed draws the same names, prices and orders
+	BatchSize int
+}
+
+// Result counts what was generated.
+type Result struct {
+	Products  int
+	Customers int
+	Orders    int
+}
+
+// Demo order values, loosely matching the default flat shipping rate.
+const (
+	demoShippingRate = 10.0
+	demoTaxRate      = 0.11
+)
+
+// demoCancelReasons are drawn for cancelled demo orders.
+var demoCancelReasons = []string{"changed_mind", "ordered_by_mistake", "found_cheaper", "delivery_too_slow", "payment_problem"}
+
+// Generate inserts the demo data in batches. Generated rows are tagged with
+// a run ID in their SKUs, usernames and emails so repeated runs do not
+// collide. Without new products, orders are placed on the existing catalog.
+func Generate(db *gorm.DB, opts Options) (*Result, error) {
+	if opts.Products < 0 || opts.Customers < 0 || opts.Orders < 0 {
+		return nil, fmt.Errorf("invalid demo options: counts must not be negative")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.History <= 0 {
+		opts.History = 365 * 24 * time.Hour
+	}
+	if opts.Customers == 0 && opts.Orders > 0 {
+		opts.Customers = opts.Orders/10 + 1
+	}
+
+	f := newFaker(opts.Seed)
+	runBytes := make([]byte, 3)
+	if _, err := rand.Read(runBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate run ID: %w", err)
+	}
+	runID := hex.EncodeToString(runBytes)
+	now := time.Now()
+	result := &Result{}
+
+	products, err := generateProducts(db, f, opts, runID, now)
+	if err != nil {
+		return result, err
+	}
+	result.Products = len(products)
+
+	if opts.Orders == 0 {
+		return result, nil
+	}
+	if len(products) == 0 {
+		if err := db.Where("status = ? AND type = ?", models.ProductStatusActive, models.ProductTypePhysical).Limit(10000).Find(&products).Error; err != nil {
+			return result, fmt.Errorf("failed to load products: %w", err)
+		}
+		if len(products) == 0 {
+			return result, fmt.Errorf("invalid demo options: orders need products, generate some with --products")
+		}
+	}
+
+	customers, err := generateCustomers(db, f, opts, runID, now)
+	if err != nil {
+		return result, err
+	}
+	result.Customers = len(customers)
+
+	orders, err := generateOrders(db, f, opts, products, customers, now)
+	result.Orders = orders
+	return result, err
+}
+
+func generateProducts(db *gorm.DB, f *faker, opts Options, runID string, now time.Time) ([]models.Product, error) {
+	products := make([]models.Product, 0, opts.Products)
+	for i := 0; i < opts.Products; i++ {
+		digital := f.rng.Intn(10) == 0
+		name := f.productName(digital)
+		product := models.Product{
+			ID:          uuid.New().String(),
+			Name:        name,
+			Description: f.description(name),
+			SKU:         fmt.Sprintf("DEMO-%s-%06d", runID, i+1),
+			Price:       f.price(5, 250),
+			Status:      models.ProductStatusActive,
+			Type:        models.ProductTypePhysical,
+		}
+		product.CreatedAt = now.Add(-opts.History - time.Duration(f.rng.Int63n(int64(30*24*time.Hour))))
+		if digital {
+			product.Type = models.ProductTypeDigital
+			product.Price = f.price(3, 40)
+		} else {
+			product.Stock = f.between(0, 500)
+			product.WeightGrams = f.between(50, 5000)
+		}
+		products = append(products, product)
+	}
+	if err := insert(db, products, opts.BatchSize); err != nil {
+		return nil, fmt.Errorf("failed to create demo products: %w", err)
+	}
+	return products, nil
+}
+
+// demoCustomer is a generated customer and the name their orders ship to.
+type demoCustomer struct {
+	id   string
+	name string
+}
+
+func generateCustomers(db *gorm.DB, f *faker, opts Options, runID string, now time.Time) ([]demoCustomer, error) {
+	// Demo customers share one unusable password; hashing one per customer
+	// would take minutes at scale
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	users := make([]models.User, 0, opts.Customers)
+	customers := make([]demoCustomer, 0, opts.Customers)
+	for i := 0; i < opts.Customers; i++ {
+		first, last := f.fullName()
+		user := models.User{
+			ID:              uuid.New().String(),
+			Username:        fmt.Sprintf("demo-%s-%d", runID, i+1),
+			Email:           strings.ToLower(fmt.Sprintf("%s.%s.%s%d@demo.toko.test", first, last, runID, i+1)),
+			Password:        string(hashedPassword),
+			Role:            models.RoleCustomer,
+			ShippingCountry: "ID",
+		}
+		user.CreatedAt = now.Add(-time.Duration(f.rng.Int63n(int64(opts.History))))
+		users = append(users, user)
+		customers = append(customers, demoCustomer{id: user.ID, name: first + " " + last})
+	}
+	if err := insert(db, users, opts.BatchSize); err != nil {
+		return nil, fmt.Errorf("failed to create demo customers: %w", err)
+	}
+	return customers, nil
+}
+
+func generateOrders(db *gorm.DB, f *faker, opts Options, products []models.Product, customers []demoCustomer, now time.Time) (int, error) {
+	// A few products sell far more than the rest, as in a real store
+	popularity := mathrand.NewZipf(f.rng, 1.2, 2, uint64(len(products)-1))
+
+	created := 0
+	batch := make([]models.Order, 0, opts.BatchSize)
+	for i := 0; i < opts.Orders; i++ {
+		customer := customers[f.rng.Intn(len(customers))]
+		createdAt := now.Add(-time.Duration(f.rng.Int63n(int64(opts.History))))
+		order := models.Order{
+			ID:              uuid.New().String(),
+			UserID:          customer.id,
+			CreatedAt:       createdAt,
+			UpdatedAt:       createdAt,
+			ShippingAddress: f.address(customer.name),
+		}
+		order.BillingAddress = order.ShippingAddress
+
+		seen := make(map[string]bool)
+		physical := false
+		for n := f.between(1, 4); n > 0; n-- {
+			product := products[popularity.Uint64()]
+			if seen[product.ID] {
+				continue
+			}
+			seen[product.ID] = true
+			physical = physical || product.Type != models.ProductTypeDigital
+			order.Items = append(order.Items, models.OrderItem{
+				ProductID: product.ID,
+				Quantity:  f.between(1, 3),
+				Price:     product.Price,
+			})
+			order.Subtotal += product.Price * float64(order.Items[len(order.Items)-1].Quantity)
+		}
+		order.Subtotal = math.Round(order.Subtotal*100) / 100
+		order.TaxTotal = math.Round(order.Subtotal*demoTaxRate*100) / 100
+		if physical {
+			order.ShippingTotal = demoShippingRate
+			order.ShippingMethod = "flat/standard"
+		}
+		order.UpdateGrandTotal()
+		setDemoStatus(f, &order, now)
+
+		batch = append(batch, order)
+		if len(batch) == opts.BatchSize {
+			if err := insert(db, batch, opts.BatchSize); err != nil {
+				return created, fmt.Errorf("failed to create demo orders: %w", err)
+			}
+			created += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := insert(db, batch, opts.BatchSize); err != nil {
+		return created, fmt.Errorf("failed to create demo orders: %w", err)
+	}
+	return created + len(batch), nil
+}
+
+// setDemoStatus gives an order a status that fits its age: old orders are
+// mostly delivered, recent ones are still on their way.
+func setDemoStatus(f *faker, order *models.Order, now time.Time) {
+	age := now.Sub(order.CreatedAt)
+	roll := f.rng.Intn(100)
+	var status string
+	switch {
+	case age > 14*24*time.Hour:
+		switch {
+		case roll < 85:
+			status = "delivered"
+		case roll < 95:
+			status = "cancelled"
+		default:
+			status = "payment_failed"
+		}
+	case age > 3*24*time.Hour:
+		switch {
+		case roll < 50:
+			status = "delivered"
+		case roll < 80:
+			status = "shipped"
+		case roll < 90:
+			status = "processing"
+		default:
+			status = "cancelled"
+		}
+	default:
+		switch {
+		case roll < 40:
+			status = "pending"
+		case roll < 80:
+			status = "processing"
+		default:
+			status = "shipped"
+		}
+	}
+
+	order.Status = status
+	itemStatus := models.ItemStatusPending
+	switch status {
+	case "processing":
+		itemStatus = models.ItemStatusProcessing
+	case "shipped":
+		itemStatus = models.ItemStatusShipped
+	case "delivered":
+		itemStatus = models.ItemStatusDelivered
+	case "cancelled":
+		itemStatus = models.ItemStatusCancelled
+		cancelledAt := order.CreatedAt.Add(time.Duration(f.rng.Int63n(int64(48 * time.Hour))))
+		order.CancelReason = f.pick(demoCancelReasons)
+		order.CancelledAt = &cancelledAt
+	}
+	for i := range order.Items {
+		order.Items[i].Status = itemStatus
+	}
+	if status == "shipped" || status == "delivered" {
+		order.Carrier = "demo"
+		order.TrackingNumber = fmt.Sprintf("DEMO%010d", f.rng.Int63n(1e10))
+	}
+	setDemoMilestones(f, order, now)
+}
+
+// setDemoMilestones spreads the fulfillment milestones the order's status
+// has reached over plausible delays, none of them after now.
+func setDemoMilestones(f *faker, order *models.Order, now time.Time) {
+	at := order.CreatedAt
+	delays := map[string]time.Duration{
+		models.MilestonePaid:      time.Duration(f.rng.Int63n(int64(6 * time.Hour))),
+		models.MilestoneShipped:   2*time.Hour + time.Duration(f.rng.Int63n(int64(46*time.Hour))),
+		models.MilestoneDelivered: 24*time.Hour + time.Duration(f.rng.Int63n(int64(96*time.Hour))),
+	}
+	for _, milestone := range models.ReachedMilestones(order.Status) {
+		at = at.Add(delays[milestone])
+		if at.After(now) {
+			at = now
+		}
+		reached := at
+		switch milestone {
+		case models.MilestonePaid:
+			order.PaidAt = &reached
+		case models.MilestoneShipped:
+			order.ShippedAt = &reached
+		case models.MilestoneDelivered:
+			order.DeliveredAt = &reached
+		}
+	}
+}
+
+func insert[T any](db *gorm.DB, rows []T, batchSize int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(rows, batchSize).Error
+}
@@ -0,0 +1,46 @@
+package demo_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"toko/internal/demo"
+	"toko/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGenerate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Product{}, &models.User{}, &models.Order{}, &models.OrderItem{}))
+
+	result, err := demo.Generate(db, demo.Options{Products: 30, Orders: 120, History: 90 * 24 * time.Hour, BatchSize: 50})
+	require.NoError(t, err)
+	assert.Equal(t, demo.Result{Products: 30, Customers: 13, Orders: 120}, *result)
+
+	var orders []models.Order
+	require.NoError(t, db.Preload("Items").Find(&orders).Error)
+	require.Len(t, orders, 120)
+	for _, order := range orders {
+		assert.NotEmpty(t, order.Items)
+		assert.Contains(t, []string{"pending", "processing", "shipped", "delivered", "cancelled", "payment_failed"}, order.Status)
+		assert.InDelta(t, order.Subtotal+order.TaxTotal+order.ShippingTotal, order.GrandTotal, 0.01)
+		assert.True(t, order.CreatedAt.After(time.Now().Add(-91*24*time.Hour)))
+		assert.Equal(t, "ID", order.ShippingAddress.Country)
+	}
+
+	// A second run adds orders on the existing catalog without colliding
+	result, err = demo.Generate(db, demo.Options{Orders: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Products)
+	assert.Equal(t, 10, result.Orders)
+
+	var customers int64
+	require.NoError(t, db.Model(&models.User{}).Count(&customers).Error)
+	assert.Equal(t, int64(15), customers)
+}
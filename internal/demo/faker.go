@@ -0,0 +1,111 @@
+package demo
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"toko/internal/models"
+)
+
+// Word lists the fake catalog, customers and addresses are drawn from. No
+// entry is taken from real customer data.
+var (
+	firstNames = []string{
+		"Adi", "Ani", "Bayu", "Budi", "Citra", "Dewi", "Dian", "Eka", "Fajar", "Gita",
+		"Hadi", "Indah", "Joko", "Kartika", "Lestari", "Maya", "Nanda", "Oki", "Putri", "Rizky",
+		"Sari", "Teguh", "Umar", "Vina", "Wahyu", "Yuni", "Zainal", "Agus", "Bella", "Rina",
+	}
+	lastNames = []string{
+		"Pratama", "Wijaya", "Santoso", "Saputra", "Hidayat", "Kusuma", "Lubis", "Nasution", "Siregar", "Gunawan",
+		"Halim", "Tan", "Setiawan", "Susanto", "Wibowo", "Purnomo", "Rahman", "Hakim", "Utami", "Maharani",
+	}
+	streets = []string{
+		"Jl. Merdeka", "Jl. Sudirman", "Jl. Thamrin", "Jl. Diponegoro", "Jl. Gatot Subroto",
+		"Jl. Ahmad Yani", "Jl. Pahlawan", "Jl. Kenanga", "Jl. Melati", "Jl. Cendana",
+	}
+	cities = []struct{ name, region, postalPrefix string }{
+		{"Jakarta", "DKI Jakarta", "10"},
+		{"Bandung", "Jawa Barat", "40"},
+		{"Surabaya", "Jawa Timur", "60"},
+		{"Yogyakarta", "DI Yogyakarta", "55"},
+		{"Semarang", "Jawa Tengah", "50"},
+		{"Medan", "Sumatera Utara", "20"},
+		{"Makassar", "Sulawesi Selatan", "90"},
+		{"Denpasar", "Bali", "80"},
+		{"Malang", "Jawa Timur", "65"},
+		{"Palembang", "Sumatera Selatan", "30"},
+	}
+	adjectives = []string{
+		"Classic", "Organic", "Handmade", "Premium", "Vintage", "Compact", "Deluxe", "Eco", "Modern", "Rustic",
+		"Essential", "Everyday", "Lightweight", "Sturdy", "Soft",
+	}
+	materials = []string{
+		"Cotton", "Bamboo", "Leather", "Ceramic", "Teak", "Batik", "Rattan", "Steel", "Linen", "Wool",
+	}
+	nouns = []string{
+		"T-Shirt", "Mug", "Tote Bag", "Notebook", "Sandals", "Backpack", "Lamp", "Bowl", "Scarf", "Wallet",
+		"Coffee Beans", "Tea Set", "Cutting Board", "Pillow Cover", "Water Bottle", "Hat", "Candle", "Basket",
+	}
+	digitalNouns = []string{"E-Book", "Recipe Collection", "Sewing Pattern", "Photo Preset Pack", "Online Course"}
+)
+
+// faker draws random but plausible store data from a seeded source, so the
+// same seed always produces the same data set.
+type faker struct {
+	rng *rand.Rand
+}
+
+func newFaker(seed int64) *faker {
+	return &faker{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (f *faker) pick(list []string) string {
+	return list[f.rng.Intn(len(list))]
+}
+
+// between returns a random int in [min, max].
+func (f *faker) between(min, max int) int {
+	return min + f.rng.Intn(max-min+1)
+}
+
+// price returns a price between min and max dollars ending in .99 or .00.
+func (f *faker) price(min, max int) float64 {
+	dollars := f.between(min, max)
+	if f.rng.Intn(2) == 0 {
+		return float64(dollars) + 0.99
+	}
+	return float64(dollars)
+}
+
+func (f *faker) productName(digital bool) string {
+	if digital {
+		return fmt.Sprintf("%s %s", f.pick(adjectives), f.pick(digitalNouns))
+	}
+	return fmt.Sprintf("%s %s %s", f.pick(adjectives), f.pick(materials), f.pick(nouns))
+}
+
+func (f *faker) description(name string) string {
+	return fmt.Sprintf("%s, made for %s use. Demo product generated for testing.", name, strings.ToLower(f.pick(adjectives)))
+}
+
+func (f *faker) fullName() (string, string) {
+	return f.pick(firstNames), f.pick(lastNames)
+}
+
+func (f *faker) phone() string {
+	return fmt.Sprintf("+628%d%08d", f.between(11, 59), f.rng.Intn(100000000))
+}
+
+func (f *faker) address(recipient string) models.PostalAddress {
+	city := cities[f.rng.Intn(len(cities))]
+	return models.PostalAddress{
+		RecipientName: recipient,
+		Phone:         f.phone(),
+		Line1:         fmt.Sprintf("%s No. %d", f.pick(streets), f.between(1, 250)),
+		City:          city.name,
+		Region:        city.region,
+		PostalCode:    fmt.Sprintf("%s%03d", city.postalPrefix, f.rng.Intn(1000)),
+		Country:       "ID",
+	}
+}
@@ -0,0 +1,126 @@
+// Package doctor runs the self-checks of "toko doctor": each check probes
+// one dependency of a deployment and the results are printed as a report.
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"toko/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+// ErrSkipped is returned by checks of optional dependencies that are not configured.
+var ErrSkipped = errors.New("skipped")
+
+// Check probes one dependency. Run returns a short detail shown on success,
+// an error wrapping ErrSkipped when there is nothing to check, or the failure.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name    string
+	Status  string // PASS, FAIL or SKIP
+	Detail  string
+	Elapsed time.Duration
+}
+
+// Check statuses.
+const (
+	StatusPass = "PASS"
+	StatusFail = "FAIL"
+	StatusSkip = "SKIP"
+)
+
+// Run runs the checks in order, each with the timeout, and writes one line
+// per check to w. It reports whether no check failed.
+func Run(ctx context.Context, checks []Check, timeout time.Duration, w io.Writer) ([]Result, bool) {
+	results := make([]Result, 0, len(checks))
+	ok := true
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		started := time.Now()
+		detail, err := check.Run(checkCtx)
+		cancel()
+
+		result := Result{Name: check.Name, Status: StatusPass, Detail: detail, Elapsed: time.Since(started)}
+		switch {
+		case errors.Is(err, ErrSkipped):
+			result.Status, result.Detail = StatusSkip, strings.TrimSuffix(strings.TrimSuffix(err.Error(), ErrSkipped.Error()), ": ")
+		case err != nil:
+			result.Status, result.Detail = StatusFail, err.Error()
+			ok = false
+		}
+		results = append(results, result)
+		fmt.Fprintf(w, "[%s] %-12s %s (%s)\n", result.Status, result.Name, result.Detail, result.Elapsed.Round(time.Millisecond))
+	}
+	return results, ok
+}
+
+// Skip returns an error marking a check as skipped for the reason.
+func Skip(reason string) error {
+	return fmt.Errorf("%s: %w", reason, ErrSkipped)
+}
+
+// Schema returns an error listing the tables and columns of the models that
+// are missing from the database, i.e. migrations the server has yet to run.
+func Schema(db *gorm.DB, models ...interface{}) (string, error) {
+	migrator := db.Migrator()
+	var missing []string
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return "", fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		if !migrator.HasTable(model) {
+			missing = append(missing, "table "+stmt.Schema.Table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+				missing = append(missing, "column "+stmt.Schema.Table+"."+field.DBName)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("schema is not migrated, missing %s; start the server once to migrate it", strings.Join(missing, ", "))
+	}
+	return fmt.Sprintf("%d tables up to date", len(models)), nil
+}
+
+// Storage writes, reads back and deletes a probe object, which needs the same
+// permissions as uploads and backups.
+func Storage(fileStorage storage.Storage) (string, error) {
+	key := fmt.Sprintf("doctor/probe-%d", time.Now().UnixNano())
+	content := []byte("toko doctor")
+	if err := fileStorage.Put(key, bytes.NewReader(content)); err != nil {
+		return "", fmt.Errorf("cannot write: %w", err)
+	}
+	defer fileStorage.Delete(key)
+
+	r, err := fileStorage.Open(key)
+	if err != nil {
+		return "", fmt.Errorf("cannot read: %w", err)
+	}
+	defer r.Close()
+	read, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("cannot read: %w", err)
+	}
+	if !bytes.Equal(read, content) {
+		return "", fmt.Errorf("probe object read back with different content")
+	}
+	if err := fileStorage.Delete(key); err != nil {
+		return "", fmt.Errorf("cannot delete: %w", err)
+	}
+	return "read, write and delete allowed", nil
+}
@@ -0,0 +1,70 @@
+package doctor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"toko/internal/doctor"
+	"toko/internal/models"
+	"toko/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRun_ReportsEveryCheck(t *testing.T) {
+	var out bytes.Buffer
+	results, ok := doctor.Run(context.Background(), []doctor.Check{
+		{Name: "db", Run: func(ctx context.Context) (string, error) { return "connected", nil }},
+		{Name: "redis", Run: func(ctx context.Context) (string, error) { return "", doctor.Skip("not configured") }},
+		{Name: "smtp", Run: func(ctx context.Context) (string, error) { return "", errors.New("connection refused") }},
+		{Name: "slow", Run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	}, 10*time.Millisecond, &out)
+
+	assert.False(t, ok)
+	require.Len(t, results, 4)
+	assert.Equal(t, doctor.StatusPass, results[0].Status)
+	assert.Equal(t, doctor.StatusSkip, results[1].Status)
+	assert.Equal(t, "not configured", results[1].Detail)
+	assert.Equal(t, doctor.StatusFail, results[2].Status)
+	assert.Equal(t, doctor.StatusFail, results[3].Status)
+	assert.Contains(t, out.String(), "[FAIL] smtp")
+	assert.Contains(t, out.String(), "connection refused")
+
+	_, ok = doctor.Run(context.Background(), []doctor.Check{
+		{Name: "redis", Run: func(ctx context.Context) (string, error) { return "", doctor.Skip("not configured") }},
+	}, time.Second, &out)
+	assert.True(t, ok)
+}
+
+func TestSchema_ListsMissingTablesAndColumns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Cart{}))
+	require.NoError(t, db.Migrator().DropColumn(&models.Cart{}, "token"))
+
+	_, err = doctor.Schema(db, &models.Cart{}, &models.CartItem{})
+	assert.ErrorContains(t, err, "column carts.token")
+	assert.ErrorContains(t, err, "table cart_items")
+
+	require.NoError(t, db.AutoMigrate(&models.Cart{}, &models.CartItem{}))
+	detail, err := doctor.Schema(db, &models.Cart{}, &models.CartItem{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2 tables up to date", detail)
+}
+
+func TestStorage_ProbesReadWriteDelete(t *testing.T) {
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	_, err = doctor.Storage(fileStorage)
+	assert.NoError(t, err)
+}
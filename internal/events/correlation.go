@@ -0,0 +1,49 @@
+package events
+
+import "context"
+
+// AMQP headers tying a message to what started it: the correlation ID is the
+// request ID of the HTTP request that began the chain of work, the causation
+// ID that of the request or message that directly caused this one.
+const (
+	CorrelationIDHeader = "correlation_id"
+	CausationIDHeader   = "causation_id"
+)
+
+type correlationKey struct{}
+
+// Correlation identifies the chain of work a message belongs to.
+type Correlation struct {
+	CorrelationID string
+	CausationID   string
+}
+
+// WithCorrelation returns a context whose published events carry the given
+// correlation.
+func WithCorrelation(ctx context.Context, correlation Correlation) context.Context {
+	return context.WithValue(ctx, correlationKey{}, correlation)
+}
+
+// CorrelationFromContext returns the correlation stored in ctx, if any.
+func CorrelationFromContext(ctx context.Context) Correlation {
+	correlation, _ := ctx.Value(correlationKey{}).(Correlation)
+	return correlation
+}
+
+// Headers adds the correlation headers that are set to headers.
+func (c Correlation) Headers(headers map[string]interface{}) {
+	if c.CorrelationID != "" {
+		headers[CorrelationIDHeader] = c.CorrelationID
+	}
+	if c.CausationID != "" {
+		headers[CausationIDHeader] = c.CausationID
+	}
+}
+
+// CorrelationFromHeaders reads the correlation headers of a message.
+func CorrelationFromHeaders(headers map[string]interface{}) Correlation {
+	correlation := Correlation{}
+	correlation.CorrelationID, _ = headers[CorrelationIDHeader].(string)
+	correlation.CausationID, _ = headers[CausationIDHeader].(string)
+	return correlation
+}
@@ -0,0 +1,142 @@
+// Package events holds the JSON Schemas of the events exchanged over RabbitMQ
+// and validates payloads against them.
+//
+// Schemas live in schemas/<routing key>.v<version>.json. A breaking change to
+// an event adds a new version file; old versions stay so that consumers can
+// still validate messages published before the change.
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed schemas/*.json
+var embeddedSchemas embed.FS
+
+// SchemaVersionHeader is the AMQP header carrying the schema version of a message.
+const SchemaVersionHeader = "schema_version"
+
+// Validation directions used in metrics.
+const (
+	DirectionPublish = "publish"
+	DirectionConsume = "consume"
+)
+
+// schemaMetrics counts validation outcomes per routing key on /debug/vars.
+var schemaMetrics = expvar.NewMap("event_schema")
+
+var schemaFileName = regexp.MustCompile(`^(.+)\.v(\d+)\.json$`)
+
+// Registry holds compiled schemas by routing key and version.
+type Registry struct {
+	schemas map[string]map[int]*jsonschema.Schema
+	latest  map[string]int
+}
+
+// NewRegistry compiles every schemas/*.json file in fsys.
+func NewRegistry(fsys fs.FS) (*Registry, error) {
+	files, err := fs.Glob(fsys, "schemas/*.json")
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{
+		schemas: make(map[string]map[int]*jsonschema.Schema),
+		latest:  make(map[string]int),
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	for _, file := range files {
+		match := schemaFileName.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("schema file %s must be named <routing key>.v<version>.json", file)
+		}
+		routingKey := match[1]
+		version, _ := strconv.Atoi(match[2])
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if err := compiler.AddResource(file, bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", file, err)
+		}
+		schema, err := compiler.Compile(file)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", file, err)
+		}
+
+		if r.schemas[routingKey] == nil {
+			r.schemas[routingKey] = make(map[int]*jsonschema.Schema)
+		}
+		r.schemas[routingKey][version] = schema
+		if version > r.latest[routingKey] {
+			r.latest[routingKey] = version
+		}
+	}
+	return r, nil
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// DefaultRegistry returns the registry of the schemas embedded in the binary.
+func DefaultRegistry() *Registry {
+	defaultRegistryOnce.Do(func() {
+		registry, err := NewRegistry(embeddedSchemas)
+		if err != nil {
+			panic(fmt.Sprintf("events: embedded schemas are invalid: %v", err))
+		}
+		defaultRegistry = registry
+	})
+	return defaultRegistry
+}
+
+// LatestVersion returns the newest schema version of an event, or 0 if the
+// event has no schema.
+func (r *Registry) LatestVersion(routingKey string) int {
+	return r.latest[routingKey]
+}
+
+// Validate checks payload against the given schema version of an event;
+// version 0 means the latest. Events without a registered schema pass but are
+// counted as unregistered.
+func (r *Registry) Validate(direction, routingKey string, version int, payload []byte) error {
+	versions, ok := r.schemas[routingKey]
+	if !ok {
+		schemaMetrics.Add(routingKey+"."+direction+".unregistered", 1)
+		return nil
+	}
+	if version == 0 {
+		version = r.latest[routingKey]
+	}
+	schema, ok := versions[version]
+	if !ok {
+		schemaMetrics.Add(routingKey+"."+direction+".invalid", 1)
+		return fmt.Errorf("unknown schema version %d for %s event", version, routingKey)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		schemaMetrics.Add(routingKey+"."+direction+".invalid", 1)
+		return fmt.Errorf("invalid %s event: malformed JSON: %w", routingKey, err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		schemaMetrics.Add(routingKey+"."+direction+".invalid", 1)
+		return fmt.Errorf("invalid %s event (schema v%d): %w", routingKey, version, err)
+	}
+	schemaMetrics.Add(routingKey+"."+direction+".valid", 1)
+	return nil
+}
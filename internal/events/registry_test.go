@@ -0,0 +1,48 @@
+package events_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"toko/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRegistry_ValidatesPayloads(t *testing.T) {
+	registry := events.DefaultRegistry()
+	assert.Equal(t, 1, registry.LatestVersion("order.created"))
+
+	err := registry.Validate(events.DirectionPublish, "order.created", 0, []byte(`{"orderID":"o1","userID":"u1","status":"pending","total":12.5}`))
+	assert.NoError(t, err)
+
+	err = registry.Validate(events.DirectionPublish, "order.created", 0, []byte(`{"orderID":"o1","status":"pending"}`))
+	assert.Error(t, err)
+
+	err = registry.Validate(events.DirectionConsume, "product.archived", 1, []byte(`{"productID":"p1","archivedAt":"yesterday"}`))
+	assert.Error(t, err, "archivedAt must be a date-time")
+
+	err = registry.Validate(events.DirectionConsume, "order.created", 7, []byte(`{}`))
+	assert.ErrorContains(t, err, "unknown schema version")
+
+	// Events without a schema are let through
+	assert.NoError(t, registry.Validate(events.DirectionPublish, "order.unknown", 0, []byte(`{}`)))
+}
+
+func TestNewRegistry_TracksVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/thing.happened.v1.json": {Data: []byte(`{"type":"object","required":["id"]}`)},
+		"schemas/thing.happened.v2.json": {Data: []byte(`{"type":"object","required":["id","at"]}`)},
+	}
+	registry, err := events.NewRegistry(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, 2, registry.LatestVersion("thing.happened"))
+
+	payload := []byte(`{"id":"1"}`)
+	assert.NoError(t, registry.Validate(events.DirectionConsume, "thing.happened", 1, payload))
+	assert.Error(t, registry.Validate(events.DirectionConsume, "thing.happened", 0, payload))
+
+	_, err = events.NewRegistry(fstest.MapFS{"schemas/bad-name.json": {Data: []byte(`{}`)}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AddressHandler handles HTTP requests for the authenticated user's address book.
+type AddressHandler struct {
+	service  *services.AddressService
+	validate *validator.Validate
+}
+
+// NewAddressHandler creates a new AddressHandler.
+func NewAddressHandler(service *services.AddressService) *AddressHandler {
+	return &AddressHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// RegisterRoutes registers the address book routes with the Fiber app.
+func (h *AddressHandler) RegisterRoutes(router fiber.Router) {
+	addressRoutes := router.Group("/users/me/addresses")
+	addressRoutes.Get("/", h.HandleListAddresses)
+	addressRoutes.Post("/", h.HandleCreateAddress)
+	addressRoutes.Get("/:id", h.HandleGetAddress)
+	addressRoutes.Put("/:id", h.HandleUpdateAddress)
+	addressRoutes.Delete("/:id", h.HandleDeleteAddress)
+}
+
+// HandleListAddresses lists the user's addresses, default first.
+func (h *AddressHandler) HandleListAddresses(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	addresses, err := h.service.ListAddresses(userID)
+	if err != nil {
+		log.Printf("Error listing addresses for user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve addresses",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(addresses)
+}
+
+// HandleGetAddress returns one of the user's addresses.
+func (h *AddressHandler) HandleGetAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	id := c.Params("id")
+	address, err := h.service.GetAddress(userID, id)
+	if err != nil {
+		return h.addressError(c, id, "retrieve", err)
+	}
+	return c.JSON(address)
+}
+
+// HandleCreateAddress adds an address to the user's address book.
+func (h *AddressHandler) HandleCreateAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	address, ok := h.parseAddress(c)
+	if !ok {
+		return nil
+	}
+	if err := h.service.CreateAddress(userID, address); err != nil {
+		return h.addressError(c, "", "create", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(address)
+}
+
+// HandleUpdateAddress replaces one of the user's addresses.
+func (h *AddressHandler) HandleUpdateAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	id := c.Params("id")
+	changes, ok := h.parseAddress(c)
+	if !ok {
+		return nil
+	}
+	address, err := h.service.UpdateAddress(userID, id, changes)
+	if err != nil {
+		return h.addressError(c, id, "update", err)
+	}
+	return c.JSON(address)
+}
+
+// HandleDeleteAddress removes one of the user's addresses.
+func (h *AddressHandler) HandleDeleteAddress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	id := c.Params("id")
+	if err := h.service.DeleteAddress(userID, id); err != nil {
+		return h.addressError(c, id, "delete", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseAddress binds and validates the request body, writing a 400 response when it is invalid.
+func (h *AddressHandler) parseAddress(c *fiber.Ctx) (*models.Address, bool) {
+	var address models.Address
+	if err := c.BodyParser(&address); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+	address.Country = strings.ToUpper(address.Country)
+	if err := h.validate.Struct(address); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+	return &address, true
+}
+
+func (h *AddressHandler) addressError(c *fiber.Ctx, id, action string, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": fmt.Sprintf("Address with ID %s not found", id),
+		})
+	}
+	if strings.Contains(err.Error(), "invalid address") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s address %s: %v", action, id, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": fmt.Sprintf("Could not %s address", action),
+		"error":   err.Error(),
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"toko/internal/models"
 	"toko/internal/services"
+	"toko/pkg/captcha"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -15,8 +16,13 @@ import (
 type AuthHandler struct {
 	authService *services.AuthService
 	validate    *validator.Validate
+	carts       *services.CartService
+	captcha     captcha.Verifier
 }
 
+// CaptchaTokenHeader carries the CAPTCHA widget's token when the body does not.
+const CaptchaTokenHeader = "X-Captcha-Token"
+
 // NewAuthHandler creates a new AuthHandler.
 func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 	return &AuthHandler{
@@ -25,11 +31,35 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 	}
 }
 
+// SetCarts merges the shopper's anonymous cart into their own at login.
+func (h *AuthHandler) SetCarts(carts *services.CartService) {
+	h.carts = carts
+}
+
+// SetCaptcha makes registration and login require a token from the
+// CAPTCHA widget the verifier checks, to slow down bots.
+func (h *AuthHandler) SetCaptcha(verifier captcha.Verifier) {
+	h.captcha = verifier
+}
+
 // RegisterRoutes registers the authentication routes with the Fiber app.
 func (h *AuthHandler) RegisterRoutes(router fiber.Router) {
 	authRoutes := router.Group("/auth")
 	authRoutes.Post("/register", h.HandleRegister)
 	authRoutes.Post("/login", h.HandleLogin)
+	authRoutes.Post("/refresh", h.HandleRefresh)
+	authRoutes.Post("/device-login", h.HandleDeviceLogin)
+	authRoutes.Post("/forgot-password", h.HandleForgotPassword)
+	authRoutes.Post("/reset-password", h.HandleResetPassword)
+	authRoutes.Post("/confirm-email", h.HandleConfirmEmail)
+	authRoutes.Post("/guest-token", h.HandleGuestToken)
+}
+
+// RegisterProtectedRoutes registers the authentication routes that need a
+// logged-in user with a router behind AuthRequired.
+func (h *AuthHandler) RegisterProtectedRoutes(router fiber.Router) {
+	router.Post("/auth/logout", h.HandleLogout)
+	router.Post("/users/me/password", h.HandleChangePassword)
 }
 
 // HandleRegister handles new user registration.
@@ -43,6 +73,10 @@ func (h *AuthHandler) HandleRegister(c *fiber.Ctx) error {
 		})
 	}
 
+	if !h.checkCaptcha(c) {
+		return nil
+	}
+
 	// Validate the user struct
 	if err := h.validate.Struct(user); err != nil {
 		validationErrors := err.(validator.ValidationErrors)
@@ -58,6 +92,12 @@ func (h *AuthHandler) HandleRegister(c *fiber.Ctx) error {
 
 	if err := h.authService.RegisterUser(&user); err != nil {
 		log.Printf("Error registering user: %v", err)
+		if strings.Contains(err.Error(), "not invited") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Registration is invite-only during the beta",
+				"error":   err.Error(),
+			})
+		}
 		if strings.Contains(err.Error(), "already taken") || strings.Contains(err.Error(), "already registered") {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 				"message": "Registration failed",
@@ -80,8 +120,19 @@ func (h *AuthHandler) HandleRegister(c *fiber.Ctx) error {
 
 // LoginRequest represents the request body for login.
 type LoginRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username  string `json:"username" validate:"required"`
+	Password  string `json:"password" validate:"required"`
+	CartToken string `json:"cart_token"` // Anonymous cart to merge into the user's cart; also read from X-Cart-Token
+	// RememberDevice asks for a device token bound to DeviceFingerprint,
+	// which logs the device in again at /auth/device-login.
+	RememberDevice    bool   `json:"remember_device"`
+	DeviceFingerprint string `json:"device_fingerprint"`
+	DeviceName        string `json:"device_name"`
+}
+
+// captchaRequest holds the CAPTCHA token of a registration or login body.
+type captchaRequest struct {
+	CaptchaToken string `json:"captcha_token"`
 }
 
 // HandleLogin handles user login and issues a JWT token.
@@ -108,17 +159,409 @@ func (h *AuthHandler) HandleLogin(c *fiber.Ctx) error {
 		})
 	}
 
-	token, err := h.authService.LoginUser(req.Username, req.Password)
+	if req.RememberDevice && strings.TrimSpace(req.DeviceFingerprint) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "device_fingerprint is required to remember the device",
+		})
+	}
+	if !h.checkCaptcha(c) {
+		return nil
+	}
+
+	tokens, err := h.authService.LoginFrom(req.Username, req.Password, services.DeviceInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IPAddress: c.IP()})
 	if err != nil {
 		log.Printf("Error during login for user %s: %v", req.Username, err)
+		if strings.Contains(err.Error(), "not invited") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "The store is in invite-only beta",
+				"error":   err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"message": "Authentication failed",
 			"error":   err.Error(),
 		})
 	}
 
-	return c.JSON(fiber.Map{
+	token := tokens.AccessToken
+	response := fiber.Map{
 		"message": "Login successful",
 		"token":   token,
+	}
+	if tokens.RefreshToken != "" {
+		response["refresh_token"] = tokens.RefreshToken
+	}
+	if req.RememberDevice {
+		// A device that cannot be remembered must not keep the user from logging in
+		if claims, err := h.authService.ValidateToken(token); err == nil {
+			userID, _ := claims["user_id"].(string)
+			deviceToken, err := h.authService.RememberDevice(userID, req.DeviceFingerprint, req.DeviceName)
+			if err != nil {
+				log.Printf("Error remembering device of user %s: %v", userID, err)
+			} else {
+				response["device_token"] = deviceToken
+			}
+		}
+	}
+	cartToken := req.CartToken
+	if cartToken == "" {
+		cartToken = c.Get(CartTokenHeader)
+	}
+	if h.carts != nil && cartToken != "" {
+		// A cart that cannot be merged must not keep the user from logging in
+		if claims, err := h.authService.ValidateToken(token); err == nil {
+			userID, _ := claims["user_id"].(string)
+			merged, err := h.carts.MergeCart(userID, cartToken)
+			if err != nil {
+				log.Printf("Error merging cart for user %s: %v", userID, err)
+			} else {
+				response["cart"] = merged.Cart
+				response["cart_adjustments"] = merged.Adjustments
+			}
+		}
+	}
+	return c.JSON(response)
+}
+
+// RefreshRequest represents the request body for exchanging a refresh token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// HandleRefresh exchanges a refresh token for a new access token and
+// refresh token. The old refresh token stops working.
+func (h *AuthHandler) HandleRefresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "refresh_token is required",
+		})
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		if strings.Contains(err.Error(), "not invited") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "The store is in invite-only beta",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Authentication failed",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error refreshing token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not refresh token",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message":       "Token refreshed",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+	})
+}
+
+// ForgotPasswordRequest represents the request body for asking a password reset link.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// DeviceLoginRequest represents the request body for logging a remembered
+// device in again.
+type DeviceLoginRequest struct {
+	DeviceToken       string `json:"device_token" validate:"required"`
+	DeviceFingerprint string `json:"device_fingerprint" validate:"required"`
+}
+
+// HandleDeviceLogin logs a remembered device in with its device token,
+// returning a new access token and refresh token.
+func (h *AuthHandler) HandleDeviceLogin(c *fiber.Ctx) error {
+	var req DeviceLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "device_token and device_fingerprint are required",
+		})
+	}
+
+	tokens, err := h.authService.LoginWithDevice(req.DeviceToken, req.DeviceFingerprint, services.DeviceInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IPAddress: c.IP()})
+	if err != nil {
+		if strings.Contains(err.Error(), "not invited") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "The store is in invite-only beta",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Authentication failed",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error logging in with device token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not log in",
+			"error":   err.Error(),
+		})
+	}
+	response := fiber.Map{
+		"message": "Login successful",
+		"token":   tokens.AccessToken,
+	}
+	if tokens.RefreshToken != "" {
+		response["refresh_token"] = tokens.RefreshToken
+	}
+	return c.JSON(response)
+}
+
+// HandleForgotPassword emails a password reset link to the address. The
+// response is the same whether or not an account has the address.
+func (h *AuthHandler) HandleForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "a valid email is required",
+		})
+	}
+
+	if err := h.authService.RequestPasswordReset(req.Email); err != nil {
+		log.Printf("Error requesting password reset: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not request password reset",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "If an account uses this email, a link to reset its password has been sent",
+	})
+}
+
+// ResetPasswordRequest represents the request body for setting a new password with a reset token.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// HandleResetPassword sets a new password with the token from a password reset email.
+func (h *AuthHandler) HandleResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "token and password are required",
+		})
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.Password); err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Could not reset password",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error resetting password: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not reset password",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleGuestToken issues a guest token that shoppers who have not logged in
+// browse the catalog with.
+func (h *AuthHandler) HandleGuestToken(c *fiber.Ctx) error {
+	token, err := h.authService.IssueGuestToken()
+	if err != nil {
+		log.Printf("Error issuing guest token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not issue guest token",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(token)
+}
+
+// ConfirmEmailRequest represents the request body for confirming a new email with the token sent to it.
+type ConfirmEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// HandleConfirmEmail replaces a user's email with the new address the token
+// from an email change confirmation was sent to.
+func (h *AuthHandler) HandleConfirmEmail(c *fiber.Ctx) error {
+	var req ConfirmEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "token is required",
+		})
+	}
+
+	user, err := h.authService.ConfirmEmailChange(req.Token)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Could not confirm email",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "already registered") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error confirming email change: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not confirm email",
+			"error":   err.Error(),
+		})
+	}
+	user.Password = "" // For security, do not return the password hash
+	return c.JSON(user)
+}
+
+// ChangePasswordRequest represents the request body for changing the logged-in user's password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// HandleChangePassword changes the logged-in user's password. The login the
+// request was made with stays signed in; the user's other logins are ended.
+func (h *AuthHandler) HandleChangePassword(c *fiber.Ctx) error {
+	if impersonating(c) {
+		return refuseImpersonated(c)
+	}
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   "current_password and new_password are required",
+		})
+	}
+
+	token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+	if err := h.authService.ChangePassword(token, req.CurrentPassword, req.NewPassword); err != nil {
+		if strings.Contains(err.Error(), "invalid token") {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid or expired token",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid current password") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Current password is incorrect",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Could not change password",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error changing password of user %v: %v", c.Locals("user_id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not change password",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleLogout revokes the access token the request was made with, and the
+// refresh tokens of the same login.
+func (h *AuthHandler) HandleLogout(c *fiber.Ctx) error {
+	token := strings.TrimPrefix(c.Get("Authorization"), "Bearer ")
+	if err := h.authService.Logout(token); err != nil {
+		if strings.Contains(err.Error(), "invalid token") {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid or expired token",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error logging out user %v: %v", c.Locals("user_id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not log out",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// checkCaptcha verifies the request's CAPTCHA token, read from the body's
+// captcha_token or the X-Captcha-Token header, when CAPTCHA is enabled.
+// It writes the error response and returns false when the check fails.
+func (h *AuthHandler) checkCaptcha(c *fiber.Ctx) bool {
+	if h.captcha == nil {
+		return true
+	}
+	token := c.Get(CaptchaTokenHeader)
+	if token == "" {
+		var req captchaRequest
+		if err := c.BodyParser(&req); err == nil {
+			token = req.CaptchaToken
+		}
+	}
+	err := h.captcha.Verify(c.UserContext(), token, c.IP())
+	if err == nil {
+		return true
+	}
+	if strings.Contains(err.Error(), "invalid captcha") {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "CAPTCHA verification failed",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	log.Printf("Error verifying CAPTCHA: %v", err)
+	c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"message": "Could not verify CAPTCHA, please try again",
+		"error":   err.Error(),
 	})
+	return false
 }
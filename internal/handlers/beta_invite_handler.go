@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// BetaInviteHandler handles admin requests for managing beta invites.
+type BetaInviteHandler struct {
+	service  *services.BetaAccessService
+	validate *validator.Validate
+}
+
+// NewBetaInviteHandler creates a new BetaInviteHandler.
+func NewBetaInviteHandler(service *services.BetaAccessService) *BetaInviteHandler {
+	return &BetaInviteHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// RegisterRoutes registers the invite routes with the admin router.
+func (h *BetaInviteHandler) RegisterRoutes(router fiber.Router) {
+	inviteRoutes := router.Group("/beta-invites")
+	inviteRoutes.Get("/", h.HandleListInvites)
+	inviteRoutes.Post("/", h.HandleInvite)
+	inviteRoutes.Delete("/:email", h.HandleRevoke)
+}
+
+// InviteRequest represents the request body for inviting an email.
+type InviteRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Note  string `json:"note" validate:"omitempty,max=255"`
+}
+
+// HandleListInvites lists all invites and whether beta mode is on.
+func (h *BetaInviteHandler) HandleListInvites(c *fiber.Ctx) error {
+	invites, err := h.service.ListInvites()
+	if err != nil {
+		log.Printf("Error listing beta invites: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve beta invites",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"beta_mode": h.service.Enabled(),
+		"invites":   invites,
+	})
+}
+
+// HandleInvite adds an email to the invite list.
+func (h *BetaInviteHandler) HandleInvite(c *fiber.Ctx) error {
+	var req InviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	}
+
+	adminID, _ := c.Locals("user_id").(string)
+	invite, err := h.service.Invite(req.Email, req.Note, adminID)
+	if err != nil {
+		log.Printf("Error inviting %s: %v", req.Email, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not create beta invite",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(invite)
+}
+
+// HandleRevoke removes an email from the invite list.
+func (h *BetaInviteHandler) HandleRevoke(c *fiber.Ctx) error {
+	email := c.Params("email")
+	if err := h.service.Revoke(email); err != nil {
+		log.Printf("Error revoking beta invite for %s: %v", email, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("No beta invite for %s", email),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not revoke beta invite",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BundleHandler handles "frequently bought together" suggestions.
+type BundleHandler struct {
+	service *services.BundleService
+}
+
+// NewBundleHandler creates a new BundleHandler.
+func NewBundleHandler(service *services.BundleService) *BundleHandler {
+	return &BundleHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the bundle routes with the Fiber app.
+func (h *BundleHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/products/:id/bundle", h.HandleGetBundle)
+}
+
+// HandleGetBundle returns the products frequently bought with a product, the
+// bundle price and the POST /orders body that adds the whole bundle.
+func (h *BundleHandler) HandleGetBundle(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	bundle, err := h.service.SuggestBundle(productID, userID, time.Now())
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Product with ID %s not found", productID),
+			})
+		}
+		log.Printf("Error suggesting bundle for product %s: %v", productID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not suggest a bundle",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(bundle)
+}
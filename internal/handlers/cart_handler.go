@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CartTokenHeader carries the token of an anonymous shopper's cart.
+const CartTokenHeader = "X-Cart-Token"
+
+// CartHandler handles HTTP requests for shopping carts. Logged-in shoppers
+// use their own cart; anonymous shoppers send the token they were given
+// with their first item.
+type CartHandler struct {
+	service *services.CartService
+}
+
+// NewCartHandler creates a new CartHandler.
+func NewCartHandler(service *services.CartService) *CartHandler {
+	return &CartHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the cart routes with a router that identifies
+// logged-in users without requiring them.
+func (h *CartHandler) RegisterRoutes(router fiber.Router) {
+	cartRoutes := router.Group("/cart")
+	cartRoutes.Get("/", h.HandleGetCart)
+	cartRoutes.Delete("/", h.HandleClearCart)
+	cartRoutes.Post("/items", h.HandleAddItem)
+	cartRoutes.Put("/items/:productId", h.HandleSetItemQuantity)
+	cartRoutes.Delete("/items/:productId", h.HandleRemoveItem)
+	cartRoutes.Post("/items/:productId/save-for-later", h.HandleSaveForLater)
+	cartRoutes.Post("/saved/:productId/move-to-cart", h.HandleMoveSavedToCart)
+	cartRoutes.Delete("/saved/:productId", h.HandleRemoveSaved)
+	cartRoutes.Post("/merge", h.HandleMergeCart)
+	cartRoutes.Post("/apply-coupon", h.HandleApplyCoupon)
+	cartRoutes.Post("/share", h.HandleShareCart)
+	cartRoutes.Post("/shared/redeem", h.HandleRedeemSharedCart)
+}
+
+// shopper returns the logged-in user's ID and the anonymous cart token sent with the request.
+func shopper(c *fiber.Ctx) (string, string) {
+	userID, _ := c.Locals("user_id").(string)
+	return userID, strings.TrimSpace(c.Get(CartTokenHeader))
+}
+
+// AddCartItemRequest is the body of a request adding a product to the cart.
+type AddCartItemRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// ApplyCouponRequest is the body of a request previewing a promo code on the cart.
+type ApplyCouponRequest struct {
+	Code string `json:"code"`
+}
+
+// RedeemSharedCartRequest is the body of a request copying a shared cart.
+type RedeemSharedCartRequest struct {
+	Token string `json:"token"`
+}
+
+// SetCartItemRequest is the body of a request changing a cart line's quantity.
+type SetCartItemRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// HandleGetCart returns the shopper's cart.
+func (h *CartHandler) HandleGetCart(c *fiber.Ctx) error {
+	userID, token := shopper(c)
+	cart, err := h.service.GetCart(userID, token)
+	if err != nil {
+		return h.cartError(c, "retrieve", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleAddItem adds units of a product to the cart. Anonymous shoppers
+// without a cart get one, and its token in the X-Cart-Token header.
+func (h *CartHandler) HandleAddItem(c *fiber.Ctx) error {
+	var req AddCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	userID, token := shopper(c)
+	cart, err := h.service.AddItem(userID, token, req.ProductID, req.Quantity)
+	if err != nil {
+		return h.cartError(c, "update", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleSetItemQuantity changes the quantity of a product in the cart.
+func (h *CartHandler) HandleSetItemQuantity(c *fiber.Ctx) error {
+	var req SetCartItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	userID, token := shopper(c)
+	cart, err := h.service.SetItemQuantity(userID, token, c.Params("productId"), req.Quantity)
+	if err != nil {
+		return h.cartError(c, "update", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleRemoveItem removes a product from the cart.
+func (h *CartHandler) HandleRemoveItem(c *fiber.Ctx) error {
+	userID, token := shopper(c)
+	cart, err := h.service.SetItemQuantity(userID, token, c.Params("productId"), 0)
+	if err != nil {
+		return h.cartError(c, "update", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleSaveForLater moves a product from the logged-in user's cart to the
+// saved-for-later section.
+func (h *CartHandler) HandleSaveForLater(c *fiber.Ctx) error {
+	userID, _ := shopper(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Authorization header is required",
+		})
+	}
+	cart, err := h.service.SaveForLater(userID, c.Params("productId"))
+	if err != nil {
+		return h.cartError(c, "update", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleMoveSavedToCart moves a product saved for later back into the cart.
+func (h *CartHandler) HandleMoveSavedToCart(c *fiber.Ctx) error {
+	userID, _ := shopper(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Authorization header is required",
+		})
+	}
+	cart, err := h.service.MoveSavedToCart(userID, c.Params("productId"))
+	if err != nil {
+		return h.cartError(c, "update", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleRemoveSaved removes a product from the saved-for-later section.
+func (h *CartHandler) HandleRemoveSaved(c *fiber.Ctx) error {
+	userID, _ := shopper(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Authorization header is required",
+		})
+	}
+	cart, err := h.service.RemoveSaved(userID, c.Params("productId"))
+	if err != nil {
+		return h.cartError(c, "update", err)
+	}
+	return h.cartResponse(c, cart)
+}
+
+// HandleClearCart empties the cart.
+func (h *CartHandler) HandleClearCart(c *fiber.Ctx) error {
+	userID, token := shopper(c)
+	if err := h.service.Clear(userID, token); err != nil {
+		return h.cartError(c, "clear", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleMergeCart moves the anonymous cart in the X-Cart-Token header into
+// the logged-in user's cart. Clients that log in through /auth/login can
+// send the token there instead.
+func (h *CartHandler) HandleMergeCart(c *fiber.Ctx) error {
+	userID, token := shopper(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "Authorization header is required",
+		})
+	}
+	result, err := h.service.MergeCart(userID, token)
+	if err != nil {
+		return h.cartError(c, "merge", err)
+	}
+	return c.JSON(result)
+}
+
+// HandleApplyCoupon returns the discount a promo code would give on the
+// cart. Nothing is saved; the code must be sent again at checkout.
+func (h *CartHandler) HandleApplyCoupon(c *fiber.Ctx) error {
+	var req ApplyCouponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	userID, token := shopper(c)
+	preview, err := h.service.PreviewCoupon(userID, token, req.Code)
+	if err != nil {
+		return h.cartError(c, "check coupon for", err)
+	}
+	return c.JSON(preview)
+}
+
+// HandleShareCart returns a signed, expiring token others can use to copy
+// the items in the shopper's cart into their own.
+func (h *CartHandler) HandleShareCart(c *fiber.Ctx) error {
+	userID, token := shopper(c)
+	share, err := h.service.ShareCart(userID, token)
+	if err != nil {
+		return h.cartError(c, "share", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(share)
+}
+
+// HandleRedeemSharedCart copies the items of a shared cart into the
+// shopper's cart. Anonymous shoppers without a cart get one, and its token
+// in the X-Cart-Token header.
+func (h *CartHandler) HandleRedeemSharedCart(c *fiber.Ctx) error {
+	var req RedeemSharedCartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	userID, token := shopper(c)
+	result, err := h.service.RedeemShare(userID, token, strings.TrimSpace(req.Token))
+	if err != nil {
+		return h.cartError(c, "copy shared", err)
+	}
+	if result.Cart.IsAnonymous() && result.Cart.Token != "" {
+		c.Set(CartTokenHeader, result.Cart.Token)
+	}
+	return c.JSON(result)
+}
+
+// cartResponse sends the cart, and its token in the X-Cart-Token header for anonymous carts.
+func (h *CartHandler) cartResponse(c *fiber.Ctx, cart *models.Cart) error {
+	if cart.IsAnonymous() && cart.Token != "" {
+		c.Set(CartTokenHeader, cart.Token)
+	}
+	return c.JSON(cart)
+}
+
+func (h *CartHandler) cartError(c *fiber.Ctx, action string, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "insufficient stock") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s cart: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action + " cart",
+		"error":   err.Error(),
+	})
+}
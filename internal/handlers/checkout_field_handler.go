@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckoutFieldHandler tells storefronts which optional checkout fields to ask for.
+type CheckoutFieldHandler struct {
+	fields *services.CheckoutFields
+}
+
+// NewCheckoutFieldHandler creates a new CheckoutFieldHandler.
+func NewCheckoutFieldHandler(fields *services.CheckoutFields) *CheckoutFieldHandler {
+	return &CheckoutFieldHandler{
+		fields: fields,
+	}
+}
+
+// RegisterPublicRoutes registers the checkout field route, which needs no JWT
+// so guest checkouts can render the same form.
+func (h *CheckoutFieldHandler) RegisterPublicRoutes(router fiber.Router) {
+	router.Get("/checkout-fields", h.HandleListFields)
+}
+
+// HandleListFields returns every configurable checkout field with its requirement.
+func (h *CheckoutFieldHandler) HandleListFields(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"fields": h.fields.Fields()})
+}
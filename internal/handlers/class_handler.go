@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClassHandler handles HTTP requests for categories and their tax and shipping classes.
+type ClassHandler struct {
+	service    *services.ClassService
+	taxService *services.TaxService
+	validate   *validator.Validate
+}
+
+// NewClassHandler creates a new ClassHandler.
+func NewClassHandler(service *services.ClassService, taxService *services.TaxService) *ClassHandler {
+	return &ClassHandler{
+		service:    service,
+		taxService: taxService,
+		validate:   validator.New(),
+	}
+}
+
+// RegisterRoutes registers the category and tax quote routes with the Fiber app.
+func (h *ClassHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/categories", h.HandleListCategories)
+	router.Post("/tax/quote", h.HandleTaxQuote)
+}
+
+// RegisterAdminRoutes registers the category and class management routes with the admin router.
+func (h *ClassHandler) RegisterAdminRoutes(router fiber.Router) {
+	categoryRoutes := router.Group("/categories")
+	categoryRoutes.Get("/", h.HandleListCategories)
+	categoryRoutes.Post("/", h.HandleCreateCategory)
+	categoryRoutes.Put("/:id", h.HandleUpdateCategory)
+	categoryRoutes.Delete("/:id", h.HandleDeleteCategory)
+
+	taxRoutes := router.Group("/tax-classes")
+	taxRoutes.Get("/", h.HandleListTaxClasses)
+	taxRoutes.Put("/:code", h.HandleSaveTaxClass)
+	taxRoutes.Delete("/:code", h.HandleDeleteTaxClass)
+
+	shippingRoutes := router.Group("/shipping-classes")
+	shippingRoutes.Get("/", h.HandleListShippingClasses)
+	shippingRoutes.Put("/:code", h.HandleSaveShippingClass)
+	shippingRoutes.Delete("/:code", h.HandleDeleteShippingClass)
+}
+
+// HandleListCategories lists all categories.
+func (h *ClassHandler) HandleListCategories(c *fiber.Ctx) error {
+	categories, err := h.service.ListCategories()
+	if err != nil {
+		return h.classError(c, "retrieve categories", err)
+	}
+	return c.JSON(categories)
+}
+
+// HandleCreateCategory adds a category.
+func (h *ClassHandler) HandleCreateCategory(c *fiber.Ctx) error {
+	var category models.Category
+	if !h.parseBody(c, &category) {
+		return nil
+	}
+	if err := h.service.CreateCategory(&category); err != nil {
+		return h.classError(c, "create category", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(category)
+}
+
+// HandleUpdateCategory replaces a category's name and classes.
+func (h *ClassHandler) HandleUpdateCategory(c *fiber.Ctx) error {
+	var changes models.Category
+	if !h.parseBody(c, &changes) {
+		return nil
+	}
+	category, err := h.service.UpdateCategory(c.Params("id"), &changes)
+	if err != nil {
+		return h.classError(c, "update category", err)
+	}
+	return c.JSON(category)
+}
+
+// HandleDeleteCategory removes a category.
+func (h *ClassHandler) HandleDeleteCategory(c *fiber.Ctx) error {
+	if err := h.service.DeleteCategory(c.Params("id")); err != nil {
+		return h.classError(c, "delete category", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListTaxClasses lists all tax classes.
+func (h *ClassHandler) HandleListTaxClasses(c *fiber.Ctx) error {
+	classes, err := h.service.ListTaxClasses()
+	if err != nil {
+		return h.classError(c, "retrieve tax classes", err)
+	}
+	return c.JSON(classes)
+}
+
+// HandleSaveTaxClass creates or updates the tax class with the code in the path.
+func (h *ClassHandler) HandleSaveTaxClass(c *fiber.Ctx) error {
+	var class models.TaxClass
+	if !h.parseBody(c, &class) {
+		return nil
+	}
+	if err := h.service.SaveTaxClass(c.Params("code"), &class); err != nil {
+		return h.classError(c, "save tax class", err)
+	}
+	return c.JSON(class)
+}
+
+// HandleDeleteTaxClass removes a tax class.
+func (h *ClassHandler) HandleDeleteTaxClass(c *fiber.Ctx) error {
+	if err := h.service.DeleteTaxClass(c.Params("code")); err != nil {
+		return h.classError(c, "delete tax class", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListShippingClasses lists all shipping classes.
+func (h *ClassHandler) HandleListShippingClasses(c *fiber.Ctx) error {
+	classes, err := h.service.ListShippingClasses()
+	if err != nil {
+		return h.classError(c, "retrieve shipping classes", err)
+	}
+	return c.JSON(classes)
+}
+
+// HandleSaveShippingClass creates or updates the shipping class with the code in the path.
+func (h *ClassHandler) HandleSaveShippingClass(c *fiber.Ctx) error {
+	var class models.ShippingClass
+	if !h.parseBody(c, &class) {
+		return nil
+	}
+	if err := h.service.SaveShippingClass(c.Params("code"), &class); err != nil {
+		return h.classError(c, "save shipping class", err)
+	}
+	return c.JSON(class)
+}
+
+// HandleDeleteShippingClass removes a shipping class.
+func (h *ClassHandler) HandleDeleteShippingClass(c *fiber.Ctx) error {
+	if err := h.service.DeleteShippingClass(c.Params("code")); err != nil {
+		return h.classError(c, "delete shipping class", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// TaxQuoteBody is the body of a tax quote request.
+type TaxQuoteBody struct {
+	Items []models.OrderItem `json:"items"`
+}
+
+// HandleTaxQuote returns the tax on a cart before it is ordered.
+func (h *ClassHandler) HandleTaxQuote(c *fiber.Ctx) error {
+	var body TaxQuoteBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	for i := range body.Items {
+		body.Items[i].Price = 0 // Always quote current prices
+	}
+	breakdown, err := h.taxService.Calculate(body.Items)
+	if err != nil {
+		return h.classError(c, "quote tax", err)
+	}
+	return c.JSON(breakdown)
+}
+
+// parseBody binds and validates the request body, writing a 400 response when it is invalid.
+func (h *ClassHandler) parseBody(c *fiber.Ctx, out interface{}) bool {
+	if err := c.BodyParser(out); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	if err := h.validate.Struct(out); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func (h *ClassHandler) classError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	case strings.Contains(err.Error(), "cannot be deleted"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CouponHandler handles admin requests for managing promo codes.
+type CouponHandler struct {
+	service *services.CouponService
+}
+
+// NewCouponHandler creates a new CouponHandler.
+func NewCouponHandler(service *services.CouponService) *CouponHandler {
+	return &CouponHandler{
+		service: service,
+	}
+}
+
+// RegisterAdminRoutes registers the coupon routes with the admin router.
+func (h *CouponHandler) RegisterAdminRoutes(router fiber.Router) {
+	couponRoutes := router.Group("/coupons")
+	couponRoutes.Get("/", h.HandleListCoupons)
+	couponRoutes.Get("/:id", h.HandleGetCoupon)
+	couponRoutes.Post("/", h.HandleCreateCoupon)
+	couponRoutes.Put("/:id", h.HandleUpdateCoupon)
+	couponRoutes.Delete("/:id", h.HandleDeleteCoupon)
+}
+
+// HandleListCoupons lists all coupons with their usage.
+func (h *CouponHandler) HandleListCoupons(c *fiber.Ctx) error {
+	coupons, err := h.service.ListCoupons()
+	if err != nil {
+		return h.couponError(c, "retrieve coupons", err)
+	}
+	return c.JSON(coupons)
+}
+
+// HandleGetCoupon retrieves a single coupon.
+func (h *CouponHandler) HandleGetCoupon(c *fiber.Ctx) error {
+	coupon, err := h.service.GetCoupon(c.Params("id"))
+	if err != nil {
+		return h.couponError(c, "retrieve coupon", err)
+	}
+	return c.JSON(coupon)
+}
+
+// HandleCreateCoupon adds a coupon.
+func (h *CouponHandler) HandleCreateCoupon(c *fiber.Ctx) error {
+	var coupon models.Coupon
+	if err := c.BodyParser(&coupon); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.service.CreateCoupon(&coupon); err != nil {
+		return h.couponError(c, "create coupon", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(coupon)
+}
+
+// HandleUpdateCoupon replaces a coupon's settings.
+func (h *CouponHandler) HandleUpdateCoupon(c *fiber.Ctx) error {
+	var changes models.Coupon
+	if err := c.BodyParser(&changes); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	coupon, err := h.service.UpdateCoupon(c.Params("id"), &changes)
+	if err != nil {
+		return h.couponError(c, "update coupon", err)
+	}
+	return c.JSON(coupon)
+}
+
+// HandleDeleteCoupon removes a coupon.
+func (h *CouponHandler) HandleDeleteCoupon(c *fiber.Ctx) error {
+	if err := h.service.DeleteCoupon(c.Params("id")); err != nil {
+		return h.couponError(c, "delete coupon", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CouponHandler) couponError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action,
+		"error":   err.Error(),
+	})
+}
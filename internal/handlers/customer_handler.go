@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CustomerHandler handles admin requests for the internal tags and notes kept on customers.
+type CustomerHandler struct {
+	service *services.CustomerService
+}
+
+// NewCustomerHandler creates a new CustomerHandler.
+func NewCustomerHandler(service *services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the customer routes with the admin router.
+func (h *CustomerHandler) RegisterRoutes(router fiber.Router) {
+	customerRoutes := router.Group("/customers/:id")
+	customerRoutes.Get("/", h.HandleGetProfile)
+	customerRoutes.Post("/tags", h.HandleAddTag)
+	customerRoutes.Delete("/tags/:tag", h.HandleRemoveTag)
+	customerRoutes.Post("/notes", h.HandleAddNote)
+	customerRoutes.Delete("/notes/:noteId", h.HandleDeleteNote)
+}
+
+// CustomerTagRequest is the body of a request to tag a customer.
+type CustomerTagRequest struct {
+	Tag string `json:"tag"`
+}
+
+// CustomerNoteRequest is the body of a request to add a note to a customer.
+type CustomerNoteRequest struct {
+	Body string `json:"body"`
+}
+
+// HandleGetProfile returns a customer's tags, notes and logins.
+func (h *CustomerHandler) HandleGetProfile(c *fiber.Ctx) error {
+	profile, err := h.service.Profile(c.Params("id"))
+	if err != nil {
+		return h.customerError(c, "retrieve customer", err)
+	}
+	return c.JSON(profile)
+}
+
+// HandleAddTag tags a customer.
+func (h *CustomerHandler) HandleAddTag(c *fiber.Ctx) error {
+	var body CustomerTagRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	staffID, _ := c.Locals("user_id").(string)
+	if err := h.service.AddTag(c.Params("id"), body.Tag, staffID); err != nil {
+		return h.customerError(c, "tag customer", err)
+	}
+	return h.HandleGetProfile(c)
+}
+
+// HandleRemoveTag removes a tag from a customer.
+func (h *CustomerHandler) HandleRemoveTag(c *fiber.Ctx) error {
+	if err := h.service.RemoveTag(c.Params("id"), c.Params("tag")); err != nil {
+		return h.customerError(c, "remove tag", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleAddNote adds an internal note to a customer.
+func (h *CustomerHandler) HandleAddNote(c *fiber.Ctx) error {
+	var body CustomerNoteRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	staffID, _ := c.Locals("user_id").(string)
+	note, err := h.service.AddNote(c.Params("id"), body.Body, staffID)
+	if err != nil {
+		return h.customerError(c, "add note", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(note)
+}
+
+// HandleDeleteNote removes a customer's note.
+func (h *CustomerHandler) HandleDeleteNote(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("noteId")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid note ID",
+		})
+	}
+	if err := h.service.DeleteNote(c.Params("id"), uint(id)); err != nil {
+		return h.customerError(c, "delete note", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CustomerHandler) customerError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"time"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultSlotWindow is how far ahead slots are listed when no end date is given.
+const defaultSlotWindow = 14 * 24 * time.Hour
+
+// DeliverySlotHandler handles HTTP requests for delivery windows.
+type DeliverySlotHandler struct {
+	service  *services.DeliverySlotService
+	validate *validator.Validate
+}
+
+// NewDeliverySlotHandler creates a new DeliverySlotHandler.
+func NewDeliverySlotHandler(service *services.DeliverySlotService) *DeliverySlotHandler {
+	return &DeliverySlotHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// RegisterRoutes registers the customer-facing slot routes.
+func (h *DeliverySlotHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/shipping/slots", h.HandleAvailableSlots)
+}
+
+// RegisterAdminRoutes registers the delivery slot management routes with the admin router.
+func (h *DeliverySlotHandler) RegisterAdminRoutes(router fiber.Router) {
+	slotRoutes := router.Group("/delivery-slots")
+	slotRoutes.Get("/", h.HandleListSlots)
+	slotRoutes.Post("/", h.HandleCreateSlot)
+	slotRoutes.Put("/:id", h.HandleUpdateSlot)
+	slotRoutes.Delete("/:id", h.HandleDeleteSlot)
+}
+
+// HandleAvailableSlots lists the bookable slots between the from and to
+// query parameters, by default the next two weeks.
+func (h *DeliverySlotHandler) HandleAvailableSlots(c *fiber.Ctx) error {
+	from, to, ok := h.parseRange(c)
+	if !ok {
+		return nil
+	}
+	slots, err := h.service.AvailableSlots(from, to)
+	if err != nil {
+		return h.slotError(c, "retrieve delivery slots", err)
+	}
+	return c.JSON(slots)
+}
+
+// HandleListSlots lists all slots in the range, including full and inactive ones.
+func (h *DeliverySlotHandler) HandleListSlots(c *fiber.Ctx) error {
+	from, to, ok := h.parseRange(c)
+	if !ok {
+		return nil
+	}
+	slots, err := h.service.ListSlots(from, to)
+	if err != nil {
+		return h.slotError(c, "retrieve delivery slots", err)
+	}
+	return c.JSON(slots)
+}
+
+// HandleCreateSlot adds a slot.
+func (h *DeliverySlotHandler) HandleCreateSlot(c *fiber.Ctx) error {
+	var slot models.DeliverySlot
+	if !h.parseBody(c, &slot) {
+		return nil
+	}
+	if err := h.service.CreateSlot(&slot); err != nil {
+		return h.slotError(c, "create delivery slot", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(slot)
+}
+
+// HandleUpdateSlot changes a slot.
+func (h *DeliverySlotHandler) HandleUpdateSlot(c *fiber.Ctx) error {
+	var changes models.DeliverySlot
+	if !h.parseBody(c, &changes) {
+		return nil
+	}
+	slot, err := h.service.UpdateSlot(c.Params("id"), &changes)
+	if err != nil {
+		return h.slotError(c, "update delivery slot", err)
+	}
+	return c.JSON(slot)
+}
+
+// HandleDeleteSlot removes a slot without bookings.
+func (h *DeliverySlotHandler) HandleDeleteSlot(c *fiber.Ctx) error {
+	if err := h.service.DeleteSlot(c.Params("id")); err != nil {
+		return h.slotError(c, "delete delivery slot", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseRange reads the from and to query parameters, writing a 400 response when they are invalid.
+func (h *DeliverySlotHandler) parseRange(c *fiber.Ctx) (time.Time, time.Time, bool) {
+	from, err := parseDateParam(c.Query("from"), false)
+	if err == nil && from.IsZero() {
+		from = time.Now()
+	}
+	var to time.Time
+	if err == nil {
+		to, err = parseDateParam(c.Query("to"), true)
+	}
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid date range",
+			"error":   err.Error(),
+		})
+		return from, to, false
+	}
+	if to.IsZero() {
+		to = from.Add(defaultSlotWindow)
+	}
+	return from, to, true
+}
+
+// parseBody binds and validates the request body, writing a 400 response when it is invalid.
+func (h *DeliverySlotHandler) parseBody(c *fiber.Ctx, out interface{}) bool {
+	if err := c.BodyParser(out); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	if err := h.validate.Struct(out); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func (h *DeliverySlotHandler) slotError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action,
+		"error":   err.Error(),
+	})
+}
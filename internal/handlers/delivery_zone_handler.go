@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeliveryZoneHandler handles admin requests for delivery zones.
+type DeliveryZoneHandler struct {
+	service  *services.DeliveryZoneService
+	validate *validator.Validate
+}
+
+// NewDeliveryZoneHandler creates a new DeliveryZoneHandler.
+func NewDeliveryZoneHandler(service *services.DeliveryZoneService) *DeliveryZoneHandler {
+	return &DeliveryZoneHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// RegisterAdminRoutes registers the delivery zone management routes with the admin router.
+func (h *DeliveryZoneHandler) RegisterAdminRoutes(router fiber.Router) {
+	zoneRoutes := router.Group("/delivery-zones")
+	zoneRoutes.Get("/", h.HandleListZones)
+	zoneRoutes.Post("/", h.HandleCreateZone)
+	zoneRoutes.Put("/:id", h.HandleUpdateZone)
+	zoneRoutes.Delete("/:id", h.HandleDeleteZone)
+}
+
+// HandleListZones lists all zones in the order they are matched.
+func (h *DeliveryZoneHandler) HandleListZones(c *fiber.Ctx) error {
+	zones, err := h.service.ListZones()
+	if err != nil {
+		return h.zoneError(c, "retrieve delivery zones", err)
+	}
+	return c.JSON(zones)
+}
+
+// HandleCreateZone adds a zone.
+func (h *DeliveryZoneHandler) HandleCreateZone(c *fiber.Ctx) error {
+	var zone models.DeliveryZone
+	if !h.parseBody(c, &zone) {
+		return nil
+	}
+	if err := h.service.CreateZone(&zone); err != nil {
+		return h.zoneError(c, "create delivery zone", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(zone)
+}
+
+// HandleUpdateZone replaces a zone.
+func (h *DeliveryZoneHandler) HandleUpdateZone(c *fiber.Ctx) error {
+	var changes models.DeliveryZone
+	if !h.parseBody(c, &changes) {
+		return nil
+	}
+	zone, err := h.service.UpdateZone(c.Params("id"), &changes)
+	if err != nil {
+		return h.zoneError(c, "update delivery zone", err)
+	}
+	return c.JSON(zone)
+}
+
+// HandleDeleteZone removes a zone.
+func (h *DeliveryZoneHandler) HandleDeleteZone(c *fiber.Ctx) error {
+	if err := h.service.DeleteZone(c.Params("id")); err != nil {
+		return h.zoneError(c, "delete delivery zone", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseBody binds and validates the request body, writing a 400 response when it is invalid.
+func (h *DeliveryZoneHandler) parseBody(c *fiber.Ctx, out interface{}) bool {
+	if err := c.BodyParser(out); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	if err := h.validate.Struct(out); err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func (h *DeliveryZoneHandler) zoneError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action,
+		"error":   err.Error(),
+	})
+}
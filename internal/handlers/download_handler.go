@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DownloadHandler handles HTTP requests for digital product files and downloads.
+type DownloadHandler struct {
+	downloadService *services.DownloadService
+	orderService    *services.OrderService
+}
+
+// NewDownloadHandler creates a new DownloadHandler.
+func NewDownloadHandler(downloadService *services.DownloadService, orderService *services.OrderService) *DownloadHandler {
+	return &DownloadHandler{
+		downloadService: downloadService,
+		orderService:    orderService,
+	}
+}
+
+// RegisterRoutes registers the authenticated download routes with the Fiber app.
+func (h *DownloadHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/orders/:id/downloads", h.HandleGetOrderDownloads)
+}
+
+// RegisterAdminRoutes registers the product file upload route with the admin router.
+func (h *DownloadHandler) RegisterAdminRoutes(router fiber.Router) {
+	router.Post("/products/:id/file", h.HandleUploadProductFile)
+}
+
+// RegisterPublicRoutes registers the signed download route, which needs no JWT.
+func (h *DownloadHandler) RegisterPublicRoutes(router fiber.Router) {
+	router.Get("/downloads/:orderID/:productID", h.HandleDownload)
+}
+
+// HandleUploadProductFile attaches a file (multipart field "file") to a digital product.
+func (h *DownloadHandler) HandleUploadProductFile(c *fiber.Ctx) error {
+	productID := c.Params("id")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "A multipart 'file' field is required",
+			"error":   err.Error(),
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("Error opening uploaded file for product %s: %v", productID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Could not read uploaded file",
+			"error":   err.Error(),
+		})
+	}
+	defer file.Close()
+
+	product, err := h.downloadService.AttachFile(productID, fileHeader.Filename, file)
+	if err != nil {
+		log.Printf("Error attaching file to product %s: %v", productID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Product with ID %s not found", productID),
+			})
+		}
+		if strings.Contains(err.Error(), "not a digital product") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Files can only be attached to digital products",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not attach file",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(product)
+}
+
+// HandleGetOrderDownloads returns fresh signed download links for an order's digital items.
+func (h *DownloadHandler) HandleGetOrderDownloads(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	order, err := h.orderService.GetOrderByID(orderID)
+	if err != nil || order.UserID != c.Locals("user_id") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": fmt.Sprintf("Order with ID %s not found", orderID),
+		})
+	}
+
+	links, err := h.downloadService.GenerateLinks(order)
+	if err != nil {
+		log.Printf("Error generating download links for order %s: %v", orderID, err)
+		if strings.Contains(err.Error(), "cancelled") || strings.Contains(err.Error(), "not paid") || strings.Contains(err.Error(), "returned") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "Downloads are only available for paid orders that were not cancelled or returned",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not generate download links",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"order_id":  orderID,
+		"downloads": links,
+	})
+}
+
+// HandleDownload streams a purchased file after verifying the link signature.
+func (h *DownloadHandler) HandleDownload(c *fiber.Ctx) error {
+	orderID := c.Params("orderID")
+	productID := c.Params("productID")
+
+	file, fileName, err := h.downloadService.OpenDownload(orderID, productID, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		log.Printf("Error serving download for order %s product %s: %v", orderID, productID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Download not found",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Download link is invalid or has expired",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Attachment(fileName)
+	return c.SendStream(file)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ERPSyncHandler handles admin requests for the ERP sync connectors, their
+// state and the conflict log.
+type ERPSyncHandler struct {
+	service *services.ERPSyncService
+}
+
+// NewERPSyncHandler creates a new ERPSyncHandler.
+func NewERPSyncHandler(service *services.ERPSyncService) *ERPSyncHandler {
+	return &ERPSyncHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the ERP sync routes with the admin router.
+func (h *ERPSyncHandler) RegisterRoutes(router fiber.Router) {
+	syncRoutes := router.Group("/erp-sync")
+	syncRoutes.Get("/", h.HandleGetStatus)
+	syncRoutes.Get("/conflicts", h.HandleListConflicts)
+	syncRoutes.Post("/:connector/run", h.HandleRun)
+}
+
+// HandleGetStatus lists the connectors and the state of their last runs.
+func (h *ERPSyncHandler) HandleGetStatus(c *fiber.Ctx) error {
+	states, err := h.service.States()
+	if err != nil {
+		log.Printf("Error listing ERP sync states: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve sync state",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"connectors": h.service.Connectors(),
+		"states":     states,
+	})
+}
+
+// HandleListConflicts lists logged conflicts, newest first (?connector=&page=&page_size=).
+// The total number of conflicts is returned in the X-Total-Count header.
+func (h *ERPSyncHandler) HandleListConflicts(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", 50)
+	if page < 1 || pageSize < 1 || pageSize > 200 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "page must be at least 1 and page_size between 1 and 200",
+		})
+	}
+	conflicts, total, err := h.service.Conflicts(repositories.SyncConflictListOptions{
+		Connector: c.Query("connector"),
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	})
+	if err != nil {
+		log.Printf("Error listing ERP sync conflicts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve sync conflicts",
+			"error":   err.Error(),
+		})
+	}
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	return c.JSON(conflicts)
+}
+
+// HandleRun runs a connector now instead of waiting for its schedule.
+func (h *ERPSyncHandler) HandleRun(c *fiber.Ctx) error {
+	name := c.Params("connector")
+	if err := h.service.Sync(c.UserContext(), name, time.Now()); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("ERP connector %s not found", name),
+			})
+		}
+		log.Printf("Error running ERP connector %s: %v", name, err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"message": "Sync failed",
+			"error":   err.Error(),
+		})
+	}
+	return h.HandleGetStatus(c)
+}
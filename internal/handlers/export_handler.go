@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportHandler handles HTTP requests for background exports and their job status.
+type ExportHandler struct {
+	service *services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler.
+func NewExportHandler(service *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the authenticated export routes with the Fiber app.
+func (h *ExportHandler) RegisterRoutes(router fiber.Router) {
+	router.Post("/exports", h.HandleStartExport)
+	router.Get("/jobs/:id", h.HandleGetJob)
+	router.Get("/users/me/export", h.HandleExportPersonalData)
+}
+
+// RegisterAdminRoutes registers the streaming order export with the admin router.
+func (h *ExportHandler) RegisterAdminRoutes(router fiber.Router) {
+	router.Get("/orders/export", h.HandleExportOrdersCSV)
+}
+
+// RegisterPublicRoutes registers the signed export download route, which needs no JWT.
+func (h *ExportHandler) RegisterPublicRoutes(router fiber.Router) {
+	router.Get("/jobs/:id/download", h.HandleDownloadExport)
+}
+
+// StartExportRequest represents the request body for starting an export.
+type StartExportRequest struct {
+	Type string `json:"type"` // "orders", "products" or "gdpr"
+}
+
+// HandleStartExport queues an export and immediately returns its job ID.
+func (h *ExportHandler) HandleStartExport(c *fiber.Ctx) error {
+	var req StartExportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	job, err := h.service.StartExport(userID, req.Type)
+	if err != nil {
+		log.Printf("Error starting %s export: %v", req.Type, err)
+		if strings.Contains(err.Error(), "invalid export type") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "type must be one of: orders, products, gdpr",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not start export",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"job_id":     job.ID,
+		"status":     job.Status,
+		"status_url": fmt.Sprintf("/api/v1/jobs/%s", job.ID),
+	})
+}
+
+// HandleExportPersonalData queues a ZIP archive of the user's personal data
+// and returns its job ID; the job gets a download link once it completes.
+func (h *ExportHandler) HandleExportPersonalData(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	job, err := h.service.StartExport(userID, models.ExportTypeGDPR)
+	if err != nil {
+		log.Printf("Error starting personal data export: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not start export",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"job_id":     job.ID,
+		"status":     job.Status,
+		"status_url": fmt.Sprintf("/api/v1/jobs/%s", job.ID),
+	})
+}
+
+// HandleGetJob returns the status and progress of an export job.
+func (h *ExportHandler) HandleGetJob(c *fiber.Ctx) error {
+	jobID := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	job, err := h.service.GetJob(jobID, userID)
+	if err != nil {
+		log.Printf("Error getting job %s: %v", jobID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Job with ID %s not found", jobID),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve job",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(job)
+}
+
+// HandleDownloadExport streams a completed export after verifying the link signature.
+func (h *ExportHandler) HandleDownloadExport(c *fiber.Ctx) error {
+	jobID := c.Params("id")
+
+	job, file, err := h.service.OpenDownload(jobID, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		log.Printf("Error serving export %s: %v", jobID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Export not found",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Download link is invalid or has expired",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Attachment(job.FileName)
+	return c.SendStream(file)
+}
+
+// HandleExportOrdersCSV streams orders with their items and totals as CSV for
+// accounting (?from=&to=&status=). Dates default to the last 30 days.
+func (h *ExportHandler) HandleExportOrdersCSV(c *fiber.Ctx) error {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Dates must use the YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	write, err := h.service.StreamOrdersCSV(repositories.OrderListOptions{
+		Status: c.Query("status"),
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(fmt.Sprintf("orders-%s.csv", time.Now().Format("20060102")))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The status line is already sent, so a failure can only cut the file short
+		if err := write(w); err != nil {
+			log.Printf("Error streaming order export: %v", err)
+		}
+	})
+	return nil
+}
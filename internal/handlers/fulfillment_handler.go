@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FulfillmentHandler handles admin requests for warehouse bin locations,
+// packing slips and pick lists.
+type FulfillmentHandler struct {
+	service *services.FulfillmentService
+}
+
+// NewFulfillmentHandler creates a new FulfillmentHandler.
+func NewFulfillmentHandler(service *services.FulfillmentService) *FulfillmentHandler {
+	return &FulfillmentHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the fulfillment routes with the admin router.
+func (h *FulfillmentHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/products/:id/locations", h.HandleListLocations)
+	router.Put("/products/:id/locations/:warehouse", h.HandleSetLocation)
+	router.Delete("/products/:id/locations/:warehouse", h.HandleDeleteLocation)
+	router.Get("/orders/:id/packing-slip", h.HandlePackingSlip)
+	router.Get("/pick-list", h.HandlePickList)
+}
+
+// HandleListLocations returns where a product is kept in each warehouse.
+func (h *FulfillmentHandler) HandleListLocations(c *fiber.Ctx) error {
+	productID := c.Params("id")
+	locations, err := h.service.ListLocations(productID)
+	if err != nil {
+		return h.locationError(c, err)
+	}
+	return c.JSON(locations)
+}
+
+// HandleSetLocation stores a product's aisle, shelf and bin in a warehouse.
+func (h *FulfillmentHandler) HandleSetLocation(c *fiber.Ctx) error {
+	var location models.BinLocation
+	if err := c.BodyParser(&location); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.service.SetLocation(c.Params("id"), c.Params("warehouse"), &location); err != nil {
+		return h.locationError(c, err)
+	}
+	return c.JSON(location)
+}
+
+// HandleDeleteLocation removes a product's location in a warehouse.
+func (h *FulfillmentHandler) HandleDeleteLocation(c *fiber.Ctx) error {
+	if err := h.service.DeleteLocation(c.Params("id"), c.Params("warehouse")); err != nil {
+		return h.locationError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandlePackingSlip downloads the PDF packing slip of an order (?warehouse=).
+func (h *FulfillmentHandler) HandlePackingSlip(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	slip, err := h.service.PackingSlip(orderID, c.Query("warehouse"))
+	if err != nil {
+		log.Printf("Error rendering packing slip for order %s: %v", orderID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not render packing slip",
+			"error":   err.Error(),
+		})
+	}
+	c.Attachment(fmt.Sprintf("packing-slip-%s.pdf", orderID))
+	c.Type("pdf")
+	return c.Send(slip)
+}
+
+// HandlePickList returns what to pick for orders waiting to be shipped
+// (?warehouse=&limit=100&format=pdf). JSON is returned unless format=pdf.
+func (h *FulfillmentHandler) HandlePickList(c *fiber.Ctx) error {
+	list, err := h.service.OpenOrderPickList(c.Query("warehouse"), c.QueryInt("limit", 100))
+	if err != nil {
+		log.Printf("Error building pick list: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not build pick list",
+			"error":   err.Error(),
+		})
+	}
+	if c.Query("format") == "pdf" {
+		c.Attachment(fmt.Sprintf("pick-list-%s.pdf", list.Warehouse))
+		c.Type("pdf")
+		return c.Send(h.service.PickListPDF(list))
+	}
+	return c.JSON(list)
+}
+
+func (h *FulfillmentHandler) locationError(c *fiber.Ctx, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if strings.Contains(err.Error(), "invalid location") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error managing bin location: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not manage location",
+		"error":   err.Error(),
+	})
+}
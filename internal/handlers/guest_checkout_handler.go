@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GuestCheckoutHandler handles orders placed without an account.
+type GuestCheckoutHandler struct {
+	service *services.GuestCheckoutService
+}
+
+// NewGuestCheckoutHandler creates a new GuestCheckoutHandler.
+func NewGuestCheckoutHandler(service *services.GuestCheckoutService) *GuestCheckoutHandler {
+	return &GuestCheckoutHandler{
+		service: service,
+	}
+}
+
+// RegisterPublicRoutes registers the guest checkout route, which needs no JWT.
+func (h *GuestCheckoutHandler) RegisterPublicRoutes(router fiber.Router) {
+	router.Post("/checkout/guest", h.HandleGuestCheckout)
+}
+
+// GuestCheckoutRequest represents the request body of a guest order.
+type GuestCheckoutRequest struct {
+	Email           string               `json:"email"`
+	Items           []models.OrderItem   `json:"items"`
+	ShippingAddress models.PostalAddress `json:"shipping_address"`
+	BillingAddress  models.PostalAddress `json:"billing_address"` // Defaults to the shipping address
+	CouponCode      string               `json:"coupon_code"`
+	IsGift          bool                 `json:"is_gift"`
+	GiftMessage     string               `json:"gift_message"`
+	ShippingMethod  string               `json:"shipping_method"`
+	CompanyName     string               `json:"company_name"`
+	TaxID           string               `json:"tax_id"`
+}
+
+// HandleGuestCheckout places an order for an email address and returns it with
+// a signed status link the guest can use to follow the order.
+func (h *GuestCheckoutHandler) HandleGuestCheckout(c *fiber.Ctx) error {
+	var request GuestCheckoutRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if request.Email == "" || len(request.Items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "email and at least one item are required for an order.",
+		})
+	}
+
+	checkout, err := h.service.PlaceOrder(c.UserContext(), request.Email, models.Order{
+		Items:           request.Items,
+		ShippingAddress: request.ShippingAddress,
+		BillingAddress:  request.BillingAddress,
+		CouponCode:      request.CouponCode,
+		IsGift:          request.IsGift,
+		GiftMessage:     request.GiftMessage,
+		ShippingMethod:  request.ShippingMethod,
+		CompanyName:     request.CompanyName,
+		TaxID:           request.TaxID,
+	})
+	if err != nil {
+		log.Printf("Error placing guest order: %v", err)
+		if strings.Contains(err.Error(), "already registered") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "insufficient stock") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Could not place order",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not create order",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(checkout)
+}
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,12 +11,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"toko/internal/handlers"
 	"toko/internal/middleware"
 	"toko/internal/models"
 	"toko/internal/repositories"
 	"toko/internal/services"
+	"toko/pkg/geoip"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
@@ -38,7 +41,7 @@ func setupApp() (*fiber.App, *services.AuthService, error) {
 	}
 
 	// Auto-migrate models
-	err = db.AutoMigrate(&models.Product{}, &models.User{})
+	err = db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}, &models.Order{}, &models.OrderItem{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to auto-migrate database: %w", err)
 	}
@@ -49,13 +52,13 @@ func setupApp() (*fiber.App, *services.AuthService, error) {
 	orderRepo := repositories.NewMockOrderRepository() // Using mock for order for simplicity in this test
 
 	// Initialize Services
-	productService := services.NewProductService(productRepo)
-	orderService := services.NewOrderService(orderRepo, productRepo, nil) // nil for RabbitMQ client
+	productService := services.NewProductService(productRepo, nil)
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 15*time.Minute) // nil for RabbitMQ client
 	authService := services.NewAuthService(userRepo, jwtSecret)
 
 	// Initialize Handlers
 	productHandler := handlers.NewProductHandler(productService)
-	orderHandler := handlers.NewOrderHandler(orderService)
+	orderHandler := handlers.NewOrderHandler(orderService, authService, services.NewAddressService(repositories.NewMockAddressRepository()))
 	authHandler := handlers.NewAuthHandler(authService)
 
 	app := fiber.New()
@@ -74,6 +77,17 @@ func setupApp() (*fiber.App, *services.AuthService, error) {
 	// Register order routes
 	orderHandler.RegisterRoutes(protectedRoutes)
 
+	// Admin routes, with the catalog and order permissions used in main.go
+	adminRoutes := apiV1.Group("/admin",
+		middleware.AuthRequired(authService),
+		middleware.PermissionPolicy(authService, "/api/v1/admin", []middleware.PermissionRule{
+			{Path: "/orders", Permission: models.PermissionOrdersWrite},
+			{Path: "/products", Permission: models.PermissionProductsWrite},
+		}),
+	)
+	productHandler.RegisterAdminRoutes(adminRoutes)
+	orderHandler.RegisterAdminRoutes(adminRoutes)
+
 	// Seed some initial products (optional, but good for testing GET all)
 	seedProductsForTest(productRepo)
 
@@ -163,7 +177,7 @@ func TestAuthRegisterAndLogin(t *testing.T) {
 }
 
 func TestToProductEndpointsWithoutAuth(t *testing.T) {
-	app, _, err := setupApp()
+	app, authService, err := setupApp()
 	assert.NoError(t, err)
 
 	// First, register and log in a user to get a token
@@ -197,6 +211,28 @@ func TestToProductEndpointsWithoutAuth(t *testing.T) {
 	assert.NotEmpty(t, token)
 	resp.Body.Close()
 
+	// Customers cannot change the catalog or mark orders paid
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/products", bytes.NewReader([]byte(`{"name":"Free","price":1,"stock":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+	req = httptest.NewRequest(http.MethodPatch, "/api/v1/admin/orders/some-order/status", bytes.NewReader([]byte(`{"status":"processing"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	// Catalog staff can
+	claims, err := authService.ValidateToken(token)
+	assert.NoError(t, err)
+	_, err = authService.SetUserAccess(claims["user_id"].(string), models.RoleSupport, []string{models.PermissionProductsWrite})
+	assert.NoError(t, err)
+
 	// --- Test GET /products (protected) ---
 	req = httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
 	req.Header.Set("Authorization", "Bearer "+token)
@@ -209,7 +245,7 @@ func TestToProductEndpointsWithoutAuth(t *testing.T) {
 	assert.GreaterOrEqual(t, len(products), 2) // Should contain seeded products
 	resp.Body.Close()
 
-	// --- Test POST /products (protected) ---
+	// --- Test POST /admin/products (products:write) ---
 	newProduct := map[string]interface{}{
 		"name":        "Smartphone",
 		"description": "Latest model smartphone",
@@ -217,7 +253,7 @@ func TestToProductEndpointsWithoutAuth(t *testing.T) {
 		"stock":       50,
 	}
 	jsonBody, _ = json.Marshal(newProduct)
-	req = httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewReader(jsonBody))
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/products", bytes.NewReader(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
@@ -243,7 +279,7 @@ func TestToProductEndpointsWithoutAuth(t *testing.T) {
 	assert.Equal(t, createdProduct.ID, fetchedProduct.ID)
 	resp.Body.Close()
 
-	// --- Test PUT /products/:id (protected) ---
+	// --- Test PUT /admin/products/:id (products:write) ---
 	updatedProductData := map[string]interface{}{
 		"name":        "Smartphone Pro",
 		"description": "Latest model smartphone pro edition",
@@ -251,7 +287,7 @@ func TestToProductEndpointsWithoutAuth(t *testing.T) {
 		"stock":       45,
 	}
 	jsonBody, _ = json.Marshal(updatedProductData)
-	req = httptest.NewRequest(http.MethodPut, "/api/v1/products/"+createdProduct.ID, bytes.NewReader(jsonBody))
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/admin/products/"+createdProduct.ID, bytes.NewReader(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
@@ -265,8 +301,32 @@ func TestToProductEndpointsWithoutAuth(t *testing.T) {
 	assert.Equal(t, updatedProductData["name"], updatedProduct.Name)
 	resp.Body.Close()
 
-	// --- Test DELETE /products/:id (protected) ---
-	req = httptest.NewRequest(http.MethodDelete, "/api/v1/products/"+createdProduct.ID, nil)
+	// --- Test archiving: archived products are only listed for staff ---
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/products/"+createdProduct.ID+"/archive", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	listedIDs := func(path string) []string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var listed []models.Product
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&listed))
+		ids := []string{}
+		for _, product := range listed {
+			ids = append(ids, product.ID)
+		}
+		return ids
+	}
+	assert.NotContains(t, listedIDs("/api/v1/products?include_archived=true"), createdProduct.ID)
+	assert.Contains(t, listedIDs("/api/v1/admin/products"), createdProduct.ID)
+
+	// --- Test DELETE /admin/products/:id (products:write) ---
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/products/"+createdProduct.ID, nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 	resp, err = app.Test(req, -1)
 	assert.NoError(t, err)
@@ -311,3 +371,356 @@ func TestProductEndpointsWithoutAuth(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	resp.Body.Close()
 }
+
+// captchaFunc adapts a function to captcha.Verifier.
+type captchaFunc func(ctx context.Context, token, remoteIP string) error
+
+func (f captchaFunc) Verify(ctx context.Context, token, remoteIP string) error {
+	return f(ctx, token, remoteIP)
+}
+
+func TestAuthCaptcha(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:captcha?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.User{}))
+	authHandler := handlers.NewAuthHandler(services.NewAuthService(repositories.NewGORMUserRepository(db), "test_jwt_secret"))
+	authHandler.SetCaptcha(captchaFunc(func(ctx context.Context, token, remoteIP string) error {
+		switch token {
+		case "human":
+			return nil
+		case "outage":
+			return fmt.Errorf("recaptcha: connection refused")
+		}
+		return fmt.Errorf("invalid captcha: rejected by recaptcha")
+	}))
+	app := fiber.New()
+	authHandler.RegisterRoutes(app.Group("/api/v1"))
+
+	post := func(path string, body map[string]string, header string) int {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set(handlers.CaptchaTokenHeader, header)
+		}
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	user := map[string]string{"username": "captchauser", "email": "captcha@example.com", "password": "password123"}
+	assert.Equal(t, http.StatusBadRequest, post("/api/v1/auth/register", user, ""))
+	assert.Equal(t, http.StatusServiceUnavailable, post("/api/v1/auth/register", user, "outage"))
+	user["captcha_token"] = "human"
+	assert.Equal(t, http.StatusCreated, post("/api/v1/auth/register", user, ""))
+
+	login := map[string]string{"username": "captchauser", "password": "password123"}
+	assert.Equal(t, http.StatusBadRequest, post("/api/v1/auth/login", login, "bot"))
+	assert.Equal(t, http.StatusOK, post("/api/v1/auth/login", login, "human"))
+}
+
+func TestGuestBrowse(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:guests?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}))
+	productRepo := repositories.NewGORMProductRepository(db)
+	seedProductsForTest(productRepo)
+	authService := services.NewAuthService(repositories.NewGORMUserRepository(db), "test_jwt_secret")
+	authService.SetGuestTokens(time.Hour)
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 15*time.Minute)
+
+	app := fiber.New()
+	apiV1 := app.Group("/api/v1")
+	handlers.NewAuthHandler(authService).RegisterRoutes(apiV1)
+	protectedRoutes := apiV1.Group("", middleware.GuestBrowse(authService, "/api/v1", []string{"/products", "/products/:id"},
+		middleware.RateLimitConfig{Max: 3, Window: time.Minute}))
+	handlers.NewProductHandler(services.NewProductService(productRepo, nil)).RegisterRoutes(protectedRoutes)
+	// Stands in for the product routes guests are not listed for, such as license keys
+	protectedRoutes.Get("/products/:id/license-keys", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	handlers.NewOrderHandler(orderService, authService, services.NewAddressService(repositories.NewMockAddressRepository())).RegisterRoutes(protectedRoutes)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/v1/auth/guest-token", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var guest services.GuestToken
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&guest))
+	resp.Body.Close()
+	assert.NotEmpty(t, guest.GuestID)
+
+	request := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+guest.Token)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	products, err := productRepo.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/products"))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPost, "/api/v1/products"), "guests cannot change the catalog")
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/orders"), "guest tokens are not access tokens")
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/products/"+products[0].ID+"/license-keys"), "only the listed routes are open to guests")
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/products/"+products[0].ID))
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/products"))
+	assert.Equal(t, http.StatusTooManyRequests, request(http.MethodGet, "/api/v1/products"))
+}
+
+// setupCheckout returns an app serving the customer order routes, backed by
+// its own in-memory database, and a logged-in customer's token.
+func setupCheckout(t *testing.T, name string) (*fiber.App, *services.OrderService, repositories.ProductRepository, *repositories.MockAddressRepository, *models.User, string) {
+	db, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}))
+	productRepo := repositories.NewGORMProductRepository(db)
+	seedProductsForTest(productRepo)
+	authService := services.NewAuthService(repositories.NewGORMUserRepository(db), "test_jwt_secret")
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 15*time.Minute)
+	addressRepo := repositories.NewMockAddressRepository()
+
+	app := fiber.New()
+	protectedRoutes := app.Group("/api/v1", middleware.AuthRequired(authService))
+	handlers.NewOrderHandler(orderService, authService, services.NewAddressService(addressRepo)).RegisterRoutes(protectedRoutes)
+
+	customer := &models.User{Username: "customer", Email: "customer@example.com", Password: "password123"}
+	assert.NoError(t, authService.RegisterUser(customer))
+	token, err := authService.LoginUser("customer", "password123")
+	assert.NoError(t, err)
+	return app, orderService, productRepo, addressRepo, customer, token
+}
+
+// postOrder sends an order creation request and decodes the response.
+func postOrder(t *testing.T, app *fiber.App, token, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func TestCreateOrderIsPlacedForTheCaller(t *testing.T) {
+	app, _, productRepo, addressRepo, customer, token := setupCheckout(t, "checkout_owner")
+	products, err := productRepo.GetAll()
+	assert.NoError(t, err)
+	assert.NoError(t, addressRepo.Create(&models.Address{UserID: "victim", IsDefault: true, PostalAddress: models.PostalAddress{
+		RecipientName: "Victim", Line1: "Jl. Rahasia 7", City: "Bandung", PostalCode: "40111", Country: "id",
+	}}))
+	assert.NoError(t, addressRepo.Create(&models.Address{UserID: customer.ID, IsDefault: true, PostalAddress: models.PostalAddress{
+		RecipientName: "Budi", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "id",
+	}}))
+	items := fmt.Sprintf(`"items":[{"product_id":%q,"quantity":1}]`, products[0].ID)
+
+	status, body := postOrder(t, app, token, `{"user_id":"victim",`+items+`}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.NotContains(t, fmt.Sprint(body), "Rahasia", "another user's address must not leak")
+
+	status, body = postOrder(t, app, token, `{`+items+`}`)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, customer.ID, body["user_id"])
+	assert.Equal(t, "Budi", body["shipping_address"].(map[string]interface{})["recipient_name"])
+}
+
+func TestPurchaseLimitCountsTheCallersOrders(t *testing.T) {
+	app, _, productRepo, _, _, token := setupCheckout(t, "checkout_limits")
+	limited := &models.Product{Name: "Limited Sneaker", Price: 150, Stock: 10, MaxPerCustomer: 1}
+	assert.NoError(t, productRepo.Create(limited))
+	order := func(userID string) int {
+		status, _ := postOrder(t, app, token, fmt.Sprintf(`{"user_id":%q,"items":[{"product_id":%q,"quantity":1}],
+			"shipping_address":{"recipient_name":"Budi","line1":"Jl. Merdeka 1","city":"Jakarta","postal_code":"10110","country":"id"}}`, userID, limited.ID))
+		return status
+	}
+
+	assert.Equal(t, http.StatusCreated, order(""))
+	assert.Equal(t, http.StatusBadRequest, order(""), "the limit is reached")
+	assert.Equal(t, http.StatusForbidden, order("made-up-user"), "another user_id cannot get around the limit")
+}
+
+func TestCouponPerCustomerLimitCountsTheCallersOrders(t *testing.T) {
+	app, orderService, productRepo, _, _, token := setupCheckout(t, "checkout_coupons")
+	coupons := services.NewCouponService(repositories.NewMockCouponRepository())
+	assert.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "WELCOME", Type: models.CouponTypePercent, Value: 10, PerUserLimit: 1, Active: true}))
+	orderService.SetCoupons(coupons)
+	products, err := productRepo.GetAll()
+	assert.NoError(t, err)
+	order := func(userID string) int {
+		status, _ := postOrder(t, app, token, fmt.Sprintf(`{"user_id":%q,"coupon_code":"welcome","items":[{"product_id":%q,"quantity":1}],
+			"shipping_address":{"recipient_name":"Budi","line1":"Jl. Merdeka 1","city":"Jakarta","postal_code":"10110","country":"id"}}`, userID, products[0].ID))
+		return status
+	}
+
+	assert.Equal(t, http.StatusCreated, order(""))
+	assert.Equal(t, http.StatusBadRequest, order(""), "the coupon is used up for this customer")
+	assert.Equal(t, http.StatusForbidden, order("another-customer"), "another user_id cannot reuse the coupon")
+}
+
+func TestGeoDefaultsForAnonymousShoppers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:geo?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}))
+	productRepo := repositories.NewGORMProductRepository(db)
+	seedProductsForTest(productRepo)
+	authService := services.NewAuthService(repositories.NewGORMUserRepository(db), "test_jwt_secret")
+	currencies, err := services.NewCurrencyConverter("IDR", map[string]float64{"USD": 0.5})
+	assert.NoError(t, err)
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 15*time.Minute)
+	orderService.SetCurrencies(currencies)
+	productHandler := handlers.NewProductHandler(services.NewProductService(productRepo, nil))
+	productHandler.SetCurrencies(currencies)
+
+	app := fiber.New()
+	apiV1 := app.Group("/api/v1")
+	geoDefaults := middleware.GeoDefaults(middleware.GeoConfig{
+		CountryHeader: "CF-IPCountry",
+		Fallback:      geoip.Location{Country: "ID", Currency: "IDR", Locale: "id-ID"},
+	}, authService)
+	apiV1.Use("/products", geoDefaults)
+	apiV1.Use("/quotes", geoDefaults, middleware.OptionalAuth(authService))
+	handlers.NewOrderHandler(orderService, authService, services.NewAddressService(repositories.NewMockAddressRepository())).RegisterQuoteRoutes(apiV1)
+	protectedRoutes := apiV1.Group("", middleware.AuthRequired(authService))
+	productHandler.RegisterRoutes(protectedRoutes)
+
+	products, err := productRepo.GetAll()
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/quotes", bytes.NewReader([]byte(
+		fmt.Sprintf(`{"items":[{"product_id":"%s","quantity":1}]}`, products[0].ID))))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("CF-IPCountry", "US")
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "USD", resp.Header.Get("X-Currency"))
+	var quote services.Quote
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&quote))
+	resp.Body.Close()
+	assert.Equal(t, "USD", quote.PresentmentCurrency, "the detected currency is used when none is asked for")
+
+	// Anonymous catalog requests still need a login, but get the defaults first
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set("CF-IPCountry", "US")
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "USD", resp.Header.Get("X-Currency"))
+	assert.Equal(t, "US", resp.Header.Get("X-Shipping-Country"))
+
+	assert.NoError(t, authService.RegisterUser(&models.User{Username: "shopper", Email: "shopper@example.com", Password: "password123"}))
+	token, err := authService.LoginUser("shopper", "password123")
+	assert.NoError(t, err)
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/products/"+products[0].ID, nil)
+	req.Header.Set("CF-IPCountry", "US")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var product handlers.CatalogProduct
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&product))
+	resp.Body.Close()
+	assert.Equal(t, "USD", product.DisplayCurrency)
+	assert.Equal(t, products[0].Price*0.5, product.DisplayPrice)
+}
+
+func TestAdminOrderStatusCancellationNeedsReason(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:cancellations?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}))
+	productRepo := repositories.NewGORMProductRepository(db)
+	seedProductsForTest(productRepo)
+	authService := services.NewAuthService(repositories.NewGORMUserRepository(db), "test_jwt_secret")
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 15*time.Minute)
+	orderService.SetCancellationReasons(services.NewReasonList([]string{"changed_mind"}))
+
+	app := fiber.New()
+	adminRoutes := app.Group("/api/v1/admin", middleware.AuthRequired(authService), middleware.AdminRequired(authService))
+	handlers.NewOrderHandler(orderService, authService, services.NewAddressService(repositories.NewMockAddressRepository())).RegisterAdminRoutes(adminRoutes)
+
+	assert.NoError(t, authService.RegisterUser(&models.User{Username: "root", Email: "root@example.com", Password: "password123"}))
+	assert.NoError(t, db.Model(&models.User{}).Where("username = ?", "root").Update("role", models.RoleAdmin).Error)
+	token, err := authService.LoginUser("root", "password123")
+	assert.NoError(t, err)
+	products, err := productRepo.GetAll()
+	assert.NoError(t, err)
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: products[0].ID, Quantity: 1}},
+		ShippingAddress: models.PostalAddress{RecipientName: "Budi", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "id"},
+	})
+	assert.NoError(t, err)
+
+	patchStatus := func(body string) int {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/orders/"+order.ID+"/status", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusBadRequest, patchStatus(`{"status":"cancelled"}`))
+	assert.Equal(t, http.StatusOK, patchStatus(`{"status":"cancelled","reason":"changed_mind"}`))
+	stored, err := orderRepo.GetByID(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", stored.Status)
+	assert.Equal(t, "changed_mind", stored.CancelReason)
+}
+
+func TestImpersonationCannotChangeSignInDetails(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:impersonation?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.User{}, &models.Impersonation{}, &models.ImpersonationAction{}))
+	authService := services.NewAuthService(repositories.NewGORMUserRepository(db), "test_jwt_secret")
+	authService.SetImpersonation(repositories.NewGORMImpersonationRepository(db), 15*time.Minute)
+
+	app := fiber.New()
+	protectedRoutes := app.Group("/api/v1", middleware.AuthRequired(authService), middleware.ImpersonationAudit(authService))
+	handlers.NewAuthHandler(authService).RegisterProtectedRoutes(protectedRoutes)
+	handlers.NewProfileHandler(authService, nil).RegisterRoutes(protectedRoutes)
+
+	admin, err := authService.BootstrapAdmin("root", "root@example.com", "password123")
+	assert.NoError(t, err)
+	customer := &models.User{Username: "customer", Email: "customer@example.com", Password: "password123"}
+	assert.NoError(t, authService.RegisterUser(customer))
+	token, err := authService.Impersonate(admin.ID, customer.ID, "Ticket 42", "10.0.0.1")
+	assert.NoError(t, err)
+
+	request := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPost, "/api/v1/users/me/password", `{"current_password":"password123","new_password":"password456"}`))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPatch, "/api/v1/users/me", `{"email":"admin@example.com","current_password":"password123"}`))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, "/api/v1/users/me", `{"password":"password123"}`))
+	assert.Equal(t, http.StatusOK, request(http.MethodPatch, "/api/v1/users/me", `{"locale":"id-ID"}`))
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api/v1/users/me", ""))
+
+	stored, err := authService.GetUserByID(customer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "customer@example.com", stored.Email)
+	assert.Equal(t, "id-ID", stored.Locale)
+
+	actions, err := authService.ImpersonatedActions(customer.ID)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 4, "reads are not recorded")
+	for _, action := range actions {
+		assert.Equal(t, admin.ID, action.ImpersonatorID)
+	}
+	assert.Equal(t, http.MethodPatch, actions[0].Method)
+	assert.Equal(t, http.StatusOK, actions[0].Status)
+	assert.Equal(t, http.StatusForbidden, actions[1].Status)
+}
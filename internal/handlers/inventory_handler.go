@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+	"time"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InventoryHandler handles admin requests for stock forecasts and reorder suggestions.
+type InventoryHandler struct {
+	service *services.InventoryForecastService
+}
+
+// NewInventoryHandler creates a new InventoryHandler.
+func NewInventoryHandler(service *services.InventoryForecastService) *InventoryHandler {
+	return &InventoryHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the inventory routes with the admin router.
+func (h *InventoryHandler) RegisterRoutes(router fiber.Router) {
+	inventoryRoutes := router.Group("/inventory")
+	inventoryRoutes.Get("/forecast", h.HandleListForecasts)
+	inventoryRoutes.Post("/forecast/refresh", h.HandleRefreshForecasts)
+}
+
+// HandleListForecasts returns the latest forecasts (?low_stock=true for reorder candidates only).
+func (h *InventoryHandler) HandleListForecasts(c *fiber.Ctx) error {
+	forecasts, err := h.service.ListForecasts(c.QueryBool("low_stock"))
+	if err != nil {
+		log.Printf("Error listing inventory forecasts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve inventory forecasts",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(forecasts)
+}
+
+// HandleRefreshForecasts recomputes the forecasts immediately instead of
+// waiting for the next scheduled run.
+func (h *InventoryHandler) HandleRefreshForecasts(c *fiber.Ctx) error {
+	low, err := h.service.Recompute(time.Now())
+	if err != nil {
+		log.Printf("Error recomputing inventory forecasts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not recompute inventory forecasts",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message":   "Inventory forecasts recomputed",
+		"low_stock": low,
+	})
+}
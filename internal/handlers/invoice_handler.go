@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InvoiceHandler handles HTTP requests for order invoices.
+type InvoiceHandler struct {
+	invoiceService *services.InvoiceService
+	orderService   *services.OrderService
+	authService    *services.AuthService // Used to let admins download any invoice
+}
+
+// NewInvoiceHandler creates a new InvoiceHandler.
+func NewInvoiceHandler(invoiceService *services.InvoiceService, orderService *services.OrderService, authService *services.AuthService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		orderService:   orderService,
+		authService:    authService,
+	}
+}
+
+// RegisterRoutes registers the invoice routes with the Fiber app.
+func (h *InvoiceHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/orders/:id/invoice", h.HandleGetInvoice)
+}
+
+// HandleGetInvoice downloads the PDF invoice of a paid order, or with
+// ?variant=gift the gift receipt without prices.
+// Customers can only download their own invoices; admins can download any.
+func (h *InvoiceHandler) HandleGetInvoice(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	order, err := h.orderService.GetOrderByID(orderID)
+	if err != nil || (order.UserID != c.Locals("user_id") && !h.isAdmin(c)) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": fmt.Sprintf("Order with ID %s not found", orderID),
+		})
+	}
+
+	fileName := services.InvoiceFileName(order.ID)
+	render := h.invoiceService.Invoice
+	switch c.Query("variant") {
+	case "", "standard":
+	case "gift":
+		fileName = services.GiftReceiptFileName(order.ID)
+		render = h.invoiceService.GiftReceipt
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "variant must be one of: standard, gift",
+		})
+	}
+
+	invoice, err := render(order)
+	if err != nil {
+		log.Printf("Error retrieving invoice for order %s: %v", orderID, err)
+		if strings.Contains(err.Error(), "not been paid") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "Invoices are only available for paid orders",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve invoice",
+			"error":   err.Error(),
+		})
+	}
+
+	c.Attachment(fileName)
+	c.Type("pdf")
+	return c.Send(invoice)
+}
+
+// isAdmin reports whether the authenticated user is an admin.
+func (h *InvoiceHandler) isAdmin(c *fiber.Ctx) bool {
+	if h.authService == nil {
+		return false
+	}
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	tokenType, _ := c.Locals("token_type").(string)
+	return h.authService.IsAdmin(userID, role, tokenType)
+}
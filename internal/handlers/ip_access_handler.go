@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// IPAccessHandler handles admin requests for managing the IP denylist.
+type IPAccessHandler struct {
+	service  *services.IPAccessService
+	validate *validator.Validate
+}
+
+// NewIPAccessHandler creates a new IPAccessHandler.
+func NewIPAccessHandler(service *services.IPAccessService) *IPAccessHandler {
+	return &IPAccessHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// RegisterRoutes registers the denylist routes with the admin router.
+func (h *IPAccessHandler) RegisterRoutes(router fiber.Router) {
+	denylistRoutes := router.Group("/ip-denylist")
+	denylistRoutes.Get("/", h.HandleListDenied)
+	denylistRoutes.Post("/", h.HandleDeny)
+	// The CIDR is passed as a query parameter since it contains a slash
+	denylistRoutes.Delete("/", h.HandleAllow)
+}
+
+// DenyIPRequest represents the request body for adding a denylist entry.
+type DenyIPRequest struct {
+	CIDR   string `json:"cidr" validate:"required"`
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+	TTL    string `json:"ttl"` // Optional Go duration, e.g. "1h"; empty means permanent
+}
+
+// HandleListDenied lists the active denylist entries.
+func (h *IPAccessHandler) HandleListDenied(c *fiber.Ctx) error {
+	entries, err := h.service.ListDenied()
+	if err != nil {
+		log.Printf("Error listing IP denylist: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve IP denylist",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(entries)
+}
+
+// HandleDeny adds an IP address or CIDR range to the denylist.
+func (h *IPAccessHandler) HandleDeny(c *fiber.Ctx) error {
+	var req DenyIPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		errorMessages := make(map[string]string)
+		for _, e := range validationErrors {
+			errorMessages[e.Field()] = fmt.Sprintf("Field '%s' failed on the '%s' tag", e.Field(), e.Tag())
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"errors":  errorMessages,
+		})
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "ttl must be a positive duration such as '1h'",
+			})
+		}
+		ttl = parsed
+	}
+
+	entry, err := h.service.Deny(req.CIDR, req.Reason, ttl)
+	if err != nil {
+		log.Printf("Error denying %s: %v", req.CIDR, err)
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid IP address or CIDR range",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not update IP denylist",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+// HandleAllow removes an entry (?cidr=...) from the denylist.
+func (h *IPAccessHandler) HandleAllow(c *fiber.Ctx) error {
+	cidr := c.Query("cidr")
+	if cidr == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "The cidr query parameter is required",
+		})
+	}
+
+	if err := h.service.Allow(cidr); err != nil {
+		log.Printf("Error removing %s from IP denylist: %v", cidr, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Denylist entry %s not found", cidr),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid IP address or CIDR range",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not update IP denylist",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": fmt.Sprintf("%s removed from IP denylist", cidr),
+	})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LicenseHandler handles HTTP requests for license keys of digital products.
+type LicenseHandler struct {
+	service *services.LicenseService
+}
+
+// NewLicenseHandler creates a new LicenseHandler.
+func NewLicenseHandler(service *services.LicenseService) *LicenseHandler {
+	return &LicenseHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the customer license key routes with the Fiber app.
+func (h *LicenseHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/orders/:id/licenses", h.HandleGetOrderLicenses)
+}
+
+// RegisterAdminRoutes registers the key pool routes with the admin router.
+func (h *LicenseHandler) RegisterAdminRoutes(router fiber.Router) {
+	router.Get("/products/:id/license-keys", h.HandleGetAvailableKeys)
+	router.Post("/products/:id/license-keys", h.HandleAddKeys)
+	router.Post("/orders/:id/licenses", h.HandleFulfillOrder)
+}
+
+// HandleGetOrderLicenses returns the license keys of one of the user's orders.
+func (h *LicenseHandler) HandleGetOrderLicenses(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	keys, err := h.service.ListForOrder(orderID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		if strings.Contains(err.Error(), "cancelled") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "License keys are not available for cancelled orders",
+			})
+		}
+		log.Printf("Error getting license keys of order %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not get license keys",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"order_id": orderID,
+		"licenses": keys,
+	})
+}
+
+// HandleGetAvailableKeys returns the number of unassigned keys in a product's pool.
+func (h *LicenseHandler) HandleGetAvailableKeys(c *fiber.Ctx) error {
+	productID := c.Params("id")
+
+	available, err := h.service.AvailableKeys(productID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Product with ID %s not found", productID),
+			})
+		}
+		log.Printf("Error counting license keys of product %s: %v", productID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not count license keys",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"product_id": productID,
+		"available":  available,
+	})
+}
+
+// AddLicenseKeysRequest represents the request body for adding keys to a pool.
+type AddLicenseKeysRequest struct {
+	Keys []string `json:"keys"`
+}
+
+// HandleAddKeys adds license keys to a product's pool.
+func (h *LicenseHandler) HandleAddKeys(c *fiber.Ctx) error {
+	productID := c.Params("id")
+
+	var request AddLicenseKeysRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	added, err := h.service.AddKeys(productID, request.Keys)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Product with ID %s not found", productID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error adding license keys to product %s: %v", productID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not add license keys",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"product_id": productID,
+		"added":      added,
+	})
+}
+
+// HandleFulfillOrder assigns the license keys an order is still missing,
+// e.g. after the pool of one of its products was topped up.
+func (h *LicenseHandler) HandleFulfillOrder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	if err := h.service.Fulfill(orderID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		if strings.Contains(err.Error(), "cancelled") || strings.Contains(err.Error(), "not paid") || strings.Contains(err.Error(), "not enough license keys") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error assigning license keys to order %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not assign license keys",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
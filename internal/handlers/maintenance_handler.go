@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaintenanceHandler handles admin requests for operational switches.
+type MaintenanceHandler struct {
+	service *services.MaintenanceService
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler.
+func NewMaintenanceHandler(service *services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the maintenance routes with the admin router.
+func (h *MaintenanceHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/read-only", h.HandleGetReadOnly)
+	router.Put("/read-only", h.HandleSetReadOnly)
+}
+
+// SetReadOnlyRequest represents the request body for toggling read-only mode.
+type SetReadOnlyRequest struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// HandleGetReadOnly reports whether read-only mode is enabled.
+func (h *MaintenanceHandler) HandleGetReadOnly(c *fiber.Ctx) error {
+	return c.JSON(h.service.ReadOnly())
+}
+
+// HandleSetReadOnly turns read-only mode on or off for this instance.
+func (h *MaintenanceHandler) HandleSetReadOnly(c *fiber.Ctx) error {
+	var req SetReadOnlyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(h.service.SetReadOnly(req.Enabled, req.Reason))
+}
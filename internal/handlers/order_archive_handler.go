@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OrderArchiveHandler handles admin requests for orders moved to archives.
+type OrderArchiveHandler struct {
+	service *services.OrderArchiveService
+}
+
+// NewOrderArchiveHandler creates a new OrderArchiveHandler.
+func NewOrderArchiveHandler(service *services.OrderArchiveService) *OrderArchiveHandler {
+	return &OrderArchiveHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the archived order routes with the admin router.
+func (h *OrderArchiveHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/orders/archived/:id", h.HandleGetArchivedOrder)
+}
+
+// HandleGetArchivedOrder returns an archived order with its return requests.
+func (h *OrderArchiveHandler) HandleGetArchivedOrder(c *fiber.Ctx) error {
+	record, err := h.service.GetArchivedOrder(c.Params("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error retrieving archived order %s: %v", c.Params("id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve archived order",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(record)
+}
@@ -3,7 +3,11 @@ package handlers
 import (
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 	"toko/internal/models"
+	"toko/internal/repositories"
 	"toko/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,13 +15,18 @@ import (
 
 // OrderHandler handles HTTP requests for orders.
 type OrderHandler struct {
-	service *services.OrderService
+	service        *services.OrderService
+	authService    *services.AuthService    // Used to recognise admins acting on other users' orders
+	addressService *services.AddressService // Resolves saved addresses referenced at checkout
+	customers      *services.CustomerService
 }
 
 // NewOrderHandler creates a new OrderHandler.
-func NewOrderHandler(service *services.OrderService) *OrderHandler {
+func NewOrderHandler(service *services.OrderService, authService *services.AuthService, addressService *services.AddressService) *OrderHandler {
 	return &OrderHandler{
-		service: service,
+		service:        service,
+		authService:    authService,
+		addressService: addressService,
 	}
 }
 
@@ -25,26 +34,132 @@ func NewOrderHandler(service *services.OrderService) *OrderHandler {
 func (h *OrderHandler) RegisterRoutes(router fiber.Router) {
 	orderRoutes := router.Group("/orders")
 	orderRoutes.Get("/", h.HandleGetOrders)
+	orderRoutes.Get("/cancellation-reasons", h.HandleCancellationReasons)
 	orderRoutes.Get("/:id", h.HandleGetOrderByID)
 	orderRoutes.Post("/", h.HandleCreateOrder)
-	// Example for updating order status, could be managed by admin or user role
-	orderRoutes.Patch("/:id/status", h.HandleUpdateOrderStatus)
+	orderRoutes.Post("/:id/cancel", h.HandleCancelOrder)
+	orderRoutes.Post("/:id/reorder", h.HandleReorder)
+	orderRoutes.Post("/:id/resend-receipt", h.HandleResendReceipt)
 }
 
-// HandleGetOrders retrieves all orders.
-// In a real app, this would likely be filtered by user ID based on authentication context.
+// SetCustomers shows customer tags and notes in the admin order view.
+func (h *OrderHandler) SetCustomers(customers *services.CustomerService) {
+	h.customers = customers
+}
+
+// RegisterQuoteRoutes registers the price quote route with a router that
+// identifies logged-in users without requiring them.
+func (h *OrderHandler) RegisterQuoteRoutes(router fiber.Router) {
+	router.Post("/quotes", h.HandleQuote)
+}
+
+// RegisterAdminRoutes registers the admin order routes with the admin router.
+func (h *OrderHandler) RegisterAdminRoutes(router fiber.Router) {
+	router.Get("/orders/:id", h.HandleGetAdminOrder)
+	// Marking an order paid hands out its stock and license keys
+	router.Patch("/orders/:id/status", h.HandleUpdateOrderStatus)
+	router.Patch("/orders/:id/items/:itemId/status", h.HandleUpdateItemStatus)
+	router.Post("/orders/:id/release", h.HandleReleasePreorder)
+	router.Get("/orders/external/:ref", h.HandleGetOrderByExternalRef)
+	router.Patch("/orders/:id/references", h.HandleUpdateOrderReferences)
+}
+
+// RegisterBulkRoutes registers the bulk order route used for load tests with the admin router.
+func (h *OrderHandler) RegisterBulkRoutes(router fiber.Router) {
+	router.Post("/orders/bulk", h.HandleCreateOrdersBulk)
+}
+
+// isAdmin reports whether the authenticated user is an admin.
+func (h *OrderHandler) isAdmin(c *fiber.Ctx) bool {
+	if h.authService == nil {
+		return false
+	}
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	tokenType, _ := c.Locals("token_type").(string)
+	return h.authService.IsAdmin(userID, role, tokenType)
+}
+
+// Order listing page sizes.
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+)
+
+// HandleGetOrders lists orders one page at a time, newest first.
+// Customers only see their own orders; admins see all and may filter by user_id.
+// Supported query parameters: page, page_size, status, from, to (RFC 3339 or YYYY-MM-DD).
+// The total number of matching orders is returned in the X-Total-Count header.
 func (h *OrderHandler) HandleGetOrders(c *fiber.Ctx) error {
-	orders, err := h.service.GetAllOrders()
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", defaultOrderPageSize)
+	if page < 1 || pageSize < 1 || pageSize > maxOrderPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("page must be at least 1 and page_size between 1 and %d", maxOrderPageSize),
+		})
+	}
+
+	opts := repositories.OrderListOptions{
+		Status: c.Query("status"),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+	var err error
+	if opts.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid from date",
+			"error":   err.Error(),
+		})
+	}
+	if opts.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid to date",
+			"error":   err.Error(),
+		})
+	}
+	if h.isAdmin(c) {
+		opts.UserID = c.Query("user_id")
+	} else {
+		opts.UserID, _ = c.Locals("user_id").(string)
+	}
+
+	orders, total, err := h.service.ListOrders(opts)
 	if err != nil {
-		log.Printf("Error getting all orders: %v", err)
+		log.Printf("Error listing orders: %v", err)
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid order filter",
+				"error":   err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Could not retrieve orders",
 			"error":   err.Error(),
 		})
 	}
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
 	return c.JSON(orders)
 }
 
+// parseDateParam parses an RFC 3339 timestamp or a YYYY-MM-DD date. A plain
+// date used as an upper bound covers the whole day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date, got %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
 // HandleGetOrderByID retrieves a single order by its ID.
 func (h *OrderHandler) HandleGetOrderByID(c *fiber.Ctx) error {
 	orderID := c.Params("id")
@@ -65,11 +180,53 @@ func (h *OrderHandler) HandleGetOrderByID(c *fiber.Ctx) error {
 	return c.JSON(order)
 }
 
+// HandleGetAdminOrder retrieves an order together with what staff know about
+// its customer: their tags and internal notes.
+func (h *OrderHandler) HandleGetAdminOrder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	order, err := h.service.GetOrderByID(orderID)
+	if err != nil {
+		log.Printf("Error getting order by ID %s: %v", orderID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve order",
+			"error":   err.Error(),
+		})
+	}
+
+	customer := &services.CustomerProfile{UserID: order.UserID, Tags: []string{}, Notes: []models.CustomerNote{}}
+	if h.customers != nil {
+		if customer, err = h.customers.Profile(order.UserID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Could not retrieve customer",
+				"error":   err.Error(),
+			})
+		}
+	}
+	return c.JSON(fiber.Map{
+		"order":    order,
+		"customer": customer,
+	})
+}
+
+// CreateOrderRequest is the body of an order creation request. Addresses can
+// be given inline or by the ID of a saved address; without either, the
+// customer's default address is used for shipping.
+type CreateOrderRequest struct {
+	models.Order
+	ShippingAddressID string `json:"shipping_address_id"`
+	BillingAddressID  string `json:"billing_address_id"`
+}
+
 // HandleCreateOrder creates a new order.
 func (h *OrderHandler) HandleCreateOrder(c *fiber.Ctx) error {
-	var orderRequest models.Order
+	var request CreateOrderRequest
 	// Attempt to bind the request body to the Order model
-	if err := c.BodyParser(&orderRequest); err != nil {
+	if err := c.BodyParser(&request); err != nil {
 		log.Printf("Error parsing request body: %v", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "Invalid request body",
@@ -77,6 +234,21 @@ func (h *OrderHandler) HandleCreateOrder(c *fiber.Ctx) error {
 		})
 	}
 
+	orderRequest := request.Order
+	// Customers order for themselves; only admin accounts, which integrations
+	// use, may order for another user or stamp their own references
+	if !h.isAdmin(c) {
+		userID, _ := c.Locals("user_id").(string)
+		if orderRequest.UserID != "" && orderRequest.UserID != userID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Orders can only be placed for your own account",
+			})
+		}
+		orderRequest.UserID = userID
+		orderRequest.ExternalRef = ""
+		orderRequest.Metadata = nil
+	}
+
 	// Basic validation: UserID and Items are required
 	if orderRequest.UserID == "" || len(orderRequest.Items) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -86,12 +258,53 @@ func (h *OrderHandler) HandleCreateOrder(c *fiber.Ctx) error {
 
 	// Add any additional validation for items if needed (e.g., quantity > 0)
 
+	// Copy the addresses onto the order so later address book edits do not affect it
+	var err error
+	if orderRequest.ShippingAddress, err = h.addressService.ResolveOrderAddress(orderRequest.UserID, request.ShippingAddressID, orderRequest.ShippingAddress, true); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid shipping address",
+			"error":   err.Error(),
+		})
+	}
+	if orderRequest.BillingAddress, err = h.addressService.ResolveOrderAddress(orderRequest.UserID, request.BillingAddressID, orderRequest.BillingAddress, false); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid billing address",
+			"error":   err.Error(),
+		})
+	}
+
 	// Call the service to create the order. The service handles validation,
 	// repository interaction, and RabbitMQ publishing.
-	createdOrder, err := h.service.CreateOrder(orderRequest)
+	createdOrder, err := h.service.CreateOrder(c.UserContext(), orderRequest)
 	if err != nil {
 		log.Printf("Error creating order: %v", err)
 		// Specific error handling based on service errors (e.g., insufficient stock)
+		if strings.Contains(err.Error(), "address") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid order address",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid gift message") || strings.Contains(err.Error(), "invalid shipping method") ||
+			strings.Contains(err.Error(), "invalid order value") || strings.Contains(err.Error(), "invalid delivery slot") ||
+			strings.Contains(err.Error(), "invalid quantity") || strings.Contains(err.Error(), "invalid checkout field") ||
+			strings.Contains(err.Error(), "invalid external reference") || strings.Contains(err.Error(), "invalid metadata") ||
+			strings.Contains(err.Error(), "invalid currency") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "already used") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid coupon") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "The promo code cannot be used",
+				"error":   err.Error(),
+			})
+		}
 		if err.Error() == "insufficient stock" { // Example error string
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"message": "Order creation failed due to insufficient stock.",
@@ -114,6 +327,8 @@ func (h *OrderHandler) HandleUpdateOrderStatus(c *fiber.Ctx) error {
 	orderID := c.Params("id")
 	var updateData struct {
 		Status string `json:"status"`
+		Reason string `json:"reason"` // Required when cancelling
+		Note   string `json:"note"`
 	}
 
 	if err := c.BodyParser(&updateData); err != nil {
@@ -130,7 +345,12 @@ func (h *OrderHandler) HandleUpdateOrderStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	err := h.service.UpdateOrderStatus(orderID, updateData.Status)
+	if updateData.Status == "cancelled" {
+		// Cancelling restores stock and records why, like POST /orders/:id/cancel
+		return h.cancelOrder(c, orderID, true, updateData.Reason, updateData.Note)
+	}
+
+	err := h.service.UpdateOrderStatus(c.UserContext(), orderID, updateData.Status)
 	if err != nil {
 		log.Printf("Error updating order status for order %s: %v", orderID, err)
 		// Check for specific errors like "order not found" or "invalid status"
@@ -140,6 +360,21 @@ func (h *OrderHandler) HandleUpdateOrderStatus(c *fiber.Ctx) error {
 				"message": fmt.Sprintf("Order update failed: %v", err.Error()),
 			})
 		}
+		if strings.Contains(err.Error(), "invalid order status") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid order state") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Could not update order status",
 			"error":   err.Error(),
@@ -151,3 +386,345 @@ func (h *OrderHandler) HandleUpdateOrderStatus(c *fiber.Ctx) error {
 		"message": fmt.Sprintf("Order %s status updated successfully to %s", orderID, updateData.Status),
 	})
 }
+
+// ItemStatusBody is the body of an item status update. Reason is required
+// when cancelling an item and is one of the cancellation reasons.
+type ItemStatusBody struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HandleUpdateItemStatus moves one item of a paid order to a new status and
+// returns the order with its status derived from the items.
+func (h *OrderHandler) HandleUpdateItemStatus(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	itemID, err := c.ParamsInt("itemId")
+	if err != nil || itemID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Item ID must be a positive integer",
+		})
+	}
+	var body ItemStatusBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	order, err := h.service.UpdateItemStatus(c.UserContext(), orderID, uint(itemID), body.Status, body.Reason)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error updating item %d of order %s: %v", itemID, orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not update item status",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(order)
+}
+
+// CancelOrderBody is the body of a cancellation. Reason is one of the
+// configured cancellation reasons; Note adds detail in the customer's words.
+type CancelOrderBody struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note,omitempty"`
+}
+
+// HandleCancellationReasons lists the reasons a cancellation can give.
+func (h *OrderHandler) HandleCancellationReasons(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"reasons": h.service.CancellationReasons()})
+}
+
+// HandleCancelOrder cancels a pending or processing order and restores its stock.
+// Only the order's owner or an admin may cancel it.
+func (h *OrderHandler) HandleCancelOrder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	var body CancelOrderBody
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return h.cancelOrder(c, orderID, h.isAdmin(c), body.Reason, body.Note)
+}
+
+// cancelOrder cancels an order for the authenticated user and writes the response.
+func (h *OrderHandler) cancelOrder(c *fiber.Ctx, orderID string, isAdmin bool, reason, note string) error {
+	userID, _ := c.Locals("user_id").(string)
+	order, err := h.service.CancelOrder(c.UserContext(), orderID, userID, isAdmin, reason, note)
+	if err != nil {
+		log.Printf("Error cancelling order %s: %v", orderID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "cannot be cancelled") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "Only orders that have not shipped yet can be cancelled",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not cancel order",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(order)
+}
+
+// HandleReorder drafts a new order from one of the user's previous orders,
+// at current prices and capped at current stock. The draft is not saved; it
+// is placed with POST /orders once the customer has reviewed it.
+func (h *OrderHandler) HandleReorder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	draft, err := h.service.Reorder(orderID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		log.Printf("Error drafting reorder of order %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not reorder",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(draft)
+}
+
+// HandleResendReceipt queues the order confirmation email again for support
+// agents and customers who never received it.
+func (h *OrderHandler) HandleResendReceipt(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	if err := h.service.ResendReceipt(c.UserContext(), orderID, userID, h.isAdmin(c)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		log.Printf("Error resending receipt of order %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not resend receipt",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Receipt will be sent again shortly",
+	})
+}
+
+// HandleReleasePreorder sends a paid preorder to fulfillment before its release date.
+func (h *OrderHandler) HandleReleasePreorder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	order, err := h.service.ReleasePreorder(c.UserContext(), orderID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid order state") || strings.Contains(err.Error(), "cannot move") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error releasing preorder %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not release preorder",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(order)
+}
+
+// HandleGetOrderByExternalRef looks an order up by the reference an integration stamped on it.
+func (h *OrderHandler) HandleGetOrderByExternalRef(c *fiber.Ctx) error {
+	ref := c.Params("ref")
+	order, err := h.service.GetOrderByExternalRef(ref)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with external reference %s not found", ref),
+			})
+		}
+		log.Printf("Error getting order by external reference %s: %v", ref, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve order",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(order)
+}
+
+// HandleUpdateOrderReferences sets an order's external reference and merges its metadata.
+func (h *OrderHandler) HandleUpdateOrderReferences(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	var request services.OrderReferencesUpdate
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	order, err := h.service.UpdateOrderReferences(orderID, request)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "already used") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error updating references of order %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not update order",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(order)
+}
+
+// BulkOrderRequest is the body of a bulk order request. Addresses must be given inline.
+type BulkOrderRequest struct {
+	Orders      []models.Order `json:"orders"`
+	MarkPaid    bool           `json:"mark_paid"`   // Confirm the orders without payment
+	Concurrency int            `json:"concurrency"` // Orders placed at the same time; defaults to 1
+}
+
+// HandleCreateOrdersBulk places many orders at once to benchmark the order pipeline.
+func (h *OrderHandler) HandleCreateOrdersBulk(c *fiber.Ctx) error {
+	var request BulkOrderRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	result, err := h.service.CreateOrdersBulk(c.UserContext(), request.Orders, request.MarkPaid, request.Concurrency)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not create orders",
+			"error":   err.Error(),
+		})
+	}
+	log.Printf("Bulk order request created %d of %d orders in %dms", result.Created, len(request.Orders), result.ElapsedMS)
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// QuoteRequestBody is the body of a price quote request. Logged-in users can
+// name a saved address instead of sending the destination.
+type QuoteRequestBody struct {
+	Items               []models.OrderItem   `json:"items"`
+	AddressID           string               `json:"address_id,omitempty"`
+	Destination         models.PostalAddress `json:"destination"`
+	CouponCode          string               `json:"coupon_code,omitempty"`
+	ShippingMethod      string               `json:"shipping_method,omitempty"`
+	PresentmentCurrency string               `json:"presentment_currency,omitempty"`
+}
+
+// HandleQuote returns the subtotal, discount, tax and shipping of a set of
+// items as checkout would compute them, without creating anything.
+func (h *OrderHandler) HandleQuote(c *fiber.Ctx) error {
+	var body QuoteRequestBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	destination := body.Destination
+	if body.AddressID != "" {
+		if userID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Authorization header is required to use a saved address",
+			})
+		}
+		var err error
+		if destination, err = h.addressService.ResolveOrderAddress(userID, body.AddressID, destination, false); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Address not found",
+			})
+		}
+	}
+	// GeoDefaults fills in what the shopper has not chosen
+	if destination.Country == "" {
+		destination.Country, _ = c.Locals("shipping_country").(string)
+	}
+	detectedCurrency, _ := c.Locals("currency").(string)
+
+	quote, err := h.service.Quote(services.QuoteRequest{
+		UserID:              userID,
+		Items:               body.Items,
+		Destination:         destination,
+		CouponCode:          body.CouponCode,
+		ShippingMethod:      body.ShippingMethod,
+		PresentmentCurrency: body.PresentmentCurrency,
+		DetectedCurrency:    detectedCurrency,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "insufficient stock"):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error quoting order: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not quote order",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(quote)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OrderStatusHandler serves the public order status page and issues signed links to it.
+type OrderStatusHandler struct {
+	statusService *services.OrderStatusPageService
+	orderService  *services.OrderService
+	authService   *services.AuthService // Used to let admins create links for any order
+}
+
+// NewOrderStatusHandler creates a new OrderStatusHandler.
+func NewOrderStatusHandler(statusService *services.OrderStatusPageService, orderService *services.OrderService, authService *services.AuthService) *OrderStatusHandler {
+	return &OrderStatusHandler{
+		statusService: statusService,
+		orderService:  orderService,
+		authService:   authService,
+	}
+}
+
+// RegisterRoutes registers the authenticated link route with the Fiber app.
+func (h *OrderStatusHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/orders/:id/status-link", h.HandleGetStatusLink)
+}
+
+// RegisterPublicRoutes registers the signed status page route, which needs no JWT.
+func (h *OrderStatusHandler) RegisterPublicRoutes(router fiber.Router) {
+	router.Get("/order-status/:id", h.HandleGetStatus)
+}
+
+// HandleGetStatusLink returns a signed link to the order's public status page.
+// Customers can only link their own orders; admins can link any order.
+func (h *OrderStatusHandler) HandleGetStatusLink(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	order, err := h.orderService.GetOrderByID(orderID)
+	if err != nil || (order.UserID != c.Locals("user_id") && !h.isAdmin(c)) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": fmt.Sprintf("Order with ID %s not found", orderID),
+		})
+	}
+	return c.JSON(h.statusService.Link(order.ID))
+}
+
+// HandleGetStatus shows an order's status and tracking after verifying the link signature.
+// Browsers get an HTML page; other clients get JSON.
+func (h *OrderStatusHandler) HandleGetStatus(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	view, err := h.statusService.GetStatus(orderID, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		log.Printf("Error serving status page for order %s: %v", orderID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Order not found",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "Status link is invalid or has expired",
+			"error":   err.Error(),
+		})
+	}
+
+	// Links may be forwarded, so keep the page out of shared caches and search engines
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set("X-Robots-Tag", "noindex")
+
+	if c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML {
+		var page bytes.Buffer
+		if err := orderStatusPage.Execute(&page, view); err != nil {
+			log.Printf("Error rendering status page for order %s: %v", orderID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "Could not render status page",
+			})
+		}
+		c.Type("html", "utf-8")
+		return c.Send(page.Bytes())
+	}
+	return c.JSON(view)
+}
+
+// isAdmin reports whether the authenticated user is an admin.
+func (h *OrderStatusHandler) isAdmin(c *fiber.Ctx) bool {
+	if h.authService == nil {
+		return false
+	}
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	tokenType, _ := c.Locals("token_type").(string)
+	return h.authService.IsAdmin(userID, role, tokenType)
+}
+
+// orderStatusPage renders the public status page shown to customers.
+var orderStatusPage = template.Must(template.New("order-status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Order {{.OrderID}}</title>
+</head>
+<body>
+<h1>Order {{.OrderID}}</h1>
+<p>Status: <strong>{{.Status}}</strong></p>
+<p>Placed on {{.PlacedAt.Format "2 Jan 2006"}}, last updated {{.UpdatedAt.Format "2 Jan 2006 15:04 MST"}}</p>
+{{if .TrackingNumber}}<p>Tracking: {{if .Carrier}}{{.Carrier}} {{end}}{{.TrackingNumber}}</p>{{end}}
+<table>
+<tr><th>Item</th><th>Quantity</th></tr>
+{{range .Items}}<tr><td>{{.Name}}</td><td>{{.Quantity}}</td></tr>
+{{end}}</table>
+<p>Total: {{printf "%.2f" .GrandTotal}}</p>
+</body>
+</html>
+`))
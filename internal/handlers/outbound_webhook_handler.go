@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OutboundWebhookHandler handles admin requests for webhook subscriptions and
+// the delivery log used to inspect and redeliver failed webhooks.
+type OutboundWebhookHandler struct {
+	service  *services.WebhookService
+	validate *validator.Validate
+}
+
+// NewOutboundWebhookHandler creates a new OutboundWebhookHandler.
+func NewOutboundWebhookHandler(service *services.WebhookService) *OutboundWebhookHandler {
+	return &OutboundWebhookHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// RegisterRoutes registers the subscription and delivery routes with the admin router.
+// Subscriptions are served at /webhooks as well as /webhook-subscriptions.
+func (h *OutboundWebhookHandler) RegisterRoutes(router fiber.Router) {
+	for _, prefix := range []string{"/webhook-subscriptions", "/webhooks"} {
+		subscriptionRoutes := router.Group(prefix)
+		subscriptionRoutes.Get("/", h.HandleListSubscriptions)
+		subscriptionRoutes.Get("/events", h.HandleListEvents)
+		subscriptionRoutes.Post("/", h.HandleCreateSubscription)
+		subscriptionRoutes.Patch("/:id", h.HandleUpdateSubscription)
+		subscriptionRoutes.Delete("/:id", h.HandleDeleteSubscription)
+	}
+
+	deliveryRoutes := router.Group("/webhook-deliveries")
+	deliveryRoutes.Get("/", h.HandleListDeliveries)
+	deliveryRoutes.Get("/:id", h.HandleGetDelivery)
+	deliveryRoutes.Post("/:id/redeliver", h.HandleRedeliver)
+}
+
+// CreateWebhookSubscriptionRequest represents the request body for creating a subscription.
+type CreateWebhookSubscriptionRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16"`
+	Description string   `json:"description" validate:"omitempty,max=255"`
+}
+
+// UpdateWebhookSubscriptionRequest represents the request body for updating a subscription.
+type UpdateWebhookSubscriptionRequest struct {
+	URL    *string  `json:"url" validate:"omitempty,url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// HandleListEvents lists the events subscriptions can choose from.
+func (h *OutboundWebhookHandler) HandleListEvents(c *fiber.Ctx) error {
+	return c.JSON(h.service.EventCatalog())
+}
+
+// HandleListSubscriptions lists all webhook subscriptions.
+func (h *OutboundWebhookHandler) HandleListSubscriptions(c *fiber.Ctx) error {
+	subscriptions, err := h.service.ListSubscriptions()
+	if err != nil {
+		log.Printf("Error listing webhook subscriptions: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve webhook subscriptions",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(subscriptions)
+}
+
+// HandleCreateSubscription registers a new endpoint. The signing secret is
+// only returned in this response.
+func (h *OutboundWebhookHandler) HandleCreateSubscription(c *fiber.Ctx) error {
+	var req CreateWebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:         req.URL,
+		Events:      strings.Join(req.Events, ","),
+		Secret:      req.Secret,
+		Description: req.Description,
+	}
+	if err := h.service.CreateSubscription(subscription); err != nil {
+		if strings.Contains(err.Error(), "quota exceeded") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error creating webhook subscription: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not create webhook subscription",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"subscription": subscription,
+		"secret":       subscription.Secret,
+	})
+}
+
+// HandleUpdateSubscription changes a subscription's URL, events or active flag.
+func (h *OutboundWebhookHandler) HandleUpdateSubscription(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var req UpdateWebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"error":   err.Error(),
+		})
+	}
+
+	var events *string
+	if req.Events != nil {
+		joined := strings.Join(req.Events, ",")
+		events = &joined
+	}
+	subscription, err := h.service.UpdateSubscription(id, req.URL, events, req.Active)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Webhook subscription with ID %s not found", id),
+			})
+		}
+		log.Printf("Error updating webhook subscription %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not update webhook subscription",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(subscription)
+}
+
+// HandleDeleteSubscription removes a subscription.
+func (h *OutboundWebhookHandler) HandleDeleteSubscription(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.service.DeleteSubscription(id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Webhook subscription with ID %s not found", id),
+			})
+		}
+		log.Printf("Error deleting webhook subscription %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not delete webhook subscription",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListDeliveries lists deliveries one page at a time, newest first.
+// Supported query parameters: page, page_size, subscription_id, event, status.
+// The total number of matching deliveries is returned in the X-Total-Count header.
+func (h *OutboundWebhookHandler) HandleListDeliveries(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", defaultOrderPageSize)
+	if page < 1 || pageSize < 1 || pageSize > maxOrderPageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("page must be at least 1 and page_size between 1 and %d", maxOrderPageSize),
+		})
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(repositories.WebhookDeliveryListOptions{
+		SubscriptionID: c.Query("subscription_id"),
+		Event:          c.Query("event"),
+		Status:         c.Query("status"),
+		Limit:          pageSize,
+		Offset:         (page - 1) * pageSize,
+	})
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve webhook deliveries",
+			"error":   err.Error(),
+		})
+	}
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	return c.JSON(deliveries)
+}
+
+// HandleGetDelivery returns a delivery with its payload and every attempt.
+func (h *OutboundWebhookHandler) HandleGetDelivery(c *fiber.Ctx) error {
+	id := c.Params("id")
+	delivery, err := h.service.GetDelivery(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Webhook delivery with ID %s not found", id),
+			})
+		}
+		log.Printf("Error retrieving webhook delivery %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve webhook delivery",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(delivery)
+}
+
+// HandleRedeliver queues a delivery to be sent again with its original payload.
+func (h *OutboundWebhookHandler) HandleRedeliver(c *fiber.Ctx) error {
+	id := c.Params("id")
+	delivery, err := h.service.Redeliver(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Webhook delivery or its subscription not found",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error redelivering webhook %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not redeliver webhook",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":  "Webhook redelivery queued",
+		"delivery": delivery,
+	})
+}
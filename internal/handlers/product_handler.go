@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 	"toko/internal/models"
 	"toko/internal/services"
 
@@ -13,8 +14,9 @@ import (
 
 // ProductHandler handles HTTP requests for products.
 type ProductHandler struct {
-	service  *services.ProductService
-	validate *validator.Validate
+	service    *services.ProductService
+	validate   *validator.Validate
+	currencies *services.CurrencyConverter // Optional; prices are also shown in the detected currency
 }
 
 // NewProductHandler creates a new ProductHandler.
@@ -25,17 +27,53 @@ func NewProductHandler(service *services.ProductService) *ProductHandler {
 	}
 }
 
+// SetCurrencies shows catalog prices in the currency detected by GeoDefaults
+// as well, when customers can pay in it.
+func (h *ProductHandler) SetCurrencies(currencies *services.CurrencyConverter) {
+	h.currencies = currencies
+}
+
+// CatalogProduct is a product as the catalog shows it, with its price in the
+// shopper's currency when that differs from the store currency.
+type CatalogProduct struct {
+	*models.Product
+	DisplayCurrency string  `json:"display_currency,omitempty"`
+	DisplayPrice    float64 `json:"display_price,omitempty"`
+}
+
+// catalogProduct prices product in the "currency" local.
+func (h *ProductHandler) catalogProduct(c *fiber.Ctx, product *models.Product) CatalogProduct {
+	view := CatalogProduct{Product: product}
+	currency, _ := c.Locals("currency").(string)
+	if h.currencies == nil || currency == "" || strings.EqualFold(currency, h.currencies.Base()) {
+		return view
+	}
+	if price, err := h.currencies.Convert(product.Price, currency); err == nil {
+		view.DisplayCurrency, view.DisplayPrice = strings.ToUpper(currency), price
+	}
+	return view
+}
+
 // RegisterRoutes registers the product routes with the Fiber app.
 func (h *ProductHandler) RegisterRoutes(router fiber.Router) {
 	productRoutes := router.Group("/products")
 	productRoutes.Get("/", h.HandleGetProducts)
 	productRoutes.Get("/:id", h.HandleGetProductByID)
+	router.Get("/catalog/changes", h.HandleCatalogChanges)
+}
+
+// RegisterAdminRoutes registers the staff catalog routes with the admin router.
+func (h *ProductHandler) RegisterAdminRoutes(router fiber.Router) {
+	productRoutes := router.Group("/products")
+	productRoutes.Get("/", h.HandleGetAllProducts)
 	productRoutes.Post("/", h.HandleCreateProduct)
 	productRoutes.Put("/:id", h.HandleUpdateProduct)
 	productRoutes.Delete("/:id", h.HandleDeleteProduct)
+	productRoutes.Post("/:id/archive", h.HandleArchiveProduct)
+	productRoutes.Post("/:id/unarchive", h.HandleUnarchiveProduct)
 }
 
-// HandleGetProducts retrieves all products.
+// HandleGetProducts retrieves all products in the catalog, leaving out archived ones.
 func (h *ProductHandler) HandleGetProducts(c *fiber.Ctx) error {
 	products, err := h.service.GetAllProducts()
 	if err != nil {
@@ -45,6 +83,23 @@ func (h *ProductHandler) HandleGetProducts(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
+	catalog := make([]CatalogProduct, len(products))
+	for i := range products {
+		catalog[i] = h.catalogProduct(c, &products[i])
+	}
+	return c.JSON(catalog)
+}
+
+// HandleGetAllProducts retrieves all products for staff, archived ones included.
+func (h *ProductHandler) HandleGetAllProducts(c *fiber.Ctx) error {
+	products, err := h.service.GetAllProductsIncludingArchived()
+	if err != nil {
+		log.Printf("Error getting all products: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve products",
+			"error":   err.Error(),
+		})
+	}
 	return c.JSON(products)
 }
 
@@ -65,7 +120,7 @@ func (h *ProductHandler) HandleGetProductByID(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
-	return c.JSON(product)
+	return c.JSON(h.catalogProduct(c, product))
 }
 
 // HandleCreateProduct creates a new product.
@@ -95,6 +150,11 @@ func (h *ProductHandler) HandleCreateProduct(c *fiber.Ctx) error {
 
 	err := h.service.CreateProduct(&product)
 	if err != nil {
+		if strings.Contains(err.Error(), "quota exceeded") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
 		log.Printf("Error creating product: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Could not create product",
@@ -181,3 +241,63 @@ func (h *ProductHandler) HandleDeleteProduct(c *fiber.Ctx) error {
 		"message": fmt.Sprintf("Product with ID %s deleted successfully", productID),
 	})
 }
+
+// HandleArchiveProduct removes a product from the catalog without deleting it.
+func (h *ProductHandler) HandleArchiveProduct(c *fiber.Ctx) error {
+	productID := c.Params("id")
+
+	product, err := h.service.ArchiveProduct(c.UserContext(), productID, "manual")
+	if err != nil {
+		log.Printf("Error archiving product with ID %s: %v", productID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Product with ID %s not found", productID),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not archive product",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(product)
+}
+
+// HandleUnarchiveProduct returns an archived product to the catalog.
+func (h *ProductHandler) HandleUnarchiveProduct(c *fiber.Ctx) error {
+	productID := c.Params("id")
+
+	product, err := h.service.UnarchiveProduct(c.UserContext(), productID)
+	if err != nil {
+		log.Printf("Error unarchiving product with ID %s: %v", productID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Product with ID %s not found", productID),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not unarchive product",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(product)
+}
+
+// HandleCatalogChanges returns the product changes after ?since=<cursor> so
+// search indexes and app caches can sync incrementally. Consumers store
+// next_cursor and keep polling while has_more is true.
+func (h *ProductHandler) HandleCatalogChanges(c *fiber.Ctx) error {
+	page, err := h.service.CatalogChanges(c.Query("since"), c.QueryInt("limit"), time.Now())
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error listing catalog changes: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not list catalog changes",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(page)
+}
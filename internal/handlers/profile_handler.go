@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProfileHandler handles HTTP requests of logged-in users about their own account.
+type ProfileHandler struct {
+	authService *services.AuthService
+	accounts    *services.AccountService
+	validate    *validator.Validate
+}
+
+// NewProfileHandler creates a new ProfileHandler.
+func NewProfileHandler(authService *services.AuthService, accounts *services.AccountService) *ProfileHandler {
+	return &ProfileHandler{
+		authService: authService,
+		accounts:    accounts,
+		validate:    validator.New(),
+	}
+}
+
+// impersonating reports whether the request was made by an admin acting as
+// the user. Impersonation tokens are for reproducing problems, so they cannot
+// change how the account signs in or delete it.
+func impersonating(c *fiber.Ctx) bool {
+	impersonatorID, _ := c.Locals("impersonator_id").(string)
+	return impersonatorID != ""
+}
+
+// refuseImpersonated turns down a change admins may not make as the user.
+func refuseImpersonated(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"message": "This change cannot be made while impersonating the user",
+	})
+}
+
+// RegisterRoutes registers the profile routes with a router behind AuthRequired.
+func (h *ProfileHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/users/me", h.HandleGetProfile)
+	router.Patch("/users/me", h.HandleUpdateProfile)
+	router.Delete("/users/me", h.HandleDeleteAccount)
+	router.Get("/users/me/sessions", h.HandleListSessions)
+	router.Delete("/users/me/sessions/:id", h.HandleRevokeSession)
+	router.Get("/users/me/logins", h.HandleListLogins)
+	router.Get("/users/me/devices", h.HandleListDevices)
+	router.Delete("/users/me/devices/:id", h.HandleForgetDevice)
+}
+
+// UpdateProfileRequest is the body of a request changing the logged-in
+// user's profile. Omitted fields are left unchanged; empty preferences are
+// cleared.
+type UpdateProfileRequest struct {
+	Username          *string `json:"username" validate:"omitnil,min=3,max=100"`
+	Email             *string `json:"email" validate:"omitnil,email"`
+	PreferredCurrency *string `json:"preferred_currency" validate:"omitempty,len=3,alpha"`
+	Locale            *string `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	ShippingCountry   *string `json:"shipping_country" validate:"omitempty,iso3166_1_alpha2"`
+	CurrentPassword   string  `json:"current_password"` // Required to change the email
+}
+
+// HandleGetProfile returns the logged-in user's profile.
+func (h *ProfileHandler) HandleGetProfile(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	user, err := h.authService.GetUserByID(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		log.Printf("Error retrieving profile of user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve profile",
+			"error":   err.Error(),
+		})
+	}
+	user.Password = "" // For security, do not return the password hash
+	return c.JSON(user)
+}
+
+// HandleUpdateProfile changes the logged-in user's profile.
+func (h *ProfileHandler) HandleUpdateProfile(c *fiber.Ctx) error {
+	var req UpdateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		errorMessages := make(map[string]string)
+		for _, e := range validationErrors {
+			errorMessages[e.Field()] = fmt.Sprintf("Field '%s' failed on the '%s' tag", e.Field(), e.Tag())
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Validation failed",
+			"errors":  errorMessages,
+		})
+	}
+	if req.Email != nil && impersonating(c) {
+		return refuseImpersonated(c)
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	user, err := h.authService.UpdateProfile(userID, services.ProfileUpdate{
+		Username:          req.Username,
+		Email:             req.Email,
+		PreferredCurrency: req.PreferredCurrency,
+		Locale:            req.Locale,
+		ShippingCountry:   req.ShippingCountry,
+		CurrentPassword:   req.CurrentPassword,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		if strings.Contains(err.Error(), "invalid current password") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Current password is incorrect",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "already taken") || strings.Contains(err.Error(), "already registered") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error updating profile of user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not update profile",
+			"error":   err.Error(),
+		})
+	}
+	user.Password = "" // For security, do not return the password hash
+	return c.JSON(user)
+}
+
+// HandleListSessions lists the logins of the logged-in user still in use,
+// marking the one the request was made with.
+func (h *ProfileHandler) HandleListSessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals("session_id").(string)
+	sessions, err := h.authService.ListSessions(userID, sessionID)
+	if err != nil {
+		log.Printf("Error listing sessions of user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve sessions",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(sessions)
+}
+
+// HandleRevokeSession ends one login of the logged-in user, such as one on
+// a lost device.
+func (h *ProfileHandler) HandleRevokeSession(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.authService.RevokeSession(userID, c.Params("id")); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Session not found",
+			})
+		}
+		log.Printf("Error revoking session %s of user %s: %v", c.Params("id"), userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not revoke session",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListLogins lists the latest logins of the logged-in user, so they
+// can spot ones they did not make.
+func (h *ProfileHandler) HandleListLogins(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	logins, err := h.authService.LoginHistory(userID)
+	if err != nil {
+		log.Printf("Error listing logins of user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve logins",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(logins)
+}
+
+// HandleListDevices lists the devices the logged-in user keeps logged in.
+func (h *ProfileHandler) HandleListDevices(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	devices, err := h.authService.ListDevices(userID)
+	if err != nil {
+		log.Printf("Error listing devices of user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve devices",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(devices)
+}
+
+// HandleForgetDevice stops a device of the logged-in user from logging in
+// again with its device token.
+func (h *ProfileHandler) HandleForgetDevice(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid device ID",
+		})
+	}
+	if err := h.authService.ForgetDevice(userID, uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Device not found",
+			})
+		}
+		log.Printf("Error forgetting device %d of user %s: %v", id, userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not forget device",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteAccountRequest is the body of a request deleting the logged-in user's account.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// HandleDeleteAccount erases the logged-in user's account and personal
+// data. The password is asked again so a stolen token cannot do it.
+func (h *ProfileHandler) HandleDeleteAccount(c *fiber.Ctx) error {
+	if impersonating(c) {
+		return refuseImpersonated(c)
+	}
+	var req DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.accounts.DeleteAccount(c.UserContext(), userID, req.Password); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		if strings.Contains(err.Error(), "invalid password") {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Password is incorrect",
+				"error":   err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error deleting account of user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not delete account",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
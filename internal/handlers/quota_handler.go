@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// QuotaHandler handles admin requests about the store's plan limits.
+type QuotaHandler struct {
+	service *services.QuotaService
+}
+
+// NewQuotaHandler creates a new QuotaHandler.
+func NewQuotaHandler(service *services.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the quota routes with the admin router.
+func (h *QuotaHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/usage", h.HandleUsage)
+}
+
+// HandleUsage shows how much of each plan limit is used.
+func (h *QuotaHandler) HandleUsage(c *fiber.Ctx) error {
+	usage, err := h.service.Usage()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve plan usage",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(usage)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"time"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReportHandler handles HTTP requests for sales reports.
+type ReportHandler struct {
+	service *services.ReportService
+}
+
+// NewReportHandler creates a new ReportHandler.
+func NewReportHandler(service *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the report routes with the admin router.
+func (h *ReportHandler) RegisterRoutes(router fiber.Router) {
+	reportRoutes := router.Group("/reports")
+	reportRoutes.Get("/top-products", h.HandleTopProducts)
+	reportRoutes.Get("/revenue", h.HandleRevenue)
+	reportRoutes.Get("/cancellation-reasons", h.HandleCancellationReasons)
+}
+
+// reportPeriod reads the ?from= and ?to= dates (YYYY-MM-DD), defaulting to the last 30 days.
+func reportPeriod(c *fiber.Ctx) (time.Time, time.Time, error) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, err
+		}
+		from = parsed
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, err
+		}
+		to = parsed.AddDate(0, 0, 1) // Include the whole end day
+	}
+	return from, to, nil
+}
+
+// HandleTopProducts returns the best-selling products (?from=&to=&limit=).
+func (h *ReportHandler) HandleTopProducts(c *fiber.Ctx) error {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Dates must use the YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	sales, err := h.service.TopProducts(from, to, c.QueryInt("limit", 10))
+	if err != nil {
+		log.Printf("Error building top products report: %v", err)
+		if strings.Contains(err.Error(), "invalid report period") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not build report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(sales)
+}
+
+// HandleRevenue returns the revenue summary (?from=&to=).
+func (h *ReportHandler) HandleRevenue(c *fiber.Ctx) error {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Dates must use the YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	summary, err := h.service.Revenue(from, to)
+	if err != nil {
+		log.Printf("Error building revenue report: %v", err)
+		if strings.Contains(err.Error(), "invalid report period") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not build report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(summary)
+}
+
+// HandleCancellationReasons returns the top cancellation and return reasons (?from=&to=).
+func (h *ReportHandler) HandleCancellationReasons(c *fiber.Ctx) error {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Dates must use the YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	report, err := h.service.CancellationReasons(from, to)
+	if err != nil {
+		log.Printf("Error building cancellation reasons report: %v", err)
+		if strings.Contains(err.Error(), "invalid report period") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not build report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(report)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReturnHandler handles HTTP requests for order returns.
+type ReturnHandler struct {
+	service     *services.ReturnService
+	authService *services.AuthService // Used to let admins view any return
+}
+
+// NewReturnHandler creates a new ReturnHandler.
+func NewReturnHandler(service *services.ReturnService, authService *services.AuthService) *ReturnHandler {
+	return &ReturnHandler{
+		service:     service,
+		authService: authService,
+	}
+}
+
+// RegisterRoutes registers the customer return routes with the Fiber app.
+func (h *ReturnHandler) RegisterRoutes(router fiber.Router) {
+	router.Post("/orders/:id/returns", h.HandleRequestReturn)
+	router.Get("/returns", h.HandleListMyReturns)
+	router.Get("/returns/reasons", h.HandleReasons)
+	router.Get("/returns/:id", h.HandleGetReturn)
+}
+
+// RegisterAdminRoutes registers the return review routes with the admin router.
+func (h *ReturnHandler) RegisterAdminRoutes(router fiber.Router) {
+	returnRoutes := router.Group("/returns")
+	returnRoutes.Get("/", h.HandleListReturns)
+	returnRoutes.Post("/:id/approve", h.HandleApproveReturn)
+	returnRoutes.Post("/:id/reject", h.HandleRejectReturn)
+}
+
+// ReturnRequestBody is the body of a return request. ReasonCode is one of
+// the configured return reasons; Reason adds detail in the customer's words.
+type ReturnRequestBody struct {
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason"`
+}
+
+// ReturnResolutionBody is the body of an approval or rejection.
+type ReturnResolutionBody struct {
+	Note string `json:"note"`
+}
+
+// HandleRequestReturn opens a return for one of the user's delivered orders.
+func (h *ReturnHandler) HandleRequestReturn(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	var body ReturnRequestBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	request, err := h.service.RequestReturn(orderID, userID, body.ReasonCode, body.Reason)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", orderID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid return reason") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "cannot be returned") || strings.Contains(err.Error(), "window") || strings.Contains(err.Error(), "already") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "Order cannot be returned",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error requesting return for order %s: %v", orderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not request return",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+// HandleReasons lists the reasons a return request can give.
+func (h *ReturnHandler) HandleReasons(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"reasons": h.service.Reasons()})
+}
+
+// HandleListMyReturns lists the user's return requests.
+func (h *ReturnHandler) HandleListMyReturns(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	return h.listReturns(c, userID)
+}
+
+// HandleListReturns lists every return request, optionally filtered by status.
+func (h *ReturnHandler) HandleListReturns(c *fiber.Ctx) error {
+	return h.listReturns(c, "")
+}
+
+func (h *ReturnHandler) listReturns(c *fiber.Ctx, userID string) error {
+	requests, err := h.service.ListReturns(userID, c.Query("status"))
+	if err != nil {
+		log.Printf("Error listing return requests: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve return requests",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(requests)
+}
+
+// HandleGetReturn returns one return request, including its drop-off code once approved.
+func (h *ReturnHandler) HandleGetReturn(c *fiber.Ctx) error {
+	id := c.Params("id")
+	userID, _ := c.Locals("user_id").(string)
+
+	request, err := h.service.GetReturn(id, userID, h.isAdmin(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Return request with ID %s not found", id),
+			})
+		}
+		log.Printf("Error retrieving return request %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve return request",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(request)
+}
+
+// HandleApproveReturn approves a return and sends the customer a drop-off code.
+func (h *ReturnHandler) HandleApproveReturn(c *fiber.Ctx) error {
+	var body ReturnResolutionBody
+	c.BodyParser(&body) // The note is optional
+	request, err := h.service.Approve(c.UserContext(), c.Params("id"), body.Note)
+	return h.resolutionResponse(c, request, err)
+}
+
+// HandleRejectReturn rejects a return.
+func (h *ReturnHandler) HandleRejectReturn(c *fiber.Ctx) error {
+	var body ReturnResolutionBody
+	if err := c.BodyParser(&body); err != nil || strings.TrimSpace(body.Note) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "A note explaining the rejection is required",
+		})
+	}
+	request, err := h.service.Reject(c.Params("id"), body.Note)
+	return h.resolutionResponse(c, request, err)
+}
+
+func (h *ReturnHandler) resolutionResponse(c *fiber.Ctx, request *models.ReturnRequest, err error) error {
+	id := c.Params("id")
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Return request with ID %s not found", id),
+			})
+		}
+		if strings.Contains(err.Error(), "already been resolved") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "Return request has already been resolved",
+				"error":   err.Error(),
+			})
+		}
+		log.Printf("Error resolving return request %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not resolve return request",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(request)
+}
+
+// isAdmin reports whether the authenticated user is an admin.
+func (h *ReturnHandler) isAdmin(c *fiber.Ctx) bool {
+	if h.authService == nil {
+		return false
+	}
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	tokenType, _ := c.Locals("token_type").(string)
+	return h.authService.IsAdmin(userID, role, tokenType)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScrapingHandler handles admin requests for reviewing catalog scraping detections.
+type ScrapingHandler struct {
+	service *services.ScrapingService
+}
+
+// NewScrapingHandler creates a new ScrapingHandler.
+func NewScrapingHandler(service *services.ScrapingService) *ScrapingHandler {
+	return &ScrapingHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the scraping review routes with the admin router.
+func (h *ScrapingHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/scraping-events", h.HandleListEvents)
+}
+
+// HandleListEvents lists recent throttled and blocked clients (?limit=100).
+func (h *ScrapingHandler) HandleListEvents(c *fiber.Ctx) error {
+	events, err := h.service.ListEvents(c.QueryInt("limit", 100))
+	if err != nil {
+		log.Printf("Error listing scraping events: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve scraping events",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(events)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SearchHandler handles HTTP requests for catalog search and its analytics.
+type SearchHandler struct {
+	service *services.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the search routes with the Fiber app.
+func (h *SearchHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/search", h.HandleSearch)
+	router.Post("/search/clicks", h.HandleRecordClick)
+}
+
+// RegisterAdminRoutes registers the search analytics report and synonym
+// dictionary with the admin router.
+func (h *SearchHandler) RegisterAdminRoutes(router fiber.Router) {
+	router.Get("/reports/search", h.HandleSearchReport)
+	router.Get("/search/synonyms", h.HandleListSynonyms)
+	router.Post("/search/synonyms", h.HandleCreateSynonym)
+	router.Put("/search/synonyms/:id", h.HandleUpdateSynonym)
+	router.Delete("/search/synonyms/:id", h.HandleDeleteSynonym)
+}
+
+// HandleSearch searches active products (?q=&limit=20).
+func (h *SearchHandler) HandleSearch(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	result, err := h.service.Search(c.Query("q"), userID, c.QueryInt("limit", 20))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid search") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "A search query is required",
+			})
+		}
+		log.Printf("Error searching products: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not search products",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(result)
+}
+
+// SearchClickRequest is the body reporting a click on a search result.
+type SearchClickRequest struct {
+	SearchID  string `json:"search_id"`
+	ProductID string `json:"product_id"`
+	Position  int    `json:"position"`
+}
+
+// HandleRecordClick records a click-through from a search's results.
+func (h *SearchHandler) HandleRecordClick(c *fiber.Ctx) error {
+	var body SearchClickRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if err := h.service.RecordClick(body.SearchID, body.ProductID, body.Position); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Search with ID %s not found", body.SearchID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid click") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error recording search click: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not record click",
+			"error":   err.Error(),
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleSearchReport returns the top and zero-result queries (?from=&to=&limit=).
+func (h *SearchHandler) HandleSearchReport(c *fiber.Ctx) error {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Dates must use the YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	report, err := h.service.Report(from, to, c.QueryInt("limit", 20))
+	if err != nil {
+		log.Printf("Error building search report: %v", err)
+		if strings.Contains(err.Error(), "invalid report period") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not build report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(report)
+}
+
+// SearchSynonymRequest is the body of a synonym set: comma-separated terms
+// that a search for any one of them also matches, e.g. "hp,handphone,phone".
+type SearchSynonymRequest struct {
+	Terms string `json:"terms"`
+}
+
+// HandleListSynonyms returns the synonym dictionary.
+func (h *SearchHandler) HandleListSynonyms(c *fiber.Ctx) error {
+	synonyms, err := h.service.ListSynonyms()
+	if err != nil {
+		log.Printf("Error listing search synonyms: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not list synonyms",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(synonyms)
+}
+
+// HandleCreateSynonym adds a synonym set.
+func (h *SearchHandler) HandleCreateSynonym(c *fiber.Ctx) error {
+	var body SearchSynonymRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	synonym, err := h.service.CreateSynonym(body.Terms)
+	if err != nil {
+		return h.synonymError(c, "", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(synonym)
+}
+
+// HandleUpdateSynonym replaces the terms of a synonym set.
+func (h *SearchHandler) HandleUpdateSynonym(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid synonym ID",
+		})
+	}
+	var body SearchSynonymRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	synonym, err := h.service.UpdateSynonym(uint(id), body.Terms)
+	if err != nil {
+		return h.synonymError(c, c.Params("id"), err)
+	}
+	return c.JSON(synonym)
+}
+
+// HandleDeleteSynonym removes a synonym set.
+func (h *SearchHandler) HandleDeleteSynonym(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid synonym ID",
+		})
+	}
+	if err := h.service.DeleteSynonym(uint(id)); err != nil {
+		return h.synonymError(c, c.Params("id"), err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *SearchHandler) synonymError(c *fiber.Ctx, id string, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": fmt.Sprintf("Synonym with ID %s not found", id),
+		})
+	}
+	if strings.Contains(err.Error(), "invalid synonym") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error saving search synonym: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not save synonym",
+		"error":   err.Error(),
+	})
+}
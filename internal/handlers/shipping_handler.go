@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ShippingHandler handles HTTP requests for shipping quotes.
+type ShippingHandler struct {
+	service        *services.ShippingService
+	addressService *services.AddressService // Resolves saved destinations
+}
+
+// NewShippingHandler creates a new ShippingHandler.
+func NewShippingHandler(service *services.ShippingService, addressService *services.AddressService) *ShippingHandler {
+	return &ShippingHandler{
+		service:        service,
+		addressService: addressService,
+	}
+}
+
+// RegisterRoutes registers the shipping routes with the Fiber app.
+func (h *ShippingHandler) RegisterRoutes(router fiber.Router) {
+	router.Post("/shipping/quote", h.HandleQuote)
+}
+
+// ShippingQuoteBody is the body of a shipping quote request. The destination
+// is the saved address with AddressID, the inline Destination, the user's
+// default address, or the detected shipping country, in that order.
+type ShippingQuoteBody struct {
+	Items             []models.OrderItem   `json:"items"`
+	AddressID         string               `json:"address_id,omitempty"`
+	Destination       models.PostalAddress `json:"destination"`
+	DestinationAreaID string               `json:"destination_area_id,omitempty"`
+}
+
+// HandleQuote returns the shipping options for a cart before it is ordered.
+func (h *ShippingHandler) HandleQuote(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	var body ShippingQuoteBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	destination, err := h.addressService.ResolveOrderAddress(userID, body.AddressID, body.Destination, true)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Address not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not resolve destination address",
+			"error":   err.Error(),
+		})
+	}
+	// Without an address, quote for the country GeoDefaults detected
+	if destination.Country == "" {
+		destination.Country, _ = c.Locals("shipping_country").(string)
+	}
+
+	quote, err := h.service.Quote(c.UserContext(), services.ShippingQuoteRequest{
+		Items:             body.Items,
+		Destination:       destination,
+		DestinationAreaID: body.DestinationAreaID,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "Product not found",
+				"error":   err.Error(),
+			})
+		case strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "no longer available"):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid shipping quote request",
+				"error":   err.Error(),
+			})
+		case strings.Contains(err.Error(), "no shipping rates"):
+			log.Printf("Error quoting shipping for user %s: %v", userID, err)
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"message": "Shipping rates are temporarily unavailable",
+			})
+		}
+		log.Printf("Error quoting shipping for user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not quote shipping",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(quote)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SigningKeyHandler handles admin requests about the keys access tokens are signed with.
+type SigningKeyHandler struct {
+	authService *services.AuthService
+}
+
+// NewSigningKeyHandler creates a new SigningKeyHandler.
+func NewSigningKeyHandler(authService *services.AuthService) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		authService: authService,
+	}
+}
+
+// RegisterRoutes registers the signing key routes with the admin router.
+func (h *SigningKeyHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/auth/keys", h.HandleListKeys)
+	router.Post("/auth/keys/reload", h.HandleReloadKeys)
+}
+
+// HandleListKeys lists the signing keys in use, without their key material.
+func (h *SigningKeyHandler) HandleListKeys(c *fiber.Ctx) error {
+	return c.JSON(h.authService.SigningKeys())
+}
+
+// HandleReloadKeys loads the signing keys from the configuration again,
+// rotating to a newly added key. Tokens signed with keys still in the
+// keyset stay valid.
+func (h *SigningKeyHandler) HandleReloadKeys(c *fiber.Ctx) error {
+	keys, err := h.authService.ReloadSigningKeys()
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error reloading signing keys: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not reload signing keys",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(keys)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SLAHandler serves fulfillment SLA reports to admins and metrics to Prometheus.
+type SLAHandler struct {
+	service      *services.SLAService
+	metricsToken string
+}
+
+// NewSLAHandler creates a new SLAHandler. With a metrics token, scrapes must
+// send it as a bearer token.
+func NewSLAHandler(service *services.SLAService, metricsToken string) *SLAHandler {
+	return &SLAHandler{
+		service:      service,
+		metricsToken: metricsToken,
+	}
+}
+
+// RegisterRoutes registers the SLA report route with the admin router.
+func (h *SLAHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/reports/sla", h.HandleSLAReport)
+}
+
+// RegisterMetricsRoute registers the Prometheus scrape endpoint. The router
+// must limit it to trusted networks, as the metrics are not public.
+func (h *SLAHandler) RegisterMetricsRoute(router fiber.Router) {
+	router.Get("/metrics", h.HandleMetrics)
+}
+
+// HandleSLAReport returns the fulfillment stage durations of the orders
+// placed in the period (?from=&to=), with SLA breaches.
+func (h *SLAHandler) HandleSLAReport(c *fiber.Ctx) error {
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Dates must use the YYYY-MM-DD format",
+			"error":   err.Error(),
+		})
+	}
+
+	report, err := h.service.Report(from, to)
+	if err != nil {
+		log.Printf("Error building SLA report: %v", err)
+		if strings.Contains(err.Error(), "invalid report period") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not build report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(report)
+}
+
+// HandleMetrics writes the SLA metrics in the Prometheus text format.
+func (h *SLAHandler) HandleMetrics(c *fiber.Ctx) error {
+	if h.metricsToken != "" {
+		token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.metricsToken)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid metrics token",
+			})
+		}
+	}
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	return h.service.WriteMetrics(c.Response().BodyWriter())
+}
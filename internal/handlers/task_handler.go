@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/jobs"
+	"toko/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TaskHandler handles admin requests for inspecting and retrying background tasks.
+type TaskHandler struct {
+	queue *jobs.Queue
+}
+
+// NewTaskHandler creates a new TaskHandler.
+func NewTaskHandler(queue *jobs.Queue) *TaskHandler {
+	return &TaskHandler{
+		queue: queue,
+	}
+}
+
+// RegisterRoutes registers the task routes with the admin router.
+func (h *TaskHandler) RegisterRoutes(router fiber.Router) {
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Get("/", h.HandleListTasks)
+	taskRoutes.Get("/:id", h.HandleGetTask)
+	taskRoutes.Post("/:id/retry", h.HandleRetryTask)
+}
+
+// HandleListTasks lists tasks by status (?status=dead by default, ?limit=50).
+func (h *TaskHandler) HandleListTasks(c *fiber.Ctx) error {
+	status := c.Query("status", models.TaskStatusDead)
+	tasks, err := h.queue.ListTasks(status, c.QueryInt("limit", 50))
+	if err != nil {
+		log.Printf("Error listing %s tasks: %v", status, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve tasks",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(tasks)
+}
+
+// HandleGetTask retrieves a single task by its ID.
+func (h *TaskHandler) HandleGetTask(c *fiber.Ctx) error {
+	taskID := c.Params("id")
+	task, err := h.queue.GetTask(taskID)
+	if err != nil {
+		log.Printf("Error getting task %s: %v", taskID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Task with ID %s not found", taskID),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve task",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(task)
+}
+
+// HandleRetryTask requeues a dead task.
+func (h *TaskHandler) HandleRetryTask(c *fiber.Ctx) error {
+	taskID := c.Params("id")
+	task, err := h.queue.Retry(taskID)
+	if err != nil {
+		log.Printf("Error retrying task %s: %v", taskID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Task with ID %s not found", taskID),
+			})
+		}
+		if strings.Contains(err.Error(), "only dead tasks") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retry task",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(task)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserAccessHandler handles admin requests changing what staff may do in the
+// admin API and letting admins act as customers.
+type UserAccessHandler struct {
+	authService *services.AuthService
+}
+
+// NewUserAccessHandler creates a new UserAccessHandler.
+func NewUserAccessHandler(authService *services.AuthService) *UserAccessHandler {
+	return &UserAccessHandler{
+		authService: authService,
+	}
+}
+
+// RegisterRoutes registers the user access routes with the admin router.
+func (h *UserAccessHandler) RegisterRoutes(router fiber.Router) {
+	router.Put("/users/:id/access", h.HandleSetAccess)
+	router.Post("/users/:id/impersonate", h.HandleImpersonate)
+	router.Get("/users/:id/impersonations", h.HandleListImpersonations)
+	router.Get("/users/:id/impersonations/actions", h.HandleListImpersonatedActions)
+}
+
+// ImpersonateRequest is the body of a request to act as a customer.
+type ImpersonateRequest struct {
+	Reason string `json:"reason"` // Kept in the audit record, e.g. the support ticket
+}
+
+// UserAccessRequest is the body of a request setting a user's role and
+// personal permission grants.
+type UserAccessRequest struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// HandleSetAccess makes a user a customer or support staff member and
+// replaces the permissions granted to them personally.
+func (h *UserAccessHandler) HandleSetAccess(c *fiber.Ctx) error {
+	var body UserAccessRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	user, err := h.authService.SetUserAccess(c.Params("id"), body.Role, body.Permissions)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error setting access of user %s: %v", c.Params("id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not change user access",
+			"error":   err.Error(),
+		})
+	}
+	user.Password = "" // For security, do not return the password hash
+	return c.JSON(user)
+}
+
+// HandleImpersonate issues the admin a short-lived access token of a
+// customer, recording the impersonation.
+func (h *UserAccessHandler) HandleImpersonate(c *fiber.Ctx) error {
+	var body ImpersonateRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	adminID, _ := c.Locals("user_id").(string)
+	token, err := h.authService.Impersonate(adminID, c.Params("id"), body.Reason, c.IP())
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": "User not found",
+			})
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+		log.Printf("Error impersonating user %s: %v", c.Params("id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not impersonate user",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(token)
+}
+
+// HandleListImpersonations lists who impersonated a user, when and why.
+func (h *UserAccessHandler) HandleListImpersonations(c *fiber.Ctx) error {
+	impersonations, err := h.authService.Impersonations(c.Params("id"))
+	if err != nil {
+		log.Printf("Error listing impersonations of user %s: %v", c.Params("id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve impersonations",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(impersonations)
+}
+
+// HandleListImpersonatedActions lists the changes admins made while acting
+// as a user.
+func (h *UserAccessHandler) HandleListImpersonatedActions(c *fiber.Ctx) error {
+	actions, err := h.authService.ImpersonatedActions(c.Params("id"))
+	if err != nil {
+		log.Printf("Error listing impersonated actions on user %s: %v", c.Params("id"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not retrieve impersonated actions",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(actions)
+}
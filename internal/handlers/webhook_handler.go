@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler handles inbound webhooks from payment, shipping and partner integrations.
+// Signatures are verified by middleware before these handlers run.
+type WebhookHandler struct {
+	orderService *services.OrderService
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(orderService *services.OrderService) *WebhookHandler {
+	return &WebhookHandler{
+		orderService: orderService,
+	}
+}
+
+// RegisterRoutes registers the webhook routes. verify returns the signature
+// middleware for the named integration.
+func (h *WebhookHandler) RegisterRoutes(router fiber.Router, verify func(integration string) fiber.Handler) {
+	webhookRoutes := router.Group("/webhooks")
+	webhookRoutes.Post("/payments", verify("payments"), h.HandlePaymentWebhook)
+	webhookRoutes.Post("/shipping", verify("shipping"), h.HandleShippingWebhook)
+	webhookRoutes.Post("/partners", verify("partners"), h.HandlePartnerWebhook)
+}
+
+// WebhookEvent is the common envelope of inbound webhook payloads.
+type WebhookEvent struct {
+	Event   string                 `json:"event"`
+	OrderID string                 `json:"order_id"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// paymentStatuses maps payment provider events to order statuses.
+var paymentStatuses = map[string]string{
+	"payment.succeeded": "processing",
+}
+
+// paymentFailures maps payment provider events to the reason recorded when
+// the order's payment is failed and its stock given back.
+var paymentFailures = map[string]string{
+	"payment.failed":  services.PaymentFailureDeclined,
+	"payment.expired": services.PaymentFailureTimeout,
+}
+
+// shippingStatuses maps shipping provider events to order statuses.
+var shippingStatuses = map[string]string{
+	"shipment.shipped":   "shipped",
+	"shipment.delivered": "delivered",
+}
+
+// HandlePaymentWebhook updates an order when its payment status changes.
+// Failed and expired payments release the order's stock.
+func (h *WebhookHandler) HandlePaymentWebhook(c *fiber.Ctx) error {
+	var event WebhookEvent
+	if err := c.BodyParser(&event); err == nil {
+		if reason, ok := paymentFailures[event.Event]; ok && event.OrderID != "" {
+			return h.handlePaymentFailure(c, event, reason)
+		}
+	}
+	return h.handleOrderStatusWebhook(c, "payment", paymentStatuses)
+}
+
+func (h *WebhookHandler) handlePaymentFailure(c *fiber.Ctx, event WebhookEvent, reason string) error {
+	if _, err := h.orderService.FailPayment(c.UserContext(), event.OrderID, reason); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", event.OrderID),
+			})
+		case strings.Contains(err.Error(), "invalid"):
+			// A failure reported after the order was paid or cancelled changes nothing
+			log.Printf("Ignoring payment webhook %s for order %s: %v", event.Event, event.OrderID, err)
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"message": "Webhook received",
+			})
+		}
+		log.Printf("Error applying payment webhook %s to order %s: %v", event.Event, event.OrderID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not process webhook",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": fmt.Sprintf("Order %s payment marked as failed", event.OrderID),
+	})
+}
+
+// HandleShippingWebhook updates an order when its shipment status changes.
+func (h *WebhookHandler) HandleShippingWebhook(c *fiber.Ctx) error {
+	return h.handleOrderStatusWebhook(c, "shipping", shippingStatuses)
+}
+
+// HandlePartnerWebhook acknowledges partner notifications.
+func (h *WebhookHandler) HandlePartnerWebhook(c *fiber.Ctx) error {
+	var event WebhookEvent
+	if err := c.BodyParser(&event); err != nil || event.Event == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid webhook payload",
+		})
+	}
+	log.Printf("Received partner webhook %s", event.Event)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Webhook received",
+	})
+}
+
+func (h *WebhookHandler) handleOrderStatusWebhook(c *fiber.Ctx, source string, statuses map[string]string) error {
+	var event WebhookEvent
+	if err := c.BodyParser(&event); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid webhook payload",
+			"error":   err.Error(),
+		})
+	}
+	if event.Event == "" || event.OrderID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "event and order_id are required",
+		})
+	}
+
+	status, ok := statuses[event.Event]
+	if !ok {
+		// Unknown events are acknowledged so the provider does not keep retrying them
+		log.Printf("Ignoring %s webhook event %s for order %s", source, event.Event, event.OrderID)
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "Webhook received",
+		})
+	}
+
+	// Shipping providers include tracking details with shipment events
+	if trackingNumber, _ := event.Data["tracking_number"].(string); trackingNumber != "" {
+		carrier, _ := event.Data["carrier"].(string)
+		if err := h.orderService.UpdateTracking(event.OrderID, carrier, trackingNumber); err != nil {
+			log.Printf("Error recording tracking from %s webhook for order %s: %v", source, event.OrderID, err)
+		}
+	}
+
+	if err := h.orderService.UpdateOrderStatus(c.UserContext(), event.OrderID, status); err != nil {
+		log.Printf("Error applying %s webhook %s to order %s: %v", source, event.Event, event.OrderID, err)
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"message": fmt.Sprintf("Order with ID %s not found", event.OrderID),
+			})
+		}
+		if strings.Contains(err.Error(), "invalid order state") {
+			// Late or replayed events for orders that moved on are acknowledged so they are not retried
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"message": "Webhook received",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Could not process webhook",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": fmt.Sprintf("Order %s status updated to %s", event.OrderID, status),
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WishlistHandler handles HTTP requests for the authenticated user's wishlist.
+type WishlistHandler struct {
+	service *services.WishlistService
+}
+
+// NewWishlistHandler creates a new WishlistHandler.
+func NewWishlistHandler(service *services.WishlistService) *WishlistHandler {
+	return &WishlistHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the wishlist routes with the Fiber app.
+func (h *WishlistHandler) RegisterRoutes(router fiber.Router) {
+	wishlistRoutes := router.Group("/wishlist")
+	wishlistRoutes.Get("/", h.HandleGetWishlist)
+	wishlistRoutes.Post("/items", h.HandleAddItem)
+	wishlistRoutes.Delete("/items/:productId", h.HandleRemoveItem)
+	wishlistRoutes.Post("/items/:productId/move-to-cart", h.HandleMoveToCart)
+}
+
+// AddWishlistItemRequest is the body of a request saving a product to the wishlist.
+type AddWishlistItemRequest struct {
+	ProductID string `json:"product_id"`
+}
+
+// MoveToCartRequest is the body of a request moving a wishlist item to the cart.
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity"` // Defaults to 1
+}
+
+// HandleGetWishlist returns the user's wishlist.
+func (h *WishlistHandler) HandleGetWishlist(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	items, err := h.service.GetWishlist(userID)
+	if err != nil {
+		return h.wishlistError(c, "retrieve", err)
+	}
+	return c.JSON(items)
+}
+
+// HandleAddItem saves a product to the wishlist.
+func (h *WishlistHandler) HandleAddItem(c *fiber.Ctx) error {
+	var req AddWishlistItemRequest
+	if err := c.BodyParser(&req); err != nil || req.ProductID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body: product_id is required",
+		})
+	}
+	userID, _ := c.Locals("user_id").(string)
+	item, err := h.service.AddItem(userID, req.ProductID)
+	if err != nil {
+		return h.wishlistError(c, "update", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+// HandleRemoveItem removes a product from the wishlist.
+func (h *WishlistHandler) HandleRemoveItem(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.service.RemoveItem(userID, c.Params("productId")); err != nil {
+		return h.wishlistError(c, "update", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleMoveToCart moves a product from the wishlist to the user's cart and
+// returns the cart.
+func (h *WishlistHandler) HandleMoveToCart(c *fiber.Ctx) error {
+	var req MoveToCartRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+				"error":   err.Error(),
+			})
+		}
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	userID, _ := c.Locals("user_id").(string)
+	cart, err := h.service.MoveToCart(userID, c.Params("productId"), req.Quantity)
+	if err != nil {
+		return h.wishlistError(c, "move", err)
+	}
+	return c.JSON(cart)
+}
+
+func (h *WishlistHandler) wishlistError(c *fiber.Ctx, action string, err error) error {
+	if strings.Contains(err.Error(), "not found") {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "insufficient stock") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	log.Printf("Error trying to %s wishlist: %v", action, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Could not " + action + " wishlist",
+		"error":   err.Error(),
+	})
+}
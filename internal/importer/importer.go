@@ -0,0 +1,370 @@
+// Package importer migrates catalogs, customers and order history from other
+// e-commerce platforms' export files.
+package importer
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// orderNamespace derives stable order IDs from platform order numbers so that
+// re-running an import skips orders that were already migrated.
+var orderNamespace = uuid.MustParse("4c1b7f52-5e0c-4b47-9a8e-2f2b1f0e6d11")
+
+// Result summarises what an import created and skipped.
+type Result struct {
+	ProductsCreated  int
+	ProductsSkipped  int
+	CustomersCreated int
+	CustomersSkipped int
+	OrdersCreated    int
+	OrdersSkipped    int
+}
+
+// Importer writes platform export data through the regular repositories.
+type Importer struct {
+	productRepo repositories.ProductRepository
+	userRepo    repositories.UserRepository
+	orderRepo   repositories.OrderRepository
+}
+
+// NewImporter creates a new Importer.
+func NewImporter(productRepo repositories.ProductRepository, userRepo repositories.UserRepository, orderRepo repositories.OrderRepository) *Importer {
+	return &Importer{
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		orderRepo:   orderRepo,
+	}
+}
+
+// SupportedSources returns the accepted --source names.
+func SupportedSources() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ImportFile imports a platform export. The file is either a single CSV or a
+// zip archive of CSVs; each CSV is recognised by "product", "customer" or
+// "order" in its name.
+func (i *Importer) ImportFile(sourceName, path string) (*Result, error) {
+	src, ok := sources[sourceName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import source %q (supported: %s)", sourceName, strings.Join(SupportedSources(), ", "))
+	}
+
+	files, err := readExport(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	// Products and customers first so that orders can reference them
+	if err := i.importProducts(src, files["product"], result); err != nil {
+		return result, err
+	}
+	if err := i.importCustomers(src, files["customer"], result); err != nil {
+		return result, err
+	}
+	if err := i.importOrders(sourceName, src, files["order"], result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (i *Importer) importProducts(src source, rows []row, result *Result) error {
+	existing, err := i.productRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to load existing products: %w", err)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, product := range existing {
+		known[productKey(product.SKU, product.Name)] = true
+	}
+
+	titles := make(map[string]string) // Shopify only repeats the title on a product's first row
+	for _, r := range rows {
+		if handle := r.get("Handle"); handle != "" {
+			if r.get("Title") != "" {
+				titles[handle] = r.get("Title")
+			} else if r.get("Variant SKU", "Option1 Value") != "" {
+				r["Title"] = titles[handle]
+			}
+		}
+
+		product, ok := src.product(r)
+		if !ok {
+			continue
+		}
+		key := productKey(product.SKU, product.Name)
+		if known[key] {
+			result.ProductsSkipped++
+			continue
+		}
+		if err := i.productRepo.Create(&product); err != nil {
+			return fmt.Errorf("failed to import product %s: %w", product.Name, err)
+		}
+		known[key] = true
+		result.ProductsCreated++
+	}
+	return nil
+}
+
+func (i *Importer) importCustomers(src source, rows []row, result *Result) error {
+	for _, r := range rows {
+		user, ok := src.customer(r)
+		if !ok {
+			continue
+		}
+		if existing, err := i.userRepo.GetByEmail(user.Email); err == nil && existing != nil {
+			result.CustomersSkipped++
+			continue
+		}
+		if err := i.createCustomer(&user); err != nil {
+			return err
+		}
+		result.CustomersCreated++
+	}
+	return nil
+}
+
+// createCustomer stores an imported customer with an unusable random password;
+// customers set their own password through the password reset flow.
+func (i *Importer) createCustomer(user *models.User) error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = string(hashedPassword)
+	user.Role = models.RoleCustomer
+	if err := i.userRepo.Create(user); err != nil {
+		return fmt.Errorf("failed to import customer %s: %w", user.Email, err)
+	}
+	return nil
+}
+
+func (i *Importer) importOrders(sourceName string, src source, rows []row, result *Result) error {
+	// Group line items by order, keeping the export's order
+	var references []string
+	lines := make(map[string][]orderLine)
+	for _, r := range rows {
+		line, ok := src.orderLine(r)
+		if !ok {
+			continue
+		}
+		if _, seen := lines[line.Reference]; !seen {
+			references = append(references, line.Reference)
+		}
+		lines[line.Reference] = append(lines[line.Reference], line)
+	}
+	if len(references) == 0 {
+		return nil
+	}
+
+	products, err := i.productRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to load products: %w", err)
+	}
+	productIDs := make(map[string]string, len(products))
+	for _, product := range products {
+		productIDs[productKey(product.SKU, product.Name)] = product.ID
+		productIDs[productKey("", product.Name)] = product.ID
+	}
+
+	for _, reference := range references {
+		orderID := uuid.NewSHA1(orderNamespace, []byte(sourceName+":"+reference)).String()
+		if _, err := i.orderRepo.GetByID(orderID); err == nil {
+			result.OrdersSkipped++
+			continue
+		}
+
+		order, err := i.buildOrder(orderID, lines[reference], productIDs)
+		if err != nil {
+			log.Printf("Skipping order %s: %v", reference, err)
+			result.OrdersSkipped++
+			continue
+		}
+		// Historical orders are stored as-is; stock levels come from the product export
+		if err := i.orderRepo.Create(order); err != nil {
+			return fmt.Errorf("failed to import order %s: %w", reference, err)
+		}
+		result.OrdersCreated++
+	}
+	return nil
+}
+
+func (i *Importer) buildOrder(orderID string, lines []orderLine, productIDs map[string]string) (*models.Order, error) {
+	first := lines[0]
+	user, err := i.userRepo.GetByEmail(first.Email)
+	if err != nil {
+		// Orders from customers missing in the customer export still keep their history
+		user = &models.User{Username: first.Email, Email: first.Email}
+		if first.Email == "" {
+			return nil, fmt.Errorf("order has no customer email")
+		}
+		if err := i.createCustomer(user); err != nil {
+			return nil, err
+		}
+	}
+
+	order := &models.Order{
+		ID:        orderID,
+		UserID:    user.ID,
+		Status:    first.Status,
+		CreatedAt: first.CreatedAt,
+		UpdatedAt: first.CreatedAt,
+	}
+	var itemsTotal float64
+	for _, line := range lines {
+		productID, err := i.productForLine(line, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		order.Items = append(order.Items, models.OrderItem{
+			ProductID: productID,
+			Quantity:  line.Quantity,
+			Price:     line.Price,
+		})
+		itemsTotal += line.Price * float64(line.Quantity)
+	}
+	// The platform's total includes any tax and shipping, which are not itemized in the export
+	order.Subtotal = itemsTotal
+	order.GrandTotal = first.Total
+	if order.GrandTotal == 0 {
+		order.GrandTotal = itemsTotal
+	}
+	return order, nil
+}
+
+// productForLine finds the product an order line refers to. Products that no
+// longer exist on the platform are recreated as archived, out-of-stock entries
+// so the order history stays intact.
+func (i *Importer) productForLine(line orderLine, productIDs map[string]string) (string, error) {
+	if id, ok := productIDs[productKey(line.SKU, line.Name)]; ok {
+		return id, nil
+	}
+	if id, ok := productIDs[productKey("", line.Name)]; ok && line.SKU == "" {
+		return id, nil
+	}
+	if line.Name == "" {
+		return "", fmt.Errorf("line item has no product name")
+	}
+	product := &models.Product{
+		Name:   line.Name,
+		SKU:    line.SKU,
+		Price:  line.Price,
+		Status: models.ProductStatusArchived,
+		Type:   models.ProductTypePhysical,
+	}
+	if err := i.productRepo.Create(product); err != nil {
+		return "", fmt.Errorf("failed to create placeholder product %s: %w", line.Name, err)
+	}
+	productIDs[productKey(line.SKU, line.Name)] = product.ID
+	productIDs[productKey("", line.Name)] = product.ID
+	return product.ID, nil
+}
+
+// productKey identifies a product by SKU, or by name when it has no SKU.
+func productKey(sku, name string) string {
+	if sku != "" {
+		return "sku:" + strings.ToLower(sku)
+	}
+	return "name:" + strings.ToLower(name)
+}
+
+// readExport loads the CSVs of an export, grouped by the kind of data they hold.
+func readExport(path string) (map[string][]row, error) {
+	files := make(map[string][]row)
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer f.Close()
+		return files, addCSV(files, filepath.Base(path), f)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import archive: %w", err)
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(file.Name), ".csv") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		err = addCSV(files, filepath.Base(file.Name), rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// addCSV parses a CSV and files its rows under the kind named in the file name.
+func addCSV(files map[string][]row, name string, r io.Reader) error {
+	lower := strings.ToLower(name)
+	var kind string
+	switch {
+	case strings.Contains(lower, "product"):
+		kind = "product"
+	case strings.Contains(lower, "customer"):
+		kind = "customer"
+	case strings.Contains(lower, "order"):
+		kind = "order"
+	default:
+		log.Printf("Ignoring %s: not a product, customer or order export", name)
+		return nil
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel adds a byte order mark
+	}
+	for _, record := range records[1:] {
+		r := make(row, len(header))
+		for idx, column := range header {
+			if idx < len(record) {
+				r[strings.TrimSpace(column)] = record[idx]
+			}
+		}
+		files[kind] = append(files[kind], r)
+	}
+	return nil
+}
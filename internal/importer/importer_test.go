@@ -0,0 +1,116 @@
+package importer_test
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"toko/internal/importer"
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const shopifyProducts = `Handle,Title,Body (HTML),Option1 Value,Variant SKU,Variant Price,Variant Inventory Qty,Variant Requires Shipping,Status
+t-shirt,T-Shirt,Cotton tee,Small,TS-S,20.00,5,true,active
+t-shirt,,,Large,TS-L,22.00,3,true,active
+ebook,Go E-Book,PDF,Default Title,EB-1,9.99,0,false,active
+`
+
+const shopifyCustomers = `First Name,Last Name,Email,Default Address Country Code
+Ani,Wijaya,ani@example.com,ID
+`
+
+const shopifyOrders = `Name,Email,Financial Status,Fulfillment Status,Created at,Cancelled at,Total,Lineitem quantity,Lineitem name,Lineitem price,Lineitem sku
+#1001,ani@example.com,paid,fulfilled,2023-01-15 10:30:00 +0700,,64.00,2,T-Shirt - Small,20.00,TS-S
+#1001,ani@example.com,paid,fulfilled,2023-01-15 10:30:00 +0700,,64.00,1,T-Shirt - Large,22.00,TS-L
+#1002,budi@example.com,pending,,2023-02-01 09:00:00 +0700,,15.00,1,Retired Mug,15.00,MUG-1
+`
+
+func writeZip(t *testing.T, files map[string]string) string {
+	path := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+	return path
+}
+
+func TestImporter_ShopifyExport(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}, &models.Order{}, &models.OrderItem{}))
+
+	productRepo := repositories.NewGORMProductRepository(db)
+	userRepo := repositories.NewGORMUserRepository(db)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	imp := importer.NewImporter(productRepo, userRepo, orderRepo)
+
+	path := writeZip(t, map[string]string{
+		"products_export.csv":  shopifyProducts,
+		"customers_export.csv": shopifyCustomers,
+		"orders_export.csv":    shopifyOrders,
+	})
+
+	result, err := imp.ImportFile("shopify", path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ProductsCreated)
+	assert.Equal(t, 1, result.CustomersCreated)
+	assert.Equal(t, 2, result.OrdersCreated)
+
+	products, err := productRepo.GetAll()
+	require.NoError(t, err)
+	stock := make(map[string]int)
+	for _, p := range products {
+		stock[p.Name] = p.Stock
+	}
+	// Stock comes from the product export and is not reduced by historical orders
+	assert.Equal(t, 5, stock["T-Shirt - Small"])
+	assert.Equal(t, 3, stock["T-Shirt - Large"])
+	assert.Contains(t, stock, "Retired Mug")
+
+	ani, err := userRepo.GetByEmail("ani@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ID", ani.ShippingCountry)
+
+	orders, err := orderRepo.GetAll()
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	for _, order := range orders {
+		if order.UserID == ani.ID {
+			assert.Equal(t, "delivered", order.Status)
+			assert.Len(t, order.Items, 2)
+			assert.Equal(t, 64.00, order.GrandTotal)
+			assert.Equal(t, 2023, order.CreatedAt.Year())
+		} else {
+			assert.Equal(t, "pending", order.Status)
+		}
+	}
+
+	// Re-running the import is a no-op
+	result, err = imp.ImportFile("shopify", path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ProductsCreated)
+	assert.Equal(t, 0, result.OrdersCreated)
+	assert.Equal(t, 2, result.OrdersSkipped)
+}
+
+func TestImporter_UnsupportedSource(t *testing.T) {
+	imp := importer.NewImporter(nil, nil, nil)
+	_, err := imp.ImportFile("magento", "export.zip")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported import source")
+}
@@ -0,0 +1,225 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"toko/internal/models"
+)
+
+// row is a CSV record keyed by its header column.
+type row map[string]string
+
+// get returns the first non-empty value among the given columns.
+func (r row) get(columns ...string) string {
+	for _, column := range columns {
+		if value := strings.TrimSpace(r[column]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// orderLine is one line item of a platform order export. Platforms repeat the
+// order-level columns on every line of the same order.
+type orderLine struct {
+	Reference string
+	Email     string
+	Status    string
+	CreatedAt time.Time
+	Total     float64
+	Name      string
+	SKU       string
+	Quantity  int
+	Price     float64
+}
+
+// source maps one platform's export columns onto our models.
+type source struct {
+	product   func(r row) (models.Product, bool)
+	customer  func(r row) (models.User, bool)
+	orderLine func(r row) (orderLine, bool)
+}
+
+// sources lists the supported platforms by their --source name.
+var sources = map[string]source{
+	"shopify":     {product: shopifyProduct, customer: shopifyCustomer, orderLine: shopifyOrderLine},
+	"woocommerce": {product: wooProduct, customer: wooCustomer, orderLine: wooOrderLine},
+}
+
+// shopifyProduct maps a row of Shopify's products_export.csv. Variant rows leave
+// the title empty; the importer fills it in from the product's first row.
+func shopifyProduct(r row) (models.Product, bool) {
+	name := r.get("Title")
+	if name == "" {
+		return models.Product{}, false
+	}
+	if option := r.get("Option1 Value"); option != "" && option != "Default Title" {
+		name = fmt.Sprintf("%s - %s", name, option)
+	}
+	product := models.Product{
+		Name:        name,
+		Description: r.get("Body (HTML)"),
+		SKU:         r.get("Variant SKU"),
+		Price:       parseFloat(r.get("Variant Price")),
+		Stock:       parseInt(r.get("Variant Inventory Qty")),
+		Status:      models.ProductStatusActive,
+		Type:        models.ProductTypePhysical,
+	}
+	if strings.EqualFold(r.get("Variant Requires Shipping"), "false") {
+		product.Type = models.ProductTypeDigital
+		product.Stock = 0
+	}
+	if status := strings.ToLower(r.get("Status")); status == "archived" || status == "draft" {
+		product.Status = models.ProductStatusArchived
+	}
+	return product, true
+}
+
+// shopifyCustomer maps a row of Shopify's customers_export.csv.
+func shopifyCustomer(r row) (models.User, bool) {
+	email := strings.ToLower(r.get("Email"))
+	if email == "" {
+		return models.User{}, false
+	}
+	user := models.User{Username: email, Email: email}
+	if country := strings.ToUpper(r.get("Default Address Country Code", "Country Code")); len(country) == 2 {
+		user.ShippingCountry = country
+	}
+	return user, true
+}
+
+// shopifyOrderLine maps a row of Shopify's orders_export.csv.
+func shopifyOrderLine(r row) (orderLine, bool) {
+	reference := r.get("Name")
+	if reference == "" {
+		return orderLine{}, false
+	}
+	status := "pending"
+	switch {
+	case r.get("Cancelled at") != "" || strings.EqualFold(r.get("Financial Status"), "refunded"):
+		status = "cancelled"
+	case strings.EqualFold(r.get("Fulfillment Status"), "fulfilled"):
+		status = "delivered"
+	case strings.EqualFold(r.get("Fulfillment Status"), "partial"):
+		status = "shipped"
+	case strings.EqualFold(r.get("Financial Status"), "paid"), strings.EqualFold(r.get("Financial Status"), "partially_refunded"):
+		status = "processing"
+	}
+	return orderLine{
+		Reference: reference,
+		Email:     strings.ToLower(r.get("Email")),
+		Status:    status,
+		CreatedAt: parseTime(r.get("Created at")),
+		Total:     parseFloat(r.get("Total")),
+		Name:      r.get("Lineitem name"),
+		SKU:       r.get("Lineitem sku"),
+		Quantity:  parseInt(r.get("Lineitem quantity")),
+		Price:     parseFloat(r.get("Lineitem price")),
+	}, true
+}
+
+// wooProduct maps a row of WooCommerce's product CSV export.
+func wooProduct(r row) (models.Product, bool) {
+	name := r.get("Name")
+	if name == "" {
+		return models.Product{}, false
+	}
+	product := models.Product{
+		Name:        name,
+		Description: r.get("Short description", "Description"),
+		SKU:         r.get("SKU"),
+		Price:       parseFloat(r.get("Sale price", "Regular price")),
+		Stock:       parseInt(r.get("Stock")),
+		Status:      models.ProductStatusActive,
+		Type:        models.ProductTypePhysical,
+	}
+	if productType := strings.ToLower(r.get("Type")); strings.Contains(productType, "virtual") || strings.Contains(productType, "downloadable") {
+		product.Type = models.ProductTypeDigital
+		product.Stock = 0
+	}
+	if published := r.get("Published"); published != "" && published != "1" {
+		product.Status = models.ProductStatusArchived
+	}
+	return product, true
+}
+
+// wooCustomer maps a row of a WooCommerce customer export.
+func wooCustomer(r row) (models.User, bool) {
+	email := strings.ToLower(r.get("Email", "Billing Email"))
+	if email == "" {
+		return models.User{}, false
+	}
+	user := models.User{Username: r.get("Username"), Email: email}
+	if user.Username == "" {
+		user.Username = email
+	}
+	if country := strings.ToUpper(r.get("Billing Country", "Country")); len(country) == 2 {
+		user.ShippingCountry = country
+	}
+	return user, true
+}
+
+// wooOrderLine maps a row of a WooCommerce order export with one line item per row.
+func wooOrderLine(r row) (orderLine, bool) {
+	reference := r.get("Order Number", "Order ID")
+	if reference == "" {
+		return orderLine{}, false
+	}
+	status := "pending"
+	switch strings.TrimPrefix(strings.ToLower(r.get("Order Status")), "wc-") {
+	case "completed":
+		status = "delivered"
+	case "processing":
+		status = "processing"
+	case "cancelled", "refunded", "failed":
+		status = "cancelled"
+	}
+	return orderLine{
+		Reference: reference,
+		Email:     strings.ToLower(r.get("Billing Email", "Customer Email")),
+		Status:    status,
+		CreatedAt: parseTime(r.get("Order Date")),
+		Total:     parseFloat(r.get("Order Total")),
+		Name:      r.get("Item Name"),
+		SKU:       r.get("SKU"),
+		Quantity:  parseInt(r.get("Quantity")),
+		Price:     parseFloat(r.get("Item Cost")),
+	}, true
+}
+
+// parseFloat parses a money amount, ignoring currency symbols and thousands separators.
+func parseFloat(value string) float64 {
+	value = strings.NewReplacer(",", "", "$", "", " ", "").Replace(value)
+	f, _ := strconv.ParseFloat(value, 64)
+	return f
+}
+
+// parseInt parses a whole quantity, treating blanks and negatives as zero.
+func parseInt(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// timeLayouts are the timestamp formats used by supported exports.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime parses an export timestamp, falling back to the zero time.
+func parseTime(value string) time.Time {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
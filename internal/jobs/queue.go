@@ -0,0 +1,228 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// TaskHandler processes a single task. Returning an error schedules a retry
+// until the task runs out of attempts, after which it is marked dead.
+type TaskHandler func(ctx context.Context, task *models.Task) error
+
+// QueueConfig configures a Queue.
+type QueueConfig struct {
+	Workers      int           // Number of concurrent workers
+	PollInterval time.Duration // How often idle workers check for new tasks
+	MaxAttempts  int           // Default attempts per task
+	RetryBackoff time.Duration // Base delay before a retry, doubled on each attempt
+}
+
+// Queue is a durable, database-backed task queue. Tasks survive restarts and
+// can be processed by workers in any API instance sharing the database.
+type Queue struct {
+	repo     repositories.TaskRepository
+	cfg      QueueConfig
+	mu       sync.RWMutex
+	handlers map[string]TaskHandler
+	wake     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewQueue creates a new Queue.
+func NewQueue(repo repositories.TaskRepository, cfg QueueConfig) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	return &Queue{
+		repo:     repo,
+		cfg:      cfg,
+		handlers: make(map[string]TaskHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler for a task type.
+func (q *Queue) Register(taskType string, handler TaskHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[taskType] = handler
+}
+
+// Handle registers a handler that receives the task payload decoded into T.
+func Handle[T any](q *Queue, taskType string, handler func(ctx context.Context, task *models.Task, payload T) error) {
+	q.Register(taskType, func(ctx context.Context, task *models.Task) error {
+		var payload T
+		if err := json.Unmarshal([]byte(task.Payload), &payload); err != nil {
+			return fmt.Errorf("failed to decode %s payload: %w", taskType, err)
+		}
+		return handler(ctx, task, payload)
+	})
+}
+
+// Enqueue stores a new task to run as soon as a worker is free.
+func (q *Queue) Enqueue(taskType string, payload interface{}) (*models.Task, error) {
+	return q.EnqueueAt(taskType, payload, time.Now())
+}
+
+// EnqueueAt stores a new task that will not run before runAt.
+func (q *Queue) EnqueueAt(taskType string, payload interface{}, runAt time.Time) (*models.Task, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", taskType, err)
+	}
+
+	task := &models.Task{
+		Type:        taskType,
+		Payload:     string(body),
+		Status:      models.TaskStatusPending,
+		MaxAttempts: q.cfg.MaxAttempts,
+		RunAt:       runAt,
+	}
+	if err := q.repo.Create(task); err != nil {
+		return nil, err
+	}
+
+	// Nudge an idle worker instead of waiting for the next poll
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return task, nil
+}
+
+// GetTask returns a task by its ID.
+func (q *Queue) GetTask(id string) (*models.Task, error) {
+	return q.repo.GetByID(id)
+}
+
+// ListTasks returns up to limit tasks with the given status.
+func (q *Queue) ListTasks(status string, limit int) ([]models.Task, error) {
+	return q.repo.ListByStatus(status, limit)
+}
+
+// Retry resets a dead task so it is picked up again with a fresh set of attempts.
+func (q *Queue) Retry(id string) (*models.Task, error) {
+	task, err := q.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != models.TaskStatusDead {
+		return nil, fmt.Errorf("task %s is %s, only dead tasks can be retried", id, task.Status)
+	}
+
+	task.Status = models.TaskStatusPending
+	task.Attempts = 0
+	task.RunAt = time.Now()
+	if err := q.repo.Update(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Start launches the workers and returns immediately.
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+}
+
+// Wait blocks until all workers have stopped after ctx is cancelled.
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+// RunNext claims and processes one ready task. It reports whether a task was found.
+func (q *Queue) RunNext(ctx context.Context) (bool, error) {
+	task, err := q.repo.ClaimNext(time.Now())
+	if err != nil || task == nil {
+		return false, err
+	}
+	q.process(ctx, task)
+	return true, nil
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every ready task before going back to sleep
+		for ctx.Err() == nil {
+			found, err := q.RunNext(ctx)
+			if err != nil {
+				log.Printf("Task queue error: %v", err)
+				break
+			}
+			if !found {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *Queue) process(ctx context.Context, task *models.Task) {
+	q.mu.RLock()
+	handler, ok := q.handlers[task.Type]
+	q.mu.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for task type %s", task.Type)
+	} else {
+		err = safeRun(ctx, handler, task)
+	}
+
+	now := time.Now()
+	if err == nil {
+		task.Status = models.TaskStatusDone
+		task.LastError = ""
+		task.CompletedAt = &now
+	} else {
+		task.LastError = err.Error()
+		if task.Attempts >= task.MaxAttempts {
+			task.Status = models.TaskStatusDead
+			log.Printf("Task %s (%s) is dead after %d attempts: %v", task.ID, task.Type, task.Attempts, err)
+		} else {
+			task.Status = models.TaskStatusPending
+			task.RunAt = now.Add(q.cfg.RetryBackoff * time.Duration(1<<(task.Attempts-1)))
+			log.Printf("Task %s (%s) failed on attempt %d, retrying at %s: %v", task.ID, task.Type, task.Attempts, task.RunAt.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := q.repo.Update(task); err != nil {
+		log.Printf("Failed to update task %s: %v", task.ID, err)
+	}
+}
+
+// safeRun runs handler, converting a panic into an error so one bad task cannot stop a worker.
+func safeRun(ctx context.Context, handler TaskHandler, task *models.Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panicked: %v", r)
+		}
+	}()
+	return handler(ctx, task)
+}
@@ -0,0 +1,110 @@
+package jobs_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type emailBatch struct {
+	Recipients []string `json:"recipients"`
+}
+
+func TestQueue_RetriesThenSucceeds(t *testing.T) {
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 3})
+
+	calls := 0
+	jobs.Handle(queue, "email.batch", func(ctx context.Context, task *models.Task, payload emailBatch) error {
+		calls++
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, payload.Recipients)
+		if calls == 1 {
+			return fmt.Errorf("smtp unavailable")
+		}
+		return nil
+	})
+
+	task, err := queue.Enqueue("email.batch", emailBatch{Recipients: []string{"a@example.com", "b@example.com"}})
+	assert.NoError(t, err)
+
+	found, err := queue.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	stored, _ := queue.GetTask(task.ID)
+	assert.Equal(t, models.TaskStatusPending, stored.Status)
+	assert.Equal(t, "smtp unavailable", stored.LastError)
+
+	found, err = queue.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	stored, _ = queue.GetTask(task.ID)
+	assert.Equal(t, models.TaskStatusDone, stored.Status)
+	assert.Equal(t, 2, stored.Attempts)
+	assert.NotNil(t, stored.CompletedAt)
+
+	// Nothing left to run
+	found, err = queue.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestQueue_DeadTaskCanBeRetried(t *testing.T) {
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 1})
+	queue.Register("image.resize", func(ctx context.Context, task *models.Task) error {
+		panic("corrupt image")
+	})
+
+	task, err := queue.Enqueue("image.resize", map[string]string{"key": "products/1/photo.jpg"})
+	assert.NoError(t, err)
+
+	_, err = queue.RunNext(context.Background())
+	assert.NoError(t, err)
+
+	dead, err := queue.ListTasks(models.TaskStatusDead, 10)
+	assert.NoError(t, err)
+	assert.Len(t, dead, 1)
+	assert.Contains(t, dead[0].LastError, "corrupt image")
+
+	retried, err := queue.Retry(task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskStatusPending, retried.Status)
+	assert.Equal(t, 0, retried.Attempts)
+
+	// Only dead tasks can be retried
+	_, err = queue.Retry(task.ID)
+	assert.Error(t, err)
+}
+
+func TestQueue_WorkersProcessEnqueuedTasks(t *testing.T) {
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{Workers: 2, PollInterval: time.Hour})
+	done := make(chan string, 1)
+	queue.Register("export.orders", func(ctx context.Context, task *models.Task) error {
+		done <- task.ID
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Start(ctx)
+	defer func() {
+		cancel()
+		queue.Wait()
+	}()
+
+	task, err := queue.Enqueue("export.orders", nil)
+	assert.NoError(t, err)
+
+	select {
+	case id := <-done:
+		assert.Equal(t, task.ID, id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("task was not processed")
+	}
+}
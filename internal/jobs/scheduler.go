@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// job is a named unit of work run on a fixed interval.
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs periodically until its context is cancelled.
+type Scheduler struct {
+	jobs []job
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a new, empty Scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers fn to run every interval. Jobs with a non-positive interval are ignored.
+func (s *Scheduler) Every(interval time.Duration, name string, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		log.Printf("Job %s disabled (interval %v)", name, interval)
+		return
+	}
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: fn})
+}
+
+// Start launches every registered job in its own goroutine and returns immediately.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Wait blocks until all jobs have stopped after the context passed to Start is cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	log.Printf("Job %s scheduled every %v", j.name, j.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			if err := j.run(ctx); err != nil {
+				log.Printf("Job %s failed after %v: %v", j.name, time.Since(start), err)
+			}
+		}
+	}
+}
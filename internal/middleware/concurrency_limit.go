@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// limiterMetrics exposes per-group load shedding counters on /debug/vars.
+var limiterMetrics = expvar.NewMap("concurrency_limiter")
+
+var limiterMetricsMu sync.Mutex
+
+// ConcurrencyConfig configures a concurrency limiter for one route group.
+type ConcurrencyConfig struct {
+	Name         string        // Route group name used in metrics, e.g. "checkout"
+	MaxInFlight  int           // Requests handled at once; 0 disables the limiter
+	MaxQueue     int           // Requests allowed to wait for a slot
+	QueueTimeout time.Duration // How long a queued request waits before being shed
+	RetryAfter   time.Duration // Retry-After hint sent with shed responses
+}
+
+// ConcurrencyLimit caps the number of in-flight requests of a route group.
+// Requests beyond the cap wait in a bounded queue; when the queue is full they
+// are rejected with 429, and when their wait times out with 503. Both carry a
+// Retry-After header.
+func ConcurrencyLimit(cfg ConcurrencyConfig) fiber.Handler {
+	if cfg.MaxInFlight <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	slots := make(chan struct{}, cfg.MaxInFlight)
+	queue := make(chan struct{}, cfg.MaxQueue)
+	retryAfter := strconv.Itoa(int(cfg.RetryAfter.Seconds()))
+	if cfg.RetryAfter < time.Second {
+		retryAfter = "1"
+	}
+
+	limiterMetricsMu.Lock()
+	groupMetrics, ok := limiterMetrics.Get(cfg.Name).(*expvar.Map)
+	if !ok {
+		groupMetrics = new(expvar.Map).Init()
+		limiterMetrics.Set(cfg.Name, groupMetrics)
+	}
+	limiterMetricsMu.Unlock()
+
+	shed := func(c *fiber.Ctx, status int, reason string) error {
+		groupMetrics.Add(reason, 1)
+		c.Set(fiber.HeaderRetryAfter, retryAfter)
+		return c.Status(status).JSON(fiber.Map{
+			"message": "The server is busy. Please try again shortly.",
+		})
+	}
+
+	return func(c *fiber.Ctx) error {
+		select {
+		case slots <- struct{}{}:
+		default:
+			// All slots are busy: wait in the queue if there is room
+			select {
+			case queue <- struct{}{}:
+			default:
+				return shed(c, fiber.StatusTooManyRequests, "rejected_queue_full")
+			}
+			timer := time.NewTimer(cfg.QueueTimeout)
+			select {
+			case slots <- struct{}{}:
+				timer.Stop()
+				<-queue
+			case <-timer.C:
+				<-queue
+				return shed(c, fiber.StatusServiceUnavailable, "rejected_timeout")
+			}
+		}
+
+		groupMetrics.Add("in_flight", 1)
+		defer func() {
+			groupMetrics.Add("in_flight", -1)
+			<-slots
+		}()
+		groupMetrics.Add("accepted", 1)
+		return c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"toko/internal/services"
+	"toko/pkg/geoip"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GeoConfig configures the GeoDefaults middleware.
+type GeoConfig struct {
+	Resolver      geoip.Resolver // May be nil if no GeoIP database is configured
+	CountryHeader string         // Country header set by a trusted CDN, e.g. "CF-IPCountry"
+	Fallback      geoip.Location // Used when the country cannot be determined
+}
+
+// GeoDefaults resolves the currency, locale and shipping country for catalog,
+// quote and shipping requests. Precedence: explicit query parameters, then
+// the authenticated user's saved preferences, then the GeoIP lookup, then the
+// configured fallback. It runs ahead of the auth middleware so anonymous and
+// guest requests get defaults too, and reads the user from an access token
+// itself; invalid tokens are left for the auth middleware to reject.
+// The result is stored in the "currency", "locale" and "shipping_country" locals.
+func GeoDefaults(cfg GeoConfig, authService *services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		loc := cfg.Fallback
+		if country := lookupCountry(c, cfg); country != "" {
+			if defaults, ok := geoip.DefaultsFor(country); ok {
+				loc = defaults
+			} else {
+				loc.Country = country
+			}
+		}
+
+		// Saved user preferences win over anything derived from the IP address
+		if userID := requestUserID(c, authService); userID != "" {
+			if user, err := authService.GetUserByID(userID); err == nil {
+				if user.PreferredCurrency != "" {
+					loc.Currency = user.PreferredCurrency
+				}
+				if user.Locale != "" {
+					loc.Locale = user.Locale
+				}
+				if user.ShippingCountry != "" {
+					loc.Country = user.ShippingCountry
+				}
+			}
+		}
+
+		if currency := c.Query("currency"); currency != "" {
+			loc.Currency = strings.ToUpper(currency)
+		}
+		if locale := c.Query("locale"); locale != "" {
+			loc.Locale = locale
+		}
+		if country := c.Query("country"); country != "" {
+			loc.Country = strings.ToUpper(country)
+		}
+
+		c.Locals("currency", loc.Currency)
+		c.Locals("locale", loc.Locale)
+		c.Locals("shipping_country", loc.Country)
+
+		c.Set(fiber.HeaderContentLanguage, loc.Locale)
+		c.Set("X-Currency", loc.Currency)
+		c.Set("X-Shipping-Country", loc.Country)
+
+		return c.Next()
+	}
+}
+
+// requestUserID returns the user the request's access token belongs to, if any.
+func requestUserID(c *fiber.Ctx, authService *services.AuthService) string {
+	if authService == nil {
+		return ""
+	}
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	token, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	claims, err := authService.ValidateToken(token)
+	if err != nil {
+		return ""
+	}
+	userID, _ := claims["user_id"].(string)
+	return userID
+}
+
+// lookupCountry determines the client's country from the CDN header or the GeoIP resolver.
+func lookupCountry(c *fiber.Ctx, cfg GeoConfig) string {
+	if cfg.CountryHeader != "" {
+		// "XX" and "T1" are used by CDNs for unknown and Tor traffic
+		if country := strings.ToUpper(c.Get(cfg.CountryHeader)); len(country) == 2 && country != "XX" && country != "T1" {
+			return country
+		}
+	}
+	if cfg.Resolver == nil {
+		return ""
+	}
+	country, ok := cfg.Resolver.Country(net.ParseIP(c.IP()))
+	if !ok {
+		return ""
+	}
+	return country
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"strings"
+
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GuestBrowse authenticates requests like AuthRequired, except that GET
+// requests to the listed routes may also be made with a guest token. The
+// guest's ID is stored as guest_id so the request can be attributed, and
+// each guest is held to limit, keyed by guest ID. routes are relative to
+// prefix, the path the router is mounted at, and ":param" segments match
+// any single segment; a route does not cover the paths below it.
+func GuestBrowse(authService *services.AuthService, prefix string, routes []string, limit RateLimitConfig) fiber.Handler {
+	limit.Key = func(c *fiber.Ctx) string {
+		guestID, _ := c.Locals("guest_id").(string)
+		return "guest:" + guestID
+	}
+	limited := RateLimit(limit)
+	required := AuthRequired(authService)
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return required(c)
+		}
+		if !matchesAnyRoute(strings.TrimPrefix(c.Path(), prefix), routes) {
+			return required(c)
+		}
+		token, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+		if !ok {
+			return required(c)
+		}
+		guestID, err := authService.ValidateGuestToken(token)
+		if err != nil {
+			return required(c)
+		}
+		c.Locals("guest_id", guestID)
+		c.Locals("token_type", services.TokenTypeGuest)
+		return limited(c)
+	}
+}
+
+// matchesAnyRoute reports whether path is one of routes.
+func matchesAnyRoute(path string, routes []string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range routes {
+		pattern := strings.Split(strings.Trim(route, "/"), "/")
+		if len(pattern) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			if !strings.HasPrefix(segment, ":") && segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
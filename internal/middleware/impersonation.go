@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log"
+
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImpersonationAudit records every change made with an impersonation token,
+// with the admin behind it, once the handler has answered. Reads are not
+// recorded. It must be registered after AuthRequired.
+func ImpersonationAudit(authService *services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		impersonatorID, _ := c.Locals("impersonator_id").(string)
+		if impersonatorID == "" || c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+			return c.Next()
+		}
+
+		err := c.Next()
+		userID, _ := c.Locals("user_id").(string)
+		tokenID, _ := c.Locals("token_id").(string)
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		}
+		action := &models.ImpersonationAction{
+			ImpersonatorID: impersonatorID,
+			UserID:         userID,
+			TokenID:        tokenID,
+			Method:         c.Method(),
+			Path:           c.Path(),
+			Status:         status,
+		}
+		if recordErr := authService.RecordImpersonatedAction(action); recordErr != nil {
+			log.Printf("Error recording %s %s by admin %s impersonating user %s: %v", action.Method, action.Path, impersonatorID, userID, recordErr)
+		}
+		return err
+	}
+}
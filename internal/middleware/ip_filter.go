@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"log"
+	"net"
+
+	"toko/internal/services"
+	"toko/pkg/netutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IPAllowlist only lets requests through from the given networks.
+// An empty allowlist allows every address.
+func IPAllowlist(allowed []*net.IPNet) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(allowed) == 0 {
+			return c.Next()
+		}
+		if !netutil.ContainsIP(allowed, net.ParseIP(c.IP())) {
+			log.Printf("Blocked request to %s from non-allowlisted IP %s", c.Path(), c.IP())
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Access from your IP address is not allowed",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// IPDenylist rejects requests from addresses on the runtime denylist.
+func IPDenylist(ipAccessService *services.IPAccessService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ipAccessService.IsDenied(net.ParseIP(c.IP())) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Access from your IP address has been blocked",
+			})
+		}
+		return c.Next()
+	}
+}
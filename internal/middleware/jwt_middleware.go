@@ -41,8 +41,25 @@ func AuthRequired(authService *services.AuthService) fiber.Handler {
 		// Store claims in Fiber context for subsequent handlers
 		c.Locals("user_id", claims["user_id"])
 		c.Locals("username", claims["username"])
+		c.Locals("session_id", claims["sid"])
+		c.Locals("role", claims["role"]) // As of when the token was issued
+		c.Locals("token_type", claims["token_type"])
+		c.Locals("token_id", claims["jti"])
+		c.Locals("impersonator_id", claims["impersonator"]) // Set while an admin acts as the user
 
 		// Continue to the next handler
 		return c.Next()
 	}
 }
+
+// OptionalAuth identifies the user like AuthRequired when a token is sent,
+// and lets requests without one through anonymously.
+func OptionalAuth(authService *services.AuthService) fiber.Handler {
+	required := AuthRequired(authService)
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return required(c)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"strings"
+
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionRule declares the permission needed by requests to a path and
+// everything below it. An empty Method matches every method.
+type PermissionRule struct {
+	Method     string
+	Path       string // Relative to the router the policy is mounted on
+	Permission string
+}
+
+// PermissionRequired only lets through users holding the permission.
+// It must be registered after AuthRequired.
+func PermissionRequired(authService *services.AuthService, permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return checkPermission(c, authService, permission)
+	}
+}
+
+// PermissionPolicy checks each request against the first matching rule.
+// Requests matching no rule need every permission, so only admins get
+// through. prefix is the path the router is mounted at. It must be
+// registered after AuthRequired.
+func PermissionPolicy(authService *services.AuthService, prefix string, rules []PermissionRule) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := strings.TrimPrefix(c.Path(), prefix)
+		permission := models.PermissionAll
+		for _, rule := range rules {
+			if rule.Method != "" && rule.Method != c.Method() {
+				continue
+			}
+			if path == rule.Path || strings.HasPrefix(path, rule.Path+"/") {
+				permission = rule.Permission
+				break
+			}
+		}
+		return checkPermission(c, authService, permission)
+	}
+}
+
+func checkPermission(c *fiber.Ctx, authService *services.AuthService, permission string) error {
+	userID, _ := c.Locals("user_id").(string)
+	allowed, err := authService.HasPermission(userID, permission)
+	if err != nil || !allowed {
+		message := "Admin privileges are required"
+		if permission != models.PermissionAll {
+			message = "Permission " + permission + " is required"
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": message,
+		})
+	}
+	return c.Next()
+}
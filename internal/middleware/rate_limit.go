@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimitConfig configures a fixed-window request rate limit.
+type RateLimitConfig struct {
+	Max    int // Requests per client within Window; 0 disables the limit
+	Window time.Duration
+	Key    func(c *fiber.Ctx) string // Identifies the client; the IP by default
+}
+
+// RateLimit rejects clients making more than cfg.Max requests within
+// cfg.Window with 429 and a Retry-After header. Counts are kept in memory
+// per instance.
+func RateLimit(cfg RateLimitConfig) fiber.Handler {
+	if cfg.Max <= 0 || cfg.Window <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	key := cfg.Key
+	if key == nil {
+		key = func(c *fiber.Ctx) string { return c.IP() }
+	}
+	retryAfter := strconv.Itoa(int(cfg.Window.Seconds()) + 1)
+	return limiter.New(limiter.Config{
+		Max:          cfg.Max,
+		Expiration:   cfg.Window,
+		KeyGenerator: key,
+		LimitReached: func(c *fiber.Ctx) error {
+			c.Set(fiber.HeaderRetryAfter, retryAfter)
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"message": "Too many requests. Please slow down and try again later.",
+			})
+		},
+	})
+}
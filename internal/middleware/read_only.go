@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// readOnlyRetryAfter is the Retry-After hint, in seconds, sent while mutations are rejected.
+const readOnlyRetryAfter = "60"
+
+// ReadOnly rejects mutating requests with 503 while read-only mode is enabled.
+// Safe methods are always served. Exempt paths, such as the switch that turns
+// read-only mode off again, are let through.
+func ReadOnly(maintenance *services.MaintenanceService, exempt ...string) fiber.Handler {
+	exemptPaths := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		exemptPaths[path] = true
+	}
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+		status := maintenance.ReadOnly()
+		if !status.Enabled || exemptPaths[c.Path()] {
+			return c.Next()
+		}
+		c.Set(fiber.HeaderRetryAfter, readOnlyRetryAfter)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"message": "The store is temporarily read-only. Please try again later.",
+			"reason":  status.Reason,
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"toko/internal/events"
+	"toko/pkg/httpclient"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestContext copies the request ID assigned by the requestid middleware
+// into the request's user context, so outbound calls and events published
+// while handling the request carry the same ID. The request starts a chain of
+// work, so the ID is both its correlation and causation ID. It must be
+// registered after requestid.New().
+func RequestContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if requestID, ok := c.Locals("requestid").(string); ok && requestID != "" {
+			ctx := httpclient.WithRequestID(c.UserContext(), requestID)
+			ctx = events.WithCorrelation(ctx, events.Correlation{CorrelationID: requestID, CausationID: requestID})
+			c.SetUserContext(ctx)
+		}
+		return c.Next()
+	}
+}
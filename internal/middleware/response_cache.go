@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"toko/pkg/respcache"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CacheResponse serves GET requests from the response cache for ttl.
+// Only successful responses are cached, keyed by path and query string plus
+// the currency GeoDefaults detected, and invalidating any of the tags drops
+// them. Use it only on routes whose response does not otherwise depend on who
+// is asking.
+func CacheResponse(cache *respcache.Cache, ttl time.Duration, tags ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet || ttl <= 0 {
+			return c.Next()
+		}
+
+		url := c.OriginalURL()
+		if currency, _ := c.Locals("currency").(string); currency != "" {
+			url += "#currency=" + currency
+		}
+		key, entry, err := cache.Lookup(url, tags...)
+		if err != nil {
+			log.Printf("Error reading response cache: %v", err)
+			return c.Next()
+		}
+		if entry != nil {
+			c.Set("X-Cache", "HIT")
+			c.Set(fiber.HeaderContentType, entry.ContentType)
+			return c.Status(entry.Status).Send(entry.Body)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		c.Set("X-Cache", "MISS")
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+		entry = &respcache.Entry{
+			Status:      fiber.StatusOK,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		if err := cache.Save(key, entry, ttl); err != nil {
+			log.Printf("Error writing response cache: %v", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminRequired only lets authenticated admin users through.
+// It must be registered after AuthRequired.
+func AdminRequired(authService *services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("user_id").(string)
+		role, _ := c.Locals("role").(string)
+		tokenType, _ := c.Locals("token_type").(string)
+		if !authService.IsAdmin(userID, role, tokenType) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "Admin privileges are required",
+			})
+		}
+		return c.Next()
+	}
+}
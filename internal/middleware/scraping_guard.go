@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"toko/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScrapingGuard throttles and blocks clients that walk the catalog one
+// product after another. Only GET requests for a single product (the path
+// segment after prefix) count. Each request is checked against both the
+// authenticated user, or guest, and the client IP, so neither rotating
+// accounts nor rotating addresses avoids detection on its own.
+func ScrapingGuard(scrapingService *services.ScrapingService, prefix string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !scrapingService.Enabled() || c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+
+		productID := ""
+		if rest, ok := strings.CutPrefix(c.Path(), prefix); ok {
+			productID = strings.SplitN(strings.Trim(rest, "/"), "/", 2)[0]
+		}
+		ip := c.IP()
+		userID, _ := c.Locals("user_id").(string)
+
+		now := time.Now()
+		verdict := scrapingService.Check("ip:"+ip, ip, userID, productID, now)
+		if userID != "" {
+			if byUser := scrapingService.Check("user:"+userID, ip, userID, productID, now); !verdict.Blocked && (byUser.Blocked || byUser.Delay > verdict.Delay) {
+				verdict = byUser
+			}
+		}
+		if guestID, _ := c.Locals("guest_id").(string); guestID != "" {
+			if byGuest := scrapingService.Check("guest:"+guestID, ip, "", productID, now); !verdict.Blocked && (byGuest.Blocked || byGuest.Delay > verdict.Delay) {
+				verdict = byGuest
+			}
+		}
+
+		if verdict.Blocked {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(verdict.RetryAfter.Seconds())+1))
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"message": "Too many product requests. Please slow down and try again later.",
+			})
+		}
+		if verdict.Delay > 0 {
+			time.Sleep(verdict.Delay)
+		}
+		return c.Next()
+	}
+}
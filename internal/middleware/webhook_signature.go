@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log"
+
+	"toko/pkg/webhooksig"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookSignature rejects inbound webhook requests whose HMAC signature,
+// timestamp or replay check fails. Each integration gets its own verifier.
+func WebhookSignature(verifier *webhooksig.Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := verifier.Verify(
+			c.Get(webhooksig.TimestampHeader),
+			c.Get(webhooksig.SignatureHeader),
+			c.Body(),
+		)
+		if err != nil {
+			log.Printf("Rejected %s webhook from %s: %v", verifier.Name(), c.IP(), err)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "Invalid webhook signature",
+				"error":   err.Error(),
+			})
+		}
+		return c.Next()
+	}
+}
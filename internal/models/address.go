@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PostalAddress is a delivery or billing address. Orders keep their own copy
+// so that later address book changes never rewrite past orders.
+type PostalAddress struct {
+	RecipientName string `json:"recipient_name" gorm:"type:varchar(255)" validate:"required,max=255"`
+	Phone         string `json:"phone,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"`
+	Line1         string `json:"line1" gorm:"type:varchar(255)" validate:"required,max=255"`
+	Line2         string `json:"line2,omitempty" gorm:"type:varchar(255)" validate:"omitempty,max=255"`
+	City          string `json:"city" gorm:"type:varchar(100)" validate:"required,max=100"`
+	Region        string `json:"region,omitempty" gorm:"type:varchar(100)" validate:"omitempty,max=100"` // State or province
+	PostalCode    string `json:"postal_code" gorm:"type:varchar(20)" validate:"required,max=20"`
+	Country       string `json:"country" gorm:"type:varchar(2)" validate:"required,iso3166_1_alpha2"`
+	// Coordinates are optional; they place the address in map-drawn delivery zones
+	Latitude  float64 `json:"latitude,omitempty" validate:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude,omitempty" validate:"gte=-180,lte=180"`
+}
+
+// HasCoordinates reports whether the address was pinned on a map.
+func (a PostalAddress) HasCoordinates() bool {
+	return a.Latitude != 0 || a.Longitude != 0
+}
+
+// IsZero reports whether no address was given.
+func (a PostalAddress) IsZero() bool {
+	return a == PostalAddress{}
+}
+
+// Lines returns the address as printed on a label, skipping empty lines.
+func (a PostalAddress) Lines() []string {
+	var lines []string
+	for _, line := range []string{
+		a.RecipientName,
+		a.Phone,
+		a.Line1,
+		a.Line2,
+		strings.Join(strings.Fields(a.City+" "+a.Region+" "+a.PostalCode), " "),
+		a.Country,
+	} {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Validate checks that the fields needed to deliver to the address are present.
+func (a PostalAddress) Validate() error {
+	var missing []string
+	for _, field := range []struct{ name, value string }{
+		{"recipient_name", a.RecipientName},
+		{"line1", a.Line1},
+		{"city", a.City},
+		{"postal_code", a.PostalCode},
+		{"country", a.Country},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			missing = append(missing, field.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("address is missing %s", strings.Join(missing, ", "))
+	}
+	if len(a.Country) != 2 {
+		return fmt.Errorf("address country must be a two-letter ISO 3166 code")
+	}
+	return nil
+}
+
+// Address is an entry in a user's address book.
+type Address struct {
+	ID            string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID        string `json:"-" gorm:"type:varchar(36);index;not null"`
+	Label         string `json:"label,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"` // e.g. "Home", "Office"
+	PostalAddress `gorm:"embedded"`
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ArchivedOrder records that an order was moved out of the database into a
+// compressed archive in storage, and which archive holds it.
+type ArchivedOrder struct {
+	OrderID        string    `json:"order_id" gorm:"primaryKey;type:varchar(36)"`
+	UserID         string    `json:"user_id" gorm:"type:varchar(36);index"`
+	Status         string    `json:"status" gorm:"type:varchar(20)"`
+	GrandTotal     float64   `json:"grand_total"`
+	ArchiveKey     string    `json:"archive_key" gorm:"type:varchar(255);not null"` // Storage key of the archive
+	OrderCreatedAt time.Time `json:"order_created_at" gorm:"index"`
+	ArchivedAt     time.Time `json:"archived_at"`
+}
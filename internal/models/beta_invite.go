@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// BetaInvite allows an email address to register and log in while the store
+// runs in invite-only beta mode.
+type BetaInvite struct {
+	Email     string     `json:"email" gorm:"primaryKey;type:varchar(255)" validate:"required,email"`
+	Note      string     `json:"note,omitempty" gorm:"type:varchar(255)" validate:"omitempty,max=255"`
+	InvitedBy string     `json:"invited_by,omitempty" gorm:"type:varchar(36)"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // Set when the invited email registers
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// BinLocation is where a product is kept in a warehouse, printed on packing
+// slips and pick lists so pickers can find it.
+type BinLocation struct {
+	ProductID string    `json:"product_id" gorm:"primaryKey;type:varchar(36)"`
+	Warehouse string    `json:"warehouse" gorm:"primaryKey;type:varchar(50)"`
+	Aisle     string    `json:"aisle,omitempty" gorm:"type:varchar(20)" validate:"omitempty,max=20"`
+	Shelf     string    `json:"shelf,omitempty" gorm:"type:varchar(20)" validate:"omitempty,max=20"`
+	Bin       string    `json:"bin,omitempty" gorm:"type:varchar(20)" validate:"omitempty,max=20"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Code returns the location as printed, e.g. "A-03-12" for aisle A, shelf 03, bin 12.
+func (l BinLocation) Code() string {
+	var parts []string
+	for _, part := range []string{l.Aisle, l.Shelf, l.Bin} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
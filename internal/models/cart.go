@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Cart holds the products a shopper intends to buy. A user has at most one
+// cart; anonymous carts are identified by a random token instead.
+type Cart struct {
+	ID     string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID string     `json:"-" gorm:"type:varchar(36);index"`               // Empty for anonymous carts
+	Token  string     `json:"token,omitempty" gorm:"type:varchar(64);index"` // Set on anonymous carts only
+	Items  []CartItem `json:"items" gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE"`
+	// Lines the user set aside for later; stored with the items and never
+	// part of checkout
+	SavedItems []CartItem `json:"saved_items" gorm:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"index"`
+	// Subtotal at current prices, and problems checkout would run into,
+	// computed when the cart is read
+	Subtotal float64       `json:"subtotal" gorm:"-"`
+	Warnings []CartWarning `json:"warnings,omitempty" gorm:"-"`
+}
+
+// CartWarning tells the shopper about a cart line that changed since it was
+// added, e.g. a product that sold out or got more expensive.
+type CartWarning struct {
+	ProductID string  `json:"product_id"`
+	Code      string  `json:"code"` // e.g. "out_of_stock" or "price_changed"
+	Message   string  `json:"message"`
+	Available *int    `json:"available,omitempty"` // Units that can still be bought
+	OldPrice  float64 `json:"old_price,omitempty"`
+	NewPrice  float64 `json:"new_price,omitempty"`
+}
+
+// IsAnonymous reports whether the cart belongs to a shopper who is not logged in.
+func (c *Cart) IsAnonymous() bool {
+	return c.UserID == ""
+}
+
+// SplitSavedItems moves the lines saved for later from Items, where they are
+// loaded from storage, to SavedItems.
+func (c *Cart) SplitSavedItems() {
+	items := make([]CartItem, 0, len(c.Items))
+	c.SavedItems = []CartItem{}
+	for _, item := range c.Items {
+		if item.Saved {
+			c.SavedItems = append(c.SavedItems, item)
+		} else {
+			items = append(items, item)
+		}
+	}
+	c.Items = items
+}
+
+// StoredItems returns the items followed by the saved items, marked as such,
+// as they are stored.
+func (c *Cart) StoredItems() []CartItem {
+	items := make([]CartItem, 0, len(c.Items)+len(c.SavedItems))
+	for _, item := range c.Items {
+		item.Saved = false
+		items = append(items, item)
+	}
+	for _, item := range c.SavedItems {
+		item.Saved = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// CartItem is a product and quantity in a cart.
+type CartItem struct {
+	ID        uint   `json:"id,omitempty" gorm:"primaryKey"`
+	CartID    string `json:"-" gorm:"type:varchar(36);index;not null"`
+	ProductID string `json:"product_id" gorm:"type:varchar(36);not null"`
+	Quantity  int    `json:"quantity" gorm:"not null"`
+	// Price the shopper saw when they last added or changed the line
+	AddedPrice float64   `json:"added_price"`
+	Saved      bool      `json:"-" gorm:"not null;default:false"` // Saved for later
+	CreatedAt  time.Time `json:"added_at"`
+	// Current product details, filled in when the cart is read
+	Name  string  `json:"name,omitempty" gorm:"-"`
+	Price float64 `json:"price" gorm:"-"`
+}
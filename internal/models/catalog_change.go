@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Catalog change types.
+const (
+	CatalogChangeCreated = "created"
+	CatalogChangeUpdated = "updated"
+	CatalogChangeDeleted = "deleted"
+)
+
+// CatalogChange is an entry of the catalog change feed. It is written in the
+// same transaction as the product change, so the feed never misses one; its
+// ID is the cursor consumers resume from.
+type CatalogChange struct {
+	ID        uint            `json:"cursor,string" gorm:"primaryKey"`
+	ProductID string          `json:"product_id" gorm:"type:varchar(36);index"`
+	Type      string          `json:"type" gorm:"type:varchar(10)"`
+	Product   json.RawMessage `json:"product,omitempty" gorm:"type:text"` // The product after the change; empty for deletions
+	CreatedAt time.Time       `json:"created_at"`
+}
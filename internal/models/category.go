@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Category groups products and sets the tax and shipping classes its
+// products use unless a product overrides them.
+type Category struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Name          string    `json:"name" gorm:"type:varchar(100);uniqueIndex" validate:"required,max=100"`
+	TaxClass      string    `json:"tax_class,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"`
+	ShippingClass string    `json:"shipping_class,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TaxClass is a named tax rate, e.g. "standard" at 11% or "exempt" at 0%.
+type TaxClass struct {
+	Code      string    `json:"code" gorm:"primaryKey;type:varchar(50)"`
+	Name      string    `json:"name" gorm:"type:varchar(100)" validate:"required,max=100"`
+	Rate      float64   `json:"rate" validate:"gte=0,lte=1"` // Fraction of the net price, e.g. 0.11
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ShippingClass groups products that cost the same to ship, e.g. "bulky" or "fragile".
+type ShippingClass struct {
+	Code      string    `json:"code" gorm:"primaryKey;type:varchar(50)"`
+	Name      string    `json:"name" gorm:"type:varchar(100)" validate:"required,max=100"`
+	Surcharge float64   `json:"surcharge" validate:"gte=0"` // Added to every shipping rate per unit of the class
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
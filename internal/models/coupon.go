@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Coupon discount types.
+const (
+	CouponTypePercent = "percent" // Value is a percentage of the subtotal
+	CouponTypeFixed   = "fixed"   // Value is an amount off the subtotal
+)
+
+// Coupon is a promo code customers enter at checkout for a discount.
+type Coupon struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Code         string     `json:"code" gorm:"type:varchar(50);uniqueIndex"` // Stored upper-case; matched case-insensitively
+	Type         string     `json:"type" gorm:"type:varchar(10)"`
+	Value        float64    `json:"value"`
+	MinSpend     float64    `json:"min_spend"`      // Subtotal required before the coupon applies
+	UsageLimit   int        `json:"usage_limit"`    // Total redemptions allowed; 0 is unlimited
+	PerUserLimit int        `json:"per_user_limit"` // Redemptions allowed per customer; 0 is unlimited
+	UsedCount    int        `json:"used_count"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Active       bool       `json:"active" gorm:"default:true"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// Customer tag conditions, e.g. VIP-only codes or none for chargeback risks
+	CustomerTag        string `json:"customer_tag,omitempty" gorm:"type:varchar(50)"`         // Required tag
+	ExcludeCustomerTag string `json:"exclude_customer_tag,omitempty" gorm:"type:varchar(50)"` // Customers with this tag cannot use the coupon
+}
+
+// Discount returns the amount the coupon takes off a subtotal, never more
+// than the subtotal itself, rounded to cents.
+func (c *Coupon) Discount(subtotal float64) float64 {
+	discount := c.Value
+	if c.Type == CouponTypePercent {
+		discount = subtotal * c.Value / 100
+	}
+	return math.Round(math.Min(discount, subtotal)*100) / 100
+}
+
+// CouponRedemption records a coupon used on an order.
+type CouponRedemption struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CouponID  string    `json:"coupon_id" gorm:"type:varchar(36);index"`
+	OrderID   string    `json:"order_id" gorm:"type:varchar(36);uniqueIndex"`
+	UserID    string    `json:"user_id" gorm:"type:varchar(36);index"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Common customer tags. Staff may use any other tag as well.
+const (
+	CustomerTagVIP            = "vip"
+	CustomerTagChargebackRisk = "chargeback-risk"
+)
+
+// CustomerTag labels a customer for support staff and promotion rules.
+type CustomerTag struct {
+	UserID    string    `json:"user_id" gorm:"primaryKey;type:varchar(36)"`
+	Tag       string    `json:"tag" gorm:"primaryKey;type:varchar(50);index"`
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(36)"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CustomerNote is an internal note staff keep on a customer. Customers never see it.
+type CustomerNote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"type:varchar(36);index"`
+	AuthorID  string    `json:"author_id" gorm:"type:varchar(36)"`
+	Body      string    `json:"body" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// DeliverySlot is a delivery window customers can book at checkout. Each
+// order placed in the window takes one unit of its capacity.
+type DeliverySlot struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	StartsAt  time.Time `json:"starts_at" gorm:"index" validate:"required"`
+	EndsAt    time.Time `json:"ends_at" validate:"required"`
+	Capacity  int       `json:"capacity" validate:"gte=0"`
+	Booked    int       `json:"booked"`
+	Active    bool      `json:"active"` // Inactive slots are hidden and cannot be booked
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Remaining returns the number of orders the slot can still take.
+func (s *DeliverySlot) Remaining() int {
+	return max(s.Capacity-s.Booked, 0)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DeliveryZone is an area the store delivers to, drawn on a map or listed by
+// postal code, with its own delivery fee and minimum order value.
+type DeliveryZone struct {
+	ID      string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Name    string `json:"name" gorm:"type:varchar(100)" validate:"required,max=100"`
+	Country string `json:"country,omitempty" gorm:"type:varchar(2)" validate:"omitempty,iso3166_1_alpha2"` // Empty matches every country
+	// PostalCodes is a comma-separated list of postal codes; a trailing * matches a prefix, e.g. "12*,40115"
+	PostalCodes string `json:"postal_codes,omitempty" gorm:"type:text"`
+	// Polygon is the zone's outline as [latitude, longitude] points. Only
+	// addresses with coordinates can fall inside it.
+	Polygon   [][2]float64 `json:"polygon,omitempty" gorm:"type:text;serializer:json"`
+	Fee       float64      `json:"fee" validate:"gte=0"`       // Added to every shipping rate
+	MinOrder  float64      `json:"min_order" validate:"gte=0"` // Smallest subtotal delivered to the zone
+	Active    bool         `json:"active"`                     // Inactive zones refuse delivery, e.g. during floods
+	Priority  int          `json:"priority"`                   // Higher priorities are matched first where zones overlap
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// Contains reports whether the address lies in the zone, either inside its
+// polygon or at one of its postal codes.
+func (z *DeliveryZone) Contains(address PostalAddress) bool {
+	if z.Country != "" && !strings.EqualFold(z.Country, address.Country) {
+		return false
+	}
+	if len(z.Polygon) >= 3 && address.HasCoordinates() && insidePolygon(z.Polygon, address.Latitude, address.Longitude) {
+		return true
+	}
+	postalCode := NormalizePostalCode(address.PostalCode)
+	if postalCode == "" {
+		return false
+	}
+	for _, pattern := range strings.Split(z.PostalCodes, ",") {
+		pattern = NormalizePostalCode(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(postalCode, prefix) {
+				return true
+			}
+		} else if pattern != "" && pattern == postalCode {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePostalCode upper-cases a postal code and removes its spaces and dashes.
+func NormalizePostalCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// insidePolygon casts a ray from the point and counts how many edges it
+// crosses; an odd count means the point is inside.
+func insidePolygon(polygon [][2]float64, lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		latI, lngI := polygon[i][0], polygon[i][1]
+		latJ, lngJ := polygon[j][0], polygon[j][1]
+		if (lngI > lng) != (lngJ > lng) && lat < (latJ-latI)*(lng-lngI)/(lngJ-lngI)+latI {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DeviceToken keeps a device such as a phone app logged in for a long time
+// without long-lived access tokens: the app exchanges it for a new login
+// when its refresh token runs out. It only works together with the
+// fingerprint of the device it was issued to. Only hashes of the token and
+// fingerprint are stored.
+type DeviceToken struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          string     `json:"-" gorm:"type:varchar(36);index"`
+	Name            string     `json:"name" gorm:"type:varchar(100)"` // Given by the device, e.g. "Pixel 8"
+	TokenHash       string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	FingerprintHash string     `json:"-" gorm:"type:varchar(64)"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"index"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt       *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ERP sync directions and run statuses.
+const (
+	SyncDirectionPull = "pull" // External system to store
+	SyncDirectionPush = "push" // Store to external system
+
+	SyncStatusOK     = "ok"
+	SyncStatusFailed = "failed"
+)
+
+// SyncState is the progress of one connector syncing one entity in one direction.
+type SyncState struct {
+	Connector string    `json:"connector" gorm:"primaryKey;type:varchar(50)"`
+	Entity    string    `json:"entity" gorm:"primaryKey;type:varchar(20)"`
+	Direction string    `json:"direction" gorm:"primaryKey;type:varchar(10)"`
+	Cursor    time.Time `json:"cursor"` // Changes up to this time have been synced
+	LastRunAt time.Time `json:"last_run_at"`
+	Status    string    `json:"status" gorm:"type:varchar(10)"`
+	Error     string    `json:"error,omitempty" gorm:"type:text"`
+	Records   int       `json:"records"`   // Records synced in the last run
+	Conflicts int       `json:"conflicts"` // Conflicts found in the last run
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SyncConflict records a field changed both in the store and in the external
+// system since the last sync, and which side was kept.
+type SyncConflict struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Connector     string    `json:"connector" gorm:"type:varchar(50);index"`
+	Entity        string    `json:"entity" gorm:"type:varchar(20)"`
+	Key           string    `json:"key" gorm:"type:varchar(100)"` // SKU or order ID
+	Field         string    `json:"field" gorm:"type:varchar(50)"`
+	StoreValue    string    `json:"store_value"`
+	ExternalValue string    `json:"external_value"`
+	Resolution    string    `json:"resolution" gorm:"type:varchar(10)"` // The side that was kept: "store" or "external"
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
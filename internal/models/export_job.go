@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Export types.
+const (
+	ExportTypeOrders   = "orders"
+	ExportTypeProducts = "products"
+	ExportTypeGDPR     = "gdpr" // All personal data held about the requesting user
+)
+
+// Export job statuses.
+const (
+	ExportStatusQueued    = "queued"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// ExportJob tracks a long-running export requested through the API.
+type ExportJob struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      string     `json:"user_id" gorm:"type:varchar(36);index"`
+	Type        string     `json:"type" gorm:"type:varchar(20)"`
+	Status      string     `json:"status" gorm:"type:varchar(20)"`
+	Progress    int        `json:"progress"` // Percentage between 0 and 100
+	FileKey     string     `json:"-" gorm:"type:varchar(255)"`
+	FileName    string     `json:"file_name,omitempty" gorm:"type:varchar(255)"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	DownloadURL string     `json:"download_url,omitempty" gorm:"-"` // Signed on demand once completed
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
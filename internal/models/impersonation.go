@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Impersonation is the audit record of an admin acting as a customer, such
+// as to reproduce a problem they reported. TokenID is the jti claim of the
+// token issued for it.
+type Impersonation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	AdminID   string    `json:"admin_id" gorm:"type:varchar(36);index"`
+	UserID    string    `json:"user_id" gorm:"type:varchar(36);index"`
+	Reason    string    `json:"reason" gorm:"type:varchar(500)"`
+	IPAddress string    `json:"ip_address" gorm:"type:varchar(45)"`
+	TokenID   string    `json:"token_id" gorm:"type:varchar(36)"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImpersonationAction is the audit record of a change made with an
+// impersonation token, attributing it to the admin behind it.
+type ImpersonationAction struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ImpersonatorID string    `json:"impersonator_id" gorm:"type:varchar(36);index"`
+	UserID         string    `json:"user_id" gorm:"type:varchar(36);index"`
+	TokenID        string    `json:"token_id" gorm:"type:varchar(36)"`
+	Method         string    `json:"method" gorm:"type:varchar(10)"`
+	Path           string    `json:"path" gorm:"type:varchar(255)"`
+	Status         int       `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+}
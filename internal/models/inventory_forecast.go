@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// InventoryForecast is the latest stock projection of a physical product,
+// recomputed periodically from its recent sales velocity.
+type InventoryForecast struct {
+	ProductID       string    `json:"product_id" gorm:"primaryKey;type:varchar(36)"`
+	SKU             string    `json:"sku,omitempty" gorm:"type:varchar(100)"`
+	Name            string    `json:"name"`
+	Stock           int       `json:"stock"`
+	UnitsSold       int       `json:"units_sold"`               // Units sold during the forecast window
+	DailyVelocity   float64   `json:"daily_velocity"`           // Average units sold per day
+	DaysRemaining   *float64  `json:"days_remaining,omitempty"` // Days of stock left at the current velocity; nil when nothing sells
+	ReorderPoint    int       `json:"reorder_point"`            // Reorder when stock falls to this level
+	ReorderQuantity int       `json:"reorder_quantity"`         // Suggested units to order
+	LowStock        bool      `json:"low_stock" gorm:"index"`
+	ComputedAt      time.Time `json:"computed_at"`
+}
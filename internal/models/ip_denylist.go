@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DeniedIP is an IP address or CIDR range blocked from accessing the API.
+type DeniedIP struct {
+	CIDR      string     `json:"cidr" validate:"required"`
+	Reason    string     `json:"reason" validate:"omitempty,max=255"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil means the entry never expires
+}
+
+// IsExpired reports whether the entry has passed its expiry time.
+func (d *DeniedIP) IsExpired(now time.Time) bool {
+	return d.ExpiresAt != nil && now.After(*d.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// License key sources of digital products.
+const (
+	LicenseSourceGenerated = "generated" // A new key is generated for every unit sold
+	LicenseSourcePool      = "pool"      // Keys are taken from a pool uploaded by admins
+)
+
+// LicenseKey is a software license key of a digital product. Pool keys have
+// no order until they are handed out; generated keys are created assigned.
+type LicenseKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ProductID  string     `json:"product_id" gorm:"type:varchar(36);uniqueIndex:idx_license_keys_product_key;index:idx_license_keys_available"`
+	Key        string     `json:"key" gorm:"type:varchar(255);uniqueIndex:idx_license_keys_product_key"`
+	OrderID    string     `json:"order_id,omitempty" gorm:"type:varchar(36);index;index:idx_license_keys_available"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// LoginEvent records a successful login, kept so users and staff can spot
+// logins from devices or places they do not recognize.
+type LoginEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"-" gorm:"type:varchar(36);index"`
+	SessionID string    `json:"session_id,omitempty" gorm:"type:varchar(36)"`
+	UserAgent string    `json:"user_agent" gorm:"type:varchar(255)"`
+	IPAddress string    `json:"ip_address" gorm:"type:varchar(45)"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName keeps the table named after what it holds rather than the type.
+func (LoginEvent) TableName() string {
+	return "login_history"
+}
@@ -1,21 +1,231 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Order item statuses. Items of an unpaid order are pending; once it is paid
+// each item is fulfilled on its own and the order's status follows them.
+const (
+	ItemStatusPending     = "pending"
+	ItemStatusProcessing  = "processing"  // Paid and waiting to be picked
+	ItemStatusBackordered = "backordered" // Paid but waiting for stock
+	ItemStatusShipped     = "shipped"
+	ItemStatusDelivered   = "delivered"
+	ItemStatusCancelled   = "cancelled"
+)
 
 // OrderItem represents a single item within an order.
 type OrderItem struct {
-	ProductID string  `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"` // Price at the time of order
+	ID           uint     `json:"id,omitempty" gorm:"primaryKey"`
+	OrderID      string   `json:"-" gorm:"type:varchar(36);index;not null"`
+	ProductID    string   `json:"product_id" gorm:"type:varchar(36);index;not null"`
+	Product      *Product `json:"-" gorm:"foreignKey:ProductID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Quantity     int      `json:"quantity" gorm:"not null"`
+	Price        float64  `json:"price"` // Price at the time of order
+	Status       string   `json:"status,omitempty" gorm:"type:varchar(20);default:pending"`
+	CancelReason string   `json:"cancel_reason,omitempty" gorm:"type:varchar(50)"`
+}
+
+// ItemStatus returns the item's status; items saved before statuses were tracked are pending.
+func (i *OrderItem) ItemStatus() string {
+	if i.Status == "" {
+		return ItemStatusPending
+	}
+	return i.Status
+}
+
+// AwaitingShipment reports whether the item still has to be picked and shipped.
+func (i *OrderItem) AwaitingShipment() bool {
+	status := i.ItemStatus()
+	return status == ItemStatusPending || status == ItemStatusProcessing
+}
+
+// DeriveOrderStatus returns the order status implied by its items' statuses:
+// cancelled when every item is cancelled, delivered or shipped when every
+// remaining item is, and partially_shipped when only some are. It returns ""
+// while no item has shipped, leaving the order's own status in charge.
+func DeriveOrderStatus(items []OrderItem) string {
+	active, shipped, delivered := 0, 0, 0
+	for i := range items {
+		switch items[i].ItemStatus() {
+		case ItemStatusCancelled:
+			continue
+		case ItemStatusDelivered:
+			delivered++
+			shipped++
+		case ItemStatusShipped:
+			shipped++
+		}
+		active++
+	}
+	switch {
+	case len(items) > 0 && active == 0:
+		return "cancelled"
+	case active > 0 && delivered == active:
+		return "delivered"
+	case active > 0 && shipped == active:
+		return "shipped"
+	case shipped > 0:
+		return "partially_shipped"
+	}
+	return ""
 }
 
 // Order represents a customer order.
 type Order struct {
-	ID          string      `json:"id"`
-	UserID      string      `json:"user_id"`
-	Items       []OrderItem `json:"items"`
-	TotalAmount float64     `json:"total_amount"`
-	Status      string      `json:"status"` // e.g., "pending", "processing", "shipped", "delivered", "cancelled"
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID        string      `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    string      `json:"user_id" gorm:"type:varchar(36);index"`
+	Items     []OrderItem `json:"items" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	Status    string      `json:"status" gorm:"type:varchar(20);index"` // e.g., "pending", "payment_failed", "processing", "preorder", "partially_shipped", "shipped", "delivered", "cancelled"
+	CreatedAt time.Time   `json:"created_at" gorm:"index"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	// Addresses are copied from the request or address book when the order is placed
+	ShippingAddress PostalAddress `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  PostalAddress `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+	// Totals, computed when the order is placed; see UpdateGrandTotal
+	Subtotal       float64 `json:"subtotal"`       // Items at their order prices
+	DiscountTotal  float64 `json:"discount_total"` // Coupon discount
+	TaxTotal       float64 `json:"tax_total"`
+	ShippingTotal  float64 `json:"shipping_total"`
+	GrandTotal     float64 `json:"grand_total"`
+	CouponCode     string  `json:"coupon_code,omitempty" gorm:"type:varchar(50)"`
+	ShippingMethod string  `json:"shipping_method,omitempty" gorm:"type:varchar(100)"` // Provider and service, e.g. "flat/standard"
+	// Gift orders get a gift receipt without prices alongside the invoice
+	IsGift      bool   `json:"is_gift"`
+	GiftMessage string `json:"gift_message,omitempty" gorm:"type:varchar(500)"`
+	// Business customers' details for the invoice; which checkout fields are
+	// asked for is configured per store, see services.CheckoutFields
+	CompanyName string `json:"company_name,omitempty" gorm:"type:varchar(255)"`
+	TaxID       string `json:"tax_id,omitempty" gorm:"type:varchar(50)"`
+	// Why and when the order was cancelled
+	CancelReason string     `json:"cancel_reason,omitempty" gorm:"type:varchar(50);index"`
+	CancelNote   string     `json:"cancel_note,omitempty" gorm:"type:varchar(500)"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" gorm:"index"`
+	// Latest release date of the order's preorder items; paid orders wait in
+	// the preorder status until then
+	ReleaseAt *time.Time `json:"release_at,omitempty" gorm:"index"`
+	// Delivery window booked at checkout, if any
+	DeliverySlotID string     `json:"delivery_slot_id,omitempty" gorm:"type:varchar(36);index"`
+	DeliveryStart  *time.Time `json:"delivery_start,omitempty"`
+	DeliveryEnd    *time.Time `json:"delivery_end,omitempty"`
+	// Shipment tracking, set from the shipping provider's webhook
+	Carrier        string `json:"carrier,omitempty" gorm:"type:varchar(100)"`
+	TrackingNumber string `json:"tracking_number,omitempty" gorm:"type:varchar(100)"`
+	// Set by integrations such as ERPs and marketplaces: their own ID for the
+	// order, and any data they want to keep with it
+	ExternalRef string        `json:"external_ref,omitempty" gorm:"type:varchar(100);index"`
+	Metadata    OrderMetadata `json:"metadata,omitempty" gorm:"type:jsonb"`
+	// With multi-currency enabled: the store currency the totals are in, and
+	// the currency and exchange rate the customer was shown, frozen when the
+	// order is placed so later reports use the rate of the day of the sale
+	Currency            string  `json:"currency,omitempty" gorm:"type:varchar(3)"`
+	PresentmentCurrency string  `json:"presentment_currency,omitempty" gorm:"type:varchar(3);index"`
+	ExchangeRate        float64 `json:"exchange_rate,omitempty"`     // Presentment currency units per store currency unit
+	PresentmentTotal    float64 `json:"presentment_total,omitempty"` // Grand total in the presentment currency
+	// Fulfillment milestones, recorded the first time the order reaches each
+	// stage; see ReachedMilestones
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	ShippedAt   *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// OrderMetadata is free-form JSON attached to an order, stored as JSONB.
+type OrderMetadata map[string]interface{}
+
+// Value encodes the metadata as JSON; empty metadata is stored as NULL.
+func (m OrderMetadata) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan decodes metadata read from the database.
+func (m *OrderMetadata) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported order metadata type %T", value)
+	}
+	return json.Unmarshal(data, m)
+}
+
+// UpdateGrandTotal sets GrandTotal to the subtotal less the discount, plus
+// tax and shipping.
+func (o *Order) UpdateGrandTotal() {
+	o.GrandTotal = math.Round((o.Subtotal-o.DiscountTotal+o.TaxTotal+o.ShippingTotal)*100) / 100
+}
+
+// ToPresentment converts an amount in the store currency to the order's
+// presentment currency at the rate frozen on the order. Orders placed
+// without multi-currency keep the amount as is.
+func (o *Order) ToPresentment(amount float64) float64 {
+	if o.ExchangeRate <= 0 {
+		return amount
+	}
+	return math.Round(amount*o.ExchangeRate*100) / 100
+}
+
+// Fulfillment milestones of an order, named after their columns.
+const (
+	MilestonePaid      = "paid_at"
+	MilestoneShipped   = "shipped_at"
+	MilestoneDelivered = "delivered_at"
+)
+
+// ReachedMilestones returns the milestones an order in the status has passed.
+func ReachedMilestones(status string) []string {
+	switch status {
+	case "processing", "preorder":
+		return []string{MilestonePaid}
+	case "partially_shipped", "shipped":
+		return []string{MilestonePaid, MilestoneShipped}
+	case "delivered":
+		return []string{MilestonePaid, MilestoneShipped, MilestoneDelivered}
+	}
+	return nil
+}
+
+// RecordMilestones sets the times of the milestones the order's status has
+// reached that are not set yet.
+func (o *Order) RecordMilestones(at time.Time) {
+	for _, milestone := range ReachedMilestones(o.Status) {
+		var field **time.Time
+		switch milestone {
+		case MilestonePaid:
+			field = &o.PaidAt
+		case MilestoneShipped:
+			field = &o.ShippedAt
+		case MilestoneDelivered:
+			field = &o.DeliveredAt
+		}
+		if *field == nil {
+			t := at
+			*field = &t
+		}
+	}
+}
+
+// DownloadLink is an expiring, signed link to a purchased digital product.
+type DownloadLink struct {
+	ProductID string    `json:"product_id"`
+	FileName  string    `json:"file_name"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// OutboxEvent is an event kept in the database while RabbitMQ is
+// unreachable. It is deleted once the broker has taken it.
+type OutboxEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Exchange   string    `json:"exchange" gorm:"type:varchar(100);not null"`
+	RoutingKey string    `json:"routing_key" gorm:"type:varchar(100);not null"`
+	MessageID  string    `json:"message_id" gorm:"type:varchar(36)"` // Published with every attempt
+	Body       []byte    `json:"body" gorm:"not null"`
+	Headers    []byte    `json:"headers"` // JSON object of the AMQP headers
+	CreatedAt  time.Time `json:"created_at"`
+}
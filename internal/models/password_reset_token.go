@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordResetToken lets whoever received it by email set a new password
+// for the user once. Only a hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"type:varchar(36);index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
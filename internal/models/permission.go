@@ -0,0 +1,61 @@
+package models
+
+import "strings"
+
+// Permissions grant access to parts of the admin API. Each is
+// "<resource>:<action>"; "<resource>:*" grants every action on a resource
+// and PermissionAll grants everything.
+const (
+	PermissionAll = "*"
+
+	PermissionProductsRead  = "products:read"
+	PermissionProductsWrite = "products:write" // Products and their files, categories, classes, bin locations, license pools and synonyms
+	PermissionOrdersRead    = "orders:read"
+	PermissionOrdersWrite   = "orders:write"
+	PermissionOrdersRefund  = "orders:refund" // Reviewing returns
+	PermissionCustomersRead = "customers:read"
+	PermissionCustomersEdit = "customers:write"
+	PermissionCouponsRead   = "coupons:read"
+	PermissionCouponsWrite  = "coupons:write"
+	PermissionReportsRead   = "reports:read"
+)
+
+// KnownPermissions lists every permission that can be granted.
+var KnownPermissions = []string{
+	PermissionProductsRead, PermissionProductsWrite,
+	PermissionOrdersRead, PermissionOrdersWrite, PermissionOrdersRefund,
+	PermissionCustomersRead, PermissionCustomersEdit,
+	PermissionCouponsRead, PermissionCouponsWrite,
+	PermissionReportsRead,
+}
+
+// PermissionGrants reports whether holding granted gives permission.
+func PermissionGrants(granted, permission string) bool {
+	if granted == PermissionAll || granted == permission {
+		return true
+	}
+	resource, ok := strings.CutSuffix(granted, ":*")
+	return ok && strings.HasPrefix(permission, resource+":")
+}
+
+// IsKnownPermission reports whether permission is one of KnownPermissions,
+// a "<resource>:*" wildcard of one of them, or PermissionAll.
+func IsKnownPermission(permission string) bool {
+	for _, known := range KnownPermissions {
+		if PermissionGrants(permission, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePermissions splits a comma-separated permission list.
+func ParsePermissions(list string) []string {
+	var permissions []string
+	for _, permission := range strings.Split(list, ",") {
+		if permission = strings.TrimSpace(permission); permission != "" {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
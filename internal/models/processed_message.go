@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Processed message states.
+const (
+	MessageStateNew        = "new" // Returned when a message is claimed for the first time
+	MessageStateProcessing = "processing"
+	MessageStateDone       = "done"
+)
+
+// ProcessedMessage records that a consumer has handled, or is handling, a
+// message so that redeliveries are not processed twice.
+type ProcessedMessage struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(255)"` // "<consumer>:<message ID>"
+	State     string    `json:"state" gorm:"type:varchar(20)"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
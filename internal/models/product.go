@@ -1,13 +1,77 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Product statuses. An empty status is treated as active.
+const (
+	ProductStatusActive   = "active"
+	ProductStatusArchived = "archived"
+)
+
+// Product types. Digital products are delivered as downloads and have no stock.
+const (
+	ProductTypePhysical = "physical"
+	ProductTypeDigital  = "digital"
+)
 
 // Product represents a product in the store.
 type Product struct {
-	ID          string  `json:"id" gorm:"primaryKey;type:varchar(36)" validate:"omitempty,uuid"`
-	Name        string  `json:"name" validate:"required,min=3,max=100"`
-	Description string  `json:"description" validate:"omitempty,max=500"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock" validate:"gte=0"`
-	gorm.Model          // Embed gorm.Model for CreatedAt, UpdatedAt, DeletedAt
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)" validate:"omitempty,uuid"`
+	Name        string     `json:"name" validate:"required,min=3,max=100"`
+	Description string     `json:"description" validate:"omitempty,max=500"`
+	SKU         string     `json:"sku,omitempty" gorm:"type:varchar(100);index" validate:"omitempty,max=100"`
+	Price       float64    `json:"price" validate:"required,gt=0"`
+	Stock       int        `json:"stock" validate:"gte=0"`
+	WeightGrams int        `json:"weight_grams,omitempty" validate:"gte=0"` // Shipping weight of one unit
+	Status      string     `json:"status" gorm:"type:varchar(20);default:active;index" validate:"omitempty,oneof=active archived"`
+	Type        string     `json:"type" gorm:"type:varchar(20);default:physical" validate:"omitempty,oneof=physical digital"`
+	FileKey     string     `json:"-" gorm:"type:varchar(255)"` // Storage key of the downloadable file
+	FileName    string     `json:"file_name,omitempty" gorm:"type:varchar(255)"`
+	LastSoldAt  *time.Time `json:"last_sold_at,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	gorm.Model             // Embed gorm.Model for CreatedAt, UpdatedAt, DeletedAt
+	// Tax and shipping classes override the category's when set
+	CategoryID    string `json:"category_id,omitempty" gorm:"type:varchar(36);index" validate:"omitempty,uuid"`
+	TaxClass      string `json:"tax_class,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"`
+	ShippingClass string `json:"shipping_class,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"`
+	// Purchase limits keep limited items from being bought out by single
+	// customers; zero means no limit
+	MaxPerOrder      int `json:"max_per_order,omitempty" validate:"gte=0"`
+	MaxPerCustomer   int `json:"max_per_customer,omitempty" validate:"gte=0"`
+	LimitWindowHours int `json:"limit_window_hours,omitempty" validate:"gte=0"` // Period MaxPerCustomer counts over; zero counts every past order
+	// Where license keys of a digital product come from; empty means it has none
+	LicenseSource string `json:"license_source,omitempty" gorm:"type:varchar(20)" validate:"omitempty,oneof=generated pool"`
+	// Preorder products can be ordered before their release date; such orders
+	// wait in the preorder status until then
+	Preorder    bool       `json:"preorder,omitempty"`
+	ReleaseDate *time.Time `json:"release_date,omitempty"`
+}
+
+// IsArchived reports whether the product has been archived from the catalog.
+func (p *Product) IsArchived() bool {
+	return p.Status == ProductStatusArchived
+}
+
+// HasPurchaseLimit reports whether customers can only buy a limited number of units.
+func (p *Product) HasPurchaseLimit() bool {
+	return p.MaxPerOrder > 0 || p.MaxPerCustomer > 0
+}
+
+// HasLicenseKeys reports whether every unit sold comes with a license key.
+func (p *Product) HasLicenseKeys() bool {
+	return p.IsDigital() && p.LicenseSource != ""
+}
+
+// AwaitingRelease reports whether the product is a preorder that is not released yet at now.
+func (p *Product) AwaitingRelease(now time.Time) bool {
+	return p.Preorder && p.ReleaseDate != nil && now.Before(*p.ReleaseDate)
+}
+
+// IsDigital reports whether the product is delivered as a download.
+func (p *Product) IsDigital() bool {
+	return p.Type == ProductTypeDigital
 }
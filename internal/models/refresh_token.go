@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RefreshToken is a single-use token that gets a user a new access token.
+// Only a hash of the token is stored. Every refresh replaces the token with
+// a new one of the same family, which is revoked as a whole when a used
+// token is presented again.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    string     `json:"user_id" gorm:"type:varchar(36);index"`
+	FamilyID  string     `json:"family_id" gorm:"type:varchar(36);index"` // Shared by the tokens descending from one login
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
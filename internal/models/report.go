@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// ProductSales summarizes the sales of a single product.
+type ProductSales struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Units     int     `json:"units"`
+	Revenue   float64 `json:"revenue"`
+}
+
+// ProductPairing counts the orders in which a product was bought along with another.
+type ProductPairing struct {
+	ProductID string `json:"product_id"`
+	Orders    int    `json:"orders"`
+}
+
+// RevenueSummary summarizes order revenue over a period.
+type RevenueSummary struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	OrderCount int64     `json:"order_count"`
+	Revenue    float64   `json:"revenue"` // Sum of grand totals
+	// Breakdown of the revenue
+	Subtotal      float64 `json:"subtotal"`
+	DiscountTotal float64 `json:"discount_total"`
+	TaxTotal      float64 `json:"tax_total"`
+	ShippingTotal float64 `json:"shipping_total"`
+	// Revenue of multi-currency orders by the currency the customers paid in
+	ByCurrency []CurrencyRevenue `json:"by_currency,omitempty"`
+}
+
+// CurrencyRevenue is the revenue of the orders presented in one currency,
+// converted at each order's own exchange rate.
+type CurrencyRevenue struct {
+	Currency           string  `json:"currency"`
+	OrderCount         int64   `json:"order_count"`
+	Revenue            float64 `json:"revenue"`             // In the store currency
+	PresentmentRevenue float64 `json:"presentment_revenue"` // In Currency
+}
+
+// ReasonCount is how often a cancellation or return reason was given in a
+// period and the value of the orders concerned.
+type ReasonCount struct {
+	Reason string  `json:"reason"` // "unspecified" for records without a reason
+	Count  int64   `json:"count"`
+	Value  float64 `json:"value"` // Sum of the orders' grand totals
+	Share  float64 `json:"share"` // Fraction of all records in the period
+}
+
+// CancellationReport ranks the reasons orders were cancelled and returned
+// over a period, most frequent first.
+type CancellationReport struct {
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"`
+	Cancellations []ReasonCount `json:"cancellations"`
+	Returns       []ReasonCount `json:"returns"`
+}
+
+// OrderMilestones are the times an order reached each fulfillment stage;
+// stages it has not reached are nil.
+type OrderMilestones struct {
+	OrderID     string
+	Status      string
+	CreatedAt   time.Time
+	PaidAt      *time.Time
+	ShippedAt   *time.Time
+	DeliveredAt *time.Time
+}
+
+// StageSLA summarizes how long orders took to get through one fulfillment
+// stage. Durations are in seconds.
+type StageSLA struct {
+	Stage     string  `json:"stage"` // payment, fulfillment, delivery or total
+	Count     int     `json:"count"` // Orders that completed the stage
+	Mean      float64 `json:"mean_seconds"`
+	P50       float64 `json:"p50_seconds"`
+	P90       float64 `json:"p90_seconds"`
+	P95       float64 `json:"p95_seconds"`
+	P99       float64 `json:"p99_seconds"`
+	Threshold float64 `json:"threshold_seconds,omitempty"` // Zero when no SLA is configured
+	// Orders that completed the stage later than the threshold, and open
+	// orders that have been in the stage longer than it
+	Breaches     int `json:"breaches"`
+	OpenBreaches int `json:"open_breaches"`
+}
+
+// SLAReport summarizes the fulfillment times of the orders placed in a period.
+type SLAReport struct {
+	From   time.Time  `json:"from"`
+	To     time.Time  `json:"to"`
+	Stages []StageSLA `json:"stages"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Return request statuses.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+)
+
+// ReturnRequest is a customer's request to send back a delivered order.
+// Approved returns carry the carrier drop-off code the customer uses to ship the parcel.
+type ReturnRequest struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	OrderID        string     `json:"order_id" gorm:"type:varchar(36);index;not null"`
+	UserID         string     `json:"user_id" gorm:"type:varchar(36);index;not null"`
+	ReasonCode     string     `json:"reason_code" gorm:"type:varchar(50);index"` // One of the configured return reasons
+	Reason         string     `json:"reason" gorm:"type:text"`                   // The customer's own words
+	Status         string     `json:"status" gorm:"type:varchar(20);index"`
+	ResolutionNote string     `json:"resolution_note,omitempty" gorm:"type:text"` // Admin's note on approval or rejection
+	Carrier        string     `json:"carrier,omitempty" gorm:"type:varchar(100)"`
+	DropOffCode    string     `json:"drop_off_code,omitempty" gorm:"type:varchar(100)"`
+	LabelURL       string     `json:"label_url,omitempty" gorm:"type:varchar(2048)"`
+	LabelExpiresAt *time.Time `json:"label_expires_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
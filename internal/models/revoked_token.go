@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken is an access token revoked before it expired, e.g. at
+// logout. It is kept until the token would have expired anyway.
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"primaryKey;type:varchar(36)"` // The token's "jti" claim
+	UserID    string    `json:"user_id" gorm:"type:varchar(36);index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
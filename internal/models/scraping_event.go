@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Scraping detection actions.
+const (
+	ScrapingActionThrottled = "throttled"
+	ScrapingActionBlocked   = "blocked"
+)
+
+// ScrapingEvent records a client that crossed a catalog scraping threshold,
+// kept for admins to review and, if needed, add to the IP denylist.
+type ScrapingEvent struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ClientKey        string    `json:"client_key" gorm:"type:varchar(100);index"` // "user:<id>" or "ip:<address>"
+	IP               string    `json:"ip" gorm:"type:varchar(45)"`
+	UserID           string    `json:"user_id,omitempty" gorm:"type:varchar(36)"`
+	DistinctProducts int       `json:"distinct_products"` // Products viewed within the detection window
+	Action           string    `json:"action" gorm:"type:varchar(20)"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// SearchQuery records a catalog search and how many products it found.
+type SearchQuery struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Query       string    `json:"query" gorm:"type:varchar(255);index"` // Normalized: trimmed, lowercased, single-spaced
+	UserID      string    `json:"user_id,omitempty" gorm:"type:varchar(36)"`
+	ResultCount int       `json:"result_count"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// SearchClick records a product opened from a search's results.
+type SearchClick struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SearchID  string    `json:"search_id" gorm:"type:varchar(36);index"`
+	ProductID string    `json:"product_id" gorm:"type:varchar(36)"`
+	Position  int       `json:"position"` // 1-based rank of the product in the results
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchQueryStats aggregates the searches for one query over a period.
+type SearchQueryStats struct {
+	Query            string  `json:"query"`
+	Searches         int64   `json:"searches"`
+	AvgResults       float64 `json:"avg_results"`
+	Clicks           int64   `json:"clicks"`
+	ClickThroughRate float64 `json:"click_through_rate"` // Share of searches with at least one click
+}
+
+// SearchReport lists the most frequent queries and those that found nothing.
+type SearchReport struct {
+	From              time.Time          `json:"from"`
+	To                time.Time          `json:"to"`
+	TopQueries        []SearchQueryStats `json:"top_queries"`
+	ZeroResultQueries []SearchQueryStats `json:"zero_result_queries"`
+}
+
+// SearchSynonym is a set of terms searched as equivalents, e.g. "laptop,notebook".
+type SearchSynonym struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Terms     string    `json:"terms" gorm:"type:varchar(1024)" validate:"required"` // Comma-separated, lowercase
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TermList returns the synonym's terms.
+func (s *SearchSynonym) TermList() []string {
+	var terms []string
+	for _, term := range strings.Split(s.Terms, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session is one login of a user on a device. Its ID is the family ID of
+// the refresh tokens descending from the login, and the sid claim of its
+// access tokens.
+type Session struct {
+	ID              string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID          string     `json:"-" gorm:"type:varchar(36);index"`
+	UserAgent       string     `json:"user_agent" gorm:"type:varchar(255)"`
+	IPAddress       string     `json:"ip_address" gorm:"type:varchar(45)"`
+	AccessJTI       string     `json:"-" gorm:"type:varchar(36)"` // The latest access token, revoked with the session
+	AccessExpiresAt time.Time  `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsedAt      time.Time  `json:"last_used_at"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"index"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	Current         bool       `json:"current" gorm:"-"` // Whether the request was made with this session
+}
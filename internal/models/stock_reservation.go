@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Stock reservation statuses.
+const (
+	ReservationStatusActive    = "active"    // Stock is held for an unpaid order
+	ReservationStatusCommitted = "committed" // The order was paid; the stock is sold
+	ReservationStatusReleased  = "released"  // The order was cancelled; stock was returned
+	ReservationStatusExpired   = "expired"   // The hold timed out; stock was returned
+)
+
+// StockReservation holds product stock for an order while the customer pays.
+type StockReservation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	OrderID   string    `json:"order_id" gorm:"type:varchar(36);index;not null"`
+	ProductID string    `json:"product_id" gorm:"type:varchar(36);index;not null"`
+	Quantity  int       `json:"quantity" gorm:"not null"`
+	Status    string    `json:"status" gorm:"type:varchar(20);index;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
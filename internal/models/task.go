@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Task statuses.
+const (
+	TaskStatusPending = "pending"
+	TaskStatusRunning = "running"
+	TaskStatusDone    = "done"
+	TaskStatusDead    = "dead" // Failed on every attempt; needs manual attention
+)
+
+// Task is a unit of background work stored in the durable task queue.
+type Task struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Type        string     `json:"type" gorm:"type:varchar(100);index;not null"`
+	Payload     string     `json:"payload" gorm:"type:text"` // JSON-encoded task arguments
+	Status      string     `json:"status" gorm:"type:varchar(20);index;not null"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	RunAt       time.Time  `json:"run_at" gorm:"index"` // Earliest time the task may run
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
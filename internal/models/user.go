@@ -1,12 +1,44 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User roles.
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+	// RoleSupport is for staff who get the admin API permissions configured
+	// for the role, and any granted to them personally.
+	RoleSupport = "support"
+	// RoleGuest marks accounts created by guest checkout. They cannot log in
+	// and become customers when someone registers with their email.
+	RoleGuest = "guest"
+)
 
 // User represents a user of the store.
 type User struct {
-	ID         string `json:"id" gorm:"primaryKey;type:varchar(36)" validate:"omitempty,uuid"`
-	Username   string `json:"username" gorm:"uniqueIndex;type:varchar(100)" validate:"required,min=3,max=100"`
-	Email      string `json:"email" gorm:"uniqueIndex;type:varchar(255)" validate:"required,email"`
-	Password   string `gorm:"type:varchar(255)" validate:"required,min=6"` // No json tag for security
-	gorm.Model        // Embed gorm.Model for CreatedAt, UpdatedAt, DeletedAt
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(36)" validate:"omitempty,uuid"`
+	Username string `json:"username" gorm:"uniqueIndex;type:varchar(100)" validate:"required,min=3,max=100"`
+	Email    string `json:"email" gorm:"uniqueIndex;type:varchar(255)" validate:"required,email"`
+	Password string `gorm:"type:varchar(255)" validate:"required,min=6"` // No json tag for security
+	Role     string `json:"role" gorm:"type:varchar(20);default:customer"`
+	// Permissions granted to the user on top of their role's, comma-separated
+	Permissions string `json:"permissions,omitempty" gorm:"type:varchar(500)"`
+	// Explicit preferences override the GeoIP-derived defaults.
+	PreferredCurrency string `json:"preferred_currency,omitempty" gorm:"type:varchar(3)" validate:"omitempty,len=3,alpha"`
+	Locale            string `json:"locale,omitempty" gorm:"type:varchar(10)" validate:"omitempty,bcp47_language_tag"`
+	ShippingCountry   string `json:"shipping_country,omitempty" gorm:"type:varchar(2)" validate:"omitempty,iso3166_1_alpha2"`
+	// Address the user asked to change their email to, until they confirm it
+	PendingEmail string `json:"pending_email,omitempty" gorm:"type:varchar(255)"`
+	// Set on every successful login while login history is kept
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	gorm.Model             // Embed gorm.Model for CreatedAt, UpdatedAt, DeletedAt
+}
+
+// PermissionList returns the permissions granted to the user personally.
+func (u *User) PermissionList() []string {
+	return ParsePermissions(u.Permissions)
 }
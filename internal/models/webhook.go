@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Outbound webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed" // All automatic attempts were used up
+)
+
+// WebhookSubscription is an external endpoint that receives store events.
+type WebhookSubscription struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	URL         string    `json:"url" gorm:"type:varchar(2048)" validate:"required,url"`
+	Secret      string    `json:"-" gorm:"type:varchar(255)"`       // Used to sign deliveries
+	Events      string    `json:"events" gorm:"type:varchar(1024)"` // Comma-separated event names; "*" for all
+	Description string    `json:"description,omitempty" gorm:"type:varchar(255)"`
+	Active      bool      `json:"active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the subscription wants the named event.
+func (s *WebhookSubscription) Subscribes(event string) bool {
+	for _, name := range strings.Split(s.Events, ",") {
+		name = strings.TrimSpace(name)
+		if name == "*" || name == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one subscription, with its attempt history.
+type WebhookDelivery struct {
+	ID             string           `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	SubscriptionID string           `json:"subscription_id" gorm:"type:varchar(36);index"`
+	Event          string           `json:"event" gorm:"type:varchar(100);index"`
+	Payload        string           `json:"payload" gorm:"type:text"` // Exact request body sent to the subscriber
+	Status         string           `json:"status" gorm:"type:varchar(20);index"`
+	AttemptCount   int              `json:"attempt_count"`
+	LastStatusCode int              `json:"last_status_code,omitempty"`
+	LastError      string           `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time       `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Attempts       []WebhookAttempt `json:"attempts,omitempty" gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE"`
+}
+
+// WebhookAttempt records one HTTP request made for a delivery.
+type WebhookAttempt struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID   string    `json:"delivery_id" gorm:"type:varchar(36);index"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty" gorm:"type:text"` // Truncated
+	Error        string    `json:"error,omitempty" gorm:"type:text"`
+	DurationMs   int64     `json:"duration_ms"`
+	Manual       bool      `json:"manual"` // Triggered by an admin redelivery
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// WishlistItem is a product a customer saved to buy later.
+type WishlistItem struct {
+	UserID    string    `json:"-" gorm:"primaryKey;type:varchar(36)"`
+	ProductID string    `json:"product_id" gorm:"primaryKey;type:varchar(36);index"`
+	CreatedAt time.Time `json:"added_at"`
+	// Current product details, filled in when the wishlist is read
+	Name    string  `json:"name,omitempty" gorm:"-"`
+	Price   float64 `json:"price" gorm:"-"`
+	InStock bool    `json:"in_stock" gorm:"-"`
+}
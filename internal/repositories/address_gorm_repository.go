@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMAddressRepository is a GORM implementation of AddressRepository.
+type GORMAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMAddressRepository creates a new instance of GORMAddressRepository.
+func NewGORMAddressRepository(db *gorm.DB) *GORMAddressRepository {
+	return &GORMAddressRepository{
+		db: db,
+	}
+}
+
+// ListByUser retrieves the user's addresses, default first, then newest first.
+func (r *GORMAddressRepository) ListByUser(userID string) ([]models.Address, error) {
+	var addresses []models.Address
+	if err := r.db.Where("user_id = ?", userID).Order("is_default DESC, created_at DESC").Find(&addresses).Error; err != nil {
+		return nil, fmt.Errorf("failed to get addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// GetByID retrieves one of the user's addresses.
+func (r *GORMAddressRepository) GetByID(userID, id string) (*models.Address, error) {
+	var address models.Address
+	if err := r.db.First(&address, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("address with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get address %s: %w", id, err)
+	}
+	return &address, nil
+}
+
+// Create adds an address.
+func (r *GORMAddressRepository) Create(address *models.Address) error {
+	if address.ID == "" {
+		address.ID = uuid.New().String()
+	}
+	if err := r.db.Create(address).Error; err != nil {
+		return fmt.Errorf("failed to create address: %w", err)
+	}
+	return nil
+}
+
+// Update saves changes to an existing address.
+func (r *GORMAddressRepository) Update(address *models.Address) error {
+	res := r.db.Model(address).Where("user_id = ?", address.UserID).Select("*").Omit("created_at").Updates(address)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update address %s: %w", address.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("address with ID %s not found", address.ID)
+	}
+	return nil
+}
+
+// Delete removes one of the user's addresses.
+func (r *GORMAddressRepository) Delete(userID, id string) error {
+	res := r.db.Delete(&models.Address{}, "id = ? AND user_id = ?", id, userID)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete address: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("address with ID %s not found", id)
+	}
+	return nil
+}
+
+// SetDefault clears the user's other default addresses and marks this one, in a single transaction.
+func (r *GORMAddressRepository) SetDefault(userID, id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default = ?", userID, id, true).
+			Updates(map[string]interface{}{"is_default": false, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to clear default address: %w", err)
+		}
+		res := tx.Model(&models.Address{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Updates(map[string]interface{}{"is_default": true, "updated_at": now})
+		if res.Error != nil {
+			return fmt.Errorf("failed to set default address: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("address with ID %s not found", id)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockAddressRepository is an in-memory implementation of AddressRepository.
+type MockAddressRepository struct {
+	addresses map[string]models.Address
+	mu        sync.RWMutex
+}
+
+// NewMockAddressRepository creates a new instance of MockAddressRepository.
+func NewMockAddressRepository() *MockAddressRepository {
+	return &MockAddressRepository{
+		addresses: make(map[string]models.Address),
+	}
+}
+
+// ListByUser returns the user's addresses, default first, then newest first.
+func (r *MockAddressRepository) ListByUser(userID string) ([]models.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addresses := []models.Address{}
+	for _, address := range r.addresses {
+		if address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].IsDefault != addresses[j].IsDefault {
+			return addresses[i].IsDefault
+		}
+		return addresses[i].CreatedAt.After(addresses[j].CreatedAt)
+	})
+	return addresses, nil
+}
+
+// GetByID returns one of the user's addresses.
+func (r *MockAddressRepository) GetByID(userID, id string) (*models.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	address, ok := r.addresses[id]
+	if !ok || address.UserID != userID {
+		return nil, fmt.Errorf("address with ID %s not found", id)
+	}
+	return &address, nil
+}
+
+// Create adds an address.
+func (r *MockAddressRepository) Create(address *models.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if address.ID == "" {
+		address.ID = uuid.New().String()
+	}
+	address.CreatedAt = time.Now()
+	address.UpdatedAt = address.CreatedAt
+	r.addresses[address.ID] = *address
+	return nil
+}
+
+// Update saves changes to an existing address.
+func (r *MockAddressRepository) Update(address *models.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.addresses[address.ID]
+	if !ok || existing.UserID != address.UserID {
+		return fmt.Errorf("address with ID %s not found", address.ID)
+	}
+	address.CreatedAt = existing.CreatedAt
+	address.UpdatedAt = time.Now()
+	r.addresses[address.ID] = *address
+	return nil
+}
+
+// Delete removes one of the user's addresses.
+func (r *MockAddressRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	address, ok := r.addresses[id]
+	if !ok || address.UserID != userID {
+		return fmt.Errorf("address with ID %s not found", id)
+	}
+	delete(r.addresses, id)
+	return nil
+}
+
+// SetDefault makes the address the user's only default address.
+func (r *MockAddressRepository) SetDefault(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	target, ok := r.addresses[id]
+	if !ok || target.UserID != userID {
+		return fmt.Errorf("address with ID %s not found", id)
+	}
+	for key, address := range r.addresses {
+		if address.UserID == userID {
+			address.IsDefault = key == id
+			r.addresses[key] = address
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import "toko/internal/models"
+
+// AddressRepository defines the interface for address book data access.
+// Every method is scoped to the owning user.
+type AddressRepository interface {
+	// ListByUser returns the user's addresses, default first, then newest first.
+	ListByUser(userID string) ([]models.Address, error)
+	GetByID(userID, id string) (*models.Address, error)
+	Create(address *models.Address) error
+	Update(address *models.Address) error
+	Delete(userID, id string) error
+	// SetDefault makes the address the user's only default address.
+	SetDefault(userID, id string) error
+}
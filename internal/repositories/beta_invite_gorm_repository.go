@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMBetaInviteRepository is a GORM implementation of BetaInviteRepository.
+type GORMBetaInviteRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMBetaInviteRepository creates a new instance of GORMBetaInviteRepository.
+func NewGORMBetaInviteRepository(db *gorm.DB) *GORMBetaInviteRepository {
+	return &GORMBetaInviteRepository{
+		db: db,
+	}
+}
+
+// GetAll retrieves all invites, newest first.
+func (r *GORMBetaInviteRepository) GetAll() ([]models.BetaInvite, error) {
+	var invites []models.BetaInvite
+	if err := r.db.Order("created_at DESC").Find(&invites).Error; err != nil {
+		return nil, fmt.Errorf("failed to get beta invites: %w", err)
+	}
+	return invites, nil
+}
+
+// GetByEmail retrieves the invite for an email address.
+func (r *GORMBetaInviteRepository) GetByEmail(email string) (*models.BetaInvite, error) {
+	var invite models.BetaInvite
+	if err := r.db.First(&invite, "email = ?", email).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("beta invite for %s not found", email)
+		}
+		return nil, fmt.Errorf("failed to get beta invite for %s: %w", email, err)
+	}
+	return &invite, nil
+}
+
+// Save creates or replaces an invite.
+func (r *GORMBetaInviteRepository) Save(invite *models.BetaInvite) error {
+	if err := r.db.Save(invite).Error; err != nil {
+		return fmt.Errorf("failed to save beta invite: %w", err)
+	}
+	return nil
+}
+
+// MarkUsed records that the invited email has registered.
+func (r *GORMBetaInviteRepository) MarkUsed(email string) error {
+	if err := r.db.Model(&models.BetaInvite{}).Where("email = ?", email).Update("used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to mark beta invite for %s as used: %w", email, err)
+	}
+	return nil
+}
+
+// Delete revokes an invite.
+func (r *GORMBetaInviteRepository) Delete(email string) error {
+	res := r.db.Delete(&models.BetaInvite{}, "email = ?", email)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete beta invite: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("beta invite for %s not found", email)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockBetaInviteRepository is an in-memory implementation of BetaInviteRepository.
+type MockBetaInviteRepository struct {
+	invites map[string]models.BetaInvite
+	mu      sync.RWMutex
+}
+
+// NewMockBetaInviteRepository creates a new instance of MockBetaInviteRepository.
+func NewMockBetaInviteRepository() *MockBetaInviteRepository {
+	return &MockBetaInviteRepository{
+		invites: make(map[string]models.BetaInvite),
+	}
+}
+
+// GetAll returns all invites, newest first.
+func (r *MockBetaInviteRepository) GetAll() ([]models.BetaInvite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invites := make([]models.BetaInvite, 0, len(r.invites))
+	for _, invite := range r.invites {
+		invites = append(invites, invite)
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+	return invites, nil
+}
+
+// GetByEmail returns the invite for an email address.
+func (r *MockBetaInviteRepository) GetByEmail(email string) (*models.BetaInvite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invite, ok := r.invites[email]
+	if !ok {
+		return nil, fmt.Errorf("beta invite for %s not found", email)
+	}
+	return &invite, nil
+}
+
+// Save creates or replaces an invite.
+func (r *MockBetaInviteRepository) Save(invite *models.BetaInvite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if invite.CreatedAt.IsZero() {
+		invite.CreatedAt = time.Now()
+	}
+	r.invites[invite.Email] = *invite
+	return nil
+}
+
+// MarkUsed records that the invited email has registered.
+func (r *MockBetaInviteRepository) MarkUsed(email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, ok := r.invites[email]
+	if !ok {
+		return fmt.Errorf("beta invite for %s not found", email)
+	}
+	now := time.Now()
+	invite.UsedAt = &now
+	r.invites[email] = invite
+	return nil
+}
+
+// Delete revokes an invite.
+func (r *MockBetaInviteRepository) Delete(email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.invites[email]; !ok {
+		return fmt.Errorf("beta invite for %s not found", email)
+	}
+	delete(r.invites, email)
+	return nil
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import "toko/internal/models"
+
+// BetaInviteRepository defines the interface for beta invite data access.
+type BetaInviteRepository interface {
+	GetAll() ([]models.BetaInvite, error)
+	GetByEmail(email string) (*models.BetaInvite, error)
+	// Save creates the invite or replaces an existing invite for the same email.
+	Save(invite *models.BetaInvite) error
+	MarkUsed(email string) error
+	Delete(email string) error
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMBinLocationRepository is a GORM implementation of BinLocationRepository.
+type GORMBinLocationRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMBinLocationRepository creates a new instance of GORMBinLocationRepository.
+func NewGORMBinLocationRepository(db *gorm.DB) *GORMBinLocationRepository {
+	return &GORMBinLocationRepository{
+		db: db,
+	}
+}
+
+// ListByProduct returns a product's locations in every warehouse.
+func (r *GORMBinLocationRepository) ListByProduct(productID string) ([]models.BinLocation, error) {
+	var locations []models.BinLocation
+	if err := r.db.Where("product_id = ?", productID).Order("warehouse").Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list locations of product %s: %w", productID, err)
+	}
+	return locations, nil
+}
+
+// ListByWarehouse returns the locations of the given products in one warehouse.
+func (r *GORMBinLocationRepository) ListByWarehouse(warehouse string, productIDs []string) ([]models.BinLocation, error) {
+	var locations []models.BinLocation
+	if len(productIDs) == 0 {
+		return locations, nil
+	}
+	if err := r.db.Where("warehouse = ? AND product_id IN ?", warehouse, productIDs).Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list locations in warehouse %s: %w", warehouse, err)
+	}
+	return locations, nil
+}
+
+// Save creates the location or updates the product's location in the same warehouse.
+func (r *GORMBinLocationRepository) Save(location *models.BinLocation) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "warehouse"}},
+		DoUpdates: clause.AssignmentColumns([]string{"aisle", "shelf", "bin", "updated_at"}),
+	}).Create(location).Error
+	if err != nil {
+		return fmt.Errorf("failed to save location of product %s in warehouse %s: %w", location.ProductID, location.Warehouse, err)
+	}
+	return nil
+}
+
+// Delete removes a product's location in a warehouse.
+func (r *GORMBinLocationRepository) Delete(productID, warehouse string) error {
+	res := r.db.Where("product_id = ? AND warehouse = ?", productID, warehouse).Delete(&models.BinLocation{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete location of product %s in warehouse %s: %w", productID, warehouse, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("location of product %s in warehouse %s not found", productID, warehouse)
+	}
+	return nil
+}
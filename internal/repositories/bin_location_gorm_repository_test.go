@@ -0,0 +1,37 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMBinLocationRepository_SaveUpserts(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.BinLocation{}))
+	repo := repositories.NewGORMBinLocationRepository(db)
+
+	assert.NoError(t, repo.Save(&models.BinLocation{ProductID: "p1", Warehouse: "main", Shelf: "A1", Bin: "01"}))
+	assert.NoError(t, repo.Save(&models.BinLocation{ProductID: "p1", Warehouse: "main", Shelf: "B2", Bin: "07"}))
+	assert.NoError(t, repo.Save(&models.BinLocation{ProductID: "p1", Warehouse: "surabaya", Bin: "9"}))
+	assert.NoError(t, repo.Save(&models.BinLocation{ProductID: "p2", Warehouse: "main", Bin: "3"}))
+
+	locations, err := repo.ListByProduct("p1")
+	assert.NoError(t, err)
+	if assert.Len(t, locations, 2) {
+		assert.Equal(t, "B2-07", locations[0].Code())
+		assert.Equal(t, "surabaya", locations[1].Warehouse)
+	}
+
+	locations, err = repo.ListByWarehouse("main", []string{"p2", "p3"})
+	assert.NoError(t, err)
+	if assert.Len(t, locations, 1) {
+		assert.Equal(t, "p2", locations[0].ProductID)
+	}
+
+	assert.NoError(t, repo.Delete("p1", "surabaya"))
+	assert.ErrorContains(t, repo.Delete("p1", "surabaya"), "not found")
+}
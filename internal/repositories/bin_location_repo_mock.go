@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockBinLocationRepository is an in-memory implementation of BinLocationRepository.
+type MockBinLocationRepository struct {
+	locations map[string]models.BinLocation // Keyed by product ID and warehouse
+	mu        sync.RWMutex
+}
+
+// NewMockBinLocationRepository creates a new instance of MockBinLocationRepository.
+func NewMockBinLocationRepository() *MockBinLocationRepository {
+	return &MockBinLocationRepository{
+		locations: make(map[string]models.BinLocation),
+	}
+}
+
+func binLocationKey(productID, warehouse string) string {
+	return productID + "/" + warehouse
+}
+
+// ListByProduct returns a product's locations in every warehouse.
+func (r *MockBinLocationRepository) ListByProduct(productID string) ([]models.BinLocation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locations := []models.BinLocation{}
+	for _, location := range r.locations {
+		if location.ProductID == productID {
+			locations = append(locations, location)
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Warehouse < locations[j].Warehouse })
+	return locations, nil
+}
+
+// ListByWarehouse returns the locations of the given products in one warehouse.
+func (r *MockBinLocationRepository) ListByWarehouse(warehouse string, productIDs []string) ([]models.BinLocation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locations := []models.BinLocation{}
+	for _, productID := range productIDs {
+		if location, ok := r.locations[binLocationKey(productID, warehouse)]; ok {
+			locations = append(locations, location)
+		}
+	}
+	return locations, nil
+}
+
+// Save creates or replaces a location.
+func (r *MockBinLocationRepository) Save(location *models.BinLocation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location.UpdatedAt = time.Now()
+	r.locations[binLocationKey(location.ProductID, location.Warehouse)] = *location
+	return nil
+}
+
+// Delete removes a location.
+func (r *MockBinLocationRepository) Delete(productID, warehouse string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := binLocationKey(productID, warehouse)
+	if _, ok := r.locations[key]; !ok {
+		return fmt.Errorf("location of product %s in warehouse %s not found", productID, warehouse)
+	}
+	delete(r.locations, key)
+	return nil
+}
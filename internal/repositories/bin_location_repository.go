@@ -0,0 +1,13 @@
+package repositories
+
+import "toko/internal/models"
+
+// BinLocationRepository defines the interface for warehouse bin location data access.
+// Locations are keyed by product and warehouse and saved with upserts.
+type BinLocationRepository interface {
+	ListByProduct(productID string) ([]models.BinLocation, error)
+	// ListByWarehouse returns the locations of the given products in one warehouse.
+	ListByWarehouse(warehouse string, productIDs []string) ([]models.BinLocation, error)
+	Save(location *models.BinLocation) error
+	Delete(productID, warehouse string) error
+}
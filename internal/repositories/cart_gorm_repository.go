@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMCartRepository is a GORM implementation of CartRepository.
+type GORMCartRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMCartRepository creates a new instance of GORMCartRepository.
+func NewGORMCartRepository(db *gorm.DB) *GORMCartRepository {
+	return &GORMCartRepository{
+		db: db,
+	}
+}
+
+// GetByUserID retrieves the user's cart.
+func (r *GORMCartRepository) GetByUserID(userID string) (*models.Cart, error) {
+	return r.get("user_id = ?", userID, "cart for user "+userID)
+}
+
+// GetByToken retrieves the anonymous cart with the token.
+func (r *GORMCartRepository) GetByToken(token string) (*models.Cart, error) {
+	return r.get("token = ? AND user_id = ''", token, "cart")
+}
+
+func (r *GORMCartRepository) get(query, value, what string) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).First(&cart, query, value).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%s not found", what)
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", what, err)
+	}
+	cart.SplitSavedItems()
+	return &cart, nil
+}
+
+// Save creates the cart or replaces its items, in a single transaction.
+func (r *GORMCartRepository) Save(cart *models.Cart) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if cart.ID == "" {
+			cart.ID = uuid.New().String()
+			cart.CreatedAt = now
+		} else if err := tx.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return fmt.Errorf("failed to save cart %s: %w", cart.ID, err)
+		}
+		cart.UpdatedAt = now
+		cart.Items = cart.StoredItems()
+		defer cart.SplitSavedItems()
+		for i := range cart.Items {
+			cart.Items[i].ID = 0
+			cart.Items[i].CartID = cart.ID
+			if cart.Items[i].CreatedAt.IsZero() {
+				cart.Items[i].CreatedAt = now
+			}
+		}
+		if err := tx.Save(cart).Error; err != nil {
+			return fmt.Errorf("failed to save cart %s: %w", cart.ID, err)
+		}
+		return nil
+	})
+}
+
+// Delete removes a cart and its items.
+func (r *GORMCartRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id = ?", id).Delete(&models.CartItem{}).Error; err != nil {
+			return fmt.Errorf("failed to delete cart %s: %w", id, err)
+		}
+		res := tx.Delete(&models.Cart{}, "id = ?", id)
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete cart %s: %w", id, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("cart with ID %s not found", id)
+		}
+		return nil
+	})
+}
+
+// DeleteAnonymousBefore removes anonymous carts not updated since cutoff.
+func (r *GORMCartRepository) DeleteAnonymousBefore(cutoff time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&models.Cart{}).Select("id").Where("user_id = '' AND updated_at < ?", cutoff)
+		if err := tx.Where("cart_id IN (?)", stale).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("user_id = '' AND updated_at < ?", cutoff).Delete(&models.Cart{})
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale carts: %w", err)
+	}
+	return deleted, nil
+}
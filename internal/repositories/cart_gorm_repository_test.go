@@ -0,0 +1,88 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMCartRepository_SaveReplacesItems(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.Cart{}, &models.CartItem{}))
+	repo := repositories.NewGORMCartRepository(db)
+
+	cart := &models.Cart{Token: "abc", Items: []models.CartItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p2", Quantity: 2}}}
+	assert.NoError(t, repo.Save(cart))
+	assert.NotEmpty(t, cart.ID)
+
+	cart.Items = []models.CartItem{{ProductID: "p2", Quantity: 5}}
+	assert.NoError(t, repo.Save(cart))
+	stored, err := repo.GetByToken("abc")
+	assert.NoError(t, err)
+	if assert.Len(t, stored.Items, 1) {
+		assert.Equal(t, 5, stored.Items[0].Quantity)
+	}
+	var count int64
+	db.Model(&models.CartItem{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// User carts are never found by token
+	assert.NoError(t, repo.Save(&models.Cart{UserID: "u1", Token: "def", Items: []models.CartItem{{ProductID: "p1", Quantity: 1}}}))
+	_, err = repo.GetByToken("def")
+	assert.ErrorContains(t, err, "not found")
+	_, err = repo.GetByUserID("u1")
+	assert.NoError(t, err)
+}
+
+func TestGORMCartRepository_DeleteAnonymousBefore(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.Cart{}, &models.CartItem{}))
+	repo := repositories.NewGORMCartRepository(db)
+
+	stale := &models.Cart{Token: "old", Items: []models.CartItem{{ProductID: "p1", Quantity: 1}}}
+	fresh := &models.Cart{Token: "new", Items: []models.CartItem{{ProductID: "p1", Quantity: 1}}}
+	owned := &models.Cart{UserID: "u1", Items: []models.CartItem{{ProductID: "p1", Quantity: 1}}}
+	for _, cart := range []*models.Cart{stale, fresh, owned} {
+		assert.NoError(t, repo.Save(cart))
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	db.Model(&models.Cart{}).Where("id IN ?", []string{stale.ID, owned.ID}).UpdateColumn("updated_at", old)
+
+	deleted, err := repo.DeleteAnonymousBefore(time.Now().Add(-24 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = repo.GetByToken("old")
+	assert.ErrorContains(t, err, "not found")
+	_, err = repo.GetByUserID("u1")
+	assert.NoError(t, err)
+	var count int64
+	db.Model(&models.CartItem{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestGORMCartRepository_SavedItems(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.Cart{}, &models.CartItem{}))
+	repo := repositories.NewGORMCartRepository(db)
+
+	cart := &models.Cart{
+		UserID:     "u1",
+		Items:      []models.CartItem{{ProductID: "p1", Quantity: 1}},
+		SavedItems: []models.CartItem{{ProductID: "p2", Quantity: 3}},
+	}
+	assert.NoError(t, repo.Save(cart))
+	assert.Len(t, cart.Items, 1)
+	assert.Len(t, cart.SavedItems, 1)
+
+	stored, err := repo.GetByUserID("u1")
+	assert.NoError(t, err)
+	if assert.Len(t, stored.Items, 1) && assert.Len(t, stored.SavedItems, 1) {
+		assert.Equal(t, "p1", stored.Items[0].ProductID)
+		assert.Equal(t, "p2", stored.SavedItems[0].ProductID)
+		assert.Equal(t, 3, stored.SavedItems[0].Quantity)
+	}
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockCartRepository is an in-memory implementation of CartRepository.
+type MockCartRepository struct {
+	carts      map[string]models.Cart
+	nextItemID uint
+	mu         sync.RWMutex
+}
+
+// NewMockCartRepository creates a new instance of MockCartRepository.
+func NewMockCartRepository() *MockCartRepository {
+	return &MockCartRepository{
+		carts: make(map[string]models.Cart),
+	}
+}
+
+// GetByUserID returns the user's cart.
+func (r *MockCartRepository) GetByUserID(userID string) (*models.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, cart := range r.carts {
+		if cart.UserID == userID && userID != "" {
+			return copyCart(cart), nil
+		}
+	}
+	return nil, fmt.Errorf("cart for user %s not found", userID)
+}
+
+// GetByToken returns the anonymous cart with the token.
+func (r *MockCartRepository) GetByToken(token string) (*models.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, cart := range r.carts {
+		if cart.UserID == "" && cart.Token == token && token != "" {
+			return copyCart(cart), nil
+		}
+	}
+	return nil, fmt.Errorf("cart not found")
+}
+
+// Save creates the cart or replaces its items.
+func (r *MockCartRepository) Save(cart *models.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if cart.ID == "" {
+		cart.ID = uuid.New().String()
+		cart.CreatedAt = now
+	}
+	cart.UpdatedAt = now
+	cart.Items = cart.StoredItems()
+	defer cart.SplitSavedItems()
+	for i := range cart.Items {
+		r.nextItemID++
+		cart.Items[i].ID = r.nextItemID
+		cart.Items[i].CartID = cart.ID
+		if cart.Items[i].CreatedAt.IsZero() {
+			cart.Items[i].CreatedAt = now
+		}
+	}
+	stored := *cart
+	stored.Items = append([]models.CartItem(nil), cart.Items...)
+	stored.SavedItems = nil
+	r.carts[cart.ID] = stored
+	return nil
+}
+
+// Delete removes a cart.
+func (r *MockCartRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.carts[id]; !ok {
+		return fmt.Errorf("cart with ID %s not found", id)
+	}
+	delete(r.carts, id)
+	return nil
+}
+
+// DeleteAnonymousBefore removes anonymous carts not updated since cutoff.
+func (r *MockCartRepository) DeleteAnonymousBefore(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, cart := range r.carts {
+		if cart.UserID == "" && cart.UpdatedAt.Before(cutoff) {
+			delete(r.carts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// copyCart returns a copy of a stored cart with its saved items split off.
+func copyCart(cart models.Cart) *models.Cart {
+	cart.Items = append([]models.CartItem(nil), cart.Items...)
+	cart.SplitSavedItems()
+	return &cart
+}
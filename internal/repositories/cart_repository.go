@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// CartRepository defines the interface for shopping cart data access.
+// Carts are returned with their items, oldest first.
+type CartRepository interface {
+	GetByUserID(userID string) (*models.Cart, error)
+	// GetByToken returns the anonymous cart with the token.
+	GetByToken(token string) (*models.Cart, error)
+	// Save creates the cart, or replaces an existing cart's items.
+	Save(cart *models.Cart) error
+	Delete(id string) error
+	// DeleteAnonymousBefore removes anonymous carts not updated since cutoff
+	// and returns how many were removed.
+	DeleteAnonymousBefore(cutoff time.Time) (int64, error)
+}
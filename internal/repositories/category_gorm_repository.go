@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMCategoryRepository is a GORM implementation of CategoryRepository.
+type GORMCategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMCategoryRepository creates a new instance of GORMCategoryRepository.
+func NewGORMCategoryRepository(db *gorm.DB) *GORMCategoryRepository {
+	return &GORMCategoryRepository{
+		db: db,
+	}
+}
+
+// List retrieves all categories by name.
+func (r *GORMCategoryRepository) List() ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetByID retrieves a category by its ID.
+func (r *GORMCategoryRepository) GetByID(id string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.First(&category, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("category with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get category %s: %w", id, err)
+	}
+	return &category, nil
+}
+
+// Create adds a category.
+func (r *GORMCategoryRepository) Create(category *models.Category) error {
+	if category.ID == "" {
+		category.ID = uuid.New().String()
+	}
+	if err := r.db.Create(category).Error; err != nil {
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+	return nil
+}
+
+// Update saves changes to an existing category.
+func (r *GORMCategoryRepository) Update(category *models.Category) error {
+	res := r.db.Model(category).Select("*").Omit("created_at").Updates(category)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update category %s: %w", category.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("category with ID %s not found", category.ID)
+	}
+	return nil
+}
+
+// Delete removes a category. Its products keep their category ID and fall
+// back to the default classes.
+func (r *GORMCategoryRepository) Delete(id string) error {
+	res := r.db.Delete(&models.Category{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete category: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("category with ID %s not found", id)
+	}
+	return nil
+}
+
+// GORMClassRepository is a GORM implementation of ClassRepository.
+type GORMClassRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMClassRepository creates a new instance of GORMClassRepository.
+func NewGORMClassRepository(db *gorm.DB) *GORMClassRepository {
+	return &GORMClassRepository{
+		db: db,
+	}
+}
+
+// ListTaxClasses retrieves all tax classes by code.
+func (r *GORMClassRepository) ListTaxClasses() ([]models.TaxClass, error) {
+	var classes []models.TaxClass
+	if err := r.db.Order("code ASC").Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tax classes: %w", err)
+	}
+	return classes, nil
+}
+
+// GetTaxClass retrieves a tax class by its code.
+func (r *GORMClassRepository) GetTaxClass(code string) (*models.TaxClass, error) {
+	var class models.TaxClass
+	if err := r.db.First(&class, "code = ?", code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("tax class %s not found", code)
+		}
+		return nil, fmt.Errorf("failed to get tax class %s: %w", code, err)
+	}
+	return &class, nil
+}
+
+// SaveTaxClass creates the tax class or updates the one with the same code.
+func (r *GORMClassRepository) SaveTaxClass(class *models.TaxClass) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "rate", "updated_at"}),
+	}).Create(class).Error
+	if err != nil {
+		return fmt.Errorf("failed to save tax class %s: %w", class.Code, err)
+	}
+	return nil
+}
+
+// DeleteTaxClass removes a tax class.
+func (r *GORMClassRepository) DeleteTaxClass(code string) error {
+	res := r.db.Delete(&models.TaxClass{}, "code = ?", code)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete tax class: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("tax class %s not found", code)
+	}
+	return nil
+}
+
+// ListShippingClasses retrieves all shipping classes by code.
+func (r *GORMClassRepository) ListShippingClasses() ([]models.ShippingClass, error) {
+	var classes []models.ShippingClass
+	if err := r.db.Order("code ASC").Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get shipping classes: %w", err)
+	}
+	return classes, nil
+}
+
+// GetShippingClass retrieves a shipping class by its code.
+func (r *GORMClassRepository) GetShippingClass(code string) (*models.ShippingClass, error) {
+	var class models.ShippingClass
+	if err := r.db.First(&class, "code = ?", code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("shipping class %s not found", code)
+		}
+		return nil, fmt.Errorf("failed to get shipping class %s: %w", code, err)
+	}
+	return &class, nil
+}
+
+// SaveShippingClass creates the shipping class or updates the one with the same code.
+func (r *GORMClassRepository) SaveShippingClass(class *models.ShippingClass) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "surcharge", "updated_at"}),
+	}).Create(class).Error
+	if err != nil {
+		return fmt.Errorf("failed to save shipping class %s: %w", class.Code, err)
+	}
+	return nil
+}
+
+// DeleteShippingClass removes a shipping class.
+func (r *GORMClassRepository) DeleteShippingClass(code string) error {
+	res := r.db.Delete(&models.ShippingClass{}, "code = ?", code)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete shipping class: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("shipping class %s not found", code)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMClassRepository_SaveUpserts(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.TaxClass{}, &models.ShippingClass{}))
+	repo := repositories.NewGORMClassRepository(db)
+
+	assert.NoError(t, repo.SaveTaxClass(&models.TaxClass{Code: "standard", Name: "Standard", Rate: 0.1}))
+	assert.NoError(t, repo.SaveTaxClass(&models.TaxClass{Code: "standard", Name: "Standard VAT", Rate: 0.11}))
+	classes, err := repo.ListTaxClasses()
+	assert.NoError(t, err)
+	if assert.Len(t, classes, 1) {
+		assert.Equal(t, "Standard VAT", classes[0].Name)
+		assert.Equal(t, 0.11, classes[0].Rate)
+	}
+
+	_, err = repo.GetShippingClass("bulky")
+	assert.ErrorContains(t, err, "not found")
+	assert.ErrorContains(t, repo.DeleteShippingClass("bulky"), "not found")
+}
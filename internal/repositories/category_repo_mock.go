@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockCategoryRepository is an in-memory implementation of CategoryRepository.
+type MockCategoryRepository struct {
+	categories map[string]models.Category
+	mu         sync.RWMutex
+}
+
+// NewMockCategoryRepository creates a new instance of MockCategoryRepository.
+func NewMockCategoryRepository() *MockCategoryRepository {
+	return &MockCategoryRepository{
+		categories: make(map[string]models.Category),
+	}
+}
+
+// List returns all categories by name.
+func (r *MockCategoryRepository) List() ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]models.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories, nil
+}
+
+// GetByID returns a category.
+func (r *MockCategoryRepository) GetByID(id string) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, fmt.Errorf("category with ID %s not found", id)
+	}
+	return &category, nil
+}
+
+// Create adds a category.
+func (r *MockCategoryRepository) Create(category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if category.ID == "" {
+		category.ID = uuid.New().String()
+	}
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// Update saves changes to an existing category.
+func (r *MockCategoryRepository) Update(category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[category.ID]
+	if !ok {
+		return fmt.Errorf("category with ID %s not found", category.ID)
+	}
+	category.CreatedAt = existing.CreatedAt
+	category.UpdatedAt = time.Now()
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// Delete removes a category.
+func (r *MockCategoryRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return fmt.Errorf("category with ID %s not found", id)
+	}
+	delete(r.categories, id)
+	return nil
+}
+
+// MockClassRepository is an in-memory implementation of ClassRepository.
+type MockClassRepository struct {
+	taxClasses      map[string]models.TaxClass
+	shippingClasses map[string]models.ShippingClass
+	mu              sync.RWMutex
+}
+
+// NewMockClassRepository creates a new instance of MockClassRepository.
+func NewMockClassRepository() *MockClassRepository {
+	return &MockClassRepository{
+		taxClasses:      make(map[string]models.TaxClass),
+		shippingClasses: make(map[string]models.ShippingClass),
+	}
+}
+
+// ListTaxClasses returns all tax classes by code.
+func (r *MockClassRepository) ListTaxClasses() ([]models.TaxClass, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	classes := make([]models.TaxClass, 0, len(r.taxClasses))
+	for _, class := range r.taxClasses {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Code < classes[j].Code })
+	return classes, nil
+}
+
+// GetTaxClass returns a tax class.
+func (r *MockClassRepository) GetTaxClass(code string) (*models.TaxClass, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	class, ok := r.taxClasses[code]
+	if !ok {
+		return nil, fmt.Errorf("tax class %s not found", code)
+	}
+	return &class, nil
+}
+
+// SaveTaxClass creates or replaces a tax class.
+func (r *MockClassRepository) SaveTaxClass(class *models.TaxClass) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	class.UpdatedAt = time.Now()
+	class.CreatedAt = class.UpdatedAt
+	if existing, ok := r.taxClasses[class.Code]; ok {
+		class.CreatedAt = existing.CreatedAt
+	}
+	r.taxClasses[class.Code] = *class
+	return nil
+}
+
+// DeleteTaxClass removes a tax class.
+func (r *MockClassRepository) DeleteTaxClass(code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.taxClasses[code]; !ok {
+		return fmt.Errorf("tax class %s not found", code)
+	}
+	delete(r.taxClasses, code)
+	return nil
+}
+
+// ListShippingClasses returns all shipping classes by code.
+func (r *MockClassRepository) ListShippingClasses() ([]models.ShippingClass, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	classes := make([]models.ShippingClass, 0, len(r.shippingClasses))
+	for _, class := range r.shippingClasses {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Code < classes[j].Code })
+	return classes, nil
+}
+
+// GetShippingClass returns a shipping class.
+func (r *MockClassRepository) GetShippingClass(code string) (*models.ShippingClass, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	class, ok := r.shippingClasses[code]
+	if !ok {
+		return nil, fmt.Errorf("shipping class %s not found", code)
+	}
+	return &class, nil
+}
+
+// SaveShippingClass creates or replaces a shipping class.
+func (r *MockClassRepository) SaveShippingClass(class *models.ShippingClass) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	class.UpdatedAt = time.Now()
+	class.CreatedAt = class.UpdatedAt
+	if existing, ok := r.shippingClasses[class.Code]; ok {
+		class.CreatedAt = existing.CreatedAt
+	}
+	r.shippingClasses[class.Code] = *class
+	return nil
+}
+
+// DeleteShippingClass removes a shipping class.
+func (r *MockClassRepository) DeleteShippingClass(code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.shippingClasses[code]; !ok {
+		return fmt.Errorf("shipping class %s not found", code)
+	}
+	delete(r.shippingClasses, code)
+	return nil
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import "toko/internal/models"
+
+// CategoryRepository defines the interface for product category data access.
+type CategoryRepository interface {
+	List() ([]models.Category, error)
+	GetByID(id string) (*models.Category, error)
+	Create(category *models.Category) error
+	Update(category *models.Category) error
+	Delete(id string) error
+}
+
+// ClassRepository defines the interface for tax and shipping class data access.
+// Classes are keyed by their code and saved with upserts.
+type ClassRepository interface {
+	ListTaxClasses() ([]models.TaxClass, error)
+	GetTaxClass(code string) (*models.TaxClass, error)
+	SaveTaxClass(class *models.TaxClass) error
+	DeleteTaxClass(code string) error
+	ListShippingClasses() ([]models.ShippingClass, error)
+	GetShippingClass(code string) (*models.ShippingClass, error)
+	SaveShippingClass(class *models.ShippingClass) error
+	DeleteShippingClass(code string) error
+}
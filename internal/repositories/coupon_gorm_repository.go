@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMCouponRepository is a GORM implementation of CouponRepository.
+type GORMCouponRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMCouponRepository creates a new instance of GORMCouponRepository.
+func NewGORMCouponRepository(db *gorm.DB) *GORMCouponRepository {
+	return &GORMCouponRepository{
+		db: db,
+	}
+}
+
+// List retrieves all coupons by code.
+func (r *GORMCouponRepository) List() ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	if err := r.db.Order("code").Find(&coupons).Error; err != nil {
+		return nil, fmt.Errorf("failed to list coupons: %w", err)
+	}
+	return coupons, nil
+}
+
+// GetByID retrieves a coupon by its ID.
+func (r *GORMCouponRepository) GetByID(id string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := r.db.First(&coupon, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("coupon with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get coupon %s: %w", id, err)
+	}
+	return &coupon, nil
+}
+
+// GetByCode retrieves a coupon by its code.
+func (r *GORMCouponRepository) GetByCode(code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := r.db.First(&coupon, "code = ?", code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("coupon %s not found", code)
+		}
+		return nil, fmt.Errorf("failed to get coupon %s: %w", code, err)
+	}
+	return &coupon, nil
+}
+
+// Create creates a new coupon.
+func (r *GORMCouponRepository) Create(coupon *models.Coupon) error {
+	if coupon.ID == "" {
+		coupon.ID = uuid.New().String()
+	}
+	if err := r.db.Create(coupon).Error; err != nil {
+		return fmt.Errorf("failed to create coupon: %w", err)
+	}
+	return nil
+}
+
+// Update saves a coupon's settings. The usage count is left alone so
+// concurrent redemptions are not overwritten.
+func (r *GORMCouponRepository) Update(coupon *models.Coupon) error {
+	res := r.db.Model(coupon).Select("code", "type", "value", "min_spend", "usage_limit", "per_user_limit", "starts_at", "expires_at", "active", "customer_tag", "exclude_customer_tag", "updated_at").Updates(coupon)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update coupon %s: %w", coupon.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("coupon with ID %s not found", coupon.ID)
+	}
+	return nil
+}
+
+// Delete removes a coupon.
+func (r *GORMCouponRepository) Delete(id string) error {
+	res := r.db.Delete(&models.Coupon{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete coupon %s: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("coupon with ID %s not found", id)
+	}
+	return nil
+}
+
+// Redeem records a redemption. The conditional UPDATE guarantees the total
+// usage limit holds under concurrency.
+func (r *GORMCouponRepository) Redeem(coupon *models.Coupon, redemption *models.CouponRedemption) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if coupon.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&models.CouponRedemption{}).Where("coupon_id = ? AND user_id = ?", coupon.ID, redemption.UserID).Count(&used).Error; err != nil {
+				return fmt.Errorf("failed to count redemptions of coupon %s: %w", coupon.Code, err)
+			}
+			if used >= int64(coupon.PerUserLimit) {
+				return fmt.Errorf("invalid coupon: %s has already been used the maximum number of times", coupon.Code)
+			}
+		}
+
+		res := tx.Model(&models.Coupon{}).
+			Where("id = ? AND (usage_limit = 0 OR used_count < usage_limit)", coupon.ID).
+			Update("used_count", gorm.Expr("used_count + 1"))
+		if res.Error != nil {
+			return fmt.Errorf("failed to redeem coupon %s: %w", coupon.Code, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("invalid coupon: %s is no longer available", coupon.Code)
+		}
+
+		redemption.CouponID = coupon.ID
+		if err := tx.Create(redemption).Error; err != nil {
+			return fmt.Errorf("failed to record redemption of coupon %s: %w", coupon.Code, err)
+		}
+		return nil
+	})
+}
+
+// Release deletes the order's redemption and gives the use back to its coupon.
+func (r *GORMCouponRepository) Release(orderID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var redemption models.CouponRedemption
+		if err := tx.First(&redemption, "order_id = ?", orderID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get coupon redemption of order %s: %w", orderID, err)
+		}
+		if err := tx.Delete(&redemption).Error; err != nil {
+			return fmt.Errorf("failed to delete coupon redemption of order %s: %w", orderID, err)
+		}
+		return tx.Model(&models.Coupon{}).
+			Where("id = ? AND used_count > 0", redemption.CouponID).
+			Update("used_count", gorm.Expr("used_count - 1")).Error
+	})
+}
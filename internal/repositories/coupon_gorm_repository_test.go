@@ -0,0 +1,40 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMCouponRepository_RedeemEnforcesLimits(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.Coupon{}, &models.CouponRedemption{}))
+	repo := repositories.NewGORMCouponRepository(db)
+
+	coupon := &models.Coupon{Code: "SAVE", Type: models.CouponTypeFixed, Value: 5, UsageLimit: 2, PerUserLimit: 1, Active: true}
+	assert.NoError(t, repo.Create(coupon))
+
+	assert.NoError(t, repo.Redeem(coupon, &models.CouponRedemption{OrderID: "o1", UserID: "u1", Amount: 5}))
+	assert.ErrorContains(t, repo.Redeem(coupon, &models.CouponRedemption{OrderID: "o2", UserID: "u1", Amount: 5}), "maximum number of times")
+	assert.NoError(t, repo.Redeem(coupon, &models.CouponRedemption{OrderID: "o3", UserID: "u2", Amount: 5}))
+	assert.ErrorContains(t, repo.Redeem(coupon, &models.CouponRedemption{OrderID: "o4", UserID: "u3", Amount: 5}), "no longer available")
+
+	// Releasing an order gives its use back
+	assert.NoError(t, repo.Release("o3"))
+	assert.NoError(t, repo.Release("o3"))
+	stored, err := repo.GetByCode("SAVE")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stored.UsedCount)
+
+	// Updates never reset the usage count
+	stored.Value = 7
+	stored.UsedCount = 0
+	assert.NoError(t, repo.Update(stored))
+	stored, err = repo.GetByID(coupon.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 7.0, stored.Value)
+	assert.Equal(t, 1, stored.UsedCount)
+}
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockCouponRepository is an in-memory implementation of CouponRepository.
+type MockCouponRepository struct {
+	coupons     map[string]models.Coupon
+	redemptions map[string]models.CouponRedemption // Keyed by order ID
+	mu          sync.RWMutex
+}
+
+// NewMockCouponRepository creates a new instance of MockCouponRepository.
+func NewMockCouponRepository() *MockCouponRepository {
+	return &MockCouponRepository{
+		coupons:     make(map[string]models.Coupon),
+		redemptions: make(map[string]models.CouponRedemption),
+	}
+}
+
+// List returns all coupons by code.
+func (r *MockCouponRepository) List() ([]models.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coupons := make([]models.Coupon, 0, len(r.coupons))
+	for _, coupon := range r.coupons {
+		coupons = append(coupons, coupon)
+	}
+	sort.Slice(coupons, func(i, j int) bool { return coupons[i].Code < coupons[j].Code })
+	return coupons, nil
+}
+
+// GetByID returns a coupon by its ID.
+func (r *MockCouponRepository) GetByID(id string) (*models.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coupon, ok := r.coupons[id]
+	if !ok {
+		return nil, fmt.Errorf("coupon with ID %s not found", id)
+	}
+	return &coupon, nil
+}
+
+// GetByCode returns a coupon by its code.
+func (r *MockCouponRepository) GetByCode(code string) (*models.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, coupon := range r.coupons {
+		if coupon.Code == code {
+			return &coupon, nil
+		}
+	}
+	return nil, fmt.Errorf("coupon %s not found", code)
+}
+
+// Create adds a new coupon.
+func (r *MockCouponRepository) Create(coupon *models.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.coupons {
+		if existing.Code == coupon.Code {
+			return fmt.Errorf("failed to create coupon: code %s already exists", coupon.Code)
+		}
+	}
+	if coupon.ID == "" {
+		coupon.ID = uuid.New().String()
+	}
+	coupon.CreatedAt = time.Now()
+	coupon.UpdatedAt = coupon.CreatedAt
+	r.coupons[coupon.ID] = *coupon
+	return nil
+}
+
+// Update saves a coupon's settings, keeping its usage count.
+func (r *MockCouponRepository) Update(coupon *models.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.coupons[coupon.ID]
+	if !ok {
+		return fmt.Errorf("coupon with ID %s not found", coupon.ID)
+	}
+	coupon.UsedCount = existing.UsedCount
+	coupon.CreatedAt = existing.CreatedAt
+	coupon.UpdatedAt = time.Now()
+	r.coupons[coupon.ID] = *coupon
+	return nil
+}
+
+// Delete removes a coupon.
+func (r *MockCouponRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.coupons[id]; !ok {
+		return fmt.Errorf("coupon with ID %s not found", id)
+	}
+	delete(r.coupons, id)
+	return nil
+}
+
+// Redeem records a redemption within the coupon's usage limits.
+func (r *MockCouponRepository) Redeem(coupon *models.Coupon, redemption *models.CouponRedemption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.coupons[coupon.ID]
+	if !ok {
+		return fmt.Errorf("coupon with ID %s not found", coupon.ID)
+	}
+	if stored.PerUserLimit > 0 {
+		used := 0
+		for _, existing := range r.redemptions {
+			if existing.CouponID == coupon.ID && existing.UserID == redemption.UserID {
+				used++
+			}
+		}
+		if used >= stored.PerUserLimit {
+			return fmt.Errorf("invalid coupon: %s has already been used the maximum number of times", coupon.Code)
+		}
+	}
+	if stored.UsageLimit > 0 && stored.UsedCount >= stored.UsageLimit {
+		return fmt.Errorf("invalid coupon: %s is no longer available", coupon.Code)
+	}
+
+	stored.UsedCount++
+	r.coupons[coupon.ID] = stored
+	redemption.CouponID = coupon.ID
+	redemption.ID = uint(len(r.redemptions) + 1)
+	redemption.CreatedAt = time.Now()
+	r.redemptions[redemption.OrderID] = *redemption
+	return nil
+}
+
+// Release undoes the order's redemption, if any.
+func (r *MockCouponRepository) Release(orderID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	redemption, ok := r.redemptions[orderID]
+	if !ok {
+		return nil
+	}
+	delete(r.redemptions, orderID)
+	if coupon, ok := r.coupons[redemption.CouponID]; ok && coupon.UsedCount > 0 {
+		coupon.UsedCount--
+		r.coupons[coupon.ID] = coupon
+	}
+	return nil
+}
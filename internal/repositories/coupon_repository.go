@@ -0,0 +1,18 @@
+package repositories
+
+import "toko/internal/models"
+
+// CouponRepository defines the interface for coupon data access.
+type CouponRepository interface {
+	List() ([]models.Coupon, error)
+	GetByID(id string) (*models.Coupon, error)
+	GetByCode(code string) (*models.Coupon, error)
+	Create(coupon *models.Coupon) error
+	Update(coupon *models.Coupon) error
+	Delete(id string) error
+	// Redeem records the redemption and counts it against the coupon's usage
+	// limits atomically, failing when either limit has been reached.
+	Redeem(coupon *models.Coupon, redemption *models.CouponRedemption) error
+	// Release undoes the redemption made for an order, if any.
+	Release(orderID string) error
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMCustomerNoteRepository is a GORM implementation of CustomerNoteRepository.
+type GORMCustomerNoteRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMCustomerNoteRepository creates a new instance of GORMCustomerNoteRepository.
+func NewGORMCustomerNoteRepository(db *gorm.DB) *GORMCustomerNoteRepository {
+	return &GORMCustomerNoteRepository{
+		db: db,
+	}
+}
+
+// ListTags returns a customer's tags in alphabetical order.
+func (r *GORMCustomerNoteRepository) ListTags(userID string) ([]models.CustomerTag, error) {
+	var tags []models.CustomerTag
+	if err := r.db.Where("user_id = ?", userID).Order("tag").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tags of customer %s: %w", userID, err)
+	}
+	return tags, nil
+}
+
+// AddTag saves a tag, keeping the original one if the customer already has it.
+func (r *GORMCustomerNoteRepository) AddTag(tag *models.CustomerTag) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(tag).Error; err != nil {
+		return fmt.Errorf("failed to tag customer %s: %w", tag.UserID, err)
+	}
+	return nil
+}
+
+// RemoveTag removes a tag from a customer.
+func (r *GORMCustomerNoteRepository) RemoveTag(userID, tag string) error {
+	res := r.db.Where("user_id = ? AND tag = ?", userID, tag).Delete(&models.CustomerTag{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to remove tag %s from customer %s: %w", tag, userID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("tag %s of customer %s not found", tag, userID)
+	}
+	return nil
+}
+
+// ListNotes returns a customer's notes, newest first.
+func (r *GORMCustomerNoteRepository) ListNotes(userID string) ([]models.CustomerNote, error) {
+	var notes []models.CustomerNote
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notes of customer %s: %w", userID, err)
+	}
+	return notes, nil
+}
+
+// AddNote stores a new note.
+func (r *GORMCustomerNoteRepository) AddNote(note *models.CustomerNote) error {
+	if err := r.db.Create(note).Error; err != nil {
+		return fmt.Errorf("failed to add note to customer %s: %w", note.UserID, err)
+	}
+	return nil
+}
+
+// DeleteNote removes one of a customer's notes.
+func (r *GORMCustomerNoteRepository) DeleteNote(userID string, id uint) error {
+	res := r.db.Where("user_id = ?", userID).Delete(&models.CustomerNote{}, id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete note %d: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("note %d of customer %s not found", id, userID)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMCustomerNoteRepository_TagsAndNotes(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.CustomerTag{}, &models.CustomerNote{}))
+	repo := repositories.NewGORMCustomerNoteRepository(db)
+
+	assert.NoError(t, repo.AddTag(&models.CustomerTag{UserID: "u1", Tag: models.CustomerTagVIP}))
+	assert.NoError(t, repo.AddTag(&models.CustomerTag{UserID: "u1", Tag: models.CustomerTagVIP}))
+	assert.NoError(t, repo.AddTag(&models.CustomerTag{UserID: "u1", Tag: models.CustomerTagChargebackRisk}))
+	tags, err := repo.ListTags("u1")
+	assert.NoError(t, err)
+	if assert.Len(t, tags, 2) {
+		assert.Equal(t, models.CustomerTagChargebackRisk, tags[0].Tag)
+	}
+	assert.NoError(t, repo.RemoveTag("u1", models.CustomerTagVIP))
+	assert.ErrorContains(t, repo.RemoveTag("u1", models.CustomerTagVIP), "not found")
+
+	first := &models.CustomerNote{UserID: "u1", Body: "Called about a late parcel"}
+	assert.NoError(t, repo.AddNote(first))
+	assert.NoError(t, repo.AddNote(&models.CustomerNote{UserID: "u1", Body: "Refunded shipping"}))
+	notes, err := repo.ListNotes("u1")
+	assert.NoError(t, err)
+	if assert.Len(t, notes, 2) {
+		assert.Equal(t, "Refunded shipping", notes[0].Body)
+	}
+	assert.ErrorContains(t, repo.DeleteNote("u2", first.ID), "not found")
+	assert.NoError(t, repo.DeleteNote("u1", first.ID))
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockCustomerNoteRepository is an in-memory implementation of CustomerNoteRepository.
+type MockCustomerNoteRepository struct {
+	tags   map[string]models.CustomerTag // Keyed by user ID and tag
+	notes  map[uint]models.CustomerNote
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockCustomerNoteRepository creates a new instance of MockCustomerNoteRepository.
+func NewMockCustomerNoteRepository() *MockCustomerNoteRepository {
+	return &MockCustomerNoteRepository{
+		tags:  make(map[string]models.CustomerTag),
+		notes: make(map[uint]models.CustomerNote),
+	}
+}
+
+func customerTagKey(userID, tag string) string {
+	return userID + "/" + tag
+}
+
+// ListTags returns a customer's tags in alphabetical order.
+func (r *MockCustomerNoteRepository) ListTags(userID string) ([]models.CustomerTag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := []models.CustomerTag{}
+	for _, tag := range r.tags {
+		if tag.UserID == userID {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, nil
+}
+
+// AddTag saves a tag, keeping the original one if the customer already has it.
+func (r *MockCustomerNoteRepository) AddTag(tag *models.CustomerTag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := customerTagKey(tag.UserID, tag.Tag)
+	if _, ok := r.tags[key]; ok {
+		return nil
+	}
+	tag.CreatedAt = time.Now()
+	r.tags[key] = *tag
+	return nil
+}
+
+// RemoveTag removes a tag from a customer.
+func (r *MockCustomerNoteRepository) RemoveTag(userID, tag string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := customerTagKey(userID, tag)
+	if _, ok := r.tags[key]; !ok {
+		return fmt.Errorf("tag %s of customer %s not found", tag, userID)
+	}
+	delete(r.tags, key)
+	return nil
+}
+
+// ListNotes returns a customer's notes, newest first.
+func (r *MockCustomerNoteRepository) ListNotes(userID string) ([]models.CustomerNote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notes := []models.CustomerNote{}
+	for _, note := range r.notes {
+		if note.UserID == userID {
+			notes = append(notes, note)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].ID > notes[j].ID })
+	return notes, nil
+}
+
+// AddNote stores a new note.
+func (r *MockCustomerNoteRepository) AddNote(note *models.CustomerNote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	note.ID = r.nextID
+	note.CreatedAt = time.Now()
+	r.notes[note.ID] = *note
+	return nil
+}
+
+// DeleteNote removes one of a customer's notes.
+func (r *MockCustomerNoteRepository) DeleteNote(userID string, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if note, ok := r.notes[id]; !ok || note.UserID != userID {
+		return fmt.Errorf("note %d of customer %s not found", id, userID)
+	}
+	delete(r.notes, id)
+	return nil
+}
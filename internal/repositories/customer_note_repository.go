@@ -0,0 +1,14 @@
+package repositories
+
+import "toko/internal/models"
+
+// CustomerNoteRepository defines the interface for customer tag and note data access.
+type CustomerNoteRepository interface {
+	ListTags(userID string) ([]models.CustomerTag, error)
+	// AddTag saves a tag; adding a tag the customer already has is not an error.
+	AddTag(tag *models.CustomerTag) error
+	RemoveTag(userID, tag string) error
+	ListNotes(userID string) ([]models.CustomerNote, error)
+	AddNote(note *models.CustomerNote) error
+	DeleteNote(userID string, id uint) error
+}
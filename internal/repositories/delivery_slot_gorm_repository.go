@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMDeliverySlotRepository is a GORM implementation of DeliverySlotRepository.
+type GORMDeliverySlotRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMDeliverySlotRepository creates a new instance of GORMDeliverySlotRepository.
+func NewGORMDeliverySlotRepository(db *gorm.DB) *GORMDeliverySlotRepository {
+	return &GORMDeliverySlotRepository{
+		db: db,
+	}
+}
+
+// List retrieves the slots starting in [from, to), earliest first.
+func (r *GORMDeliverySlotRepository) List(from, to time.Time) ([]models.DeliverySlot, error) {
+	var slots []models.DeliverySlot
+	if err := r.db.Where("starts_at >= ? AND starts_at < ?", from, to).Order("starts_at ASC, id ASC").Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get delivery slots: %w", err)
+	}
+	return slots, nil
+}
+
+// GetByID retrieves a slot by its ID.
+func (r *GORMDeliverySlotRepository) GetByID(id string) (*models.DeliverySlot, error) {
+	var slot models.DeliverySlot
+	if err := r.db.First(&slot, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("delivery slot with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get delivery slot %s: %w", id, err)
+	}
+	return &slot, nil
+}
+
+// Create adds a slot.
+func (r *GORMDeliverySlotRepository) Create(slot *models.DeliverySlot) error {
+	if slot.ID == "" {
+		slot.ID = uuid.New().String()
+	}
+	if err := r.db.Create(slot).Error; err != nil {
+		return fmt.Errorf("failed to create delivery slot: %w", err)
+	}
+	return nil
+}
+
+// Update saves changes to an existing slot without touching its bookings.
+func (r *GORMDeliverySlotRepository) Update(slot *models.DeliverySlot) error {
+	res := r.db.Model(slot).Select("starts_at", "ends_at", "capacity", "active", "updated_at").Updates(slot)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update delivery slot %s: %w", slot.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("delivery slot with ID %s not found", slot.ID)
+	}
+	return nil
+}
+
+// Delete removes a slot.
+func (r *GORMDeliverySlotRepository) Delete(id string) error {
+	res := r.db.Delete(&models.DeliverySlot{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete delivery slot: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("delivery slot with ID %s not found", id)
+	}
+	return nil
+}
+
+// Book increments the booked count of an active slot with capacity left.
+func (r *GORMDeliverySlotRepository) Book(id string) error {
+	res := r.db.Model(&models.DeliverySlot{}).
+		Where("id = ? AND active = ? AND booked < capacity", id, true).
+		Updates(map[string]interface{}{"booked": gorm.Expr("booked + 1"), "updated_at": time.Now()})
+	if res.Error != nil {
+		return fmt.Errorf("failed to book delivery slot %s: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("delivery slot %s is fully booked", id)
+	}
+	return nil
+}
+
+// Release decrements the booked count of a slot.
+func (r *GORMDeliverySlotRepository) Release(id string) error {
+	res := r.db.Model(&models.DeliverySlot{}).
+		Where("id = ? AND booked > 0", id).
+		Updates(map[string]interface{}{"booked": gorm.Expr("booked - 1"), "updated_at": time.Now()})
+	if res.Error != nil {
+		return fmt.Errorf("failed to release delivery slot %s: %w", id, res.Error)
+	}
+	return nil
+}
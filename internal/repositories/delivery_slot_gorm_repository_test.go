@@ -0,0 +1,51 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMDeliverySlotRepository_BookingRespectsCapacity(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.DeliverySlot{}))
+	repo := repositories.NewGORMDeliverySlotRepository(db)
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	slot := &models.DeliverySlot{StartsAt: start, EndsAt: start.Add(2 * time.Hour), Capacity: 2, Active: true}
+	require.NoError(t, repo.Create(slot))
+	later := &models.DeliverySlot{StartsAt: start.Add(48 * time.Hour), EndsAt: start.Add(50 * time.Hour), Capacity: 1, Active: true}
+	require.NoError(t, repo.Create(later))
+
+	slots, err := repo.List(start, start.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, slots, 1)
+	assert.Equal(t, slot.ID, slots[0].ID)
+
+	require.NoError(t, repo.Book(slot.ID))
+	require.NoError(t, repo.Book(slot.ID))
+	assert.ErrorContains(t, repo.Book(slot.ID), "fully booked")
+	assert.ErrorContains(t, repo.Book("missing"), "not found")
+
+	// Editing the slot keeps its bookings
+	slot.Capacity = 3
+	require.NoError(t, repo.Update(slot))
+	require.NoError(t, repo.Book(slot.ID))
+	stored, err := repo.GetByID(slot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.Booked)
+
+	require.NoError(t, repo.Release(slot.ID))
+	stored, _ = repo.GetByID(slot.ID)
+	assert.Equal(t, 2, stored.Booked)
+
+	// Inactive slots cannot be booked
+	later.Active = false
+	require.NoError(t, repo.Update(later))
+	assert.ErrorContains(t, repo.Book(later.ID), "fully booked")
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockDeliverySlotRepository is an in-memory implementation of DeliverySlotRepository.
+type MockDeliverySlotRepository struct {
+	slots map[string]models.DeliverySlot
+	mu    sync.RWMutex
+}
+
+// NewMockDeliverySlotRepository creates a new instance of MockDeliverySlotRepository.
+func NewMockDeliverySlotRepository() *MockDeliverySlotRepository {
+	return &MockDeliverySlotRepository{
+		slots: make(map[string]models.DeliverySlot),
+	}
+}
+
+// List returns the slots starting in [from, to), earliest first.
+func (r *MockDeliverySlotRepository) List(from, to time.Time) ([]models.DeliverySlot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	slots := []models.DeliverySlot{}
+	for _, slot := range r.slots {
+		if !slot.StartsAt.Before(from) && slot.StartsAt.Before(to) {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].StartsAt.Equal(slots[j].StartsAt) {
+			return slots[i].ID < slots[j].ID
+		}
+		return slots[i].StartsAt.Before(slots[j].StartsAt)
+	})
+	return slots, nil
+}
+
+// GetByID returns a slot.
+func (r *MockDeliverySlotRepository) GetByID(id string) (*models.DeliverySlot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	slot, ok := r.slots[id]
+	if !ok {
+		return nil, fmt.Errorf("delivery slot with ID %s not found", id)
+	}
+	return &slot, nil
+}
+
+// Create adds a slot.
+func (r *MockDeliverySlotRepository) Create(slot *models.DeliverySlot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slot.ID == "" {
+		slot.ID = uuid.New().String()
+	}
+	slot.CreatedAt = time.Now()
+	slot.UpdatedAt = slot.CreatedAt
+	r.slots[slot.ID] = *slot
+	return nil
+}
+
+// Update saves changes to an existing slot without touching its bookings.
+func (r *MockDeliverySlotRepository) Update(slot *models.DeliverySlot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.slots[slot.ID]
+	if !ok {
+		return fmt.Errorf("delivery slot with ID %s not found", slot.ID)
+	}
+	existing.StartsAt = slot.StartsAt
+	existing.EndsAt = slot.EndsAt
+	existing.Capacity = slot.Capacity
+	existing.Active = slot.Active
+	existing.UpdatedAt = time.Now()
+	r.slots[slot.ID] = existing
+	return nil
+}
+
+// Delete removes a slot.
+func (r *MockDeliverySlotRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.slots[id]; !ok {
+		return fmt.Errorf("delivery slot with ID %s not found", id)
+	}
+	delete(r.slots, id)
+	return nil
+}
+
+// Book takes one unit of an active slot's capacity.
+func (r *MockDeliverySlotRepository) Book(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slot, ok := r.slots[id]
+	if !ok {
+		return fmt.Errorf("delivery slot with ID %s not found", id)
+	}
+	if !slot.Active || slot.Booked >= slot.Capacity {
+		return fmt.Errorf("delivery slot %s is fully booked", id)
+	}
+	slot.Booked++
+	r.slots[id] = slot
+	return nil
+}
+
+// Release gives back one unit of capacity.
+func (r *MockDeliverySlotRepository) Release(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slot, ok := r.slots[id]; ok && slot.Booked > 0 {
+		slot.Booked--
+		r.slots[id] = slot
+	}
+	return nil
+}
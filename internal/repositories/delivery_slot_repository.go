@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// DeliverySlotRepository defines the interface for delivery slot data access.
+type DeliverySlotRepository interface {
+	// List returns the slots starting in [from, to), earliest first.
+	List(from, to time.Time) ([]models.DeliverySlot, error)
+	GetByID(id string) (*models.DeliverySlot, error)
+	Create(slot *models.DeliverySlot) error
+	// Update saves the window, capacity and availability; the booked count is left alone.
+	Update(slot *models.DeliverySlot) error
+	Delete(id string) error
+	// Book takes one unit of an active slot's capacity, atomically, so
+	// concurrent orders cannot overbook it.
+	Book(id string) error
+	// Release gives back one unit of capacity.
+	Release(id string) error
+}
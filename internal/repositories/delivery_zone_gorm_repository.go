@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMDeliveryZoneRepository is a GORM implementation of DeliveryZoneRepository.
+type GORMDeliveryZoneRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMDeliveryZoneRepository creates a new instance of GORMDeliveryZoneRepository.
+func NewGORMDeliveryZoneRepository(db *gorm.DB) *GORMDeliveryZoneRepository {
+	return &GORMDeliveryZoneRepository{
+		db: db,
+	}
+}
+
+// List retrieves all zones, highest priority first, then by name.
+func (r *GORMDeliveryZoneRepository) List() ([]models.DeliveryZone, error) {
+	var zones []models.DeliveryZone
+	if err := r.db.Order("priority DESC, name ASC").Find(&zones).Error; err != nil {
+		return nil, fmt.Errorf("failed to get delivery zones: %w", err)
+	}
+	return zones, nil
+}
+
+// GetByID retrieves a zone by its ID.
+func (r *GORMDeliveryZoneRepository) GetByID(id string) (*models.DeliveryZone, error) {
+	var zone models.DeliveryZone
+	if err := r.db.First(&zone, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("delivery zone with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get delivery zone %s: %w", id, err)
+	}
+	return &zone, nil
+}
+
+// Create adds a zone.
+func (r *GORMDeliveryZoneRepository) Create(zone *models.DeliveryZone) error {
+	if zone.ID == "" {
+		zone.ID = uuid.New().String()
+	}
+	if err := r.db.Create(zone).Error; err != nil {
+		return fmt.Errorf("failed to create delivery zone: %w", err)
+	}
+	return nil
+}
+
+// Update saves changes to an existing zone.
+func (r *GORMDeliveryZoneRepository) Update(zone *models.DeliveryZone) error {
+	res := r.db.Model(zone).Select("*").Omit("created_at").Updates(zone)
+	if res.Error != nil {
+		return fmt.Errorf("failed to update delivery zone %s: %w", zone.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("delivery zone with ID %s not found", zone.ID)
+	}
+	return nil
+}
+
+// Delete removes a zone.
+func (r *GORMDeliveryZoneRepository) Delete(id string) error {
+	res := r.db.Delete(&models.DeliveryZone{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete delivery zone: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("delivery zone with ID %s not found", id)
+	}
+	return nil
+}
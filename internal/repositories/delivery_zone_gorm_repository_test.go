@@ -0,0 +1,40 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMDeliveryZoneRepository_CRUD(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.DeliveryZone{}))
+	repo := repositories.NewGORMDeliveryZoneRepository(db)
+
+	city := &models.DeliveryZone{Name: "City", Polygon: [][2]float64{{-6.1, 106.7}, {-6.1, 106.9}, {-6.3, 106.9}}, Fee: 10000, Active: true}
+	suburbs := &models.DeliveryZone{Name: "Suburbs", PostalCodes: "16*", Fee: 20000, Priority: -1}
+	require.NoError(t, repo.Create(suburbs))
+	require.NoError(t, repo.Create(city))
+
+	zones, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, zones, 2)
+	assert.Equal(t, "City", zones[0].Name)
+	assert.Equal(t, city.Polygon, zones[0].Polygon, "the polygon survives a round trip")
+
+	// Deactivating a zone must be saved even though false is the zero value
+	city.Active = false
+	require.NoError(t, repo.Update(city))
+	stored, err := repo.GetByID(city.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Active)
+
+	require.NoError(t, repo.Delete(suburbs.ID))
+	assert.ErrorContains(t, repo.Delete(suburbs.ID), "not found")
+	_, err = repo.GetByID(suburbs.ID)
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockDeliveryZoneRepository is an in-memory implementation of DeliveryZoneRepository.
+type MockDeliveryZoneRepository struct {
+	zones map[string]models.DeliveryZone
+	mu    sync.RWMutex
+}
+
+// NewMockDeliveryZoneRepository creates a new instance of MockDeliveryZoneRepository.
+func NewMockDeliveryZoneRepository() *MockDeliveryZoneRepository {
+	return &MockDeliveryZoneRepository{
+		zones: make(map[string]models.DeliveryZone),
+	}
+}
+
+// List returns all zones, highest priority first, then by name.
+func (r *MockDeliveryZoneRepository) List() ([]models.DeliveryZone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	zones := make([]models.DeliveryZone, 0, len(r.zones))
+	for _, zone := range r.zones {
+		zones = append(zones, zone)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].Priority != zones[j].Priority {
+			return zones[i].Priority > zones[j].Priority
+		}
+		return zones[i].Name < zones[j].Name
+	})
+	return zones, nil
+}
+
+// GetByID returns a zone.
+func (r *MockDeliveryZoneRepository) GetByID(id string) (*models.DeliveryZone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	zone, ok := r.zones[id]
+	if !ok {
+		return nil, fmt.Errorf("delivery zone with ID %s not found", id)
+	}
+	return &zone, nil
+}
+
+// Create adds a zone.
+func (r *MockDeliveryZoneRepository) Create(zone *models.DeliveryZone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if zone.ID == "" {
+		zone.ID = uuid.New().String()
+	}
+	zone.CreatedAt = time.Now()
+	zone.UpdatedAt = zone.CreatedAt
+	r.zones[zone.ID] = *zone
+	return nil
+}
+
+// Update saves changes to an existing zone.
+func (r *MockDeliveryZoneRepository) Update(zone *models.DeliveryZone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.zones[zone.ID]
+	if !ok {
+		return fmt.Errorf("delivery zone with ID %s not found", zone.ID)
+	}
+	zone.CreatedAt = existing.CreatedAt
+	zone.UpdatedAt = time.Now()
+	r.zones[zone.ID] = *zone
+	return nil
+}
+
+// Delete removes a zone.
+func (r *MockDeliveryZoneRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zones[id]; !ok {
+		return fmt.Errorf("delivery zone with ID %s not found", id)
+	}
+	delete(r.zones, id)
+	return nil
+}
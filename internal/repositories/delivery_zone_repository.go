@@ -0,0 +1,13 @@
+package repositories
+
+import "toko/internal/models"
+
+// DeliveryZoneRepository defines the interface for delivery zone data access.
+type DeliveryZoneRepository interface {
+	// List returns all zones, highest priority first.
+	List() ([]models.DeliveryZone, error)
+	GetByID(id string) (*models.DeliveryZone, error)
+	Create(zone *models.DeliveryZone) error
+	Update(zone *models.DeliveryZone) error
+	Delete(id string) error
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMDeviceTokenRepository is a GORM implementation of DeviceTokenRepository.
+type GORMDeviceTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMDeviceTokenRepository creates a new instance of GORMDeviceTokenRepository.
+func NewGORMDeviceTokenRepository(db *gorm.DB) *GORMDeviceTokenRepository {
+	return &GORMDeviceTokenRepository{
+		db: db,
+	}
+}
+
+// Create saves a device token.
+func (r *GORMDeviceTokenRepository) Create(token *models.DeviceToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create device token for user %s: %w", token.UserID, err)
+	}
+	return nil
+}
+
+// GetByHash retrieves a device token by the hash of its value.
+func (r *GORMDeviceTokenRepository) GetByHash(hash string) (*models.DeviceToken, error) {
+	var token models.DeviceToken
+	if err := r.db.First(&token, "token_hash = ?", hash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("device token not found")
+		}
+		return nil, fmt.Errorf("failed to get device token: %w", err)
+	}
+	return &token, nil
+}
+
+// ListActiveByUser lists the device tokens of a user still in use.
+func (r *GORMDeviceTokenRepository) ListActiveByUser(userID string, now time.Time) ([]models.DeviceToken, error) {
+	var tokens []models.DeviceToken
+	if err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).Order("created_at DESC, id DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list device tokens of user %s: %w", userID, err)
+	}
+	return tokens, nil
+}
+
+// Touch records that a device token was used.
+func (r *GORMDeviceTokenRepository) Touch(id uint, at time.Time) error {
+	if err := r.db.Model(&models.DeviceToken{}).Where("id = ?", id).Update("last_used_at", at).Error; err != nil {
+		return fmt.Errorf("failed to update device token %d: %w", id, err)
+	}
+	return nil
+}
+
+// Revoke revokes a device token of a user.
+func (r *GORMDeviceTokenRepository) Revoke(userID string, id uint, at time.Time) error {
+	res := r.db.Model(&models.DeviceToken{}).Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).Update("revoked_at", at)
+	if res.Error != nil {
+		return fmt.Errorf("failed to revoke device token %d: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("device with ID %d not found", id)
+	}
+	return nil
+}
+
+// RevokeUser revokes the device tokens of a user.
+func (r *GORMDeviceTokenRepository) RevokeUser(userID string, at time.Time) error {
+	if err := r.db.Model(&models.DeviceToken{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", at).Error; err != nil {
+		return fmt.Errorf("failed to revoke device tokens of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes the device tokens that expired before the time.
+func (r *GORMDeviceTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", before).Delete(&models.DeviceToken{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired device tokens: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
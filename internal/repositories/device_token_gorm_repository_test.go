@@ -0,0 +1,52 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMDeviceTokenRepository(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.DeviceToken{}))
+	repo := repositories.NewGORMDeviceTokenRepository(db)
+	now := time.Now()
+
+	phone := &models.DeviceToken{UserID: "user-1", Name: "Phone", TokenHash: "hash-1", FingerprintHash: "fp-1", ExpiresAt: now.Add(time.Hour)}
+	tablet := &models.DeviceToken{UserID: "user-1", Name: "Tablet", TokenHash: "hash-2", FingerprintHash: "fp-2", ExpiresAt: now.Add(time.Hour)}
+	old := &models.DeviceToken{UserID: "user-1", Name: "Old", TokenHash: "hash-3", FingerprintHash: "fp-3", ExpiresAt: now.Add(-time.Hour)}
+	for _, token := range []*models.DeviceToken{phone, tablet, old} {
+		require.NoError(t, repo.Create(token))
+	}
+	assert.Error(t, repo.Create(&models.DeviceToken{UserID: "user-2", TokenHash: "hash-1"}), "token hashes are unique")
+
+	found, err := repo.GetByHash("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Phone", found.Name)
+	_, err = repo.GetByHash("missing")
+	assert.ErrorContains(t, err, "not found")
+
+	require.NoError(t, repo.Touch(phone.ID, now))
+	active, err := repo.ListActiveByUser("user-1", now)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, "Tablet", active[0].Name, "newest first")
+	assert.NotNil(t, active[1].LastUsedAt)
+
+	assert.ErrorContains(t, repo.Revoke("user-2", phone.ID, now), "not found")
+	require.NoError(t, repo.Revoke("user-1", phone.ID, now))
+	assert.ErrorContains(t, repo.Revoke("user-1", phone.ID, now), "not found")
+	require.NoError(t, repo.RevokeUser("user-1", now))
+	active, err = repo.ListActiveByUser("user-1", now)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	deleted, err := repo.DeleteExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
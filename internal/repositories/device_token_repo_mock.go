@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockDeviceTokenRepository is an in-memory implementation of DeviceTokenRepository.
+type MockDeviceTokenRepository struct {
+	tokens map[uint]models.DeviceToken
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockDeviceTokenRepository creates a new instance of MockDeviceTokenRepository.
+func NewMockDeviceTokenRepository() *MockDeviceTokenRepository {
+	return &MockDeviceTokenRepository{
+		tokens: make(map[uint]models.DeviceToken),
+		nextID: 1,
+	}
+}
+
+// Create saves a device token.
+func (r *MockDeviceTokenRepository) Create(token *models.DeviceToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return fmt.Errorf("failed to create device token for user %s: duplicate hash", token.UserID)
+		}
+	}
+	token.ID = r.nextID
+	r.nextID++
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+// GetByHash retrieves a device token by the hash of its value.
+func (r *MockDeviceTokenRepository) GetByHash(hash string) (*models.DeviceToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, fmt.Errorf("device token not found")
+}
+
+// ListActiveByUser lists the device tokens of a user still in use.
+func (r *MockDeviceTokenRepository) ListActiveByUser(userID string, now time.Time) ([]models.DeviceToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tokens []models.DeviceToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil && token.ExpiresAt.After(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID > tokens[j].ID })
+	return tokens, nil
+}
+
+// Touch records that a device token was used.
+func (r *MockDeviceTokenRepository) Touch(id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.tokens[id]; ok {
+		token.LastUsedAt = &at
+		r.tokens[id] = token
+	}
+	return nil
+}
+
+// Revoke revokes a device token of a user.
+func (r *MockDeviceTokenRepository) Revoke(userID string, id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UserID != userID || token.RevokedAt != nil {
+		return fmt.Errorf("device with ID %d not found", id)
+	}
+	token.RevokedAt = &at
+	r.tokens[id] = token
+	return nil
+}
+
+// RevokeUser revokes the device tokens of a user.
+func (r *MockDeviceTokenRepository) RevokeUser(userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes the device tokens that expired before the time.
+func (r *MockDeviceTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// DeviceTokenRepository defines the interface for device token data access.
+type DeviceTokenRepository interface {
+	Create(token *models.DeviceToken) error
+	// GetByHash returns the token with the hash, revoked ones included.
+	GetByHash(hash string) (*models.DeviceToken, error)
+	// ListActiveByUser returns the tokens of the user that are neither
+	// revoked nor expired at the time, newest first.
+	ListActiveByUser(userID string, now time.Time) ([]models.DeviceToken, error)
+	// Touch records that the token was used.
+	Touch(id uint, at time.Time) error
+	// Revoke revokes a token of the user unless it already is.
+	Revoke(userID string, id uint, at time.Time) error
+	// RevokeUser revokes every token of the user that is not revoked yet.
+	RevokeUser(userID string, at time.Time) error
+	// DeleteExpired removes tokens that expired before the time and returns
+	// how many were removed.
+	DeleteExpired(before time.Time) (int64, error)
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMERPSyncRepository is a GORM implementation of ERPSyncRepository.
+type GORMERPSyncRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMERPSyncRepository creates a new instance of GORMERPSyncRepository.
+func NewGORMERPSyncRepository(db *gorm.DB) *GORMERPSyncRepository {
+	return &GORMERPSyncRepository{
+		db: db,
+	}
+}
+
+// GetState returns the state of a connector's entity and direction.
+func (r *GORMERPSyncRepository) GetState(connector, entity, direction string) (*models.SyncState, error) {
+	var state models.SyncState
+	err := r.db.Where("connector = ? AND entity = ? AND direction = ?", connector, entity, direction).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.SyncState{Connector: connector, Entity: entity, Direction: direction}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s sync state of %s: %w", entity, direction, connector, err)
+	}
+	return &state, nil
+}
+
+// SaveState creates or replaces a sync state.
+func (r *GORMERPSyncRepository) SaveState(state *models.SyncState) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "connector"}, {Name: "entity"}, {Name: "direction"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cursor", "last_run_at", "status", "error", "records", "conflicts", "updated_at"}),
+	}).Create(state).Error
+	if err != nil {
+		return fmt.Errorf("failed to save %s %s sync state of %s: %w", state.Entity, state.Direction, state.Connector, err)
+	}
+	return nil
+}
+
+// ListStates returns every sync state.
+func (r *GORMERPSyncRepository) ListStates() ([]models.SyncState, error) {
+	var states []models.SyncState
+	if err := r.db.Order("connector, entity, direction").Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sync states: %w", err)
+	}
+	return states, nil
+}
+
+// AddConflict records a conflict.
+func (r *GORMERPSyncRepository) AddConflict(conflict *models.SyncConflict) error {
+	if err := r.db.Create(conflict).Error; err != nil {
+		return fmt.Errorf("failed to record sync conflict: %w", err)
+	}
+	return nil
+}
+
+// ListConflicts returns a page of conflicts, newest first.
+func (r *GORMERPSyncRepository) ListConflicts(opts SyncConflictListOptions) ([]models.SyncConflict, int64, error) {
+	query := r.db.Model(&models.SyncConflict{})
+	if opts.Connector != "" {
+		query = query.Where("connector = ?", opts.Connector)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count sync conflicts: %w", err)
+	}
+	var conflicts []models.SyncConflict
+	if err := query.Order("created_at DESC, id DESC").Limit(opts.Limit).Offset(opts.Offset).Find(&conflicts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list sync conflicts: %w", err)
+	}
+	return conflicts, total, nil
+}
@@ -0,0 +1,46 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMERPSyncRepository_StatesAndConflicts(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.SyncState{}, &models.SyncConflict{}))
+	repo := repositories.NewGORMERPSyncRepository(db)
+
+	state, err := repo.GetState("csv", "products", models.SyncDirectionPull)
+	require.NoError(t, err)
+	assert.True(t, state.Cursor.IsZero())
+
+	cursor := time.Now().Truncate(time.Second)
+	state.Cursor = cursor
+	state.Status = models.SyncStatusOK
+	state.Records = 3
+	require.NoError(t, repo.SaveState(state))
+	state.Records = 5
+	require.NoError(t, repo.SaveState(state))
+	require.NoError(t, repo.SaveState(&models.SyncState{Connector: "csv", Entity: "orders", Direction: models.SyncDirectionPush}))
+
+	state, err = repo.GetState("csv", "products", models.SyncDirectionPull)
+	require.NoError(t, err)
+	assert.Equal(t, 5, state.Records)
+	assert.True(t, cursor.Equal(state.Cursor))
+	states, err := repo.ListStates()
+	require.NoError(t, err)
+	assert.Len(t, states, 2)
+
+	require.NoError(t, repo.AddConflict(&models.SyncConflict{Connector: "csv", Entity: "products", Key: "SKU-1", Field: "price"}))
+	require.NoError(t, repo.AddConflict(&models.SyncConflict{Connector: "rest", Entity: "stock", Key: "SKU-2", Field: "stock"}))
+	conflicts, total, err := repo.ListConflicts(repositories.SyncConflictListOptions{Connector: "csv", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "SKU-1", conflicts[0].Key)
+}
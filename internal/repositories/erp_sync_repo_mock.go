@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockERPSyncRepository is an in-memory implementation of ERPSyncRepository.
+type MockERPSyncRepository struct {
+	states    map[string]models.SyncState // Keyed by connector, entity and direction
+	conflicts []models.SyncConflict
+	mu        sync.RWMutex
+}
+
+// NewMockERPSyncRepository creates a new instance of MockERPSyncRepository.
+func NewMockERPSyncRepository() *MockERPSyncRepository {
+	return &MockERPSyncRepository{
+		states: make(map[string]models.SyncState),
+	}
+}
+
+func syncStateKey(connector, entity, direction string) string {
+	return connector + "/" + entity + "/" + direction
+}
+
+// GetState returns the state of a connector's entity and direction.
+func (r *MockERPSyncRepository) GetState(connector, entity, direction string) (*models.SyncState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if state, ok := r.states[syncStateKey(connector, entity, direction)]; ok {
+		return &state, nil
+	}
+	return &models.SyncState{Connector: connector, Entity: entity, Direction: direction}, nil
+}
+
+// SaveState creates or replaces a sync state.
+func (r *MockERPSyncRepository) SaveState(state *models.SyncState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.UpdatedAt = time.Now()
+	r.states[syncStateKey(state.Connector, state.Entity, state.Direction)] = *state
+	return nil
+}
+
+// ListStates returns every sync state.
+func (r *MockERPSyncRepository) ListStates() ([]models.SyncState, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	states := make([]models.SyncState, 0, len(r.states))
+	for _, state := range r.states {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return syncStateKey(states[i].Connector, states[i].Entity, states[i].Direction) <
+			syncStateKey(states[j].Connector, states[j].Entity, states[j].Direction)
+	})
+	return states, nil
+}
+
+// AddConflict records a conflict.
+func (r *MockERPSyncRepository) AddConflict(conflict *models.SyncConflict) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conflict.ID = uint(len(r.conflicts) + 1)
+	conflict.CreatedAt = time.Now()
+	r.conflicts = append(r.conflicts, *conflict)
+	return nil
+}
+
+// ListConflicts returns a page of conflicts, newest first.
+func (r *MockERPSyncRepository) ListConflicts(opts SyncConflictListOptions) ([]models.SyncConflict, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.SyncConflict
+	for i := len(r.conflicts) - 1; i >= 0; i-- {
+		if opts.Connector == "" || r.conflicts[i].Connector == opts.Connector {
+			matched = append(matched, r.conflicts[i])
+		}
+	}
+	total := int64(len(matched))
+	if opts.Offset >= len(matched) {
+		return []models.SyncConflict{}, total, nil
+	}
+	matched = matched[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(matched) {
+		matched = matched[:opts.Limit]
+	}
+	return matched, total, nil
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import "toko/internal/models"
+
+// SyncConflictListOptions filters and paginates the ERP sync conflict log.
+type SyncConflictListOptions struct {
+	Connector string
+	Limit     int
+	Offset    int
+}
+
+// ERPSyncRepository defines the interface for ERP sync state and conflict log data access.
+type ERPSyncRepository interface {
+	// GetState returns the state of a connector's entity and direction; a
+	// zero state when it has never run.
+	GetState(connector, entity, direction string) (*models.SyncState, error)
+	SaveState(state *models.SyncState) error
+	ListStates() ([]models.SyncState, error)
+	AddConflict(conflict *models.SyncConflict) error
+	// ListConflicts returns a page of conflicts, newest first, and the total number of matches.
+	ListConflicts(opts SyncConflictListOptions) ([]models.SyncConflict, int64, error)
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMExportJobRepository is a GORM implementation of ExportJobRepository.
+type GORMExportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMExportJobRepository creates a new instance of GORMExportJobRepository.
+func NewGORMExportJobRepository(db *gorm.DB) *GORMExportJobRepository {
+	return &GORMExportJobRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new export job.
+func (r *GORMExportJobRepository) Create(job *models.ExportJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	if err := r.db.Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an export job by its ID.
+func (r *GORMExportJobRepository) GetByID(id string) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := r.db.First(&job, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("export job with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get export job by ID %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// Update saves all fields of an export job.
+func (r *GORMExportJobRepository) Update(job *models.ExportJob) error {
+	if err := r.db.Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update export job %s: %w", job.ID, err)
+	}
+	return nil
+}
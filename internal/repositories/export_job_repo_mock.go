@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockExportJobRepository is an in-memory implementation of ExportJobRepository.
+type MockExportJobRepository struct {
+	jobs map[string]models.ExportJob
+	mu   sync.RWMutex
+}
+
+// NewMockExportJobRepository creates a new instance of MockExportJobRepository.
+func NewMockExportJobRepository() *MockExportJobRepository {
+	return &MockExportJobRepository{
+		jobs: make(map[string]models.ExportJob),
+	}
+}
+
+// Create adds a new export job.
+func (r *MockExportJobRepository) Create(job *models.ExportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+// GetByID returns an export job by its ID.
+func (r *MockExportJobRepository) GetByID(id string) (*models.ExportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("export job with ID %s not found", id)
+	}
+	return &job, nil
+}
+
+// Update replaces a stored export job.
+func (r *MockExportJobRepository) Update(job *models.ExportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; !ok {
+		return fmt.Errorf("export job with ID %s not found for update", job.ID)
+	}
+	job.UpdatedAt = time.Now()
+	r.jobs[job.ID] = *job
+	return nil
+}
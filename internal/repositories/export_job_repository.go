@@ -0,0 +1,10 @@
+package repositories
+
+import "toko/internal/models"
+
+// ExportJobRepository defines the interface for export job data access.
+type ExportJobRepository interface {
+	Create(job *models.ExportJob) error
+	GetByID(id string) (*models.ExportJob, error)
+	Update(job *models.ExportJob) error
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMImpersonationRepository is a GORM implementation of ImpersonationRepository.
+type GORMImpersonationRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMImpersonationRepository creates a new instance of GORMImpersonationRepository.
+func NewGORMImpersonationRepository(db *gorm.DB) *GORMImpersonationRepository {
+	return &GORMImpersonationRepository{
+		db: db,
+	}
+}
+
+// Create saves an impersonation.
+func (r *GORMImpersonationRepository) Create(impersonation *models.Impersonation) error {
+	if err := r.db.Create(impersonation).Error; err != nil {
+		return fmt.Errorf("failed to record impersonation of user %s: %w", impersonation.UserID, err)
+	}
+	return nil
+}
+
+// ListByUser lists the impersonations of a user.
+func (r *GORMImpersonationRepository) ListByUser(userID string) ([]models.Impersonation, error) {
+	var impersonations []models.Impersonation
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&impersonations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list impersonations of user %s: %w", userID, err)
+	}
+	return impersonations, nil
+}
+
+// CreateAction saves a change made while impersonating a user.
+func (r *GORMImpersonationRepository) CreateAction(action *models.ImpersonationAction) error {
+	if err := r.db.Create(action).Error; err != nil {
+		return fmt.Errorf("failed to record impersonated action on user %s: %w", action.UserID, err)
+	}
+	return nil
+}
+
+// ListActionsByUser lists the changes made while impersonating a user.
+func (r *GORMImpersonationRepository) ListActionsByUser(userID string) ([]models.ImpersonationAction, error) {
+	var actions []models.ImpersonationAction
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list impersonated actions on user %s: %w", userID, err)
+	}
+	return actions, nil
+}
@@ -0,0 +1,36 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMImpersonationRepository(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Impersonation{}, &models.ImpersonationAction{}))
+	repo := repositories.NewGORMImpersonationRepository(db)
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	require.NoError(t, repo.Create(&models.Impersonation{AdminID: "admin-1", UserID: "user-1", Reason: "Ticket 1", TokenID: "jti-1", ExpiresAt: expiresAt}))
+	require.NoError(t, repo.Create(&models.Impersonation{AdminID: "admin-2", UserID: "user-1", Reason: "Ticket 2", TokenID: "jti-2", ExpiresAt: expiresAt}))
+	require.NoError(t, repo.Create(&models.Impersonation{AdminID: "admin-1", UserID: "user-2", Reason: "Ticket 3", TokenID: "jti-3", ExpiresAt: expiresAt}))
+
+	impersonations, err := repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, impersonations, 2)
+	assert.Equal(t, "Ticket 2", impersonations[0].Reason, "newest first")
+	assert.Equal(t, "admin-2", impersonations[0].AdminID)
+
+	require.NoError(t, repo.CreateAction(&models.ImpersonationAction{ImpersonatorID: "admin-1", UserID: "user-1", TokenID: "jti-1", Method: "POST", Path: "/api/v1/cart/items", Status: 201}))
+	require.NoError(t, repo.CreateAction(&models.ImpersonationAction{ImpersonatorID: "admin-1", UserID: "user-2", TokenID: "jti-3", Method: "POST", Path: "/api/v1/orders", Status: 201}))
+	actions, err := repo.ListActionsByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, "admin-1", actions[0].ImpersonatorID)
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockImpersonationRepository is an in-memory implementation of ImpersonationRepository.
+type MockImpersonationRepository struct {
+	impersonations []models.Impersonation
+	actions        []models.ImpersonationAction
+	mu             sync.RWMutex
+}
+
+// NewMockImpersonationRepository creates a new instance of MockImpersonationRepository.
+func NewMockImpersonationRepository() *MockImpersonationRepository {
+	return &MockImpersonationRepository{}
+}
+
+// Create saves an impersonation.
+func (r *MockImpersonationRepository) Create(impersonation *models.Impersonation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	impersonation.ID = uint(len(r.impersonations) + 1)
+	impersonation.CreatedAt = time.Now()
+	r.impersonations = append(r.impersonations, *impersonation)
+	return nil
+}
+
+// ListByUser lists the impersonations of a user.
+func (r *MockImpersonationRepository) ListByUser(userID string) ([]models.Impersonation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var impersonations []models.Impersonation
+	for i := len(r.impersonations) - 1; i >= 0; i-- {
+		if r.impersonations[i].UserID == userID {
+			impersonations = append(impersonations, r.impersonations[i])
+		}
+	}
+	return impersonations, nil
+}
+
+// CreateAction saves a change made while impersonating a user.
+func (r *MockImpersonationRepository) CreateAction(action *models.ImpersonationAction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	action.ID = uint(len(r.actions) + 1)
+	action.CreatedAt = time.Now()
+	r.actions = append(r.actions, *action)
+	return nil
+}
+
+// ListActionsByUser lists the changes made while impersonating a user.
+func (r *MockImpersonationRepository) ListActionsByUser(userID string) ([]models.ImpersonationAction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var actions []models.ImpersonationAction
+	for i := len(r.actions) - 1; i >= 0; i-- {
+		if r.actions[i].UserID == userID {
+			actions = append(actions, r.actions[i])
+		}
+	}
+	return actions, nil
+}
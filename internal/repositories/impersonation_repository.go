@@ -0,0 +1,14 @@
+package repositories
+
+import "toko/internal/models"
+
+// ImpersonationRepository defines the interface for impersonation audit data access.
+type ImpersonationRepository interface {
+	Create(impersonation *models.Impersonation) error
+	// ListByUser returns the impersonations of the user, newest first.
+	ListByUser(userID string) ([]models.Impersonation, error)
+	CreateAction(action *models.ImpersonationAction) error
+	// ListActionsByUser returns the changes made while impersonating the
+	// user, newest first.
+	ListActionsByUser(userID string) ([]models.ImpersonationAction, error)
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMInventoryForecastRepository is a GORM implementation of InventoryForecastRepository.
+type GORMInventoryForecastRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMInventoryForecastRepository creates a new instance of GORMInventoryForecastRepository.
+func NewGORMInventoryForecastRepository(db *gorm.DB) *GORMInventoryForecastRepository {
+	return &GORMInventoryForecastRepository{
+		db: db,
+	}
+}
+
+// List returns forecasts with the least stock coverage first; products that
+// are not selling come last.
+func (r *GORMInventoryForecastRepository) List(lowStockOnly bool) ([]models.InventoryForecast, error) {
+	var forecasts []models.InventoryForecast
+	query := r.db.Order("days_remaining IS NULL, days_remaining ASC, name ASC")
+	if lowStockOnly {
+		query = query.Where("low_stock = ?", true)
+	}
+	if err := query.Find(&forecasts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list inventory forecasts: %w", err)
+	}
+	return forecasts, nil
+}
+
+// ReplaceAll deletes the previous forecasts and stores the new ones in a single transaction.
+func (r *GORMInventoryForecastRepository) ReplaceAll(forecasts []models.InventoryForecast) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.InventoryForecast{}).Error; err != nil {
+			return fmt.Errorf("failed to clear inventory forecasts: %w", err)
+		}
+		if len(forecasts) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(forecasts, 500).Error; err != nil {
+			return fmt.Errorf("failed to store inventory forecasts: %w", err)
+		}
+		return nil
+	})
+}
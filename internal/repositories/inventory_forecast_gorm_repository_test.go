@@ -0,0 +1,36 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMInventoryForecastRepository_ReplaceAndList(t *testing.T) {
+	repo := repositories.NewGORMInventoryForecastRepository(setupDB(t))
+	days := func(d float64) *float64 { return &d }
+
+	assert.NoError(t, repo.ReplaceAll([]models.InventoryForecast{
+		{ProductID: "p1", Name: "Idle", ComputedAt: time.Now()},
+		{ProductID: "p2", Name: "Slow", DaysRemaining: days(40), ComputedAt: time.Now()},
+		{ProductID: "p3", Name: "Fast", DaysRemaining: days(2), LowStock: true, ComputedAt: time.Now()},
+	}))
+	forecasts, err := repo.List(false)
+	assert.NoError(t, err)
+	if assert.Len(t, forecasts, 3) {
+		assert.Equal(t, []string{"p3", "p2", "p1"}, []string{forecasts[0].ProductID, forecasts[1].ProductID, forecasts[2].ProductID})
+	}
+
+	// Replacing drops forecasts of products that are no longer tracked
+	assert.NoError(t, repo.ReplaceAll([]models.InventoryForecast{{ProductID: "p3", Name: "Fast", DaysRemaining: days(1), LowStock: true}}))
+	forecasts, err = repo.List(true)
+	assert.NoError(t, err)
+	assert.Len(t, forecasts, 1)
+	forecasts, err = repo.List(false)
+	assert.NoError(t, err)
+	assert.Len(t, forecasts, 1)
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"toko/internal/models"
+)
+
+// MockInventoryForecastRepository is an in-memory implementation of InventoryForecastRepository.
+type MockInventoryForecastRepository struct {
+	forecasts []models.InventoryForecast
+	mu        sync.RWMutex
+}
+
+// NewMockInventoryForecastRepository creates a new instance of MockInventoryForecastRepository.
+func NewMockInventoryForecastRepository() *MockInventoryForecastRepository {
+	return &MockInventoryForecastRepository{}
+}
+
+// List returns forecasts with the least stock coverage first.
+func (r *MockInventoryForecastRepository) List(lowStockOnly bool) ([]models.InventoryForecast, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var forecasts []models.InventoryForecast
+	for _, forecast := range r.forecasts {
+		if lowStockOnly && !forecast.LowStock {
+			continue
+		}
+		forecasts = append(forecasts, forecast)
+	}
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		a, b := forecasts[i].DaysRemaining, forecasts[j].DaysRemaining
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return *a < *b
+	})
+	return forecasts, nil
+}
+
+// ReplaceAll swaps the stored forecasts.
+func (r *MockInventoryForecastRepository) ReplaceAll(forecasts []models.InventoryForecast) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.forecasts = append([]models.InventoryForecast(nil), forecasts...)
+	return nil
+}
@@ -0,0 +1,11 @@
+package repositories
+
+import "toko/internal/models"
+
+// InventoryForecastRepository defines the interface for inventory forecast data access.
+type InventoryForecastRepository interface {
+	// List returns forecasts ordered by days of stock remaining, optionally only low-stock ones.
+	List(lowStockOnly bool) ([]models.InventoryForecast, error)
+	// ReplaceAll swaps the stored forecasts for a freshly computed set.
+	ReplaceAll(forecasts []models.InventoryForecast) error
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ipDenylistKey is the Redis hash holding denylist entries keyed by CIDR.
+const ipDenylistKey = "toko:ip_denylist"
+
+// RedisIPDenylistRepository is a Redis implementation of IPDenylistRepository.
+// Entries are shared by every API instance pointing at the same Redis.
+type RedisIPDenylistRepository struct {
+	client  *redis.Client
+	timeout time.Duration
+}
+
+// NewRedisIPDenylistRepository creates a new instance of RedisIPDenylistRepository.
+func NewRedisIPDenylistRepository(client *redis.Client) *RedisIPDenylistRepository {
+	return &RedisIPDenylistRepository{
+		client:  client,
+		timeout: 2 * time.Second,
+	}
+}
+
+// GetAll returns all unexpired denylist entries, pruning expired ones.
+func (r *RedisIPDenylistRepository) GetAll() ([]models.DeniedIP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	values, err := r.client.HGetAll(ctx, ipDenylistKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IP denylist: %w", err)
+	}
+
+	now := time.Now()
+	entries := make([]models.DeniedIP, 0, len(values))
+	for cidr, raw := range values {
+		var entry models.DeniedIP
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode denylist entry %s: %w", cidr, err)
+		}
+		if entry.IsExpired(now) {
+			r.client.HDel(ctx, ipDenylistKey, cidr)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Add stores or replaces a denylist entry.
+func (r *RedisIPDenylistRepository) Add(entry *models.DeniedIP) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode denylist entry: %w", err)
+	}
+	if err := r.client.HSet(ctx, ipDenylistKey, entry.CIDR, raw).Err(); err != nil {
+		return fmt.Errorf("failed to add %s to IP denylist: %w", entry.CIDR, err)
+	}
+	return nil
+}
+
+// Remove deletes a denylist entry.
+func (r *RedisIPDenylistRepository) Remove(cidr string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	removed, err := r.client.HDel(ctx, ipDenylistKey, cidr).Result()
+	if err != nil {
+		return fmt.Errorf("failed to remove %s from IP denylist: %w", cidr, err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("denylist entry %s not found", cidr)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockIPDenylistRepository is an in-memory implementation of IPDenylistRepository.
+type MockIPDenylistRepository struct {
+	entries map[string]models.DeniedIP
+	mu      sync.RWMutex
+}
+
+// NewMockIPDenylistRepository creates a new instance of MockIPDenylistRepository.
+func NewMockIPDenylistRepository() *MockIPDenylistRepository {
+	return &MockIPDenylistRepository{
+		entries: make(map[string]models.DeniedIP),
+	}
+}
+
+// GetAll returns all unexpired denylist entries.
+func (r *MockIPDenylistRepository) GetAll() ([]models.DeniedIP, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	entries := make([]models.DeniedIP, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if !entry.IsExpired(now) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Add stores or replaces a denylist entry.
+func (r *MockIPDenylistRepository) Add(entry *models.DeniedIP) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[entry.CIDR] = *entry
+	return nil
+}
+
+// Remove deletes a denylist entry.
+func (r *MockIPDenylistRepository) Remove(cidr string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[cidr]; !ok {
+		return fmt.Errorf("denylist entry %s not found", cidr)
+	}
+	delete(r.entries, cidr)
+	return nil
+}
@@ -0,0 +1,10 @@
+package repositories
+
+import "toko/internal/models"
+
+// IPDenylistRepository defines the interface for denylisted IP storage.
+type IPDenylistRepository interface {
+	GetAll() ([]models.DeniedIP, error)
+	Add(entry *models.DeniedIP) error
+	Remove(cidr string) error
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMLicenseKeyRepository is a GORM implementation of LicenseKeyRepository.
+type GORMLicenseKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMLicenseKeyRepository creates a new instance of GORMLicenseKeyRepository.
+func NewGORMLicenseKeyRepository(db *gorm.DB) *GORMLicenseKeyRepository {
+	return &GORMLicenseKeyRepository{
+		db: db,
+	}
+}
+
+// AddToPool inserts unassigned keys, ignoring keys the product already has.
+func (r *GORMLicenseKeyRepository) AddToPool(productID string, keys []string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	rows := make([]models.LicenseKey, 0, len(keys))
+	for _, key := range keys {
+		rows = append(rows, models.LicenseKey{ProductID: productID, Key: key})
+	}
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500)
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to add license keys for product %s: %w", productID, res.Error)
+	}
+	return int(res.RowsAffected), nil
+}
+
+// Create saves a key.
+func (r *GORMLicenseKeyRepository) Create(key *models.LicenseKey) error {
+	if err := r.db.Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create license key for product %s: %w", key.ProductID, err)
+	}
+	return nil
+}
+
+// Claim assigns unassigned keys to the order. The update re-checks that each
+// key is still unassigned, so a key taken by a concurrent claim is skipped.
+func (r *GORMLicenseKeyRepository) Claim(productID, orderID string, count int) ([]models.LicenseKey, error) {
+	if count <= 0 {
+		return []models.LicenseKey{}, nil
+	}
+	var ids []uint
+	if err := r.db.Model(&models.LicenseKey{}).
+		Where("product_id = ? AND order_id = ''", productID).
+		Order("id ASC").Limit(count).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find license keys for product %s: %w", productID, err)
+	}
+	if len(ids) == 0 {
+		return []models.LicenseKey{}, nil
+	}
+
+	claimedAt := time.Now()
+	if err := r.db.Model(&models.LicenseKey{}).
+		Where("id IN ? AND order_id = ''", ids).
+		Updates(map[string]interface{}{"order_id": orderID, "assigned_at": claimedAt}).Error; err != nil {
+		return nil, fmt.Errorf("failed to assign license keys to order %s: %w", orderID, err)
+	}
+
+	var keys []models.LicenseKey
+	if err := r.db.Where("id IN ? AND order_id = ?", ids, orderID).Order("id ASC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to get license keys of order %s: %w", orderID, err)
+	}
+	return keys, nil
+}
+
+// ListByOrder retrieves the keys assigned to an order.
+func (r *GORMLicenseKeyRepository) ListByOrder(orderID string) ([]models.LicenseKey, error) {
+	var keys []models.LicenseKey
+	if err := r.db.Where("order_id = ?", orderID).Order("id ASC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to get license keys of order %s: %w", orderID, err)
+	}
+	return keys, nil
+}
+
+// CountAvailable counts the unassigned keys of a product.
+func (r *GORMLicenseKeyRepository) CountAvailable(productID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.LicenseKey{}).Where("product_id = ? AND order_id = ''", productID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count license keys for product %s: %w", productID, err)
+	}
+	return count, nil
+}
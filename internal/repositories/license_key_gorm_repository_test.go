@@ -0,0 +1,47 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMLicenseKeyRepository_PoolClaims(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.LicenseKey{}))
+	repo := repositories.NewGORMLicenseKeyRepository(db)
+
+	added, err := repo.AddToPool("product-1", []string{"AAA", "BBB", "CCC"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, added)
+	// Keys the product already has are skipped; other products may reuse them
+	added, err = repo.AddToPool("product-1", []string{"BBB", "DDD"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	added, err = repo.AddToPool("product-2", []string{"AAA"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	claimed, err := repo.Claim("product-1", "order-1", 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "AAA", claimed[0].Key)
+	assert.Equal(t, "BBB", claimed[1].Key)
+	assert.NotNil(t, claimed[0].AssignedAt)
+
+	// A short pool hands out what it has
+	claimed, err = repo.Claim("product-1", "order-2", 5)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	available, err := repo.CountAvailable("product-1")
+	require.NoError(t, err)
+	assert.Zero(t, available)
+
+	keys, err := repo.ListByOrder("order-1")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
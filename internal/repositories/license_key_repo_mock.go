@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockLicenseKeyRepository is an in-memory implementation of LicenseKeyRepository.
+type MockLicenseKeyRepository struct {
+	keys   map[uint]models.LicenseKey
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockLicenseKeyRepository creates a new instance of MockLicenseKeyRepository.
+func NewMockLicenseKeyRepository() *MockLicenseKeyRepository {
+	return &MockLicenseKeyRepository{
+		keys: make(map[uint]models.LicenseKey),
+	}
+}
+
+// AddToPool adds unassigned keys, skipping keys the product already has.
+func (r *MockLicenseKeyRepository) AddToPool(productID string, keys []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := make(map[string]bool)
+	for _, key := range r.keys {
+		if key.ProductID == productID {
+			existing[key.Key] = true
+		}
+	}
+	added := 0
+	for _, key := range keys {
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		r.nextID++
+		r.keys[r.nextID] = models.LicenseKey{ID: r.nextID, ProductID: productID, Key: key, CreatedAt: time.Now()}
+		added++
+	}
+	return added, nil
+}
+
+// Create saves a key.
+func (r *MockLicenseKeyRepository) Create(key *models.LicenseKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	key.ID = r.nextID
+	key.CreatedAt = time.Now()
+	r.keys[key.ID] = *key
+	return nil
+}
+
+// Claim assigns up to count unassigned keys of a product to an order.
+func (r *MockLicenseKeyRepository) Claim(productID, orderID string, count int) ([]models.LicenseKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimedAt := time.Now()
+	claimed := []models.LicenseKey{}
+	for _, key := range r.sorted() {
+		if len(claimed) >= count {
+			break
+		}
+		if key.ProductID != productID || key.OrderID != "" {
+			continue
+		}
+		key.OrderID = orderID
+		key.AssignedAt = &claimedAt
+		r.keys[key.ID] = key
+		claimed = append(claimed, key)
+	}
+	return claimed, nil
+}
+
+// ListByOrder returns the keys assigned to an order, oldest first.
+func (r *MockLicenseKeyRepository) ListByOrder(orderID string) ([]models.LicenseKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []models.LicenseKey{}
+	for _, key := range r.sorted() {
+		if key.OrderID == orderID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// CountAvailable returns the number of unassigned keys of a product.
+func (r *MockLicenseKeyRepository) CountAvailable(productID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, key := range r.keys {
+		if key.ProductID == productID && key.OrderID == "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// sorted returns every key in ID order. The caller must hold the lock.
+func (r *MockLicenseKeyRepository) sorted() []models.LicenseKey {
+	keys := make([]models.LicenseKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
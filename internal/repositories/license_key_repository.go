@@ -0,0 +1,20 @@
+package repositories
+
+import "toko/internal/models"
+
+// LicenseKeyRepository defines the interface for license key data access.
+type LicenseKeyRepository interface {
+	// AddToPool adds unassigned keys for a product, skipping keys it already
+	// has, and returns the number added.
+	AddToPool(productID string, keys []string) (int, error)
+	// Create saves a key, e.g. one generated for an order.
+	Create(key *models.LicenseKey) error
+	// Claim assigns up to count unassigned keys of a product to an order,
+	// oldest first, and returns the keys it got. Concurrent claims never
+	// receive the same key, so fewer than count may be returned.
+	Claim(productID, orderID string, count int) ([]models.LicenseKey, error)
+	// ListByOrder returns the keys assigned to an order, oldest first.
+	ListByOrder(orderID string) ([]models.LicenseKey, error)
+	// CountAvailable returns the number of unassigned keys of a product.
+	CountAvailable(productID string) (int64, error)
+}
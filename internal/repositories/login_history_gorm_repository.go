@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMLoginHistoryRepository is a GORM implementation of LoginHistoryRepository.
+type GORMLoginHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMLoginHistoryRepository creates a new instance of GORMLoginHistoryRepository.
+func NewGORMLoginHistoryRepository(db *gorm.DB) *GORMLoginHistoryRepository {
+	return &GORMLoginHistoryRepository{
+		db: db,
+	}
+}
+
+// Create saves a login.
+func (r *GORMLoginHistoryRepository) Create(event *models.LoginEvent) error {
+	if err := r.db.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record login of user %s: %w", event.UserID, err)
+	}
+	return nil
+}
+
+// ListByUser lists the latest logins of a user.
+func (r *GORMLoginHistoryRepository) ListByUser(userID string, limit int) ([]models.LoginEvent, error) {
+	var events []models.LoginEvent
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list logins of user %s: %w", userID, err)
+	}
+	return events, nil
+}
+
+// DeleteByUser removes the login history of a user.
+func (r *GORMLoginHistoryRepository) DeleteByUser(userID string) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&models.LoginEvent{}).Error; err != nil {
+		return fmt.Errorf("failed to delete logins of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteBefore removes the logins made before the time.
+func (r *GORMLoginHistoryRepository) DeleteBefore(before time.Time) (int64, error) {
+	res := r.db.Where("created_at < ?", before).Delete(&models.LoginEvent{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to delete old logins: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,45 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMLoginHistoryRepository(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.LoginEvent{}))
+	repo := repositories.NewGORMLoginHistoryRepository(db)
+	now := time.Now()
+
+	for _, event := range []*models.LoginEvent{
+		{UserID: "user-1", IPAddress: "10.0.0.1", CreatedAt: now.Add(-100 * 24 * time.Hour)},
+		{UserID: "user-1", IPAddress: "10.0.0.2", CreatedAt: now.Add(-time.Hour)},
+		{UserID: "user-1", IPAddress: "10.0.0.3", CreatedAt: now},
+		{UserID: "user-2", IPAddress: "10.0.0.4", CreatedAt: now},
+	} {
+		require.NoError(t, repo.Create(event))
+	}
+
+	logins, err := repo.ListByUser("user-1", 2)
+	require.NoError(t, err)
+	require.Len(t, logins, 2)
+	assert.Equal(t, "10.0.0.3", logins[0].IPAddress, "newest first")
+
+	deleted, err := repo.DeleteBefore(now.Add(-90 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	require.NoError(t, repo.DeleteByUser("user-1"))
+	logins, err = repo.ListByUser("user-1", 10)
+	require.NoError(t, err)
+	assert.Empty(t, logins)
+	logins, err = repo.ListByUser("user-2", 10)
+	require.NoError(t, err)
+	assert.Len(t, logins, 1)
+}
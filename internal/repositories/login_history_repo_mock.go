@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockLoginHistoryRepository is an in-memory implementation of LoginHistoryRepository.
+type MockLoginHistoryRepository struct {
+	events []models.LoginEvent
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockLoginHistoryRepository creates a new instance of MockLoginHistoryRepository.
+func NewMockLoginHistoryRepository() *MockLoginHistoryRepository {
+	return &MockLoginHistoryRepository{
+		nextID: 1,
+	}
+}
+
+// Create saves a login.
+func (r *MockLoginHistoryRepository) Create(event *models.LoginEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = r.nextID
+	r.nextID++
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// ListByUser lists the latest logins of a user.
+func (r *MockLoginHistoryRepository) ListByUser(userID string, limit int) ([]models.LoginEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []models.LoginEvent
+	for _, event := range r.events {
+		if event.UserID == userID {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].ID > events[j].ID
+		}
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// DeleteByUser removes the login history of a user.
+func (r *MockLoginHistoryRepository) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.events[:0]
+	for _, event := range r.events {
+		if event.UserID != userID {
+			kept = append(kept, event)
+		}
+	}
+	r.events = kept
+	return nil
+}
+
+// DeleteBefore removes the logins made before the time.
+func (r *MockLoginHistoryRepository) DeleteBefore(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	kept := r.events[:0]
+	for _, event := range r.events {
+		if event.CreatedAt.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	r.events = kept
+	return deleted, nil
+}
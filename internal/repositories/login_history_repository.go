@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// LoginHistoryRepository defines the interface for login history data access.
+type LoginHistoryRepository interface {
+	Create(event *models.LoginEvent) error
+	// ListByUser returns the latest logins of the user, newest first.
+	ListByUser(userID string, limit int) ([]models.LoginEvent, error)
+	// DeleteByUser removes the whole login history of the user.
+	DeleteByUser(userID string) error
+	// DeleteBefore removes logins made before the time and returns how many
+	// were removed.
+	DeleteBefore(before time.Time) (int64, error)
+}
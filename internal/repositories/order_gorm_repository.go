@@ -0,0 +1,341 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMOrderRepository is a GORM implementation of OrderRepository.
+// Order items are stored in their own table with foreign keys to orders and products.
+type GORMOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMOrderRepository creates a new instance of GORMOrderRepository.
+func NewGORMOrderRepository(db *gorm.DB) *GORMOrderRepository {
+	return &GORMOrderRepository{
+		db: db,
+	}
+}
+
+// GetAll retrieves all orders with their items, newest first.
+func (r *GORMOrderRepository) GetAll() ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.db.Preload("Items").Order("created_at DESC").Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to get all orders: %w", err)
+	}
+	return orders, nil
+}
+
+// List retrieves a filtered page of orders with their items, newest first.
+func (r *GORMOrderRepository) List(opts OrderListOptions) ([]models.Order, int64, error) {
+	query := r.db.Model(&models.Order{})
+	if opts.UserID != "" {
+		query = query.Where("user_id = ?", opts.UserID)
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+	if !opts.From.IsZero() {
+		query = query.Where("created_at >= ?", opts.From)
+	}
+	if !opts.To.IsZero() {
+		query = query.Where("created_at < ?", opts.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	var orders []models.Order
+	// Order by ID among equal timestamps so consecutive pages never overlap
+	query = query.Preload("Items").Order("created_at DESC, id DESC").Offset(opts.Offset)
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if err := query.Find(&orders).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return orders, total, nil
+}
+
+// ListStale retrieves the oldest orders in one of statuses created before cutoff.
+func (r *GORMOrderRepository) ListStale(statuses []string, cutoff time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Preload("Items").Where("status IN ? AND created_at < ?", statuses, cutoff).Order("created_at, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to list stale orders: %w", err)
+	}
+	return orders, nil
+}
+
+// ListDuePreorders retrieves paid preorders whose release date has come.
+func (r *GORMOrderRepository) ListDuePreorders(now time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Preload("Items").Where("status = ? AND release_at <= ?", "preorder", now).Order("release_at, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to list due preorders: %w", err)
+	}
+	return orders, nil
+}
+
+// GetByID retrieves a single order with its items.
+func (r *GORMOrderRepository) GetByID(id string) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.Preload("Items").First(&order, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("order with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get order by ID %s: %w", id, err)
+	}
+	return &order, nil
+}
+
+// GetByExternalRef returns the newest order with the given external reference.
+func (r *GORMOrderRepository) GetByExternalRef(ref string) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.Preload("Items").Where("external_ref = ?", ref).Order("created_at DESC").First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("order with external reference %s not found", ref)
+		}
+		return nil, fmt.Errorf("failed to get order by external reference %s: %w", ref, err)
+	}
+	return &order, nil
+}
+
+// Create inserts an order and its items in a single transaction.
+func (r *GORMOrderRepository) Create(order *models.Order) error {
+	if order.ID == "" {
+		order.ID = uuid.New().String()
+	}
+	order.RecordMilestones(time.Now())
+	if err := r.db.Create(order).Error; err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus updates the status of an order.
+func (r *GORMOrderRepository) UpdateStatus(id string, status string) error {
+	res := r.db.Model(&models.Order{}).Where("id = ?", id).Updates(statusUpdates(status, time.Now()))
+	if res.Error != nil {
+		return fmt.Errorf("failed to update order status: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("order with ID %s not found for status update", id)
+	}
+	return nil
+}
+
+// statusUpdates returns the columns to update when an order moves to status,
+// recording the milestones it reaches for the first time.
+func statusUpdates(status string, now time.Time) map[string]interface{} {
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": now,
+	}
+	for _, milestone := range models.ReachedMilestones(status) {
+		updates[milestone] = gorm.Expr("COALESCE("+milestone+", ?)", now)
+	}
+	return updates
+}
+
+// UpdateTracking sets the carrier and tracking number of an order.
+func (r *GORMOrderRepository) UpdateTracking(id, carrier, trackingNumber string) error {
+	res := r.db.Model(&models.Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"carrier":         carrier,
+		"tracking_number": trackingNumber,
+		"updated_at":      time.Now(),
+	})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update order tracking: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("order with ID %s not found for tracking update", id)
+	}
+	return nil
+}
+
+// UpdateReferences replaces the external reference and metadata of an order.
+func (r *GORMOrderRepository) UpdateReferences(id, externalRef string, metadata models.OrderMetadata) error {
+	res := r.db.Model(&models.Order{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"external_ref": externalRef,
+		"metadata":     metadata,
+		"updated_at":   time.Now(),
+	})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update order references: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("order with ID %s not found for reference update", id)
+	}
+	return nil
+}
+
+// TransitionStatus updates the status of an order that is in one of fromStatuses.
+func (r *GORMOrderRepository) TransitionStatus(id string, fromStatuses []string, status string) error {
+	res := r.db.Model(&models.Order{}).Where("id = ? AND status IN ?", id, fromStatuses).Updates(statusUpdates(status, time.Now()))
+	if res.Error != nil {
+		return fmt.Errorf("failed to update order status: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		if err := r.db.Model(&models.Order{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("order with ID %s not found", id)
+		}
+		return fmt.Errorf("order %s cannot move to %s from its current status", id, status)
+	}
+	return nil
+}
+
+// Cancel cancels the order and restores the stock held by its reservations in a single transaction.
+func (r *GORMOrderRepository) Cancel(id string, fromStatuses []string, reason, note string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The status condition makes concurrent cancellations and payments safe
+		now := time.Now()
+		res := tx.Model(&models.Order{}).Where("id = ? AND status IN ?", id, fromStatuses).Updates(map[string]interface{}{
+			"status":        "cancelled",
+			"cancel_reason": reason,
+			"cancel_note":   note,
+			"cancelled_at":  now,
+			"updated_at":    now,
+		})
+		if res.Error != nil {
+			return fmt.Errorf("failed to cancel order: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&models.Order{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to cancel order: %w", err)
+			}
+			if count == 0 {
+				return fmt.Errorf("order with ID %s not found", id)
+			}
+			return fmt.Errorf("order %s cannot be cancelled in its current status", id)
+		}
+
+		var reservations []models.StockReservation
+		if err := tx.Where("order_id = ? AND status IN ?", id, []string{models.ReservationStatusActive, models.ReservationStatusCommitted}).
+			Find(&reservations).Error; err != nil {
+			return fmt.Errorf("failed to load reservations for order %s: %w", id, err)
+		}
+		for _, reservation := range reservations {
+			if err := tx.Model(&models.StockReservation{}).Where("id = ?", reservation.ID).Updates(map[string]interface{}{
+				"status":     models.ReservationStatusReleased,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to release reservation %d: %w", reservation.ID, err)
+			}
+			if err := tx.Model(&models.Product{}).
+				Where("id = ?", reservation.ProductID).
+				Update("stock", gorm.Expr("stock + ?", reservation.Quantity)).Error; err != nil {
+				return fmt.Errorf("failed to restore stock for product %s: %w", reservation.ProductID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateItemStatuses sets the status of the order's items that are in one of fromStatuses.
+func (r *GORMOrderRepository) UpdateItemStatuses(orderID string, itemIDs []uint, fromStatuses []string, status, reason string) (int64, error) {
+	query := r.db.Model(&models.OrderItem{}).Where("order_id = ? AND status IN ?", orderID, fromStatuses)
+	if len(itemIDs) > 0 {
+		query = query.Where("id IN ?", itemIDs)
+	}
+	res := query.Updates(map[string]interface{}{"status": status, "cancel_reason": reason})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to update items of order %s: %w", orderID, res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// PurchasedUnits sums the user's ordered units of the product since the given time.
+func (r *GORMOrderRepository) PurchasedUnits(userID, productID string, since time.Time) (int, error) {
+	var units int
+	err := r.db.Table("order_items").
+		Select("COALESCE(SUM(order_items.quantity), 0)").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND order_items.product_id = ?", userID, productID).
+		Where("orders.status NOT IN ? AND order_items.status <> ?", unsoldOrderStatuses, models.ItemStatusCancelled).
+		Where("orders.created_at >= ?", since).
+		Row().Scan(&units)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purchased units: %w", err)
+	}
+	return units, nil
+}
+
+// Archive deletes archived orders, their items and return requests, and
+// stores the archive records in their place.
+func (r *GORMOrderRepository) Archive(archived []models.ArchivedOrder) error {
+	if len(archived) == 0 {
+		return nil
+	}
+	ids := make([]string, len(archived))
+	for i, record := range archived {
+		ids[i] = record.OrderID
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&archived).Error; err != nil {
+			return fmt.Errorf("failed to record archived orders: %w", err)
+		}
+		if err := tx.Where("order_id IN ?", ids).Delete(&models.ReturnRequest{}).Error; err != nil {
+			return fmt.Errorf("failed to delete return requests of archived orders: %w", err)
+		}
+		if err := tx.Where("order_id IN ?", ids).Delete(&models.OrderItem{}).Error; err != nil {
+			return fmt.Errorf("failed to delete items of archived orders: %w", err)
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&models.Order{}).Error; err != nil {
+			return fmt.Errorf("failed to delete archived orders: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetArchived retrieves the archive record of an order.
+func (r *GORMOrderRepository) GetArchived(id string) (*models.ArchivedOrder, error) {
+	var archived models.ArchivedOrder
+	if err := r.db.First(&archived, "order_id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("archived order with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get archived order: %w", err)
+	}
+	return &archived, nil
+}
+
+// AnonymizeByUser clears the personal data on a user's orders.
+func (r *GORMOrderRepository) AnonymizeByUser(userID string) (int64, error) {
+	updates := map[string]interface{}{
+		"company_name": "",
+		"tax_id":       "",
+		"gift_message": "",
+		"updated_at":   time.Now(),
+	}
+	for _, prefix := range []string{"shipping_", "billing_"} {
+		for _, column := range []string{"recipient_name", "phone", "line1", "line2"} {
+			updates[prefix+column] = ""
+		}
+		updates[prefix+"latitude"] = 0
+		updates[prefix+"longitude"] = 0
+	}
+	res := r.db.Model(&models.Order{}).Where("user_id = ?", userID).Updates(updates)
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to anonymize orders of user %s: %w", userID, res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,403 @@
+package repositories_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDB(t *testing.T) *gorm.DB {
+	// A named in-memory database per test keeps tests isolated across pooled connections
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	err = db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}, &models.Order{}, &models.OrderItem{}, &models.StockReservation{}, &models.InventoryForecast{})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestGORMOrderRepository_CreateAndReports(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	reportRepo := repositories.NewGORMReportRepository(db)
+
+	laptop := &models.Product{Name: "Laptop", Price: 1000, Stock: 5}
+	mouse := &models.Product{Name: "Mouse", Price: 20, Stock: 50}
+	assert.NoError(t, productRepo.Create(laptop))
+	assert.NoError(t, productRepo.Create(mouse))
+
+	order := &models.Order{
+		UserID: "user-1",
+		Items: []models.OrderItem{
+			{ProductID: laptop.ID, Quantity: 1, Price: 1000},
+			{ProductID: mouse.ID, Quantity: 3, Price: 20},
+		},
+		Subtotal:      1060,
+		DiscountTotal: 60,
+		TaxTotal:      110,
+		ShippingTotal: 15,
+		GrandTotal:    1125,
+		Status:        "pending",
+	}
+	assert.NoError(t, orderRepo.Create(order))
+	assert.NotEmpty(t, order.ID)
+
+	cancelled := &models.Order{
+		UserID:     "user-2",
+		Items:      []models.OrderItem{{ProductID: mouse.ID, Quantity: 10, Price: 20}},
+		GrandTotal: 200,
+		Status:     "cancelled",
+	}
+	assert.NoError(t, orderRepo.Create(cancelled))
+
+	unpaid := &models.Order{
+		UserID:     "user-3",
+		Items:      []models.OrderItem{{ProductID: laptop.ID, Quantity: 2, Price: 1000}},
+		GrandTotal: 2000,
+		Status:     "pending",
+	}
+	assert.NoError(t, orderRepo.Create(unpaid))
+
+	// Items are loaded back from their own table
+	fetched, err := orderRepo.GetByID(order.ID)
+	assert.NoError(t, err)
+	assert.Len(t, fetched.Items, 2)
+
+	assert.NoError(t, orderRepo.UpdateStatus(order.ID, "processing"))
+	err = orderRepo.UpdateStatus("missing", "processing")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	from := time.Now().Add(-time.Hour)
+	to := time.Now().Add(time.Hour)
+
+	top, err := reportRepo.TopProducts(from, to, 10)
+	assert.NoError(t, err)
+	assert.Len(t, top, 2)
+	assert.Equal(t, mouse.ID, top[0].ProductID) // Cancelled and unpaid orders do not count
+	assert.Equal(t, 3, top[0].Units)
+	assert.Equal(t, "Laptop", top[1].Name)
+
+	revenue, err := reportRepo.Revenue(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), revenue.OrderCount)
+	assert.Equal(t, 1125.0, revenue.Revenue)
+	assert.Equal(t, 60.0, revenue.DiscountTotal)
+	assert.Equal(t, 110.0, revenue.TaxTotal)
+
+	units, err := reportRepo.UnitsSold(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{laptop.ID: 1, mouse.ID: 3}, units)
+}
+
+func TestGORMOrderRepository_CancelRestoresStock(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	reservationRepo := repositories.NewGORMStockReservationRepository(db)
+
+	laptop := &models.Product{Name: "Laptop", Price: 1000, Stock: 5}
+	assert.NoError(t, productRepo.Create(laptop))
+
+	items := []models.OrderItem{{ProductID: laptop.ID, Quantity: 2, Price: 1000}}
+	order := &models.Order{ID: "order-1", UserID: "user-1", Items: items, Status: "pending"}
+	_, err := reservationRepo.Reserve(order.ID, items, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.NoError(t, orderRepo.Create(order))
+	_, err = reservationRepo.Commit(order.ID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, orderRepo.Cancel(order.ID, []string{"pending", "processing"}, "changed_mind", "Bought it elsewhere"))
+	product, _ := productRepo.GetByID(laptop.ID)
+	assert.Equal(t, 5, product.Stock)
+	cancelled, err := orderRepo.GetByID(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "changed_mind", cancelled.CancelReason)
+	assert.NotNil(t, cancelled.CancelledAt)
+
+	// A second cancellation must fail and leave stock untouched
+	err = orderRepo.Cancel(order.ID, []string{"pending", "processing"}, "other", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be cancelled")
+	product, _ = productRepo.GetByID(laptop.ID)
+	assert.Equal(t, 5, product.Stock)
+
+	err = orderRepo.Cancel("missing", []string{"pending"}, "other", "")
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestGORMOrderRepository_TransitionStatus(t *testing.T) {
+	orderRepo := repositories.NewGORMOrderRepository(setupDB(t))
+	assert.NoError(t, orderRepo.Create(&models.Order{ID: "order-1", UserID: "user-1", Status: "pending"}))
+
+	assert.NoError(t, orderRepo.TransitionStatus("order-1", []string{"pending"}, "payment_failed"))
+	order, _ := orderRepo.GetByID("order-1")
+	assert.Equal(t, "payment_failed", order.Status)
+
+	err := orderRepo.TransitionStatus("order-1", []string{"pending"}, "payment_failed")
+	assert.ErrorContains(t, err, "cannot move to payment_failed")
+	err = orderRepo.TransitionStatus("missing", []string{"pending"}, "payment_failed")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestGORMOrderRepository_ListStale(t *testing.T) {
+	db := setupDB(t)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	now := time.Now()
+	for _, order := range []models.Order{
+		{ID: "old-pending", Status: "pending", CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "old-failed", Status: "payment_failed", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "old-paid", Status: "processing", CreatedAt: now.Add(-4 * time.Hour)},
+		{ID: "new-pending", Status: "pending", CreatedAt: now},
+	} {
+		order := order
+		assert.NoError(t, db.Create(&order).Error)
+	}
+
+	orders, err := orderRepo.ListStale([]string{"pending", "payment_failed"}, now.Add(-time.Hour), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, "old-pending", orders[0].ID)
+		assert.Equal(t, "old-failed", orders[1].ID)
+	}
+
+	orders, err = orderRepo.ListStale([]string{"pending", "payment_failed"}, now.Add(-time.Hour), 1)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+}
+
+func TestGORMOrderRepository_List(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+
+	mouse := &models.Product{Name: "Mouse", Price: 20, Stock: 50}
+	assert.NoError(t, productRepo.Create(mouse))
+
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		status := "pending"
+		if i%2 == 0 {
+			status = "delivered"
+		}
+		assert.NoError(t, orderRepo.Create(&models.Order{
+			UserID:    fmt.Sprintf("user-%d", i%2),
+			Status:    status,
+			Items:     []models.OrderItem{{ProductID: mouse.ID, Quantity: 1, Price: 20}},
+			CreatedAt: base.AddDate(0, 0, i),
+		}))
+	}
+
+	orders, total, err := orderRepo.List(repositories.OrderListOptions{Limit: 2, Offset: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	assert.Len(t, orders, 2)
+	assert.True(t, orders[0].CreatedAt.After(orders[1].CreatedAt))
+	assert.Len(t, orders[0].Items, 1)
+
+	orders, total, err = orderRepo.List(repositories.OrderListOptions{UserID: "user-0", Status: "delivered"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, orders, 3)
+
+	orders, total, err = orderRepo.List(repositories.OrderListOptions{From: base.AddDate(0, 0, 1), To: base.AddDate(0, 0, 3)})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, orders, 2)
+}
+
+func TestGORMOrderRepository_UpdateItemStatuses(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 5}
+	desk := &models.Product{Name: "Desk", Price: 200, Stock: 5}
+	assert.NoError(t, productRepo.Create(lamp))
+	assert.NoError(t, productRepo.Create(desk))
+
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: "processing", Items: []models.OrderItem{
+		{ProductID: lamp.ID, Quantity: 1, Price: 40},
+		{ProductID: desk.ID, Quantity: 1, Price: 200, Status: models.ItemStatusProcessing},
+	}}
+	assert.NoError(t, orderRepo.Create(order))
+	stored, _ := orderRepo.GetByID(order.ID)
+	assert.Equal(t, models.ItemStatusPending, stored.Items[0].Status, "items default to pending")
+
+	updated, err := orderRepo.UpdateItemStatuses(order.ID, []uint{order.Items[1].ID}, []string{models.ItemStatusProcessing}, models.ItemStatusCancelled, "out_of_stock")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	// Items not in one of the from statuses are left alone
+	updated, err = orderRepo.UpdateItemStatuses(order.ID, nil, []string{models.ItemStatusPending, models.ItemStatusProcessing}, models.ItemStatusShipped, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	stored, _ = orderRepo.GetByID(order.ID)
+	statuses := map[string]string{}
+	for _, item := range stored.Items {
+		statuses[item.ProductID] = item.Status + "/" + item.CancelReason
+	}
+	assert.Equal(t, map[string]string{lamp.ID: "shipped/", desk.ID: "cancelled/out_of_stock"}, statuses)
+}
+
+func TestGORMOrderRepository_PurchasedUnits(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	sneaker := &models.Product{Name: "Sneaker", Price: 150, Stock: 10}
+	assert.NoError(t, productRepo.Create(sneaker))
+
+	now := time.Now()
+	for _, order := range []models.Order{
+		{ID: "recent", UserID: "user-1", Status: "processing", CreatedAt: now, Items: []models.OrderItem{{ProductID: sneaker.ID, Quantity: 2}, {ProductID: sneaker.ID, Quantity: 1, Status: models.ItemStatusCancelled}}},
+		{ID: "old", UserID: "user-1", Status: "delivered", CreatedAt: now.Add(-48 * time.Hour), Items: []models.OrderItem{{ProductID: sneaker.ID, Quantity: 3}}},
+		{ID: "cancelled", UserID: "user-1", Status: "cancelled", CreatedAt: now, Items: []models.OrderItem{{ProductID: sneaker.ID, Quantity: 5}}},
+		{ID: "other-user", UserID: "user-2", Status: "pending", CreatedAt: now, Items: []models.OrderItem{{ProductID: sneaker.ID, Quantity: 4}}},
+	} {
+		assert.NoError(t, orderRepo.Create(&order))
+	}
+
+	units, err := orderRepo.PurchasedUnits("user-1", sneaker.ID, now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, units)
+	units, err = orderRepo.PurchasedUnits("user-1", sneaker.ID, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, units)
+	units, err = orderRepo.PurchasedUnits("user-3", sneaker.ID, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, units)
+}
+
+func TestGORMOrderRepository_ListDuePreorders(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewGORMOrderRepository(db)
+
+	now := time.Now()
+	due, later := now.Add(-time.Hour), now.Add(time.Hour)
+	for _, order := range []*models.Order{
+		{UserID: "user-1", Status: "preorder", ReleaseAt: &due},
+		{UserID: "user-1", Status: "preorder", ReleaseAt: &later},
+		{UserID: "user-1", Status: "pending", ReleaseAt: &due},
+	} {
+		assert.NoError(t, repo.Create(order))
+	}
+
+	orders, err := repo.ListDuePreorders(now, 10)
+	assert.NoError(t, err)
+	if !assert.Len(t, orders, 1) {
+		return
+	}
+	assert.Equal(t, "preorder", orders[0].Status)
+	assert.True(t, orders[0].ReleaseAt.Before(now))
+}
+
+func TestGORMOrderRepository_References(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewGORMOrderRepository(db)
+
+	order := &models.Order{UserID: "user-1", Status: "pending", ExternalRef: "SHOP-1001", Metadata: models.OrderMetadata{"channel": "marketplace"}}
+	assert.NoError(t, repo.Create(order))
+
+	found, err := repo.GetByExternalRef("SHOP-1001")
+	assert.NoError(t, err)
+	assert.Equal(t, order.ID, found.ID)
+	assert.Equal(t, "marketplace", found.Metadata["channel"])
+
+	assert.NoError(t, repo.UpdateReferences(order.ID, "ERP-7", models.OrderMetadata{"erp_batch": float64(42)}))
+	_, err = repo.GetByExternalRef("SHOP-1001")
+	assert.ErrorContains(t, err, "not found")
+	found, err = repo.GetByExternalRef("ERP-7")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderMetadata{"erp_batch": float64(42)}, found.Metadata)
+
+	assert.ErrorContains(t, repo.UpdateReferences("missing", "", nil), "not found")
+}
+
+func TestGORMOrderRepository_Archive(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.ReturnRequest{}, &models.ArchivedOrder{}))
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 5}
+	assert.NoError(t, repositories.NewGORMProductRepository(db).Create(product))
+
+	old := &models.Order{UserID: "user-1", Status: "delivered", Items: []models.OrderItem{{ProductID: product.ID, Quantity: 1, Price: 40}}}
+	kept := &models.Order{UserID: "user-1", Status: "delivered", Items: []models.OrderItem{{ProductID: product.ID, Quantity: 1, Price: 40}}}
+	assert.NoError(t, orderRepo.Create(old))
+	assert.NoError(t, orderRepo.Create(kept))
+	assert.NoError(t, db.Create(&models.ReturnRequest{ID: "return-1", OrderID: old.ID, UserID: "user-1"}).Error)
+
+	assert.NoError(t, orderRepo.Archive([]models.ArchivedOrder{{OrderID: old.ID, UserID: "user-1", ArchiveKey: "archives/orders/a.jsonl.gz", ArchivedAt: time.Now()}}))
+
+	_, err := orderRepo.GetByID(old.ID)
+	assert.ErrorContains(t, err, "not found")
+	_, err = orderRepo.GetByID(kept.ID)
+	assert.NoError(t, err)
+	var items, returns int64
+	db.Model(&models.OrderItem{}).Count(&items)
+	db.Model(&models.ReturnRequest{}).Count(&returns)
+	assert.Equal(t, int64(1), items)
+	assert.Zero(t, returns)
+
+	archived, err := orderRepo.GetArchived(old.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "archives/orders/a.jsonl.gz", archived.ArchiveKey)
+	_, err = orderRepo.GetArchived(kept.ID)
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestGORMOrderRepository_RecordsMilestones(t *testing.T) {
+	db := setupDB(t)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	order := &models.Order{UserID: "user-1", Status: "pending"}
+	assert.NoError(t, orderRepo.Create(order))
+
+	assert.NoError(t, orderRepo.TransitionStatus(order.ID, []string{"pending"}, "processing"))
+	paid, err := orderRepo.GetByID(order.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, paid.PaidAt) {
+		assert.Nil(t, paid.ShippedAt)
+	}
+
+	assert.NoError(t, orderRepo.UpdateStatus(order.ID, "delivered"))
+	delivered, err := orderRepo.GetByID(order.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, delivered.PaidAt) && assert.NotNil(t, delivered.DeliveredAt) {
+		assert.True(t, delivered.PaidAt.Equal(*paid.PaidAt), "milestones keep the first time they were reached")
+		assert.NotNil(t, delivered.ShippedAt)
+	}
+}
+
+func TestGORMOrderRepository_AnonymizeByUser(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewGORMOrderRepository(db)
+
+	address := models.PostalAddress{RecipientName: "Budi", Phone: "0812", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "ID", Latitude: -6.2, Longitude: 106.8}
+	order := &models.Order{UserID: "user-1", Status: "delivered", ShippingAddress: address, BillingAddress: address, CompanyName: "PT Budi", TaxID: "01.234", GiftMessage: "For Ani"}
+	other := &models.Order{UserID: "user-2", Status: "delivered", ShippingAddress: address}
+	assert.NoError(t, repo.Create(order))
+	assert.NoError(t, repo.Create(other))
+
+	updated, err := repo.AnonymizeByUser("user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	found, err := repo.GetByID(order.ID)
+	assert.NoError(t, err)
+	for _, a := range []models.PostalAddress{found.ShippingAddress, found.BillingAddress} {
+		assert.Equal(t, models.PostalAddress{City: "Jakarta", PostalCode: "10110", Country: "ID"}, a)
+	}
+	assert.Empty(t, found.CompanyName)
+	assert.Empty(t, found.TaxID)
+	assert.Empty(t, found.GiftMessage)
+
+	found, err = repo.GetByID(other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi", found.ShippingAddress.RecipientName, "other users' orders are untouched")
+}
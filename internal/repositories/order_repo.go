@@ -1,14 +1,63 @@
 package repositories
 
 import (
+	"time"
 	"toko/internal/models"
 )
 
+// OrderListOptions filters and paginates order listings. Zero values mean "no filter".
+type OrderListOptions struct {
+	UserID string
+	Status string
+	From   time.Time // Inclusive lower bound on CreatedAt
+	To     time.Time // Exclusive upper bound on CreatedAt
+	Limit  int
+	Offset int
+}
+
 // OrderRepository defines the interface for order data access.
 type OrderRepository interface {
 	GetAll() ([]models.Order, error)
+	// List returns one page of matching orders, newest first, and the total number of matches.
+	List(opts OrderListOptions) ([]models.Order, int64, error)
 	GetByID(id string) (*models.Order, error)
+	// GetByExternalRef returns the newest order stamped with an integration's reference.
+	GetByExternalRef(ref string) (*models.Order, error)
+	// ListStale returns up to limit orders in one of statuses that were
+	// created before cutoff, oldest first.
+	ListStale(statuses []string, cutoff time.Time, limit int) ([]models.Order, error)
+	// ListDuePreorders returns up to limit orders in the preorder status
+	// whose release date is not after now, earliest release first.
+	ListDuePreorders(now time.Time, limit int) ([]models.Order, error)
 	Create(order *models.Order) error
 	UpdateStatus(id string, status string) error
+	UpdateTracking(id, carrier, trackingNumber string) error
+	// UpdateReferences replaces the order's external reference and metadata.
+	UpdateReferences(id, externalRef string, metadata models.OrderMetadata) error
+	// TransitionStatus moves an order to status only if it is in one of
+	// fromStatuses, so concurrent updates cannot both win.
+	TransitionStatus(id string, fromStatuses []string, status string) error
+	// Cancel moves an order in one of the given statuses to "cancelled" and
+	// returns its reserved or sold stock, atomically. The reason and note
+	// are recorded with the cancellation time.
+	Cancel(id string, fromStatuses []string, reason, note string) error
+	// PurchasedUnits returns how many units of the product the user has
+	// ordered since the given time, leaving out cancelled and failed orders
+	// and cancelled items. A zero since counts every order.
+	PurchasedUnits(userID, productID string, since time.Time) (int, error)
+	// UpdateItemStatuses sets the status of the order's items that are in
+	// one of fromStatuses, all items if itemIDs is empty. The reason is kept
+	// for cancelled items. It returns the number of items updated.
+	UpdateItemStatuses(orderID string, itemIDs []uint, fromStatuses []string, status, reason string) (int64, error)
+	// Archive deletes the archived orders with their items and return
+	// requests and keeps the records of where they were archived, atomically.
+	Archive(archived []models.ArchivedOrder) error
+	// GetArchived returns the record of an archived order.
+	GetArchived(id string) (*models.ArchivedOrder, error)
+	// AnonymizeByUser clears the personal data on the user's orders: names,
+	// phone numbers, street addresses and business details. Cities, postal
+	// codes and countries stay for tax and sales reports. It returns the
+	// number of orders updated.
+	AnonymizeByUser(userID string) (int64, error)
 	// Delete(id string) error // Deletion of orders might be complex, so we'll omit for now.
 }
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 	"toko/internal/models"
@@ -11,14 +12,17 @@ import (
 
 // MockOrderRepository is an in-memory implementation of OrderRepository.
 type MockOrderRepository struct {
-	orders map[string]models.Order
-	mu     sync.RWMutex
+	orders     map[string]models.Order
+	archived   map[string]models.ArchivedOrder
+	nextItemID uint
+	mu         sync.RWMutex
 }
 
 // NewMockOrderRepository creates a new instance of MockOrderRepository.
 func NewMockOrderRepository() *MockOrderRepository {
 	return &MockOrderRepository{
-		orders: make(map[string]models.Order),
+		orders:   make(map[string]models.Order),
+		archived: make(map[string]models.ArchivedOrder),
 	}
 }
 
@@ -34,6 +38,97 @@ func (r *MockOrderRepository) GetAll() ([]models.Order, error) {
 	return orderList, nil
 }
 
+// List returns a filtered page of orders, newest first.
+func (r *MockOrderRepository) List(opts OrderListOptions) ([]models.Order, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []models.Order
+	for _, order := range r.orders {
+		if opts.UserID != "" && order.UserID != opts.UserID {
+			continue
+		}
+		if opts.Status != "" && order.Status != opts.Status {
+			continue
+		}
+		if !opts.From.IsZero() && order.CreatedAt.Before(opts.From) {
+			continue
+		}
+		if !opts.To.IsZero() && !order.CreatedAt.Before(opts.To) {
+			continue
+		}
+		matches = append(matches, order)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].ID > matches[j].ID
+		}
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	total := int64(len(matches))
+	if opts.Offset >= len(matches) {
+		return []models.Order{}, total, nil
+	}
+	matches = matches[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(matches) {
+		matches = matches[:opts.Limit]
+	}
+	return matches, total, nil
+}
+
+// ListStale returns the oldest orders in one of statuses created before cutoff.
+func (r *MockOrderRepository) ListStale(statuses []string, cutoff time.Time, limit int) ([]models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []models.Order
+	for _, order := range r.orders {
+		if !order.CreatedAt.Before(cutoff) {
+			continue
+		}
+		for _, status := range statuses {
+			if order.Status == status {
+				matches = append(matches, order)
+				break
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].CreatedAt.Before(matches[j].CreatedAt)
+	})
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// ListDuePreorders returns preorders whose release date is not after now, earliest release first.
+func (r *MockOrderRepository) ListDuePreorders(now time.Time, limit int) ([]models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []models.Order
+	for _, order := range r.orders {
+		if order.Status == "preorder" && order.ReleaseAt != nil && !order.ReleaseAt.After(now) {
+			matches = append(matches, order)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].ReleaseAt.Equal(*matches[j].ReleaseAt) {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].ReleaseAt.Before(*matches[j].ReleaseAt)
+	})
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
 // GetByID returns an order by its ID.
 func (r *MockOrderRepository) GetByID(id string) (*models.Order, error) {
 	r.mu.RLock()
@@ -43,9 +138,30 @@ func (r *MockOrderRepository) GetByID(id string) (*models.Order, error) {
 	if !ok {
 		return nil, fmt.Errorf("order with ID %s not found", id)
 	}
+	order.Items = append([]models.OrderItem(nil), order.Items...)
 	return &order, nil
 }
 
+// GetByExternalRef returns the newest order with the given external reference.
+func (r *MockOrderRepository) GetByExternalRef(ref string) (*models.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *models.Order
+	for _, order := range r.orders {
+		if order.ExternalRef != ref || (found != nil && !order.CreatedAt.After(found.CreatedAt)) {
+			continue
+		}
+		order := order
+		order.Items = append([]models.OrderItem(nil), order.Items...)
+		found = &order
+	}
+	if found == nil {
+		return nil, fmt.Errorf("order with external reference %s not found", ref)
+	}
+	return found, nil
+}
+
 // Create adds a new order.
 func (r *MockOrderRepository) Create(order *models.Order) error {
 	r.mu.Lock()
@@ -56,7 +172,17 @@ func (r *MockOrderRepository) Create(order *models.Order) error {
 	}
 	order.CreatedAt = time.Now()
 	order.UpdatedAt = time.Now()
-	r.orders[order.ID] = *order
+	order.RecordMilestones(order.CreatedAt)
+	for i := range order.Items {
+		if order.Items[i].ID == 0 {
+			r.nextItemID++
+			order.Items[i].ID = r.nextItemID
+		}
+		order.Items[i].OrderID = order.ID
+	}
+	stored := *order
+	stored.Items = append([]models.OrderItem(nil), order.Items...)
+	r.orders[order.ID] = stored
 	return nil
 }
 
@@ -71,7 +197,194 @@ func (r *MockOrderRepository) UpdateStatus(id string, status string) error {
 	}
 	order.Status = status
 	order.UpdatedAt = time.Now()
+	order.RecordMilestones(order.UpdatedAt)
+	r.orders[id] = order
+	return nil
+}
+
+// UpdateTracking sets the carrier and tracking number of an order.
+func (r *MockOrderRepository) UpdateTracking(id, carrier, trackingNumber string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return fmt.Errorf("order with ID %s not found for tracking update", id)
+	}
+	order.Carrier = carrier
+	order.TrackingNumber = trackingNumber
+	order.UpdatedAt = time.Now()
+	r.orders[id] = order
+	return nil
+}
+
+// UpdateReferences replaces the external reference and metadata of an order.
+func (r *MockOrderRepository) UpdateReferences(id, externalRef string, metadata models.OrderMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return fmt.Errorf("order with ID %s not found for reference update", id)
+	}
+	order.ExternalRef = externalRef
+	order.Metadata = metadata
+	order.UpdatedAt = time.Now()
 	r.orders[id] = order
 	return nil
 }
 
+// TransitionStatus updates the status of an order that is in one of fromStatuses.
+func (r *MockOrderRepository) TransitionStatus(id string, fromStatuses []string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return fmt.Errorf("order with ID %s not found", id)
+	}
+	for _, from := range fromStatuses {
+		if order.Status == from {
+			order.Status = status
+			order.UpdatedAt = time.Now()
+			order.RecordMilestones(order.UpdatedAt)
+			r.orders[id] = order
+			return nil
+		}
+	}
+	return fmt.Errorf("order %s cannot move to %s from its current status", id, status)
+}
+
+// Cancel cancels an order if it is in one of the given statuses.
+// The mock does not track stock, so nothing is restored.
+func (r *MockOrderRepository) Cancel(id string, fromStatuses []string, reason, note string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return fmt.Errorf("order with ID %s not found", id)
+	}
+	for _, status := range fromStatuses {
+		if order.Status == status {
+			now := time.Now()
+			order.Status = "cancelled"
+			order.CancelReason = reason
+			order.CancelNote = note
+			order.CancelledAt = &now
+			order.UpdatedAt = now
+			r.orders[id] = order
+			return nil
+		}
+	}
+	return fmt.Errorf("order %s cannot be cancelled in its current status", id)
+}
+
+// UpdateItemStatuses sets the status of the order's items that are in one of fromStatuses.
+func (r *MockOrderRepository) UpdateItemStatuses(orderID string, itemIDs []uint, fromStatuses []string, status, reason string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[orderID]
+	if !ok {
+		return 0, fmt.Errorf("order with ID %s not found", orderID)
+	}
+	order.Items = append([]models.OrderItem(nil), order.Items...)
+	var updated int64
+	for i := range order.Items {
+		item := &order.Items[i]
+		if len(itemIDs) > 0 && !containsItemID(itemIDs, item.ID) {
+			continue
+		}
+		for _, from := range fromStatuses {
+			if item.ItemStatus() == from {
+				item.Status = status
+				item.CancelReason = reason
+				updated++
+				break
+			}
+		}
+	}
+	r.orders[orderID] = order
+	return updated, nil
+}
+
+func containsItemID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// PurchasedUnits sums the user's ordered units of the product since the given time.
+func (r *MockOrderRepository) PurchasedUnits(userID, productID string, since time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	units := 0
+	for _, order := range r.orders {
+		if order.UserID != userID || order.Status == "cancelled" || order.Status == "payment_failed" || order.CreatedAt.Before(since) {
+			continue
+		}
+		for _, item := range order.Items {
+			if item.ProductID == productID && item.ItemStatus() != models.ItemStatusCancelled {
+				units += item.Quantity
+			}
+		}
+	}
+	return units, nil
+}
+
+// Archive removes the archived orders and keeps their archive records.
+// The mock does not track return requests, so none are deleted.
+func (r *MockOrderRepository) Archive(archived []models.ArchivedOrder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range archived {
+		if _, ok := r.archived[record.OrderID]; ok {
+			return fmt.Errorf("order %s is already archived", record.OrderID)
+		}
+	}
+	for _, record := range archived {
+		delete(r.orders, record.OrderID)
+		r.archived[record.OrderID] = record
+	}
+	return nil
+}
+
+// GetArchived returns the archive record of an order.
+func (r *MockOrderRepository) GetArchived(id string) (*models.ArchivedOrder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	archived, ok := r.archived[id]
+	if !ok {
+		return nil, fmt.Errorf("archived order with ID %s not found", id)
+	}
+	return &archived, nil
+}
+
+// AnonymizeByUser clears the personal data on a user's orders.
+func (r *MockOrderRepository) AnonymizeByUser(userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var updated int64
+	for id, order := range r.orders {
+		if order.UserID != userID {
+			continue
+		}
+		for _, address := range []*models.PostalAddress{&order.ShippingAddress, &order.BillingAddress} {
+			address.RecipientName, address.Phone, address.Line1, address.Line2 = "", "", "", ""
+			address.Latitude, address.Longitude = 0, 0
+		}
+		order.CompanyName, order.TaxID, order.GiftMessage = "", "", ""
+		order.UpdatedAt = time.Now()
+		r.orders[id] = order
+		updated++
+	}
+	return updated, nil
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMOutboxRepository is a GORM implementation of OutboxRepository.
+type GORMOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMOutboxRepository creates a new instance of GORMOutboxRepository.
+func NewGORMOutboxRepository(db *gorm.DB) *GORMOutboxRepository {
+	return &GORMOutboxRepository{
+		db: db,
+	}
+}
+
+// Add stores an event.
+func (r *GORMOutboxRepository) Add(event *models.OutboxEvent) error {
+	if err := r.db.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to store %s event: %w", event.RoutingKey, err)
+	}
+	return nil
+}
+
+// Pending returns up to limit events, oldest first.
+func (r *GORMOutboxRepository) Pending(limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	if err := r.db.Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// Delete removes a published event.
+func (r *GORMOutboxRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.OutboxEvent{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete outbox event %d: %w", id, err)
+	}
+	return nil
+}
+
+// Count returns how many events wait to be published.
+func (r *GORMOutboxRepository) Count() (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.OutboxEvent{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count outbox events: %w", err)
+	}
+	return count, nil
+}
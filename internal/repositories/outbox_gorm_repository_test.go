@@ -0,0 +1,31 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMOutboxRepository_PendingOldestFirst(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.OutboxEvent{}))
+	repo := repositories.NewGORMOutboxRepository(db)
+
+	for _, key := range []string{"order.created", "order.status_updated", "order.cancelled"} {
+		assert.NoError(t, repo.Add(&models.OutboxEvent{Exchange: "order", RoutingKey: key, Body: []byte(`{}`)}))
+	}
+	events, err := repo.Pending(2)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "order.created", events[0].RoutingKey)
+		assert.Equal(t, "order.status_updated", events[1].RoutingKey)
+	}
+
+	assert.NoError(t, repo.Delete(events[0].ID))
+	count, err := repo.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockOutboxRepository is an in-memory implementation of OutboxRepository.
+type MockOutboxRepository struct {
+	events map[uint]models.OutboxEvent
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockOutboxRepository creates a new instance of MockOutboxRepository.
+func NewMockOutboxRepository() *MockOutboxRepository {
+	return &MockOutboxRepository{
+		events: make(map[uint]models.OutboxEvent),
+	}
+}
+
+// Add stores an event.
+func (r *MockOutboxRepository) Add(event *models.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	event.ID = r.nextID
+	event.CreatedAt = time.Now()
+	r.events[event.ID] = *event
+	return nil
+}
+
+// Pending returns up to limit events, oldest first.
+func (r *MockOutboxRepository) Pending(limit int) ([]models.OutboxEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]models.OutboxEvent, 0, len(r.events))
+	for _, event := range r.events {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// Delete removes a published event.
+func (r *MockOutboxRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.events, id)
+	return nil
+}
+
+// Count returns how many events wait to be published.
+func (r *MockOutboxRepository) Count() (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.events)), nil
+}
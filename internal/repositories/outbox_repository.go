@@ -0,0 +1,12 @@
+package repositories
+
+import "toko/internal/models"
+
+// OutboxRepository defines the interface for access to events waiting to be published.
+type OutboxRepository interface {
+	Add(event *models.OutboxEvent) error
+	// Pending returns up to limit events, oldest first.
+	Pending(limit int) ([]models.OutboxEvent, error)
+	Delete(id uint) error
+	Count() (int64, error)
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMPasswordResetRepository is a GORM implementation of PasswordResetRepository.
+type GORMPasswordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMPasswordResetRepository creates a new instance of GORMPasswordResetRepository.
+func NewGORMPasswordResetRepository(db *gorm.DB) *GORMPasswordResetRepository {
+	return &GORMPasswordResetRepository{
+		db: db,
+	}
+}
+
+// Create saves a password reset token.
+func (r *GORMPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create password reset token for user %s: %w", token.UserID, err)
+	}
+	return nil
+}
+
+// GetByHash retrieves a password reset token by the hash of its value.
+func (r *GORMPasswordResetRepository) GetByHash(hash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.First(&token, "token_hash = ?", hash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("password reset token not found")
+		}
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkUsed sets used_at only while it is still empty, so a token is used once.
+func (r *GORMPasswordResetRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	res := r.db.Model(&models.PasswordResetToken{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", at)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to mark password reset token %d used: %w", id, res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// InvalidateUser marks the unused tokens of the user as used.
+func (r *GORMPasswordResetRepository) InvalidateUser(userID string, at time.Time) error {
+	if err := r.db.Model(&models.PasswordResetToken{}).Where("user_id = ? AND used_at IS NULL", userID).Update("used_at", at).Error; err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes the tokens that expired before the time.
+func (r *GORMPasswordResetRepository) DeleteExpired(before time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", before).Delete(&models.PasswordResetToken{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired password reset tokens: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,47 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMPasswordResetRepository_SingleUse(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.PasswordResetToken{}))
+	repo := repositories.NewGORMPasswordResetRepository(db)
+	now := time.Now()
+
+	first := &models.PasswordResetToken{UserID: "user-1", TokenHash: "hash-1", ExpiresAt: now.Add(time.Hour)}
+	second := &models.PasswordResetToken{UserID: "user-1", TokenHash: "hash-2", ExpiresAt: now.Add(time.Hour)}
+	expired := &models.PasswordResetToken{UserID: "user-2", TokenHash: "hash-3", ExpiresAt: now.Add(-time.Minute)}
+	for _, token := range []*models.PasswordResetToken{first, second, expired} {
+		require.NoError(t, repo.Create(token))
+	}
+
+	used, err := repo.MarkUsed(first.ID, now)
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.MarkUsed(first.ID, now)
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	require.NoError(t, repo.InvalidateUser("user-1", now))
+	found, err := repo.GetByHash("hash-2")
+	require.NoError(t, err)
+	assert.NotNil(t, found.UsedAt)
+	found, err = repo.GetByHash("hash-3")
+	require.NoError(t, err)
+	assert.Nil(t, found.UsedAt, "other users' tokens are untouched")
+
+	deleted, err := repo.DeleteExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = repo.GetByHash("hash-3")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockPasswordResetRepository is an in-memory implementation of PasswordResetRepository.
+type MockPasswordResetRepository struct {
+	tokens map[uint]models.PasswordResetToken
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockPasswordResetRepository creates a new instance of MockPasswordResetRepository.
+func NewMockPasswordResetRepository() *MockPasswordResetRepository {
+	return &MockPasswordResetRepository{
+		tokens: make(map[uint]models.PasswordResetToken),
+	}
+}
+
+// Create saves a password reset token.
+func (r *MockPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+// GetByHash retrieves a password reset token by the hash of its value.
+func (r *MockPasswordResetRepository) GetByHash(hash string) (*models.PasswordResetToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, fmt.Errorf("password reset token not found")
+}
+
+// MarkUsed marks an unused token as used.
+func (r *MockPasswordResetRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &at
+	r.tokens[id] = token
+	return true, nil
+}
+
+// InvalidateUser marks the unused tokens of the user as used.
+func (r *MockPasswordResetRepository) InvalidateUser(userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes the tokens that expired before the time.
+func (r *MockPasswordResetRepository) DeleteExpired(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// PasswordResetRepository defines the interface for password reset token data access.
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	// GetByHash returns the token with the hash, used ones included.
+	GetByHash(hash string) (*models.PasswordResetToken, error)
+	// MarkUsed marks an unused token as used and reports whether it was
+	// unused. Of concurrent calls for the same token only one succeeds.
+	MarkUsed(id uint, at time.Time) (bool, error)
+	// InvalidateUser marks every unused token of the user as used.
+	InvalidateUser(userID string, at time.Time) error
+	// DeleteExpired removes tokens that expired before the time and returns
+	// how many were removed.
+	DeleteExpired(before time.Time) (int64, error)
+}
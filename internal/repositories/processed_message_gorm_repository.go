@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMProcessedMessageRepository is a GORM implementation of ProcessedMessageRepository.
+type GORMProcessedMessageRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMProcessedMessageRepository creates a new instance of GORMProcessedMessageRepository.
+func NewGORMProcessedMessageRepository(db *gorm.DB) *GORMProcessedMessageRepository {
+	return &GORMProcessedMessageRepository{
+		db: db,
+	}
+}
+
+// Begin inserts a processing claim, or takes over an expired one.
+func (r *GORMProcessedMessageRepository) Begin(key string, lease time.Duration) (string, error) {
+	now := time.Now()
+	claim := models.ProcessedMessage{Key: key, State: models.MessageStateProcessing, ExpiresAt: now.Add(lease)}
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&claim)
+	if res.Error != nil {
+		return "", fmt.Errorf("failed to claim message %s: %w", key, res.Error)
+	}
+	if res.RowsAffected == 1 {
+		return models.MessageStateNew, nil
+	}
+
+	// Another claim exists; take it over only if it has expired
+	res = r.db.Model(&models.ProcessedMessage{}).
+		Where("key = ? AND expires_at < ?", key, now).
+		Updates(map[string]interface{}{"state": models.MessageStateProcessing, "expires_at": now.Add(lease), "updated_at": now})
+	if res.Error != nil {
+		return "", fmt.Errorf("failed to claim message %s: %w", key, res.Error)
+	}
+	if res.RowsAffected == 1 {
+		return models.MessageStateNew, nil
+	}
+
+	var existing models.ProcessedMessage
+	if err := r.db.First(&existing, "key = ?", key).Error; err != nil {
+		return "", fmt.Errorf("failed to load claim for message %s: %w", key, err)
+	}
+	return existing.State, nil
+}
+
+// Complete marks the message as done.
+func (r *GORMProcessedMessageRepository) Complete(key string, ttl time.Duration) error {
+	now := time.Now()
+	err := r.db.Model(&models.ProcessedMessage{}).Where("key = ?", key).
+		Updates(map[string]interface{}{"state": models.MessageStateDone, "expires_at": now.Add(ttl), "updated_at": now}).Error
+	if err != nil {
+		return fmt.Errorf("failed to complete message %s: %w", key, err)
+	}
+	return nil
+}
+
+// Abandon deletes the claim.
+func (r *GORMProcessedMessageRepository) Abandon(key string) error {
+	if err := r.db.Delete(&models.ProcessedMessage{}, "key = ?", key).Error; err != nil {
+		return fmt.Errorf("failed to abandon message %s: %w", key, err)
+	}
+	return nil
+}
+
+// PurgeExpired deletes records whose retention has passed.
+func (r *GORMProcessedMessageRepository) PurgeExpired(now time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", now).Delete(&models.ProcessedMessage{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to purge processed messages: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
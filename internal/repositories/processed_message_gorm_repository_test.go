@@ -0,0 +1,41 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMProcessedMessageRepository_Claims(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.ProcessedMessage{}))
+	repo := repositories.NewGORMProcessedMessageRepository(db)
+
+	state, err := repo.Begin("worker:msg-1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MessageStateNew, state)
+
+	state, err = repo.Begin("worker:msg-1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MessageStateProcessing, state)
+
+	assert.NoError(t, repo.Complete("worker:msg-1", time.Hour))
+	state, err = repo.Begin("worker:msg-1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MessageStateDone, state)
+
+	// Expired claims can be taken over, e.g. after a worker crashed mid-message
+	_, err = repo.Begin("worker:msg-2", -time.Second)
+	assert.NoError(t, err)
+	state, err = repo.Begin("worker:msg-2", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MessageStateNew, state)
+
+	purged, err := repo.PurgeExpired(time.Now().Add(2 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+}
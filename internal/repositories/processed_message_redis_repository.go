@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// processedMessagePrefix namespaces dedupe keys in Redis.
+const processedMessagePrefix = "toko:processed_message:"
+
+// RedisProcessedMessageRepository is a Redis implementation of ProcessedMessageRepository.
+// Expiry is handled by Redis key TTLs.
+type RedisProcessedMessageRepository struct {
+	client  *redis.Client
+	timeout time.Duration
+}
+
+// NewRedisProcessedMessageRepository creates a new instance of RedisProcessedMessageRepository.
+func NewRedisProcessedMessageRepository(client *redis.Client) *RedisProcessedMessageRepository {
+	return &RedisProcessedMessageRepository{
+		client:  client,
+		timeout: 2 * time.Second,
+	}
+}
+
+// Begin claims the key with SET NX, or reports the existing claim's state.
+func (r *RedisProcessedMessageRepository) Begin(key string, lease time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	claimed, err := r.client.SetNX(ctx, processedMessagePrefix+key, models.MessageStateProcessing, lease).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to claim message %s: %w", key, err)
+	}
+	if claimed {
+		return models.MessageStateNew, nil
+	}
+	state, err := r.client.Get(ctx, processedMessagePrefix+key).Result()
+	if err == redis.Nil {
+		// The claim expired between the two calls; let the redelivery retry
+		return models.MessageStateProcessing, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load claim for message %s: %w", key, err)
+	}
+	return state, nil
+}
+
+// Complete marks the message as done.
+func (r *RedisProcessedMessageRepository) Complete(key string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.client.Set(ctx, processedMessagePrefix+key, models.MessageStateDone, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to complete message %s: %w", key, err)
+	}
+	return nil
+}
+
+// Abandon deletes the claim.
+func (r *RedisProcessedMessageRepository) Abandon(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.client.Del(ctx, processedMessagePrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to abandon message %s: %w", key, err)
+	}
+	return nil
+}
+
+// PurgeExpired is a no-op because Redis expires keys itself.
+func (r *RedisProcessedMessageRepository) PurgeExpired(now time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockProcessedMessageRepository is an in-memory implementation of ProcessedMessageRepository.
+type MockProcessedMessageRepository struct {
+	messages map[string]models.ProcessedMessage
+	mu       sync.Mutex
+}
+
+// NewMockProcessedMessageRepository creates a new instance of MockProcessedMessageRepository.
+func NewMockProcessedMessageRepository() *MockProcessedMessageRepository {
+	return &MockProcessedMessageRepository{
+		messages: make(map[string]models.ProcessedMessage),
+	}
+}
+
+// Begin claims the key unless an unexpired claim exists.
+func (r *MockProcessedMessageRepository) Begin(key string, lease time.Duration) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := r.messages[key]; ok && existing.ExpiresAt.After(now) {
+		return existing.State, nil
+	}
+	r.messages[key] = models.ProcessedMessage{Key: key, State: models.MessageStateProcessing, ExpiresAt: now.Add(lease), CreatedAt: now, UpdatedAt: now}
+	return models.MessageStateNew, nil
+}
+
+// Complete marks the message as done.
+func (r *MockProcessedMessageRepository) Complete(key string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message := r.messages[key]
+	message.Key = key
+	message.State = models.MessageStateDone
+	message.ExpiresAt = time.Now().Add(ttl)
+	message.UpdatedAt = time.Now()
+	r.messages[key] = message
+	return nil
+}
+
+// Abandon deletes the claim.
+func (r *MockProcessedMessageRepository) Abandon(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.messages, key)
+	return nil
+}
+
+// PurgeExpired deletes expired records.
+func (r *MockProcessedMessageRepository) PurgeExpired(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for key, message := range r.messages {
+		if message.ExpiresAt.Before(now) {
+			delete(r.messages, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
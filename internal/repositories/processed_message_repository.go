@@ -0,0 +1,17 @@
+package repositories
+
+import "time"
+
+// ProcessedMessageRepository defines the interface for the consumer dedupe store.
+type ProcessedMessageRepository interface {
+	// Begin claims key for processing for the duration of lease. It returns
+	// models.MessageStateNew when claimed, or the state of an unexpired
+	// existing claim (processing or done).
+	Begin(key string, lease time.Duration) (string, error)
+	// Complete marks key as done and keeps it for ttl.
+	Complete(key string, ttl time.Duration) error
+	// Abandon removes a claim so that a redelivery is processed again.
+	Abandon(key string) error
+	// PurgeExpired deletes expired records and returns how many were removed.
+	PurgeExpired(now time.Time) (int64, error)
+}
@@ -1,7 +1,10 @@
 package repositories
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 	"toko/internal/models"
 
 	"github.com/google/uuid"
@@ -29,6 +32,15 @@ func (r *GORMProductRepository) GetAll() ([]models.Product, error) {
 	return products, nil
 }
 
+// Count returns the number of products in the database.
+func (r *GORMProductRepository) Count() (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Product{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count products: %w", err)
+	}
+	return count, nil
+}
+
 // GetByID retrieves a single product by its ID from the database.
 func (r *GORMProductRepository) GetByID(id string) (*models.Product, error) {
 	var product models.Product
@@ -46,7 +58,13 @@ func (r *GORMProductRepository) Create(product *models.Product) error {
 	if product.ID == "" {
 		product.ID = uuid.New().String()
 	}
-	if err := r.db.Create(product).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		return recordCatalogChange(tx, models.CatalogChangeCreated, product.ID, product)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to create product: %w", err)
 	}
 	return nil
@@ -54,11 +72,22 @@ func (r *GORMProductRepository) Create(product *models.Product) error {
 
 // Update updates an existing product in the database.
 func (r *GORMProductRepository) Update(product *models.Product) error {
-	res := r.db.Save(product) // Save will update all fields, including zero values
-	if res.Error != nil {
-		return fmt.Errorf("failed to update product: %w", res.Error)
+	var rowsAffected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Save(product) // Save will update all fields, including zero values
+		if res.Error != nil {
+			return res.Error
+		}
+		rowsAffected = res.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		return recordCatalogChange(tx, models.CatalogChangeUpdated, product.ID, product)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
 	}
-	if res.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		// This case happens if the record doesn't exist.
 		// GORM's Save doesn't return ErrRecordNotFound if no rows affected
 		// for an update, so we check RowsAffected.
@@ -69,12 +98,86 @@ func (r *GORMProductRepository) Update(product *models.Product) error {
 
 // Delete deletes a product by its ID from the database.
 func (r *GORMProductRepository) Delete(id string) error {
-	res := r.db.Delete(&models.Product{}, "id = ?", id)
-	if res.Error != nil {
-		return fmt.Errorf("failed to delete product: %w", res.Error)
+	var rowsAffected int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.Product{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		rowsAffected = res.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
+		return recordCatalogChange(tx, models.CatalogChangeDeleted, id, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
 	}
-	if res.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		return fmt.Errorf("product with ID %s not found for deletion", id)
 	}
 	return nil
 }
+
+// ListArchiveCandidates retrieves active, out-of-stock products that have not sold since cutoff.
+// Products that never sold are measured from their creation time.
+func (r *GORMProductRepository) ListArchiveCandidates(cutoff time.Time) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.
+		Where("stock = 0").
+		Where("status IS NULL OR status = '' OR status = ?", models.ProductStatusActive).
+		Where("COALESCE(last_sold_at, created_at) < ?", cutoff).
+		Find(&products).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archive candidates: %w", err)
+	}
+	return products, nil
+}
+
+// UpdateLastSoldAt records the time of a product's most recent sale without touching other columns.
+func (r *GORMProductRepository) UpdateLastSoldAt(id string, soldAt time.Time) error {
+	if err := r.db.Model(&models.Product{}).Where("id = ?", id).Update("last_sold_at", soldAt).Error; err != nil {
+		return fmt.Errorf("failed to update last sale of product %s: %w", id, err)
+	}
+	return nil
+}
+
+// Search retrieves active products matching every term group, case-insensitively, by name.
+func (r *GORMProductRepository) Search(termGroups [][]string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	query := r.db.Where("status <> ?", models.ProductStatusArchived)
+	for _, alternatives := range termGroups {
+		group := r.db.Where("1 = 0")
+		for _, term := range alternatives {
+			pattern := "%" + strings.ToLower(term) + "%"
+			group = group.Or("LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(sku) LIKE ?", pattern, pattern, pattern)
+		}
+		query = query.Where(group)
+	}
+	if err := query.Order("name ASC").Limit(limit).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+	return products, nil
+}
+
+// ListChanges retrieves catalog changes after the cursor, oldest first.
+func (r *GORMProductRepository) ListChanges(cursor uint, until time.Time, limit int) ([]models.CatalogChange, error) {
+	var changes []models.CatalogChange
+	if err := r.db.Where("id > ? AND created_at < ?", cursor, until).Order("id ASC").Limit(limit).Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list catalog changes: %w", err)
+	}
+	return changes, nil
+}
+
+// recordCatalogChange adds an entry to the catalog change feed within tx.
+func recordCatalogChange(tx *gorm.DB, changeType, productID string, product *models.Product) error {
+	change := models.CatalogChange{ProductID: productID, Type: changeType}
+	if product != nil {
+		snapshot, err := json.Marshal(product)
+		if err != nil {
+			return err
+		}
+		change.Product = snapshot
+	}
+	return tx.Create(&change).Error
+}
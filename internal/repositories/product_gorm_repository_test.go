@@ -0,0 +1,47 @@
+package repositories_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMProductRepository_RecordsCatalogChanges(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewGORMProductRepository(db)
+
+	mug := &models.Product{Name: "Mug", Price: 8, Stock: 10}
+	require.NoError(t, repo.Create(mug))
+	mug.Price = 9
+	require.NoError(t, repo.Update(mug))
+	require.NoError(t, repo.Delete(mug.ID))
+	// Failed changes leave no trace in the feed
+	assert.Error(t, repo.Delete(mug.ID))
+
+	changes, err := repo.ListChanges(0, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, models.CatalogChangeCreated, changes[0].Type)
+	assert.Equal(t, models.CatalogChangeUpdated, changes[1].Type)
+	assert.Equal(t, models.CatalogChangeDeleted, changes[2].Type)
+	assert.Empty(t, changes[2].Product)
+
+	var snapshot models.Product
+	require.NoError(t, json.Unmarshal(changes[1].Product, &snapshot))
+	assert.Equal(t, 9.0, snapshot.Price)
+
+	changes, err = repo.ListChanges(changes[0].ID, time.Now().Add(time.Minute), 1)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, models.CatalogChangeUpdated, changes[0].Type)
+
+	changes, err = repo.ListChanges(0, time.Now().Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
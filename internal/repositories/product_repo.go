@@ -1,14 +1,28 @@
 package repositories
 
 import (
+	"time"
 	"toko/internal/models"
 )
 
 // ProductRepository defines the interface for product data access.
 type ProductRepository interface {
 	GetAll() ([]models.Product, error)
+	// Count returns the number of products, archived ones included.
+	Count() (int64, error)
 	GetByID(id string) (*models.Product, error)
 	Create(product *models.Product) error
 	Update(product *models.Product) error
 	Delete(id string) error
+	// ListArchiveCandidates returns active, out-of-stock products with no sales since cutoff.
+	ListArchiveCandidates(cutoff time.Time) ([]models.Product, error)
+	// UpdateLastSoldAt records the time of a product's most recent sale.
+	UpdateLastSoldAt(id string, soldAt time.Time) error
+	// Search returns active products whose name, description or SKU contain
+	// at least one alternative of every term group.
+	Search(termGroups [][]string, limit int) ([]models.Product, error)
+	// ListChanges returns up to limit catalog changes after the cursor that
+	// were recorded before until, oldest first. Create, Update and Delete
+	// record a change each.
+	ListChanges(cursor uint, until time.Time, limit int) ([]models.CatalogChange, error)
 }
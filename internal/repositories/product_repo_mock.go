@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 	"toko/internal/models"
 
 	"github.com/google/uuid"
@@ -11,6 +15,7 @@ import (
 // MockProductRepository is an in-memory implementation of ProductRepository.
 type MockProductRepository struct {
 	products map[string]models.Product
+	changes  []models.CatalogChange
 	mu       sync.RWMutex
 }
 
@@ -33,6 +38,13 @@ func (r *MockProductRepository) GetAll() ([]models.Product, error) {
 	return productList, nil
 }
 
+// Count returns the number of products.
+func (r *MockProductRepository) Count() (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.products)), nil
+}
+
 // GetByID returns a product by its ID.
 func (r *MockProductRepository) GetByID(id string) (*models.Product, error) {
 	r.mu.RLock()
@@ -54,6 +66,7 @@ func (r *MockProductRepository) Create(product *models.Product) error {
 		product.ID = uuid.New().String()
 	}
 	r.products[product.ID] = *product
+	r.recordChange(models.CatalogChangeCreated, product.ID, product)
 	return nil
 }
 
@@ -67,6 +80,7 @@ func (r *MockProductRepository) Update(product *models.Product) error {
 		return fmt.Errorf("product with ID %s not found for update", product.ID)
 	}
 	r.products[product.ID] = *product
+	r.recordChange(models.CatalogChangeUpdated, product.ID, product)
 	return nil
 }
 
@@ -80,5 +94,103 @@ func (r *MockProductRepository) Delete(id string) error {
 		return fmt.Errorf("product with ID %s not found for deletion", id)
 	}
 	delete(r.products, id)
+	r.recordChange(models.CatalogChangeDeleted, id, nil)
 	return nil
 }
+
+// ListArchiveCandidates returns active, out-of-stock products with no sales since cutoff.
+func (r *MockProductRepository) ListArchiveCandidates(cutoff time.Time) ([]models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var candidates []models.Product
+	for _, p := range r.products {
+		if p.Stock != 0 || p.IsArchived() {
+			continue
+		}
+		lastActivity := p.CreatedAt
+		if p.LastSoldAt != nil {
+			lastActivity = *p.LastSoldAt
+		}
+		if lastActivity.Before(cutoff) {
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates, nil
+}
+
+// UpdateLastSoldAt records the time of a product's most recent sale.
+func (r *MockProductRepository) UpdateLastSoldAt(id string, soldAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return fmt.Errorf("product with ID %s not found for update", id)
+	}
+	product.LastSoldAt = &soldAt
+	r.products[id] = product
+	return nil
+}
+
+// Search returns active products matching every term group, case-insensitively, by name.
+func (r *MockProductRepository) Search(termGroups [][]string, limit int) ([]models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []models.Product
+	for _, p := range r.products {
+		if p.IsArchived() {
+			continue
+		}
+		text := strings.ToLower(p.Name + " " + p.Description + " " + p.SKU)
+		matches := true
+		for _, alternatives := range termGroups {
+			groupMatches := false
+			for _, term := range alternatives {
+				if strings.Contains(text, strings.ToLower(term)) {
+					groupMatches = true
+					break
+				}
+			}
+			if !groupMatches {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			products = append(products, p)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].Name < products[j].Name })
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return products, nil
+}
+
+// ListChanges returns catalog changes after the cursor, oldest first.
+func (r *MockProductRepository) ListChanges(cursor uint, until time.Time, limit int) ([]models.CatalogChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := []models.CatalogChange{}
+	for _, change := range r.changes {
+		if len(changes) >= limit {
+			break
+		}
+		if change.ID > cursor && change.CreatedAt.Before(until) {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// recordChange appends to the change feed. The caller must hold the lock.
+func (r *MockProductRepository) recordChange(changeType, productID string, product *models.Product) {
+	change := models.CatalogChange{ID: uint(len(r.changes) + 1), ProductID: productID, Type: changeType, CreatedAt: time.Now()}
+	if product != nil {
+		change.Product, _ = json.Marshal(product)
+	}
+	r.changes = append(r.changes, change)
+}
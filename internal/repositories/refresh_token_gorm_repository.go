@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMRefreshTokenRepository is a GORM implementation of RefreshTokenRepository.
+type GORMRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMRefreshTokenRepository creates a new instance of GORMRefreshTokenRepository.
+func NewGORMRefreshTokenRepository(db *gorm.DB) *GORMRefreshTokenRepository {
+	return &GORMRefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Create saves a refresh token.
+func (r *GORMRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create refresh token for user %s: %w", token.UserID, err)
+	}
+	return nil
+}
+
+// GetByHash retrieves a refresh token by the hash of its value.
+func (r *GORMRefreshTokenRepository) GetByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.First(&token, "token_hash = ?", hash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkUsed sets used_at only while it is still empty, so a token is used once.
+func (r *GORMRefreshTokenRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	res := r.db.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", at)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed to mark refresh token %d used: %w", id, res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// RevokeFamily revokes the tokens of a family.
+func (r *GORMRefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	if err := r.db.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", familyID).Update("revoked_at", at).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens of family %s: %w", familyID, err)
+	}
+	return nil
+}
+
+// RevokeUser revokes the tokens of a user outside the kept family.
+func (r *GORMRefreshTokenRepository) RevokeUser(userID, keepFamilyID string, at time.Time) error {
+	query := r.db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepFamilyID != "" {
+		query = query.Where("family_id <> ?", keepFamilyID)
+	}
+	if err := query.Update("revoked_at", at).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes the tokens that expired before the time.
+func (r *GORMRefreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
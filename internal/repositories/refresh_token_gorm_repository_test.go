@@ -0,0 +1,82 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMRefreshTokenRepository_Rotation(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.RefreshToken{}))
+	repo := repositories.NewGORMRefreshTokenRepository(db)
+	now := time.Now()
+
+	first := &models.RefreshToken{UserID: "user-1", FamilyID: "family-1", TokenHash: "hash-1", ExpiresAt: now.Add(time.Hour)}
+	second := &models.RefreshToken{UserID: "user-1", FamilyID: "family-1", TokenHash: "hash-2", ExpiresAt: now.Add(time.Hour)}
+	other := &models.RefreshToken{UserID: "user-1", FamilyID: "family-2", TokenHash: "hash-3", ExpiresAt: now.Add(-time.Minute)}
+	for _, token := range []*models.RefreshToken{first, second, other} {
+		require.NoError(t, repo.Create(token))
+	}
+	assert.Error(t, repo.Create(&models.RefreshToken{UserID: "user-2", FamilyID: "family-3", TokenHash: "hash-1"}))
+
+	found, err := repo.GetByHash("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, found.ID)
+	_, err = repo.GetByHash("missing")
+	assert.ErrorContains(t, err, "not found")
+
+	// A token is used once
+	used, err := repo.MarkUsed(first.ID, now)
+	require.NoError(t, err)
+	assert.True(t, used)
+	used, err = repo.MarkUsed(first.ID, now)
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	require.NoError(t, repo.RevokeFamily("family-1", now))
+	found, err = repo.GetByHash("hash-2")
+	require.NoError(t, err)
+	assert.NotNil(t, found.RevokedAt)
+	found, err = repo.GetByHash("hash-3")
+	require.NoError(t, err)
+	assert.Nil(t, found.RevokedAt, "other families are untouched")
+
+	deleted, err := repo.DeleteExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	_, err = repo.GetByHash("hash-3")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestGORMRefreshTokenRepository_RevokeUser(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.RefreshToken{}))
+	repo := repositories.NewGORMRefreshTokenRepository(db)
+	now := time.Now()
+
+	for _, token := range []*models.RefreshToken{
+		{UserID: "user-1", FamilyID: "family-1", TokenHash: "hash-1", ExpiresAt: now.Add(time.Hour)},
+		{UserID: "user-1", FamilyID: "family-2", TokenHash: "hash-2", ExpiresAt: now.Add(time.Hour)},
+		{UserID: "user-2", FamilyID: "family-3", TokenHash: "hash-3", ExpiresAt: now.Add(time.Hour)},
+	} {
+		require.NoError(t, repo.Create(token))
+	}
+
+	require.NoError(t, repo.RevokeUser("user-1", "family-1", now))
+	for hash, revoked := range map[string]bool{"hash-1": false, "hash-2": true, "hash-3": false} {
+		found, err := repo.GetByHash(hash)
+		require.NoError(t, err)
+		assert.Equal(t, revoked, found.RevokedAt != nil, hash)
+	}
+
+	require.NoError(t, repo.RevokeUser("user-1", "", now))
+	found, err := repo.GetByHash("hash-1")
+	require.NoError(t, err)
+	assert.NotNil(t, found.RevokedAt)
+}
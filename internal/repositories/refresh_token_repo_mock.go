@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockRefreshTokenRepository is an in-memory implementation of RefreshTokenRepository.
+type MockRefreshTokenRepository struct {
+	tokens map[uint]models.RefreshToken
+	nextID uint
+	mu     sync.RWMutex
+}
+
+// NewMockRefreshTokenRepository creates a new instance of MockRefreshTokenRepository.
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		tokens: make(map[uint]models.RefreshToken),
+	}
+}
+
+// Create saves a refresh token.
+func (r *MockRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return fmt.Errorf("failed to create refresh token for user %s: duplicate token", token.UserID)
+		}
+	}
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+// GetByHash retrieves a refresh token by the hash of its value.
+func (r *MockRefreshTokenRepository) GetByHash(hash string) (*models.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, fmt.Errorf("refresh token not found")
+}
+
+// MarkUsed marks an unused token as used.
+func (r *MockRefreshTokenRepository) MarkUsed(id uint, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	token.UsedAt = &at
+	r.tokens[id] = token
+	return true, nil
+}
+
+// RevokeFamily revokes the tokens of a family.
+func (r *MockRefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// RevokeUser revokes the tokens of a user outside the kept family.
+func (r *MockRefreshTokenRepository) RevokeUser(userID, keepFamilyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil && (keepFamilyID == "" || token.FamilyID != keepFamilyID) {
+			token.RevokedAt = &at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes the tokens that expired before the time.
+func (r *MockRefreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
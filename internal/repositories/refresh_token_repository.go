@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// RefreshTokenRepository defines the interface for refresh token data access.
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	// GetByHash returns the token with the hash, used and revoked ones included.
+	GetByHash(hash string) (*models.RefreshToken, error)
+	// MarkUsed marks an unused token as used and reports whether it was
+	// unused. Of concurrent calls for the same token only one succeeds.
+	MarkUsed(id uint, at time.Time) (bool, error)
+	// RevokeFamily revokes every token of a family that is not revoked yet.
+	RevokeFamily(familyID string, at time.Time) error
+	// RevokeUser revokes every token of the user that is not revoked yet,
+	// except those of keepFamilyID when it is not empty.
+	RevokeUser(userID, keepFamilyID string, at time.Time) error
+	// DeleteExpired removes tokens that expired before the time and returns
+	// how many were removed.
+	DeleteExpired(before time.Time) (int64, error)
+}
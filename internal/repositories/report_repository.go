@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ReportRepository defines the interface for aggregate sales reporting.
+type ReportRepository interface {
+	TopProducts(from, to time.Time, limit int) ([]models.ProductSales, error)
+	Revenue(from, to time.Time) (*models.RevenueSummary, error)
+	// UnitsSold returns the units sold per product ID in the period.
+	UnitsSold(from, to time.Time) (map[string]int, error)
+	// CancellationReasons counts the orders cancelled in the period by reason.
+	CancellationReasons(from, to time.Time) ([]models.ReasonCount, error)
+	// ReturnReasons counts the returns requested in the period by reason code.
+	ReturnReasons(from, to time.Time) ([]models.ReasonCount, error)
+	// BoughtTogether returns the products most often ordered along with a
+	// product in the period, by number of shared orders.
+	BoughtTogether(productID string, from, to time.Time, limit int) ([]models.ProductPairing, error)
+	// OrderMilestones returns when the orders placed in the period reached
+	// each fulfillment stage, leaving out cancelled and failed orders.
+	OrderMilestones(from, to time.Time) ([]models.OrderMilestones, error)
+	// OpenOrderMilestones returns the milestones of the orders still on their
+	// way to the customer. Preorders waiting for their release are left out.
+	OpenOrderMilestones() ([]models.OrderMilestones, error)
+}
+
+// openOrderStatuses are the statuses of orders not yet delivered, cancelled or waiting for a release.
+var openOrderStatuses = []string{"pending", "processing", "partially_shipped", "shipped"}
+
+// unsoldOrderStatuses are the statuses of orders that never became sales.
+var unsoldOrderStatuses = []string{"cancelled", "payment_failed"}
+
+// soldOrderStatuses are the statuses of paid orders, the only ones sales
+// reports count. Pending orders may still fail or be abandoned.
+var soldOrderStatuses = []string{"processing", "preorder", "partially_shipped", "shipped", "delivered"}
+
+// GORMReportRepository runs aggregate sales queries against the order tables.
+type GORMReportRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMReportRepository creates a new instance of GORMReportRepository.
+func NewGORMReportRepository(db *gorm.DB) *GORMReportRepository {
+	return &GORMReportRepository{
+		db: db,
+	}
+}
+
+// TopProducts returns the best-selling products by units sold in the period.
+// Only paid orders are counted.
+func (r *GORMReportRepository) TopProducts(from, to time.Time, limit int) ([]models.ProductSales, error) {
+	var sales []models.ProductSales
+	err := r.db.Table("order_items").
+		Select("order_items.product_id, products.name, SUM(order_items.quantity) AS units, SUM(order_items.quantity * order_items.price) AS revenue").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN products ON products.id = order_items.product_id").
+		Where("orders.status IN ?", soldOrderStatuses).
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to).
+		Group("order_items.product_id, products.name").
+		Order("units DESC").
+		Limit(limit).
+		Scan(&sales).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top products: %w", err)
+	}
+	return sales, nil
+}
+
+// Revenue returns the order count and revenue of the paid orders in the period.
+func (r *GORMReportRepository) Revenue(from, to time.Time) (*models.RevenueSummary, error) {
+	summary := &models.RevenueSummary{From: from, To: to}
+	row := r.db.Table("orders").
+		Select("COUNT(*), COALESCE(SUM(grand_total), 0), COALESCE(SUM(subtotal), 0), COALESCE(SUM(discount_total), 0), COALESCE(SUM(tax_total), 0), COALESCE(SUM(shipping_total), 0)").
+		Where("status IN ?", soldOrderStatuses).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Row()
+	if err := row.Scan(&summary.OrderCount, &summary.Revenue, &summary.Subtotal, &summary.DiscountTotal, &summary.TaxTotal, &summary.ShippingTotal); err != nil {
+		return nil, fmt.Errorf("failed to query revenue: %w", err)
+	}
+
+	err := r.db.Table("orders").
+		Select("presentment_currency AS currency, COUNT(*) AS order_count, SUM(grand_total) AS revenue, SUM(presentment_total) AS presentment_revenue").
+		Where("status IN ?", soldOrderStatuses).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Where("presentment_currency <> ''").
+		Group("presentment_currency").
+		Order("presentment_currency").
+		Scan(&summary.ByCurrency).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revenue by currency: %w", err)
+	}
+	return summary, nil
+}
+
+// UnitsSold returns the units of paid orders per product in the period.
+func (r *GORMReportRepository) UnitsSold(from, to time.Time) (map[string]int, error) {
+	var rows []struct {
+		ProductID string
+		Units     int
+	}
+	err := r.db.Table("order_items").
+		Select("order_items.product_id, SUM(order_items.quantity) AS units").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ?", soldOrderStatuses).
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to).
+		Group("order_items.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query units sold: %w", err)
+	}
+	units := make(map[string]int, len(rows))
+	for _, row := range rows {
+		units[row.ProductID] = row.Units
+	}
+	return units, nil
+}
+
+// CancellationReasons counts the orders cancelled in the period by reason, most frequent first.
+func (r *GORMReportRepository) CancellationReasons(from, to time.Time) ([]models.ReasonCount, error) {
+	var counts []models.ReasonCount
+	err := r.db.Table("orders").
+		Select("cancel_reason AS reason, COUNT(*) AS count, COALESCE(SUM(grand_total), 0) AS value").
+		Where("status = ?", "cancelled").
+		Where("cancelled_at >= ? AND cancelled_at < ?", from, to).
+		Group("cancel_reason").
+		Order("count DESC, reason ASC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cancellation reasons: %w", err)
+	}
+	return counts, nil
+}
+
+// ReturnReasons counts the returns requested in the period by reason code, most frequent first.
+func (r *GORMReportRepository) ReturnReasons(from, to time.Time) ([]models.ReasonCount, error) {
+	var counts []models.ReasonCount
+	err := r.db.Table("return_requests").
+		Select("return_requests.reason_code AS reason, COUNT(*) AS count, COALESCE(SUM(orders.grand_total), 0) AS value").
+		Joins("JOIN orders ON orders.id = return_requests.order_id").
+		Where("return_requests.created_at >= ? AND return_requests.created_at < ?", from, to).
+		Group("return_requests.reason_code").
+		Order("count DESC, reason ASC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query return reasons: %w", err)
+	}
+	return counts, nil
+}
+
+// BoughtTogether counts the paid orders in the period that contain both the
+// product and each other product, most frequent first.
+func (r *GORMReportRepository) BoughtTogether(productID string, from, to time.Time, limit int) ([]models.ProductPairing, error) {
+	var pairings []models.ProductPairing
+	err := r.db.Table("order_items AS item").
+		Select("other.product_id, COUNT(DISTINCT other.order_id) AS orders").
+		Joins("JOIN order_items AS other ON other.order_id = item.order_id AND other.product_id <> item.product_id").
+		Joins("JOIN orders ON orders.id = item.order_id").
+		Where("item.product_id = ?", productID).
+		Where("orders.status IN ?", soldOrderStatuses).
+		Where("orders.created_at >= ? AND orders.created_at < ?", from, to).
+		Group("other.product_id").
+		Order("orders DESC, other.product_id ASC").
+		Limit(limit).
+		Scan(&pairings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products bought with %s: %w", productID, err)
+	}
+	return pairings, nil
+}
+
+// OrderMilestones lists the milestones of the sold orders placed in the period.
+func (r *GORMReportRepository) OrderMilestones(from, to time.Time) ([]models.OrderMilestones, error) {
+	var milestones []models.OrderMilestones
+	err := r.db.Table("orders").
+		Select("id AS order_id, status, created_at, paid_at, shipped_at, delivered_at").
+		Where("status NOT IN ?", unsoldOrderStatuses).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Scan(&milestones).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order milestones: %w", err)
+	}
+	return milestones, nil
+}
+
+// OpenOrderMilestones lists the milestones of the orders that are not delivered yet.
+func (r *GORMReportRepository) OpenOrderMilestones() ([]models.OrderMilestones, error) {
+	var milestones []models.OrderMilestones
+	err := r.db.Table("orders").
+		Select("id AS order_id, status, created_at, paid_at, shipped_at, delivered_at").
+		Where("status IN ?", openOrderStatuses).
+		Scan(&milestones).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open order milestones: %w", err)
+	}
+	return milestones, nil
+}
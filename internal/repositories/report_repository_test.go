@@ -0,0 +1,99 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMReportRepository_CancellationAndReturnReasons(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ReturnRequest{}))
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	returnRepo := repositories.NewGORMReturnRepository(db)
+	reportRepo := repositories.NewGORMReportRepository(db)
+
+	for i, reason := range []string{"changed_mind", "found_cheaper", "changed_mind", ""} {
+		order := &models.Order{UserID: "user-1", Status: "pending", GrandTotal: float64(10 * (i + 1))}
+		require.NoError(t, orderRepo.Create(order))
+		require.NoError(t, orderRepo.Cancel(order.ID, []string{"pending"}, reason, ""))
+	}
+	delivered := &models.Order{UserID: "user-1", Status: "delivered", GrandTotal: 50}
+	require.NoError(t, orderRepo.Create(delivered))
+	require.NoError(t, returnRepo.Create(&models.ReturnRequest{OrderID: delivered.ID, UserID: "user-1", ReasonCode: "damaged", Status: models.ReturnStatusRequested}))
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	cancellations, err := reportRepo.CancellationReasons(from, to)
+	require.NoError(t, err)
+	require.Len(t, cancellations, 3)
+	assert.Equal(t, "changed_mind", cancellations[0].Reason)
+	assert.Equal(t, int64(2), cancellations[0].Count)
+	assert.Equal(t, 40.0, cancellations[0].Value)
+
+	returns, err := reportRepo.ReturnReasons(from, to)
+	require.NoError(t, err)
+	require.Len(t, returns, 1)
+	assert.Equal(t, models.ReasonCount{Reason: "damaged", Count: 1, Value: 50}, returns[0])
+
+	cancellations, err = reportRepo.CancellationReasons(to, to.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, cancellations)
+}
+
+func TestGORMReportRepository_BoughtTogether(t *testing.T) {
+	db := setupDB(t)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	reportRepo := repositories.NewGORMReportRepository(db)
+
+	place := func(status string, productIDs ...string) {
+		order := &models.Order{UserID: "user-1", Status: status}
+		for _, productID := range productIDs {
+			order.Items = append(order.Items, models.OrderItem{ProductID: productID, Quantity: 1, Price: 10})
+		}
+		require.NoError(t, orderRepo.Create(order))
+	}
+	place("delivered", "camera", "sd-card", "strap")
+	place("processing", "camera", "sd-card", "sd-card")
+	place("pending", "camera", "tripod")
+	place("cancelled", "camera", "tripod")
+	place("delivered", "sd-card", "strap")
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	pairings, err := reportRepo.BoughtTogether("camera", from, to, 10)
+	require.NoError(t, err)
+	// Cancelled and unpaid orders do not count and split lines count once per order
+	assert.Equal(t, []models.ProductPairing{{ProductID: "sd-card", Orders: 2}, {ProductID: "strap", Orders: 1}}, pairings)
+
+	pairings, err = reportRepo.BoughtTogether("camera", from, to, 1)
+	require.NoError(t, err)
+	assert.Len(t, pairings, 1)
+}
+
+func TestGORMReportRepository_RevenueByCurrency(t *testing.T) {
+	db := setupDB(t)
+	orderRepo := repositories.NewGORMOrderRepository(db)
+	reportRepo := repositories.NewGORMReportRepository(db)
+
+	for _, order := range []*models.Order{
+		{UserID: "user-1", Status: "processing", GrandTotal: 100, Currency: "USD", PresentmentCurrency: "EUR", ExchangeRate: 0.9, PresentmentTotal: 90},
+		{UserID: "user-1", Status: "delivered", GrandTotal: 50, Currency: "USD", PresentmentCurrency: "EUR", ExchangeRate: 0.8, PresentmentTotal: 40},
+		{UserID: "user-1", Status: "cancelled", GrandTotal: 70, Currency: "USD", PresentmentCurrency: "EUR", ExchangeRate: 0.9, PresentmentTotal: 63},
+		{UserID: "user-1", Status: "processing", GrandTotal: 10, Currency: "USD", PresentmentCurrency: "IDR", ExchangeRate: 15000, PresentmentTotal: 150000},
+		{UserID: "user-1", Status: "processing", GrandTotal: 20}, // Placed before multi-currency
+	} {
+		require.NoError(t, orderRepo.Create(order))
+	}
+
+	summary, err := reportRepo.Revenue(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 180.0, summary.Revenue)
+	assert.Equal(t, []models.CurrencyRevenue{
+		{Currency: "EUR", OrderCount: 2, Revenue: 150, PresentmentRevenue: 130},
+		{Currency: "IDR", OrderCount: 1, Revenue: 10, PresentmentRevenue: 150000},
+	}, summary.ByCurrency)
+}
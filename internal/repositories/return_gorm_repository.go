@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMReturnRepository is a GORM implementation of ReturnRepository.
+type GORMReturnRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMReturnRepository creates a new instance of GORMReturnRepository.
+func NewGORMReturnRepository(db *gorm.DB) *GORMReturnRepository {
+	return &GORMReturnRepository{
+		db: db,
+	}
+}
+
+// Create stores a new return request.
+func (r *GORMReturnRepository) Create(request *models.ReturnRequest) error {
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+	if err := r.db.Create(request).Error; err != nil {
+		return fmt.Errorf("failed to create return request: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a return request.
+func (r *GORMReturnRepository) GetByID(id string) (*models.ReturnRequest, error) {
+	var request models.ReturnRequest
+	if err := r.db.First(&request, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("return request with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get return request %s: %w", id, err)
+	}
+	return &request, nil
+}
+
+// List retrieves requests newest first, optionally filtered by user and status.
+func (r *GORMReturnRepository) List(userID, status string) ([]models.ReturnRequest, error) {
+	query := r.db.Order("created_at DESC")
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var requests []models.ReturnRequest
+	if err := query.Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to list return requests: %w", err)
+	}
+	return requests, nil
+}
+
+// ListByOrder retrieves all return requests of an order.
+func (r *GORMReturnRepository) ListByOrder(orderID string) ([]models.ReturnRequest, error) {
+	var requests []models.ReturnRequest
+	if err := r.db.Where("order_id = ?", orderID).Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to list return requests for order %s: %w", orderID, err)
+	}
+	return requests, nil
+}
+
+// Resolve saves the request only while it is still awaiting a decision, so
+// two admins cannot both resolve it.
+func (r *GORMReturnRepository) Resolve(request *models.ReturnRequest) error {
+	res := r.db.Model(&models.ReturnRequest{}).
+		Where("id = ? AND status = ?", request.ID, models.ReturnStatusRequested).
+		Updates(map[string]interface{}{
+			"status":           request.Status,
+			"resolution_note":  request.ResolutionNote,
+			"carrier":          request.Carrier,
+			"drop_off_code":    request.DropOffCode,
+			"label_url":        request.LabelURL,
+			"label_expires_at": request.LabelExpiresAt,
+			"resolved_at":      request.ResolvedAt,
+			"updated_at":       request.ResolvedAt,
+		})
+	if res.Error != nil {
+		return fmt.Errorf("failed to resolve return request %s: %w", request.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("return request %s has already been resolved", request.ID)
+	}
+	return nil
+}
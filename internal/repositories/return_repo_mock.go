@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockReturnRepository is an in-memory implementation of ReturnRepository.
+type MockReturnRepository struct {
+	requests map[string]models.ReturnRequest
+	mu       sync.RWMutex
+}
+
+// NewMockReturnRepository creates a new instance of MockReturnRepository.
+func NewMockReturnRepository() *MockReturnRepository {
+	return &MockReturnRepository{
+		requests: make(map[string]models.ReturnRequest),
+	}
+}
+
+// Create stores a new return request.
+func (r *MockReturnRepository) Create(request *models.ReturnRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	}
+	request.CreatedAt = time.Now()
+	request.UpdatedAt = request.CreatedAt
+	r.requests[request.ID] = *request
+	return nil
+}
+
+// GetByID returns a return request.
+func (r *MockReturnRepository) GetByID(id string) (*models.ReturnRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("return request with ID %s not found", id)
+	}
+	return &request, nil
+}
+
+// List returns requests newest first, optionally filtered by user and status.
+func (r *MockReturnRepository) List(userID, status string) ([]models.ReturnRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	requests := []models.ReturnRequest{}
+	for _, request := range r.requests {
+		if (userID == "" || request.UserID == userID) && (status == "" || request.Status == status) {
+			requests = append(requests, request)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+// ListByOrder returns all return requests of an order.
+func (r *MockReturnRepository) ListByOrder(orderID string) ([]models.ReturnRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var requests []models.ReturnRequest
+	for _, request := range r.requests {
+		if request.OrderID == orderID {
+			requests = append(requests, request)
+		}
+	}
+	return requests, nil
+}
+
+// Resolve saves the request if it is still awaiting a decision.
+func (r *MockReturnRepository) Resolve(request *models.ReturnRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.requests[request.ID]
+	if !ok {
+		return fmt.Errorf("return request with ID %s not found", request.ID)
+	}
+	if existing.Status != models.ReturnStatusRequested {
+		return fmt.Errorf("return request %s has already been resolved", request.ID)
+	}
+	request.UpdatedAt = time.Now()
+	r.requests[request.ID] = *request
+	return nil
+}
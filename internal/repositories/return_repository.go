@@ -0,0 +1,14 @@
+package repositories
+
+import "toko/internal/models"
+
+// ReturnRepository defines the interface for return request data access.
+type ReturnRepository interface {
+	Create(request *models.ReturnRequest) error
+	GetByID(id string) (*models.ReturnRequest, error)
+	// List returns requests newest first, optionally filtered by user and status.
+	List(userID, status string) ([]models.ReturnRequest, error)
+	ListByOrder(orderID string) ([]models.ReturnRequest, error)
+	// Resolve saves an approval or rejection if the request is still awaiting one.
+	Resolve(request *models.ReturnRequest) error
+}
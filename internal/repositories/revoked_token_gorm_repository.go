@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMRevokedTokenRepository is a GORM implementation of RevokedTokenRepository.
+type GORMRevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMRevokedTokenRepository creates a new instance of GORMRevokedTokenRepository.
+func NewGORMRevokedTokenRepository(db *gorm.DB) *GORMRevokedTokenRepository {
+	return &GORMRevokedTokenRepository{
+		db: db,
+	}
+}
+
+// Revoke inserts the token; revoking it again changes nothing.
+func (r *GORMRevokedTokenRepository) Revoke(token *models.RevokedToken) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to revoke token %s: %w", token.JTI, err)
+	}
+	return nil
+}
+
+// IsRevoked looks the token up.
+func (r *GORMRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check revocation of token %s: %w", jti, err)
+	}
+	return count > 0, nil
+}
+
+// PurgeExpired deletes the entries of tokens that have expired.
+func (r *GORMRevokedTokenRepository) PurgeExpired(now time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", now).Delete(&models.RevokedToken{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to purge revoked tokens: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,38 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMRevokedTokenRepository_Blacklist(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.RevokedToken{}))
+	repo := repositories.NewGORMRevokedTokenRepository(db)
+	now := time.Now()
+
+	require.NoError(t, repo.Revoke(&models.RevokedToken{JTI: "jti-1", UserID: "user-1", ExpiresAt: now.Add(time.Hour), RevokedAt: now}))
+	require.NoError(t, repo.Revoke(&models.RevokedToken{JTI: "jti-2", UserID: "user-1", ExpiresAt: now.Add(-time.Minute), RevokedAt: now}))
+	// Revoking twice is harmless
+	require.NoError(t, repo.Revoke(&models.RevokedToken{JTI: "jti-1", UserID: "user-1", ExpiresAt: now.Add(time.Hour), RevokedAt: now}))
+
+	revoked, err := repo.IsRevoked("jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = repo.IsRevoked("jti-3")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	purged, err := repo.PurgeExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	revoked, err = repo.IsRevoked("jti-2")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// revokedTokenPrefix namespaces blacklisted token IDs in Redis.
+const revokedTokenPrefix = "toko:revoked_token:"
+
+// RedisRevokedTokenRepository is a Redis implementation of RevokedTokenRepository.
+// Entries expire with the tokens they blacklist.
+type RedisRevokedTokenRepository struct {
+	client  *redis.Client
+	timeout time.Duration
+}
+
+// NewRedisRevokedTokenRepository creates a new instance of RedisRevokedTokenRepository.
+func NewRedisRevokedTokenRepository(client *redis.Client) *RedisRevokedTokenRepository {
+	return &RedisRevokedTokenRepository{
+		client:  client,
+		timeout: 2 * time.Second,
+	}
+}
+
+// Revoke sets a key that lives as long as the token.
+func (r *RedisRevokedTokenRepository) Revoke(token *models.RevokedToken) error {
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 {
+		return nil // Expired tokens are rejected anyway
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.client.Set(ctx, revokedTokenPrefix+token.JTI, token.UserID, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token %s: %w", token.JTI, err)
+	}
+	return nil
+}
+
+// IsRevoked checks whether the token's key exists.
+func (r *RedisRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	exists, err := r.client.Exists(ctx, revokedTokenPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check revocation of token %s: %w", jti, err)
+	}
+	return exists > 0, nil
+}
+
+// PurgeExpired is a no-op because Redis expires keys itself.
+func (r *RedisRevokedTokenRepository) PurgeExpired(now time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockRevokedTokenRepository is an in-memory implementation of RevokedTokenRepository.
+type MockRevokedTokenRepository struct {
+	tokens map[string]models.RevokedToken
+	mu     sync.RWMutex
+}
+
+// NewMockRevokedTokenRepository creates a new instance of MockRevokedTokenRepository.
+func NewMockRevokedTokenRepository() *MockRevokedTokenRepository {
+	return &MockRevokedTokenRepository{
+		tokens: make(map[string]models.RevokedToken),
+	}
+}
+
+// Revoke blacklists a token.
+func (r *MockRevokedTokenRepository) Revoke(token *models.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[token.JTI]; !ok {
+		r.tokens[token.JTI] = *token
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token is blacklisted.
+func (r *MockRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.tokens[jti]
+	return ok, nil
+}
+
+// PurgeExpired deletes the entries of tokens that have expired.
+func (r *MockRevokedTokenRepository) PurgeExpired(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for jti, token := range r.tokens {
+		if token.ExpiresAt.Before(now) {
+			delete(r.tokens, jti)
+			purged++
+		}
+	}
+	return purged, nil
+}
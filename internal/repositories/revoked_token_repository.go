@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// RevokedTokenRepository defines the interface for the access token blacklist.
+type RevokedTokenRepository interface {
+	// Revoke blacklists a token until it expires.
+	Revoke(token *models.RevokedToken) error
+	// IsRevoked reports whether the token with the ID is blacklisted.
+	IsRevoked(jti string) (bool, error)
+	// PurgeExpired deletes entries of expired tokens and returns how many were removed.
+	PurgeExpired(now time.Time) (int64, error)
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMScrapingEventRepository is a GORM implementation of ScrapingEventRepository.
+type GORMScrapingEventRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMScrapingEventRepository creates a new instance of GORMScrapingEventRepository.
+func NewGORMScrapingEventRepository(db *gorm.DB) *GORMScrapingEventRepository {
+	return &GORMScrapingEventRepository{
+		db: db,
+	}
+}
+
+// Create stores a scraping event.
+func (r *GORMScrapingEventRepository) Create(event *models.ScrapingEvent) error {
+	if err := r.db.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record scraping event: %w", err)
+	}
+	return nil
+}
+
+// List returns the most recent scraping events.
+func (r *GORMScrapingEventRepository) List(limit int) ([]models.ScrapingEvent, error) {
+	var events []models.ScrapingEvent
+	if err := r.db.Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scraping events: %w", err)
+	}
+	return events, nil
+}
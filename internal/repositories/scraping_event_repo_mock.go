@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockScrapingEventRepository is an in-memory implementation of ScrapingEventRepository.
+type MockScrapingEventRepository struct {
+	events []models.ScrapingEvent
+	mu     sync.RWMutex
+}
+
+// NewMockScrapingEventRepository creates a new instance of MockScrapingEventRepository.
+func NewMockScrapingEventRepository() *MockScrapingEventRepository {
+	return &MockScrapingEventRepository{}
+}
+
+// Create stores a scraping event.
+func (r *MockScrapingEventRepository) Create(event *models.ScrapingEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = uint(len(r.events) + 1)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// List returns the most recent scraping events.
+func (r *MockScrapingEventRepository) List(limit int) ([]models.ScrapingEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []models.ScrapingEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		events = append(events, r.events[i])
+	}
+	return events, nil
+}
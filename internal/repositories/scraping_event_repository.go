@@ -0,0 +1,10 @@
+package repositories
+
+import "toko/internal/models"
+
+// ScrapingEventRepository defines the interface for scraping detection event data access.
+type ScrapingEventRepository interface {
+	Create(event *models.ScrapingEvent) error
+	// List returns the most recent events first.
+	List(limit int) ([]models.ScrapingEvent, error)
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMSearchAnalyticsRepository is a GORM implementation of SearchAnalyticsRepository.
+type GORMSearchAnalyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMSearchAnalyticsRepository creates a new instance of GORMSearchAnalyticsRepository.
+func NewGORMSearchAnalyticsRepository(db *gorm.DB) *GORMSearchAnalyticsRepository {
+	return &GORMSearchAnalyticsRepository{
+		db: db,
+	}
+}
+
+// CreateQuery records a search.
+func (r *GORMSearchAnalyticsRepository) CreateQuery(query *models.SearchQuery) error {
+	if query.ID == "" {
+		query.ID = uuid.New().String()
+	}
+	if err := r.db.Create(query).Error; err != nil {
+		return fmt.Errorf("failed to record search query: %w", err)
+	}
+	return nil
+}
+
+// GetQuery retrieves a recorded search by its ID.
+func (r *GORMSearchAnalyticsRepository) GetQuery(id string) (*models.SearchQuery, error) {
+	var query models.SearchQuery
+	if err := r.db.First(&query, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("search with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get search %s: %w", id, err)
+	}
+	return &query, nil
+}
+
+// CreateClick records a click on a search result.
+func (r *GORMSearchAnalyticsRepository) CreateClick(click *models.SearchClick) error {
+	if err := r.db.Create(click).Error; err != nil {
+		return fmt.Errorf("failed to record search click: %w", err)
+	}
+	return nil
+}
+
+// QueryStats aggregates searches in [from, to) by query. Clicks are counted
+// per search first so that joining them does not inflate the averages.
+func (r *GORMSearchAnalyticsRepository) QueryStats(from, to time.Time, zeroResultsOnly bool, limit int) ([]models.SearchQueryStats, error) {
+	clicks := r.db.Table("search_clicks").Select("search_id, COUNT(*) AS clicks").Group("search_id")
+	query := r.db.Table("search_queries AS q").
+		Select(`q.query AS query,
+			COUNT(*) AS searches,
+			AVG(q.result_count) AS avg_results,
+			COALESCE(SUM(c.clicks), 0) AS clicks,
+			AVG(CASE WHEN c.clicks > 0 THEN 1.0 ELSE 0.0 END) AS click_through_rate`).
+		Joins("LEFT JOIN (?) AS c ON c.search_id = q.id", clicks).
+		Where("q.created_at >= ? AND q.created_at < ?", from, to)
+	if zeroResultsOnly {
+		query = query.Where("q.result_count = 0")
+	}
+
+	var stats []models.SearchQueryStats
+	err := query.Group("q.query").
+		Order("searches DESC, q.query ASC").
+		Limit(limit).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// GORMSearchSynonymRepository is a GORM implementation of SearchSynonymRepository.
+type GORMSearchSynonymRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMSearchSynonymRepository creates a new instance of GORMSearchSynonymRepository.
+func NewGORMSearchSynonymRepository(db *gorm.DB) *GORMSearchSynonymRepository {
+	return &GORMSearchSynonymRepository{
+		db: db,
+	}
+}
+
+// List retrieves all synonyms.
+func (r *GORMSearchSynonymRepository) List() ([]models.SearchSynonym, error) {
+	var synonyms []models.SearchSynonym
+	if err := r.db.Order("id ASC").Find(&synonyms).Error; err != nil {
+		return nil, fmt.Errorf("failed to get search synonyms: %w", err)
+	}
+	return synonyms, nil
+}
+
+// GetByID retrieves a synonym by its ID.
+func (r *GORMSearchSynonymRepository) GetByID(id uint) (*models.SearchSynonym, error) {
+	var synonym models.SearchSynonym
+	if err := r.db.First(&synonym, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("search synonym %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get search synonym %d: %w", id, err)
+	}
+	return &synonym, nil
+}
+
+// Create adds a synonym.
+func (r *GORMSearchSynonymRepository) Create(synonym *models.SearchSynonym) error {
+	if err := r.db.Create(synonym).Error; err != nil {
+		return fmt.Errorf("failed to create search synonym: %w", err)
+	}
+	return nil
+}
+
+// Update saves the terms of an existing synonym.
+func (r *GORMSearchSynonymRepository) Update(synonym *models.SearchSynonym) error {
+	res := r.db.Model(synonym).Updates(map[string]interface{}{"terms": synonym.Terms, "updated_at": time.Now()})
+	if res.Error != nil {
+		return fmt.Errorf("failed to update search synonym %d: %w", synonym.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("search synonym %d not found", synonym.ID)
+	}
+	return nil
+}
+
+// Delete removes a synonym.
+func (r *GORMSearchSynonymRepository) Delete(id uint) error {
+	res := r.db.Delete(&models.SearchSynonym{}, id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete search synonym: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("search synonym %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMSearchAnalyticsRepository_QueryStats(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.SearchQuery{}, &models.SearchClick{}))
+	repo := repositories.NewGORMSearchAnalyticsRepository(db)
+	productRepo := repositories.NewGORMProductRepository(db)
+
+	laptop := &models.Product{Name: "Gaming Laptop", Description: "Fast", SKU: "LP-1", Price: 1000}
+	assert.NoError(t, productRepo.Create(laptop))
+	found, err := productRepo.Search([][]string{{"laptop"}, {"notebook", "lp-1"}}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	first := &models.SearchQuery{Query: "laptop", ResultCount: 4, CreatedAt: time.Now()}
+	second := &models.SearchQuery{Query: "laptop", ResultCount: 2, CreatedAt: time.Now()}
+	missing := &models.SearchQuery{Query: "lapptop", ResultCount: 0, CreatedAt: time.Now()}
+	for _, query := range []*models.SearchQuery{first, second, missing} {
+		assert.NoError(t, repo.CreateQuery(query))
+	}
+	// Two clicks on one search count once towards the click-through rate
+	assert.NoError(t, repo.CreateClick(&models.SearchClick{SearchID: first.ID, ProductID: laptop.ID, Position: 1}))
+	assert.NoError(t, repo.CreateClick(&models.SearchClick{SearchID: first.ID, ProductID: laptop.ID, Position: 2}))
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	stats, err := repo.QueryStats(from, to, false, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, models.SearchQueryStats{Query: "laptop", Searches: 2, AvgResults: 3, Clicks: 2, ClickThroughRate: 0.5}, stats[0])
+	}
+
+	stats, err = repo.QueryStats(from, to, true, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, "lapptop", stats[0].Query)
+	}
+}
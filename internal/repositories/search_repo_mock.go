@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockSearchAnalyticsRepository is an in-memory implementation of SearchAnalyticsRepository.
+type MockSearchAnalyticsRepository struct {
+	queries map[string]models.SearchQuery
+	clicks  []models.SearchClick
+	mu      sync.RWMutex
+}
+
+// NewMockSearchAnalyticsRepository creates a new instance of MockSearchAnalyticsRepository.
+func NewMockSearchAnalyticsRepository() *MockSearchAnalyticsRepository {
+	return &MockSearchAnalyticsRepository{
+		queries: make(map[string]models.SearchQuery),
+	}
+}
+
+// CreateQuery records a search.
+func (r *MockSearchAnalyticsRepository) CreateQuery(query *models.SearchQuery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if query.ID == "" {
+		query.ID = uuid.New().String()
+	}
+	if query.CreatedAt.IsZero() {
+		query.CreatedAt = time.Now()
+	}
+	r.queries[query.ID] = *query
+	return nil
+}
+
+// GetQuery returns a recorded search.
+func (r *MockSearchAnalyticsRepository) GetQuery(id string) (*models.SearchQuery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	query, ok := r.queries[id]
+	if !ok {
+		return nil, fmt.Errorf("search with ID %s not found", id)
+	}
+	return &query, nil
+}
+
+// CreateClick records a click on a search result.
+func (r *MockSearchAnalyticsRepository) CreateClick(click *models.SearchClick) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	click.ID = uint(len(r.clicks) + 1)
+	if click.CreatedAt.IsZero() {
+		click.CreatedAt = time.Now()
+	}
+	r.clicks = append(r.clicks, *click)
+	return nil
+}
+
+// QueryStats aggregates searches in [from, to) by query, most searched first.
+func (r *MockSearchAnalyticsRepository) QueryStats(from, to time.Time, zeroResultsOnly bool, limit int) ([]models.SearchQueryStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clicksBySearch := make(map[string]int64)
+	for _, click := range r.clicks {
+		clicksBySearch[click.SearchID]++
+	}
+
+	byQuery := make(map[string]*models.SearchQueryStats)
+	results := make(map[string]int)
+	clicked := make(map[string]int)
+	for _, query := range r.queries {
+		if query.CreatedAt.Before(from) || !query.CreatedAt.Before(to) || (zeroResultsOnly && query.ResultCount != 0) {
+			continue
+		}
+		stats, ok := byQuery[query.Query]
+		if !ok {
+			stats = &models.SearchQueryStats{Query: query.Query}
+			byQuery[query.Query] = stats
+		}
+		stats.Searches++
+		stats.Clicks += clicksBySearch[query.ID]
+		results[query.Query] += query.ResultCount
+		if clicksBySearch[query.ID] > 0 {
+			clicked[query.Query]++
+		}
+	}
+
+	statsList := make([]models.SearchQueryStats, 0, len(byQuery))
+	for _, stats := range byQuery {
+		stats.AvgResults = float64(results[stats.Query]) / float64(stats.Searches)
+		stats.ClickThroughRate = float64(clicked[stats.Query]) / float64(stats.Searches)
+		statsList = append(statsList, *stats)
+	}
+	sort.Slice(statsList, func(i, j int) bool {
+		if statsList[i].Searches != statsList[j].Searches {
+			return statsList[i].Searches > statsList[j].Searches
+		}
+		return statsList[i].Query < statsList[j].Query
+	})
+	if len(statsList) > limit {
+		statsList = statsList[:limit]
+	}
+	return statsList, nil
+}
+
+// MockSearchSynonymRepository is an in-memory implementation of SearchSynonymRepository.
+type MockSearchSynonymRepository struct {
+	synonyms map[uint]models.SearchSynonym
+	nextID   uint
+	mu       sync.RWMutex
+}
+
+// NewMockSearchSynonymRepository creates a new instance of MockSearchSynonymRepository.
+func NewMockSearchSynonymRepository() *MockSearchSynonymRepository {
+	return &MockSearchSynonymRepository{
+		synonyms: make(map[uint]models.SearchSynonym),
+	}
+}
+
+// List returns all synonyms.
+func (r *MockSearchSynonymRepository) List() ([]models.SearchSynonym, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	synonyms := make([]models.SearchSynonym, 0, len(r.synonyms))
+	for _, synonym := range r.synonyms {
+		synonyms = append(synonyms, synonym)
+	}
+	sort.Slice(synonyms, func(i, j int) bool { return synonyms[i].ID < synonyms[j].ID })
+	return synonyms, nil
+}
+
+// GetByID returns a synonym.
+func (r *MockSearchSynonymRepository) GetByID(id uint) (*models.SearchSynonym, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	synonym, ok := r.synonyms[id]
+	if !ok {
+		return nil, fmt.Errorf("search synonym %d not found", id)
+	}
+	return &synonym, nil
+}
+
+// Create adds a synonym.
+func (r *MockSearchSynonymRepository) Create(synonym *models.SearchSynonym) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	synonym.ID = r.nextID
+	synonym.CreatedAt = time.Now()
+	synonym.UpdatedAt = synonym.CreatedAt
+	r.synonyms[synonym.ID] = *synonym
+	return nil
+}
+
+// Update saves the terms of an existing synonym.
+func (r *MockSearchSynonymRepository) Update(synonym *models.SearchSynonym) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.synonyms[synonym.ID]
+	if !ok {
+		return fmt.Errorf("search synonym %d not found", synonym.ID)
+	}
+	existing.Terms = synonym.Terms
+	existing.UpdatedAt = time.Now()
+	r.synonyms[synonym.ID] = existing
+	*synonym = existing
+	return nil
+}
+
+// Delete removes a synonym.
+func (r *MockSearchSynonymRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.synonyms[id]; !ok {
+		return fmt.Errorf("search synonym %d not found", id)
+	}
+	delete(r.synonyms, id)
+	return nil
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// SearchAnalyticsRepository defines the interface for search analytics data access.
+type SearchAnalyticsRepository interface {
+	CreateQuery(query *models.SearchQuery) error
+	GetQuery(id string) (*models.SearchQuery, error)
+	CreateClick(click *models.SearchClick) error
+	// QueryStats aggregates searches in the period by query, most searched
+	// first. zeroResultsOnly restricts it to searches that found nothing.
+	QueryStats(from, to time.Time, zeroResultsOnly bool, limit int) ([]models.SearchQueryStats, error)
+}
+
+// SearchSynonymRepository defines the interface for search synonym data access.
+type SearchSynonymRepository interface {
+	List() ([]models.SearchSynonym, error)
+	GetByID(id uint) (*models.SearchSynonym, error)
+	Create(synonym *models.SearchSynonym) error
+	Update(synonym *models.SearchSynonym) error
+	Delete(id uint) error
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMSessionRepository is a GORM implementation of SessionRepository.
+type GORMSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMSessionRepository creates a new instance of GORMSessionRepository.
+func NewGORMSessionRepository(db *gorm.DB) *GORMSessionRepository {
+	return &GORMSessionRepository{
+		db: db,
+	}
+}
+
+// Create saves a session.
+func (r *GORMSessionRepository) Create(session *models.Session) error {
+	if err := r.db.Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session for user %s: %w", session.UserID, err)
+	}
+	return nil
+}
+
+// GetByID retrieves a session by its ID.
+func (r *GORMSessionRepository) GetByID(id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("session with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get session %s: %w", id, err)
+	}
+	return &session, nil
+}
+
+// ListActiveByUser lists the sessions of a user still in use.
+func (r *GORMSessionRepository) ListActiveByUser(userID string, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sessions of user %s: %w", userID, err)
+	}
+	return sessions, nil
+}
+
+// Touch records a new access token of the session.
+func (r *GORMSessionRepository) Touch(id, accessJTI string, accessExpiresAt, expiresAt, at time.Time) error {
+	if err := r.db.Model(&models.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"access_jti":        accessJTI,
+		"access_expires_at": accessExpiresAt,
+		"expires_at":        expiresAt,
+		"last_used_at":      at,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update session %s: %w", id, err)
+	}
+	return nil
+}
+
+// Revoke revokes a session.
+func (r *GORMSessionRepository) Revoke(id string, at time.Time) error {
+	if err := r.db.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at).Error; err != nil {
+		return fmt.Errorf("failed to revoke session %s: %w", id, err)
+	}
+	return nil
+}
+
+// RevokeUser revokes the sessions of a user other than keepID.
+func (r *GORMSessionRepository) RevokeUser(userID, keepID string, at time.Time) ([]models.Session, error) {
+	var revoked []models.Session
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("user_id = ? AND revoked_at IS NULL", userID)
+		if keepID != "" {
+			query = query.Where("id <> ?", keepID)
+		}
+		if err := query.Find(&revoked).Error; err != nil {
+			return err
+		}
+		if len(revoked) == 0 {
+			return nil
+		}
+		ids := make([]string, len(revoked))
+		for i := range revoked {
+			ids[i] = revoked[i].ID
+			revoked[i].RevokedAt = &at
+		}
+		return tx.Model(&models.Session{}).Where("id IN ?", ids).Update("revoked_at", at).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions of user %s: %w", userID, err)
+	}
+	return revoked, nil
+}
+
+// DeleteExpired removes the sessions that expired before the time.
+func (r *GORMSessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", before).Delete(&models.Session{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
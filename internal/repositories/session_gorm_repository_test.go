@@ -0,0 +1,58 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMSessionRepository(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Session{}))
+	repo := repositories.NewGORMSessionRepository(db)
+	now := time.Now()
+
+	for _, session := range []*models.Session{
+		{ID: "session-1", UserID: "user-1", UserAgent: "Firefox", LastUsedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "session-2", UserID: "user-1", UserAgent: "Safari", LastUsedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour)},
+		{ID: "session-3", UserID: "user-1", UserAgent: "Chrome", LastUsedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Minute)},
+		{ID: "session-4", UserID: "user-2", LastUsedAt: now, ExpiresAt: now.Add(time.Hour)},
+	} {
+		require.NoError(t, repo.Create(session))
+	}
+
+	active, err := repo.ListActiveByUser("user-1", now)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, "session-2", active[0].ID, "most recently used first")
+
+	require.NoError(t, repo.Touch("session-1", "jti-1", now.Add(15*time.Minute), now.Add(2*time.Hour), now))
+	found, err := repo.GetByID("session-1")
+	require.NoError(t, err)
+	assert.Equal(t, "jti-1", found.AccessJTI)
+	assert.WithinDuration(t, now.Add(2*time.Hour), found.ExpiresAt, time.Second)
+
+	revoked, err := repo.RevokeUser("user-1", "session-1", now)
+	require.NoError(t, err)
+	assert.Len(t, revoked, 2)
+	active, err = repo.ListActiveByUser("user-1", now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "session-1", active[0].ID)
+
+	require.NoError(t, repo.Revoke("session-1", now))
+	active, err = repo.ListActiveByUser("user-1", now)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	_, err = repo.GetByID("missing")
+	assert.ErrorContains(t, err, "not found")
+
+	deleted, err := repo.DeleteExpired(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockSessionRepository is an in-memory implementation of SessionRepository.
+type MockSessionRepository struct {
+	sessions map[string]models.Session
+	mu       sync.RWMutex
+}
+
+// NewMockSessionRepository creates a new instance of MockSessionRepository.
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		sessions: make(map[string]models.Session),
+	}
+}
+
+// Create saves a session.
+func (r *MockSessionRepository) Create(session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return fmt.Errorf("failed to create session for user %s: duplicate ID", session.UserID)
+	}
+	session.CreatedAt = time.Now()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// GetByID retrieves a session by its ID.
+func (r *MockSessionRepository) GetByID(id string) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session with ID %s not found", id)
+	}
+	return &session, nil
+}
+
+// ListActiveByUser lists the sessions of a user still in use.
+func (r *MockSessionRepository) ListActiveByUser(userID string, now time.Time) ([]models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// Touch records a new access token of the session.
+func (r *MockSessionRepository) Touch(id, accessJTI string, accessExpiresAt, expiresAt, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil
+	}
+	session.AccessJTI = accessJTI
+	session.AccessExpiresAt = accessExpiresAt
+	session.ExpiresAt = expiresAt
+	session.LastUsedAt = at
+	r.sessions[id] = session
+	return nil
+}
+
+// Revoke revokes a session.
+func (r *MockSessionRepository) Revoke(id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &at
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+// RevokeUser revokes the sessions of a user other than keepID.
+func (r *MockSessionRepository) RevokeUser(userID, keepID string, at time.Time) ([]models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var revoked []models.Session
+	for id, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && id != keepID {
+			session.RevokedAt = &at
+			r.sessions[id] = session
+			revoked = append(revoked, session)
+		}
+	}
+	return revoked, nil
+}
+
+// DeleteExpired removes the sessions that expired before the time.
+func (r *MockSessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
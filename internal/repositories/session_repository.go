@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// SessionRepository defines the interface for login session data access.
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id string) (*models.Session, error)
+	// ListActiveByUser returns the sessions of the user that are neither
+	// revoked nor expired at the time, most recently used first.
+	ListActiveByUser(userID string, now time.Time) ([]models.Session, error)
+	// Touch records that the session got a new access token and keeps it
+	// going until expiresAt.
+	Touch(id, accessJTI string, accessExpiresAt, expiresAt, at time.Time) error
+	// Revoke revokes the session unless it already is.
+	Revoke(id string, at time.Time) error
+	// RevokeUser revokes the sessions of the user that are not revoked yet,
+	// except keepID when it is not empty, and returns them.
+	RevokeUser(userID, keepID string, at time.Time) ([]models.Session, error)
+	// DeleteExpired removes sessions that expired before the time and
+	// returns how many were removed.
+	DeleteExpired(before time.Time) (int64, error)
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GORMStockReservationRepository is a GORM implementation of StockReservationRepository.
+type GORMStockReservationRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMStockReservationRepository creates a new instance of GORMStockReservationRepository.
+func NewGORMStockReservationRepository(db *gorm.DB) *GORMStockReservationRepository {
+	return &GORMStockReservationRepository{
+		db: db,
+	}
+}
+
+// Reserve decrements stock and records reservations in a single transaction.
+// The conditional UPDATE guarantees stock never goes negative under concurrency.
+func (r *GORMStockReservationRepository) Reserve(orderID string, items []models.OrderItem, expiresAt time.Time) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			res := tx.Model(&models.Product{}).
+				Where("id = ? AND stock >= ?", item.ProductID, item.Quantity).
+				Update("stock", gorm.Expr("stock - ?", item.Quantity))
+			if res.Error != nil {
+				return fmt.Errorf("failed to reserve stock for product %s: %w", item.ProductID, res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("insufficient stock for product %s", item.ProductID)
+			}
+			reservations = append(reservations, models.StockReservation{
+				OrderID:   orderID,
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Status:    models.ReservationStatusActive,
+				ExpiresAt: expiresAt,
+			})
+		}
+		if len(reservations) == 0 {
+			return nil
+		}
+		return tx.Create(&reservations).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// Release returns stock for the order's active reservations in a single transaction.
+func (r *GORMStockReservationRepository) Release(orderID string, status string) (int, error) {
+	released := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		if err := tx.Where("order_id = ? AND status = ?", orderID, models.ReservationStatusActive).Find(&reservations).Error; err != nil {
+			return fmt.Errorf("failed to load reservations for order %s: %w", orderID, err)
+		}
+		for _, reservation := range reservations {
+			// Guard on status so concurrent releases cannot return the stock twice
+			res := tx.Model(&models.StockReservation{}).
+				Where("id = ? AND status = ?", reservation.ID, models.ReservationStatusActive).
+				Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+			if res.Error != nil {
+				return fmt.Errorf("failed to release reservation %d: %w", reservation.ID, res.Error)
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			if err := tx.Model(&models.Product{}).
+				Where("id = ?", reservation.ProductID).
+				Update("stock", gorm.Expr("stock + ?", reservation.Quantity)).Error; err != nil {
+				return fmt.Errorf("failed to restore stock for product %s: %w", reservation.ProductID, err)
+			}
+			released++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return released, nil
+}
+
+// Commit marks the order's active reservations as committed.
+func (r *GORMStockReservationRepository) Commit(orderID string) (int, error) {
+	res := r.db.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderID, models.ReservationStatusActive).
+		Updates(map[string]interface{}{"status": models.ReservationStatusCommitted, "updated_at": time.Now()})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to commit reservations for order %s: %w", orderID, res.Error)
+	}
+	return int(res.RowsAffected), nil
+}
+
+// ListByOrder returns all reservations of an order.
+func (r *GORMStockReservationRepository) ListByOrder(orderID string) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	if err := r.db.Where("order_id = ?", orderID).Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reservations for order %s: %w", orderID, err)
+	}
+	return reservations, nil
+}
+
+// ListExpiredOrderIDs returns orders with active reservations that expired before now.
+func (r *GORMStockReservationRepository) ListExpiredOrderIDs(now time.Time, limit int) ([]string, error) {
+	var orderIDs []string
+	err := r.db.Model(&models.StockReservation{}).
+		Where("status = ? AND expires_at < ?", models.ReservationStatusActive, now).
+		Distinct("order_id").
+		Limit(limit).
+		Pluck("order_id", &orderIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired reservations: %w", err)
+	}
+	return orderIDs, nil
+}
+
+// ReleaseItem returns the stock of one of the order's reservations for the product.
+func (r *GORMStockReservationRepository) ReleaseItem(orderID, productID string, quantity int, status string) (bool, error) {
+	released := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		held := []string{models.ReservationStatusActive, models.ReservationStatusCommitted}
+		if err := tx.Where("order_id = ? AND product_id = ? AND quantity = ? AND status IN ?", orderID, productID, quantity, held).
+			Order("id ASC").Find(&reservations).Error; err != nil {
+			return fmt.Errorf("failed to load reservations for order %s: %w", orderID, err)
+		}
+		for _, reservation := range reservations {
+			res := tx.Model(&models.StockReservation{}).
+				Where("id = ? AND status IN ?", reservation.ID, held).
+				Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+			if res.Error != nil {
+				return fmt.Errorf("failed to release reservation %d: %w", reservation.ID, res.Error)
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			if err := tx.Model(&models.Product{}).
+				Where("id = ?", reservation.ProductID).
+				Update("stock", gorm.Expr("stock + ?", reservation.Quantity)).Error; err != nil {
+				return fmt.Errorf("failed to restore stock for product %s: %w", reservation.ProductID, err)
+			}
+			released = true
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return released, nil
+}
@@ -0,0 +1,90 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMStockReservationRepository_ReserveReleaseCommit(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	reservationRepo := repositories.NewGORMStockReservationRepository(db)
+
+	laptop := &models.Product{Name: "Laptop", Price: 1000, Stock: 2}
+	assert.NoError(t, productRepo.Create(laptop))
+
+	// Reserving more than is available fails without touching stock
+	_, err := reservationRepo.Reserve("order-1", []models.OrderItem{{ProductID: laptop.ID, Quantity: 3}}, time.Now().Add(time.Minute))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient stock")
+
+	_, err = reservationRepo.Reserve("order-1", []models.OrderItem{{ProductID: laptop.ID, Quantity: 2}}, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	product, _ := productRepo.GetByID(laptop.ID)
+	assert.Equal(t, 0, product.Stock)
+
+	_, err = reservationRepo.Reserve("order-2", []models.OrderItem{{ProductID: laptop.ID, Quantity: 1}}, time.Now().Add(time.Minute))
+	assert.Error(t, err)
+
+	expired, err := reservationRepo.ListExpiredOrderIDs(time.Now(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-1"}, expired)
+
+	released, err := reservationRepo.Release("order-1", models.ReservationStatusExpired)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+	product, _ = productRepo.GetByID(laptop.ID)
+	assert.Equal(t, 2, product.Stock)
+
+	// Releasing twice must not return the stock again
+	released, err = reservationRepo.Release("order-1", models.ReservationStatusExpired)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+
+	_, err = reservationRepo.Reserve("order-2", []models.OrderItem{{ProductID: laptop.ID, Quantity: 1}}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	committed, err := reservationRepo.Commit("order-2")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, committed)
+
+	reservations, err := reservationRepo.ListByOrder("order-2")
+	assert.NoError(t, err)
+	assert.Len(t, reservations, 1)
+	assert.Equal(t, models.ReservationStatusCommitted, reservations[0].Status)
+}
+
+func TestGORMStockReservationRepository_ReleaseItem(t *testing.T) {
+	db := setupDB(t)
+	productRepo := repositories.NewGORMProductRepository(db)
+	reservationRepo := repositories.NewGORMStockReservationRepository(db)
+
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 5}
+	assert.NoError(t, productRepo.Create(lamp))
+	_, err := reservationRepo.Reserve("order-1", []models.OrderItem{{ProductID: lamp.ID, Quantity: 2}, {ProductID: lamp.ID, Quantity: 1}}, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	_, err = reservationRepo.Commit("order-1")
+	assert.NoError(t, err)
+
+	released, err := reservationRepo.ReleaseItem("order-1", lamp.ID, 2, models.ReservationStatusReleased)
+	assert.NoError(t, err)
+	assert.True(t, released)
+	product, _ := productRepo.GetByID(lamp.ID)
+	assert.Equal(t, 4, product.Stock)
+
+	// The same item cannot be released twice
+	released, err = reservationRepo.ReleaseItem("order-1", lamp.ID, 2, models.ReservationStatusReleased)
+	assert.NoError(t, err)
+	assert.False(t, released)
+
+	// Releasing the rest of the order only returns what is still held
+	count, err := reservationRepo.Release("order-1", models.ReservationStatusReleased)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count, "committed reservations are not released by Release")
+	product, _ = productRepo.GetByID(lamp.ID)
+	assert.Equal(t, 4, product.Stock)
+}
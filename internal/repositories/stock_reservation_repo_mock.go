@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockStockReservationRepository is an in-memory implementation of StockReservationRepository.
+// It adjusts stock through the given ProductRepository.
+type MockStockReservationRepository struct {
+	productRepo  ProductRepository
+	reservations []models.StockReservation
+	nextID       uint
+	mu           sync.Mutex
+}
+
+// NewMockStockReservationRepository creates a new instance of MockStockReservationRepository.
+func NewMockStockReservationRepository(productRepo ProductRepository) *MockStockReservationRepository {
+	return &MockStockReservationRepository{
+		productRepo: productRepo,
+	}
+}
+
+// Reserve decrements stock for every item, rolling back on failure.
+func (r *MockStockReservationRepository) Reserve(orderID string, items []models.OrderItem, expiresAt time.Time) ([]models.StockReservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reserved []models.StockReservation
+	for _, item := range items {
+		product, err := r.productRepo.GetByID(item.ProductID)
+		if err == nil && product.Stock < item.Quantity {
+			err = fmt.Errorf("insufficient stock for product %s", item.ProductID)
+		}
+		if err == nil {
+			product.Stock -= item.Quantity
+			err = r.productRepo.Update(product)
+		}
+		if err != nil {
+			r.restock(reserved)
+			return nil, err
+		}
+		r.nextID++
+		reserved = append(reserved, models.StockReservation{
+			ID:        r.nextID,
+			OrderID:   orderID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Status:    models.ReservationStatusActive,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+	r.reservations = append(r.reservations, reserved...)
+	return reserved, nil
+}
+
+func (r *MockStockReservationRepository) restock(reservations []models.StockReservation) {
+	for _, reservation := range reservations {
+		if product, err := r.productRepo.GetByID(reservation.ProductID); err == nil {
+			product.Stock += reservation.Quantity
+			r.productRepo.Update(product)
+		}
+	}
+}
+
+// Release returns the stock of the order's active reservations.
+func (r *MockStockReservationRepository) Release(orderID string, status string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	released := 0
+	for i := range r.reservations {
+		reservation := &r.reservations[i]
+		if reservation.OrderID != orderID || reservation.Status != models.ReservationStatusActive {
+			continue
+		}
+		r.restock([]models.StockReservation{*reservation})
+		reservation.Status = status
+		reservation.UpdatedAt = time.Now()
+		released++
+	}
+	return released, nil
+}
+
+// Commit marks the order's active reservations as committed.
+func (r *MockStockReservationRepository) Commit(orderID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	committed := 0
+	for i := range r.reservations {
+		reservation := &r.reservations[i]
+		if reservation.OrderID == orderID && reservation.Status == models.ReservationStatusActive {
+			reservation.Status = models.ReservationStatusCommitted
+			reservation.UpdatedAt = time.Now()
+			committed++
+		}
+	}
+	return committed, nil
+}
+
+// ListByOrder returns all reservations of an order.
+func (r *MockStockReservationRepository) ListByOrder(orderID string) ([]models.StockReservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reservations []models.StockReservation
+	for _, reservation := range r.reservations {
+		if reservation.OrderID == orderID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+// ListExpiredOrderIDs returns orders with active reservations that expired before now.
+func (r *MockStockReservationRepository) ListExpiredOrderIDs(now time.Time, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var orderIDs []string
+	for _, reservation := range r.reservations {
+		if reservation.Status != models.ReservationStatusActive || !reservation.ExpiresAt.Before(now) || seen[reservation.OrderID] {
+			continue
+		}
+		seen[reservation.OrderID] = true
+		orderIDs = append(orderIDs, reservation.OrderID)
+		if limit > 0 && len(orderIDs) == limit {
+			break
+		}
+	}
+	return orderIDs, nil
+}
+
+// ReleaseItem returns the stock of one of the order's reservations for the product.
+func (r *MockStockReservationRepository) ReleaseItem(orderID, productID string, quantity int, status string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.reservations {
+		reservation := &r.reservations[i]
+		if reservation.OrderID != orderID || reservation.ProductID != productID || reservation.Quantity != quantity {
+			continue
+		}
+		if reservation.Status != models.ReservationStatusActive && reservation.Status != models.ReservationStatusCommitted {
+			continue
+		}
+		r.restock([]models.StockReservation{*reservation})
+		reservation.Status = status
+		reservation.UpdatedAt = time.Now()
+		return true, nil
+	}
+	return false, nil
+}
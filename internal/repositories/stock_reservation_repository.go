@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// StockReservationRepository defines the interface for stock reservation data access.
+// Reserving and releasing adjust product stock atomically with the reservation rows.
+type StockReservationRepository interface {
+	// Reserve decrements stock for every item and records active reservations.
+	// Either all items are reserved or none are.
+	Reserve(orderID string, items []models.OrderItem, expiresAt time.Time) ([]models.StockReservation, error)
+	// Release returns the stock of the order's active reservations and marks them with status.
+	// It returns the number of reservations released.
+	Release(orderID string, status string) (int, error)
+	// ReleaseItem returns the stock of one active or committed reservation of
+	// the order for quantity units of the product, as when a single item is
+	// cancelled, and marks it with status. It returns false if none matched.
+	ReleaseItem(orderID, productID string, quantity int, status string) (bool, error)
+	// Commit marks the order's active reservations as committed.
+	Commit(orderID string) (int, error)
+	ListByOrder(orderID string) ([]models.StockReservation, error)
+	// ListExpiredOrderIDs returns orders with active reservations that expired before now.
+	ListExpiredOrderIDs(now time.Time, limit int) ([]string, error)
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMTaskRepository is a GORM implementation of TaskRepository.
+type GORMTaskRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMTaskRepository creates a new instance of GORMTaskRepository.
+func NewGORMTaskRepository(db *gorm.DB) *GORMTaskRepository {
+	return &GORMTaskRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new task.
+func (r *GORMTaskRepository) Create(task *models.Task) error {
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	if err := r.db.Create(task).Error; err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a task by its ID.
+func (r *GORMTaskRepository) GetByID(id string) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.First(&task, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("task with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get task by ID %s: %w", id, err)
+	}
+	return &task, nil
+}
+
+// ListByStatus retrieves the most recently updated tasks with the given status.
+func (r *GORMTaskRepository) ListByStatus(status string, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := r.db.Where("status = ?", status).Order("updated_at DESC").Limit(limit).Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list %s tasks: %w", status, err)
+	}
+	return tasks, nil
+}
+
+// ClaimNext marks the oldest runnable pending task as running.
+// The conditional update makes the claim safe when several workers or API
+// instances poll the same table.
+func (r *GORMTaskRepository) ClaimNext(now time.Time) (*models.Task, error) {
+	for {
+		var task models.Task
+		err := r.db.Where("status = ? AND run_at <= ?", models.TaskStatusPending, now).
+			Order("run_at ASC").
+			First(&task).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find pending task: %w", err)
+		}
+
+		res := r.db.Model(&models.Task{}).
+			Where("id = ? AND status = ?", task.ID, models.TaskStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.TaskStatusRunning,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return nil, fmt.Errorf("failed to claim task %s: %w", task.ID, res.Error)
+		}
+		if res.RowsAffected == 1 {
+			task.Status = models.TaskStatusRunning
+			task.Attempts++
+			task.UpdatedAt = now
+			return &task, nil
+		}
+		// Another worker claimed it first; try the next one
+	}
+}
+
+// Update saves all fields of a task.
+func (r *GORMTaskRepository) Update(task *models.Task) error {
+	if err := r.db.Save(task).Error; err != nil {
+		return fmt.Errorf("failed to update task %s: %w", task.ID, err)
+	}
+	return nil
+}
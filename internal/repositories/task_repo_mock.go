@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockTaskRepository is an in-memory implementation of TaskRepository.
+type MockTaskRepository struct {
+	tasks map[string]models.Task
+	mu    sync.Mutex
+}
+
+// NewMockTaskRepository creates a new instance of MockTaskRepository.
+func NewMockTaskRepository() *MockTaskRepository {
+	return &MockTaskRepository{
+		tasks: make(map[string]models.Task),
+	}
+}
+
+// Create adds a new task.
+func (r *MockTaskRepository) Create(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	task.CreatedAt = time.Now()
+	task.UpdatedAt = task.CreatedAt
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+// GetByID returns a task by its ID.
+func (r *MockTaskRepository) GetByID(id string) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("task with ID %s not found", id)
+	}
+	return &task, nil
+}
+
+// ListByStatus returns tasks with the given status, most recently updated first.
+func (r *MockTaskRepository) ListByStatus(status string, limit int) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tasks []models.Task
+	for _, task := range r.tasks {
+		if task.Status == status {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].UpdatedAt.After(tasks[j].UpdatedAt) })
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+// ClaimNext marks the oldest runnable pending task as running.
+func (r *MockTaskRepository) ClaimNext(now time.Time) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var next *models.Task
+	for id := range r.tasks {
+		task := r.tasks[id]
+		if task.Status != models.TaskStatusPending || task.RunAt.After(now) {
+			continue
+		}
+		if next == nil || task.RunAt.Before(next.RunAt) {
+			next = &task
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.Status = models.TaskStatusRunning
+	next.Attempts++
+	next.UpdatedAt = now
+	r.tasks[next.ID] = *next
+	return next, nil
+}
+
+// Update replaces a stored task.
+func (r *MockTaskRepository) Update(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[task.ID]; !ok {
+		return fmt.Errorf("task with ID %s not found for update", task.ID)
+	}
+	task.UpdatedAt = time.Now()
+	r.tasks[task.ID] = *task
+	return nil
+}
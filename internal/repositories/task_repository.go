@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"time"
+	"toko/internal/models"
+)
+
+// TaskRepository defines the interface for durable task queue storage.
+type TaskRepository interface {
+	Create(task *models.Task) error
+	GetByID(id string) (*models.Task, error)
+	ListByStatus(status string, limit int) ([]models.Task, error)
+	// ClaimNext atomically marks the oldest runnable pending task as running and returns it.
+	// It returns nil without error when no task is ready.
+	ClaimNext(now time.Time) (*models.Task, error)
+	Update(task *models.Task) error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"fmt"
+	"time"
 	"toko/internal/models"
 
 	"github.com/google/uuid"
@@ -66,3 +67,40 @@ func (r *GORMUserRepository) GetByID(id string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+// Update saves all fields of an existing user.
+func (r *GORMUserRepository) Update(user *models.User) error {
+	if err := r.db.Save(user).Error; err != nil {
+		return fmt.Errorf("failed to update user %s: %w", user.ID, err)
+	}
+	return nil
+}
+
+// UpdateLastLogin sets when a user last logged in.
+func (r *GORMUserRepository) UpdateLastLogin(id string, at time.Time) error {
+	if err := r.db.Model(&models.User{}).Where("id = ?", id).UpdateColumn("last_login_at", at).Error; err != nil {
+		return fmt.Errorf("failed to update last login of user %s: %w", id, err)
+	}
+	return nil
+}
+
+// CountByRole returns the number of users with the given role.
+func (r *GORMUserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).Where("role = ?", role).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count users with role %s: %w", role, err)
+	}
+	return count, nil
+}
+
+// Delete soft-deletes a user.
+func (r *GORMUserRepository) Delete(id string) error {
+	res := r.db.Delete(&models.User{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete user %s: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found", id)
+	}
+	return nil
+}
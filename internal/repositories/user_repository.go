@@ -1,6 +1,9 @@
 package repositories
 
-import "toko/internal/models"
+import (
+	"time"
+	"toko/internal/models"
+)
 
 // UserRepository defines the interface for user data access.
 type UserRepository interface {
@@ -8,4 +11,12 @@ type UserRepository interface {
 	GetByUsername(username string) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByID(id string) (*models.User, error)
+	Update(user *models.User) error
+	// UpdateLastLogin sets when the user last logged in without touching
+	// the other fields.
+	UpdateLastLogin(id string, at time.Time) error
+	// CountByRole returns the number of users with the role.
+	CountByRole(role string) (int64, error)
+	// Delete soft-deletes the user.
+	Delete(id string) error
 }
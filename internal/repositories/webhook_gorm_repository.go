@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GORMWebhookRepository is a GORM implementation of WebhookRepository.
+type GORMWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMWebhookRepository creates a new instance of GORMWebhookRepository.
+func NewGORMWebhookRepository(db *gorm.DB) *GORMWebhookRepository {
+	return &GORMWebhookRepository{
+		db: db,
+	}
+}
+
+// CreateSubscription inserts a new subscription.
+func (r *GORMWebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	if err := r.db.Create(subscription).Error; err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// GetSubscription retrieves a subscription by its ID.
+func (r *GORMWebhookRepository) GetSubscription(id string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.First(&subscription, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("webhook subscription with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription %s: %w", id, err)
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions retrieves all subscriptions.
+func (r *GORMWebhookRepository) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription saves a subscription.
+func (r *GORMWebhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	if err := r.db.Save(subscription).Error; err != nil {
+		return fmt.Errorf("failed to update webhook subscription %s: %w", subscription.ID, err)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription. Its delivery history is kept.
+func (r *GORMWebhookRepository) DeleteSubscription(id string) error {
+	res := r.db.Delete(&models.WebhookSubscription{}, "id = ?", id)
+	if res.Error != nil {
+		return fmt.Errorf("failed to delete webhook subscription %s: %w", id, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("webhook subscription with ID %s not found", id)
+	}
+	return nil
+}
+
+// CreateDelivery inserts a new delivery.
+func (r *GORMWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	if err := r.db.Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDelivery retrieves a delivery with its attempts.
+func (r *GORMWebhookRepository) GetDelivery(id string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Preload("Attempts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).First(&delivery, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("webhook delivery with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery %s: %w", id, err)
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries retrieves a filtered page of deliveries, newest first.
+func (r *GORMWebhookRepository) ListDeliveries(opts WebhookDeliveryListOptions) ([]models.WebhookDelivery, int64, error) {
+	query := r.db.Model(&models.WebhookDelivery{})
+	if opts.SubscriptionID != "" {
+		query = query.Where("subscription_id = ?", opts.SubscriptionID)
+	}
+	if opts.Event != "" {
+		query = query.Where("event = ?", opts.Event)
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	var deliveries []models.WebhookDelivery
+	query = query.Order("created_at DESC").Offset(opts.Offset)
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// UpdateDelivery saves a delivery's status fields.
+func (r *GORMWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	if err := r.db.Omit("Attempts").Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery %s: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// AddAttempt records an attempt.
+func (r *GORMWebhookRepository) AddAttempt(attempt *models.WebhookAttempt) error {
+	if err := r.db.Create(attempt).Error; err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockWebhookRepository is an in-memory implementation of WebhookRepository.
+type MockWebhookRepository struct {
+	subscriptions map[string]models.WebhookSubscription
+	deliveries    map[string]models.WebhookDelivery
+	attempts      map[string][]models.WebhookAttempt
+	nextAttemptID uint
+	mu            sync.RWMutex
+}
+
+// NewMockWebhookRepository creates a new instance of MockWebhookRepository.
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		subscriptions: make(map[string]models.WebhookSubscription),
+		deliveries:    make(map[string]models.WebhookDelivery),
+		attempts:      make(map[string][]models.WebhookAttempt),
+	}
+}
+
+// CreateSubscription stores a new subscription.
+func (r *MockWebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+	r.subscriptions[subscription.ID] = *subscription
+	return nil
+}
+
+// GetSubscription returns a subscription by its ID.
+func (r *MockWebhookRepository) GetSubscription(id string) (*models.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook subscription with ID %s not found", id)
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions returns all subscriptions, oldest first.
+func (r *MockWebhookRepository) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := make([]models.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+// UpdateSubscription saves changes to an existing subscription.
+func (r *MockWebhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[subscription.ID]; !ok {
+		return fmt.Errorf("webhook subscription with ID %s not found", subscription.ID)
+	}
+	subscription.UpdatedAt = time.Now()
+	r.subscriptions[subscription.ID] = *subscription
+	return nil
+}
+
+// DeleteSubscription removes a subscription.
+func (r *MockWebhookRepository) DeleteSubscription(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return fmt.Errorf("webhook subscription with ID %s not found", id)
+	}
+	delete(r.subscriptions, id)
+	return nil
+}
+
+// CreateDelivery stores a new delivery.
+func (r *MockWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	stored := *delivery
+	stored.Attempts = nil
+	r.deliveries[delivery.ID] = stored
+	return nil
+}
+
+// GetDelivery returns a delivery with its attempts, oldest first.
+func (r *MockWebhookRepository) GetDelivery(id string) (*models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook delivery with ID %s not found", id)
+	}
+	delivery.Attempts = append([]models.WebhookAttempt(nil), r.attempts[id]...)
+	return &delivery, nil
+}
+
+// ListDeliveries returns matching deliveries, newest first, and the total number of matches.
+func (r *MockWebhookRepository) ListDeliveries(opts WebhookDeliveryListOptions) ([]models.WebhookDelivery, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if opts.SubscriptionID != "" && delivery.SubscriptionID != opts.SubscriptionID {
+			continue
+		}
+		if opts.Event != "" && delivery.Event != opts.Event {
+			continue
+		}
+		if opts.Status != "" && delivery.Status != opts.Status {
+			continue
+		}
+		matches = append(matches, delivery)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].CreatedAt.After(matches[j].CreatedAt)
+	})
+
+	total := int64(len(matches))
+	if opts.Offset >= len(matches) {
+		return []models.WebhookDelivery{}, total, nil
+	}
+	matches = matches[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(matches) {
+		matches = matches[:opts.Limit]
+	}
+	return matches, total, nil
+}
+
+// UpdateDelivery saves changes to an existing delivery.
+func (r *MockWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		return fmt.Errorf("webhook delivery with ID %s not found", delivery.ID)
+	}
+	delivery.UpdatedAt = time.Now()
+	stored := *delivery
+	stored.Attempts = nil
+	r.deliveries[delivery.ID] = stored
+	return nil
+}
+
+// AddAttempt records an attempt for a delivery.
+func (r *MockWebhookRepository) AddAttempt(attempt *models.WebhookAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextAttemptID++
+	attempt.ID = r.nextAttemptID
+	attempt.CreatedAt = time.Now()
+	r.attempts[attempt.DeliveryID] = append(r.attempts[attempt.DeliveryID], *attempt)
+	return nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import "toko/internal/models"
+
+// WebhookDeliveryListOptions filters and paginates delivery listings. Zero values mean "no filter".
+type WebhookDeliveryListOptions struct {
+	SubscriptionID string
+	Event          string
+	Status         string
+	Limit          int
+	Offset         int
+}
+
+// WebhookRepository defines the interface for outbound webhook data access.
+type WebhookRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	GetSubscription(id string) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+	DeleteSubscription(id string) error
+
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	// GetDelivery returns a delivery with its attempts, oldest first.
+	GetDelivery(id string) (*models.WebhookDelivery, error)
+	// ListDeliveries returns matching deliveries without attempts, newest first, and the total number of matches.
+	ListDeliveries(opts WebhookDeliveryListOptions) ([]models.WebhookDelivery, int64, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	AddAttempt(attempt *models.WebhookAttempt) error
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"fmt"
+	"toko/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMWishlistRepository is a GORM implementation of WishlistRepository.
+type GORMWishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMWishlistRepository creates a new instance of GORMWishlistRepository.
+func NewGORMWishlistRepository(db *gorm.DB) *GORMWishlistRepository {
+	return &GORMWishlistRepository{
+		db: db,
+	}
+}
+
+// List returns the user's wishlist, most recently added first.
+func (r *GORMWishlistRepository) List(userID string) ([]models.WishlistItem, error) {
+	var items []models.WishlistItem
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC, product_id").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list wishlist of user %s: %w", userID, err)
+	}
+	return items, nil
+}
+
+// Add saves an item, keeping the original one if the product is already listed.
+func (r *GORMWishlistRepository) Add(item *models.WishlistItem) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error; err != nil {
+		return fmt.Errorf("failed to add product %s to wishlist: %w", item.ProductID, err)
+	}
+	return nil
+}
+
+// Remove removes a product from the user's wishlist.
+func (r *GORMWishlistRepository) Remove(userID, productID string) error {
+	res := r.db.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.WishlistItem{})
+	if res.Error != nil {
+		return fmt.Errorf("failed to remove product %s from wishlist: %w", productID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("product %s not found in wishlist", productID)
+	}
+	return nil
+}
+
+// Count returns how many products the user's wishlist holds.
+func (r *GORMWishlistRepository) Count(userID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.WishlistItem{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count wishlist of user %s: %w", userID, err)
+	}
+	return count, nil
+}
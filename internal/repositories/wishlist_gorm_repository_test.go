@@ -0,0 +1,34 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGORMWishlistRepository_AddListRemove(t *testing.T) {
+	db := setupDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.WishlistItem{}))
+	repo := repositories.NewGORMWishlistRepository(db)
+
+	assert.NoError(t, repo.Add(&models.WishlistItem{UserID: "u1", ProductID: "p1"}))
+	assert.NoError(t, repo.Add(&models.WishlistItem{UserID: "u1", ProductID: "p1"}))
+	assert.NoError(t, repo.Add(&models.WishlistItem{UserID: "u1", ProductID: "p2"}))
+	assert.NoError(t, repo.Add(&models.WishlistItem{UserID: "u2", ProductID: "p1"}))
+
+	items, err := repo.List("u1")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	count, err := repo.Count("u1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, repo.Remove("u1", "p1"))
+	assert.ErrorContains(t, repo.Remove("u1", "p1"), "not found")
+	count, err = repo.Count("u2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
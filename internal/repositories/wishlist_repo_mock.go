@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"toko/internal/models"
+)
+
+// MockWishlistRepository is an in-memory implementation of WishlistRepository.
+type MockWishlistRepository struct {
+	items map[string]models.WishlistItem // Keyed by user ID and product ID
+	mu    sync.RWMutex
+}
+
+// NewMockWishlistRepository creates a new instance of MockWishlistRepository.
+func NewMockWishlistRepository() *MockWishlistRepository {
+	return &MockWishlistRepository{
+		items: make(map[string]models.WishlistItem),
+	}
+}
+
+func wishlistKey(userID, productID string) string {
+	return userID + "/" + productID
+}
+
+// List returns the user's wishlist, most recently added first.
+func (r *MockWishlistRepository) List(userID string) ([]models.WishlistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := []models.WishlistItem{}
+	for _, item := range r.items {
+		if item.UserID == userID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.After(items[j].CreatedAt)
+		}
+		return items[i].ProductID < items[j].ProductID
+	})
+	return items, nil
+}
+
+// Add saves an item, keeping the original one if the product is already listed.
+func (r *MockWishlistRepository) Add(item *models.WishlistItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := wishlistKey(item.UserID, item.ProductID)
+	if _, ok := r.items[key]; ok {
+		return nil
+	}
+	item.CreatedAt = time.Now()
+	r.items[key] = *item
+	return nil
+}
+
+// Remove removes a product from the user's wishlist.
+func (r *MockWishlistRepository) Remove(userID, productID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := wishlistKey(userID, productID)
+	if _, ok := r.items[key]; !ok {
+		return fmt.Errorf("product %s not found in wishlist", productID)
+	}
+	delete(r.items, key)
+	return nil
+}
+
+// Count returns how many products the user's wishlist holds.
+func (r *MockWishlistRepository) Count(userID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, item := range r.items {
+		if item.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import "toko/internal/models"
+
+// WishlistRepository defines the interface for wishlist data access.
+type WishlistRepository interface {
+	// List returns the user's wishlist, most recently added first.
+	List(userID string) ([]models.WishlistItem, error)
+	// Add saves an item, keeping the original one if the product is already listed.
+	Add(item *models.WishlistItem) error
+	Remove(userID, productID string) error
+	Count(userID string) (int64, error)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountService handles requests of users about their account as a whole.
+type AccountService struct {
+	userRepo    repositories.UserRepository
+	orderRepo   repositories.OrderRepository
+	addressRepo repositories.AddressRepository
+	authService *AuthService
+	mqClient    Publisher // RabbitMQ client for user events
+}
+
+// NewAccountService creates a new AccountService.
+func NewAccountService(userRepo repositories.UserRepository, orderRepo repositories.OrderRepository, addressRepo repositories.AddressRepository, authService *AuthService, mqClient Publisher) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		orderRepo:   orderRepo,
+		addressRepo: addressRepo,
+		authService: authService,
+		mqClient:    mqClient,
+	}
+}
+
+// DeleteAccount erases a user's account after checking their password: the
+// personal data on the account and their orders is cleared, the address book
+// emptied, every login ended and forgotten and the account soft-deleted.
+// Orders are kept, without personal data, for the store's books. A
+// user.deleted event tells other systems to erase what they hold about the
+// user.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID, password string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == models.RoleAdmin {
+		return fmt.Errorf("invalid account: admin accounts cannot be deleted")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return fmt.Errorf("invalid password")
+	}
+
+	if _, err := s.orderRepo.AnonymizeByUser(userID); err != nil {
+		return err
+	}
+	addresses, err := s.addressRepo.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if err := s.addressRepo.Delete(userID, address.ID); err != nil {
+			return err
+		}
+	}
+
+	// Free the username and email for reuse and leave nothing to log in with
+	user.Username = "deleted-" + user.ID
+	user.Email = "deleted-" + user.ID + "@deleted.invalid"
+	user.PendingEmail = ""
+	user.Password = ""
+	user.Permissions = ""
+	user.PreferredCurrency, user.Locale, user.ShippingCountry = "", "", ""
+	user.LastLoginAt = nil
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to anonymize user %s: %w", userID, err)
+	}
+	if err := s.authService.EndAllLogins(userID); err != nil {
+		return err
+	}
+	if err := s.authService.DeleteLoginHistory(userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(userID); err != nil {
+		return err
+	}
+
+	log.Printf("Deleted the account of user %s", userID)
+	publishEvent(ctx, s.mqClient, "user", "user.deleted", map[string]interface{}{
+		"userID":    userID,
+		"deletedAt": time.Now(),
+	})
+	return nil
+}
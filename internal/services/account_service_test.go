@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountService_DeleteAccount(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	orderRepo := repositories.NewMockOrderRepository()
+	addressRepo := repositories.NewMockAddressRepository()
+	authService := services.NewAuthService(userRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(repositories.NewMockRefreshTokenRepository(), 15*time.Minute, time.Hour)
+	authService.SetRevocation(repositories.NewMockRevokedTokenRepository())
+	authService.SetSessions(repositories.NewMockSessionRepository())
+	service := services.NewAccountService(userRepo, orderRepo, addressRepo, authService, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "budi", Email: "budi@example.com", Password: string(hashedPassword), Role: models.RoleCustomer}
+	userRepo.On("GetByUsername", "budi").Return(user, nil)
+	userRepo.On("GetByID", "user-123").Return(user, nil)
+	userRepo.On("Update", user).Return(nil)
+	userRepo.On("Delete", "user-123").Return(nil)
+
+	address := models.PostalAddress{RecipientName: "Budi", Phone: "0812", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "ID"}
+	order := &models.Order{UserID: "user-123", Status: "delivered", ShippingAddress: address, BillingAddress: address}
+	require.NoError(t, orderRepo.Create(order))
+	require.NoError(t, addressRepo.Create(&models.Address{UserID: "user-123", PostalAddress: address}))
+
+	login, err := authService.Login("budi", "password123")
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, service.DeleteAccount(context.Background(), "user-123", "wrong"), "invalid password")
+	userRepo.AssertNotCalled(t, "Delete", "user-123")
+
+	require.NoError(t, service.DeleteAccount(context.Background(), "user-123", "password123"))
+	userRepo.AssertCalled(t, "Delete", "user-123")
+	assert.Equal(t, "deleted-user-123", user.Username)
+	assert.NotContains(t, user.Email, "budi")
+	assert.Empty(t, user.Password)
+
+	found, err := orderRepo.GetByID(order.ID)
+	require.NoError(t, err)
+	assert.Empty(t, found.ShippingAddress.RecipientName)
+	assert.Empty(t, found.BillingAddress.Line1)
+	assert.Equal(t, "Jakarta", found.ShippingAddress.City)
+	addresses, err := addressRepo.ListByUser("user-123")
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+
+	_, err = authService.ValidateToken(login.AccessToken)
+	assert.ErrorContains(t, err, "revoked")
+	_, err = authService.Refresh(login.RefreshToken)
+	assert.ErrorContains(t, err, "invalid refresh token")
+}
+
+func TestAccountService_DeleteAccountRefusesAdmins(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	authService := services.NewAuthService(userRepo, "test_jwt_secret")
+	service := services.NewAccountService(userRepo, repositories.NewMockOrderRepository(), repositories.NewMockAddressRepository(), authService, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	userRepo.On("GetByID", "admin-1").Return(&models.User{ID: "admin-1", Role: models.RoleAdmin, Password: string(hashedPassword)}, nil)
+
+	assert.ErrorContains(t, service.DeleteAccount(context.Background(), "admin-1", "password123"), "invalid account")
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// AddressService manages users' address books and resolves the addresses used at checkout.
+type AddressService struct {
+	repo repositories.AddressRepository
+}
+
+// NewAddressService creates a new AddressService.
+func NewAddressService(repo repositories.AddressRepository) *AddressService {
+	return &AddressService{
+		repo: repo,
+	}
+}
+
+// ListAddresses returns the user's address book.
+func (s *AddressService) ListAddresses(userID string) ([]models.Address, error) {
+	return s.repo.ListByUser(userID)
+}
+
+// GetAddress returns one of the user's addresses.
+func (s *AddressService) GetAddress(userID, id string) (*models.Address, error) {
+	return s.repo.GetByID(userID, id)
+}
+
+// CreateAddress adds an address to the user's address book. The first
+// address automatically becomes the default.
+func (s *AddressService) CreateAddress(userID string, address *models.Address) error {
+	address.ID = ""
+	address.UserID = userID
+	address.Country = strings.ToUpper(address.Country)
+	if err := address.Validate(); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+
+	existing, err := s.repo.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+	makeDefault := address.IsDefault || len(existing) == 0
+	address.IsDefault = false
+	if err := s.repo.Create(address); err != nil {
+		return err
+	}
+	if makeDefault {
+		if err := s.repo.SetDefault(userID, address.ID); err != nil {
+			return err
+		}
+		address.IsDefault = true
+	}
+	return nil
+}
+
+// UpdateAddress replaces the fields of one of the user's addresses.
+func (s *AddressService) UpdateAddress(userID, id string, changes *models.Address) (*models.Address, error) {
+	address, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	address.Label = changes.Label
+	address.PostalAddress = changes.PostalAddress
+	address.Country = strings.ToUpper(address.Country)
+	if err := address.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	if err := s.repo.Update(address); err != nil {
+		return nil, err
+	}
+	if changes.IsDefault && !address.IsDefault {
+		if err := s.repo.SetDefault(userID, id); err != nil {
+			return nil, err
+		}
+		address.IsDefault = true
+	}
+	return address, nil
+}
+
+// DeleteAddress removes one of the user's addresses. When the default is
+// removed, the most recently added remaining address becomes the default.
+func (s *AddressService) DeleteAddress(userID, id string) error {
+	address, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(userID, id); err != nil {
+		return err
+	}
+	if !address.IsDefault {
+		return nil
+	}
+	remaining, err := s.repo.ListByUser(userID)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+	return s.repo.SetDefault(userID, remaining[0].ID)
+}
+
+// ResolveOrderAddress returns the address to copy onto an order: the user's
+// saved address with addressID when given, otherwise the inline address,
+// otherwise (when useDefault is set) the user's default address.
+func (s *AddressService) ResolveOrderAddress(userID, addressID string, inline models.PostalAddress, useDefault bool) (models.PostalAddress, error) {
+	if addressID != "" {
+		address, err := s.repo.GetByID(userID, addressID)
+		if err != nil {
+			return models.PostalAddress{}, err
+		}
+		return address.PostalAddress, nil
+	}
+	if !inline.IsZero() || !useDefault {
+		return inline, nil
+	}
+
+	addresses, err := s.repo.ListByUser(userID)
+	if err != nil {
+		return models.PostalAddress{}, err
+	}
+	if len(addresses) > 0 && addresses[0].IsDefault {
+		return addresses[0].PostalAddress, nil
+	}
+	return models.PostalAddress{}, nil
+}
@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPostalAddress(city string) models.PostalAddress {
+	return models.PostalAddress{RecipientName: "Budi", Line1: "Jl. Merdeka 1", City: city, PostalCode: "10110", Country: "id"}
+}
+
+func TestAddressService_DefaultAddressHandling(t *testing.T) {
+	service := services.NewAddressService(repositories.NewMockAddressRepository())
+
+	// The first address becomes the default automatically
+	home := &models.Address{Label: "Home", PostalAddress: testPostalAddress("Jakarta")}
+	require.NoError(t, service.CreateAddress("user-1", home))
+	assert.True(t, home.IsDefault)
+	assert.Equal(t, "ID", home.Country)
+
+	office := &models.Address{Label: "Office", PostalAddress: testPostalAddress("Bandung"), IsDefault: true}
+	require.NoError(t, service.CreateAddress("user-1", office))
+
+	addresses, err := service.ListAddresses("user-1")
+	require.NoError(t, err)
+	require.Len(t, addresses, 2)
+	assert.Equal(t, office.ID, addresses[0].ID)
+	assert.False(t, addresses[1].IsDefault)
+
+	// Other users cannot see or use the address
+	_, err = service.GetAddress("user-2", office.ID)
+	assert.Error(t, err)
+	_, err = service.ResolveOrderAddress("user-2", office.ID, models.PostalAddress{}, true)
+	assert.Error(t, err)
+
+	// Orders without an address fall back to the default for shipping only
+	shipping, err := service.ResolveOrderAddress("user-1", "", models.PostalAddress{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Bandung", shipping.City)
+	billing, err := service.ResolveOrderAddress("user-1", "", models.PostalAddress{}, false)
+	require.NoError(t, err)
+	assert.True(t, billing.IsZero())
+
+	// Deleting the default promotes the remaining address
+	require.NoError(t, service.DeleteAddress("user-1", office.ID))
+	remaining, err := service.GetAddress("user-1", home.ID)
+	require.NoError(t, err)
+	assert.True(t, remaining.IsDefault)
+
+	// Incomplete addresses are rejected
+	err = service.CreateAddress("user-1", &models.Address{PostalAddress: models.PostalAddress{City: "Surabaya"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recipient_name")
+}
+
+func TestOrderService_CreateOrderRequiresShippingAddress(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Laptop", Price: 1200, Stock: 3}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 0)
+
+	request := models.Order{UserID: "user-1", Items: []models.OrderItem{{ProductID: product.ID, Quantity: 1}}}
+	_, err := orderService.CreateOrder(context.Background(), request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shipping address")
+
+	request.ShippingAddress = testPostalAddress("Jakarta")
+	order, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "ID", order.ShippingAddress.Country)
+	assert.Equal(t, order.ShippingAddress, order.BillingAddress)
+}
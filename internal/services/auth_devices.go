@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// SetDeviceTokens lets users keep a device logged in for ttl: a device
+// token, bound to the device's fingerprint, gets it a new login whenever
+// its refresh token has run out.
+func (s *AuthService) SetDeviceTokens(repo repositories.DeviceTokenRepository, ttl time.Duration) {
+	s.deviceRepo = repo
+	s.deviceDurat = ttl
+}
+
+// RememberDevice issues a device token to the user for the device with the
+// fingerprint, and returns the token.
+func (s *AuthService) RememberDevice(userID, fingerprint, name string) (string, error) {
+	if s.deviceRepo == nil {
+		return "", fmt.Errorf("device tokens are not enabled")
+	}
+	fingerprint = strings.TrimSpace(fingerprint)
+	if fingerprint == "" {
+		return "", fmt.Errorf("invalid device: a fingerprint is required")
+	}
+	name = strings.TrimSpace(name)
+	if len([]rune(name)) > 100 {
+		name = string([]rune(name)[:100]) // The column's size
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate device token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.deviceRepo.Create(&models.DeviceToken{
+		UserID:          userID,
+		Name:            name,
+		TokenHash:       hashToken(token),
+		FingerprintHash: hashToken(fingerprint),
+		ExpiresAt:       time.Now().Add(s.deviceDurat),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// LoginWithDevice starts a new login of the user a device token belongs to
+// when the fingerprint is that of the device it was issued to.
+func (s *AuthService) LoginWithDevice(deviceToken, fingerprint string, device DeviceInfo) (*TokenPair, error) {
+	if s.deviceRepo == nil {
+		return nil, fmt.Errorf("invalid device token: device tokens are not enabled")
+	}
+	stored, err := s.deviceRepo.GetByHash(hashToken(deviceToken))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("invalid device token")
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if stored.RevokedAt != nil {
+		return nil, fmt.Errorf("invalid device token: revoked")
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, fmt.Errorf("invalid device token: expired")
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(strings.TrimSpace(fingerprint))), []byte(stored.FingerprintHash)) != 1 {
+		return nil, fmt.Errorf("invalid device token: issued to another device")
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil || user.Role == models.RoleGuest {
+		return nil, fmt.Errorf("invalid device token")
+	}
+	if s.betaAccess != nil && user.Role != models.RoleAdmin && !s.betaAccess.IsInvited(user.Email) {
+		return nil, fmt.Errorf("account is not invited to the beta")
+	}
+	if err := s.deviceRepo.Touch(stored.ID, now); err != nil {
+		return nil, err
+	}
+	familyID := uuid.New().String()
+	if err := s.startSession(user, familyID, device); err != nil {
+		return nil, err
+	}
+	if err := s.recordLogin(user, familyID, device); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, familyID)
+}
+
+// ListDevices returns the devices the user keeps logged in.
+func (s *AuthService) ListDevices(userID string) ([]models.DeviceToken, error) {
+	if s.deviceRepo == nil {
+		return []models.DeviceToken{}, nil
+	}
+	return s.deviceRepo.ListActiveByUser(userID, time.Now())
+}
+
+// ForgetDevice revokes the device token of one of the user's devices. Its
+// current login, if any, runs until it is revoked or expires.
+func (s *AuthService) ForgetDevice(userID string, id uint) error {
+	if s.deviceRepo == nil {
+		return fmt.Errorf("device with ID %d not found", id)
+	}
+	return s.deviceRepo.Revoke(userID, id, time.Now())
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/pkg/signedurl"
+)
+
+// emailChange is the payload of an email change confirmation token.
+type emailChange struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// SetEmailChanges makes users confirm a new email before it replaces the
+// old one. The new address gets a link to confirmURL carrying a token signed
+// with signer and valid for ttl, and the old address a notice of the change.
+func (s *AuthService) SetEmailChanges(signer *signedurl.Signer, notifications *NotificationService, ttl time.Duration, confirmURL string) {
+	s.emailSigner = signer
+	s.emailNotify = notifications
+	s.emailDurat = ttl
+	s.emailURL = confirmURL
+}
+
+// requestEmailChange sends the link confirming the user's pending email,
+// and tells their current address about it.
+func (s *AuthService) requestEmailChange(user *models.User, email string) error {
+	payload, err := json.Marshal(emailChange{UserID: user.ID, Email: email})
+	if err != nil {
+		return fmt.Errorf("failed to encode email change: %w", err)
+	}
+	expiresAt := time.Now().Add(s.emailDurat)
+	token := s.emailSigner.SignToken(payload, expiresAt)
+	return s.emailNotify.QueueEmailChange(user.ID, user.Email, email, s.emailURL+"?token="+token, expiresAt)
+}
+
+// ConfirmEmailChange replaces the email of a user with the pending one the
+// token was sent to. A token only works while its address is still the
+// pending one, so it cannot be used again, nor after a later request.
+func (s *AuthService) ConfirmEmailChange(token string) (*models.User, error) {
+	if s.emailSigner == nil {
+		return nil, fmt.Errorf("invalid email change token: confirming email changes is not enabled")
+	}
+	payload, err := s.emailSigner.VerifyToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email change token: %w", err)
+	}
+	var change emailChange
+	if err := json.Unmarshal(payload, &change); err != nil || change.UserID == "" || change.Email == "" {
+		return nil, fmt.Errorf("invalid email change token")
+	}
+
+	user, err := s.userRepo.GetByID(change.UserID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("invalid email change token")
+		}
+		return nil, err
+	}
+	if user.PendingEmail == "" || !strings.EqualFold(user.PendingEmail, change.Email) {
+		return nil, fmt.Errorf("invalid email change token: already used or replaced")
+	}
+	// The address may have been registered since the change was asked for
+	if existing, err := s.userRepo.GetByEmail(change.Email); err == nil && existing != nil && existing.ID != user.ID {
+		return nil, fmt.Errorf("email '%s' already registered", change.Email)
+	}
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to change email of user %s: %w", user.ID, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// TokenTypeGuest is the token_type claim of guest tokens, which let
+// shoppers who have not logged in browse the catalog. They are not access
+// tokens, so ValidateToken rejects them.
+const TokenTypeGuest = "guest"
+
+// GuestToken is a token anonymous shoppers browse the catalog with. GuestID
+// attributes their requests, e.g. to throttle them, without an account.
+type GuestToken struct {
+	Token     string    `json:"token"`
+	GuestID   string    `json:"guest_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SetGuestTokens lets anyone get a guest token valid for ttl.
+func (s *AuthService) SetGuestTokens(ttl time.Duration) {
+	s.guestDurat = ttl
+}
+
+// IssueGuestToken returns a guest token with a new guest ID.
+func (s *AuthService) IssueGuestToken() (*GuestToken, error) {
+	if s.guestDurat <= 0 {
+		return nil, fmt.Errorf("guest tokens are not enabled")
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.guestDurat)
+	guestID := uuid.New().String()
+	tokenString, err := s.signToken(jwt.MapClaims{
+		"guest_id":   guestID,
+		"token_type": TokenTypeGuest,
+		"exp":        expiresAt.Unix(),
+		"iat":        now.Unix(),
+		"jti":        uuid.New().String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GuestToken{Token: tokenString, GuestID: guestID, ExpiresAt: expiresAt}, nil
+}
+
+// ValidateGuestToken returns the guest ID of a guest token. Access tokens
+// are rejected with "not a guest token", so callers can tell them apart.
+func (s *AuthService) ValidateGuestToken(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, s.keys.verifying)
+	if err != nil {
+		return "", fmt.Errorf("invalid token: %w", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", fmt.Errorf("invalid token")
+	}
+	if claims["token_type"] != TokenTypeGuest {
+		return "", fmt.Errorf("invalid token: not a guest token")
+	}
+	guestID, _ := claims["guest_id"].(string)
+	if guestID == "" {
+		return "", fmt.Errorf("invalid token: no guest ID")
+	}
+	return guestID, nil
+}
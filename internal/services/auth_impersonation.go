@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// maxImpersonationReasonLength is the longest reason an admin can give.
+const maxImpersonationReasonLength = 500
+
+// ImpersonationToken is an access token an admin acts as a customer with.
+type ImpersonationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SetImpersonation lets admins get access tokens valid for ttl to act as
+// customers, recording each in repo. The tokens carry an impersonator
+// claim with the admin's ID and cannot be refreshed.
+func (s *AuthService) SetImpersonation(repo repositories.ImpersonationRepository, ttl time.Duration) {
+	s.impersonRepo = repo
+	s.impersonTTL = ttl
+}
+
+// Impersonate issues an access token of the customer to the admin, after
+// recording why and from where.
+func (s *AuthService) Impersonate(adminID, userID, reason, ipAddress string) (*ImpersonationToken, error) {
+	if s.impersonRepo == nil {
+		return nil, fmt.Errorf("impersonation is not enabled")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > maxImpersonationReasonLength {
+		return nil, fmt.Errorf("invalid reason: must be 1 to %d characters", maxImpersonationReasonLength)
+	}
+	if adminID == userID {
+		return nil, fmt.Errorf("invalid user: admins cannot impersonate themselves")
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != models.RoleCustomer {
+		return nil, fmt.Errorf("invalid user: only customers can be impersonated, not %s accounts", user.Role)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.impersonTTL)
+	jti := uuid.New().String()
+	if err := s.impersonRepo.Create(&models.Impersonation{
+		AdminID:   adminID,
+		UserID:    user.ID,
+		Reason:    reason,
+		IPAddress: ipAddress,
+		TokenID:   jti,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	tokenString, err := s.signToken(jwt.MapClaims{
+		"user_id":      user.ID,
+		"username":     user.Username,
+		"role":         user.Role,
+		"token_type":   TokenTypeAccess,
+		"impersonator": adminID, // Marks the session as an admin acting as the user
+		"exp":          expiresAt.Unix(),
+		"iat":          now.Unix(),
+		"jti":          jti,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Admin %s is impersonating user %s until %s: %s", adminID, user.ID, expiresAt.Format(time.RFC3339), reason)
+	return &ImpersonationToken{Token: tokenString, ExpiresAt: expiresAt}, nil
+}
+
+// Impersonations returns the times the user was impersonated, newest first.
+func (s *AuthService) Impersonations(userID string) ([]models.Impersonation, error) {
+	if s.impersonRepo == nil {
+		return []models.Impersonation{}, nil
+	}
+	return s.impersonRepo.ListByUser(userID)
+}
+
+// RecordImpersonatedAction records a change an admin made while acting as
+// a customer. It does nothing when impersonation is not enabled.
+func (s *AuthService) RecordImpersonatedAction(action *models.ImpersonationAction) error {
+	if s.impersonRepo == nil {
+		return nil
+	}
+	return s.impersonRepo.CreateAction(action)
+}
+
+// ImpersonatedActions returns the changes made while impersonating the
+// user, newest first.
+func (s *AuthService) ImpersonatedActions(userID string) ([]models.ImpersonationAction, error) {
+	if s.impersonRepo == nil {
+		return []models.ImpersonationAction{}, nil
+	}
+	return s.impersonRepo.ListActionsByUser(userID)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// maxLoginHistory is how many of a user's latest logins are shown.
+const maxLoginHistory = 20
+
+// SetLoginHistory records every successful login, and the time of the
+// latest on the user, keeping the records for retention. A zero retention
+// keeps them until the account is deleted.
+func (s *AuthService) SetLoginHistory(repo repositories.LoginHistoryRepository, retention time.Duration) {
+	s.loginRepo = repo
+	s.loginKeep = retention
+}
+
+// LoginHistory returns the latest logins of the user, newest first.
+func (s *AuthService) LoginHistory(userID string) ([]models.LoginEvent, error) {
+	if s.loginRepo == nil {
+		return []models.LoginEvent{}, nil
+	}
+	return s.loginRepo.ListByUser(userID, maxLoginHistory)
+}
+
+// DeleteLoginHistory forgets the logins of the user.
+func (s *AuthService) DeleteLoginHistory(userID string) error {
+	if s.loginRepo == nil {
+		return nil
+	}
+	return s.loginRepo.DeleteByUser(userID)
+}
+
+// recordLogin adds a login of the user to their history.
+func (s *AuthService) recordLogin(user *models.User, sessionID string, device DeviceInfo) error {
+	if s.loginRepo == nil {
+		return nil
+	}
+	userAgent := device.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255] // The column's size
+	}
+	now := time.Now()
+	if err := s.loginRepo.Create(&models.LoginEvent{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		UserAgent: userAgent,
+		IPAddress: device.IPAddress,
+		CreatedAt: now,
+	}); err != nil {
+		return err
+	}
+	user.LastLoginAt = &now
+	return s.userRepo.UpdateLastLogin(user.ID, now)
+}
@@ -1,41 +1,291 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"toko/internal/models"
 	"toko/internal/repositories"
+	"toko/pkg/signedurl"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// TokenPair is what a login or refresh returns. RefreshToken is empty when
+// refresh tokens are not enabled.
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
 // AuthService handles business logic for authentication and authorization.
 type AuthService struct {
-	userRepo   repositories.UserRepository
-	jwtSecret  []byte
-	tokenDurat time.Duration // Duration for which JWT is valid
+	userRepo     repositories.UserRepository
+	keys         keySet
+	tokenDurat   time.Duration // Duration for which JWT is valid
+	betaAccess   *BetaAccessService
+	refreshRepo  repositories.RefreshTokenRepository
+	refreshDurat time.Duration
+	revokedRepo  repositories.RevokedTokenRepository
+	rolePerms    map[string][]string // Admins hold every permission regardless
+	resetRepo    repositories.PasswordResetRepository
+	resetNotify  *NotificationService
+	resetDurat   time.Duration
+	resetURL     string
+	sessionRepo  repositories.SessionRepository
+	loginRepo    repositories.LoginHistoryRepository
+	loginKeep    time.Duration
+	impersonRepo repositories.ImpersonationRepository
+	impersonTTL  time.Duration
+	deviceRepo   repositories.DeviceTokenRepository
+	deviceDurat  time.Duration
+	emailSigner  *signedurl.Signer
+	emailNotify  *NotificationService
+	emailDurat   time.Duration
+	emailURL     string
+	guestDurat   time.Duration
 }
 
-// NewAuthService creates a new AuthService.
+// TokenTypeAccess is the token_type claim of the access tokens sent with
+// API requests. ValidateToken rejects tokens of any other type.
+const TokenTypeAccess = "access"
+
+// minPasswordLength is the shortest password accepted, as at registration.
+const minPasswordLength = 6
+
+// NewAuthService creates a new AuthService signing tokens with HS256 using
+// jwtSecret until signing keys are set.
 func NewAuthService(userRepo repositories.UserRepository, jwtSecret string) *AuthService {
 	return &AuthService{
 		userRepo:   userRepo,
-		jwtSecret:  []byte(jwtSecret),
+		keys:       keySet{keys: []SigningKey{NewHMACKey("", jwtSecret)}},
 		tokenDurat: 24 * time.Hour, // Token valid for 24 hours
 	}
 }
 
+// SetBetaAccess restricts registration and login to invited emails while beta mode is on.
+func (s *AuthService) SetBetaAccess(betaAccess *BetaAccessService) {
+	s.betaAccess = betaAccess
+}
+
+// SetRefreshTokens issues access tokens valid for accessTTL along with
+// rotating refresh tokens valid for refreshTTL.
+func (s *AuthService) SetRefreshTokens(repo repositories.RefreshTokenRepository, accessTTL, refreshTTL time.Duration) {
+	s.refreshRepo = repo
+	s.tokenDurat = accessTTL
+	s.refreshDurat = refreshTTL
+}
+
+// SetRevocation lets access tokens be revoked before they expire, which
+// ValidateToken then checks on every request.
+func (s *AuthService) SetRevocation(repo repositories.RevokedTokenRepository) {
+	s.revokedRepo = repo
+}
+
+// SetPasswordResets lets users who forgot their password get an email with
+// a link to resetURL that carries a single-use token valid for ttl.
+func (s *AuthService) SetPasswordResets(repo repositories.PasswordResetRepository, notifications *NotificationService, ttl time.Duration, resetURL string) {
+	s.resetRepo = repo
+	s.resetNotify = notifications
+	s.resetDurat = ttl
+	s.resetURL = resetURL
+}
+
+// SetRolePermissions sets the admin API permissions each role holds.
+func (s *AuthService) SetRolePermissions(rolePermissions map[string][]string) error {
+	for role, permissions := range rolePermissions {
+		for _, permission := range permissions {
+			if !models.IsKnownPermission(permission) {
+				return fmt.Errorf("invalid permission %q for role %s", permission, role)
+			}
+		}
+	}
+	s.rolePerms = rolePermissions
+	return nil
+}
+
+// HasPermission reports whether the user holds the permission, through
+// their role or a personal grant.
+func (s *AuthService) HasPermission(userID, permission string) (bool, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return s.userHasPermission(user, permission), nil
+}
+
+func (s *AuthService) userHasPermission(user *models.User, permission string) bool {
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+	if user.Role == models.RoleGuest {
+		return false
+	}
+	for _, grants := range [][]string{s.rolePerms[user.Role], user.PermissionList()} {
+		for _, granted := range grants {
+			if models.PermissionGrants(granted, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetUserAccess changes a user's role to customer or support and replaces
+// their personal permission grants. Admins are not made or unmade here.
+func (s *AuthService) SetUserAccess(userID, role string, permissions []string) (*models.User, error) {
+	if role != models.RoleCustomer && role != models.RoleSupport {
+		return nil, fmt.Errorf("invalid role %q: must be %s or %s", role, models.RoleCustomer, models.RoleSupport)
+	}
+	for _, permission := range permissions {
+		if permission == models.PermissionAll || !models.IsKnownPermission(permission) {
+			return nil, fmt.Errorf("invalid permission %q", permission)
+		}
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin || user.Role == models.RoleGuest {
+		return nil, fmt.Errorf("invalid user: the access of %s accounts cannot be changed", user.Role)
+	}
+	user.Role = role
+	user.Permissions = strings.Join(permissions, ",")
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to update access of user %s: %w", userID, err)
+	}
+	return user, nil
+}
+
+// BootstrapAdmin creates an admin account with the credentials when the
+// store has no admin yet, so a fresh deployment can reach the admin API.
+// It returns nil once any admin exists, leaving the credentials unused.
+func (s *AuthService) BootstrapAdmin(username, email, password string) (*models.User, error) {
+	admins, err := s.userRepo.CountByRole(models.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count admins: %w", err)
+	}
+	if admins > 0 {
+		return nil, nil
+	}
+	if username == "" || email == "" {
+		return nil, fmt.Errorf("invalid bootstrap admin: username and email are required")
+	}
+	if len(password) < minPasswordLength {
+		return nil, fmt.Errorf("invalid bootstrap admin: password must be at least %d characters", minPasswordLength)
+	}
+	if existing, err := s.userRepo.GetByUsername(username); err == nil && existing != nil {
+		return nil, fmt.Errorf("username '%s' already taken", username)
+	}
+	if existing, err := s.userRepo.GetByEmail(email); err == nil && existing != nil {
+		return nil, fmt.Errorf("email '%s' already registered", email)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := &models.User{
+		Username: username,
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     models.RoleAdmin,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, fmt.Errorf("failed to create bootstrap admin: %w", err)
+	}
+	return user, nil
+}
+
+// ProfileUpdate holds the profile fields a user changes about themselves;
+// nil fields are left as they are. Changing the email needs the current
+// password.
+type ProfileUpdate struct {
+	Username          *string
+	Email             *string
+	PreferredCurrency *string
+	Locale            *string
+	ShippingCountry   *string
+	CurrentPassword   string
+}
+
+// UpdateProfile applies a user's changes to their own profile. While email
+// changes need confirming, a new email is held in PendingEmail until the
+// user follows the link sent to it.
+func (s *AuthService) UpdateProfile(userID string, update ProfileUpdate) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if update.Username != nil && *update.Username != user.Username {
+		if existing, err := s.userRepo.GetByUsername(*update.Username); err == nil && existing != nil {
+			return nil, fmt.Errorf("username '%s' already taken", *update.Username)
+		}
+		user.Username = *update.Username
+	}
+	var newEmail string
+	if update.Email != nil && !strings.EqualFold(*update.Email, user.Email) {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(update.CurrentPassword)); err != nil {
+			return nil, fmt.Errorf("invalid current password: required to change the email")
+		}
+		if existing, err := s.userRepo.GetByEmail(*update.Email); err == nil && existing != nil {
+			return nil, fmt.Errorf("email '%s' already registered", *update.Email)
+		}
+		if s.emailSigner != nil {
+			newEmail = *update.Email // Held pending until confirmed
+		} else {
+			user.Email = *update.Email
+		}
+	} else if update.Email != nil {
+		user.PendingEmail = "" // Changing back to the current email cancels a pending change
+	}
+	if update.PreferredCurrency != nil {
+		user.PreferredCurrency = *update.PreferredCurrency
+	}
+	if update.Locale != nil {
+		user.Locale = *update.Locale
+	}
+	if update.ShippingCountry != nil {
+		user.ShippingCountry = *update.ShippingCountry
+	}
+	if newEmail != "" {
+		user.PendingEmail = newEmail
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("failed to update profile of user %s: %w", userID, err)
+	}
+	if newEmail != "" {
+		if err := s.requestEmailChange(user, newEmail); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
 // RegisterUser registers a new user, hashes their password, and saves them to the database.
 func (s *AuthService) RegisterUser(user *models.User) error {
+	if s.betaAccess != nil && !s.betaAccess.IsInvited(user.Email) {
+		return fmt.Errorf("email '%s' is not invited to the beta", user.Email)
+	}
+
 	// Check if username or email already exists
 	if existingUser, err := s.userRepo.GetByUsername(user.Username); err == nil && existingUser != nil {
 		return fmt.Errorf("username '%s' already taken", user.Username)
 	}
+	var guest *models.User
 	if existingUser, err := s.userRepo.GetByEmail(user.Email); err == nil && existingUser != nil {
-		return fmt.Errorf("email '%s' already registered", user.Email)
+		if existingUser.Role != models.RoleGuest {
+			return fmt.Errorf("email '%s' already registered", user.Email)
+		}
+		guest = existingUser // Guest orders carry over to the new account
 	}
 
 	// Hash the password
@@ -44,51 +294,416 @@ func (s *AuthService) RegisterUser(user *models.User) error {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 	user.Password = string(hashedPassword) // Store the hashed password
+	user.Role = models.RoleCustomer        // Roles are never self-assigned at registration
+	user.Permissions = ""                  // Neither are permissions
 
-	if err := s.userRepo.Create(user); err != nil {
+	if guest != nil {
+		user.ID = guest.ID
+		user.Model = guest.Model
+		if err := s.userRepo.Update(user); err != nil {
+			return fmt.Errorf("failed to register user: %w", err)
+		}
+	} else if err := s.userRepo.Create(user); err != nil {
 		return fmt.Errorf("failed to register user: %w", err)
 	}
+	if s.betaAccess != nil {
+		s.betaAccess.MarkRegistered(user.Email)
+	}
 	return nil
 }
 
 // LoginUser authenticates a user and returns a JWT token if successful.
 func (s *AuthService) LoginUser(username, password string) (string, error) {
-	user, err := s.userRepo.GetByUsername(username)
+	tokens, err := s.Login(username, password)
+	if err != nil {
+		return "", err
+	}
+	return tokens.AccessToken, nil
+}
+
+// Login authenticates a user and returns an access token, and a refresh
+// token starting a new family when refresh tokens are enabled.
+func (s *AuthService) Login(username, password string) (*TokenPair, error) {
+	return s.LoginFrom(username, password, DeviceInfo{})
+}
+
+// LoginFrom is Login recording the device logged in from on the new session.
+func (s *AuthService) LoginFrom(username, password string, device DeviceInfo) (*TokenPair, error) {
+	user, err := s.authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+	familyID := uuid.New().String()
+	if err := s.startSession(user, familyID, device); err != nil {
+		return nil, err
+	}
+	if err := s.recordLogin(user, familyID, device); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, familyID)
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh
+// token. Each refresh token works once: presenting a used or revoked token
+// again means it leaked, so its whole family is revoked and the user has to
+// log in again.
+func (s *AuthService) Refresh(refreshToken string) (*TokenPair, error) {
+	if s.refreshRepo == nil {
+		return nil, fmt.Errorf("invalid refresh token: refresh tokens are not enabled")
+	}
+	stored, err := s.refreshRepo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil {
+		return nil, s.revokeReused(stored, now)
+	}
+	if stored.RevokedAt != nil {
+		return nil, fmt.Errorf("invalid refresh token: revoked")
+	}
+	if now.After(stored.ExpiresAt) {
+		return nil, fmt.Errorf("invalid refresh token: expired")
+	}
+	used, err := s.refreshRepo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, s.revokeReused(stored, now) // Lost a race with another refresh of the same token
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil || user.Role == models.RoleGuest {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+	if s.betaAccess != nil && user.Role != models.RoleAdmin && !s.betaAccess.IsInvited(user.Email) {
+		return nil, fmt.Errorf("account is not invited to the beta")
+	}
+	return s.issueTokens(user, stored.FamilyID)
+}
+
+// revokeReused revokes the family of a token presented after it was used,
+// and returns the error for the caller.
+func (s *AuthService) revokeReused(stored *models.RefreshToken, now time.Time) error {
+	if err := s.refreshRepo.RevokeFamily(stored.FamilyID, now); err != nil {
+		return err
+	}
+	if err := s.endSession(stored.FamilyID, now); err != nil {
+		return err
+	}
+	log.Printf("Refresh token reuse detected for user %s; revoked token family %s", stored.UserID, stored.FamilyID)
+	return fmt.Errorf("invalid refresh token: already used")
+}
+
+// Logout revokes the access token and the refresh tokens of its login, so
+// neither works again even before expiring.
+func (s *AuthService) Logout(tokenString string) error {
+	claims, err := s.ValidateToken(tokenString)
 	if err != nil {
+		return err
+	}
+	now := time.Now()
+	userID, _ := claims["user_id"].(string)
+	if jti, _ := claims["jti"].(string); jti != "" && s.revokedRepo != nil {
+		expiresAt := now.Add(s.tokenDurat)
+		if exp, ok := claims["exp"].(float64); ok {
+			expiresAt = time.Unix(int64(exp), 0)
+		}
+		if err := s.revokedRepo.Revoke(&models.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt, RevokedAt: now}); err != nil {
+			return err
+		}
+	}
+	sid, _ := claims["sid"].(string)
+	if sid != "" && s.refreshRepo != nil {
+		if err := s.refreshRepo.RevokeFamily(sid, now); err != nil {
+			return err
+		}
+	}
+	if sid != "" && s.sessionRepo != nil {
+		if err := s.sessionRepo.Revoke(sid, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredTokens removes refresh, password reset and device tokens and
+// sessions that can no longer be used, blacklist entries of access tokens that
+// expired and logins older than the history's retention, and returns how
+// many were removed.
+func (s *AuthService) DeleteExpiredTokens(now time.Time) (int64, error) {
+	var deleted int64
+	if s.refreshRepo != nil {
+		n, err := s.refreshRepo.DeleteExpired(now)
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+	if s.revokedRepo != nil {
+		n, err := s.revokedRepo.PurgeExpired(now)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if s.resetRepo != nil {
+		n, err := s.resetRepo.DeleteExpired(now)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if s.sessionRepo != nil {
+		n, err := s.sessionRepo.DeleteExpired(now)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if s.deviceRepo != nil {
+		n, err := s.deviceRepo.DeleteExpired(now)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if s.loginRepo != nil && s.loginKeep > 0 {
+		n, err := s.loginRepo.DeleteBefore(now.Add(-s.loginKeep))
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// RequestPasswordReset emails the user with the address a link to reset
+// their password, replacing any link sent before. Unknown addresses are
+// ignored without an error so the response does not tell which exist.
+func (s *AuthService) RequestPasswordReset(email string) error {
+	if s.resetRepo == nil {
+		return fmt.Errorf("password resets are not enabled")
+	}
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil || user.Role == models.RoleGuest {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.resetRepo.InvalidateUser(user.ID, now); err != nil {
+		return err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := now.Add(s.resetDurat)
+	if err := s.resetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+	return s.resetNotify.QueuePasswordReset(user.ID, s.resetURL+"?token="+token, expiresAt)
+}
+
+// ResetPassword sets a new password for the user a reset token was sent to.
+// The token works once, and every refresh token of the user is revoked so
+// other devices have to log in again.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	if s.resetRepo == nil {
+		return fmt.Errorf("invalid reset token: password resets are not enabled")
+	}
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("invalid password: must be at least %d characters", minPasswordLength)
+	}
+	stored, err := s.resetRepo.GetByHash(hashToken(token))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("invalid reset token")
+		}
+		return err
+	}
+	now := time.Now()
+	if stored.UsedAt != nil {
+		return fmt.Errorf("invalid reset token: already used")
+	}
+	if now.After(stored.ExpiresAt) {
+		return fmt.Errorf("invalid reset token: expired")
+	}
+	used, err := s.resetRepo.MarkUsed(stored.ID, now)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return fmt.Errorf("invalid reset token: already used")
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid reset token")
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to reset password of user %s: %w", user.ID, err)
+	}
+	return s.EndAllLogins(user.ID)
+}
+
+// ChangePassword replaces the password of the user an access token belongs
+// to after checking their current one. Every other login is ended, and
+// remembered devices and unused reset links stop working.
+func (s *AuthService) ChangePassword(tokenString, currentPassword, newPassword string) error {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return err
+	}
+	userID, _ := claims["user_id"].(string)
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return fmt.Errorf("invalid current password")
+	}
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("invalid password: must be at least %d characters", minPasswordLength)
+	}
+	if newPassword == currentPassword {
+		return fmt.Errorf("invalid password: must differ from the current password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to change password of user %s: %w", user.ID, err)
+	}
+
+	now := time.Now()
+	sid, _ := claims["sid"].(string)
+	if s.refreshRepo != nil {
+		if err := s.refreshRepo.RevokeUser(user.ID, sid, now); err != nil {
+			return err
+		}
+	}
+	if err := s.endUserSessions(user.ID, sid, now); err != nil {
+		return err
+	}
+	if s.deviceRepo != nil {
+		if err := s.deviceRepo.RevokeUser(user.ID, now); err != nil {
+			return err
+		}
+	}
+	if s.resetRepo != nil {
+		if err := s.resetRepo.InvalidateUser(user.ID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authenticate returns the user with the username and password.
+func (s *AuthService) authenticate(username, password string) (*models.User, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil || user.Role == models.RoleGuest {
 		// It's good practice not to reveal if the username exists or not for security
-		return "", fmt.Errorf("invalid credentials")
+		return nil, fmt.Errorf("invalid credentials")
 	}
 
 	// Compare the provided password with the hashed password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", fmt.Errorf("invalid credentials")
+		return nil, fmt.Errorf("invalid credentials")
+	}
+
+	// Admins always get in so they can manage the invite list
+	if s.betaAccess != nil && user.Role != models.RoleAdmin && !s.betaAccess.IsInvited(user.Email) {
+		return nil, fmt.Errorf("account is not invited to the beta")
 	}
+	return user, nil
+}
 
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"exp":      time.Now().Add(s.tokenDurat).Unix(), // Token expiration time
-		"iat":      time.Now().Unix(),                   // Issued at time
+// issueTokens signs an access token for the user and, when refresh tokens
+// are enabled, stores a new refresh token of the family.
+func (s *AuthService) issueTokens(user *models.User, familyID string) (*TokenPair, error) {
+	now := time.Now()
+	jti := uuid.New().String()
+	tokenString, err := s.signToken(jwt.MapClaims{
+		"user_id":    user.ID,
+		"username":   user.Username,
+		"role":       user.Role,                    // Lets handlers authorize without loading the user
+		"token_type": TokenTypeAccess,              // Keeps other kinds of tokens from being used as access tokens
+		"exp":        now.Add(s.tokenDurat).Unix(), // Token expiration time
+		"iat":        now.Unix(),                   // Issued at time
+		"jti":        jti,                          // Identifies the token for revocation
+		"sid":        familyID,                     // The login the token belongs to
 	})
+	if err != nil {
+		return nil, err
+	}
+	tokens := &TokenPair{AccessToken: tokenString}
+	if s.sessionRepo != nil {
+		if err := s.sessionRepo.Touch(familyID, jti, now.Add(s.tokenDurat), now.Add(s.sessionDuration()), now); err != nil {
+			return nil, err
+		}
+	}
+	if s.refreshRepo == nil {
+		return tokens, nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	tokens.RefreshToken = base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.refreshRepo.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(tokens.RefreshToken),
+		ExpiresAt: now.Add(s.refreshDurat),
+	}); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
 
-	tokenString, err := token.SignedString(s.jwtSecret)
+// signToken signs a token with the claims using the current signing key.
+func (s *AuthService) signToken(claims jwt.MapClaims) (string, error) {
+	key := s.keys.signing()
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	tokenString, err := token.SignedString(key.signKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
-
 	return tokenString, nil
 }
 
+// hashToken returns the hash a refresh or password reset token is stored under.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ValidateToken parses and validates a JWT token, returning the claims if valid.
 func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the alg is what we expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.jwtSecret, nil
-	})
+	token, err := jwt.Parse(tokenString, s.keys.verifying)
 
 	if err != nil {
 		log.Printf("Token validation error: %v", err)
@@ -96,8 +711,39 @@ func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		// Tokens issued before the claim was added carry no type
+		if tokenType, ok := claims["token_type"]; ok && tokenType != TokenTypeAccess {
+			return nil, fmt.Errorf("invalid token: not an access token")
+		}
+		if jti, _ := claims["jti"].(string); jti != "" && s.revokedRepo != nil {
+			revoked, err := s.revokedRepo.IsRevoked(jti)
+			if err != nil {
+				return nil, err // Fail closed: a token that cannot be checked is not trusted
+			}
+			if revoked {
+				return nil, fmt.Errorf("invalid token: token has been revoked")
+			}
+		}
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
 }
+
+// IsAdmin reports whether the user an access token was issued to is an admin.
+// role and tokenType are the token's claims, as AuthRequired stores them:
+// tokens of another type or issued to another role are turned away without
+// loading the user. For the rest the stored role decides, so a demotion
+// applies at once. Tokens issued before the claims were added have neither.
+func (s *AuthService) IsAdmin(userID, role, tokenType string) bool {
+	if userID == "" || (tokenType != "" && tokenType != TokenTypeAccess) || (role != "" && role != models.RoleAdmin) {
+		return false
+	}
+	user, err := s.userRepo.GetByID(userID)
+	return err == nil && user.Role == models.RoleAdmin
+}
+
+// GetUserByID retrieves a user by their ID.
+func (s *AuthService) GetUserByID(id string) (*models.User, error) {
+	return s.userRepo.GetByID(id)
+}
@@ -1,18 +1,26 @@
 package services_test
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"toko/internal/jobs"
 	"toko/internal/models"
+	"toko/internal/repositories"
 	"toko/internal/services"
+	"toko/pkg/signedurl"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -50,6 +58,26 @@ func (m *MockUserRepository) GetByID(id string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Update(user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) CountByRole(role string) (int64, error) {
+	args := m.Called(role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 // TestMain is used to setup test environment
 func TestMain(m *testing.M) {
 	// Suppress logging during tests for cleaner output
@@ -108,6 +136,7 @@ func TestAuthService_LoginUser(t *testing.T) {
 		Username: "testuser",
 		Email:    "test@example.com",
 		Password: string(hashedPassword),
+		Role:     models.RoleCustomer,
 	}
 
 	// Test successful login
@@ -131,6 +160,9 @@ func TestAuthService_LoginUser(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, user.ID, claims["user_id"])
 	assert.Equal(t, user.Username, claims["username"])
+	assert.Equal(t, models.RoleCustomer, claims["role"])
+	assert.Equal(t, services.TokenTypeAccess, claims["token_type"])
+	assert.NotEmpty(t, claims["jti"])
 	mockRepo.AssertExpectations(t)
 
 	// Test invalid credentials (wrong password)
@@ -157,7 +189,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id":  "user-123",
 		"username": "testuser",
-		"exp":      jwt.TimeFunc().Add(time.Hour).Unix(), // Expires in 1 hour
+		"exp":      time.Now().Add(time.Hour).Unix(), // Expires in 1 hour
 	})
 	validTokenString, _ := token.SignedString([]byte(testJWTSecret))
 
@@ -178,10 +210,688 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	expiredToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id":  "user-123",
 		"username": "testuser",
-		"exp":      jwt.TimeFunc().Add(-time.Hour).Unix(), // Expired 1 hour ago
+		"exp":      time.Now().Add(-time.Hour).Unix(), // Expired 1 hour ago
 	})
 	expiredTokenString, _ := expiredToken.SignedString([]byte(testJWTSecret))
 	_, err = authService.ValidateToken(expiredTokenString)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid token")
+
+	// Test token of another type
+	otherToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":    "user-123",
+		"token_type": "refresh",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	})
+	otherTokenString, _ := otherToken.SignedString([]byte(testJWTSecret))
+	_, err = authService.ValidateToken(otherTokenString)
+	assert.ErrorContains(t, err, "not an access token")
+}
+
+func TestAuthService_IsAdminUsesTokenClaims(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	admin := &models.User{ID: "admin-1", Role: models.RoleAdmin}
+	demoted := &models.User{ID: "admin-2", Role: models.RoleCustomer}
+	mockRepo.On("GetByID", "admin-1").Return(admin, nil)
+	mockRepo.On("GetByID", "admin-2").Return(demoted, nil)
+
+	assert.True(t, authService.IsAdmin("admin-1", models.RoleAdmin, services.TokenTypeAccess))
+	assert.True(t, authService.IsAdmin("admin-1", "", ""), "tokens issued before the claims are checked against the user")
+	assert.False(t, authService.IsAdmin("admin-2", models.RoleAdmin, services.TokenTypeAccess), "a demotion applies at once")
+	assert.False(t, authService.IsAdmin("", "", ""))
+
+	// Other roles and token types are turned away without loading the user
+	assert.False(t, authService.IsAdmin("user-1", models.RoleCustomer, services.TokenTypeAccess))
+	assert.False(t, authService.IsAdmin("user-1", models.RoleAdmin, "refresh"))
+	mockRepo.AssertNotCalled(t, "GetByID", "user-1")
+}
+
+func TestAuthService_RegisterUserUpgradesGuest(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+
+	guest := &models.User{ID: "guest-1", Username: "guest-guest-1", Email: "ani@example.com", Role: models.RoleGuest}
+	mockRepo.On("GetByUsername", "ani").Return(nil, nil).Once()
+	mockRepo.On("GetByEmail", "ani@example.com").Return(guest, nil).Once()
+	mockRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == "guest-1" && u.Username == "ani" && u.Role == models.RoleCustomer
+	})).Return(nil).Once()
+
+	err := authService.RegisterUser(&models.User{Username: "ani", Email: "ani@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	// Guests cannot log in
+	mockRepo.On("GetByUsername", "guest-guest-1").Return(guest, nil).Once()
+	_, err = authService.LoginUser("guest-guest-1", "")
+	assert.Error(t, err)
+}
+
+func TestAuthService_RefreshRotatesTokens(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(repositories.NewMockRefreshTokenRepository(), 15*time.Minute, time.Hour)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Email: "test@example.com", Password: string(hashedPassword)}
+	mockRepo.On("GetByUsername", "testuser").Return(user, nil)
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+
+	login, err := authService.Login("testuser", "password123")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, login.RefreshToken)
+
+	refreshed, err := authService.Refresh(login.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	claims, err := authService.ValidateToken(refreshed.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-123", claims["user_id"])
+
+	// Reusing a rotated token revokes the whole family, the newest token included
+	_, err = authService.Refresh(login.RefreshToken)
+	assert.ErrorContains(t, err, "already used")
+	_, err = authService.Refresh(refreshed.RefreshToken)
+	assert.ErrorContains(t, err, "invalid refresh token")
+
+	// Other logins are not affected
+	other, err := authService.Login("testuser", "password123")
+	assert.NoError(t, err)
+	_, err = authService.Refresh(other.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = authService.Refresh("unknown")
+	assert.ErrorContains(t, err, "invalid refresh token")
+
+	// Without refresh tokens logins get a long-lived access token only
+	plain := services.NewAuthService(mockRepo, "test_jwt_secret")
+	login, err = plain.Login("testuser", "password123")
+	assert.NoError(t, err)
+	assert.Empty(t, login.RefreshToken)
+	_, err = plain.Refresh("anything")
+	assert.ErrorContains(t, err, "invalid refresh token")
+}
+
+func TestAuthService_RefreshTokenExpires(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	refreshRepo := repositories.NewMockRefreshTokenRepository()
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(refreshRepo, time.Minute, -time.Minute)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	mockRepo.On("GetByUsername", "testuser").Return(&models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}, nil)
+
+	login, err := authService.Login("testuser", "password123")
+	assert.NoError(t, err)
+	_, err = authService.Refresh(login.RefreshToken)
+	assert.ErrorContains(t, err, "expired")
+
+	deleted, err := authService.DeleteExpiredTokens(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestAuthService_LogoutRevokesTokens(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(repositories.NewMockRefreshTokenRepository(), 15*time.Minute, time.Hour)
+	authService.SetRevocation(repositories.NewMockRevokedTokenRepository())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}
+	mockRepo.On("GetByUsername", "testuser").Return(user, nil)
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+
+	login, err := authService.Login("testuser", "password123")
+	assert.NoError(t, err)
+	other, err := authService.Login("testuser", "password123")
+	assert.NoError(t, err)
+
+	assert.NoError(t, authService.Logout(login.AccessToken))
+	_, err = authService.ValidateToken(login.AccessToken)
+	assert.ErrorContains(t, err, "revoked")
+	_, err = authService.Refresh(login.RefreshToken)
+	assert.ErrorContains(t, err, "invalid refresh token")
+	assert.ErrorContains(t, authService.Logout(login.AccessToken), "invalid token")
+
+	// Other logins of the user keep working
+	_, err = authService.ValidateToken(other.AccessToken)
+	assert.NoError(t, err)
+	_, err = authService.Refresh(other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestAuthService_Permissions(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	assert.Error(t, authService.SetRolePermissions(map[string][]string{models.RoleSupport: {"orders:delete"}}))
+	assert.NoError(t, authService.SetRolePermissions(map[string][]string{models.RoleSupport: {"orders:read", "customers:*"}}))
+
+	admin := &models.User{ID: "admin-1", Role: models.RoleAdmin}
+	support := &models.User{ID: "support-1", Role: models.RoleSupport, Permissions: "coupons:write"}
+	customer := &models.User{ID: "customer-1", Role: models.RoleCustomer}
+	mockRepo.On("GetByID", "admin-1").Return(admin, nil)
+	mockRepo.On("GetByID", "support-1").Return(support, nil)
+	mockRepo.On("GetByID", "customer-1").Return(customer, nil)
+
+	for _, tc := range []struct {
+		userID     string
+		permission string
+		allowed    bool
+	}{
+		{"admin-1", models.PermissionAll, true},
+		{"admin-1", models.PermissionOrdersRefund, true},
+		{"support-1", models.PermissionOrdersRead, true},
+		{"support-1", models.PermissionCustomersEdit, true}, // Through the wildcard
+		{"support-1", models.PermissionCouponsWrite, true},  // Granted personally
+		{"support-1", models.PermissionOrdersRefund, false},
+		{"support-1", models.PermissionAll, false},
+		{"customer-1", models.PermissionOrdersRead, false},
+	} {
+		allowed, err := authService.HasPermission(tc.userID, tc.permission)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.allowed, allowed, "%s holding %s", tc.userID, tc.permission)
+	}
+
+	// Customers can be made support staff with personal grants
+	mockRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == "customer-1" && u.Role == models.RoleSupport && u.Permissions == "orders:refund"
+	})).Return(nil).Once()
+	updated, err := authService.SetUserAccess("customer-1", models.RoleSupport, []string{models.PermissionOrdersRefund})
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleSupport, updated.Role)
+
+	_, err = authService.SetUserAccess("customer-1", models.RoleAdmin, nil)
+	assert.ErrorContains(t, err, "invalid role")
+	_, err = authService.SetUserAccess("customer-1", models.RoleSupport, []string{models.PermissionAll})
+	assert.ErrorContains(t, err, "invalid permission")
+	_, err = authService.SetUserAccess("admin-1", models.RoleSupport, nil)
+	assert.ErrorContains(t, err, "invalid user")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthService_BootstrapAdmin(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+
+	mockRepo.On("CountByRole", models.RoleAdmin).Return(int64(0), nil).Times(3)
+	_, err := authService.BootstrapAdmin("admin", "admin@example.com", "short")
+	assert.ErrorContains(t, err, "invalid bootstrap admin")
+
+	mockRepo.On("GetByUsername", "taken").Return(&models.User{ID: "user-1"}, nil)
+	_, err = authService.BootstrapAdmin("taken", "admin@example.com", "password123")
+	assert.ErrorContains(t, err, "already taken")
+
+	mockRepo.On("GetByUsername", "admin").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("GetByEmail", "admin@example.com").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Username == "admin" && u.Role == models.RoleAdmin && u.Password != "password123"
+	})).Return(nil).Once()
+	admin, err := authService.BootstrapAdmin("admin", "admin@example.com", "password123")
+	require.NoError(t, err)
+	require.NotNil(t, admin)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte("password123")))
+
+	// Once an admin exists the credentials are ignored
+	mockRepo.On("CountByRole", models.RoleAdmin).Return(int64(1), nil)
+	admin, err = authService.BootstrapAdmin("admin", "admin@example.com", "password123")
+	assert.NoError(t, err)
+	assert.Nil(t, admin)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	refreshRepo := repositories.NewMockRefreshTokenRepository()
+	resetRepo := repositories.NewMockPasswordResetRepository()
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(refreshRepo, 15*time.Minute, time.Hour)
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 3})
+	m := &flakyMailer{}
+	notifications := services.NewNotificationService(nil, nil, mockRepo, nil, nil, m, queue, "Toko")
+	authService.SetPasswordResets(resetRepo, notifications, time.Hour, "https://toko.example/reset-password")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Email: "test@example.com", Password: string(hashedPassword)}
+	mockRepo.On("GetByEmail", "test@example.com").Return(user, nil)
+	mockRepo.On("GetByEmail", "nobody@example.com").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("GetByUsername", "testuser").Return(user, nil)
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+	mockRepo.On("Update", user).Return(nil)
+
+	login, err := authService.Login("testuser", "password123")
+	require.NoError(t, err)
+
+	// Unknown addresses are accepted without sending anything
+	require.NoError(t, authService.RequestPasswordReset("nobody@example.com"))
+	require.NoError(t, authService.RequestPasswordReset("test@example.com"))
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, []string{"test@example.com"}, m.sent[0].To)
+	token := resetToken(t, m.sent[0].Text)
+
+	assert.ErrorContains(t, authService.ResetPassword(token, "short"), "invalid password")
+	assert.ErrorContains(t, authService.ResetPassword("not-a-token", "newpassword"), "invalid reset token")
+	require.NoError(t, authService.ResetPassword(token, "newpassword"))
+	assert.ErrorContains(t, authService.ResetPassword(token, "otherpassword"), "invalid reset token: already used")
+
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("newpassword")))
+	_, err = authService.Refresh(login.RefreshToken)
+	assert.ErrorContains(t, err, "invalid refresh token", "sessions end with a password reset")
+
+	// A new request replaces the link sent before
+	require.NoError(t, authService.RequestPasswordReset("test@example.com"))
+	require.NoError(t, authService.RequestPasswordReset("test@example.com"))
+	for i := 0; i < 2; i++ {
+		_, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+	}
+	require.Len(t, m.sent, 3)
+	assert.ErrorContains(t, authService.ResetPassword(resetToken(t, m.sent[1].Text), "newpassword"), "already used")
+	assert.NoError(t, authService.ResetPassword(resetToken(t, m.sent[2].Text), "newpassword"))
+}
+
+// resetToken returns the token of the reset link in an email.
+func resetToken(t *testing.T, text string) string {
+	const prefix = "https://toko.example/reset-password?token="
+	start := strings.Index(text, prefix)
+	require.NotEqual(t, -1, start, "email has no reset link")
+	return strings.Fields(text[start+len(prefix):])[0]
+}
+
+func TestAuthService_ChangePasswordEndsOtherLogins(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(repositories.NewMockRefreshTokenRepository(), 15*time.Minute, time.Hour)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}
+	mockRepo.On("GetByUsername", "testuser").Return(user, nil)
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+	mockRepo.On("Update", user).Return(nil)
+
+	current, err := authService.Login("testuser", "password123")
+	require.NoError(t, err)
+	other, err := authService.Login("testuser", "password123")
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, authService.ChangePassword(current.AccessToken, "wrong", "newpassword"), "invalid current password")
+	assert.ErrorContains(t, authService.ChangePassword(current.AccessToken, "password123", "short"), "invalid password")
+	assert.ErrorContains(t, authService.ChangePassword("not-a-token", "password123", "newpassword"), "invalid token")
+	require.NoError(t, authService.ChangePassword(current.AccessToken, "password123", "newpassword"))
+
+	_, err = authService.Login("testuser", "password123")
+	assert.ErrorContains(t, err, "invalid credentials")
+	_, err = authService.Refresh(current.RefreshToken)
+	assert.NoError(t, err, "the login that changed the password stays signed in")
+	_, err = authService.Refresh(other.RefreshToken)
+	assert.ErrorContains(t, err, "invalid refresh token")
+}
+
+func TestAuthService_UpdateProfile(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Email: "test@example.com", Password: string(hashedPassword), Role: models.RoleCustomer}
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+	mockRepo.On("GetByUsername", "taken").Return(&models.User{ID: "user-456"}, nil)
+	mockRepo.On("GetByUsername", "newname").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("GetByEmail", "new@example.com").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("Update", user).Return(nil)
+
+	str := func(s string) *string { return &s }
+	_, err := authService.UpdateProfile("user-123", services.ProfileUpdate{Username: str("taken")})
+	assert.ErrorContains(t, err, "already taken")
+	_, err = authService.UpdateProfile("user-123", services.ProfileUpdate{Email: str("new@example.com"), CurrentPassword: "wrong"})
+	assert.ErrorContains(t, err, "invalid current password")
+	assert.Equal(t, "test@example.com", user.Email)
+
+	updated, err := authService.UpdateProfile("user-123", services.ProfileUpdate{
+		Username:        str("newname"),
+		Email:           str("new@example.com"),
+		Locale:          str("id-ID"),
+		CurrentPassword: "password123",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "newname", updated.Username)
+	assert.Equal(t, "new@example.com", updated.Email)
+	assert.Equal(t, "id-ID", updated.Locale)
+	assert.Equal(t, models.RoleCustomer, updated.Role)
+}
+
+func TestAuthService_EmailChange(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 3})
+	m := &flakyMailer{}
+	notifications := services.NewNotificationService(nil, nil, mockRepo, nil, nil, m, queue, "Toko")
+	authService.SetEmailChanges(signedurl.New("test_signing_secret"), notifications, time.Hour, "https://toko.example/confirm-email")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Email: "test@example.com", Password: string(hashedPassword), Role: models.RoleCustomer}
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+	mockRepo.On("GetByEmail", "new@example.com").Return(nil, fmt.Errorf("user not found"))
+	mockRepo.On("GetByEmail", "other@example.com").Return(nil, fmt.Errorf("user not found")).Once()
+	mockRepo.On("GetByEmail", "other@example.com").Return(&models.User{ID: "user-456"}, nil)
+	mockRepo.On("Update", user).Return(nil)
+
+	str := func(s string) *string { return &s }
+	updated, err := authService.UpdateProfile("user-123", services.ProfileUpdate{Email: str("new@example.com"), CurrentPassword: "password123"})
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", updated.Email, "the email only changes once confirmed")
+	assert.Equal(t, "new@example.com", updated.PendingEmail)
+
+	for i := 0; i < 2; i++ {
+		found, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, found)
+	}
+	require.Len(t, m.sent, 2)
+	assert.Equal(t, []string{"new@example.com"}, m.sent[0].To)
+	assert.Equal(t, []string{"test@example.com"}, m.sent[1].To)
+	assert.Contains(t, m.sent[1].Text, "new@example.com")
+	assert.NotContains(t, m.sent[1].Text, "https://toko.example/confirm-email", "the old address cannot confirm")
+	token := emailChangeToken(t, m.sent[0].Text)
+
+	_, err = authService.ConfirmEmailChange("not-a-token")
+	assert.ErrorContains(t, err, "invalid email change token")
+	confirmed, err := authService.ConfirmEmailChange(token)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", confirmed.Email)
+	assert.Empty(t, confirmed.PendingEmail)
+	_, err = authService.ConfirmEmailChange(token)
+	assert.ErrorContains(t, err, "invalid email change token: already used")
+
+	// A later request replaces the pending address, and changing back cancels it
+	_, err = authService.UpdateProfile("user-123", services.ProfileUpdate{Email: str("other@example.com"), CurrentPassword: "password123"})
+	require.NoError(t, err)
+	_, err = authService.UpdateProfile("user-123", services.ProfileUpdate{Email: str("new@example.com")})
+	require.NoError(t, err)
+	assert.Empty(t, user.PendingEmail)
+	for i := 0; i < 2; i++ {
+		_, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+	}
+	require.Len(t, m.sent, 4)
+	_, err = authService.ConfirmEmailChange(emailChangeToken(t, m.sent[2].Text))
+	assert.ErrorContains(t, err, "invalid email change token")
+
+	// An address registered by someone else meanwhile cannot be confirmed
+	user.PendingEmail = "other@example.com"
+	_, err = authService.ConfirmEmailChange(emailChangeToken(t, m.sent[2].Text))
+	assert.ErrorContains(t, err, "already registered")
+	assert.Equal(t, "new@example.com", user.Email)
+}
+
+func emailChangeToken(t *testing.T, text string) string {
+	const prefix = "https://toko.example/confirm-email?token="
+	start := strings.Index(text, prefix)
+	require.NotEqual(t, -1, start, "email has no confirmation link")
+	return strings.Fields(text[start+len(prefix):])[0]
+}
+
+func TestAuthService_RS256(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	require.NoError(t, authService.SetSigningKeys([]services.SigningKey{services.NewRSAKey("", key)}))
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	mockRepo.On("GetByUsername", "testuser").Return(&models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}, nil)
+
+	token, err := authService.LoginUser("testuser", "password123")
+	require.NoError(t, err)
+	parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+	claims, err := authService.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims["user_id"])
+
+	// Tokens signed with the HMAC secret are no longer accepted
+	hs256, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-123",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test_jwt_secret"))
+	_, err = authService.ValidateToken(hs256)
+	assert.ErrorContains(t, err, "invalid token")
+}
+
+func TestAuthService_SigningKeyRotation(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	mockRepo.On("GetByUsername", "testuser").Return(&models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}, nil)
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys := []services.SigningKey{services.NewRSAKey("2026-01", oldKey)}
+	_, err = authService.ReloadSigningKeys()
+	assert.ErrorContains(t, err, "invalid signing keys")
+	require.NoError(t, authService.SetSigningKeySource(func() ([]services.SigningKey, error) { return keys, nil }))
+
+	before, err := authService.LoginUser("testuser", "password123")
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(before, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01", parsed.Header["kid"])
+
+	// Adding a key moves signing to it; tokens of the old key stay valid
+	keys = append(keys, services.NewRSAKey("2026-07", newKey))
+	info, err := authService.ReloadSigningKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []services.SigningKeyInfo{{ID: "2026-01", Algorithm: "RS256"}, {ID: "2026-07", Algorithm: "RS256", Signing: true}}, info)
+	after, err := authService.LoginUser("testuser", "password123")
+	require.NoError(t, err)
+	parsed, _, err = jwt.NewParser().ParseUnverified(after, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-07", parsed.Header["kid"])
+	_, err = authService.ValidateToken(before)
+	assert.NoError(t, err)
+	_, err = authService.ValidateToken(after)
+	assert.NoError(t, err)
+
+	// Retiring the old key ends its tokens
+	keys = keys[1:]
+	_, err = authService.ReloadSigningKeys()
+	require.NoError(t, err)
+	_, err = authService.ValidateToken(before)
+	assert.ErrorContains(t, err, "invalid token")
+	_, err = authService.ValidateToken(after)
+	assert.NoError(t, err)
+
+	// A failed reload keeps the keys in use
+	keys = nil
+	_, err = authService.ReloadSigningKeys()
+	assert.Error(t, err)
+	_, err = authService.ValidateToken(after)
+	assert.NoError(t, err)
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(repositories.NewMockRefreshTokenRepository(), 15*time.Minute, time.Hour)
+	authService.SetRevocation(repositories.NewMockRevokedTokenRepository())
+	authService.SetSessions(repositories.NewMockSessionRepository())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}
+	mockRepo.On("GetByUsername", "testuser").Return(user, nil)
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+
+	laptop, err := authService.LoginFrom("testuser", "password123", services.DeviceInfo{UserAgent: "Firefox", IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	phone, err := authService.LoginFrom("testuser", "password123", services.DeviceInfo{UserAgent: "Safari", IPAddress: "10.0.0.2"})
+	require.NoError(t, err)
+	claims, err := authService.ValidateToken(laptop.AccessToken)
+	require.NoError(t, err)
+	laptopID := claims["sid"].(string)
+	claims, err = authService.ValidateToken(phone.AccessToken)
+	require.NoError(t, err)
+	phoneID := claims["sid"].(string)
+
+	sessions, err := authService.ListSessions("user-123", laptopID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, session := range sessions {
+		assert.Equal(t, session.ID == laptopID, session.Current)
+		if session.ID == phoneID {
+			assert.Equal(t, "Safari", session.UserAgent)
+			assert.Equal(t, "10.0.0.2", session.IPAddress)
+		}
+	}
+
+	// Refreshing keeps the session and moves it to the new access token
+	phone, err = authService.Refresh(phone.RefreshToken)
+	require.NoError(t, err)
+	claims, err = authService.ValidateToken(phone.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, phoneID, claims["sid"])
+
+	assert.ErrorContains(t, authService.RevokeSession("someone-else", phoneID), "not found")
+	require.NoError(t, authService.RevokeSession("user-123", phoneID))
+	_, err = authService.ValidateToken(phone.AccessToken)
+	assert.ErrorContains(t, err, "revoked")
+	_, err = authService.Refresh(phone.RefreshToken)
+	assert.ErrorContains(t, err, "invalid refresh token")
+
+	sessions, err = authService.ListSessions("user-123", laptopID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, laptopID, sessions[0].ID)
+	_, err = authService.ValidateToken(laptop.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestAuthService_LoginHistory(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	history := repositories.NewMockLoginHistoryRepository()
+	authService.SetLoginHistory(history, 90*24*time.Hour)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	user := &models.User{ID: "user-123", Username: "testuser", Password: string(hashedPassword)}
+	mockRepo.On("GetByUsername", "testuser").Return(user, nil)
+	mockRepo.On("UpdateLastLogin", "user-123", mock.AnythingOfType("time.Time")).Return(nil)
+
+	_, err := authService.LoginFrom("testuser", "password123", services.DeviceInfo{UserAgent: "Firefox", IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	_, err = authService.LoginFrom("testuser", "wrong", services.DeviceInfo{IPAddress: "10.0.0.9"})
+	assert.Error(t, err)
+	_, err = authService.LoginFrom("testuser", "password123", services.DeviceInfo{UserAgent: "Safari", IPAddress: "10.0.0.2"})
+	require.NoError(t, err)
+
+	mockRepo.AssertNumberOfCalls(t, "UpdateLastLogin", 2)
+	require.NotNil(t, user.LastLoginAt)
+	logins, err := authService.LoginHistory("user-123")
+	require.NoError(t, err)
+	require.Len(t, logins, 2, "failed logins are not recorded")
+	assert.Equal(t, "10.0.0.2", logins[0].IPAddress)
+	assert.Equal(t, "Firefox", logins[1].UserAgent)
+
+	// Logins past the retention are removed with the expired tokens
+	require.NoError(t, history.Create(&models.LoginEvent{UserID: "user-123", CreatedAt: time.Now().Add(-100 * 24 * time.Hour)}))
+	deleted, err := authService.DeleteExpiredTokens(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	require.NoError(t, authService.DeleteLoginHistory("user-123"))
+	logins, err = authService.LoginHistory("user-123")
+	require.NoError(t, err)
+	assert.Empty(t, logins)
+}
+
+func TestAuthService_Impersonate(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRevocation(repositories.NewMockRevokedTokenRepository())
+	authService.SetImpersonation(repositories.NewMockImpersonationRepository(), 15*time.Minute)
+
+	mockRepo.On("GetByID", "user-123").Return(&models.User{ID: "user-123", Username: "customer", Role: models.RoleCustomer}, nil)
+	mockRepo.On("GetByID", "admin-2").Return(&models.User{ID: "admin-2", Username: "other-admin", Role: models.RoleAdmin}, nil)
+	mockRepo.On("GetByID", "ghost").Return(nil, fmt.Errorf("user with ID ghost not found"))
+
+	_, err := authService.Impersonate("admin-1", "user-123", "  ", "10.0.0.1")
+	assert.ErrorContains(t, err, "invalid reason")
+	_, err = authService.Impersonate("admin-1", "admin-2", "Ticket 42", "10.0.0.1")
+	assert.ErrorContains(t, err, "only customers")
+	_, err = authService.Impersonate("admin-1", "ghost", "Ticket 42", "10.0.0.1")
+	assert.ErrorContains(t, err, "not found")
+
+	token, err := authService.Impersonate("admin-1", "user-123", "Ticket 42", "10.0.0.1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), token.ExpiresAt, 5*time.Second)
+	claims, err := authService.ValidateToken(token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims["user_id"])
+	assert.Equal(t, "admin-1", claims["impersonator"])
+	assert.NotContains(t, claims, "sid", "impersonation tokens belong to no login")
+
+	impersonations, err := authService.Impersonations("user-123")
+	require.NoError(t, err)
+	require.Len(t, impersonations, 1)
+	assert.Equal(t, "admin-1", impersonations[0].AdminID)
+	assert.Equal(t, "Ticket 42", impersonations[0].Reason)
+	assert.Equal(t, claims["jti"], impersonations[0].TokenID)
+
+	// The token is revoked like any other
+	require.NoError(t, authService.Logout(token.Token))
+	_, err = authService.ValidateToken(token.Token)
+	assert.ErrorContains(t, err, "revoked")
+}
+
+func TestAuthService_DeviceTokens(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetRefreshTokens(repositories.NewMockRefreshTokenRepository(), 15*time.Minute, time.Hour)
+	authService.SetDeviceTokens(repositories.NewMockDeviceTokenRepository(), 365*24*time.Hour)
+
+	user := &models.User{ID: "user-123", Username: "testuser", Role: models.RoleCustomer}
+	mockRepo.On("GetByID", "user-123").Return(user, nil)
+
+	_, err := authService.RememberDevice("user-123", " ", "Phone")
+	assert.ErrorContains(t, err, "fingerprint is required")
+	phoneToken, err := authService.RememberDevice("user-123", "phone-fingerprint", "Phone")
+	require.NoError(t, err)
+	tabletToken, err := authService.RememberDevice("user-123", "tablet-fingerprint", "Tablet")
+	require.NoError(t, err)
+
+	tokens, err := authService.LoginWithDevice(phoneToken, "phone-fingerprint", services.DeviceInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	claims, err := authService.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims["user_id"])
+
+	// A copied token does not work on another device
+	_, err = authService.LoginWithDevice(phoneToken, "tablet-fingerprint", services.DeviceInfo{})
+	assert.ErrorContains(t, err, "another device")
+	_, err = authService.LoginWithDevice("forged", "phone-fingerprint", services.DeviceInfo{})
+	assert.ErrorContains(t, err, "invalid device token")
+
+	devices, err := authService.ListDevices("user-123")
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "Tablet", devices[0].Name)
+	assert.NotNil(t, devices[1].LastUsedAt)
+
+	assert.ErrorContains(t, authService.ForgetDevice("someone-else", devices[0].ID), "not found")
+	require.NoError(t, authService.ForgetDevice("user-123", devices[0].ID))
+	_, err = authService.LoginWithDevice(tabletToken, "tablet-fingerprint", services.DeviceInfo{})
+	assert.ErrorContains(t, err, "revoked")
+
+	// Ending every login forgets every device
+	require.NoError(t, authService.EndAllLogins("user-123"))
+	_, err = authService.LoginWithDevice(phoneToken, "phone-fingerprint", services.DeviceInfo{})
+	assert.ErrorContains(t, err, "revoked")
 }
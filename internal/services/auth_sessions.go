@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// DeviceInfo describes the device a login was made from.
+type DeviceInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SetSessions keeps a session per login, which users can list and end one
+// by one.
+func (s *AuthService) SetSessions(repo repositories.SessionRepository) {
+	s.sessionRepo = repo
+}
+
+// ListSessions returns the logins of the user still in use, marking the one
+// with currentID.
+func (s *AuthService) ListSessions(userID, currentID string) ([]models.Session, error) {
+	if s.sessionRepo == nil {
+		return []models.Session{}, nil
+	}
+	sessions, err := s.sessionRepo.ListActiveByUser(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends a login of the user: its refresh tokens and its latest
+// access token stop working.
+func (s *AuthService) RevokeSession(userID, sessionID string) error {
+	if s.sessionRepo == nil {
+		return fmt.Errorf("session with ID %s not found", sessionID)
+	}
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return fmt.Errorf("session with ID %s not found", sessionID)
+	}
+	now := time.Now()
+	if s.refreshRepo != nil {
+		if err := s.refreshRepo.RevokeFamily(session.ID, now); err != nil {
+			return err
+		}
+	}
+	return s.endSession(session.ID, now)
+}
+
+// EndAllLogins ends every login of the user, including the one making the
+// request, and makes remembered devices and unused password reset links
+// stop working.
+func (s *AuthService) EndAllLogins(userID string) error {
+	now := time.Now()
+	if s.refreshRepo != nil {
+		if err := s.refreshRepo.RevokeUser(userID, "", now); err != nil {
+			return err
+		}
+	}
+	if s.deviceRepo != nil {
+		if err := s.deviceRepo.RevokeUser(userID, now); err != nil {
+			return err
+		}
+	}
+	if s.resetRepo != nil {
+		if err := s.resetRepo.InvalidateUser(userID, now); err != nil {
+			return err
+		}
+	}
+	return s.endUserSessions(userID, "", now)
+}
+
+// startSession records a new login of the user.
+func (s *AuthService) startSession(user *models.User, id string, device DeviceInfo) error {
+	if s.sessionRepo == nil {
+		return nil
+	}
+	userAgent := device.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255] // The column's size
+	}
+	now := time.Now()
+	return s.sessionRepo.Create(&models.Session{
+		ID:         id,
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IPAddress:  device.IPAddress,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.sessionDuration()),
+	})
+}
+
+// sessionDuration is how long a session lasts without being used: as long
+// as its refresh token, or its access token without refresh tokens.
+func (s *AuthService) sessionDuration() time.Duration {
+	if s.refreshRepo != nil {
+		return s.refreshDurat
+	}
+	return s.tokenDurat
+}
+
+// endSession revokes the session and its latest access token. Refresh
+// tokens are left to the caller.
+func (s *AuthService) endSession(id string, now time.Time) error {
+	if s.sessionRepo == nil {
+		return nil
+	}
+	session, err := s.sessionRepo.GetByID(id)
+	if err != nil {
+		return nil // Logins from before sessions were kept have none
+	}
+	if err := s.sessionRepo.Revoke(id, now); err != nil {
+		return err
+	}
+	return s.revokeSessionAccess([]models.Session{*session}, now)
+}
+
+// endUserSessions revokes the sessions of the user other than keepID and
+// their latest access tokens.
+func (s *AuthService) endUserSessions(userID, keepID string, now time.Time) error {
+	if s.sessionRepo == nil {
+		return nil
+	}
+	sessions, err := s.sessionRepo.RevokeUser(userID, keepID, now)
+	if err != nil {
+		return err
+	}
+	return s.revokeSessionAccess(sessions, now)
+}
+
+func (s *AuthService) revokeSessionAccess(sessions []models.Session, now time.Time) error {
+	if s.revokedRepo == nil {
+		return nil
+	}
+	for _, session := range sessions {
+		if session.AccessJTI == "" || !session.AccessExpiresAt.After(now) {
+			continue
+		}
+		if err := s.revokedRepo.Revoke(&models.RevokedToken{JTI: session.AccessJTI, UserID: session.UserID, ExpiresAt: session.AccessExpiresAt, RevokedAt: now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
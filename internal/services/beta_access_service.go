@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// BetaAccessService manages the invite list used while the store runs as an
+// invite-only beta. When beta mode is off every email is allowed.
+type BetaAccessService struct {
+	repo    repositories.BetaInviteRepository
+	enabled bool
+}
+
+// NewBetaAccessService creates a new BetaAccessService.
+func NewBetaAccessService(repo repositories.BetaInviteRepository, enabled bool) *BetaAccessService {
+	return &BetaAccessService{
+		repo:    repo,
+		enabled: enabled,
+	}
+}
+
+// Enabled reports whether invite-only beta mode is on.
+func (s *BetaAccessService) Enabled() bool {
+	return s.enabled
+}
+
+// IsInvited reports whether the email may register and log in.
+func (s *BetaAccessService) IsInvited(email string) bool {
+	if !s.enabled {
+		return true
+	}
+	_, err := s.repo.GetByEmail(normalizeEmail(email))
+	return err == nil
+}
+
+// MarkRegistered records that an invited email has created its account.
+func (s *BetaAccessService) MarkRegistered(email string) {
+	if !s.enabled {
+		return
+	}
+	if err := s.repo.MarkUsed(normalizeEmail(email)); err != nil {
+		log.Printf("Warning: Failed to mark beta invite for %s as used: %v", email, err)
+	}
+}
+
+// ListInvites returns all invites.
+func (s *BetaAccessService) ListInvites() ([]models.BetaInvite, error) {
+	return s.repo.GetAll()
+}
+
+// Invite adds an email to the invite list.
+func (s *BetaAccessService) Invite(email, note, invitedBy string) (*models.BetaInvite, error) {
+	email = normalizeEmail(email)
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+	invite := &models.BetaInvite{Email: email, Note: note, InvitedBy: invitedBy}
+	if existing, err := s.repo.GetByEmail(email); err == nil {
+		// Re-inviting keeps the original invite date and usage
+		invite.CreatedAt = existing.CreatedAt
+		invite.UsedAt = existing.UsedAt
+	}
+	if err := s.repo.Save(invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Revoke removes an email from the invite list. Existing accounts can no
+// longer log in while beta mode is on.
+func (s *BetaAccessService) Revoke(email string) error {
+	return s.repo.Delete(normalizeEmail(email))
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,50 @@
+package services_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthService_BetaMode(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	inviteRepo := repositories.NewMockBetaInviteRepository()
+	betaAccess := services.NewBetaAccessService(inviteRepo, true)
+	authService := services.NewAuthService(mockRepo, "test_jwt_secret")
+	authService.SetBetaAccess(betaAccess)
+
+	// Uninvited emails cannot register
+	err := authService.RegisterUser(&models.User{Username: "stranger", Email: "stranger@example.com", Password: "password123"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not invited")
+
+	// Invites are matched case-insensitively and marked as used on registration
+	_, err = betaAccess.Invite("Tester@Example.com", "early adopter", "admin-1")
+	assert.NoError(t, err)
+	mockRepo.On("GetByUsername", "tester").Return(nil, nil).Once()
+	mockRepo.On("GetByEmail", "tester@example.com").Return(nil, nil).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil).Once()
+	err = authService.RegisterUser(&models.User{Username: "tester", Email: "tester@example.com", Password: "password123"})
+	assert.NoError(t, err)
+	invite, _ := inviteRepo.GetByEmail("tester@example.com")
+	assert.NotNil(t, invite.UsedAt)
+
+	// Revoking the invite blocks login, except for admins
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	assert.NoError(t, betaAccess.Revoke("tester@example.com"))
+	mockRepo.On("GetByUsername", "tester").Return(&models.User{ID: "u1", Username: "tester", Email: "tester@example.com", Password: string(hashedPassword), Role: models.RoleCustomer}, nil).Once()
+	_, err = authService.LoginUser("tester", "password123")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not invited")
+
+	mockRepo.On("GetByUsername", "boss").Return(&models.User{ID: "u2", Username: "boss", Email: "boss@example.com", Password: string(hashedPassword), Role: models.RoleAdmin}, nil).Once()
+	token, err := authService.LoginUser("boss", "password123")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// BundleConfig configures BundleService.
+type BundleConfig struct {
+	Lookback   time.Duration // How far back orders are counted for recommendations
+	Size       int           // Products suggested alongside the one viewed
+	MinOrders  int           // Shared orders needed before a product is suggested
+	CouponCode string        // Coupon offered on bundles; empty offers no discount
+}
+
+// BundleItem is a product of a suggested bundle.
+type BundleItem struct {
+	ProductID      string  `json:"product_id"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	BoughtTogether int     `json:"bought_together,omitempty"` // Orders that had it with the viewed product
+}
+
+// BundleCartItem is a line of a BundleCart.
+type BundleCartItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// BundleCart is the body of POST /orders that adds the whole bundle at once.
+type BundleCart struct {
+	Items      []BundleCartItem `json:"items"`
+	CouponCode string           `json:"coupon_code,omitempty"`
+}
+
+// Bundle is a "frequently bought together" suggestion for a product.
+type Bundle struct {
+	ProductID  string       `json:"product_id"`
+	Items      []BundleItem `json:"items"` // The viewed product first
+	Subtotal   float64      `json:"subtotal"`
+	Discount   float64      `json:"discount"`
+	Total      float64      `json:"total"`
+	CouponCode string       `json:"coupon_code,omitempty"`
+	AddToCart  BundleCart   `json:"add_to_cart"`
+}
+
+// BundleService suggests products frequently bought together, based on past
+// orders, and prices them with the store's bundle coupon.
+type BundleService struct {
+	reportRepo  repositories.ReportRepository
+	productRepo repositories.ProductRepository
+	coupons     *CouponService
+	cfg         BundleConfig
+}
+
+// NewBundleService creates a new BundleService.
+func NewBundleService(reportRepo repositories.ReportRepository, productRepo repositories.ProductRepository, coupons *CouponService, cfg BundleConfig) *BundleService {
+	if cfg.Size <= 0 {
+		cfg.Size = 2
+	}
+	if cfg.MinOrders <= 0 {
+		cfg.MinOrders = 1
+	}
+	return &BundleService{
+		reportRepo:  reportRepo,
+		productRepo: productRepo,
+		coupons:     coupons,
+		cfg:         cfg,
+	}
+}
+
+// SuggestBundle returns the bundle for a product as offered to a user. A
+// product nothing is bought with yet gets a bundle of just itself.
+func (s *BundleService) SuggestBundle(productID, userID string, now time.Time) (*Bundle, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsArchived() {
+		return nil, fmt.Errorf("product with ID %s not found", productID)
+	}
+
+	// Fetch extra pairings so unavailable products can be skipped
+	pairings, err := s.reportRepo.BoughtTogether(productID, now.Add(-s.cfg.Lookback), now, s.cfg.Size*3)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{ProductID: productID}
+	bundle.add(product, 0)
+	for _, pairing := range pairings {
+		if len(bundle.Items) > s.cfg.Size {
+			break
+		}
+		if pairing.Orders < s.cfg.MinOrders {
+			break
+		}
+		other, err := s.productRepo.GetByID(pairing.ProductID)
+		if err != nil {
+			// Deleted products simply drop out of the suggestions
+			continue
+		}
+		if other.IsArchived() || (!other.IsDigital() && other.Stock <= 0) {
+			continue
+		}
+		bundle.add(other, pairing.Orders)
+	}
+	bundle.Subtotal = math.Round(bundle.Subtotal*100) / 100
+	bundle.Total = bundle.Subtotal
+
+	// Single products are not a bundle and get no bundle discount
+	if s.coupons != nil && s.cfg.CouponCode != "" && len(bundle.Items) > 1 {
+		coupon, discount, err := s.coupons.Evaluate(s.cfg.CouponCode, userID, bundle.Subtotal, now)
+		if err != nil {
+			log.Printf("Bundle coupon not offered for product %s: %v", productID, err)
+		} else {
+			bundle.CouponCode = coupon.Code
+			bundle.Discount = discount
+			bundle.Total = math.Round((bundle.Subtotal-discount)*100) / 100
+			bundle.AddToCart.CouponCode = coupon.Code
+		}
+	}
+	return bundle, nil
+}
+
+// add appends one unit of a product to the bundle.
+func (b *Bundle) add(product *models.Product, boughtTogether int) {
+	b.Items = append(b.Items, BundleItem{
+		ProductID:      product.ID,
+		Name:           product.Name,
+		Price:          product.Price,
+		BoughtTogether: boughtTogether,
+	})
+	b.AddToCart.Items = append(b.AddToCart.Items, BundleCartItem{ProductID: product.ID, Quantity: 1})
+	b.Subtotal += product.Price
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pairingReportRepository returns fixed "bought together" counts.
+type pairingReportRepository struct {
+	stubReportRepository
+	pairings []models.ProductPairing
+}
+
+func (r *pairingReportRepository) BoughtTogether(productID string, from, to time.Time, limit int) ([]models.ProductPairing, error) {
+	return r.pairings[:min(limit, len(r.pairings))], nil
+}
+
+func TestBundleService_SuggestBundle(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	camera := &models.Product{Name: "Camera", Price: 300, Stock: 5}
+	card := &models.Product{Name: "SD card", Price: 20, Stock: 50}
+	strap := &models.Product{Name: "Strap", Price: 15, Stock: 0}
+	guide := &models.Product{Name: "Photo guide", Price: 10, Type: models.ProductTypeDigital}
+	tripod := &models.Product{Name: "Tripod", Price: 80, Stock: 3}
+	for _, p := range []*models.Product{camera, card, strap, guide, tripod} {
+		require.NoError(t, productRepo.Create(p))
+	}
+
+	coupons := services.NewCouponService(repositories.NewMockCouponRepository())
+	require.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "BUNDLE5", Type: models.CouponTypePercent, Value: 5, Active: true}))
+	reportRepo := &pairingReportRepository{pairings: []models.ProductPairing{
+		{ProductID: card.ID, Orders: 9},
+		{ProductID: strap.ID, Orders: 7}, // Out of stock
+		{ProductID: guide.ID, Orders: 4},
+		{ProductID: tripod.ID, Orders: 1}, // Below the minimum
+	}}
+	service := services.NewBundleService(reportRepo, productRepo, coupons, services.BundleConfig{Lookback: 90 * 24 * time.Hour, Size: 3, MinOrders: 2, CouponCode: "bundle5"})
+
+	bundle, err := service.SuggestBundle(camera.ID, "user-1", time.Now())
+	require.NoError(t, err)
+	require.Len(t, bundle.Items, 3)
+	assert.Equal(t, camera.ID, bundle.Items[0].ProductID)
+	assert.Equal(t, card.ID, bundle.Items[1].ProductID)
+	assert.Equal(t, 9, bundle.Items[1].BoughtTogether)
+	assert.Equal(t, guide.ID, bundle.Items[2].ProductID)
+	assert.Equal(t, 330.0, bundle.Subtotal)
+	assert.Equal(t, 16.5, bundle.Discount)
+	assert.Equal(t, 313.5, bundle.Total)
+	assert.Equal(t, "BUNDLE5", bundle.AddToCart.CouponCode)
+	assert.Equal(t, services.BundleCartItem{ProductID: guide.ID, Quantity: 1}, bundle.AddToCart.Items[2])
+
+	// Without pairings there is no bundle discount
+	reportRepo.pairings = nil
+	bundle, err = service.SuggestBundle(camera.ID, "user-1", time.Now())
+	require.NoError(t, err)
+	assert.Len(t, bundle.Items, 1)
+	assert.Zero(t, bundle.Discount)
+	assert.Empty(t, bundle.AddToCart.CouponCode)
+
+	_, err = service.SuggestBundle("missing", "user-1", time.Now())
+	assert.ErrorContains(t, err, "not found")
+}
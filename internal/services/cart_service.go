@@ -0,0 +1,664 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/signedurl"
+)
+
+// Cart limits.
+const (
+	maxCartItemQuantity = 99
+	maxCartLines        = 100
+)
+
+// Reasons a cart line was changed when carts were merged.
+const (
+	CartAdjustUnavailable   = "unavailable"    // The product was removed or archived
+	CartAdjustOutOfStock    = "out_of_stock"   // Nothing left, so the line was removed
+	CartAdjustLimitedStock  = "limited_stock"  // Reduced to the units in stock
+	CartAdjustPurchaseLimit = "purchase_limit" // Reduced to the product's per-order limit
+	CartAdjustQuantityLimit = "quantity_limit" // Reduced to the largest quantity a cart line holds
+	CartAdjustCartFull      = "cart_full"      // The cart already holds as many lines as allowed
+)
+
+// CartWarningPriceChanged warns that a product's price changed since it was
+// added; the other cart warnings use the CartAdjust codes.
+const CartWarningPriceChanged = "price_changed"
+
+// CartAdjustment tells the shopper how a cart line changed during a merge.
+type CartAdjustment struct {
+	ProductID string `json:"product_id"`
+	Requested int    `json:"requested"`
+	Quantity  int    `json:"quantity"` // Zero when the line was removed
+	Reason    string `json:"reason"`
+}
+
+// CouponPreview is the discount a promo code would give on the cart. The
+// code is not applied; checkout validates it again.
+type CouponPreview struct {
+	Code               string  `json:"code"`
+	Type               string  `json:"type"`
+	Value              float64 `json:"value"`
+	Subtotal           float64 `json:"subtotal"`
+	Discount           float64 `json:"discount"`
+	DiscountedSubtotal float64 `json:"discounted_subtotal"` // Before tax and shipping, which depend on the address
+}
+
+// CartShare is a signed token others can use to copy the shared cart's
+// items into their own cart.
+type CartShare struct {
+	Token     string    `json:"token"`
+	Items     int       `json:"items"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// sharedCartItem is a cart line as carried in a share token.
+type sharedCartItem struct {
+	ProductID string `json:"p"`
+	Quantity  int    `json:"q"`
+}
+
+// CartMergeResult is the user's cart after an anonymous or shared cart was merged into it.
+type CartMergeResult struct {
+	Cart        *models.Cart     `json:"cart"`
+	Adjustments []CartAdjustment `json:"adjustments"`
+}
+
+// CartService manages shopping carts. Logged-in users have one cart each;
+// anonymous shoppers get a cart token with their first item, and that cart
+// is merged into their own when they log in. Logged-in users can also move
+// lines to a saved-for-later section of their cart, which checkout ignores.
+type CartService struct {
+	cartRepo    repositories.CartRepository
+	productRepo repositories.ProductRepository
+	coupons     *CouponService
+	shareSigner *signedurl.Signer
+	shareTTL    time.Duration
+}
+
+// NewCartService creates a new CartService.
+func NewCartService(cartRepo repositories.CartRepository, productRepo repositories.ProductRepository) *CartService {
+	return &CartService{
+		cartRepo:    cartRepo,
+		productRepo: productRepo,
+	}
+}
+
+// SetCoupons enables previewing promo codes on carts.
+func (s *CartService) SetCoupons(coupons *CouponService) {
+	s.coupons = coupons
+}
+
+// SetSharing enables share tokens, signed with signer and valid for ttl.
+func (s *CartService) SetSharing(signer *signedurl.Signer, ttl time.Duration) {
+	s.shareSigner = signer
+	s.shareTTL = ttl
+}
+
+// GetCart returns the user's cart, or the anonymous cart with the token when
+// userID is empty. Shoppers without a cart get an empty, unsaved one. Lines
+// that checkout would reject, or whose price changed, come with warnings.
+func (s *CartService) GetCart(userID, token string) (*models.Cart, error) {
+	cart, err := s.findCart(userID, token)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		cart = &models.Cart{UserID: userID, Items: []models.CartItem{}, SavedItems: []models.CartItem{}}
+	}
+	s.price(cart)
+	return cart, nil
+}
+
+// AddItem adds units of a product to the cart, creating the cart if needed.
+// Anonymous shoppers whose token is unknown get a new cart and token.
+func (s *CartService) AddItem(userID, token, productID string, quantity int) (*models.Cart, error) {
+	if quantity < 1 || quantity > maxCartItemQuantity {
+		return nil, fmt.Errorf("invalid quantity: must be between 1 and %d", maxCartItemQuantity)
+	}
+	cart, err := s.cartForUpdate(userID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	index := cartItemIndex(cart, productID)
+	if index < 0 {
+		if len(cart.Items) >= maxCartLines {
+			return nil, fmt.Errorf("invalid cart: at most %d different products are allowed", maxCartLines)
+		}
+		cart.Items = append(cart.Items, models.CartItem{ProductID: productID})
+		index = len(cart.Items) - 1
+	}
+	requested := cart.Items[index].Quantity + quantity
+	product, err := s.checkQuantity(productID, requested)
+	if err != nil {
+		return nil, err
+	}
+	cart.Items[index].Quantity = requested
+	cart.Items[index].AddedPrice = product.Price
+	return s.save(cart)
+}
+
+// SetItemQuantity changes the quantity of a product in the cart; zero removes it.
+func (s *CartService) SetItemQuantity(userID, token, productID string, quantity int) (*models.Cart, error) {
+	if quantity < 0 || quantity > maxCartItemQuantity {
+		return nil, fmt.Errorf("invalid quantity: must be between 0 and %d", maxCartItemQuantity)
+	}
+	cart, err := s.findCart(userID, token)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	if cart != nil {
+		index = cartItemIndex(cart, productID)
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("product %s not found in cart", productID)
+	}
+
+	if quantity == 0 {
+		cart.Items = append(cart.Items[:index], cart.Items[index+1:]...)
+	} else {
+		product, err := s.checkQuantity(productID, quantity)
+		if err != nil {
+			return nil, err
+		}
+		cart.Items[index].Quantity = quantity
+		cart.Items[index].AddedPrice = product.Price
+	}
+	return s.save(cart)
+}
+
+// PreviewCoupon checks a promo code against the shopper's cart as it is now
+// and returns the discount it would give at checkout, without redeeming it.
+func (s *CartService) PreviewCoupon(userID, token, code string) (*CouponPreview, error) {
+	if s.coupons == nil {
+		return nil, fmt.Errorf("invalid coupon: promo codes are not accepted")
+	}
+	if strings.TrimSpace(code) == "" {
+		return nil, fmt.Errorf("invalid coupon: a code is required")
+	}
+	cart, err := s.GetCart(userID, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, fmt.Errorf("invalid cart: the cart is empty")
+	}
+
+	coupon, discount, err := s.coupons.Evaluate(code, userID, cart.Subtotal, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &CouponPreview{
+		Code:               coupon.Code,
+		Type:               coupon.Type,
+		Value:              coupon.Value,
+		Subtotal:           cart.Subtotal,
+		Discount:           discount,
+		DiscountedSubtotal: roundCents(cart.Subtotal - discount),
+	}, nil
+}
+
+// Clear removes every item from the cart; items saved for later are kept.
+func (s *CartService) Clear(userID, token string) error {
+	cart, err := s.findCart(userID, token)
+	if err != nil || cart == nil {
+		return err
+	}
+	cart.Items = nil
+	_, err = s.save(cart)
+	return err
+}
+
+// SaveForLater moves a product from the user's cart to the saved-for-later
+// section, adding to the units already saved.
+func (s *CartService) SaveForLater(userID, productID string) (*models.Cart, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("invalid cart: log in to save items for later")
+	}
+	cart, err := s.findCart(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	if cart != nil {
+		index = cartItemIndex(cart, productID)
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("product %s not found in cart", productID)
+	}
+
+	item := cart.Items[index]
+	if saved := savedItemIndex(cart, productID); saved >= 0 {
+		cart.SavedItems[saved].Quantity = min(cart.SavedItems[saved].Quantity+item.Quantity, maxCartItemQuantity)
+	} else {
+		if len(cart.SavedItems) >= maxCartLines {
+			return nil, fmt.Errorf("invalid cart: at most %d products can be saved for later", maxCartLines)
+		}
+		cart.SavedItems = append(cart.SavedItems, models.CartItem{ProductID: productID, Quantity: item.Quantity, AddedPrice: item.AddedPrice})
+	}
+	cart.Items = append(cart.Items[:index], cart.Items[index+1:]...)
+	return s.save(cart)
+}
+
+// MoveSavedToCart moves a product saved for later back into the user's cart,
+// adding to the units already there. The combined quantity must still be in stock.
+func (s *CartService) MoveSavedToCart(userID, productID string) (*models.Cart, error) {
+	cart, saved, err := s.findSavedItem(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := cartItemIndex(cart, productID)
+	if index < 0 {
+		if len(cart.Items) >= maxCartLines {
+			return nil, fmt.Errorf("invalid cart: at most %d different products are allowed", maxCartLines)
+		}
+		cart.Items = append(cart.Items, models.CartItem{ProductID: productID})
+		index = len(cart.Items) - 1
+	}
+	requested := cart.Items[index].Quantity + cart.SavedItems[saved].Quantity
+	product, err := s.checkQuantity(productID, requested)
+	if err != nil {
+		return nil, err
+	}
+	cart.Items[index].Quantity = requested
+	cart.Items[index].AddedPrice = product.Price
+	cart.SavedItems = append(cart.SavedItems[:saved], cart.SavedItems[saved+1:]...)
+	return s.save(cart)
+}
+
+// RemoveSaved removes a product from the user's saved-for-later section.
+func (s *CartService) RemoveSaved(userID, productID string) (*models.Cart, error) {
+	cart, saved, err := s.findSavedItem(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	cart.SavedItems = append(cart.SavedItems[:saved], cart.SavedItems[saved+1:]...)
+	return s.save(cart)
+}
+
+// MergeCart moves the anonymous cart with the token into the user's cart.
+// Quantities of products in both are added up, then every line is checked
+// against the current catalog and stock; lines that had to change are
+// reported. The anonymous cart is deleted afterwards.
+func (s *CartService) MergeCart(userID, token string) (*CartMergeResult, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("invalid cart merge: a user is required")
+	}
+	cart, err := s.findCart(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		cart = &models.Cart{UserID: userID}
+	}
+	result := &CartMergeResult{Adjustments: []CartAdjustment{}}
+
+	var anonymous *models.Cart
+	if token != "" {
+		if anonymous, err = s.cartRepo.GetByToken(token); err != nil {
+			if !strings.Contains(err.Error(), "not found") {
+				return nil, err
+			}
+			anonymous = nil
+		}
+	}
+	if anonymous == nil {
+		s.price(cart)
+		if cart.Items == nil {
+			cart.Items = []models.CartItem{}
+		}
+		if cart.SavedItems == nil {
+			cart.SavedItems = []models.CartItem{}
+		}
+		result.Cart = cart
+		return result, nil
+	}
+
+	s.mergeItems(cart, anonymous.Items, result)
+	if result.Cart, err = s.save(cart); err != nil {
+		return nil, err
+	}
+	if err := s.cartRepo.Delete(anonymous.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ShareCart returns a token carrying the items in the shopper's cart. The
+// token is a snapshot: later changes to the cart are not shared.
+func (s *CartService) ShareCart(userID, token string) (*CartShare, error) {
+	if s.shareSigner == nil {
+		return nil, fmt.Errorf("invalid cart share: sharing carts is not enabled")
+	}
+	cart, err := s.findCart(userID, token)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		return nil, fmt.Errorf("invalid cart: the cart is empty")
+	}
+
+	items := make([]sharedCartItem, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = sharedCartItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	payload, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode shared cart: %w", err)
+	}
+	expiresAt := time.Now().Add(s.shareTTL)
+	return &CartShare{
+		Token:     s.shareSigner.SignToken(payload, expiresAt),
+		Items:     len(items),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// RedeemShare copies the items of a shared cart into the shopper's cart,
+// adding up quantities of products already in it. Like merging, every line
+// is checked against the current catalog and stock, and lines that had to
+// change are reported. Anonymous shoppers without a cart get a new one.
+func (s *CartService) RedeemShare(userID, token, shareToken string) (*CartMergeResult, error) {
+	if s.shareSigner == nil {
+		return nil, fmt.Errorf("invalid cart share: sharing carts is not enabled")
+	}
+	payload, err := s.shareSigner.VerifyToken(shareToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cart share: %w", err)
+	}
+	var shared []sharedCartItem
+	if err := json.Unmarshal(payload, &shared); err != nil || len(shared) == 0 {
+		return nil, fmt.Errorf("invalid cart share: the token holds no items")
+	}
+
+	cart, err := s.cartForUpdate(userID, token)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.CartItem, 0, len(shared))
+	for _, item := range shared {
+		if item.Quantity <= 0 {
+			continue
+		}
+		added := models.CartItem{ProductID: item.ProductID, Quantity: item.Quantity}
+		if product, err := s.productRepo.GetByID(item.ProductID); err == nil {
+			added.AddedPrice = product.Price
+		}
+		items = append(items, added)
+	}
+	result := &CartMergeResult{Adjustments: []CartAdjustment{}}
+	s.mergeItems(cart, items, result)
+	if result.Cart, err = s.save(cart); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeItems adds items to the cart, then cuts every line down to what can
+// be bought and records the lines that changed in result.
+func (s *CartService) mergeItems(cart *models.Cart, items []models.CartItem, result *CartMergeResult) {
+	for _, item := range items {
+		if index := cartItemIndex(cart, item.ProductID); index >= 0 {
+			cart.Items[index].Quantity += item.Quantity
+			continue
+		}
+		cart.Items = append(cart.Items, models.CartItem{ProductID: item.ProductID, Quantity: item.Quantity, AddedPrice: item.AddedPrice, CreatedAt: item.CreatedAt})
+	}
+
+	merged := make([]models.CartItem, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		quantity, reason := s.revalidate(item.ProductID, item.Quantity)
+		if quantity > 0 && len(merged) >= maxCartLines {
+			quantity, reason = 0, CartAdjustCartFull
+		}
+		if reason != "" {
+			result.Adjustments = append(result.Adjustments, CartAdjustment{
+				ProductID: item.ProductID,
+				Requested: item.Quantity,
+				Quantity:  quantity,
+				Reason:    reason,
+			})
+		}
+		if quantity > 0 {
+			item.Quantity = quantity
+			merged = append(merged, item)
+		}
+	}
+	cart.Items = merged
+}
+
+// DeleteStaleCarts removes anonymous carts untouched for olderThan and
+// returns how many were removed.
+func (s *CartService) DeleteStaleCarts(olderThan time.Duration) (int64, error) {
+	return s.cartRepo.DeleteAnonymousBefore(time.Now().Add(-olderThan))
+}
+
+// findCart returns the shopper's saved cart, or nil when they have none.
+func (s *CartService) findCart(userID, token string) (*models.Cart, error) {
+	var cart *models.Cart
+	var err error
+	switch {
+	case userID != "":
+		cart, err = s.cartRepo.GetByUserID(userID)
+	case token != "":
+		cart, err = s.cartRepo.GetByToken(token)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cart, nil
+}
+
+// findSavedItem returns the user's cart and the index of the product among
+// its saved items.
+func (s *CartService) findSavedItem(userID, productID string) (*models.Cart, int, error) {
+	cart, err := s.findCart(userID, "")
+	if err != nil {
+		return nil, -1, err
+	}
+	index := -1
+	if cart != nil && userID != "" {
+		index = savedItemIndex(cart, productID)
+	}
+	if index < 0 {
+		return nil, -1, fmt.Errorf("product %s not found in saved items", productID)
+	}
+	return cart, index, nil
+}
+
+// cartForUpdate returns the shopper's cart, or a new one with a fresh token
+// for anonymous shoppers.
+func (s *CartService) cartForUpdate(userID, token string) (*models.Cart, error) {
+	cart, err := s.findCart(userID, token)
+	if err != nil || cart != nil {
+		return cart, err
+	}
+	cart = &models.Cart{UserID: userID}
+	if userID == "" {
+		if cart.Token, err = generateCartToken(); err != nil {
+			return nil, err
+		}
+	}
+	return cart, nil
+}
+
+// checkQuantity returns the product, or an error when quantity units of it cannot be bought.
+func (s *CartService) checkQuantity(productID string, quantity int) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsArchived() {
+		return nil, fmt.Errorf("product %s not found", productID)
+	}
+	if quantity > maxCartItemQuantity {
+		return nil, fmt.Errorf("invalid quantity: at most %d units of a product are allowed", maxCartItemQuantity)
+	}
+	if product.MaxPerOrder > 0 && quantity > product.MaxPerOrder {
+		return nil, fmt.Errorf("invalid quantity: at most %d units of %s are allowed per order", product.MaxPerOrder, product.Name)
+	}
+	if !product.IsDigital() && quantity > product.Stock {
+		return nil, fmt.Errorf("insufficient stock for product %s (requested: %d, available: %d)", product.Name, quantity, product.Stock)
+	}
+	return product, nil
+}
+
+// revalidate returns how many units of the product the cart can keep, and
+// why the quantity had to change, if it did.
+func (s *CartService) revalidate(productID string, quantity int) (int, string) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil || product.IsArchived() {
+		return 0, CartAdjustUnavailable
+	}
+	reason := ""
+	if quantity > maxCartItemQuantity {
+		quantity, reason = maxCartItemQuantity, CartAdjustQuantityLimit
+	}
+	if product.MaxPerOrder > 0 && quantity > product.MaxPerOrder {
+		quantity, reason = product.MaxPerOrder, CartAdjustPurchaseLimit
+	}
+	if !product.IsDigital() && quantity > product.Stock {
+		if product.Stock <= 0 {
+			return 0, CartAdjustOutOfStock
+		}
+		quantity, reason = product.Stock, CartAdjustLimitedStock
+	}
+	return quantity, reason
+}
+
+// save stores the cart, deleting it once it holds no items and nothing
+// saved for later, and returns it priced.
+func (s *CartService) save(cart *models.Cart) (*models.Cart, error) {
+	if len(cart.Items) == 0 && len(cart.SavedItems) == 0 {
+		if cart.ID != "" {
+			if err := s.cartRepo.Delete(cart.ID); err != nil {
+				return nil, err
+			}
+		}
+		return &models.Cart{UserID: cart.UserID, Items: []models.CartItem{}, SavedItems: []models.CartItem{}}, nil
+	}
+	if cart.Items == nil {
+		cart.Items = []models.CartItem{}
+	}
+	if cart.SavedItems == nil {
+		cart.SavedItems = []models.CartItem{}
+	}
+	if err := s.cartRepo.Save(cart); err != nil {
+		return nil, err
+	}
+	s.price(cart)
+	return cart, nil
+}
+
+// price fills in the current name and price of the cart's products and its
+// subtotal, and warns about lines checkout would reject or whose price
+// changed. Unavailable products do not count towards the subtotal, and
+// neither do items saved for later, which only get their name and price.
+func (s *CartService) price(cart *models.Cart) {
+	var subtotal float64
+	cart.Warnings = nil
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil || product.IsArchived() {
+			cart.Warnings = append(cart.Warnings, models.CartWarning{
+				ProductID: item.ProductID,
+				Code:      CartAdjustUnavailable,
+				Message:   "This product is no longer available",
+			})
+			continue
+		}
+		item.Name = product.Name
+		item.Price = product.Price
+		subtotal += product.Price * float64(item.Quantity)
+
+		if warning := cartStockWarning(product, item.Quantity); warning != nil {
+			cart.Warnings = append(cart.Warnings, *warning)
+		}
+		if item.AddedPrice > 0 && item.AddedPrice != product.Price {
+			direction := "dropped"
+			if product.Price > item.AddedPrice {
+				direction = "increased"
+			}
+			cart.Warnings = append(cart.Warnings, models.CartWarning{
+				ProductID: item.ProductID,
+				Code:      CartWarningPriceChanged,
+				Message:   fmt.Sprintf("The price of %s %s from %.2f to %.2f since it was added", product.Name, direction, item.AddedPrice, product.Price),
+				OldPrice:  item.AddedPrice,
+				NewPrice:  product.Price,
+			})
+		}
+	}
+	cart.Subtotal = roundCents(subtotal)
+
+	for i := range cart.SavedItems {
+		item := &cart.SavedItems[i]
+		if product, err := s.productRepo.GetByID(item.ProductID); err == nil && !product.IsArchived() {
+			item.Name = product.Name
+			item.Price = product.Price
+		}
+	}
+}
+
+// cartStockWarning returns a warning when quantity units of the product can
+// no longer be bought, or nil.
+func cartStockWarning(product *models.Product, quantity int) *models.CartWarning {
+	var available int
+	var code, message string
+	switch {
+	case !product.IsDigital() && product.Stock <= 0:
+		available, code = 0, CartAdjustOutOfStock
+		message = fmt.Sprintf("%s is out of stock", product.Name)
+	case !product.IsDigital() && quantity > product.Stock:
+		available, code = product.Stock, CartAdjustLimitedStock
+		message = fmt.Sprintf("Only %d units of %s are left", available, product.Name)
+	case product.MaxPerOrder > 0 && quantity > product.MaxPerOrder:
+		available, code = product.MaxPerOrder, CartAdjustPurchaseLimit
+		message = fmt.Sprintf("At most %d units of %s can be bought per order", available, product.Name)
+	default:
+		return nil
+	}
+	return &models.CartWarning{ProductID: product.ID, Code: code, Message: message, Available: &available}
+}
+
+func cartItemIndex(cart *models.Cart, productID string) int {
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+func savedItemIndex(cart *models.Cart, productID string) int {
+	for i := range cart.SavedItems {
+		if cart.SavedItems[i].ProductID == productID {
+			return i
+		}
+	}
+	return -1
+}
+
+func generateCartToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate cart token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,319 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/signedurl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCartService_AnonymousCart(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 3, Status: models.ProductStatusActive}
+	require.NoError(t, productRepo.Create(lamp))
+	service := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+
+	cart, err := service.AddItem("", "", lamp.ID, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, cart.Token)
+	assert.Equal(t, 80.0, cart.Subtotal)
+
+	// The token finds the same cart again
+	cart, err = service.AddItem("", cart.Token, lamp.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	assert.Equal(t, 3, cart.Items[0].Quantity)
+
+	_, err = service.AddItem("", cart.Token, lamp.ID, 1)
+	assert.ErrorContains(t, err, "insufficient stock")
+	_, err = service.AddItem("", cart.Token, lamp.ID, 0)
+	assert.ErrorContains(t, err, "invalid quantity")
+	_, err = service.SetItemQuantity("", cart.Token, "missing", 1)
+	assert.ErrorContains(t, err, "not found")
+
+	// Removing the last line deletes the cart
+	cart, err = service.SetItemQuantity("", cart.Token, lamp.ID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+	cart, err = service.GetCart("", "unknown-token")
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+}
+
+func TestCartService_MergeCart(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 5, Status: models.ProductStatusActive}
+	mug := &models.Product{Name: "Mug", Price: 8, Stock: 20, MaxPerOrder: 4, Status: models.ProductStatusActive}
+	scarf := &models.Product{Name: "Scarf", Price: 15, Stock: 10, Status: models.ProductStatusActive}
+	for _, product := range []*models.Product{lamp, mug, scarf} {
+		require.NoError(t, productRepo.Create(product))
+	}
+	cartRepo := repositories.NewMockCartRepository()
+	service := services.NewCartService(cartRepo, productRepo)
+
+	_, err := service.AddItem("user-1", "", lamp.ID, 3)
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", "", mug.ID, 2)
+	require.NoError(t, err)
+
+	anonymous, err := service.AddItem("", "", lamp.ID, 4)
+	require.NoError(t, err)
+	_, err = service.AddItem("", anonymous.Token, mug.ID, 3)
+	require.NoError(t, err)
+	_, err = service.AddItem("", anonymous.Token, scarf.ID, 1)
+	require.NoError(t, err)
+
+	// The scarf is archived before the shopper logs in
+	scarf.Status = models.ProductStatusArchived
+	require.NoError(t, productRepo.Update(scarf))
+
+	result, err := service.MergeCart("user-1", anonymous.Token)
+	require.NoError(t, err)
+	quantities := map[string]int{}
+	for _, item := range result.Cart.Items {
+		quantities[item.ProductID] = item.Quantity
+	}
+	assert.Equal(t, map[string]int{lamp.ID: 5, mug.ID: 4}, quantities)
+	assert.Equal(t, 232.0, result.Cart.Subtotal)
+	assert.ElementsMatch(t, []services.CartAdjustment{
+		{ProductID: lamp.ID, Requested: 7, Quantity: 5, Reason: services.CartAdjustLimitedStock},
+		{ProductID: mug.ID, Requested: 5, Quantity: 4, Reason: services.CartAdjustPurchaseLimit},
+		{ProductID: scarf.ID, Requested: 1, Quantity: 0, Reason: services.CartAdjustUnavailable},
+	}, result.Adjustments)
+
+	// The anonymous cart is gone, so merging again changes nothing
+	_, err = cartRepo.GetByToken(anonymous.Token)
+	assert.ErrorContains(t, err, "not found")
+	result, err = service.MergeCart("user-1", anonymous.Token)
+	require.NoError(t, err)
+	assert.Len(t, result.Cart.Items, 2)
+	assert.Empty(t, result.Adjustments)
+}
+
+func TestCartService_DeleteStaleCarts(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 5, Status: models.ProductStatusActive}
+	require.NoError(t, productRepo.Create(lamp))
+	service := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+
+	_, err := service.AddItem("", "", lamp.ID, 1)
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", "", lamp.ID, 1)
+	require.NoError(t, err)
+
+	deleted, err := service.DeleteStaleCarts(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	// Only anonymous carts expire
+	deleted, err = service.DeleteStaleCarts(-time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	cart, err := service.GetCart("user-1", "")
+	require.NoError(t, err)
+	assert.Len(t, cart.Items, 1)
+}
+
+func TestCartService_WarnsAboutStockAndPriceChanges(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 5, Status: models.ProductStatusActive}
+	mug := &models.Product{Name: "Mug", Price: 8, Stock: 20, Status: models.ProductStatusActive}
+	scarf := &models.Product{Name: "Scarf", Price: 15, Stock: 10, Status: models.ProductStatusActive}
+	for _, product := range []*models.Product{lamp, mug, scarf} {
+		require.NoError(t, productRepo.Create(product))
+	}
+	service := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+	for _, product := range []*models.Product{lamp, mug, scarf} {
+		_, err := service.AddItem("user-1", "", product.ID, 3)
+		require.NoError(t, err)
+	}
+	cart, err := service.GetCart("user-1", "")
+	require.NoError(t, err)
+	assert.Empty(t, cart.Warnings)
+
+	lamp.Stock = 2
+	lamp.Price = 45
+	mug.Stock = 0
+	scarf.Status = models.ProductStatusArchived
+	for _, product := range []*models.Product{lamp, mug, scarf} {
+		require.NoError(t, productRepo.Update(product))
+	}
+
+	cart, err = service.GetCart("user-1", "")
+	require.NoError(t, err)
+	codes := map[string][]string{}
+	for _, warning := range cart.Warnings {
+		codes[warning.ProductID] = append(codes[warning.ProductID], warning.Code)
+		if warning.Code == services.CartWarningPriceChanged {
+			assert.Equal(t, 40.0, warning.OldPrice)
+			assert.Equal(t, 45.0, warning.NewPrice)
+		}
+		if warning.Code == services.CartAdjustLimitedStock {
+			assert.Equal(t, 2, *warning.Available)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		lamp.ID:  {services.CartAdjustLimitedStock, services.CartWarningPriceChanged},
+		mug.ID:   {services.CartAdjustOutOfStock},
+		scarf.ID: {services.CartAdjustUnavailable},
+	}, codes)
+	assert.Equal(t, 159.0, cart.Subtotal) // The archived scarf no longer counts
+
+	// Changing the quantity accepts the new price
+	cart, err = service.SetItemQuantity("user-1", "", lamp.ID, 2)
+	require.NoError(t, err)
+	for _, warning := range cart.Warnings {
+		assert.NotEqual(t, lamp.ID, warning.ProductID)
+	}
+}
+
+func TestCartService_SaveForLater(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 3, Status: models.ProductStatusActive}
+	mug := &models.Product{Name: "Mug", Price: 8, Stock: 20, Status: models.ProductStatusActive}
+	for _, product := range []*models.Product{lamp, mug} {
+		require.NoError(t, productRepo.Create(product))
+	}
+	service := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+
+	_, err := service.AddItem("user-1", "", lamp.ID, 2)
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", "", mug.ID, 1)
+	require.NoError(t, err)
+
+	cart, err := service.SaveForLater("user-1", lamp.ID)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	require.Len(t, cart.SavedItems, 1)
+	assert.Equal(t, 2, cart.SavedItems[0].Quantity)
+	assert.Equal(t, "Lamp", cart.SavedItems[0].Name)
+	assert.Equal(t, 8.0, cart.Subtotal, "saved items do not count towards the subtotal")
+
+	// Saved items survive clearing the cart
+	require.NoError(t, service.Clear("user-1", ""))
+	cart, err = service.GetCart("user-1", "")
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+	require.Len(t, cart.SavedItems, 1)
+
+	// Moving back checks the stock
+	_, err = service.AddItem("user-1", "", lamp.ID, 2)
+	require.NoError(t, err)
+	_, err = service.MoveSavedToCart("user-1", lamp.ID)
+	assert.ErrorContains(t, err, "insufficient stock")
+	_, err = service.SetItemQuantity("user-1", "", lamp.ID, 1)
+	require.NoError(t, err)
+	cart, err = service.MoveSavedToCart("user-1", lamp.ID)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	assert.Equal(t, 3, cart.Items[0].Quantity)
+	assert.Empty(t, cart.SavedItems)
+
+	_, err = service.RemoveSaved("user-1", lamp.ID)
+	assert.ErrorContains(t, err, "not found")
+	_, err = service.SaveForLater("", lamp.ID)
+	assert.ErrorContains(t, err, "invalid cart")
+
+	// Removing the last saved item of an empty cart deletes the cart
+	_, err = service.SaveForLater("user-1", lamp.ID)
+	require.NoError(t, err)
+	cart, err = service.RemoveSaved("user-1", lamp.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+	assert.Empty(t, cart.SavedItems)
+}
+
+func TestCartService_PreviewCoupon(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 5, Status: models.ProductStatusActive}
+	require.NoError(t, productRepo.Create(lamp))
+	coupons := services.NewCouponService(repositories.NewMockCouponRepository())
+	require.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "SAVE10", Type: models.CouponTypePercent, Value: 10, MinSpend: 50, Active: true}))
+	service := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+
+	_, err := service.PreviewCoupon("user-1", "", "SAVE10")
+	assert.ErrorContains(t, err, "promo codes are not accepted")
+	service.SetCoupons(coupons)
+	_, err = service.PreviewCoupon("user-1", "", "SAVE10")
+	assert.ErrorContains(t, err, "cart is empty")
+
+	_, err = service.AddItem("user-1", "", lamp.ID, 1)
+	require.NoError(t, err)
+	_, err = service.PreviewCoupon("user-1", "", "SAVE10")
+	assert.ErrorContains(t, err, "minimum spend")
+
+	_, err = service.AddItem("user-1", "", lamp.ID, 1)
+	require.NoError(t, err)
+	preview, err := service.PreviewCoupon("user-1", "", "save10")
+	require.NoError(t, err)
+	assert.Equal(t, "SAVE10", preview.Code)
+	assert.Equal(t, 80.0, preview.Subtotal)
+	assert.Equal(t, 8.0, preview.Discount)
+	assert.Equal(t, 72.0, preview.DiscountedSubtotal)
+
+	// Previews do not use up the coupon
+	coupon, _, err := coupons.Evaluate("SAVE10", "user-1", 80, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, coupon.UsedCount)
+}
+
+func TestCartService_ShareCart(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 3, Status: models.ProductStatusActive}
+	mug := &models.Product{Name: "Mug", Price: 10, Stock: 20, Status: models.ProductStatusActive}
+	require.NoError(t, productRepo.Create(lamp))
+	require.NoError(t, productRepo.Create(mug))
+	service := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+	signer := signedurl.New("test_secret")
+	service.SetSharing(signer, time.Hour)
+
+	_, err := service.ShareCart("user-1", "")
+	assert.ErrorContains(t, err, "cart is empty")
+
+	_, err = service.AddItem("user-1", "", lamp.ID, 2)
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", "", mug.ID, 1)
+	require.NoError(t, err)
+	share, err := service.ShareCart("user-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, share.Items)
+	assert.NotEmpty(t, share.Token)
+
+	// Later changes to the shared cart are not part of the share
+	_, err = service.SetItemQuantity("user-1", "", mug.ID, 0)
+	require.NoError(t, err)
+
+	// An anonymous shopper gets a new cart holding the shared items
+	result, err := service.RedeemShare("", "", share.Token)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Cart.Token)
+	require.Len(t, result.Cart.Items, 2)
+	assert.Equal(t, 90.0, result.Cart.Subtotal)
+	assert.Empty(t, result.Adjustments)
+
+	// A user with a cart gets the quantities added up, within the stock
+	_, err = service.AddItem("user-2", "", lamp.ID, 2)
+	require.NoError(t, err)
+	result, err = service.RedeemShare("user-2", "", share.Token)
+	require.NoError(t, err)
+	require.Len(t, result.Cart.Items, 2)
+	assert.Equal(t, 3, result.Cart.Items[0].Quantity)
+	assert.Equal(t, []services.CartAdjustment{{ProductID: lamp.ID, Requested: 4, Quantity: 3, Reason: services.CartAdjustLimitedStock}}, result.Adjustments)
+
+	// The sharer's cart is untouched
+	sharer, err := service.GetCart("user-1", "")
+	require.NoError(t, err)
+	require.Len(t, sharer.Items, 1)
+	assert.Equal(t, 2, sharer.Items[0].Quantity)
+
+	_, err = service.RedeemShare("user-2", "", share.Token+"x")
+	assert.ErrorContains(t, err, "invalid cart share")
+	_, err = service.RedeemShare("user-2", "", signer.SignToken([]byte(`[{"p":"x","q":1}]`), time.Now().Add(-time.Minute)))
+	assert.ErrorContains(t, err, "expired")
+}
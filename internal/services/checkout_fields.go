@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"toko/internal/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Checkout field requirements.
+const (
+	FieldRequired = "required"
+	FieldOptional = "optional"
+	FieldHidden   = "hidden" // Not asked for; values sent anyway are dropped
+)
+
+// CheckoutField describes a configurable checkout field for storefronts.
+type CheckoutField struct {
+	Name        string `json:"name"`
+	Requirement string `json:"requirement"`
+	MaxLength   int    `json:"max_length"`
+}
+
+// checkoutFieldSpec defines a configurable field: its rules besides being
+// required and where the value lives on the order.
+type checkoutFieldSpec struct {
+	name      string
+	maxLength int
+	rules     string // Extra validator tags, if any
+	value     func(order *models.Order) *string
+}
+
+// checkoutFieldSpecs lists the checkout fields a store can configure.
+var checkoutFieldSpecs = []checkoutFieldSpec{
+	{name: "phone", maxLength: 50, rules: "printascii", value: func(o *models.Order) *string { return &o.ShippingAddress.Phone }},
+	{name: "company_name", maxLength: 255, value: func(o *models.Order) *string { return &o.CompanyName }},
+	{name: "tax_id", maxLength: 50, rules: "printascii", value: func(o *models.Order) *string { return &o.TaxID }},
+}
+
+// CheckoutFields validates the configurable checkout fields of orders
+// against the store's settings. The validation rules are built from the
+// settings when the store starts rather than fixed in struct tags.
+type CheckoutFields struct {
+	fields   []CheckoutField
+	specs    []checkoutFieldSpec
+	tags     []string // Validator tag per field; "" for hidden fields
+	validate *validator.Validate
+}
+
+// NewCheckoutFields builds the checkout rules from requirements keyed by
+// field name. Fields not mentioned are optional.
+func NewCheckoutFields(requirements map[string]string) (*CheckoutFields, error) {
+	known := make(map[string]bool)
+	for _, spec := range checkoutFieldSpecs {
+		known[spec.name] = true
+	}
+	for name := range requirements {
+		if !known[name] {
+			return nil, fmt.Errorf("invalid checkout field %q", name)
+		}
+	}
+
+	checkout := &CheckoutFields{validate: validator.New()}
+	for _, spec := range checkoutFieldSpecs {
+		requirement := strings.ToLower(strings.TrimSpace(requirements[spec.name]))
+		if requirement == "" {
+			requirement = FieldOptional
+		}
+		var tag string
+		switch requirement {
+		case FieldRequired:
+			tag = "required"
+		case FieldOptional:
+			tag = "omitempty"
+		case FieldHidden:
+		default:
+			return nil, fmt.Errorf("invalid checkout field %s: requirement must be required, optional or hidden, got %q", spec.name, requirement)
+		}
+		if tag != "" {
+			tag += fmt.Sprintf(",max=%d", spec.maxLength)
+			if spec.rules != "" {
+				tag += "," + spec.rules
+			}
+		}
+		checkout.fields = append(checkout.fields, CheckoutField{Name: spec.name, Requirement: requirement, MaxLength: spec.maxLength})
+		checkout.specs = append(checkout.specs, spec)
+		checkout.tags = append(checkout.tags, tag)
+	}
+	return checkout, nil
+}
+
+// Fields returns the configurable fields and whether storefronts should ask for them.
+func (c *CheckoutFields) Fields() []CheckoutField {
+	return c.fields
+}
+
+// Apply trims the configurable fields of an order, drops hidden ones and
+// checks the rest against their rules.
+func (c *CheckoutFields) Apply(order *models.Order) error {
+	for i, spec := range c.specs {
+		value := spec.value(order)
+		*value = strings.TrimSpace(*value)
+		if c.tags[i] == "" {
+			*value = ""
+			continue
+		}
+		if err := c.validate.Var(*value, c.tags[i]); err != nil {
+			return fmt.Errorf("invalid checkout field %s: %s", spec.name, describeFieldError(err, c.fields[i]))
+		}
+	}
+	return nil
+}
+
+// describeFieldError turns a validator error into a sentence for customers.
+func describeFieldError(err error, field CheckoutField) string {
+	if errs, ok := err.(validator.ValidationErrors); ok && len(errs) > 0 {
+		switch errs[0].Tag() {
+		case "required":
+			return "it is required"
+		case "max":
+			return fmt.Sprintf("at most %d characters are allowed", field.MaxLength)
+		case "printascii":
+			return "it contains characters that are not allowed"
+		}
+	}
+	return err.Error()
+}
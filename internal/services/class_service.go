@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/respcache"
+)
+
+// ClassService manages product categories and the tax and shipping classes
+// they map to, and resolves which classes apply to a product.
+type ClassService struct {
+	categoryRepo         repositories.CategoryRepository
+	classRepo            repositories.ClassRepository
+	defaultTaxClass      string
+	defaultShippingClass string
+	cache                *respcache.Cache
+}
+
+// NewClassService creates a new ClassService. Products without a class of
+// their own or from their category use the default classes.
+func NewClassService(categoryRepo repositories.CategoryRepository, classRepo repositories.ClassRepository, defaultTaxClass, defaultShippingClass string) *ClassService {
+	return &ClassService{
+		categoryRepo:         categoryRepo,
+		classRepo:            classRepo,
+		defaultTaxClass:      defaultTaxClass,
+		defaultShippingClass: defaultShippingClass,
+	}
+}
+
+// EnsureDefaults creates the default tax and shipping classes if they do not
+// exist yet, so products resolve to a class on a fresh install.
+func (s *ClassService) EnsureDefaults(defaultTaxRate float64) error {
+	if _, err := s.classRepo.GetTaxClass(s.defaultTaxClass); err != nil {
+		if !strings.Contains(err.Error(), "not found") {
+			return err
+		}
+		if err := s.classRepo.SaveTaxClass(&models.TaxClass{Code: s.defaultTaxClass, Name: "Standard", Rate: defaultTaxRate}); err != nil {
+			return err
+		}
+	}
+	if _, err := s.classRepo.GetShippingClass(s.defaultShippingClass); err != nil {
+		if !strings.Contains(err.Error(), "not found") {
+			return err
+		}
+		return s.classRepo.SaveShippingClass(&models.ShippingClass{Code: s.defaultShippingClass, Name: "Standard"})
+	}
+	return nil
+}
+
+// SetResponseCache invalidates cached category responses when categories change.
+func (s *ClassService) SetResponseCache(cache *respcache.Cache) {
+	s.cache = cache
+}
+
+// invalidateCategories drops cached category responses.
+func (s *ClassService) invalidateCategories() {
+	if s.cache != nil {
+		s.cache.Invalidate(CacheTagCategories)
+	}
+}
+
+// ListCategories returns all categories.
+func (s *ClassService) ListCategories() ([]models.Category, error) {
+	return s.categoryRepo.List()
+}
+
+// CreateCategory adds a category after checking that its classes exist.
+func (s *ClassService) CreateCategory(category *models.Category) error {
+	category.ID = ""
+	if err := s.checkClasses(category); err != nil {
+		return err
+	}
+	if err := s.categoryRepo.Create(category); err != nil {
+		return err
+	}
+	s.invalidateCategories()
+	return nil
+}
+
+// UpdateCategory replaces the name and classes of a category.
+func (s *ClassService) UpdateCategory(id string, changes *models.Category) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	category.Name = changes.Name
+	category.TaxClass = changes.TaxClass
+	category.ShippingClass = changes.ShippingClass
+	if err := s.checkClasses(category); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.Update(category); err != nil {
+		return nil, err
+	}
+	s.invalidateCategories()
+	return category, nil
+}
+
+// DeleteCategory removes a category.
+func (s *ClassService) DeleteCategory(id string) error {
+	if err := s.categoryRepo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidateCategories()
+	return nil
+}
+
+func (s *ClassService) checkClasses(category *models.Category) error {
+	category.TaxClass = strings.ToLower(strings.TrimSpace(category.TaxClass))
+	category.ShippingClass = strings.ToLower(strings.TrimSpace(category.ShippingClass))
+	if category.TaxClass != "" {
+		if _, err := s.classRepo.GetTaxClass(category.TaxClass); err != nil {
+			return fmt.Errorf("invalid category: %w", err)
+		}
+	}
+	if category.ShippingClass != "" {
+		if _, err := s.classRepo.GetShippingClass(category.ShippingClass); err != nil {
+			return fmt.Errorf("invalid category: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListTaxClasses returns all tax classes.
+func (s *ClassService) ListTaxClasses() ([]models.TaxClass, error) {
+	return s.classRepo.ListTaxClasses()
+}
+
+// SaveTaxClass creates or updates the tax class with the given code.
+func (s *ClassService) SaveTaxClass(code string, class *models.TaxClass) error {
+	class.Code = strings.ToLower(strings.TrimSpace(code))
+	if class.Code == "" {
+		return fmt.Errorf("invalid tax class: code is required")
+	}
+	if class.Rate < 0 || class.Rate > 1 {
+		return fmt.Errorf("invalid tax class: rate must be between 0 and 1")
+	}
+	return s.classRepo.SaveTaxClass(class)
+}
+
+// DeleteTaxClass removes a tax class. The default class cannot be removed.
+func (s *ClassService) DeleteTaxClass(code string) error {
+	if code == s.defaultTaxClass {
+		return fmt.Errorf("tax class %s is the default and cannot be deleted", code)
+	}
+	return s.classRepo.DeleteTaxClass(code)
+}
+
+// ListShippingClasses returns all shipping classes.
+func (s *ClassService) ListShippingClasses() ([]models.ShippingClass, error) {
+	return s.classRepo.ListShippingClasses()
+}
+
+// SaveShippingClass creates or updates the shipping class with the given code.
+func (s *ClassService) SaveShippingClass(code string, class *models.ShippingClass) error {
+	class.Code = strings.ToLower(strings.TrimSpace(code))
+	if class.Code == "" {
+		return fmt.Errorf("invalid shipping class: code is required")
+	}
+	if class.Surcharge < 0 {
+		return fmt.Errorf("invalid shipping class: surcharge cannot be negative")
+	}
+	return s.classRepo.SaveShippingClass(class)
+}
+
+// DeleteShippingClass removes a shipping class. The default class cannot be removed.
+func (s *ClassService) DeleteShippingClass(code string) error {
+	if code == s.defaultShippingClass {
+		return fmt.Errorf("shipping class %s is the default and cannot be deleted", code)
+	}
+	return s.classRepo.DeleteShippingClass(code)
+}
+
+// ResolveTaxClass returns the tax class of the product: its own, else its
+// category's, else the default.
+func (s *ClassService) ResolveTaxClass(product *models.Product) (*models.TaxClass, error) {
+	code := product.TaxClass
+	if code == "" {
+		if category := s.category(product); category != nil {
+			code = category.TaxClass
+		}
+	}
+	if code == "" {
+		code = s.defaultTaxClass
+	}
+	return s.classRepo.GetTaxClass(code)
+}
+
+// ResolveShippingClass returns the shipping class of the product: its own,
+// else its category's, else the default.
+func (s *ClassService) ResolveShippingClass(product *models.Product) (*models.ShippingClass, error) {
+	code := product.ShippingClass
+	if code == "" {
+		if category := s.category(product); category != nil {
+			code = category.ShippingClass
+		}
+	}
+	if code == "" {
+		code = s.defaultShippingClass
+	}
+	return s.classRepo.GetShippingClass(code)
+}
+
+// category returns the product's category, or nil when it has none or it was deleted.
+func (s *ClassService) category(product *models.Product) *models.Category {
+	if product.CategoryID == "" {
+		return nil
+	}
+	category, err := s.categoryRepo.GetByID(product.CategoryID)
+	if err != nil {
+		return nil
+	}
+	return category
+}
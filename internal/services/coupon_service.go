@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// CouponService manages promo codes and checks them against orders.
+type CouponService struct {
+	repo      repositories.CouponRepository
+	customers *CustomerService
+}
+
+// NewCouponService creates a new CouponService.
+func NewCouponService(repo repositories.CouponRepository) *CouponService {
+	return &CouponService{
+		repo: repo,
+	}
+}
+
+// SetCustomers enables coupon conditions on customer tags.
+func (s *CouponService) SetCustomers(customers *CustomerService) {
+	s.customers = customers
+}
+
+// NormalizeCouponCode returns the stored form of a code customers typed.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ListCoupons returns all coupons.
+func (s *CouponService) ListCoupons() ([]models.Coupon, error) {
+	return s.repo.List()
+}
+
+// GetCoupon returns a coupon by its ID.
+func (s *CouponService) GetCoupon(id string) (*models.Coupon, error) {
+	return s.repo.GetByID(id)
+}
+
+// CreateCoupon validates and stores a new coupon.
+func (s *CouponService) CreateCoupon(coupon *models.Coupon) error {
+	coupon.ID = ""
+	coupon.UsedCount = 0
+	if err := validateCoupon(coupon); err != nil {
+		return err
+	}
+	if existing, err := s.repo.GetByCode(coupon.Code); err == nil && existing != nil {
+		return fmt.Errorf("invalid coupon: code %s already exists", coupon.Code)
+	}
+	return s.repo.Create(coupon)
+}
+
+// UpdateCoupon replaces a coupon's settings. Its usage count is kept.
+func (s *CouponService) UpdateCoupon(id string, changes *models.Coupon) (*models.Coupon, error) {
+	coupon, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	changes.ID = coupon.ID
+	changes.UsedCount = coupon.UsedCount
+	changes.CreatedAt = coupon.CreatedAt
+	if err := validateCoupon(changes); err != nil {
+		return nil, err
+	}
+	if existing, err := s.repo.GetByCode(changes.Code); err == nil && existing != nil && existing.ID != id {
+		return nil, fmt.Errorf("invalid coupon: code %s already exists", changes.Code)
+	}
+	if err := s.repo.Update(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// DeleteCoupon removes a coupon. Orders keep the code and discount they got.
+func (s *CouponService) DeleteCoupon(id string) error {
+	return s.repo.Delete(id)
+}
+
+// Evaluate checks that a code can be used by the user on a subtotal right
+// now and returns the coupon and its discount. Nothing is recorded; usage
+// limits are enforced again atomically by Redeem.
+func (s *CouponService) Evaluate(code, userID string, subtotal float64, now time.Time) (*models.Coupon, float64, error) {
+	code = NormalizeCouponCode(code)
+	coupon, err := s.repo.GetByCode(code)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, 0, fmt.Errorf("invalid coupon: %s does not exist", code)
+		}
+		return nil, 0, err
+	}
+
+	switch {
+	case !coupon.Active:
+		return nil, 0, fmt.Errorf("invalid coupon: %s is not active", code)
+	case coupon.StartsAt != nil && now.Before(*coupon.StartsAt):
+		return nil, 0, fmt.Errorf("invalid coupon: %s is not valid yet", code)
+	case coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt):
+		return nil, 0, fmt.Errorf("invalid coupon: %s has expired", code)
+	case coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit:
+		return nil, 0, fmt.Errorf("invalid coupon: %s is no longer available", code)
+	case subtotal < coupon.MinSpend:
+		return nil, 0, fmt.Errorf("invalid coupon: %s requires a minimum spend of %.2f", code, coupon.MinSpend)
+	}
+	if err := s.checkCustomerTags(coupon, userID); err != nil {
+		return nil, 0, err
+	}
+	return coupon, coupon.Discount(subtotal), nil
+}
+
+// checkCustomerTags enforces a coupon's customer tag conditions.
+func (s *CouponService) checkCustomerTags(coupon *models.Coupon, userID string) error {
+	if coupon.CustomerTag == "" && coupon.ExcludeCustomerTag == "" {
+		return nil
+	}
+	var tags []string
+	if s.customers != nil && userID != "" {
+		var err error
+		if tags, err = s.customers.Tags(userID); err != nil {
+			return err
+		}
+	}
+	has := func(tag string) bool {
+		for _, t := range tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+	if (coupon.CustomerTag != "" && !has(coupon.CustomerTag)) || (coupon.ExcludeCustomerTag != "" && has(coupon.ExcludeCustomerTag)) {
+		return fmt.Errorf("invalid coupon: %s is not available to this customer", coupon.Code)
+	}
+	return nil
+}
+
+// Redeem counts a coupon use against an order.
+func (s *CouponService) Redeem(coupon *models.Coupon, orderID, userID string, amount float64) error {
+	return s.repo.Redeem(coupon, &models.CouponRedemption{OrderID: orderID, UserID: userID, Amount: amount})
+}
+
+// Release gives back the coupon use of an order that did not go ahead.
+func (s *CouponService) Release(orderID string) error {
+	return s.repo.Release(orderID)
+}
+
+func validateCoupon(coupon *models.Coupon) error {
+	coupon.Code = NormalizeCouponCode(coupon.Code)
+	coupon.CustomerTag = NormalizeCustomerTag(coupon.CustomerTag)
+	coupon.ExcludeCustomerTag = NormalizeCustomerTag(coupon.ExcludeCustomerTag)
+	switch {
+	case coupon.Code == "" || len(coupon.Code) > 50:
+		return fmt.Errorf("invalid coupon: code must be 1 to 50 characters")
+	case coupon.Type != models.CouponTypePercent && coupon.Type != models.CouponTypeFixed:
+		return fmt.Errorf("invalid coupon: type must be %s or %s", models.CouponTypePercent, models.CouponTypeFixed)
+	case coupon.Value <= 0:
+		return fmt.Errorf("invalid coupon: value must be positive")
+	case coupon.Type == models.CouponTypePercent && coupon.Value > 100:
+		return fmt.Errorf("invalid coupon: a percentage cannot exceed 100")
+	case coupon.MinSpend < 0 || coupon.UsageLimit < 0 || coupon.PerUserLimit < 0:
+		return fmt.Errorf("invalid coupon: limits cannot be negative")
+	case coupon.StartsAt != nil && coupon.ExpiresAt != nil && !coupon.StartsAt.Before(*coupon.ExpiresAt):
+		return fmt.Errorf("invalid coupon: starts_at must be before expires_at")
+	case coupon.CustomerTag != "" && !customerTagPattern.MatchString(coupon.CustomerTag),
+		coupon.ExcludeCustomerTag != "" && !customerTagPattern.MatchString(coupon.ExcludeCustomerTag):
+		return fmt.Errorf("invalid coupon: customer tags use lower-case letters, digits and dashes")
+	}
+	return nil
+}
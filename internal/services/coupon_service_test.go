@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_Evaluate(t *testing.T) {
+	service := services.NewCouponService(repositories.NewMockCouponRepository())
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+
+	assert.ErrorContains(t, service.CreateCoupon(&models.Coupon{Code: "BIG", Type: models.CouponTypePercent, Value: 150}), "invalid coupon")
+	assert.ErrorContains(t, service.CreateCoupon(&models.Coupon{Code: "ODD", Type: "bogo", Value: 1}), "invalid coupon")
+	require.NoError(t, service.CreateCoupon(&models.Coupon{Code: " save10 ", Type: models.CouponTypePercent, Value: 10, MinSpend: 50, Active: true}))
+	require.NoError(t, service.CreateCoupon(&models.Coupon{Code: "FIVE", Type: models.CouponTypeFixed, Value: 5, Active: true}))
+	require.NoError(t, service.CreateCoupon(&models.Coupon{Code: "OLD", Type: models.CouponTypeFixed, Value: 5, Active: true, ExpiresAt: &expired}))
+	assert.ErrorContains(t, service.CreateCoupon(&models.Coupon{Code: "Five", Type: models.CouponTypeFixed, Value: 1}), "already exists")
+
+	coupon, discount, err := service.Evaluate("Save10", "user-1", 80, now)
+	require.NoError(t, err)
+	assert.Equal(t, "SAVE10", coupon.Code)
+	assert.Equal(t, 8.0, discount)
+
+	_, _, err = service.Evaluate("SAVE10", "user-1", 40, now)
+	assert.ErrorContains(t, err, "minimum spend")
+	_, _, err = service.Evaluate("OLD", "user-1", 40, now)
+	assert.ErrorContains(t, err, "expired")
+	_, _, err = service.Evaluate("NOPE", "user-1", 40, now)
+	assert.ErrorContains(t, err, "does not exist")
+
+	// Fixed discounts never exceed the subtotal
+	_, discount, err = service.Evaluate("five", "user-1", 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, discount)
+}
+
+func TestOrderService_CreateOrderWithCoupon(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	request := models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 2}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+		CouponCode:      "WELCOME",
+	}
+	_, err := orderService.CreateOrder(context.Background(), request)
+	assert.ErrorContains(t, err, "invalid coupon") // Coupons are not enabled
+
+	coupons := services.NewCouponService(repositories.NewMockCouponRepository())
+	orderService.SetCoupons(coupons)
+	require.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "WELCOME", Type: models.CouponTypePercent, Value: 25, UsageLimit: 1, Active: true}))
+
+	order, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, order.Subtotal)
+	assert.Equal(t, 20.0, order.DiscountTotal)
+	assert.Equal(t, 60.0, order.GrandTotal)
+	assert.Equal(t, "WELCOME", order.CouponCode)
+
+	// The usage limit is spent until the order is cancelled
+	_, err = orderService.CreateOrder(context.Background(), request)
+	assert.ErrorContains(t, err, "no longer available")
+	_, err = orderService.CancelOrder(context.Background(), order.ID, "user-1", false, "", "")
+	require.NoError(t, err)
+	_, err = orderService.CreateOrder(context.Background(), request)
+	assert.NoError(t, err)
+}
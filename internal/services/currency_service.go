@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CurrencyConverter converts store prices to the currencies customers may pay
+// in, using exchange rates from the configuration.
+type CurrencyConverter struct {
+	base  string
+	rates map[string]float64 // Units of the currency per unit of base
+}
+
+// NewCurrencyConverter creates a converter from the store's base currency.
+// The base currency is always supported at a rate of 1.
+func NewCurrencyConverter(base string, rates map[string]float64) (*CurrencyConverter, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	if !isCurrencyCode(base) {
+		return nil, fmt.Errorf("invalid currency: %q is not a currency code", base)
+	}
+	converter := &CurrencyConverter{base: base, rates: map[string]float64{base: 1}}
+	for code, rate := range rates {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !isCurrencyCode(code) {
+			return nil, fmt.Errorf("invalid currency: %q is not a currency code", code)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate for %s: must be positive", code)
+		}
+		if code != base {
+			converter.rates[code] = rate
+		}
+	}
+	return converter, nil
+}
+
+// ParseExchangeRates parses rates written as "IDR=15800,EUR=0.92".
+func ParseExchangeRates(spec string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q: expected CODE=RATE", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate %q: %w", pair, err)
+		}
+		rates[strings.TrimSpace(code)] = rate
+	}
+	return rates, nil
+}
+
+// Base returns the store currency prices and order totals are kept in.
+func (c *CurrencyConverter) Base() string {
+	return c.base
+}
+
+// Rate returns the units of currency per unit of the base currency.
+func (c *CurrencyConverter) Rate(currency string) (float64, error) {
+	rate, ok := c.rates[strings.ToUpper(strings.TrimSpace(currency))]
+	if !ok {
+		return 0, fmt.Errorf("invalid currency: %s is not accepted, use one of %s", currency, strings.Join(c.Supported(), ", "))
+	}
+	return rate, nil
+}
+
+// Convert returns amount, in the base currency, in currency rounded to cents.
+func (c *CurrencyConverter) Convert(amount float64, currency string) (float64, error) {
+	rate, err := c.Rate(currency)
+	if err != nil {
+		return 0, err
+	}
+	return math.Round(amount*rate*100) / 100, nil
+}
+
+// Supported returns the accepted currencies in alphabetical order.
+func (c *CurrencyConverter) Supported() []string {
+	codes := make([]string, 0, len(c.rates))
+	for code := range c.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
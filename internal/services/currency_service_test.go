@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyConverter(t *testing.T) {
+	rates, err := services.ParseExchangeRates(" idr=15800, EUR=0.92 ,")
+	require.NoError(t, err)
+	converter, err := services.NewCurrencyConverter("usd", rates)
+	require.NoError(t, err)
+
+	assert.Equal(t, "USD", converter.Base())
+	assert.Equal(t, []string{"EUR", "IDR", "USD"}, converter.Supported())
+	rate, err := converter.Rate("idr")
+	require.NoError(t, err)
+	assert.Equal(t, 15800.0, rate)
+	_, err = converter.Rate("JPY")
+	assert.ErrorContains(t, err, "invalid currency")
+
+	_, err = services.ParseExchangeRates("EUR:0.92")
+	assert.Error(t, err)
+	_, err = services.NewCurrencyConverter("USD", map[string]float64{"EUR": 0})
+	assert.ErrorContains(t, err, "must be positive")
+	_, err = services.NewCurrencyConverter("dollar", nil)
+	assert.ErrorContains(t, err, "not a currency code")
+}
+
+func TestOrderService_CreateOrderFreezesExchangeRate(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	request := models.Order{
+		UserID:              "user-1",
+		Items:               []models.OrderItem{{ProductID: product.ID, Quantity: 2}},
+		ShippingAddress:     testPostalAddress("Jakarta"),
+		PresentmentCurrency: "EUR",
+	}
+
+	_, err := orderService.CreateOrder(context.Background(), request)
+	assert.ErrorContains(t, err, "invalid currency") // Multi-currency is not enabled
+
+	converter, err := services.NewCurrencyConverter("USD", map[string]float64{"EUR": 0.92})
+	require.NoError(t, err)
+	orderService.SetCurrencies(converter)
+	order, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", order.Currency)
+	assert.Equal(t, "EUR", order.PresentmentCurrency)
+	assert.Equal(t, 0.92, order.ExchangeRate)
+	assert.Equal(t, 73.6, order.PresentmentTotal)
+
+	// Later rate changes do not affect orders already placed
+	converter, err = services.NewCurrencyConverter("USD", map[string]float64{"EUR": 0.5})
+	require.NoError(t, err)
+	orderService.SetCurrencies(converter)
+	assert.Equal(t, 18.4, order.ToPresentment(20))
+
+	request.PresentmentCurrency = ""
+	order, err = orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", order.PresentmentCurrency)
+	assert.Equal(t, 1.0, order.ExchangeRate)
+	assert.Equal(t, order.GrandTotal, order.PresentmentTotal)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// maxCustomerNoteLength is the longest internal note staff can write.
+const maxCustomerNoteLength = 2000
+
+var customerTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// CustomerService manages the internal tags and notes staff keep on customers.
+type CustomerService struct {
+	repo      repositories.CustomerNoteRepository
+	userRepo  repositories.UserRepository
+	loginRepo repositories.LoginHistoryRepository
+}
+
+// NewCustomerService creates a new CustomerService.
+func NewCustomerService(repo repositories.CustomerNoteRepository, userRepo repositories.UserRepository) *CustomerService {
+	return &CustomerService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// SetLoginHistory adds the customer's last login and latest logins to
+// their profile, so staff can spot suspicious activity.
+func (s *CustomerService) SetLoginHistory(repo repositories.LoginHistoryRepository) {
+	s.loginRepo = repo
+}
+
+// CustomerProfile is what staff know about a customer, shown next to their orders.
+type CustomerProfile struct {
+	UserID      string                `json:"user_id"`
+	Tags        []string              `json:"tags"`
+	Notes       []models.CustomerNote `json:"notes"`
+	LastLoginAt *time.Time            `json:"last_login_at,omitempty"`
+	Logins      []models.LoginEvent   `json:"logins,omitempty"`
+}
+
+// NormalizeCustomerTag returns the stored form of a tag.
+func NormalizeCustomerTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Profile returns a customer's tags and notes, and their logins when login
+// history is kept.
+func (s *CustomerService) Profile(userID string) (*CustomerProfile, error) {
+	tags, err := s.Tags(userID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.repo.ListNotes(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile := &CustomerProfile{UserID: userID, Tags: tags, Notes: notes}
+	if s.loginRepo != nil {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		profile.LastLoginAt = user.LastLoginAt
+		if profile.Logins, err = s.loginRepo.ListByUser(userID, maxLoginHistory); err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
+
+// Tags returns the names of a customer's tags.
+func (s *CustomerService) Tags(userID string) ([]string, error) {
+	tags, err := s.repo.ListTags(userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Tag
+	}
+	return names, nil
+}
+
+// HasTag reports whether a customer has a tag.
+func (s *CustomerService) HasTag(userID, tag string) (bool, error) {
+	tags, err := s.Tags(userID)
+	if err != nil {
+		return false, err
+	}
+	tag = NormalizeCustomerTag(tag)
+	for _, name := range tags {
+		if name == tag {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddTag tags a customer on behalf of a staff member.
+func (s *CustomerService) AddTag(userID, tag, staffID string) error {
+	tag = NormalizeCustomerTag(tag)
+	if !customerTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid tag: use 1 to 50 lower-case letters, digits and dashes")
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return err
+	}
+	return s.repo.AddTag(&models.CustomerTag{UserID: userID, Tag: tag, CreatedBy: staffID})
+}
+
+// RemoveTag removes a tag from a customer.
+func (s *CustomerService) RemoveTag(userID, tag string) error {
+	return s.repo.RemoveTag(userID, NormalizeCustomerTag(tag))
+}
+
+// AddNote attaches an internal note written by a staff member to a customer.
+func (s *CustomerService) AddNote(userID, body, staffID string) (*models.CustomerNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > maxCustomerNoteLength {
+		return nil, fmt.Errorf("invalid note: body must be 1 to %d characters", maxCustomerNoteLength)
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+	note := &models.CustomerNote{UserID: userID, AuthorID: staffID, Body: body}
+	if err := s.repo.AddNote(note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// DeleteNote removes a customer's note.
+func (s *CustomerService) DeleteNote(userID string, id uint) error {
+	return s.repo.DeleteNote(userID, id)
+}
@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerService_TagsAndNotes(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1"}, nil)
+	userRepo.On("GetByID", mock.Anything).Return(nil, fmt.Errorf("user not found"))
+	service := services.NewCustomerService(repositories.NewMockCustomerNoteRepository(), userRepo)
+
+	require.NoError(t, service.AddTag("user-1", " VIP ", "admin-1"))
+	assert.ErrorContains(t, service.AddTag("user-1", "no spaces", "admin-1"), "invalid tag")
+	assert.ErrorContains(t, service.AddTag("ghost", "vip", "admin-1"), "not found")
+	has, err := service.HasTag("user-1", "vip")
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = service.AddNote("user-1", "   ", "admin-1")
+	assert.ErrorContains(t, err, "invalid note")
+	note, err := service.AddNote("user-1", "Asked for invoices by post", "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "admin-1", note.AuthorID)
+
+	profile, err := service.Profile("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip"}, profile.Tags)
+	assert.Len(t, profile.Notes, 1)
+
+	require.NoError(t, service.RemoveTag("user-1", "VIP"))
+	assert.ErrorContains(t, service.DeleteNote("user-2", note.ID), "not found")
+}
+
+func TestCustomerService_ProfileLogins(t *testing.T) {
+	lastLogin := time.Now()
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", LastLoginAt: &lastLogin}, nil)
+	history := repositories.NewMockLoginHistoryRepository()
+	require.NoError(t, history.Create(&models.LoginEvent{UserID: "user-1", IPAddress: "10.0.0.1", CreatedAt: lastLogin}))
+	require.NoError(t, history.Create(&models.LoginEvent{UserID: "user-2", IPAddress: "10.0.0.2", CreatedAt: lastLogin}))
+	service := services.NewCustomerService(repositories.NewMockCustomerNoteRepository(), userRepo)
+	service.SetLoginHistory(history)
+
+	profile, err := service.Profile("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, &lastLogin, profile.LastLoginAt)
+	require.Len(t, profile.Logins, 1)
+	assert.Equal(t, "10.0.0.1", profile.Logins[0].IPAddress)
+}
+
+func TestCouponService_CustomerTagConditions(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", mock.Anything).Return(&models.User{}, nil)
+	customers := services.NewCustomerService(repositories.NewMockCustomerNoteRepository(), userRepo)
+	require.NoError(t, customers.AddTag("vip-user", models.CustomerTagVIP, "admin-1"))
+	require.NoError(t, customers.AddTag("risky-user", models.CustomerTagChargebackRisk, "admin-1"))
+
+	service := services.NewCouponService(repositories.NewMockCouponRepository())
+	service.SetCustomers(customers)
+	require.NoError(t, service.CreateCoupon(&models.Coupon{Code: "VIPONLY", Type: models.CouponTypeFixed, Value: 5, Active: true, CustomerTag: "VIP"}))
+	require.NoError(t, service.CreateCoupon(&models.Coupon{Code: "WELCOME", Type: models.CouponTypeFixed, Value: 5, Active: true, ExcludeCustomerTag: models.CustomerTagChargebackRisk}))
+	assert.ErrorContains(t, service.CreateCoupon(&models.Coupon{Code: "BAD", Type: models.CouponTypeFixed, Value: 5, CustomerTag: "not a tag"}), "invalid coupon")
+
+	now := time.Now()
+	_, _, err := service.Evaluate("VIPONLY", "vip-user", 20, now)
+	assert.NoError(t, err)
+	_, _, err = service.Evaluate("VIPONLY", "other-user", 20, now)
+	assert.ErrorContains(t, err, "not available to this customer")
+	_, _, err = service.Evaluate("WELCOME", "other-user", 20, now)
+	assert.NoError(t, err)
+	_, _, err = service.Evaluate("WELCOME", "risky-user", 20, now)
+	assert.ErrorContains(t, err, "not available to this customer")
+}
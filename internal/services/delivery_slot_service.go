@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// DeliverySlotService manages the delivery windows customers choose from at
+// checkout and books them for orders.
+type DeliverySlotService struct {
+	repo repositories.DeliverySlotRepository
+}
+
+// NewDeliverySlotService creates a new DeliverySlotService.
+func NewDeliverySlotService(repo repositories.DeliverySlotRepository) *DeliverySlotService {
+	return &DeliverySlotService{
+		repo: repo,
+	}
+}
+
+// ListSlots returns every slot starting in [from, to), including full and inactive ones.
+func (s *DeliverySlotService) ListSlots(from, to time.Time) ([]models.DeliverySlot, error) {
+	return s.repo.List(from, to)
+}
+
+// AvailableSlots returns the active slots starting in [from, to) that have
+// not begun yet and still have capacity.
+func (s *DeliverySlotService) AvailableSlots(from, to time.Time) ([]models.DeliverySlot, error) {
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	slots, err := s.repo.List(from, to)
+	if err != nil {
+		return nil, err
+	}
+	available := []models.DeliverySlot{}
+	for _, slot := range slots {
+		if slot.Active && slot.Remaining() > 0 {
+			available = append(available, slot)
+		}
+	}
+	return available, nil
+}
+
+// CreateSlot adds a slot.
+func (s *DeliverySlotService) CreateSlot(slot *models.DeliverySlot) error {
+	slot.ID = ""
+	slot.Booked = 0
+	if err := validateDeliverySlot(slot); err != nil {
+		return err
+	}
+	return s.repo.Create(slot)
+}
+
+// UpdateSlot changes a slot's window, capacity and availability. Lowering the
+// capacity below the orders already booked only stops new bookings.
+func (s *DeliverySlotService) UpdateSlot(id string, changes *models.DeliverySlot) (*models.DeliverySlot, error) {
+	slot, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	changes.ID = slot.ID
+	changes.Booked = slot.Booked
+	changes.CreatedAt = slot.CreatedAt
+	if err := validateDeliverySlot(changes); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// DeleteSlot removes a slot that has no bookings; booked slots can be deactivated instead.
+func (s *DeliverySlotService) DeleteSlot(id string) error {
+	slot, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if slot.Booked > 0 {
+		return fmt.Errorf("invalid delivery slot: %d orders are booked in it; deactivate it instead", slot.Booked)
+	}
+	return s.repo.Delete(id)
+}
+
+// Book takes one unit of a slot's capacity for an order and returns the slot.
+func (s *DeliverySlotService) Book(id string) (*models.DeliverySlot, error) {
+	slot, err := s.repo.GetByID(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("invalid delivery slot: %s does not exist", id)
+		}
+		return nil, err
+	}
+	if !slot.StartsAt.After(time.Now()) {
+		return nil, fmt.Errorf("invalid delivery slot: the window has already started")
+	}
+	if err := s.repo.Book(id); err != nil {
+		if strings.Contains(err.Error(), "fully booked") {
+			return nil, fmt.Errorf("invalid delivery slot: the window is full")
+		}
+		return nil, err
+	}
+	return slot, nil
+}
+
+// Release gives back the capacity an order took in a slot.
+func (s *DeliverySlotService) Release(id string) error {
+	return s.repo.Release(id)
+}
+
+func validateDeliverySlot(slot *models.DeliverySlot) error {
+	switch {
+	case slot.StartsAt.IsZero() || slot.EndsAt.IsZero():
+		return fmt.Errorf("invalid delivery slot: starts_at and ends_at are required")
+	case !slot.EndsAt.After(slot.StartsAt):
+		return fmt.Errorf("invalid delivery slot: ends_at must be after starts_at")
+	case slot.Capacity <= 0:
+		return fmt.Errorf("invalid delivery slot: capacity must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// DeliveryZoneService manages the store's delivery zones and decides which
+// zone, if any, delivers to an address.
+type DeliveryZoneService struct {
+	repo repositories.DeliveryZoneRepository
+}
+
+// NewDeliveryZoneService creates a new DeliveryZoneService.
+func NewDeliveryZoneService(repo repositories.DeliveryZoneRepository) *DeliveryZoneService {
+	return &DeliveryZoneService{
+		repo: repo,
+	}
+}
+
+// ListZones returns all zones in the order they are matched.
+func (s *DeliveryZoneService) ListZones() ([]models.DeliveryZone, error) {
+	return s.repo.List()
+}
+
+// CreateZone adds a zone.
+func (s *DeliveryZoneService) CreateZone(zone *models.DeliveryZone) error {
+	zone.ID = ""
+	if err := validateDeliveryZone(zone); err != nil {
+		return err
+	}
+	return s.repo.Create(zone)
+}
+
+// UpdateZone replaces a zone's area, fees and availability.
+func (s *DeliveryZoneService) UpdateZone(id string, changes *models.DeliveryZone) (*models.DeliveryZone, error) {
+	zone, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	changes.ID = zone.ID
+	changes.CreatedAt = zone.CreatedAt
+	if err := validateDeliveryZone(changes); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// DeleteZone removes a zone.
+func (s *DeliveryZoneService) DeleteZone(id string) error {
+	return s.repo.Delete(id)
+}
+
+// Evaluate returns the zone that delivers to the address, checking that it
+// is active and that the order meets its minimum value. Where zones overlap
+// the highest priority wins. Without any zones nil is returned, so stores
+// that do not use zones deliver everywhere their couriers do.
+func (s *DeliveryZoneService) Evaluate(address models.PostalAddress, subtotal float64) (*models.DeliveryZone, error) {
+	zones, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return nil, nil
+	}
+	for i := range zones {
+		zone := &zones[i]
+		if !zone.Contains(address) {
+			continue
+		}
+		if !zone.Active {
+			return nil, fmt.Errorf("invalid delivery address: delivery to %s is currently unavailable", zone.Name)
+		}
+		if subtotal < zone.MinOrder {
+			return nil, fmt.Errorf("invalid order value: orders delivered to %s must be at least %.2f", zone.Name, zone.MinOrder)
+		}
+		return zone, nil
+	}
+	return nil, fmt.Errorf("invalid delivery address: the address is outside our delivery zones")
+}
+
+func validateDeliveryZone(zone *models.DeliveryZone) error {
+	zone.Name = strings.TrimSpace(zone.Name)
+	zone.Country = strings.ToUpper(strings.TrimSpace(zone.Country))
+	var codes []string
+	for _, code := range strings.Split(zone.PostalCodes, ",") {
+		if code = models.NormalizePostalCode(code); code != "" && code != "*" {
+			codes = append(codes, code)
+		}
+	}
+	zone.PostalCodes = strings.Join(codes, ",")
+
+	switch {
+	case zone.Name == "" || len(zone.Name) > 100:
+		return fmt.Errorf("invalid delivery zone: name must be 1 to 100 characters")
+	case zone.Country != "" && len(zone.Country) != 2:
+		return fmt.Errorf("invalid delivery zone: country must be a two-letter ISO 3166 code")
+	case zone.Fee < 0 || zone.MinOrder < 0:
+		return fmt.Errorf("invalid delivery zone: fee and minimum order cannot be negative")
+	case len(zone.Polygon) > 0 && len(zone.Polygon) < 3:
+		return fmt.Errorf("invalid delivery zone: a polygon needs at least 3 points")
+	case len(zone.Polygon) == 0 && len(codes) == 0:
+		return fmt.Errorf("invalid delivery zone: a polygon or postal codes are required")
+	}
+	for _, point := range zone.Polygon {
+		if point[0] < -90 || point[0] > 90 || point[1] < -180 || point[1] > 180 {
+			return fmt.Errorf("invalid delivery zone: point %v is not a valid latitude and longitude", point)
+		}
+	}
+	return nil
+}
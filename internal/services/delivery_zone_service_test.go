@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/courier"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryZoneService_Evaluate(t *testing.T) {
+	service := services.NewDeliveryZoneService(repositories.NewMockDeliveryZoneRepository())
+	address := testPostalAddress("Jakarta") // Postal code 10110
+
+	// Without zones every address is delivered to
+	zone, err := service.Evaluate(address, 10)
+	require.NoError(t, err)
+	assert.Nil(t, zone)
+
+	assert.ErrorContains(t, service.CreateZone(&models.DeliveryZone{Name: "Empty", Active: true}), "polygon or postal codes")
+	assert.ErrorContains(t, service.CreateZone(&models.DeliveryZone{Name: "Line", Polygon: [][2]float64{{0, 0}, {1, 1}}}), "at least 3 points")
+
+	central := &models.DeliveryZone{Name: "Central", Country: "id", PostalCodes: "101*, 102-10", Fee: 10000, Active: true}
+	require.NoError(t, service.CreateZone(central))
+	assert.Equal(t, "ID", central.Country)
+	assert.Equal(t, "101*,10210", central.PostalCodes)
+	// A square around Monas, drawn on the map
+	monas := &models.DeliveryZone{Name: "Monas", Priority: 10, MinOrder: 50000, Active: true,
+		Polygon: [][2]float64{{-6.17, 106.82}, {-6.17, 106.83}, {-6.18, 106.83}, {-6.18, 106.82}}}
+	require.NoError(t, service.CreateZone(monas))
+
+	zone, err = service.Evaluate(address, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "Central", zone.Name)
+
+	// The map zone has the higher priority and its own minimum order
+	pinned := address
+	pinned.Latitude, pinned.Longitude = -6.175, 106.827
+	_, err = service.Evaluate(pinned, 10)
+	assert.ErrorContains(t, err, "must be at least 50000.00")
+	zone, err = service.Evaluate(pinned, 60000)
+	require.NoError(t, err)
+	assert.Equal(t, "Monas", zone.Name)
+
+	outside := testPostalAddress("Bandung")
+	outside.PostalCode = "40115"
+	_, err = service.Evaluate(outside, 60000)
+	assert.ErrorContains(t, err, "outside our delivery zones")
+
+	central.Active = false
+	_, err = service.UpdateZone(central.ID, central)
+	require.NoError(t, err)
+	_, err = service.Evaluate(address, 10)
+	assert.ErrorContains(t, err, "currently unavailable")
+}
+
+func TestShippingService_AddsDeliveryZoneFee(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	shirt := &models.Product{Name: "Shirt", Price: 20, Stock: 10, WeightGrams: 300}
+	require.NoError(t, productRepo.Create(shirt))
+	zones := services.NewDeliveryZoneService(repositories.NewMockDeliveryZoneRepository())
+	require.NoError(t, zones.CreateZone(&models.DeliveryZone{Name: "Central", PostalCodes: "101*", Fee: 4, Active: true}))
+
+	service := services.NewShippingService(productRepo, nil, []courier.RateProvider{&courier.FlatRate{Amount: 6}}, courier.Address{City: "Jakarta"})
+	service.SetZones(zones)
+
+	quote, err := service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items:       []models.OrderItem{{ProductID: shirt.ID, Quantity: 1}},
+		Destination: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Central", quote.Zone)
+	require.Len(t, quote.Rates, 1)
+	assert.Equal(t, 10.0, quote.Rates[0].Amount)
+
+	outside := testPostalAddress("Bandung")
+	outside.PostalCode = "40115"
+	_, err = service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items:       []models.OrderItem{{ProductID: shirt.ID, Quantity: 1}},
+		Destination: outside,
+	})
+	assert.ErrorContains(t, err, "invalid delivery address")
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/signedurl"
+	"toko/pkg/storage"
+)
+
+// DownloadService handles files attached to digital products and the signed
+// links used to download them after purchase.
+type DownloadService struct {
+	productRepo repositories.ProductRepository
+	orderRepo   repositories.OrderRepository
+	storage     storage.Storage
+	signer      *signedurl.Signer
+	returnRepo  repositories.ReturnRepository // Optional; approved returns revoke an order's downloads
+	basePath    string                        // Public path prefix of the download endpoint
+	linkTTL     time.Duration                 // How long a generated link stays valid
+}
+
+// NewDownloadService creates a new DownloadService.
+func NewDownloadService(productRepo repositories.ProductRepository, orderRepo repositories.OrderRepository, store storage.Storage, signer *signedurl.Signer, basePath string, linkTTL time.Duration) *DownloadService {
+	return &DownloadService{
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		storage:     store,
+		signer:      signer,
+		basePath:    basePath,
+		linkTTL:     linkTTL,
+	}
+}
+
+// SetReturns revokes the downloads of orders whose return was approved.
+func (s *DownloadService) SetReturns(returnRepo repositories.ReturnRepository) {
+	s.returnRepo = returnRepo
+}
+
+// AttachFile stores the downloadable file for a digital product.
+func (s *DownloadService) AttachFile(productID, fileName string, content io.Reader) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsDigital() {
+		return nil, fmt.Errorf("product %s is not a digital product", productID)
+	}
+
+	fileName = filepath.Base(fileName)
+	key := fmt.Sprintf("products/%s/%s", product.ID, fileName)
+	if err := s.storage.Put(key, content); err != nil {
+		return nil, fmt.Errorf("failed to store file for product %s: %w", productID, err)
+	}
+
+	// Remove the previous file if it was stored under a different name
+	if product.FileKey != "" && product.FileKey != key {
+		_ = s.storage.Delete(product.FileKey)
+	}
+
+	product.FileKey = key
+	product.FileName = fileName
+	if err := s.productRepo.Update(product); err != nil {
+		return nil, fmt.Errorf("failed to update product %s: %w", productID, err)
+	}
+	return product, nil
+}
+
+// downloadableStatuses are the statuses of orders that are paid and released
+// to fulfillment, whose digital items can be downloaded.
+var downloadableStatuses = map[string]bool{"processing": true, "partially_shipped": true, "shipped": true, "delivered": true}
+
+// checkDownloadable returns an error unless the order's digital items can be
+// downloaded: it must be paid, not cancelled, and not returned.
+func (s *DownloadService) checkDownloadable(order *models.Order) error {
+	if order.Status == "cancelled" {
+		return fmt.Errorf("order %s is cancelled", order.ID)
+	}
+	if !downloadableStatuses[order.Status] {
+		return fmt.Errorf("order %s is not paid", order.ID)
+	}
+	if s.returnRepo == nil {
+		return nil
+	}
+	returns, err := s.returnRepo.ListByOrder(order.ID)
+	if err != nil {
+		return err
+	}
+	for _, request := range returns {
+		if request.Status == models.ReturnStatusApproved {
+			return fmt.Errorf("order %s was returned", order.ID)
+		}
+	}
+	return nil
+}
+
+// GenerateLinks returns signed download links for every digital item in the
+// order that was not cancelled.
+func (s *DownloadService) GenerateLinks(order *models.Order) ([]models.DownloadLink, error) {
+	if err := s.checkDownloadable(order); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.linkTTL)
+	links := []models.DownloadLink{}
+	for _, item := range order.Items {
+		if item.ItemStatus() == models.ItemStatusCancelled {
+			continue
+		}
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if !product.IsDigital() || product.FileKey == "" {
+			continue
+		}
+		links = append(links, models.DownloadLink{
+			ProductID: product.ID,
+			FileName:  product.FileName,
+			URL:       s.signer.Sign(s.downloadPath(order.ID, product.ID), expiresAt),
+			ExpiresAt: expiresAt,
+		})
+	}
+	return links, nil
+}
+
+// OpenDownload verifies a signed link and opens the purchased file.
+// The caller is responsible for closing the returned reader.
+func (s *DownloadService) OpenDownload(orderID, productID, expires, signature string) (io.ReadCloser, string, error) {
+	if err := s.signer.Verify(s.downloadPath(orderID, productID), expires, signature); err != nil {
+		return nil, "", err
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.checkDownloadable(order); err != nil {
+		return nil, "", err
+	}
+
+	purchased := false
+	for _, item := range order.Items {
+		if item.ProductID == productID && item.ItemStatus() != models.ItemStatusCancelled {
+			purchased = true
+			break
+		}
+	}
+	if !purchased {
+		return nil, "", fmt.Errorf("product %s not found in order %s", productID, orderID)
+	}
+
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, "", err
+	}
+	if product.FileKey == "" {
+		return nil, "", fmt.Errorf("file for product %s not found", productID)
+	}
+
+	file, err := s.storage.Open(product.FileKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, product.FileName, nil
+}
+
+func (s *DownloadService) downloadPath(orderID, productID string) string {
+	return fmt.Sprintf("%s/%s/%s", s.basePath, orderID, productID)
+}
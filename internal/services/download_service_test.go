@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/signedurl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadService_GenerateLinksNeedsPaidOrder(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	ebook := &models.Product{Name: "Ebook", Price: 10, Type: models.ProductTypeDigital, FileKey: "ebook.pdf", FileName: "ebook.pdf"}
+	require.NoError(t, productRepo.Create(ebook))
+	audiobook := &models.Product{Name: "Audiobook", Price: 15, Type: models.ProductTypeDigital, FileKey: "audiobook.mp3", FileName: "audiobook.mp3"}
+	require.NoError(t, productRepo.Create(audiobook))
+	returnRepo := repositories.NewMockReturnRepository()
+	service := services.NewDownloadService(productRepo, repositories.NewMockOrderRepository(), nil, signedurl.New("secret"), "/api/v1/downloads", time.Hour)
+	service.SetReturns(returnRepo)
+
+	order := &models.Order{ID: "order-1", Status: "pending", Items: []models.OrderItem{
+		{ProductID: ebook.ID, Quantity: 1, Status: models.ItemStatusPending},
+		{ProductID: audiobook.ID, Quantity: 1, Status: models.ItemStatusPending},
+	}}
+	_, err := service.GenerateLinks(order)
+	assert.ErrorContains(t, err, "not paid")
+	order.Status = "payment_failed"
+	_, err = service.GenerateLinks(order)
+	assert.ErrorContains(t, err, "not paid")
+
+	order.Status = "processing"
+	order.Items[0].Status = models.ItemStatusProcessing
+	order.Items[1].Status = models.ItemStatusCancelled
+	links, err := service.GenerateLinks(order)
+	require.NoError(t, err)
+	require.Len(t, links, 1, "cancelled items are not downloadable")
+	assert.Equal(t, ebook.ID, links[0].ProductID)
+
+	require.NoError(t, returnRepo.Create(&models.ReturnRequest{OrderID: order.ID, UserID: "user-1", Status: models.ReturnStatusApproved}))
+	_, err = service.GenerateLinks(order)
+	assert.ErrorContains(t, err, "returned")
+}
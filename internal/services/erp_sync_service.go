@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/erp"
+)
+
+// Conflict policies: which side wins when a field changed in both systems
+// since the last sync.
+const (
+	ConflictPreferExternal = "external"
+	ConflictPreferStore    = "store"
+)
+
+// erpOrderBatchSize is the number of orders loaded at a time when pushing orders.
+const erpOrderBatchSize = 200
+
+// ERPConnector is an adapter and what it syncs.
+type ERPConnector struct {
+	Name     string
+	Adapter  erp.Adapter
+	Entities []string // erp.EntityProducts, erp.EntityStock and erp.EntityOrders
+	// Fields renames store fields to the external system's, e.g. "sku" to
+	// "ItemCode". Unmapped fields keep their store names.
+	Fields         map[string]string
+	ConflictPolicy string // ConflictPreferExternal unless set
+}
+
+// field returns the external name of a store field.
+func (c *ERPConnector) field(name string) string {
+	if external, ok := c.Fields[name]; ok {
+		return external
+	}
+	return name
+}
+
+// ERPSyncService syncs products and stock both ways and pushes new orders to
+// external ERP and accounting systems. Products are matched by SKU; products
+// without one are not synced. Each run pushes store changes first and then
+// applies external changes, recording conflicts where both sides changed.
+type ERPSyncService struct {
+	repo        repositories.ERPSyncRepository
+	productRepo repositories.ProductRepository
+	orderRepo   repositories.OrderRepository
+	connectors  []ERPConnector
+	quotas      *QuotaService
+	mu          sync.Mutex // Runs one sync at a time
+}
+
+// NewERPSyncService creates a new ERPSyncService.
+func NewERPSyncService(repo repositories.ERPSyncRepository, productRepo repositories.ProductRepository, orderRepo repositories.OrderRepository, connectors []ERPConnector) *ERPSyncService {
+	return &ERPSyncService{
+		repo:        repo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		connectors:  connectors,
+	}
+}
+
+// Connectors returns the names of the configured connectors.
+func (s *ERPSyncService) Connectors() []string {
+	names := make([]string, len(s.connectors))
+	for i, connector := range s.connectors {
+		names[i] = connector.Name
+	}
+	return names
+}
+
+// States returns the sync state of every connector, entity and direction that has run.
+func (s *ERPSyncService) States() ([]models.SyncState, error) {
+	return s.repo.ListStates()
+}
+
+// Conflicts returns a page of the conflict log.
+func (s *ERPSyncService) Conflicts(opts repositories.SyncConflictListOptions) ([]models.SyncConflict, int64, error) {
+	return s.repo.ListConflicts(opts)
+}
+
+// SyncAll runs every connector. A failing connector does not stop the others.
+func (s *ERPSyncService) SyncAll(ctx context.Context, now time.Time) error {
+	var errs []error
+	for _, connector := range s.connectors {
+		if err := s.Sync(ctx, connector.Name, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync runs the named connector.
+func (s *ERPSyncService) Sync(ctx context.Context, name string, now time.Time) error {
+	var connector *ERPConnector
+	for i := range s.connectors {
+		if s.connectors[i].Name == name {
+			connector = &s.connectors[i]
+		}
+	}
+	if connector == nil {
+		return fmt.Errorf("ERP connector %s not found", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, entity := range connector.Entities {
+		if err := s.syncEntity(ctx, connector, entity, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s sync failed: %w", connector.Name, entity, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ERPSyncService) syncEntity(ctx context.Context, connector *ERPConnector, entity string, now time.Time) error {
+	switch entity {
+	case erp.EntityProducts, erp.EntityStock:
+	case erp.EntityOrders:
+		return s.run(connector, entity, models.SyncDirectionPush, now, func(state *models.SyncState) error {
+			return s.pushOrders(ctx, connector, state, now)
+		})
+	default:
+		return fmt.Errorf("invalid ERP entity %q", entity)
+	}
+
+	// Store changes made since the previous push may conflict with external changes
+	pushState, err := s.repo.GetState(connector.Name, entity, models.SyncDirectionPush)
+	if err != nil {
+		return err
+	}
+	storeChangedSince := pushState.Cursor
+	if err := s.run(connector, entity, models.SyncDirectionPush, now, func(state *models.SyncState) error {
+		return s.pushProducts(ctx, connector, entity, state)
+	}); err != nil {
+		return err
+	}
+	return s.run(connector, entity, models.SyncDirectionPull, now, func(state *models.SyncState) error {
+		return s.pullProducts(ctx, connector, entity, state, storeChangedSince)
+	})
+}
+
+// run loads the state of one direction, runs fn and saves the outcome. The
+// cursor only advances when fn succeeds, so failed changes are retried.
+func (s *ERPSyncService) run(connector *ERPConnector, entity, direction string, now time.Time, fn func(state *models.SyncState) error) error {
+	state, err := s.repo.GetState(connector.Name, entity, direction)
+	if err != nil {
+		return err
+	}
+	state.LastRunAt = now
+	state.Records, state.Conflicts = 0, 0
+	runErr := fn(state)
+	if runErr != nil {
+		state.Status, state.Error = models.SyncStatusFailed, runErr.Error()
+	} else {
+		state.Status, state.Error = models.SyncStatusOK, ""
+		state.Cursor = now
+	}
+	if err := s.repo.SaveState(state); err != nil {
+		log.Printf("Error saving %s %s %s sync state: %v", connector.Name, entity, direction, err)
+	}
+	return runErr
+}
+
+// productRecord maps a product onto the fields synced for the entity.
+func productRecord(connector *ERPConnector, entity string, product *models.Product) erp.Record {
+	record := erp.Record{
+		connector.field("sku"):        product.SKU,
+		connector.field("updated_at"): product.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if entity == erp.EntityStock {
+		record[connector.field("stock")] = strconv.Itoa(product.Stock)
+		return record
+	}
+	record[connector.field("name")] = product.Name
+	record[connector.field("description")] = product.Description
+	record[connector.field("price")] = formatAmount(product.Price)
+	record[connector.field("status")] = product.Status
+	return record
+}
+
+func (s *ERPSyncService) pushProducts(ctx context.Context, connector *ERPConnector, entity string, state *models.SyncState) error {
+	products, err := s.productRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	var records []erp.Record
+	for i := range products {
+		product := &products[i]
+		if product.SKU == "" || !product.UpdatedAt.After(state.Cursor) {
+			continue
+		}
+		if entity == erp.EntityStock && product.IsDigital() {
+			continue
+		}
+		records = append(records, productRecord(connector, entity, product))
+	}
+	if err := connector.Adapter.Push(ctx, entity, records); err != nil {
+		return err
+	}
+	state.Records = len(records)
+	return nil
+}
+
+func (s *ERPSyncService) pullProducts(ctx context.Context, connector *ERPConnector, entity string, state *models.SyncState, storeChangedSince time.Time) error {
+	records, err := connector.Adapter.Pull(ctx, entity, state.Cursor)
+	if err != nil {
+		return err
+	}
+	products, err := s.productRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	bySKU := make(map[string]*models.Product, len(products))
+	for i := range products {
+		if products[i].SKU != "" {
+			bySKU[products[i].SKU] = &products[i]
+		}
+	}
+
+	for _, record := range records {
+		sku := strings.TrimSpace(record[connector.field("sku")])
+		if sku == "" {
+			continue
+		}
+		if updatedAt, err := time.Parse(time.RFC3339, record[connector.field("updated_at")]); err == nil && !updatedAt.After(state.Cursor) {
+			continue // Unchanged since the last pull
+		}
+
+		product, ok := bySKU[sku]
+		if !ok {
+			if entity == erp.EntityStock {
+				log.Printf("Skipping %s stock for unknown SKU %s", connector.Name, sku)
+				continue
+			}
+			product, err = s.createPulledProduct(connector, sku, record)
+			if err != nil {
+				log.Printf("Skipping %s product %s: %v", connector.Name, sku, err)
+				continue
+			}
+			bySKU[sku] = product
+			state.Records++
+			continue
+		}
+
+		changed, err := s.applyPulledFields(connector, entity, product, record, state, storeChangedSince)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := s.productRepo.Update(product); err != nil {
+				return err
+			}
+			state.Records++
+		}
+	}
+	return nil
+}
+
+// syncedProductFields lists the product fields pulled for each entity.
+var syncedProductFields = map[string][]string{
+	erp.EntityProducts: {"name", "description", "price"},
+	erp.EntityStock:    {"stock"},
+}
+
+// applyPulledFields copies changed fields of an external record onto the
+// product. Fields also changed in the store since storeChangedSince are
+// logged as conflicts and resolved with the connector's policy.
+func (s *ERPSyncService) applyPulledFields(connector *ERPConnector, entity string, product *models.Product, record erp.Record, state *models.SyncState, storeChangedSince time.Time) (bool, error) {
+	storeChanged := !storeChangedSince.IsZero() && product.UpdatedAt.After(storeChangedSince)
+	changed := false
+	for _, field := range syncedProductFields[entity] {
+		external, ok := record[connector.field(field)]
+		if !ok {
+			continue
+		}
+		current := productField(product, field)
+		if sameFieldValue(field, current, external) {
+			continue
+		}
+		if storeChanged {
+			resolution := ConflictPreferExternal
+			if connector.ConflictPolicy == ConflictPreferStore {
+				resolution = ConflictPreferStore
+			}
+			state.Conflicts++
+			if err := s.repo.AddConflict(&models.SyncConflict{
+				Connector:     connector.Name,
+				Entity:        entity,
+				Key:           product.SKU,
+				Field:         field,
+				StoreValue:    current,
+				ExternalValue: external,
+				Resolution:    resolution,
+			}); err != nil {
+				return false, err
+			}
+			if resolution == ConflictPreferStore {
+				continue
+			}
+		}
+		if err := setProductField(product, field, external); err != nil {
+			log.Printf("Skipping %s %s of %s: %v", connector.Name, field, product.SKU, err)
+			continue
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// SetQuotas keeps products pulled from the ERP within the plan's catalog limit.
+func (s *ERPSyncService) SetQuotas(quotas *QuotaService) {
+	s.quotas = quotas
+}
+
+func (s *ERPSyncService) createPulledProduct(connector *ERPConnector, sku string, record erp.Record) (*models.Product, error) {
+	product := &models.Product{
+		SKU:    sku,
+		Status: models.ProductStatusActive,
+		Type:   models.ProductTypePhysical,
+	}
+	for _, field := range syncedProductFields[erp.EntityProducts] {
+		if err := setProductField(product, field, record[connector.field(field)]); err != nil {
+			return nil, err
+		}
+	}
+	if product.Name == "" || product.Price <= 0 {
+		return nil, fmt.Errorf("new products need a name and a price")
+	}
+	if s.quotas != nil {
+		if err := s.quotas.CheckProducts(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.productRepo.Create(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func productField(product *models.Product, field string) string {
+	switch field {
+	case "name":
+		return product.Name
+	case "description":
+		return product.Description
+	case "price":
+		return formatAmount(product.Price)
+	case "stock":
+		return strconv.Itoa(product.Stock)
+	}
+	return ""
+}
+
+func setProductField(product *models.Product, field, value string) error {
+	value = strings.TrimSpace(value)
+	switch field {
+	case "name":
+		product.Name = value
+	case "description":
+		product.Description = value
+	case "price":
+		if value == "" {
+			return nil
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("invalid price %q", value)
+		}
+		product.Price = price
+	case "stock":
+		stock, err := strconv.Atoi(value)
+		if err != nil || stock < 0 {
+			return fmt.Errorf("invalid stock %q", value)
+		}
+		product.Stock = stock
+	}
+	return nil
+}
+
+// sameFieldValue compares numbers numerically so "10" and "10.00" match.
+func sameFieldValue(field, current, external string) bool {
+	external = strings.TrimSpace(external)
+	if field == "price" || field == "stock" {
+		a, errA := strconv.ParseFloat(current, 64)
+		b, errB := strconv.ParseFloat(external, 64)
+		if errA == nil && errB == nil {
+			return a == b
+		}
+	}
+	return current == external
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// pushOrders sends the orders placed since the last push, one record per item.
+func (s *ERPSyncService) pushOrders(ctx context.Context, connector *ERPConnector, state *models.SyncState, now time.Time) error {
+	skus := map[string]string{}
+	if products, err := s.productRepo.GetAll(); err == nil {
+		for _, product := range products {
+			skus[product.ID] = product.SKU
+		}
+	}
+
+	opts := repositories.OrderListOptions{From: state.Cursor, To: now, Limit: erpOrderBatchSize}
+	var records []erp.Record
+	for opts.Offset = 0; ; opts.Offset += erpOrderBatchSize {
+		orders, _, err := s.orderRepo.List(opts)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			for _, item := range order.Items {
+				records = append(records, erp.Record{
+					connector.field("order_id"):       order.ID,
+					connector.field("created_at"):     order.CreatedAt.UTC().Format(time.RFC3339),
+					connector.field("user_id"):        order.UserID,
+					connector.field("status"):         order.Status,
+					connector.field("sku"):            skus[item.ProductID],
+					connector.field("product_id"):     item.ProductID,
+					connector.field("quantity"):       strconv.Itoa(item.Quantity),
+					connector.field("price"):          formatAmount(item.Price),
+					connector.field("subtotal"):       formatAmount(order.Subtotal),
+					connector.field("discount_total"): formatAmount(order.DiscountTotal),
+					connector.field("tax_total"):      formatAmount(order.TaxTotal),
+					connector.field("shipping_total"): formatAmount(order.ShippingTotal),
+					connector.field("grand_total"):    formatAmount(order.GrandTotal),
+				})
+			}
+		}
+		if len(orders) < erpOrderBatchSize {
+			break
+		}
+	}
+	if err := connector.Adapter.Push(ctx, erp.EntityOrders, records); err != nil {
+		return err
+	}
+	state.Records = len(records)
+	return nil
+}
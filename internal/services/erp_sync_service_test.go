@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/erp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeERPAdapter records pushes and serves canned pulls.
+type fakeERPAdapter struct {
+	pulls  map[string][]erp.Record
+	pushes map[string][]erp.Record
+	since  map[string]time.Time
+}
+
+func newFakeERPAdapter() *fakeERPAdapter {
+	return &fakeERPAdapter{pulls: map[string][]erp.Record{}, pushes: map[string][]erp.Record{}, since: map[string]time.Time{}}
+}
+
+func (a *fakeERPAdapter) Name() string { return "fake" }
+
+func (a *fakeERPAdapter) Pull(ctx context.Context, entity string, since time.Time) ([]erp.Record, error) {
+	a.since[entity] = since
+	return a.pulls[entity], nil
+}
+
+func (a *fakeERPAdapter) Push(ctx context.Context, entity string, records []erp.Record) error {
+	a.pushes[entity] = append(a.pushes[entity], records...)
+	return nil
+}
+
+func newERPSyncTestService(adapter *fakeERPAdapter, policy string, entities ...string) (*services.ERPSyncService, *repositories.MockProductRepository, *repositories.MockOrderRepository, *repositories.MockERPSyncRepository) {
+	syncRepo := repositories.NewMockERPSyncRepository()
+	productRepo := repositories.NewMockProductRepository()
+	orderRepo := repositories.NewMockOrderRepository()
+	service := services.NewERPSyncService(syncRepo, productRepo, orderRepo, []services.ERPConnector{{
+		Name:           "fake",
+		Adapter:        adapter,
+		Entities:       entities,
+		Fields:         map[string]string{"sku": "ItemCode"},
+		ConflictPolicy: policy,
+	}})
+	return service, productRepo, orderRepo, syncRepo
+}
+
+// syncedProduct returns a product last changed at updatedAt, which the mock
+// repository does not set itself.
+func syncedProduct(id, sku string, stock int, updatedAt time.Time) *models.Product {
+	product := &models.Product{ID: id, SKU: sku, Name: "Kopi", Price: 50000, Stock: stock}
+	product.UpdatedAt = updatedAt
+	return product
+}
+
+func TestERPSyncService_PushesAndPullsProducts(t *testing.T) {
+	adapter := newFakeERPAdapter()
+	service, productRepo, _, syncRepo := newERPSyncTestService(adapter, "", erp.EntityProducts)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, productRepo.Create(syncedProduct("p1", "SKU-1", 0, start.Add(-time.Hour))))
+	require.NoError(t, productRepo.Create(syncedProduct("p2", "", 0, start.Add(-time.Hour))))
+	adapter.pulls[erp.EntityProducts] = []erp.Record{
+		{"ItemCode": "SKU-1", "price": "55000"},
+		{"ItemCode": "SKU-9", "name": "Teh", "price": "20000"},
+	}
+
+	require.NoError(t, service.Sync(context.Background(), "fake", start))
+
+	require.Len(t, adapter.pushes[erp.EntityProducts], 1)
+	assert.Equal(t, "SKU-1", adapter.pushes[erp.EntityProducts][0]["ItemCode"])
+	assert.Equal(t, "50000.00", adapter.pushes[erp.EntityProducts][0]["price"])
+
+	product, err := productRepo.GetByID("p1")
+	require.NoError(t, err)
+	assert.Equal(t, 55000.0, product.Price)
+	products, err := productRepo.GetAll()
+	require.NoError(t, err)
+	assert.Len(t, products, 3)
+
+	state, err := syncRepo.GetState("fake", erp.EntityProducts, models.SyncDirectionPull)
+	require.NoError(t, err)
+	assert.Equal(t, models.SyncStatusOK, state.Status)
+	assert.Equal(t, 2, state.Records)
+	assert.Equal(t, start, state.Cursor)
+
+	// The next run pulls from the cursor and pushes nothing unchanged
+	adapter.pushes = map[string][]erp.Record{}
+	adapter.pulls[erp.EntityProducts] = nil
+	require.NoError(t, service.Sync(context.Background(), "fake", start.Add(time.Hour)))
+	assert.Equal(t, start, adapter.since[erp.EntityProducts])
+	assert.Empty(t, adapter.pushes[erp.EntityProducts])
+}
+
+func TestERPSyncService_LogsConflicts(t *testing.T) {
+	adapter := newFakeERPAdapter()
+	service, productRepo, _, syncRepo := newERPSyncTestService(adapter, services.ConflictPreferStore, erp.EntityStock)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, productRepo.Create(syncedProduct("p1", "SKU-1", 10, start.Add(-time.Hour))))
+	require.NoError(t, service.Sync(context.Background(), "fake", start))
+
+	// Both sides change the stock before the next run
+	product, err := productRepo.GetByID("p1")
+	require.NoError(t, err)
+	product.Stock = 8
+	product.UpdatedAt = start.Add(30 * time.Minute)
+	require.NoError(t, productRepo.Update(product))
+	adapter.pulls[erp.EntityStock] = []erp.Record{{"ItemCode": "SKU-1", "stock": "20"}}
+
+	require.NoError(t, service.Sync(context.Background(), "fake", start.Add(time.Hour)))
+
+	product, err = productRepo.GetByID("p1")
+	require.NoError(t, err)
+	assert.Equal(t, 8, product.Stock, "the store policy keeps the store value")
+
+	conflicts, total, err := service.Conflicts(repositories.SyncConflictListOptions{Connector: "fake"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "SKU-1", conflicts[0].Key)
+	assert.Equal(t, "stock", conflicts[0].Field)
+	assert.Equal(t, "8", conflicts[0].StoreValue)
+	assert.Equal(t, "20", conflicts[0].ExternalValue)
+	assert.Equal(t, services.ConflictPreferStore, conflicts[0].Resolution)
+
+	state, err := syncRepo.GetState("fake", erp.EntityStock, models.SyncDirectionPull)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Conflicts)
+}
+
+func TestERPSyncService_PushesNewOrders(t *testing.T) {
+	adapter := newFakeERPAdapter()
+	service, productRepo, orderRepo, _ := newERPSyncTestService(adapter, "", erp.EntityOrders)
+	require.NoError(t, productRepo.Create(&models.Product{ID: "p1", SKU: "SKU-1", Name: "Kopi", Price: 50000}))
+	require.NoError(t, orderRepo.Create(&models.Order{ID: "o1", UserID: "u1", Status: "paid", GrandTotal: 100000, Items: []models.OrderItem{{ProductID: "p1", Quantity: 2, Price: 50000}}}))
+
+	now := time.Now().Add(time.Minute)
+	require.NoError(t, service.Sync(context.Background(), "fake", now))
+	require.Len(t, adapter.pushes[erp.EntityOrders], 1)
+	record := adapter.pushes[erp.EntityOrders][0]
+	assert.Equal(t, "o1", record["order_id"])
+	assert.Equal(t, "SKU-1", record["ItemCode"])
+	assert.Equal(t, "2", record["quantity"])
+	assert.Equal(t, "100000.00", record["grand_total"])
+
+	// Orders already pushed are not sent again
+	require.NoError(t, service.Sync(context.Background(), "fake", now.Add(time.Minute)))
+	assert.Len(t, adapter.pushes[erp.EntityOrders], 1)
+}
+
+func TestERPSyncService_UnknownConnector(t *testing.T) {
+	service, _, _, _ := newERPSyncTestService(newFakeERPAdapter(), "")
+	err := service.Sync(context.Background(), "sap", time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
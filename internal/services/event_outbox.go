@@ -0,0 +1,59 @@
+package services
+
+import (
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/rabbitmq"
+)
+
+// EventOutbox keeps events in the database while RabbitMQ is unreachable,
+// so that orders are still accepted and their events published later.
+type EventOutbox struct {
+	repo repositories.OutboxRepository
+}
+
+// NewEventOutbox creates a new EventOutbox.
+func NewEventOutbox(repo repositories.OutboxRepository) *EventOutbox {
+	return &EventOutbox{repo: repo}
+}
+
+// Add stores a message the broker could not take.
+func (o *EventOutbox) Add(msg rabbitmq.OutboxMessage) error {
+	return o.repo.Add(&models.OutboxEvent{
+		Exchange:   msg.Exchange,
+		RoutingKey: msg.RoutingKey,
+		MessageID:  msg.MessageID,
+		Body:       msg.Body,
+		Headers:    msg.Headers,
+	})
+}
+
+// Pending returns up to limit stored messages, oldest first.
+func (o *EventOutbox) Pending(limit int) ([]rabbitmq.OutboxMessage, error) {
+	events, err := o.repo.Pending(limit)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]rabbitmq.OutboxMessage, len(events))
+	for i, event := range events {
+		messages[i] = rabbitmq.OutboxMessage{
+			ID:         event.ID,
+			Exchange:   event.Exchange,
+			RoutingKey: event.RoutingKey,
+			MessageID:  event.MessageID,
+			Body:       event.Body,
+			Headers:    event.Headers,
+		}
+	}
+	return messages, nil
+}
+
+// Remove deletes a published message.
+func (o *EventOutbox) Remove(id uint) error {
+	return o.repo.Delete(id)
+}
+
+// Count returns how many messages wait to be published.
+func (o *EventOutbox) Count() (int64, error) {
+	return o.repo.Count()
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"toko/internal/events"
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+)
+
+// Publisher publishes messages to the message broker. *rabbitmq.Client
+// implements it, and so does *rabbitmq.MemoryBroker for tests.
+type Publisher interface {
+	PublishWithHeaders(exchange, routingKey string, body []byte, headers amqp.Table) error
+}
+
+var (
+	_ Publisher = (*rabbitmq.Client)(nil)
+	_ Publisher = (*rabbitmq.MemoryBroker)(nil)
+)
+
+// publishEvent marshals payload to JSON, validates it against the event's
+// schema and publishes it to RabbitMQ. Failures are logged rather than
+// returned so that event delivery never blocks the business operation that
+// triggered it; payloads violating their schema are never published. The
+// correlation in ctx, set for HTTP requests by middleware.RequestContext, is
+// copied into the message headers.
+func publishEvent(ctx context.Context, mqClient Publisher, exchange, routingKey string, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal %s event: %v", routingKey, err)
+		return
+	}
+
+	registry := events.DefaultRegistry()
+	if err := registry.Validate(events.DirectionPublish, routingKey, 0, body); err != nil {
+		log.Printf("Error: Refusing to publish event that violates its contract: %v", err)
+		return
+	}
+
+	if mqClient == nil {
+		log.Printf("RabbitMQ client is not initialized. Skipping %s event.", routingKey)
+		return
+	}
+
+	headers := amqp.Table{}
+	if version := registry.LatestVersion(routingKey); version > 0 {
+		headers[events.SchemaVersionHeader] = int32(version)
+	}
+	events.CorrelationFromContext(ctx).Headers(headers)
+	if err := mqClient.PublishWithHeaders(exchange, routingKey, body, headers); err != nil {
+		log.Printf("Warning: Failed to publish %s event: %v", routingKey, err)
+	}
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/signedurl"
+	"toko/pkg/storage"
+)
+
+// exportTaskType is the task queue type used to generate exports.
+const exportTaskType = "export.generate"
+
+// orderCSVBatchSize is the number of orders loaded per query while streaming an order CSV.
+const orderCSVBatchSize = 500
+
+// exportTask is the payload of an export task.
+type exportTask struct {
+	JobID string `json:"job_id"`
+}
+
+// ExportService runs long exports in the background and tracks their progress.
+type ExportService struct {
+	exportRepo  repositories.ExportJobRepository
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	userRepo    repositories.UserRepository
+	addressRepo repositories.AddressRepository
+	storage     storage.Storage
+	signer      *signedurl.Signer
+	queue       *jobs.Queue
+	linkTTL     time.Duration
+}
+
+// NewExportService creates a new ExportService and registers its task handler on queue.
+func NewExportService(exportRepo repositories.ExportJobRepository, orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, userRepo repositories.UserRepository, store storage.Storage, signer *signedurl.Signer, queue *jobs.Queue, linkTTL time.Duration) *ExportService {
+	s := &ExportService{
+		exportRepo:  exportRepo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		storage:     store,
+		signer:      signer,
+		queue:       queue,
+		linkTTL:     linkTTL,
+	}
+	jobs.Handle(queue, exportTaskType, s.runExport)
+	return s
+}
+
+// SetAddresses adds the user's address book to personal data exports.
+func (s *ExportService) SetAddresses(addressRepo repositories.AddressRepository) {
+	s.addressRepo = addressRepo
+}
+
+// StartExport creates an export job for the user and queues it for generation.
+func (s *ExportService) StartExport(userID, exportType string) (*models.ExportJob, error) {
+	switch exportType {
+	case models.ExportTypeOrders, models.ExportTypeProducts, models.ExportTypeGDPR:
+	default:
+		return nil, fmt.Errorf("invalid export type: %s", exportType)
+	}
+
+	job := &models.ExportJob{
+		UserID: userID,
+		Type:   exportType,
+		Status: models.ExportStatusQueued,
+	}
+	if err := s.exportRepo.Create(job); err != nil {
+		return nil, err
+	}
+	if _, err := s.queue.Enqueue(exportTaskType, exportTask{JobID: job.ID}); err != nil {
+		return nil, fmt.Errorf("failed to queue export %s: %w", job.ID, err)
+	}
+	return job, nil
+}
+
+// GetJob returns the user's export job, with a signed download URL once it has completed.
+func (s *ExportService) GetJob(id, userID string) (*models.ExportJob, error) {
+	job, err := s.exportRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, fmt.Errorf("export job with ID %s not found", id)
+	}
+	if job.Status == models.ExportStatusCompleted {
+		job.DownloadURL = s.signer.Sign(s.downloadPath(job.ID), time.Now().Add(s.linkTTL))
+	}
+	return job, nil
+}
+
+// OpenDownload verifies a signed export link and opens the generated file.
+// The caller is responsible for closing the returned reader.
+func (s *ExportService) OpenDownload(id, expires, signature string) (*models.ExportJob, io.ReadCloser, error) {
+	if err := s.signer.Verify(s.downloadPath(id), expires, signature); err != nil {
+		return nil, nil, err
+	}
+	job, err := s.exportRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != models.ExportStatusCompleted {
+		return nil, nil, fmt.Errorf("export %s not found", id)
+	}
+
+	file, err := s.storage.Open(job.FileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, file, nil
+}
+
+func (s *ExportService) downloadPath(id string) string {
+	return fmt.Sprintf("/api/v1/jobs/%s/download", id)
+}
+
+// runExport is the task handler that generates an export file.
+func (s *ExportService) runExport(ctx context.Context, task *models.Task, payload exportTask) error {
+	job, err := s.exportRepo.GetByID(payload.JobID)
+	if err != nil {
+		return err
+	}
+
+	job.Status = models.ExportStatusRunning
+	job.Progress = 0
+	job.Error = ""
+	if err := s.exportRepo.Update(job); err != nil {
+		return err
+	}
+
+	var content []byte
+	switch job.Type {
+	case models.ExportTypeOrders:
+		content, err = s.exportOrders(job)
+		job.FileName = fmt.Sprintf("orders-%s.csv", time.Now().Format("20060102"))
+	case models.ExportTypeProducts:
+		content, err = s.exportProducts(job)
+		job.FileName = fmt.Sprintf("products-%s.csv", time.Now().Format("20060102"))
+	case models.ExportTypeGDPR:
+		content, err = s.exportPersonalData(job)
+		job.FileName = fmt.Sprintf("personal-data-%s.zip", time.Now().Format("20060102"))
+	default:
+		err = fmt.Errorf("invalid export type: %s", job.Type)
+	}
+	if err == nil {
+		job.FileKey = fmt.Sprintf("exports/%s/%s", job.ID, job.FileName)
+		err = s.storage.Put(job.FileKey, bytes.NewReader(content))
+	}
+
+	if err != nil {
+		// Only give up on the job once the task queue has no attempts left
+		if task.Attempts >= task.MaxAttempts {
+			job.Status = models.ExportStatusFailed
+		} else {
+			job.Status = models.ExportStatusQueued
+		}
+		job.Error = err.Error()
+		if updateErr := s.exportRepo.Update(job); updateErr != nil {
+			log.Printf("Failed to update export job %s: %v", job.ID, updateErr)
+		}
+		return err
+	}
+
+	now := time.Now()
+	job.Status = models.ExportStatusCompleted
+	job.Progress = 100
+	job.CompletedAt = &now
+	return s.exportRepo.Update(job)
+}
+
+// reportProgress stores the job's progress, at most every 10 percentage points.
+func (s *ExportService) reportProgress(job *models.ExportJob, done, total int) {
+	if total == 0 {
+		return
+	}
+	progress := done * 100 / total
+	if progress >= 100 || progress-job.Progress < 10 {
+		return
+	}
+	job.Progress = progress
+	if err := s.exportRepo.Update(job); err != nil {
+		log.Printf("Failed to update progress of export job %s: %v", job.ID, err)
+	}
+}
+
+func (s *ExportService) exportOrders(job *models.ExportJob) ([]byte, error) {
+	orders, err := s.orderRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Admins export every order; customers only their own
+	includeAll := false
+	if user, err := s.userRepo.GetByID(job.UserID); err == nil && user.Role == models.RoleAdmin {
+		includeAll = true
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"order_id", "user_id", "status", "subtotal", "discount_total", "tax_total", "shipping_total", "grand_total", "created_at", "product_id", "quantity", "price"})
+	for i, order := range orders {
+		if !includeAll && order.UserID != job.UserID {
+			continue
+		}
+		for _, item := range order.Items {
+			row := append([]string{order.ID, order.UserID, order.Status}, orderTotalsCSV(&order)...)
+			w.Write(append(row,
+				order.CreatedAt.Format(time.RFC3339),
+				item.ProductID,
+				strconv.Itoa(item.Quantity),
+				strconv.FormatFloat(item.Price, 'f', 2, 64),
+			))
+		}
+		s.reportProgress(job, i+1, len(orders))
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// StreamOrdersCSV validates the filter and returns a function that writes the
+// matching orders as CSV, one row per item with the order's totals repeated.
+// Orders are loaded in batches so memory stays bounded for any period. The
+// upper bound defaults to now so orders placed while streaming cannot shift
+// the batches.
+func (s *ExportService) StreamOrdersCSV(opts repositories.OrderListOptions) (func(w io.Writer) error, error) {
+	if opts.Status != "" && !validOrderStatuses[opts.Status] {
+		return nil, fmt.Errorf("invalid order status: %s", opts.Status)
+	}
+	if opts.To.IsZero() {
+		opts.To = time.Now()
+	}
+	if !opts.From.IsZero() && !opts.From.Before(opts.To) {
+		return nil, fmt.Errorf("invalid date range: from must be before to")
+	}
+	opts.Limit = orderCSVBatchSize
+
+	return func(out io.Writer) error {
+		w := csv.NewWriter(out)
+		w.Write([]string{"order_id", "created_at", "user_id", "status", "product_id", "quantity", "unit_price", "line_total", "subtotal", "discount_total", "tax_total", "shipping_total", "grand_total"})
+		for opts.Offset = 0; ; opts.Offset += orderCSVBatchSize {
+			orders, _, err := s.orderRepo.List(opts)
+			if err != nil {
+				return err
+			}
+			for _, order := range orders {
+				writeOrderCSVRows(w, order)
+			}
+			// Flush each batch so the client receives rows as they are produced
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return err
+			}
+			if len(orders) < orderCSVBatchSize {
+				return nil
+			}
+		}
+	}, nil
+}
+
+func writeOrderCSVRows(w *csv.Writer, order models.Order) {
+	prefix := []string{order.ID, order.CreatedAt.Format(time.RFC3339), order.UserID, order.Status}
+	totals := orderTotalsCSV(&order)
+	if len(order.Items) == 0 {
+		w.Write(append(append(prefix, "", "", "", ""), totals...))
+		return
+	}
+	for _, item := range order.Items {
+		w.Write(append(append(append([]string{}, prefix...),
+			item.ProductID,
+			strconv.Itoa(item.Quantity),
+			strconv.FormatFloat(item.Price, 'f', 2, 64),
+			strconv.FormatFloat(item.Price*float64(item.Quantity), 'f', 2, 64),
+		), totals...))
+	}
+}
+
+// orderTotalsCSV formats an order's totals breakdown as CSV fields.
+func orderTotalsCSV(order *models.Order) []string {
+	fields := make([]string, 0, 5)
+	for _, amount := range []float64{order.Subtotal, order.DiscountTotal, order.TaxTotal, order.ShippingTotal, order.GrandTotal} {
+		fields = append(fields, strconv.FormatFloat(amount, 'f', 2, 64))
+	}
+	return fields
+}
+
+func (s *ExportService) exportProducts(job *models.ExportJob) ([]byte, error) {
+	products, err := s.productRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "name", "description", "price", "stock", "status", "type"})
+	for i, p := range products {
+		w.Write([]string{
+			p.ID,
+			p.Name,
+			p.Description,
+			strconv.FormatFloat(p.Price, 'f', 2, 64),
+			strconv.Itoa(p.Stock),
+			p.Status,
+			p.Type,
+		})
+		s.reportProgress(job, i+1, len(products))
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// exportPersonalData builds a ZIP archive with one JSON file per kind of
+// personal data held about the user, for data-portability requests.
+func (s *ExportService) exportPersonalData(job *models.ExportJob) ([]byte, error) {
+	user, err := s.userRepo.GetByID(job.UserID)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = "" // Never export the password hash
+
+	addresses := []models.Address{}
+	if s.addressRepo != nil {
+		if addresses, err = s.addressRepo.ListByUser(job.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	orders := []models.Order{}
+	opts := repositories.OrderListOptions{UserID: job.UserID, Limit: orderCSVBatchSize}
+	for {
+		page, total, err := s.orderRepo.List(opts)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page...)
+		s.reportProgress(job, len(orders), int(total))
+		if len(page) < orderCSVBatchSize {
+			break
+		}
+		opts.Offset += orderCSVBatchSize
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", map[string]interface{}{"exported_at": time.Now(), "user": user}},
+		{"addresses.json", addresses},
+		{"orders.json", orders},
+	} {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.content); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", file.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
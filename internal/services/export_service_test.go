@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/signedurl"
+	"toko/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportService_ProductExportLifecycle(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	productRepo := repositories.NewMockProductRepository()
+	productRepo.Create(&models.Product{Name: "Laptop", Price: 1200, Stock: 3})
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	service := services.NewExportService(
+		repositories.NewMockExportJobRepository(),
+		repositories.NewMockOrderRepository(),
+		productRepo,
+		new(MockUserRepository),
+		store,
+		signedurl.New("test_secret"),
+		queue,
+		time.Hour,
+	)
+
+	// Unknown export types are rejected up front
+	_, err = service.StartExport("user-1", "everything")
+	assert.Error(t, err)
+
+	job, err := service.StartExport("user-1", models.ExportTypeProducts)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusQueued, job.Status)
+
+	// Other users cannot see the job
+	_, err = service.GetJob(job.ID, "user-2")
+	assert.Error(t, err)
+
+	found, err := queue.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	job, err = service.GetJob(job.ID, "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusCompleted, job.Status)
+	assert.Equal(t, 100, job.Progress)
+	assert.NotEmpty(t, job.DownloadURL)
+
+	link, err := url.Parse(job.DownloadURL)
+	assert.NoError(t, err)
+	_, file, err := service.OpenDownload(job.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "Laptop")
+
+	// Tampered signature
+	_, _, err = service.OpenDownload(job.ID, link.Query().Get("expires"), "bogus")
+	assert.Error(t, err)
+}
+
+func TestExportService_PersonalDataArchive(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "alice", Password: "secret-hash"}, nil)
+	orderRepo := repositories.NewMockOrderRepository()
+	orderRepo.Create(&models.Order{UserID: "user-1", Status: "delivered"})
+	orderRepo.Create(&models.Order{UserID: "user-2", Status: "delivered"})
+	addressRepo := repositories.NewMockAddressRepository()
+	addressRepo.Create(&models.Address{UserID: "user-1", PostalAddress: models.PostalAddress{RecipientName: "Alice", Line1: "Jl. Merdeka 1", City: "Jakarta", PostalCode: "10110", Country: "ID"}})
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	service := services.NewExportService(
+		repositories.NewMockExportJobRepository(),
+		orderRepo,
+		repositories.NewMockProductRepository(),
+		userRepo,
+		store,
+		signedurl.New("test_secret"),
+		queue,
+		time.Hour,
+	)
+	service.SetAddresses(addressRepo)
+
+	job, err := service.StartExport("user-1", models.ExportTypeGDPR)
+	assert.NoError(t, err)
+	found, err := queue.RunNext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	job, err = service.GetJob(job.ID, "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusCompleted, job.Status)
+	assert.Contains(t, job.FileName, ".zip")
+
+	link, err := url.Parse(job.DownloadURL)
+	assert.NoError(t, err)
+	_, file, err := service.OpenDownload(job.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+	assert.NoError(t, err)
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	assert.Contains(t, files["profile.json"], "alice")
+	assert.NotContains(t, files["profile.json"], "secret-hash")
+	assert.Contains(t, files["addresses.json"], "Jl. Merdeka 1")
+	assert.Contains(t, files["orders.json"], "user-1")
+	assert.NotContains(t, files["orders.json"], "user-2")
+}
+
+func TestExportService_StreamOrdersCSV(t *testing.T) {
+	orderRepo := repositories.NewMockOrderRepository()
+	for i := 0; i < 501; i++ { // More than one batch
+		orderRepo.Create(&models.Order{
+			UserID:     "user-1",
+			Status:     "delivered",
+			Subtotal:   30,
+			GrandTotal: 30,
+			Items:      []models.OrderItem{{ProductID: "p1", Quantity: 2, Price: 10}, {ProductID: "p2", Quantity: 1, Price: 10}},
+		})
+	}
+	orderRepo.Create(&models.Order{UserID: "user-2", Status: "cancelled", Subtotal: 5, TaxTotal: 0.5, GrandTotal: 5.5})
+
+	service := services.NewExportService(
+		repositories.NewMockExportJobRepository(),
+		orderRepo,
+		repositories.NewMockProductRepository(),
+		new(MockUserRepository),
+		nil,
+		signedurl.New("test_secret"),
+		jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{}),
+		time.Hour,
+	)
+
+	_, err := service.StreamOrdersCSV(repositories.OrderListOptions{Status: "lost"})
+	assert.Error(t, err)
+
+	write, err := service.StreamOrdersCSV(repositories.OrderListOptions{})
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, write(&buf))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1+501*2+1)
+	assert.Equal(t, "line_total", rows[0][7])
+
+	write, err = service.StreamOrdersCSV(repositories.OrderListOptions{Status: "cancelled"})
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, write(&buf))
+	rows, err = csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2) // Orders without items still get a row with their total
+	assert.Equal(t, []string{"user-2", "cancelled", "", "", "", "", "5.00", "0.00", "0.50", "0.00", "5.50"}, rows[1][2:])
+}
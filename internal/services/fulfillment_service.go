@@ -0,0 +1,242 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/pdf"
+)
+
+// PickLine is one product to pick, with where to find it.
+type PickLine struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Location  string `json:"location,omitempty"` // Empty when the product has no bin in the warehouse
+}
+
+// PickList is the products to pick for a set of orders, in walking order.
+type PickList struct {
+	Warehouse   string     `json:"warehouse"`
+	OrderIDs    []string   `json:"order_ids"`
+	Lines       []PickLine `json:"lines"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// FulfillmentService manages where products are kept in each warehouse and
+// prints the packing slips and pick lists warehouse staff work from.
+type FulfillmentService struct {
+	binRepo          repositories.BinLocationRepository
+	orderRepo        repositories.OrderRepository
+	productRepo      repositories.ProductRepository
+	defaultWarehouse string
+	storeName        string
+}
+
+// NewFulfillmentService creates a new FulfillmentService.
+func NewFulfillmentService(binRepo repositories.BinLocationRepository, orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, defaultWarehouse, storeName string) *FulfillmentService {
+	return &FulfillmentService{
+		binRepo:          binRepo,
+		orderRepo:        orderRepo,
+		productRepo:      productRepo,
+		defaultWarehouse: defaultWarehouse,
+		storeName:        storeName,
+	}
+}
+
+// ListLocations returns a product's locations in every warehouse.
+func (s *FulfillmentService) ListLocations(productID string) ([]models.BinLocation, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.binRepo.ListByProduct(productID)
+}
+
+// SetLocation stores where a product is kept in a warehouse.
+func (s *FulfillmentService) SetLocation(productID, warehouse string, location *models.BinLocation) error {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return err
+	}
+	location.ProductID = productID
+	location.Warehouse = strings.ToLower(strings.TrimSpace(warehouse))
+	location.Aisle = strings.TrimSpace(location.Aisle)
+	location.Shelf = strings.TrimSpace(location.Shelf)
+	location.Bin = strings.TrimSpace(location.Bin)
+	if location.Warehouse == "" {
+		return fmt.Errorf("invalid location: warehouse is required")
+	}
+	if location.Code() == "" {
+		return fmt.Errorf("invalid location: at least one of aisle, shelf or bin is required")
+	}
+	return s.binRepo.Save(location)
+}
+
+// DeleteLocation removes a product's location in a warehouse.
+func (s *FulfillmentService) DeleteLocation(productID, warehouse string) error {
+	return s.binRepo.Delete(productID, strings.ToLower(strings.TrimSpace(warehouse)))
+}
+
+// BuildPickList gathers the products of the given orders, summed per product
+// and sorted by location so the picker walks the warehouse once. Products
+// without a location come last.
+func (s *FulfillmentService) BuildPickList(warehouse string, orders []models.Order) (*PickList, error) {
+	warehouse = s.warehouse(warehouse)
+	list := &PickList{Warehouse: warehouse, OrderIDs: []string{}, Lines: []PickLine{}, GeneratedAt: time.Now()}
+
+	quantities := make(map[string]int)
+	var productIDs []string
+	for _, order := range orders {
+		list.OrderIDs = append(list.OrderIDs, order.ID)
+		for _, item := range order.Items {
+			// Shipped, backordered and cancelled items are not picked
+			if !item.AwaitingShipment() {
+				continue
+			}
+			if _, ok := quantities[item.ProductID]; !ok {
+				productIDs = append(productIDs, item.ProductID)
+			}
+			quantities[item.ProductID] += item.Quantity
+		}
+	}
+
+	locations, err := s.locationCodes(warehouse, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, productID := range productIDs {
+		line := PickLine{ProductID: productID, Name: productID, Quantity: quantities[productID], Location: locations[productID]}
+		if product, err := s.productRepo.GetByID(productID); err == nil {
+			line.Name = product.Name
+			line.SKU = product.SKU
+		}
+		list.Lines = append(list.Lines, line)
+	}
+	sort.SliceStable(list.Lines, func(i, j int) bool {
+		a, b := list.Lines[i].Location, list.Lines[j].Location
+		if (a == "") != (b == "") {
+			return a != ""
+		}
+		return a < b
+	})
+	return list, nil
+}
+
+// OpenOrderPickList builds the pick list of orders waiting to be shipped,
+// including the remaining items of partially shipped orders.
+func (s *FulfillmentService) OpenOrderPickList(warehouse string, limit int) (*PickList, error) {
+	orders, _, err := s.orderRepo.List(repositories.OrderListOptions{Status: "processing", Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	if remaining := limit - len(orders); remaining > 0 {
+		partial, _, err := s.orderRepo.List(repositories.OrderListOptions{Status: "partially_shipped", Limit: remaining})
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, partial...)
+	}
+	return s.BuildPickList(warehouse, orders)
+}
+
+// PackingSlip renders the PDF packing slip of an order: what to put in the
+// parcel, where to find it and where it goes. Prices are left out.
+func (s *FulfillmentService) PackingSlip(orderID, warehouse string) ([]byte, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.BuildPickList(warehouse, []models.Order{*order})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := pdf.New("Packing slip " + order.ID)
+	right := pdf.PageWidth - invoiceMargin
+	doc.Text(invoiceMargin, 60, 20, true, s.storeName)
+	doc.TextRight(right, 60, 20, true, "PACKING SLIP")
+	doc.Text(invoiceMargin, 100, 10, false, "Order "+order.ID)
+	doc.Text(invoiceMargin, 116, 10, false, "Order date: "+order.CreatedAt.Format("2 January 2006"))
+	doc.Text(invoiceMargin, 132, 10, false, "Warehouse: "+list.Warehouse)
+
+	doc.Text(invoiceMargin, 164, 10, true, "Ship to")
+	y := 180.0
+	for _, line := range order.ShippingAddress.Lines() {
+		doc.Text(invoiceMargin, y, 10, false, line)
+		y += invoiceLineHeight
+	}
+	if order.IsGift {
+		// Packers put the gift receipt in the parcel instead of the invoice
+		y += 8
+		doc.Text(invoiceMargin, y, 10, true, "Gift order: include the gift receipt, not the invoice")
+		y += invoiceLineHeight
+	}
+	s.renderPickLines(doc, list.Lines, y+24)
+	return doc.Bytes(), nil
+}
+
+// PickListPDF renders a pick list for printing.
+func (s *FulfillmentService) PickListPDF(list *PickList) []byte {
+	doc := pdf.New("Pick list " + list.Warehouse)
+	right := pdf.PageWidth - invoiceMargin
+	doc.Text(invoiceMargin, 60, 20, true, s.storeName)
+	doc.TextRight(right, 60, 20, true, "PICK LIST")
+	doc.Text(invoiceMargin, 100, 10, false, "Warehouse: "+list.Warehouse)
+	doc.Text(invoiceMargin, 116, 10, false, fmt.Sprintf("Orders: %d", len(list.OrderIDs)))
+	doc.Text(invoiceMargin, 132, 10, false, "Generated: "+list.GeneratedAt.Format("2 January 2006 15:04"))
+	s.renderPickLines(doc, list.Lines, 172)
+	return doc.Bytes()
+}
+
+func (s *FulfillmentService) renderPickLines(doc *pdf.Document, lines []PickLine, y float64) {
+	right := pdf.PageWidth - invoiceMargin
+	header := func() {
+		doc.Text(invoiceMargin, y, 10, true, "Location")
+		doc.Text(invoiceMargin+90, y, 10, true, "SKU")
+		doc.Text(invoiceMargin+190, y, 10, true, "Item")
+		doc.TextRight(right, y, 10, true, "Qty")
+		doc.Line(invoiceMargin, y+6, right, y+6)
+		y += invoiceLineHeight + 6
+	}
+	header()
+	for _, line := range lines {
+		if y > invoicePageBottom {
+			doc.AddPage()
+			y = 60
+			header()
+		}
+		location := line.Location
+		if location == "" {
+			location = "-"
+		}
+		doc.Text(invoiceMargin, y, 10, false, location)
+		doc.Text(invoiceMargin+90, y, 10, false, line.SKU)
+		doc.Text(invoiceMargin+190, y, 10, false, line.Name)
+		doc.TextRight(right, y, 10, false, fmt.Sprintf("%d", line.Quantity))
+		y += invoiceLineHeight
+	}
+}
+
+// locationCodes returns the printed location of each product that has one in the warehouse.
+func (s *FulfillmentService) locationCodes(warehouse string, productIDs []string) (map[string]string, error) {
+	locations, err := s.binRepo.ListByWarehouse(warehouse, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	codes := make(map[string]string, len(locations))
+	for _, location := range locations {
+		codes[location.ProductID] = location.Code()
+	}
+	return codes, nil
+}
+
+func (s *FulfillmentService) warehouse(warehouse string) string {
+	if warehouse = strings.ToLower(strings.TrimSpace(warehouse)); warehouse == "" {
+		return s.defaultWarehouse
+	}
+	return warehouse
+}
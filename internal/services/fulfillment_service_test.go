@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"bytes"
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFulfillmentService_PickListFollowsBinLocations(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", SKU: "LMP-1", Price: 40, Stock: 10}
+	mug := &models.Product{Name: "Mug", SKU: "MUG-1", Price: 5, Stock: 10}
+	poster := &models.Product{Name: "Poster", Price: 3, Stock: 10}
+	for _, p := range []*models.Product{lamp, mug, poster} {
+		require.NoError(t, productRepo.Create(p))
+	}
+	orderRepo := repositories.NewMockOrderRepository()
+	first := &models.Order{Status: "processing", ShippingAddress: testPostalAddress("Jakarta"), Items: []models.OrderItem{
+		{ProductID: lamp.ID, Quantity: 1}, {ProductID: poster.ID, Quantity: 2},
+	}}
+	require.NoError(t, orderRepo.Create(first))
+	require.NoError(t, orderRepo.Create(&models.Order{Status: "processing", Items: []models.OrderItem{
+		{ProductID: mug.ID, Quantity: 3}, {ProductID: lamp.ID, Quantity: 2},
+	}}))
+	require.NoError(t, orderRepo.Create(&models.Order{Status: "shipped", Items: []models.OrderItem{{ProductID: mug.ID, Quantity: 9}}}))
+
+	service := services.NewFulfillmentService(repositories.NewMockBinLocationRepository(), orderRepo, productRepo, "main", "Toko")
+
+	err := service.SetLocation(lamp.ID, "Main", &models.BinLocation{})
+	assert.ErrorContains(t, err, "invalid location")
+	assert.ErrorContains(t, service.SetLocation("missing", "main", &models.BinLocation{Bin: "1"}), "not found")
+	require.NoError(t, service.SetLocation(lamp.ID, "Main", &models.BinLocation{Aisle: "B", Shelf: "02", Bin: "05"}))
+	require.NoError(t, service.SetLocation(mug.ID, "main", &models.BinLocation{Aisle: "A", Shelf: "01", Bin: "03"}))
+	require.NoError(t, service.SetLocation(mug.ID, "surabaya", &models.BinLocation{Bin: "9"}))
+
+	list, err := service.OpenOrderPickList("", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "main", list.Warehouse)
+	assert.Len(t, list.OrderIDs, 2) // Shipped orders are not picked again
+	assert.Equal(t, []services.PickLine{
+		{ProductID: mug.ID, SKU: "MUG-1", Name: "Mug", Quantity: 3, Location: "A-01-03"},
+		{ProductID: lamp.ID, SKU: "LMP-1", Name: "Lamp", Quantity: 3, Location: "B-02-05"},
+		{ProductID: poster.ID, Name: "Poster", Quantity: 2}, // Products without a bin come last
+	}, list.Lines)
+	assert.True(t, bytes.HasPrefix(service.PickListPDF(list), []byte("%PDF-")))
+
+	slip, err := service.PackingSlip(first.ID, "")
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(slip, []byte("%PDF-")))
+	assert.Contains(t, string(slip), "B-02-05")
+	assert.Contains(t, string(slip), "Jl. Merdeka 1")
+	_, err = service.PackingSlip("missing", "")
+	assert.Error(t, err)
+}
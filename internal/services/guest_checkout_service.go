@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// GuestCheckout is the result of a guest order: the order and a signed link
+// that is the guest's only way back to it.
+type GuestCheckout struct {
+	Order      *models.Order    `json:"order"`
+	StatusLink *OrderStatusLink `json:"status_link"`
+}
+
+// GuestCheckoutService places orders for shoppers without an account. Each
+// email gets a lightweight guest user that owns its orders, so everything
+// keyed by user (notifications, returns, invoices) works unchanged, and the
+// orders carry over if the guest registers later.
+type GuestCheckoutService struct {
+	userRepo      repositories.UserRepository
+	orderService  *OrderService
+	statusService *OrderStatusPageService
+}
+
+// NewGuestCheckoutService creates a new GuestCheckoutService.
+func NewGuestCheckoutService(userRepo repositories.UserRepository, orderService *OrderService, statusService *OrderStatusPageService) *GuestCheckoutService {
+	return &GuestCheckoutService{
+		userRepo:      userRepo,
+		orderService:  orderService,
+		statusService: statusService,
+	}
+}
+
+// PlaceOrder places the order for the guest with the given email.
+func (s *GuestCheckoutService) PlaceOrder(ctx context.Context, email string, order models.Order) (*GuestCheckout, error) {
+	guest, err := s.guestUser(email)
+	if err != nil {
+		return nil, err
+	}
+
+	order.UserID = guest.ID
+	created, err := s.orderService.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return &GuestCheckout{Order: created, StatusLink: s.statusService.Link(created.ID)}, nil
+}
+
+// guestUser returns the guest user for email, creating it on first checkout.
+// Emails of registered accounts are refused so their orders stay behind a login.
+func (s *GuestCheckoutService) guestUser(email string) (*models.User, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, fmt.Errorf("invalid guest email: %w", err)
+	}
+	email = strings.ToLower(address.Address)
+
+	if user, err := s.userRepo.GetByEmail(email); err == nil && user != nil {
+		if user.Role != models.RoleGuest {
+			return nil, fmt.Errorf("email '%s' already registered: log in to place the order", email)
+		}
+		return user, nil
+	}
+
+	id := uuid.New().String()
+	user := &models.User{
+		ID:       id,
+		Username: "guest-" + id, // Never used to log in; guests have no password
+		Email:    email,
+		Role:     models.RoleGuest,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/signedurl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestCheckoutService_PlaceOrder(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Laptop", Price: 1200, Stock: 3}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, 0)
+	statusService := services.NewOrderStatusPageService(orderRepo, productRepo, signedurl.New("test_secret"), "https://shop.example.com", "/api/v1/order-status", time.Hour)
+
+	userRepo := new(MockUserRepository)
+	service := services.NewGuestCheckoutService(userRepo, orderService, statusService)
+	order := models.Order{
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	}
+
+	_, err := service.PlaceOrder(context.Background(), "not-an-email", order)
+	assert.Error(t, err)
+
+	// The first checkout creates a guest user for the email
+	userRepo.On("GetByEmail", "ani@example.com").Return(nil, errors.New("user with email ani@example.com not found")).Once()
+	userRepo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Role == models.RoleGuest && u.Email == "ani@example.com" && u.Password == ""
+	})).Return(nil).Once()
+	checkout, err := service.PlaceOrder(context.Background(), " Ani@Example.com ", order)
+	require.NoError(t, err)
+	assert.NotEmpty(t, checkout.Order.UserID)
+	assert.Contains(t, checkout.StatusLink.URL, "/api/v1/order-status/"+checkout.Order.ID)
+
+	link, err := url.Parse(checkout.StatusLink.URL)
+	require.NoError(t, err)
+	view, err := statusService.GetStatus(checkout.Order.ID, link.Query().Get("expires"), link.Query().Get("signature"))
+	require.NoError(t, err)
+	assert.Equal(t, "pending", view.Status)
+
+	// Later checkouts reuse the guest user
+	guest := &models.User{ID: checkout.Order.UserID, Email: "ani@example.com", Role: models.RoleGuest}
+	userRepo.On("GetByEmail", "ani@example.com").Return(guest, nil).Once()
+	second, err := service.PlaceOrder(context.Background(), "ani@example.com", order)
+	require.NoError(t, err)
+	assert.Equal(t, guest.ID, second.Order.UserID)
+
+	// Registered emails must log in
+	userRepo.On("GetByEmail", "budi@example.com").Return(&models.User{ID: "user-1", Role: models.RoleCustomer}, nil).Once()
+	_, err = service.PlaceOrder(context.Background(), "budi@example.com", order)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered")
+	userRepo.AssertExpectations(t)
+}
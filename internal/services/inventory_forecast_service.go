@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// InventoryForecastConfig tunes reorder suggestions.
+type InventoryForecastConfig struct {
+	Window      time.Duration // Sales history used to compute velocity
+	LeadTime    time.Duration // Time between placing a purchase order and receiving stock
+	SafetyStock time.Duration // Extra demand covered to absorb spikes and late deliveries
+	Coverage    time.Duration // Demand a reorder should cover
+}
+
+// InventoryForecastService projects how long stock lasts and suggests reorders.
+type InventoryForecastService struct {
+	repo        repositories.InventoryForecastRepository
+	reportRepo  repositories.ReportRepository
+	productRepo repositories.ProductRepository
+	mqClient    Publisher
+	webhooks    *WebhookService
+	cfg         InventoryForecastConfig
+}
+
+// NewInventoryForecastService creates a new InventoryForecastService.
+func NewInventoryForecastService(repo repositories.InventoryForecastRepository, reportRepo repositories.ReportRepository, productRepo repositories.ProductRepository, mqClient Publisher, cfg InventoryForecastConfig) *InventoryForecastService {
+	return &InventoryForecastService{
+		repo:        repo,
+		reportRepo:  reportRepo,
+		productRepo: productRepo,
+		mqClient:    mqClient,
+		cfg:         cfg,
+	}
+}
+
+// SetWebhooks sends product.low_stock webhooks to subscribers along with the low-stock events.
+func (s *InventoryForecastService) SetWebhooks(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// ListForecasts returns the latest forecasts, least stock coverage first.
+func (s *InventoryForecastService) ListForecasts(lowStockOnly bool) ([]models.InventoryForecast, error) {
+	return s.repo.List(lowStockOnly)
+}
+
+// Recompute refreshes the forecast of every active physical product from
+// sales in the window before now. A product.low_stock alert is published when
+// a product falls to its reorder point; products already low are not alerted
+// again until they recover. It returns the number of low-stock products.
+func (s *InventoryForecastService) Recompute(now time.Time) (int, error) {
+	if s.cfg.Window <= 0 {
+		return 0, fmt.Errorf("invalid forecast window %s", s.cfg.Window)
+	}
+	units, err := s.reportRepo.UnitsSold(now.Add(-s.cfg.Window), now)
+	if err != nil {
+		return 0, err
+	}
+	products, err := s.productRepo.GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load products: %w", err)
+	}
+	previous, err := s.repo.List(true)
+	if err != nil {
+		return 0, err
+	}
+	wasLow := make(map[string]bool, len(previous))
+	for _, forecast := range previous {
+		wasLow[forecast.ProductID] = true
+	}
+
+	windowDays := s.cfg.Window.Hours() / 24
+	var forecasts []models.InventoryForecast
+	var alerts []models.InventoryForecast
+	for _, product := range products {
+		if product.IsArchived() || product.IsDigital() {
+			continue
+		}
+		forecast := models.InventoryForecast{
+			ProductID:  product.ID,
+			SKU:        product.SKU,
+			Name:       product.Name,
+			Stock:      product.Stock,
+			UnitsSold:  units[product.ID],
+			ComputedAt: now,
+		}
+		forecast.DailyVelocity = float64(forecast.UnitsSold) / windowDays
+		if forecast.DailyVelocity > 0 {
+			days := float64(product.Stock) / forecast.DailyVelocity
+			forecast.DaysRemaining = &days
+			forecast.ReorderPoint = int(math.Ceil(forecast.DailyVelocity * (s.cfg.LeadTime + s.cfg.SafetyStock).Hours() / 24))
+			forecast.ReorderQuantity = int(math.Ceil(forecast.DailyVelocity * s.cfg.Coverage.Hours() / 24))
+			forecast.LowStock = product.Stock <= forecast.ReorderPoint
+		}
+		if forecast.LowStock && !wasLow[product.ID] {
+			alerts = append(alerts, forecast)
+		}
+		forecasts = append(forecasts, forecast)
+	}
+
+	if err := s.repo.ReplaceAll(forecasts); err != nil {
+		return 0, err
+	}
+	for _, forecast := range alerts {
+		s.alertLowStock(forecast)
+	}
+
+	low := 0
+	for _, forecast := range forecasts {
+		if forecast.LowStock {
+			low++
+		}
+	}
+	return low, nil
+}
+
+func (s *InventoryForecastService) alertLowStock(forecast models.InventoryForecast) {
+	log.Printf("Low stock: product %s (%s) has %d units left, about %.1f days at %.2f units/day; suggest reordering %d",
+		forecast.ProductID, forecast.Name, forecast.Stock, *forecast.DaysRemaining, forecast.DailyVelocity, forecast.ReorderQuantity)
+	publishEvent(context.Background(), s.mqClient, "product", "product.low_stock", map[string]interface{}{
+		"productID":       forecast.ProductID,
+		"sku":             forecast.SKU,
+		"stock":           forecast.Stock,
+		"dailyVelocity":   forecast.DailyVelocity,
+		"daysRemaining":   forecast.DaysRemaining,
+		"reorderPoint":    forecast.ReorderPoint,
+		"reorderQuantity": forecast.ReorderQuantity,
+	})
+	if s.webhooks != nil {
+		s.webhooks.Dispatch(WebhookEventProductLowStock, map[string]interface{}{
+			"product_id":       forecast.ProductID,
+			"sku":              forecast.SKU,
+			"name":             forecast.Name,
+			"stock":            forecast.Stock,
+			"daily_velocity":   forecast.DailyVelocity,
+			"days_remaining":   forecast.DaysRemaining,
+			"reorder_point":    forecast.ReorderPoint,
+			"reorder_quantity": forecast.ReorderQuantity,
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubReportRepository returns fixed units sold.
+type stubReportRepository struct {
+	units map[string]int
+}
+
+func (r *stubReportRepository) TopProducts(from, to time.Time, limit int) ([]models.ProductSales, error) {
+	return nil, nil
+}
+
+func (r *stubReportRepository) Revenue(from, to time.Time) (*models.RevenueSummary, error) {
+	return &models.RevenueSummary{From: from, To: to}, nil
+}
+
+func (r *stubReportRepository) UnitsSold(from, to time.Time) (map[string]int, error) {
+	return r.units, nil
+}
+
+func (r *stubReportRepository) CancellationReasons(from, to time.Time) ([]models.ReasonCount, error) {
+	return nil, nil
+}
+
+func (r *stubReportRepository) ReturnReasons(from, to time.Time) ([]models.ReasonCount, error) {
+	return nil, nil
+}
+
+func (r *stubReportRepository) BoughtTogether(productID string, from, to time.Time, limit int) ([]models.ProductPairing, error) {
+	return nil, nil
+}
+
+func (r *stubReportRepository) OrderMilestones(from, to time.Time) ([]models.OrderMilestones, error) {
+	return nil, nil
+}
+
+func (r *stubReportRepository) OpenOrderMilestones() ([]models.OrderMilestones, error) {
+	return nil, nil
+}
+
+func TestInventoryForecastService_SuggestsReorders(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	fast := &models.Product{Name: "Coffee", Price: 10, Stock: 40}
+	slow := &models.Product{Name: "Grinder", Price: 80, Stock: 40}
+	idle := &models.Product{Name: "Kettle", Price: 30, Stock: 5}
+	ebook := &models.Product{Name: "Brewing guide", Price: 5, Type: models.ProductTypeDigital}
+	for _, product := range []*models.Product{fast, slow, idle, ebook} {
+		require.NoError(t, productRepo.Create(product))
+	}
+
+	reportRepo := &stubReportRepository{units: map[string]int{fast.ID: 150, slow.ID: 30, ebook.ID: 500}}
+	forecastRepo := repositories.NewMockInventoryForecastRepository()
+	service := services.NewInventoryForecastService(forecastRepo, reportRepo, productRepo, nil, services.InventoryForecastConfig{
+		Window:      30 * 24 * time.Hour,
+		LeadTime:    7 * 24 * time.Hour,
+		SafetyStock: 3 * 24 * time.Hour,
+		Coverage:    30 * 24 * time.Hour,
+	})
+
+	low, err := service.Recompute(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, low)
+
+	forecasts, err := service.ListForecasts(false)
+	require.NoError(t, err)
+	require.Len(t, forecasts, 3) // Digital products are not forecast
+
+	// Coffee sells 5 a day: 8 days left, reorder at 50, order 150
+	coffee := forecasts[0]
+	assert.Equal(t, fast.ID, coffee.ProductID)
+	assert.InDelta(t, 5.0, coffee.DailyVelocity, 0.001)
+	require.NotNil(t, coffee.DaysRemaining)
+	assert.InDelta(t, 8.0, *coffee.DaysRemaining, 0.001)
+	assert.Equal(t, 50, coffee.ReorderPoint)
+	assert.Equal(t, 150, coffee.ReorderQuantity)
+	assert.True(t, coffee.LowStock)
+
+	assert.Equal(t, slow.ID, forecasts[1].ProductID)
+	assert.False(t, forecasts[1].LowStock)
+	assert.Equal(t, idle.ID, forecasts[2].ProductID)
+	assert.Nil(t, forecasts[2].DaysRemaining)
+
+	lowOnly, err := service.ListForecasts(true)
+	require.NoError(t, err)
+	assert.Len(t, lowOnly, 1)
+}
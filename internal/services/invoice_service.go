@@ -0,0 +1,232 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/pdf"
+	"toko/pkg/storage"
+)
+
+// paidOrderStatuses are the statuses of orders whose payment has been confirmed.
+var paidOrderStatuses = map[string]struct{}{
+	"processing":        {},
+	"preorder":          {},
+	"partially_shipped": {},
+	"shipped":           {},
+	"delivered":         {},
+}
+
+// InvoiceService renders PDF invoices for paid orders and keeps them in
+// storage so the same document can be downloaded again later.
+type InvoiceService struct {
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	userRepo    repositories.UserRepository
+	store       storage.Storage
+	storeName   string
+}
+
+// NewInvoiceService creates a new InvoiceService.
+func NewInvoiceService(orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, userRepo repositories.UserRepository, store storage.Storage, storeName string) *InvoiceService {
+	return &InvoiceService{
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		store:       store,
+		storeName:   storeName,
+	}
+}
+
+// InvoiceFileName returns the file name customers see for an order's invoice.
+func InvoiceFileName(orderID string) string {
+	return fmt.Sprintf("invoice-%s.pdf", orderID)
+}
+
+// GiftReceiptFileName returns the file name customers see for an order's gift receipt.
+func GiftReceiptFileName(orderID string) string {
+	return fmt.Sprintf("gift-receipt-%s.pdf", orderID)
+}
+
+// Invoice returns the PDF invoice of a paid order. It is rendered and stored
+// the first time and served from storage afterwards, so it never changes.
+func (s *InvoiceService) Invoice(order *models.Order) ([]byte, error) {
+	return s.document(order, "invoices/"+order.ID+".pdf", false)
+}
+
+// GiftReceipt returns the invoice variant that lists the items of a paid
+// order without any prices, to be put in the parcel of a gift. It is stored
+// like the invoice.
+func (s *InvoiceService) GiftReceipt(order *models.Order) ([]byte, error) {
+	return s.document(order, "invoices/"+order.ID+"-gift.pdf", true)
+}
+
+func (s *InvoiceService) document(order *models.Order, key string, gift bool) ([]byte, error) {
+	if _, ok := paidOrderStatuses[order.Status]; !ok {
+		return nil, fmt.Errorf("order %s has not been paid", order.ID)
+	}
+
+	if stored, err := s.store.Open(key); err == nil {
+		defer stored.Close()
+		return io.ReadAll(stored)
+	}
+
+	var document []byte
+	if gift {
+		document = s.renderGiftReceipt(order)
+	} else {
+		user, err := s.userRepo.GetByID(order.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load customer of order %s: %w", order.ID, err)
+		}
+		document = s.render(order, user, time.Now())
+	}
+	if err := s.store.Put(key, bytes.NewReader(document)); err != nil {
+		return nil, fmt.Errorf("failed to store invoice for order %s: %w", order.ID, err)
+	}
+	return document, nil
+}
+
+// Invoice layout, in points.
+const (
+	invoiceMargin     = 50.0
+	invoiceLineHeight = 16.0
+	invoicePageBottom = pdf.PageHeight - 60
+)
+
+func (s *InvoiceService) render(order *models.Order, user *models.User, issuedAt time.Time) []byte {
+	doc := pdf.New("Invoice " + order.ID)
+	right := pdf.PageWidth - invoiceMargin
+
+	doc.Text(invoiceMargin, 60, 20, true, s.storeName)
+	doc.TextRight(right, 60, 20, true, "INVOICE")
+	doc.Text(invoiceMargin, 100, 10, false, "Invoice for order "+order.ID)
+	doc.Text(invoiceMargin, 116, 10, false, "Order date: "+order.CreatedAt.Format("2 January 2006"))
+	doc.Text(invoiceMargin, 132, 10, false, "Issued: "+issuedAt.Format("2 January 2006"))
+	doc.Text(invoiceMargin, 164, 10, true, "Bill to")
+	doc.Text(invoiceMargin, 180, 10, false, user.Username)
+	doc.Text(invoiceMargin, 196, 10, false, user.Email)
+
+	y := 236.0
+	header := func() {
+		doc.Text(invoiceMargin, y, 10, true, "Item")
+		doc.TextRight(right-200, y, 10, true, "Qty")
+		doc.TextRight(right-100, y, 10, true, "Price")
+		doc.TextRight(right, y, 10, true, "Amount")
+		doc.Line(invoiceMargin, y+6, right, y+6)
+		y += invoiceLineHeight + 6
+	}
+	header()
+	for _, item := range order.Items {
+		if y > invoicePageBottom {
+			doc.AddPage()
+			y = 60
+			header()
+		}
+		name := item.ProductID
+		if product, err := s.productRepo.GetByID(item.ProductID); err == nil {
+			name = product.Name
+		}
+		doc.Text(invoiceMargin, y, 10, false, name)
+		doc.TextRight(right-200, y, 10, false, fmt.Sprintf("%d", item.Quantity))
+		doc.TextRight(right-100, y, 10, false, fmt.Sprintf("%.2f", item.Price))
+		doc.TextRight(right, y, 10, false, fmt.Sprintf("%.2f", item.Price*float64(item.Quantity)))
+		y += invoiceLineHeight
+	}
+
+	doc.Line(invoiceMargin, y-6, right, y-6)
+	y += 8
+	totals := []struct {
+		label  string
+		amount float64
+	}{
+		{"Subtotal", order.Subtotal},
+		{"Discount", -order.DiscountTotal},
+		{"Tax", order.TaxTotal},
+		{"Shipping", order.ShippingTotal},
+	}
+	for _, total := range totals {
+		if total.amount == 0 && total.label != "Subtotal" {
+			continue
+		}
+		doc.Text(right-200, y, 10, false, total.label)
+		doc.TextRight(right, y, 10, false, fmt.Sprintf("%.2f", total.amount))
+		y += invoiceLineHeight
+	}
+	doc.Text(right-200, y+4, 11, true, "Total")
+	doc.TextRight(right, y+4, 11, true, fmt.Sprintf("%.2f", order.GrandTotal))
+	doc.Text(invoiceMargin, y+44, 9, false, "Paid. Thank you for shopping at "+s.storeName+".")
+	return doc.Bytes()
+}
+
+// renderGiftReceipt lays out a gift receipt: the items and the gift message,
+// without prices or the buyer's details.
+func (s *InvoiceService) renderGiftReceipt(order *models.Order) []byte {
+	doc := pdf.New("Gift receipt " + order.ID)
+	right := pdf.PageWidth - invoiceMargin
+
+	doc.Text(invoiceMargin, 60, 20, true, s.storeName)
+	doc.TextRight(right, 60, 20, true, "GIFT RECEIPT")
+	doc.Text(invoiceMargin, 100, 10, false, "Order "+order.ID)
+	doc.Text(invoiceMargin, 116, 10, false, "Order date: "+order.CreatedAt.Format("2 January 2006"))
+
+	y := 148.0
+	if order.GiftMessage != "" {
+		doc.Text(invoiceMargin, y, 10, true, "Message")
+		y += invoiceLineHeight
+		for _, line := range wrapText(order.GiftMessage, 90) {
+			doc.Text(invoiceMargin, y, 10, false, line)
+			y += invoiceLineHeight
+		}
+		y += 16
+	}
+
+	header := func() {
+		doc.Text(invoiceMargin, y, 10, true, "Item")
+		doc.TextRight(right, y, 10, true, "Qty")
+		doc.Line(invoiceMargin, y+6, right, y+6)
+		y += invoiceLineHeight + 6
+	}
+	header()
+	for _, item := range order.Items {
+		if y > invoicePageBottom {
+			doc.AddPage()
+			y = 60
+			header()
+		}
+		name := item.ProductID
+		if product, err := s.productRepo.GetByID(item.ProductID); err == nil {
+			name = product.Name
+		}
+		doc.Text(invoiceMargin, y, 10, false, name)
+		doc.TextRight(right, y, 10, false, fmt.Sprintf("%d", item.Quantity))
+		y += invoiceLineHeight
+	}
+	doc.Text(invoiceMargin, y+32, 9, false, "A gift for you from "+s.storeName+". Keep this receipt for exchanges.")
+	return doc.Bytes()
+}
+
+// wrapText breaks text into lines of at most width characters at spaces.
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
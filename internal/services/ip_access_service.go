@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/netutil"
+)
+
+// IPAccessService manages the runtime IP denylist.
+// The parsed denylist is cached in memory and refreshed periodically so that
+// checking a request never needs a round trip to the backing store.
+type IPAccessService struct {
+	repo            repositories.IPDenylistRepository
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	denied      []*net.IPNet
+	lastRefresh time.Time
+}
+
+// NewIPAccessService creates a new IPAccessService.
+func NewIPAccessService(repo repositories.IPDenylistRepository, refreshInterval time.Duration) *IPAccessService {
+	return &IPAccessService{
+		repo:            repo,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// IsDenied reports whether ip matches a denylist entry.
+func (s *IPAccessService) IsDenied(ip net.IP) bool {
+	s.mu.RLock()
+	stale := time.Since(s.lastRefresh) > s.refreshInterval
+	s.mu.RUnlock()
+
+	if stale {
+		if err := s.Refresh(); err != nil {
+			// Keep serving the last known list if the store is unavailable
+			log.Printf("Warning: Failed to refresh IP denylist: %v", err)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return netutil.ContainsIP(s.denied, ip)
+}
+
+// Refresh reloads the denylist cache from the repository.
+func (s *IPAccessService) Refresh() error {
+	entries, err := s.repo.GetAll()
+	if err != nil {
+		s.mu.Lock()
+		s.lastRefresh = time.Now() // Back off until the next interval
+		s.mu.Unlock()
+		return err
+	}
+
+	denied := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		ipNet, err := netutil.ParseCIDR(entry.CIDR)
+		if err != nil {
+			log.Printf("Skipping invalid denylist entry %s: %v", entry.CIDR, err)
+			continue
+		}
+		denied = append(denied, ipNet)
+	}
+
+	s.mu.Lock()
+	s.denied = denied
+	s.lastRefresh = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// ListDenied returns all active denylist entries.
+func (s *IPAccessService) ListDenied() ([]models.DeniedIP, error) {
+	return s.repo.GetAll()
+}
+
+// Deny adds an IP address or CIDR range to the denylist.
+// A zero ttl keeps the entry until it is removed explicitly.
+func (s *IPAccessService) Deny(cidr, reason string, ttl time.Duration) (*models.DeniedIP, error) {
+	ipNet, err := netutil.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.DeniedIP{
+		CIDR:      ipNet.String(),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if ttl > 0 {
+		expiresAt := entry.CreatedAt.Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Add(entry); err != nil {
+		return nil, fmt.Errorf("failed to deny %s: %w", cidr, err)
+	}
+	if err := s.Refresh(); err != nil {
+		log.Printf("Warning: Failed to refresh IP denylist: %v", err)
+	}
+	return entry, nil
+}
+
+// Allow removes an entry from the denylist.
+func (s *IPAccessService) Allow(cidr string) error {
+	ipNet, err := netutil.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Remove(ipNet.String()); err != nil {
+		return err
+	}
+	if err := s.Refresh(); err != nil {
+		log.Printf("Warning: Failed to refresh IP denylist: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPAccessService_DenyAndAllow(t *testing.T) {
+	service := services.NewIPAccessService(repositories.NewMockIPDenylistRepository(), time.Minute)
+
+	assert.False(t, service.IsDenied(net.ParseIP("203.0.113.7")))
+
+	// Deny a whole range
+	entry, err := service.Deny("203.0.113.0/24", "scraping", 0)
+	assert.NoError(t, err)
+	assert.Nil(t, entry.ExpiresAt)
+	assert.True(t, service.IsDenied(net.ParseIP("203.0.113.7")))
+	assert.False(t, service.IsDenied(net.ParseIP("198.51.100.1")))
+
+	// Single addresses are normalized to a host network
+	entry, err = service.Deny("198.51.100.1", "abuse", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "198.51.100.1/32", entry.CIDR)
+	assert.NotNil(t, entry.ExpiresAt)
+	assert.True(t, service.IsDenied(net.ParseIP("198.51.100.1")))
+
+	entries, err := service.ListDenied()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// Removing an entry lifts the block immediately
+	assert.NoError(t, service.Allow("203.0.113.0/24"))
+	assert.False(t, service.IsDenied(net.ParseIP("203.0.113.7")))
+
+	err = service.Allow("203.0.113.0/24")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = service.Deny("not-an-ip", "", 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid")
+}
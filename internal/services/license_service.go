@@ -0,0 +1,182 @@
+package services
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// maxLicenseKeyLength is the longest license key that can be added to a pool.
+const maxLicenseKeyLength = 255
+
+// licenseKeyAlphabet leaves out characters that are easily mistaken for one another.
+const licenseKeyAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// LicenseService hands out license keys for digital products. Keys are
+// assigned when an order is paid, either generated or taken from a pool.
+type LicenseService struct {
+	licenseRepo repositories.LicenseKeyRepository
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+}
+
+// NewLicenseService creates a new LicenseService.
+func NewLicenseService(licenseRepo repositories.LicenseKeyRepository, orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository) *LicenseService {
+	return &LicenseService{
+		licenseRepo: licenseRepo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+	}
+}
+
+// AddKeys adds keys to the pool of a product and returns how many were new.
+func (s *LicenseService) AddKeys(productID string, keys []string) (int, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return 0, err
+	}
+	if !product.IsDigital() || product.LicenseSource != models.LicenseSourcePool {
+		return 0, fmt.Errorf("invalid license keys: product %s does not take its keys from a pool", productID)
+	}
+
+	cleaned := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if len(key) > maxLicenseKeyLength {
+			return 0, fmt.Errorf("invalid license keys: keys are at most %d characters", maxLicenseKeyLength)
+		}
+		cleaned = append(cleaned, key)
+	}
+	if len(cleaned) == 0 {
+		return 0, fmt.Errorf("invalid license keys: at least one key is required")
+	}
+	return s.licenseRepo.AddToPool(productID, cleaned)
+}
+
+// AvailableKeys returns the number of pool keys of a product not yet handed out.
+func (s *LicenseService) AvailableKeys(productID string) (int64, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return 0, err
+	}
+	return s.licenseRepo.CountAvailable(productID)
+}
+
+// Fulfill assigns a license key to every unit of the order's licensed
+// products that does not have one yet, so it is safe to call again after a
+// pool ran dry. It returns an error naming the products still short of keys.
+func (s *LicenseService) Fulfill(orderID string) error {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+	switch order.Status {
+	case "cancelled":
+		return fmt.Errorf("order %s is cancelled", orderID)
+	case "pending", "payment_failed":
+		return fmt.Errorf("order %s is not paid", orderID)
+	}
+
+	assigned, err := s.licenseRepo.ListByOrder(orderID)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]int)
+	for _, key := range assigned {
+		have[key.ProductID]++
+	}
+	// Split lines of the same product share their keys
+	want := make(map[string]int)
+	var productIDs []string
+	for _, item := range order.Items {
+		if item.ItemStatus() == models.ItemStatusCancelled {
+			continue
+		}
+		if _, ok := want[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		want[item.ProductID] += item.Quantity
+	}
+
+	var short []string
+	for _, productID := range productIDs {
+		missing := want[productID] - have[productID]
+		if missing <= 0 {
+			continue
+		}
+		product, err := s.productRepo.GetByID(productID)
+		if err != nil {
+			return err
+		}
+		if !product.HasLicenseKeys() {
+			continue
+		}
+
+		got := 0
+		switch product.LicenseSource {
+		case models.LicenseSourceGenerated:
+			for ; got < missing; got++ {
+				value, err := generateLicenseKey()
+				if err != nil {
+					return err
+				}
+				assignedAt := time.Now()
+				key := &models.LicenseKey{ProductID: productID, Key: value, OrderID: orderID, AssignedAt: &assignedAt}
+				if err := s.licenseRepo.Create(key); err != nil {
+					return err
+				}
+			}
+		case models.LicenseSourcePool:
+			claimed, err := s.licenseRepo.Claim(productID, orderID, missing)
+			if err != nil {
+				return err
+			}
+			got = len(claimed)
+		}
+		if got < missing {
+			short = append(short, fmt.Sprintf("%s (%d missing)", product.Name, missing-got))
+		}
+	}
+	if len(short) > 0 {
+		return fmt.Errorf("not enough license keys for order %s: %s", orderID, strings.Join(short, ", "))
+	}
+	return nil
+}
+
+// ListForOrder returns the license keys of an order to the customer who placed it.
+func (s *LicenseService) ListForOrder(orderID, userID string) ([]models.LicenseKey, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		// Hide other users' orders entirely
+		return nil, fmt.Errorf("order with ID %s not found", orderID)
+	}
+	if order.Status == "cancelled" {
+		return nil, fmt.Errorf("order %s is cancelled", orderID)
+	}
+	return s.licenseRepo.ListByOrder(orderID)
+}
+
+// generateLicenseKey returns a random key such as "7KQ2M-9XDAB-…" of five groups of five characters.
+func generateLicenseKey() (string, error) {
+	raw := make([]byte, 25)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate license key: %w", err)
+	}
+	var b strings.Builder
+	for i, c := range raw {
+		if i > 0 && i%5 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(licenseKeyAlphabet[int(c)%len(licenseKeyAlphabet)])
+	}
+	return b.String(), nil
+}
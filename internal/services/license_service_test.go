@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenseService_KeysAssignedWhenOrderIsPaid(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	ebook := &models.Product{Name: "Ebook", Price: 10, Type: models.ProductTypeDigital, LicenseSource: models.LicenseSourceGenerated}
+	require.NoError(t, productRepo.Create(ebook))
+	software := &models.Product{Name: "Software", Price: 99, Type: models.ProductTypeDigital, LicenseSource: models.LicenseSourcePool}
+	require.NoError(t, productRepo.Create(software))
+
+	orderRepo := repositories.NewMockOrderRepository()
+	licenseRepo := repositories.NewMockLicenseKeyRepository()
+	licenses := services.NewLicenseService(licenseRepo, orderRepo, productRepo)
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetLicenses(licenses)
+
+	_, err := licenses.AddKeys(ebook.ID, []string{"KEY-1"})
+	assert.ErrorContains(t, err, "invalid license keys")
+	added, err := licenses.AddKeys(software.ID, []string{" KEY-1 ", "KEY-1", ""})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: ebook.ID, Quantity: 2}, {ProductID: software.ID, Quantity: 2}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	assert.ErrorContains(t, licenses.Fulfill(order.ID), "not paid")
+
+	// The pool only has one key, so the order is left one short
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	keys, err := licenses.ListForOrder(order.ID, "user-1")
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+
+	_, err = licenses.ListForOrder(order.ID, "user-2")
+	assert.ErrorContains(t, err, "not found")
+
+	// Topping up the pool and retrying completes the order without duplicates
+	_, err = licenses.AddKeys(software.ID, []string{"KEY-2", "KEY-3"})
+	require.NoError(t, err)
+	require.NoError(t, licenses.Fulfill(order.ID))
+	require.NoError(t, licenses.Fulfill(order.ID))
+	keys, err = licenses.ListForOrder(order.ID, "user-1")
+	require.NoError(t, err)
+	require.Len(t, keys, 4)
+	for _, key := range keys {
+		if key.ProductID == ebook.ID {
+			assert.Regexp(t, `^[A-Z2-9]{5}(-[A-Z2-9]{5}){4}$`, key.Key)
+		}
+	}
+	available, err := licenses.AvailableKeys(software.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), available)
+}
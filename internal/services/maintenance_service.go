@@ -0,0 +1,56 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// ReadOnlyStatus describes whether the API currently accepts mutations.
+type ReadOnlyStatus struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// MaintenanceService holds runtime operational switches such as read-only mode.
+// The state is per instance; the READ_ONLY_MODE setting applies it on startup.
+type MaintenanceService struct {
+	mu       sync.RWMutex
+	readOnly ReadOnlyStatus
+}
+
+// NewMaintenanceService creates a new MaintenanceService.
+func NewMaintenanceService(readOnly bool, reason string) *MaintenanceService {
+	s := &MaintenanceService{}
+	if readOnly {
+		s.SetReadOnly(true, reason)
+	}
+	return s
+}
+
+// ReadOnly returns the current read-only status.
+func (s *MaintenanceService) ReadOnly() ReadOnlyStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.readOnly
+}
+
+// SetReadOnly turns read-only mode on or off.
+func (s *MaintenanceService) SetReadOnly(enabled bool, reason string) ReadOnlyStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !enabled {
+		s.readOnly = ReadOnlyStatus{}
+		log.Println("Read-only mode disabled")
+		return s.readOnly
+	}
+	now := time.Now()
+	if s.readOnly.Enabled && s.readOnly.Since != nil {
+		now = *s.readOnly.Since // Keep the original start time when only the reason changes
+	}
+	s.readOnly = ReadOnlyStatus{Enabled: true, Reason: reason, Since: &now}
+	log.Printf("Read-only mode enabled: %s", reason)
+	return s.readOnly
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/mailer"
+)
+
+// Task queue types used to send customer emails.
+const (
+	orderConfirmationTaskType = "email.order_confirmation"
+	paymentReceivedTaskType   = "email.payment_received"
+	returnLabelTaskType       = "email.return_label"
+	unpaidCancelTaskType      = "email.unpaid_cancellation"
+	passwordResetTaskType     = "email.password_reset"
+	emailChangeTaskType       = "email.email_change"
+	emailChangeNoticeTaskType = "email.email_change_notice"
+)
+
+//go:embed templates/*.tmpl
+var emailTemplates embed.FS
+
+var (
+	orderConfirmationText = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/order_confirmation.txt.tmpl"))
+	orderConfirmationHTML = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/order_confirmation.html.tmpl"))
+	paymentReceivedText   = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/payment_received.txt.tmpl"))
+	paymentReceivedHTML   = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/payment_received.html.tmpl"))
+	returnLabelText       = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/return_label.txt.tmpl"))
+	returnLabelHTML       = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/return_label.html.tmpl"))
+	unpaidCancelText      = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/unpaid_cancellation.txt.tmpl"))
+	unpaidCancelHTML      = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/unpaid_cancellation.html.tmpl"))
+	passwordResetText     = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/password_reset.txt.tmpl"))
+	passwordResetHTML     = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/password_reset.html.tmpl"))
+	emailChangeText       = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/email_change.txt.tmpl"))
+	emailChangeHTML       = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/email_change.html.tmpl"))
+	emailChangeNoticeText = texttemplate.Must(texttemplate.ParseFS(emailTemplates, "templates/email_change_notice.txt.tmpl"))
+	emailChangeNoticeHTML = htmltemplate.Must(htmltemplate.ParseFS(emailTemplates, "templates/email_change_notice.html.tmpl"))
+)
+
+// orderEmailTask is the payload of an order email task.
+type orderEmailTask struct {
+	OrderID string `json:"order_id"`
+}
+
+// returnLabelTask is the payload of a return label email task. It carries
+// everything the email shows, so it does not depend on later changes.
+type returnLabelTask struct {
+	UserID      string    `json:"user_id"`
+	OrderID     string    `json:"order_id"`
+	Carrier     string    `json:"carrier"`
+	DropOffCode string    `json:"drop_off_code"`
+	LabelURL    string    `json:"label_url,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// passwordResetTask is the payload of a password reset email task.
+type passwordResetTask struct {
+	UserID    string    `json:"user_id"`
+	ResetURL  string    `json:"reset_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// emailChangeTask is the payload of the emails sent when a user asks to
+// change their email: the confirmation link to the new address and the
+// notice to the old one. Both addresses are kept so the notice reaches the
+// old address even if the change is confirmed first.
+type emailChangeTask struct {
+	UserID     string    `json:"user_id"`
+	OldEmail   string    `json:"old_email"`
+	NewEmail   string    `json:"new_email"`
+	ConfirmURL string    `json:"confirm_url,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// orderEmailItem is a line item as shown in order emails.
+type orderEmailItem struct {
+	Name     string
+	Quantity int
+	Price    float64
+	Subtotal float64
+}
+
+// orderEmailTotals is the totals breakdown as shown in order emails.
+type orderEmailTotals struct {
+	Subtotal float64
+	Discount float64
+	Tax      float64
+	Shipping float64
+	Total    float64
+}
+
+// orderEmailData is the data available to order email templates.
+type orderEmailData struct {
+	StoreName string
+	Username  string
+	OrderID   string
+	Items     []orderEmailItem
+	Totals    orderEmailTotals
+	StatusURL string
+}
+
+// NotificationService sends transactional emails to customers. Emails are
+// sent from the task queue so that SMTP failures are retried with backoff.
+type NotificationService struct {
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	userRepo    repositories.UserRepository
+	statusPages *OrderStatusPageService // Optional; adds a status page link to emails
+	invoices    *InvoiceService
+	mailer      mailer.Mailer
+	queue       *jobs.Queue
+	storeName   string
+}
+
+// NewNotificationService creates a new NotificationService and registers its task handlers on queue.
+func NewNotificationService(orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, userRepo repositories.UserRepository, statusPages *OrderStatusPageService, invoices *InvoiceService, m mailer.Mailer, queue *jobs.Queue, storeName string) *NotificationService {
+	s := &NotificationService{
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		statusPages: statusPages,
+		invoices:    invoices,
+		mailer:      m,
+		queue:       queue,
+		storeName:   storeName,
+	}
+	jobs.Handle(queue, orderConfirmationTaskType, s.sendOrderConfirmation)
+	jobs.Handle(queue, paymentReceivedTaskType, s.sendPaymentReceived)
+	jobs.Handle(queue, returnLabelTaskType, s.sendReturnLabel)
+	jobs.Handle(queue, unpaidCancelTaskType, s.sendUnpaidCancellation)
+	jobs.Handle(queue, passwordResetTaskType, s.sendPasswordReset)
+	jobs.Handle(queue, emailChangeTaskType, s.sendEmailChange)
+	jobs.Handle(queue, emailChangeNoticeTaskType, s.sendEmailChangeNotice)
+	return s
+}
+
+// HandleOrderCreated consumes order.created events and queues the confirmation email.
+func (s *NotificationService) HandleOrderCreated(body []byte) error {
+	return s.queueOrderEmail("order.created", orderConfirmationTaskType, body)
+}
+
+// HandleReceiptRequested consumes order.receipt_requested events and queues
+// the confirmation email again.
+func (s *NotificationService) HandleReceiptRequested(body []byte) error {
+	return s.queueOrderEmail("order.receipt_requested", orderConfirmationTaskType, body)
+}
+
+// HandlePaymentConfirmed consumes order.payment_confirmed events and queues
+// the payment receipt email with the invoice attached.
+func (s *NotificationService) HandlePaymentConfirmed(body []byte) error {
+	return s.queueOrderEmail("order.payment_confirmed", paymentReceivedTaskType, body)
+}
+
+// HandleOrderCancelled consumes order.cancelled events and tells customers
+// when their order was cancelled for not being paid in time. Cancellations
+// made by the customer or staff send no email.
+func (s *NotificationService) HandleOrderCancelled(body []byte) error {
+	var event struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("failed to decode order.cancelled event: %w", err)
+	}
+	if event.Reason != OrderCancelReasonUnpaid {
+		return nil
+	}
+	return s.queueOrderEmail("order.cancelled", unpaidCancelTaskType, body)
+}
+
+// QueueReturnLabel schedules the email that gives the customer the drop-off
+// code of an approved return.
+func (s *NotificationService) QueueReturnLabel(request *models.ReturnRequest) error {
+	task := returnLabelTask{
+		UserID:      request.UserID,
+		OrderID:     request.OrderID,
+		Carrier:     request.Carrier,
+		DropOffCode: request.DropOffCode,
+		LabelURL:    request.LabelURL,
+	}
+	if request.LabelExpiresAt != nil {
+		task.ExpiresAt = *request.LabelExpiresAt
+	}
+	if _, err := s.queue.Enqueue(returnLabelTaskType, task); err != nil {
+		return fmt.Errorf("failed to queue return label email for return %s: %w", request.ID, err)
+	}
+	return nil
+}
+
+// QueuePasswordReset schedules the email with the link a user follows to
+// choose a new password.
+func (s *NotificationService) QueuePasswordReset(userID, resetURL string, expiresAt time.Time) error {
+	if _, err := s.queue.Enqueue(passwordResetTaskType, passwordResetTask{UserID: userID, ResetURL: resetURL, ExpiresAt: expiresAt}); err != nil {
+		return fmt.Errorf("failed to queue password reset email for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// QueueEmailChange schedules the email with the link that confirms the new
+// address, and the notice telling the old address about the change.
+func (s *NotificationService) QueueEmailChange(userID, oldEmail, newEmail, confirmURL string, expiresAt time.Time) error {
+	task := emailChangeTask{UserID: userID, OldEmail: oldEmail, NewEmail: newEmail, ConfirmURL: confirmURL, ExpiresAt: expiresAt}
+	if _, err := s.queue.Enqueue(emailChangeTaskType, task); err != nil {
+		return fmt.Errorf("failed to queue email change confirmation for user %s: %w", userID, err)
+	}
+	task.ConfirmURL = "" // The old address must not be able to confirm
+	if _, err := s.queue.Enqueue(emailChangeNoticeTaskType, task); err != nil {
+		return fmt.Errorf("failed to queue email change notice for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+func (s *NotificationService) queueOrderEmail(routingKey, taskType string, body []byte) error {
+	var event struct {
+		OrderID string `json:"orderID"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", routingKey, err)
+	}
+	if _, err := s.queue.Enqueue(taskType, orderEmailTask{OrderID: event.OrderID}); err != nil {
+		return fmt.Errorf("failed to queue %s email for order %s: %w", taskType, event.OrderID, err)
+	}
+	return nil
+}
+
+func (s *NotificationService) sendOrderConfirmation(ctx context.Context, task *models.Task, payload orderEmailTask) error {
+	order, err := s.orderRepo.GetByID(payload.OrderID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(order.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load customer of order %s: %w", order.ID, err)
+	}
+
+	data := s.orderEmailData(order, user)
+	msg, err := renderEmail(fmt.Sprintf("%s order confirmation %s", s.storeName, order.ID), orderConfirmationText, orderConfirmationHTML, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+	return s.mailer.Send(ctx, *msg)
+}
+
+func (s *NotificationService) sendPaymentReceived(ctx context.Context, task *models.Task, payload orderEmailTask) error {
+	order, err := s.orderRepo.GetByID(payload.OrderID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(order.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load customer of order %s: %w", order.ID, err)
+	}
+	invoice, err := s.invoices.Invoice(order)
+	if err != nil {
+		return err
+	}
+
+	data := s.orderEmailData(order, user)
+	msg, err := renderEmail(fmt.Sprintf("%s payment received for order %s", s.storeName, order.ID), paymentReceivedText, paymentReceivedHTML, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+	msg.Attachments = []mailer.Attachment{{
+		Filename:    InvoiceFileName(order.ID),
+		ContentType: "application/pdf",
+		Data:        invoice,
+	}}
+	if order.IsGift {
+		receipt, err := s.invoices.GiftReceipt(order)
+		if err != nil {
+			return err
+		}
+		msg.Attachments = append(msg.Attachments, mailer.Attachment{
+			Filename:    GiftReceiptFileName(order.ID),
+			ContentType: "application/pdf",
+			Data:        receipt,
+		})
+	}
+	return s.mailer.Send(ctx, *msg)
+}
+
+func (s *NotificationService) sendUnpaidCancellation(ctx context.Context, task *models.Task, payload orderEmailTask) error {
+	order, err := s.orderRepo.GetByID(payload.OrderID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(order.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load customer of order %s: %w", order.ID, err)
+	}
+
+	data := s.orderEmailData(order, user)
+	msg, err := renderEmail(fmt.Sprintf("%s order %s cancelled", s.storeName, order.ID), unpaidCancelText, unpaidCancelHTML, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+	return s.mailer.Send(ctx, *msg)
+}
+
+func (s *NotificationService) sendReturnLabel(ctx context.Context, task *models.Task, payload returnLabelTask) error {
+	user, err := s.userRepo.GetByID(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load customer %s: %w", payload.UserID, err)
+	}
+
+	data := struct {
+		returnLabelTask
+		StoreName string
+		Username  string
+	}{payload, s.storeName, user.Username}
+	msg, err := renderEmail(fmt.Sprintf("%s return approved for order %s", s.storeName, payload.OrderID), returnLabelText, returnLabelHTML, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+	return s.mailer.Send(ctx, *msg)
+}
+
+func (s *NotificationService) sendPasswordReset(ctx context.Context, task *models.Task, payload passwordResetTask) error {
+	user, err := s.userRepo.GetByID(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load user %s: %w", payload.UserID, err)
+	}
+
+	data := struct {
+		passwordResetTask
+		StoreName string
+		Username  string
+	}{payload, s.storeName, user.Username}
+	msg, err := renderEmail(fmt.Sprintf("Reset your %s password", s.storeName), passwordResetText, passwordResetHTML, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+	return s.mailer.Send(ctx, *msg)
+}
+
+func (s *NotificationService) sendEmailChange(ctx context.Context, task *models.Task, payload emailChangeTask) error {
+	return s.sendEmailChangeTo(ctx, payload, payload.NewEmail, fmt.Sprintf("Confirm your new %s email", s.storeName), emailChangeText, emailChangeHTML)
+}
+
+func (s *NotificationService) sendEmailChangeNotice(ctx context.Context, task *models.Task, payload emailChangeTask) error {
+	return s.sendEmailChangeTo(ctx, payload, payload.OldEmail, fmt.Sprintf("Your %s email is being changed", s.storeName), emailChangeNoticeText, emailChangeNoticeHTML)
+}
+
+func (s *NotificationService) sendEmailChangeTo(ctx context.Context, payload emailChangeTask, to, subject string, text *texttemplate.Template, html *htmltemplate.Template) error {
+	user, err := s.userRepo.GetByID(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load user %s: %w", payload.UserID, err)
+	}
+
+	data := struct {
+		emailChangeTask
+		StoreName string
+		Username  string
+	}{payload, s.storeName, user.Username}
+	msg, err := renderEmail(subject, text, html, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{to}
+	return s.mailer.Send(ctx, *msg)
+}
+
+func (s *NotificationService) orderEmailData(order *models.Order, user *models.User) orderEmailData {
+	data := orderEmailData{
+		StoreName: s.storeName,
+		Username:  user.Username,
+		OrderID:   order.ID,
+		Totals: orderEmailTotals{
+			Subtotal: order.Subtotal,
+			Discount: order.DiscountTotal,
+			Tax:      order.TaxTotal,
+			Shipping: order.ShippingTotal,
+			Total:    order.GrandTotal,
+		},
+	}
+	for _, item := range order.Items {
+		name := item.ProductID
+		if product, err := s.productRepo.GetByID(item.ProductID); err == nil {
+			name = product.Name
+		}
+		data.Items = append(data.Items, orderEmailItem{
+			Name:     name,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Subtotal: item.Price * float64(item.Quantity),
+		})
+	}
+	if s.statusPages != nil {
+		data.StatusURL = s.statusPages.Link(order.ID).URL
+	}
+	return data
+}
+
+// renderEmail renders the plain-text and HTML bodies of an email.
+func renderEmail(subject string, text *texttemplate.Template, html *htmltemplate.Template, data interface{}) (*mailer.Message, error) {
+	var textBody, htmlBody bytes.Buffer
+	if err := text.Execute(&textBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", text.Name(), err)
+	}
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", html.Name(), err)
+	}
+	return &mailer.Message{Subject: subject, Text: textBody.String(), HTML: htmlBody.String()}, nil
+}
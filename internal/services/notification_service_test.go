@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/mailer"
+	"toko/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyMailer fails the given number of sends, then records messages.
+type flakyMailer struct {
+	failures int
+	sent     []mailer.Message
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if m.failures > 0 {
+		m.failures--
+		return fmt.Errorf("smtp: 421 service not available")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestNotificationService_OrderConfirmationRetriesSMTPFailures(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Laptop", Price: 1200, Stock: 3}
+	require.NoError(t, productRepo.Create(product))
+
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "pending", GrandTotal: 2400, Items: []models.OrderItem{{ProductID: product.ID, Quantity: 2, Price: 1200}}}
+	require.NoError(t, orderRepo.Create(order))
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "budi", Email: "budi@example.com"}, nil)
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 3})
+	m := &flakyMailer{failures: 1}
+	service := services.NewNotificationService(orderRepo, productRepo, userRepo, nil, nil, m, queue, "Toko")
+
+	require.NoError(t, service.HandleOrderCreated([]byte(fmt.Sprintf(`{"orderID":%q,"userID":"user-1","status":"pending","total":2400}`, order.ID))))
+
+	// The first attempt hits an SMTP error and is retried by the queue
+	for i := 0; i < 2; i++ {
+		found, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, found)
+	}
+
+	require.Len(t, m.sent, 1)
+	msg := m.sent[0]
+	assert.Equal(t, []string{"budi@example.com"}, msg.To)
+	assert.Contains(t, msg.Subject, order.ID)
+	assert.Contains(t, msg.Text, "2 x Laptop @ 1200.00 = 2400.00")
+	assert.Contains(t, msg.HTML, "<td>Laptop</td>")
+	userRepo.AssertExpectations(t)
+}
+
+func TestNotificationService_ReceiptRequestedResendsConfirmation(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "delivered", GrandTotal: 50}
+	require.NoError(t, orderRepo.Create(order))
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "budi", Email: "budi@example.com"}, nil)
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 3})
+	m := &flakyMailer{}
+	service := services.NewNotificationService(orderRepo, productRepo, userRepo, nil, nil, m, queue, "Toko")
+
+	require.NoError(t, service.HandleReceiptRequested([]byte(fmt.Sprintf(`{"orderID":%q,"userID":"user-1","requestedBy":"admin-1"}`, order.ID))))
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, []string{"budi@example.com"}, m.sent[0].To)
+	assert.Contains(t, m.sent[0].Subject, "order confirmation")
+}
+
+func TestNotificationService_PaymentReceivedAttachesStoredInvoice(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	productRepo := repositories.NewMockProductRepository()
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "processing", GrandTotal: 50, Items: []models.OrderItem{{ProductID: "deleted-product", Quantity: 1, Price: 50}}}
+	require.NoError(t, orderRepo.Create(order))
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "budi", Email: "budi@example.com"}, nil)
+
+	invoices := services.NewInvoiceService(orderRepo, productRepo, userRepo, store, "Toko")
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	m := &flakyMailer{}
+	service := services.NewNotificationService(orderRepo, productRepo, userRepo, nil, invoices, m, queue, "Toko")
+
+	require.NoError(t, service.HandlePaymentConfirmed([]byte(fmt.Sprintf(`{"orderID":%q}`, order.ID))))
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.Len(t, m.sent, 1)
+	require.Len(t, m.sent[0].Attachments, 1)
+	attachment := m.sent[0].Attachments[0]
+	assert.Equal(t, "invoice-"+order.ID+".pdf", attachment.Filename)
+	assert.True(t, bytes.HasPrefix(attachment.Data, []byte("%PDF-")))
+
+	// The stored invoice is served again instead of being re-rendered
+	again, err := invoices.Invoice(order)
+	require.NoError(t, err)
+	assert.Equal(t, attachment.Data, again)
+
+	// Unpaid orders have no invoice
+	_, err = invoices.Invoice(&models.Order{ID: "unpaid", Status: "pending"})
+	assert.Error(t, err)
+}
+
+func TestNotificationService_PaymentReceivedAttachesGiftReceipt(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	productRepo := repositories.NewMockProductRepository()
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "processing", GrandTotal: 123.45, IsGift: true, GiftMessage: "Happy birthday!", Items: []models.OrderItem{{ProductID: "p-1", Quantity: 1, Price: 123.45}}}
+	require.NoError(t, orderRepo.Create(order))
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "budi", Email: "budi@example.com"}, nil)
+
+	invoices := services.NewInvoiceService(orderRepo, productRepo, userRepo, store, "Toko")
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	m := &flakyMailer{}
+	service := services.NewNotificationService(orderRepo, productRepo, userRepo, nil, invoices, m, queue, "Toko")
+
+	require.NoError(t, service.HandlePaymentConfirmed([]byte(fmt.Sprintf(`{"orderID":%q}`, order.ID))))
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.Len(t, m.sent, 1)
+	require.Len(t, m.sent[0].Attachments, 2)
+	receipt := m.sent[0].Attachments[1]
+	assert.Equal(t, "gift-receipt-"+order.ID+".pdf", receipt.Filename)
+	assert.True(t, bytes.Contains(receipt.Data, []byte("Happy birthday!")))
+	assert.False(t, bytes.Contains(receipt.Data, []byte("123.45")), "gift receipts must not show prices")
+	assert.NotEqual(t, m.sent[0].Attachments[0].Data, receipt.Data)
+}
+
+func TestNotificationService_UnpaidCancellationEmail(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "cancelled", Items: []models.OrderItem{{ProductID: "p-1", Quantity: 2, Price: 10}}}
+	require.NoError(t, orderRepo.Create(order))
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "budi", Email: "budi@example.com"}, nil)
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	m := &flakyMailer{}
+	service := services.NewNotificationService(orderRepo, productRepo, userRepo, nil, nil, m, queue, "Toko")
+
+	// Cancellations by the customer send nothing
+	require.NoError(t, service.HandleOrderCancelled([]byte(fmt.Sprintf(`{"orderID":%q,"userID":"user-1","previousStatus":"pending","cancelledBy":"user-1"}`, order.ID))))
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, service.HandleOrderCancelled([]byte(fmt.Sprintf(`{"orderID":%q,"userID":"user-1","previousStatus":"pending","cancelledBy":"system","reason":"unpaid"}`, order.ID))))
+	found, err = queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, []string{"budi@example.com"}, m.sent[0].To)
+	assert.Contains(t, m.sent[0].Subject, "cancelled")
+	assert.Contains(t, m.sent[0].Text, "did not receive payment in time")
+	assert.Contains(t, m.sent[0].Text, "2 x p-1")
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/storage"
+)
+
+// orderArchiveBatchSize is the number of orders written to one archive.
+const orderArchiveBatchSize = 500
+
+// archivableStatuses are the final statuses; orders in any other status are never archived.
+var archivableStatuses = []string{"delivered", "cancelled", "payment_failed"}
+
+// ArchivedOrderRecord is an order as kept in an archive: the order with its
+// items, and the return requests made for it.
+type ArchivedOrderRecord struct {
+	Order   models.Order           `json:"order"`
+	Returns []models.ReturnRequest `json:"returns"`
+}
+
+// OrderArchiveService moves old, finished orders out of the database into
+// gzipped JSON lines archives in storage, keeping the order tables small.
+// Archived orders leave reports and order listings; admins can still look
+// them up by ID.
+type OrderArchiveService struct {
+	orderRepo  repositories.OrderRepository
+	returnRepo repositories.ReturnRepository
+	storage    storage.Storage
+	after      time.Duration
+}
+
+// NewOrderArchiveService creates a new OrderArchiveService archiving orders
+// placed more than after ago; zero disables archival.
+func NewOrderArchiveService(orderRepo repositories.OrderRepository, returnRepo repositories.ReturnRepository, store storage.Storage, after time.Duration) *OrderArchiveService {
+	return &OrderArchiveService{
+		orderRepo:  orderRepo,
+		returnRepo: returnRepo,
+		storage:    store,
+		after:      after,
+	}
+}
+
+// ArchiveOldOrders archives every finished order placed before the cutoff,
+// one archive per batch, and returns how many orders were archived. Each
+// archive is stored before its orders are deleted.
+func (s *OrderArchiveService) ArchiveOldOrders(now time.Time) (int, error) {
+	if s.after <= 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-s.after)
+	archived := 0
+	for {
+		orders, err := s.orderRepo.ListStale(archivableStatuses, cutoff, orderArchiveBatchSize)
+		if err != nil {
+			return archived, err
+		}
+		if len(orders) == 0 {
+			return archived, nil
+		}
+		if err := s.archiveBatch(orders, now); err != nil {
+			return archived, err
+		}
+		archived += len(orders)
+		if len(orders) < orderArchiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+func (s *OrderArchiveService) archiveBatch(orders []models.Order, now time.Time) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	for _, order := range orders {
+		returns, err := s.returnRepo.ListByOrder(order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load return requests of order %s: %w", order.ID, err)
+		}
+		if err := enc.Encode(ArchivedOrderRecord{Order: order, Returns: returns}); err != nil {
+			return fmt.Errorf("failed to encode order %s: %w", order.ID, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress order archive: %w", err)
+	}
+
+	key := fmt.Sprintf("archives/orders/%s-%s.jsonl.gz", now.UTC().Format("20060102T150405"), orders[0].ID)
+	if err := s.storage.Put(key, &buf); err != nil {
+		return fmt.Errorf("failed to store order archive: %w", err)
+	}
+
+	records := make([]models.ArchivedOrder, len(orders))
+	for i, order := range orders {
+		records[i] = models.ArchivedOrder{
+			OrderID:        order.ID,
+			UserID:         order.UserID,
+			Status:         order.Status,
+			GrandTotal:     order.GrandTotal,
+			ArchiveKey:     key,
+			OrderCreatedAt: order.CreatedAt,
+			ArchivedAt:     now,
+		}
+	}
+	if err := s.orderRepo.Archive(records); err != nil {
+		s.storage.Delete(key)
+		return err
+	}
+	return nil
+}
+
+// GetArchivedOrder reads an archived order back from its archive.
+func (s *OrderArchiveService) GetArchivedOrder(id string) (*ArchivedOrderRecord, error) {
+	archived, err := s.orderRepo.GetArchived(id)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := s.storage.Open(archived.ArchiveKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open order archive %s: %w", archived.ArchiveKey, err)
+	}
+	defer rc.Close()
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read order archive %s: %w", archived.ArchiveKey, err)
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record ArchivedOrderRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode order archive %s: %w", archived.ArchiveKey, err)
+		}
+		if record.Order.ID == id {
+			return &record, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read order archive %s: %w", archived.ArchiveKey, err)
+	}
+	return nil, fmt.Errorf("archived order with ID %s not found in %s", id, archived.ArchiveKey)
+}
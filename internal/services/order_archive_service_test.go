@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderArchiveService_ArchiveOldOrders(t *testing.T) {
+	orderRepo := repositories.NewMockOrderRepository()
+	returnRepo := repositories.NewMockReturnRepository()
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	service := services.NewOrderArchiveService(orderRepo, returnRepo, store, 3*365*24*time.Hour)
+
+	delivered := &models.Order{UserID: "user-1", Status: "delivered", GrandTotal: 42, Items: []models.OrderItem{{ProductID: "p1", Quantity: 2, Price: 21}}}
+	shipped := &models.Order{UserID: "user-1", Status: "shipped"}
+	for _, order := range []*models.Order{delivered, shipped} {
+		require.NoError(t, orderRepo.Create(order))
+	}
+	require.NoError(t, returnRepo.Create(&models.ReturnRequest{OrderID: delivered.ID, UserID: "user-1", Reason: "damaged"}))
+
+	// Nothing is old enough yet
+	archived, err := service.ArchiveOldOrders(time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, archived)
+
+	archived, err = service.ArchiveOldOrders(time.Now().Add(4 * 365 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived, "unfinished orders are never archived")
+
+	_, err = orderRepo.GetByID(delivered.ID)
+	assert.ErrorContains(t, err, "not found")
+	_, err = orderRepo.GetByID(shipped.ID)
+	assert.NoError(t, err)
+
+	record, err := service.GetArchivedOrder(delivered.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 42.0, record.Order.GrandTotal)
+	require.Len(t, record.Order.Items, 1)
+	assert.Equal(t, 2, record.Order.Items[0].Quantity)
+	require.Len(t, record.Returns, 1)
+	assert.Equal(t, "damaged", record.Returns[0].Reason)
+
+	_, err = service.GetArchivedOrder(shipped.ID)
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"toko/internal/models"
+)
+
+// maxQuoteItems is the most lines a quote may price.
+const maxQuoteItems = 100
+
+// QuoteRequest asks what a set of items would cost if ordered now. The
+// destination needs no more than a country; the shipping rates of some
+// zones and couriers also depend on the city or postal code.
+type QuoteRequest struct {
+	UserID              string // Empty for anonymous shoppers
+	Items               []models.OrderItem
+	Destination         models.PostalAddress
+	CouponCode          string
+	ShippingMethod      string
+	PresentmentCurrency string
+	// Detected from where the shopper is; used when PresentmentCurrency is
+	// empty and customers can pay in it
+	DetectedCurrency string
+}
+
+// Quote is the price of a set of items, worked out the way checkout would.
+// Nothing is reserved, redeemed or saved, so stock and coupon limits are
+// checked again when the order is placed.
+type Quote struct {
+	Items               []models.OrderItem `json:"items"`
+	Subtotal            float64            `json:"subtotal"`
+	DiscountTotal       float64            `json:"discount_total"`
+	TaxTotal            float64            `json:"tax_total"`
+	ShippingTotal       float64            `json:"shipping_total"`
+	GrandTotal          float64            `json:"grand_total"`
+	CouponCode          string             `json:"coupon_code,omitempty"`
+	ShippingMethod      string             `json:"shipping_method,omitempty"`
+	Currency            string             `json:"currency,omitempty"`
+	PresentmentCurrency string             `json:"presentment_currency,omitempty"`
+	ExchangeRate        float64            `json:"exchange_rate,omitempty"`
+	PresentmentTotal    float64            `json:"presentment_total,omitempty"`
+}
+
+// Quote prices the items for delivery to the destination without creating an order.
+func (s *OrderService) Quote(req QuoteRequest) (*Quote, error) {
+	if len(req.Items) == 0 || len(req.Items) > maxQuoteItems {
+		return nil, fmt.Errorf("invalid quote: between 1 and %d items are required", maxQuoteItems)
+	}
+	for _, item := range req.Items {
+		if item.Quantity < 1 {
+			return nil, fmt.Errorf("invalid quantity: product %s must be ordered at least once", item.ProductID)
+		}
+	}
+	destination := req.Destination
+	destination.Country = strings.ToUpper(strings.TrimSpace(destination.Country))
+	if len(destination.Country) != 2 {
+		return nil, fmt.Errorf("invalid destination: country must be a two-letter ISO 3166 code")
+	}
+
+	requested := req.PresentmentCurrency
+	if requested == "" && req.DetectedCurrency != "" && s.currencies != nil {
+		if _, err := s.currencies.Rate(req.DetectedCurrency); err == nil {
+			requested = req.DetectedCurrency
+		}
+	}
+	currency, presentment, exchangeRate, err := s.presentmentCurrency(requested)
+	if err != nil {
+		return nil, err
+	}
+	price, err := s.priceOrder(req.UserID, req.Items, req.CouponCode, destination, req.ShippingMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	// Totals are computed on an unsaved order so they round exactly as checkout does
+	order := &models.Order{
+		Subtotal:      roundCents(price.subtotal),
+		DiscountTotal: price.discount,
+		TaxTotal:      price.tax,
+		ExchangeRate:  exchangeRate,
+	}
+	if price.shipping != nil {
+		order.ShippingTotal = price.shipping.Amount
+	}
+	order.UpdateGrandTotal()
+
+	quote := &Quote{
+		Items:               price.items,
+		Subtotal:            order.Subtotal,
+		DiscountTotal:       order.DiscountTotal,
+		TaxTotal:            order.TaxTotal,
+		ShippingTotal:       order.ShippingTotal,
+		GrandTotal:          order.GrandTotal,
+		Currency:            currency,
+		PresentmentCurrency: presentment,
+		ExchangeRate:        exchangeRate,
+	}
+	if price.coupon != nil {
+		quote.CouponCode = price.coupon.Code
+	}
+	if price.shipping != nil {
+		quote.ShippingMethod = price.shipping.Provider + "/" + price.shipping.Service
+	}
+	if exchangeRate > 0 {
+		quote.PresentmentTotal = order.ToPresentment(order.GrandTotal)
+	}
+	return quote, nil
+}
@@ -1,138 +1,1318 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 	"toko/internal/models"
 	"toko/internal/repositories"
-	"toko/pkg/rabbitmq"
+	"toko/pkg/courier"
 
 	"github.com/google/uuid"
 )
 
 // OrderService handles business logic related to orders.
 type OrderService struct {
-	orderRepo   repositories.OrderRepository
-	productRepo repositories.ProductRepository
-	mqClient    *rabbitmq.Client // RabbitMQ client
+	orderRepo       repositories.OrderRepository
+	productRepo     repositories.ProductRepository
+	reservationRepo repositories.StockReservationRepository
+	mqClient        Publisher     // RabbitMQ client
+	reservationTTL  time.Duration // How long stock is held for an unpaid order
+	coupons         *CouponService
+	taxes           *TaxService
+	shipping        *ShippingService
+	slots           *DeliverySlotService
+	cancelReasons   ReasonList // Reasons customers and admins can give for a cancellation
+	webhooks        *WebhookService
+	checkoutFields  *CheckoutFields // Store-configured phone, company and tax ID rules
+	licenses        *LicenseService
+	currencies      *CurrencyConverter // Set when customers may pay in other currencies
 }
 
 // NewOrderService creates a new OrderService.
-func NewOrderService(orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, mqClient *rabbitmq.Client) *OrderService {
+func NewOrderService(orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, reservationRepo repositories.StockReservationRepository, mqClient Publisher, reservationTTL time.Duration) *OrderService {
 	return &OrderService{
-		orderRepo:   orderRepo,
-		productRepo: productRepo,
-		mqClient:    mqClient,
+		orderRepo:       orderRepo,
+		productRepo:     productRepo,
+		reservationRepo: reservationRepo,
+		mqClient:        mqClient,
+		reservationTTL:  reservationTTL,
 	}
 }
 
+// SetCoupons enables promo codes at checkout.
+func (s *OrderService) SetCoupons(coupons *CouponService) {
+	s.coupons = coupons
+}
+
+// SetCurrencies lets customers choose the currency of their order. Its
+// exchange rate is frozen on the order when it is placed.
+func (s *OrderService) SetCurrencies(currencies *CurrencyConverter) {
+	s.currencies = currencies
+}
+
+// SetTaxes charges tax on orders at the rates of the products' tax classes.
+func (s *OrderService) SetTaxes(taxes *TaxService) {
+	s.taxes = taxes
+}
+
+// SetShipping charges shipping on orders with physical items.
+func (s *OrderService) SetShipping(shipping *ShippingService) {
+	s.shipping = shipping
+}
+
+// SetDeliverySlots lets customers book a delivery window at checkout.
+func (s *OrderService) SetDeliverySlots(slots *DeliverySlotService) {
+	s.slots = slots
+}
+
+// SetCancellationReasons requires cancellations to give one of the reasons.
+func (s *OrderService) SetCancellationReasons(reasons ReasonList) {
+	s.cancelReasons = reasons
+}
+
+// CancellationReasons returns the reasons a cancellation can give.
+func (s *OrderService) CancellationReasons() ReasonList {
+	return s.cancelReasons
+}
+
+// SetCheckoutFields checks the configurable checkout fields of new orders.
+func (s *OrderService) SetCheckoutFields(fields *CheckoutFields) {
+	s.checkoutFields = fields
+}
+
+// SetLicenses hands out license keys for digital products when orders are paid.
+func (s *OrderService) SetLicenses(licenses *LicenseService) {
+	s.licenses = licenses
+}
+
+// SetWebhooks sends order.created, order.updated and order.cancelled webhooks to subscribers.
+func (s *OrderService) SetWebhooks(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// dispatchOrderWebhook sends the order, as it is now, to the event's webhook subscribers.
+func (s *OrderService) dispatchOrderWebhook(event, orderID string) {
+	if s.webhooks == nil {
+		return
+	}
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		log.Printf("Warning: Failed to load order %s for %s webhook: %v", orderID, event, err)
+		return
+	}
+	s.webhooks.Dispatch(event, order)
+}
+
 // GetAllOrders retrieves all orders.
 func (s *OrderService) GetAllOrders() ([]models.Order, error) {
 	return s.orderRepo.GetAll()
 }
 
+// ListOrders retrieves a filtered page of orders and the total number of matches.
+func (s *OrderService) ListOrders(opts repositories.OrderListOptions) ([]models.Order, int64, error) {
+	if opts.Status != "" {
+		if _, ok := validOrderStatuses[opts.Status]; !ok {
+			return nil, 0, fmt.Errorf("invalid order status: %s", opts.Status)
+		}
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() && !opts.From.Before(opts.To) {
+		return nil, 0, fmt.Errorf("invalid date range: from must be before to")
+	}
+	return s.orderRepo.List(opts)
+}
+
 // GetOrderByID retrieves a single order by its ID.
 func (s *OrderService) GetOrderByID(id string) (*models.Order, error) {
 	return s.orderRepo.GetByID(id)
 }
 
+// Limits on what integrations may attach to an order.
+const (
+	maxExternalRefLength  = 100
+	maxMetadataKeys       = 50
+	maxMetadataKeyLength  = 40
+	maxMetadataEncodedLen = 8 << 10 // Bytes of JSON
+)
+
+// GetOrderByExternalRef retrieves the newest order an integration stamped with ref.
+func (s *OrderService) GetOrderByExternalRef(ref string) (*models.Order, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("invalid external reference: it must not be empty")
+	}
+	return s.orderRepo.GetByExternalRef(ref)
+}
+
+// OrderReferencesUpdate changes the integration fields of an order. A nil
+// ExternalRef keeps the current one; metadata keys set to null are removed
+// and the others are added or replaced.
+type OrderReferencesUpdate struct {
+	ExternalRef *string              `json:"external_ref"`
+	Metadata    models.OrderMetadata `json:"metadata"`
+}
+
+// UpdateOrderReferences sets the external reference of an order and merges
+// metadata into what it already has.
+func (s *OrderService) UpdateOrderReferences(id string, update OrderReferencesUpdate) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	externalRef := order.ExternalRef
+	if update.ExternalRef != nil {
+		externalRef = strings.TrimSpace(*update.ExternalRef)
+	}
+	metadata := models.OrderMetadata{}
+	for key, value := range order.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range update.Metadata {
+		if value == nil {
+			delete(metadata, key)
+			continue
+		}
+		metadata[key] = value
+	}
+
+	if err := validateOrderReferences(externalRef, metadata); err != nil {
+		return nil, err
+	}
+	if err := s.checkExternalRef(order.ID, externalRef); err != nil {
+		return nil, err
+	}
+	if err := s.orderRepo.UpdateReferences(order.ID, externalRef, metadata); err != nil {
+		return nil, err
+	}
+	order.ExternalRef = externalRef
+	order.Metadata = metadata
+	if len(metadata) == 0 {
+		order.Metadata = nil
+	}
+	return order, nil
+}
+
+// validateOrderReferences checks an external reference and metadata against
+// the limits on integration data.
+func validateOrderReferences(externalRef string, metadata models.OrderMetadata) error {
+	if len(externalRef) > maxExternalRefLength {
+		return fmt.Errorf("invalid external reference: at most %d characters are allowed", maxExternalRefLength)
+	}
+	if len(metadata) > maxMetadataKeys {
+		return fmt.Errorf("invalid metadata: at most %d keys are allowed", maxMetadataKeys)
+	}
+	for key := range metadata {
+		if strings.TrimSpace(key) == "" || len(key) > maxMetadataKeyLength {
+			return fmt.Errorf("invalid metadata: keys must be 1 to %d characters", maxMetadataKeyLength)
+		}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("invalid metadata: %w", err)
+	}
+	if len(encoded) > maxMetadataEncodedLen {
+		return fmt.Errorf("invalid metadata: at most %d bytes are allowed", maxMetadataEncodedLen)
+	}
+	return nil
+}
+
+// checkExternalRef returns an error when an order other than orderID already uses ref.
+func (s *OrderService) checkExternalRef(orderID, ref string) error {
+	if ref == "" {
+		return nil
+	}
+	existing, err := s.orderRepo.GetByExternalRef(ref)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != orderID {
+		return fmt.Errorf("external reference %s is already used by order %s", ref, existing.ID)
+	}
+	return nil
+}
+
+// maxGiftMessageLength is the longest gift message printed on a gift receipt.
+const maxGiftMessageLength = 500
+
 // CreateOrder creates a new order.
-func (s *OrderService) CreateOrder(orderRequest models.Order) (*models.Order, error) {
-	// 1. Validate products and calculate total amount
-	var totalAmount float64
-	var processedItems []models.OrderItem
+func (s *OrderService) CreateOrder(ctx context.Context, orderRequest models.Order) (*models.Order, error) {
+	if s.checkoutFields != nil {
+		if err := s.checkoutFields.Apply(&orderRequest); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every order ships somewhere; billing defaults to the shipping address
+	shipping, billing := orderRequest.ShippingAddress, orderRequest.BillingAddress
+	shipping.Country = strings.ToUpper(shipping.Country)
+	if err := shipping.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid shipping address: %w", err)
+	}
+	if billing.IsZero() {
+		billing = shipping
+	}
+	billing.Country = strings.ToUpper(billing.Country)
+	if err := billing.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid billing address: %w", err)
+	}
+
+	giftMessage := strings.TrimSpace(orderRequest.GiftMessage)
+	if len([]rune(giftMessage)) > maxGiftMessageLength {
+		return nil, fmt.Errorf("invalid gift message: at most %d characters are allowed", maxGiftMessageLength)
+	}
+
+	externalRef := strings.TrimSpace(orderRequest.ExternalRef)
+	if err := validateOrderReferences(externalRef, orderRequest.Metadata); err != nil {
+		return nil, err
+	}
+	if err := s.checkExternalRef("", externalRef); err != nil {
+		return nil, err
+	}
+
+	currency, presentment, exchangeRate, err := s.presentmentCurrency(orderRequest.PresentmentCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Validate products and work out the totals
+	price, err := s.priceOrder(orderRequest.UserID, orderRequest.Items, orderRequest.CouponCode, shipping, orderRequest.ShippingMethod)
+	if err != nil {
+		return nil, err
+	}
+	processedItems, physicalItems, coupon, discount := price.items, price.physical, price.coupon, price.discount
+	slotID := strings.TrimSpace(orderRequest.DeliverySlotID)
+	if slotID != "" {
+		if s.slots == nil {
+			return nil, fmt.Errorf("invalid delivery slot: delivery windows are not offered")
+		}
+		if len(physicalItems) == 0 {
+			return nil, fmt.Errorf("invalid delivery slot: the order has nothing to deliver")
+		}
+	}
+
+	// Create the order object
+	newOrder := &models.Order{
+		ID:        uuid.New().String(),
+		UserID:    orderRequest.UserID, // Assuming UserID is provided or derived from auth context
+		Items:     processedItems,
+		Status:    "pending", // Initial status
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+
+		ShippingAddress: shipping,
+		BillingAddress:  billing,
+
+		Subtotal:      roundCents(price.subtotal),
+		DiscountTotal: discount,
+		TaxTotal:      price.tax,
+
+		IsGift:      orderRequest.IsGift || giftMessage != "",
+		GiftMessage: giftMessage,
+
+		CompanyName: orderRequest.CompanyName,
+		TaxID:       orderRequest.TaxID,
+
+		ReleaseAt: price.releaseAt,
 
-	// Start a transaction if using a real DB. For mock, we simulate atomicity.
-	for _, item := range orderRequest.Items {
+		ExternalRef: externalRef,
+		Metadata:    orderRequest.Metadata,
+
+		Currency:            currency,
+		PresentmentCurrency: presentment,
+		ExchangeRate:        exchangeRate,
+	}
+	if coupon != nil {
+		newOrder.CouponCode = coupon.Code
+	}
+	if price.shipping != nil {
+		newOrder.ShippingTotal = price.shipping.Amount
+		newOrder.ShippingMethod = price.shipping.Provider + "/" + price.shipping.Service
+	}
+	newOrder.UpdateGrandTotal()
+	if exchangeRate > 0 {
+		newOrder.PresentmentTotal = newOrder.ToPresentment(newOrder.GrandTotal)
+	}
+
+	// 2. Reserve stock for physical items while the customer pays. The
+	// reservation is atomic, so concurrent orders cannot oversell.
+	if len(physicalItems) > 0 {
+		if _, err := s.reservationRepo.Reserve(newOrder.ID, physicalItems, newOrder.CreatedAt.Add(s.reservationTTL)); err != nil {
+			return nil, fmt.Errorf("failed to reserve stock: %w", err)
+		}
+	}
+
+	// 3. Count the coupon use; the limits are checked again atomically here
+	if coupon != nil {
+		if err := s.coupons.Redeem(coupon, newOrder.ID, newOrder.UserID, discount); err != nil {
+			s.releaseFailedOrder(newOrder, false)
+			return nil, err
+		}
+	}
+
+	// Book the delivery window; its capacity is checked atomically too
+	if slotID != "" {
+		slot, err := s.slots.Book(slotID)
+		if err != nil {
+			s.releaseFailedOrder(newOrder, coupon != nil)
+			return nil, err
+		}
+		newOrder.DeliverySlotID = slot.ID
+		newOrder.DeliveryStart = &slot.StartsAt
+		newOrder.DeliveryEnd = &slot.EndsAt
+	}
+
+	// 4. Save the order to the repository
+	if err := s.orderRepo.Create(newOrder); err != nil {
+		s.releaseFailedOrder(newOrder, coupon != nil)
+		return nil, fmt.Errorf("failed to create order in repository: %w", err)
+	}
+
+	// Record the sale so the archival job knows these products are still selling
+	for _, item := range processedItems {
+		if err := s.productRepo.UpdateLastSoldAt(item.ProductID, newOrder.CreatedAt); err != nil {
+			log.Printf("Warning: Failed to record last sale for product %s: %v", item.ProductID, err)
+		}
+	}
+
+	// 5. Publish an event to RabbitMQ for order creation
+	publishEvent(ctx, s.mqClient, "order", "order.created", map[string]interface{}{
+		"orderID": newOrder.ID,
+		"userID":  newOrder.UserID,
+		"status":  newOrder.Status,
+		"total":   newOrder.GrandTotal,
+	})
+	s.dispatchOrderWebhook(WebhookEventOrderCreated, newOrder.ID)
+
+	return newOrder, nil
+}
+
+// presentmentCurrency returns the store currency, the currency the customer
+// asked to pay in and its exchange rate. Without multi-currency only the
+// store currency is accepted, and nothing is returned.
+func (s *OrderService) presentmentCurrency(requested string) (string, string, float64, error) {
+	requested = strings.ToUpper(strings.TrimSpace(requested))
+	if s.currencies == nil {
+		if requested != "" {
+			return "", "", 0, fmt.Errorf("invalid currency: orders can only be paid in the store currency")
+		}
+		return "", "", 0, nil
+	}
+	currency, presentment := s.currencies.Base(), requested
+	if presentment == "" {
+		presentment = currency
+	}
+	rate, err := s.currencies.Rate(presentment)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return currency, presentment, rate, nil
+}
+
+// orderPrice is what an order's items cost and how they ship, worked out
+// before anything is reserved or redeemed.
+type orderPrice struct {
+	items     []models.OrderItem // At their current prices
+	physical  []models.OrderItem
+	releaseAt *time.Time // Latest release date of the preorder items
+	subtotal  float64
+	coupon    *models.Coupon
+	discount  float64
+	tax       float64
+	shipping  *courier.Rate // Nil when nothing ships
+}
+
+// priceOrder checks that the items can be ordered by the user and works out
+// their subtotal, the coupon discount, tax and the shipping rate to the
+// destination.
+func (s *OrderService) priceOrder(userID string, items []models.OrderItem, couponCode string, destination models.PostalAddress, shippingMethod string) (*orderPrice, error) {
+	price := &orderPrice{}
+	limited := make(map[string]*models.Product)
+
+	for _, item := range items {
 		product, err := s.productRepo.GetByID(item.ProductID)
 		if err != nil {
 			return nil, fmt.Errorf("product %s not found: %w", item.ProductID, err)
 		}
 
-		// In a real scenario, you'd check stock here.
-		// For mock, we assume stock is sufficient or handled elsewhere.
-		if product.Stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s (requested: %d, available: %d)", product.Name, item.Quantity, product.Stock)
+		// Digital products are delivered as downloads and never run out of stock
+		if !product.IsDigital() {
+			if product.Stock < item.Quantity {
+				return nil, fmt.Errorf("insufficient stock for product %s (requested: %d, available: %d)", product.Name, item.Quantity, product.Stock)
+			}
+			price.physical = append(price.physical, item)
+		}
+		if product.HasPurchaseLimit() {
+			limited[product.ID] = product
+		}
+		if product.AwaitingRelease(time.Now()) && (price.releaseAt == nil || product.ReleaseDate.After(*price.releaseAt)) {
+			price.releaseAt = product.ReleaseDate
 		}
 
 		itemPrice := product.Price // Use price at the time of order creation
-		processedItems = append(processedItems, models.OrderItem{
+		price.items = append(price.items, models.OrderItem{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
 			Price:     itemPrice,
+			Status:    models.ItemStatusPending,
 		})
-		totalAmount += itemPrice * float64(item.Quantity)
+		price.subtotal += itemPrice * float64(item.Quantity)
 	}
 
-	// Create the order object
-	newOrder := &models.Order{
-		ID:          uuid.New().String(),
-		UserID:      orderRequest.UserID, // Assuming UserID is provided or derived from auth context
-		Items:       processedItems,
-		TotalAmount: totalAmount,
-		Status:      "pending", // Initial status
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	if err := s.checkPurchaseLimits(userID, price.items, limited); err != nil {
+		return nil, err
 	}
 
-	// 2. Save the order to the repository
-	err := s.orderRepo.Create(newOrder)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create order in repository: %w", err)
+	// Check the promo code; it is only counted as used once the stock is held
+	if code := strings.TrimSpace(couponCode); code != "" {
+		if s.coupons == nil {
+			return nil, fmt.Errorf("invalid coupon: promo codes are not accepted")
+		}
+		var err error
+		if price.coupon, price.discount, err = s.coupons.Evaluate(code, userID, price.subtotal, time.Now()); err != nil {
+			return nil, err
+		}
 	}
 
-	// 3. Publish an event to RabbitMQ for order creation
-	// This could be an "order.created" event.
-	// The message should contain enough info for consumers to process it.
-	orderCreatedMessage := map[string]interface{}{
-		"orderID": newOrder.ID,
-		"userID":  newOrder.UserID,
-		"status":  newOrder.Status,
-		"total":   newOrder.TotalAmount,
-		// Include items if needed by consumers
+	var err error
+	if price.tax, err = s.calculateTax(price.items, price.subtotal, price.discount); err != nil {
+		return nil, err
+	}
+	if price.shipping, err = s.chooseShippingRate(price.physical, destination, shippingMethod); err != nil {
+		return nil, err
 	}
+	return price, nil
+}
 
-	// Use the RabbitMQ client to publish the message
-	if s.mqClient != nil {
-		messageBody, err := json.Marshal(orderCreatedMessage)
+// checkPurchaseLimits enforces the per-order and per-customer limits of the
+// limited products among the items. The per-customer check counts earlier
+// orders, so two orders placed at the same moment can both pass it.
+func (s *OrderService) checkPurchaseLimits(userID string, items []models.OrderItem, limited map[string]*models.Product) error {
+	if len(limited) == 0 {
+		return nil
+	}
+	requested := make(map[string]int)
+	for _, item := range items {
+		requested[item.ProductID] += item.Quantity
+	}
+	for productID, product := range limited {
+		quantity := requested[productID]
+		if product.MaxPerOrder > 0 && quantity > product.MaxPerOrder {
+			return fmt.Errorf("invalid quantity: %s is limited to %d per order", product.Name, product.MaxPerOrder)
+		}
+		if product.MaxPerCustomer <= 0 {
+			continue
+		}
+		var since time.Time
+		if product.LimitWindowHours > 0 {
+			since = time.Now().Add(-time.Duration(product.LimitWindowHours) * time.Hour)
+		}
+		bought, err := s.orderRepo.PurchasedUnits(userID, productID, since)
 		if err != nil {
-			log.Printf("Failed to marshal order to JSON: %v", err)
-		} else {
-			err = s.mqClient.Publish("order", "order.created", messageBody)
-			if err != nil {
-				log.Printf("Warning: Failed to publish order created event for order %s: %v", newOrder.ID, err)
-			} else {
-				log.Printf("Successfully published order created event for order %s", newOrder.ID)
+			return err
+		}
+		if bought+quantity > product.MaxPerCustomer {
+			period := "in total"
+			if product.LimitWindowHours > 0 {
+				period = fmt.Sprintf("every %d hours", product.LimitWindowHours)
 			}
+			return fmt.Errorf("invalid quantity: %s is limited to %d per customer %s and you can order %d more", product.Name, product.MaxPerCustomer, period, max(product.MaxPerCustomer-bought, 0))
 		}
+	}
+	return nil
+}
 
-	} else {
-		log.Println("RabbitMQ client is not initialized. Skipping message publication.")
+// calculateTax returns the tax on the items. A coupon discount lowers the
+// taxable amount of every item in proportion to its value.
+func (s *OrderService) calculateTax(items []models.OrderItem, subtotal, discount float64) (float64, error) {
+	if s.taxes == nil || subtotal <= 0 {
+		return 0, nil
+	}
+	breakdown, err := s.taxes.Calculate(items)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate tax: %w", err)
 	}
+	return roundCents(breakdown.Total * (subtotal - discount) / subtotal), nil
+}
 
-	return newOrder, nil
+// chooseShippingRate quotes shipping for the physical items and returns the
+// rate of the requested method, "provider/service", or the cheapest rate.
+// Orders without physical items are not charged shipping.
+func (s *OrderService) chooseShippingRate(items []models.OrderItem, destination models.PostalAddress, method string) (*courier.Rate, error) {
+	if s.shipping == nil || len(items) == 0 {
+		return nil, nil
+	}
+	quote, err := s.shipping.Quote(context.Background(), ShippingQuoteRequest{Items: items, Destination: destination})
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote shipping: %w", err)
+	}
+	if !quote.RequiresShipping {
+		return nil, nil
+	}
+	method = strings.TrimSpace(method)
+	if method == "" {
+		return &quote.Rates[0], nil
+	}
+	for i, rate := range quote.Rates {
+		if strings.EqualFold(rate.Provider+"/"+rate.Service, method) {
+			return &quote.Rates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("invalid shipping method: %s is not available for this order", method)
+}
+
+// releaseFailedOrder gives back the stock, coupon use and delivery slot taken for an order that could not be placed.
+func (s *OrderService) releaseFailedOrder(order *models.Order, redeemed bool) {
+	if _, err := s.reservationRepo.Release(order.ID, models.ReservationStatusReleased); err != nil {
+		log.Printf("Warning: Failed to release stock for failed order %s: %v", order.ID, err)
+	}
+	if redeemed {
+		if err := s.coupons.Release(order.ID); err != nil {
+			log.Printf("Warning: Failed to release coupon for failed order %s: %v", order.ID, err)
+		}
+	}
+	s.releaseDeliverySlot(order)
+}
+
+// releaseCancelledOrder gives back the coupon use and delivery slot of a cancelled order.
+func (s *OrderService) releaseCancelledOrder(order *models.Order) {
+	if s.coupons != nil && order.CouponCode != "" {
+		// Cancelled orders do not count against the coupon's limits
+		if err := s.coupons.Release(order.ID); err != nil {
+			log.Printf("Warning: Failed to release coupon for cancelled order %s: %v", order.ID, err)
+		}
+	}
+	s.releaseDeliverySlot(order)
+}
+
+// releaseDeliverySlot frees the delivery window booked for an order that will not be delivered.
+func (s *OrderService) releaseDeliverySlot(order *models.Order) {
+	if s.slots == nil || order.DeliverySlotID == "" {
+		return
+	}
+	if err := s.slots.Release(order.DeliverySlotID); err != nil {
+		log.Printf("Warning: Failed to release delivery slot for order %s: %v", order.ID, err)
+	}
+}
+
+// validOrderStatuses are the statuses an order can be in.
+var validOrderStatuses = map[string]bool{"pending": true, "payment_failed": true, "processing": true, "preorder": true, "partially_shipped": true, "shipped": true, "delivered": true, "cancelled": true}
+
+// orderStatusTransitions lists, per order status, the statuses UpdateOrderStatus
+// may move an order to. Cancelled orders stay cancelled, and an order whose
+// payment failed only moves on when a retried payment goes through.
+var orderStatusTransitions = map[string]map[string]bool{
+	"pending":           {"processing": true},
+	"payment_failed":    {"processing": true},
+	"processing":        {"shipped": true, "delivered": true}, // Digital-only orders are delivered without shipping
+	"partially_shipped": {"shipped": true},
+	"shipped":           {"delivered": true},
+}
+
+// itemCascades lists, per order status, the item statuses moved along with
+// the order when its status is set as a whole.
+var itemCascades = map[string][]string{
+	"processing": {models.ItemStatusPending},
+	"shipped":    {models.ItemStatusPending, models.ItemStatusProcessing, models.ItemStatusBackordered},
+	"delivered":  {models.ItemStatusPending, models.ItemStatusProcessing, models.ItemStatusBackordered, models.ItemStatusShipped},
+	"cancelled":  {models.ItemStatusPending, models.ItemStatusProcessing, models.ItemStatusBackordered},
 }
 
-// UpdateOrderStatus updates the status of an existing order.
-func (s *OrderService) UpdateOrderStatus(id string, status string) error {
+// UpdateOrderStatus updates the status of an existing order and moves its
+// items along with it. Orders only move forward along orderStatusTransitions,
+// and setting the status they already have does nothing. Paid orders with
+// preorder items that are not released yet move to preorder instead of
+// processing. Orders are cancelled with CancelOrder instead.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id string, status string) error {
 	// Add validation for status if necessary
-	validStatuses := map[string]bool{"pending": true, "processing": true, "shipped": true, "delivered": true, "cancelled": true}
-	if _, ok := validStatuses[status]; !ok {
+	if _, ok := validOrderStatuses[status]; !ok {
 		return fmt.Errorf("invalid order status: %s", status)
 	}
+	if status == "partially_shipped" {
+		return fmt.Errorf("invalid order status: partially_shipped follows from the status of the items")
+	}
+	if status == "preorder" {
+		return fmt.Errorf("invalid order status: preorder follows from the release dates of the items")
+	}
+	if status == "cancelled" {
+		// Cancelling gives back stock, coupon and delivery slot, and records why
+		return fmt.Errorf("invalid order status: orders are cancelled through CancelOrder")
+	}
+	if status == "payment_failed" {
+		_, err := s.FailPayment(ctx, id, PaymentFailureManual)
+		return err
+	}
 
-	err := s.orderRepo.UpdateStatus(id, status)
+	order, err := s.orderRepo.GetByID(id)
 	if err != nil {
+		return err
+	}
+	paid := status == "processing"
+	if paid && order.Status == "preorder" {
+		// Already paid; a repeated confirmation only releases it when due
+		if order.ReleaseAt != nil && time.Now().Before(*order.ReleaseAt) {
+			return nil
+		}
+		return s.releasePreorder(ctx, order)
+	}
+	if order.Status == status {
+		// Repeated notifications, e.g. a replayed webhook, change nothing
+		return nil
+	}
+	if !orderStatusTransitions[order.Status][status] {
+		return fmt.Errorf("invalid order state: order %s cannot move from %s to %s", id, order.Status, status)
+	}
+	if paid {
+		// Once an order is paid its reserved stock is sold for good
+		if err := s.commitReservations(id); err != nil {
+			return err
+		}
+		if order.ReleaseAt != nil && time.Now().Before(*order.ReleaseAt) {
+			status = "preorder"
+		}
+	}
+
+	if err := s.orderRepo.UpdateStatus(id, status); err != nil {
 		return fmt.Errorf("failed to update order status for order %s: %w", id, err)
 	}
+	s.cascadeItemStatus(id, status, "")
+	if status == "processing" {
+		s.fulfillLicenses(id)
+	}
+
+	publishEvent(ctx, s.mqClient, "order", "order.status_updated", map[string]interface{}{
+		"orderID": id,
+		"status":  status,
+	})
+	if paid {
+		// Payment confirmations are routed to the critical priority tier
+		publishEvent(ctx, s.mqClient, "order", "order.payment_confirmed", map[string]interface{}{
+			"orderID": id,
+		})
+	}
+	s.dispatchOrderWebhook(WebhookEventOrderUpdated, id)
+
+	return nil
+}
+
+// fulfillLicenses assigns the license keys of a paid order's digital products.
+func (s *OrderService) fulfillLicenses(orderID string) {
+	if s.licenses == nil {
+		return
+	}
+	// A short pool is topped up and retried by an admin; the payment stands
+	if err := s.licenses.Fulfill(orderID); err != nil {
+		log.Printf("Warning: Failed to assign license keys to order %s: %v", orderID, err)
+	}
+}
+
+// ReleasePreorder hands a paid preorder to fulfillment now, before its release date.
+func (s *OrderService) ReleasePreorder(ctx context.Context, id string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != "preorder" {
+		return nil, fmt.Errorf("invalid order state: order %s is %s, not a preorder", id, order.Status)
+	}
+	if err := s.releasePreorder(ctx, order); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetByID(id)
+}
+
+// ReleasePreorders hands the paid preorders whose release date has come to
+// fulfillment. It returns the number of orders released.
+func (s *OrderService) ReleasePreorders(now time.Time) (int, error) {
+	orders, err := s.orderRepo.ListDuePreorders(now, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for i := range orders {
+		if err := s.releasePreorder(context.Background(), &orders[i]); err != nil {
+			log.Printf("Error releasing preorder %s: %v", orders[i].ID, err)
+			continue
+		}
+		released++
+	}
+	return released, nil
+}
+
+// releasePreorder moves a preorder to processing so it is picked for fulfillment.
+func (s *OrderService) releasePreorder(ctx context.Context, order *models.Order) error {
+	if err := s.orderRepo.TransitionStatus(order.ID, []string{"preorder"}, "processing"); err != nil {
+		return err
+	}
+	s.cascadeItemStatus(order.ID, "processing", "")
+	s.fulfillLicenses(order.ID)
+
+	publishEvent(ctx, s.mqClient, "order", "order.status_updated", map[string]interface{}{
+		"orderID": order.ID,
+		"status":  "processing",
+	})
+	s.dispatchOrderWebhook(WebhookEventOrderUpdated, order.ID)
+	return nil
+}
+
+// cascadeItemStatus moves the order's items to the item status matching the
+// order's new status. Failures are logged; the order status is already saved.
+func (s *OrderService) cascadeItemStatus(orderID, orderStatus, reason string) {
+	fromStatuses, ok := itemCascades[orderStatus]
+	if !ok {
+		return
+	}
+	if _, err := s.orderRepo.UpdateItemStatuses(orderID, nil, fromStatuses, orderStatus, reason); err != nil {
+		log.Printf("Warning: Failed to update item statuses of order %s: %v", orderID, err)
+	}
+}
+
+// fulfilmentStatuses are the order statuses in which items change status one by one.
+var fulfilmentStatuses = []string{"processing", "partially_shipped", "shipped"}
+
+// itemTransitions lists the statuses an item can move to from each status.
+var itemTransitions = map[string][]string{
+	models.ItemStatusPending:     {models.ItemStatusBackordered, models.ItemStatusShipped, models.ItemStatusCancelled},
+	models.ItemStatusProcessing:  {models.ItemStatusBackordered, models.ItemStatusShipped, models.ItemStatusCancelled},
+	models.ItemStatusBackordered: {models.ItemStatusProcessing, models.ItemStatusShipped, models.ItemStatusCancelled},
+	models.ItemStatusShipped:     {models.ItemStatusDelivered},
+}
+
+// UpdateItemStatus moves one item of a paid order to status, for example
+// backordering it while the rest ships. Cancelling an item requires one of the
+// cancellation reasons and returns its stock. The order's status is derived
+// again from its items; see models.DeriveOrderStatus.
+func (s *OrderService) UpdateItemStatus(ctx context.Context, orderID string, itemID uint, status, reason string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(fulfilmentStatuses, order.Status) {
+		return nil, fmt.Errorf("invalid order state: items of a %s order cannot change status", order.Status)
+	}
+	var item *models.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			item = &order.Items[i]
+		}
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item %d of order %s not found", itemID, orderID)
+	}
+
+	current := item.ItemStatus()
+	if !containsString(itemTransitions[current], status) {
+		return nil, fmt.Errorf("invalid item status: an item cannot move from %s to %s", current, status)
+	}
+	if status == models.ItemStatusCancelled {
+		if reason, err = s.cancelReasons.Check("cancellation", reason); err != nil {
+			return nil, err
+		}
+	} else {
+		reason = ""
+	}
+
+	updated, err := s.orderRepo.UpdateItemStatuses(orderID, []uint{itemID}, []string{current}, status, reason)
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		return nil, fmt.Errorf("invalid item status: item %d changed meanwhile, reload the order", itemID)
+	}
+	item.Status = status
+	item.CancelReason = reason
+
+	if status == models.ItemStatusCancelled {
+		if _, err := s.reservationRepo.ReleaseItem(orderID, item.ProductID, item.Quantity, models.ReservationStatusReleased); err != nil {
+			log.Printf("Warning: Failed to restore stock for cancelled item %d of order %s: %v", itemID, orderID, err)
+		}
+	}
+
+	publishEvent(ctx, s.mqClient, "order", "order.item_status_updated", map[string]interface{}{
+		"orderID":   orderID,
+		"itemID":    item.ID,
+		"productID": item.ProductID,
+		"status":    status,
+		"reason":    reason,
+	})
+
+	derived := models.DeriveOrderStatus(order.Items)
+	switch {
+	case derived == "" || derived == order.Status:
+	case derived == "cancelled":
+		// The last item was cancelled, so the whole order is
+		if err := s.orderRepo.Cancel(orderID, fulfilmentStatuses, reason, ""); err != nil {
+			return nil, err
+		}
+		previousStatus := order.Status
+		now := time.Now()
+		order.Status, order.CancelReason, order.CancelledAt = derived, reason, &now
+		s.releaseCancelledOrder(order)
+		publishEvent(ctx, s.mqClient, "order", "order.cancelled", map[string]interface{}{
+			"orderID":        order.ID,
+			"userID":         order.UserID,
+			"previousStatus": previousStatus,
+			"reason":         reason,
+		})
+		s.dispatchOrderWebhook(WebhookEventOrderCancelled, orderID)
+		return order, nil
+	default:
+		if err := s.orderRepo.TransitionStatus(orderID, fulfilmentStatuses, derived); err != nil {
+			return nil, err
+		}
+		order.Status = derived
+		publishEvent(ctx, s.mqClient, "order", "order.status_updated", map[string]interface{}{
+			"orderID": orderID,
+			"status":  derived,
+		})
+	}
+	s.dispatchOrderWebhook(WebhookEventOrderUpdated, orderID)
+	return order, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTracking records the carrier and tracking number of a shipped order.
+func (s *OrderService) UpdateTracking(id, carrier, trackingNumber string) error {
+	if err := s.orderRepo.UpdateTracking(id, carrier, trackingNumber); err != nil {
+		return fmt.Errorf("failed to update tracking for order %s: %w", id, err)
+	}
+	s.dispatchOrderWebhook(WebhookEventOrderUpdated, id)
+	return nil
+}
+
+// cancellableStatuses are the order statuses from which a customer may cancel.
+var cancellableStatuses = []string{"pending", "payment_failed", "processing", "preorder"}
+
+// maxCancelNoteLength is the longest free-text note kept with a cancellation.
+const maxCancelNoteLength = 500
+
+// CancelOrder cancels an order on behalf of its owner or an admin and restores
+// its stock. The reason must be one of the configured cancellation reasons.
+func (s *OrderService) CancelOrder(ctx context.Context, id string, userID string, isAdmin bool, reason, note string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		// Hide other users' orders entirely
+		return nil, fmt.Errorf("order with ID %s not found", id)
+	}
+	reason, err = s.cancelReasons.Check("cancellation", reason)
+	if err != nil {
+		return nil, err
+	}
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > maxCancelNoteLength {
+		return nil, fmt.Errorf("invalid cancellation note: at most %d characters are allowed", maxCancelNoteLength)
+	}
+
+	if err := s.orderRepo.Cancel(id, cancellableStatuses, reason, note); err != nil {
+		return nil, err
+	}
+	previousStatus := order.Status
+	now := time.Now()
+	s.cascadeItemStatus(order.ID, "cancelled", reason)
+	for i := range order.Items {
+		if order.Items[i].AwaitingShipment() || order.Items[i].ItemStatus() == models.ItemStatusBackordered {
+			order.Items[i].Status = models.ItemStatusCancelled
+			order.Items[i].CancelReason = reason
+		}
+	}
+	order.Status = "cancelled"
+	order.CancelReason = reason
+	order.CancelNote = note
+	order.CancelledAt = &now
+	s.releaseCancelledOrder(order)
+
+	publishEvent(ctx, s.mqClient, "order", "order.cancelled", map[string]interface{}{
+		"orderID":        order.ID,
+		"userID":         order.UserID,
+		"previousStatus": previousStatus,
+		"cancelledBy":    userID,
+		"reason":         reason,
+	})
+	s.dispatchOrderWebhook(WebhookEventOrderCancelled, order.ID)
+	return order, nil
+}
+
+// ReorderItem is a line of a previous order, priced and checked against
+// current stock so it can be ordered again.
+type ReorderItem struct {
+	ProductID        string  `json:"product_id"`
+	Name             string  `json:"name"`
+	Quantity         int     `json:"quantity"` // What can be ordered now; 0 if unavailable
+	PreviousQuantity int     `json:"previous_quantity"`
+	Price            float64 `json:"price"`
+	PreviousPrice    float64 `json:"previous_price"`
+	Issue            string  `json:"issue,omitempty"` // Why the line differs from the previous order
+}
+
+// ReorderDraft is a new order prefilled from a previous one. It is not saved;
+// the customer reviews it and places it like any other order.
+type ReorderDraft struct {
+	SourceOrderID   string               `json:"source_order_id"`
+	Items           []ReorderItem        `json:"items"`
+	Subtotal        float64              `json:"subtotal"` // Orderable items at current prices
+	ShippingAddress models.PostalAddress `json:"shipping_address"`
+	BillingAddress  models.PostalAddress `json:"billing_address"`
+	ShippingMethod  string               `json:"shipping_method,omitempty"`
+}
+
+// Reasons a reordered line differs from the original order.
+const (
+	ReorderIssueUnavailable  = "unavailable"
+	ReorderIssueOutOfStock   = "out_of_stock"
+	ReorderIssueReduced      = "quantity_reduced"
+	ReorderIssuePriceChanged = "price_changed"
+)
+
+// Reorder drafts a new order with the items of one of the user's previous
+// orders at today's prices, capped at the stock available now.
+func (s *OrderService) Reorder(id, userID string) (*ReorderDraft, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		// Hide other users' orders entirely
+		return nil, fmt.Errorf("order with ID %s not found", id)
+	}
 
-	// Optionally, publish an event for order status update
-	// err = s.rabbitMQClient.PublishOrderStatusUpdated(id, status)
-	// if err != nil {
-	// 	log.Printf("Warning: Failed to publish order status update event for order %s: %v", id, err)
-	// }
+	draft := &ReorderDraft{
+		SourceOrderID:   order.ID,
+		Items:           []ReorderItem{},
+		ShippingAddress: order.ShippingAddress,
+		BillingAddress:  order.BillingAddress,
+		ShippingMethod:  order.ShippingMethod,
+	}
+	lines := map[string]int{} // Product ID to its index in draft.Items
+	for _, item := range order.Items {
+		if i, ok := lines[item.ProductID]; ok {
+			draft.Items[i].PreviousQuantity += item.Quantity
+			continue
+		}
+		lines[item.ProductID] = len(draft.Items)
+		draft.Items = append(draft.Items, ReorderItem{
+			ProductID:        item.ProductID,
+			PreviousQuantity: item.Quantity,
+			PreviousPrice:    item.Price,
+		})
+	}
 
+	for i := range draft.Items {
+		line := &draft.Items[i]
+		product, err := s.productRepo.GetByID(line.ProductID)
+		if err != nil || product.IsArchived() {
+			line.Issue = ReorderIssueUnavailable
+			continue
+		}
+		line.Name = product.Name
+		line.Price = product.Price
+		line.Quantity = line.PreviousQuantity
+		if !product.IsDigital() && product.Stock < line.Quantity {
+			line.Quantity = max(product.Stock, 0)
+			line.Issue = ReorderIssueReduced
+			if line.Quantity == 0 {
+				line.Issue = ReorderIssueOutOfStock
+			}
+		}
+		if line.Issue == "" && line.Price != line.PreviousPrice {
+			line.Issue = ReorderIssuePriceChanged
+		}
+		draft.Subtotal += line.Price * float64(line.Quantity)
+	}
+	draft.Subtotal = roundCents(draft.Subtotal)
+	return draft, nil
+}
+
+// ResendReceipt queues the order confirmation email again, e.g. when the
+// customer never received it. Customers may only resend their own receipts.
+func (s *OrderService) ResendReceipt(ctx context.Context, id, userID string, isAdmin bool) error {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && order.UserID != userID {
+		// Hide other users' orders entirely
+		return fmt.Errorf("order with ID %s not found", id)
+	}
+
+	publishEvent(ctx, s.mqClient, "order", "order.receipt_requested", map[string]interface{}{
+		"orderID":     order.ID,
+		"userID":      order.UserID,
+		"requestedBy": userID,
+	})
 	return nil
 }
+
+// unpaidStatuses are the statuses of orders still waiting to be paid.
+var unpaidStatuses = []string{"pending", "payment_failed"}
+
+// OrderCancelReasonUnpaid is the reason recorded when an order is cancelled
+// because it was never paid.
+const OrderCancelReasonUnpaid = "unpaid"
+
+// CancelUnpaidOrders cancels orders that are still unpaid after being placed
+// before cutoff and restores their stock. Customers are told through the
+// order.cancelled event. It returns the number of orders cancelled.
+func (s *OrderService) CancelUnpaidOrders(cutoff time.Time) (int, error) {
+	orders, err := s.orderRepo.ListStale(unpaidStatuses, cutoff, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, order := range orders {
+		// A payment arriving meanwhile makes the cancellation fail, which is what we want
+		if err := s.orderRepo.Cancel(order.ID, unpaidStatuses, OrderCancelReasonUnpaid, ""); err != nil {
+			log.Printf("Error cancelling unpaid order %s: %v", order.ID, err)
+			continue
+		}
+		s.releaseCancelledOrder(&order)
+		s.cascadeItemStatus(order.ID, "cancelled", OrderCancelReasonUnpaid)
+		cancelled++
+		publishEvent(context.Background(), s.mqClient, "order", "order.cancelled", map[string]interface{}{
+			"orderID":        order.ID,
+			"userID":         order.UserID,
+			"previousStatus": order.Status,
+			"cancelledBy":    "system",
+			"reason":         OrderCancelReasonUnpaid,
+		})
+		s.dispatchOrderWebhook(WebhookEventOrderCancelled, order.ID)
+	}
+	return cancelled, nil
+}
+
+// Reasons recorded on order.payment_failed events.
+const (
+	PaymentFailureDeclined = "declined" // The provider reported a failed payment
+	PaymentFailureTimeout  = "timeout"  // The order was not paid before its stock reservation expired
+	PaymentFailureManual   = "manual"   // An admin marked the payment as failed
+)
+
+// FailPayment compensates for a payment that failed or timed out: the pending
+// order moves to payment_failed, its reserved stock and coupon use are given
+// back and order.payment_failed is published. Every step is idempotent, so
+// calling it again after a partial failure finishes the compensation. A late
+// successful payment still completes the order if the stock is available.
+func (s *OrderService) FailPayment(ctx context.Context, id, reason string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := false
+	switch order.Status {
+	case "payment_failed":
+	case "pending":
+		// The status condition keeps a concurrent successful payment from being undone
+		if err := s.orderRepo.TransitionStatus(id, []string{"pending"}, "payment_failed"); err != nil {
+			return nil, err
+		}
+		failed = true
+	default:
+		return nil, fmt.Errorf("invalid order state: order %s is %s and no longer awaiting payment", id, order.Status)
+	}
+	order.Status = "payment_failed"
+
+	released, err := s.reservationRepo.Release(id, models.ReservationStatusReleased)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release stock for order %s: %w", id, err)
+	}
+	if s.coupons != nil && order.CouponCode != "" {
+		if err := s.coupons.Release(id); err != nil {
+			return nil, fmt.Errorf("failed to release coupon for order %s: %w", id, err)
+		}
+	}
+
+	// Repeated notifications for an order that was already compensated publish nothing
+	if failed || released > 0 {
+		publishEvent(ctx, s.mqClient, "order", "order.payment_failed", map[string]interface{}{
+			"orderID":       order.ID,
+			"userID":        order.UserID,
+			"reason":        reason,
+			"releasedItems": released,
+		})
+		s.dispatchOrderWebhook(WebhookEventOrderUpdated, order.ID)
+	}
+	return order, nil
+}
+
+// commitReservations converts an order's stock reservations into a sale. If the
+// reservations already expired, the stock is reserved again before committing.
+func (s *OrderService) commitReservations(orderID string) error {
+	reservations, err := s.reservationRepo.ListByOrder(orderID)
+	if err != nil {
+		return err
+	}
+	active, committed := 0, 0
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case models.ReservationStatusActive:
+			active++
+		case models.ReservationStatusCommitted:
+			committed++
+		}
+	}
+	if committed > 0 || len(reservations) == 0 {
+		return nil // Already committed, or nothing was ever reserved (digital-only order)
+	}
+
+	if active == 0 {
+		// The hold timed out before payment; try to take the stock again
+		order, err := s.orderRepo.GetByID(orderID)
+		if err != nil {
+			return err
+		}
+		var items []models.OrderItem
+		for _, reservation := range reservations {
+			// Stock given back by a failed payment can be taken again by a late one
+			if reservation.Status == models.ReservationStatusExpired ||
+				(reservation.Status == models.ReservationStatusReleased && order.Status == "payment_failed") {
+				items = append(items, models.OrderItem{ProductID: reservation.ProductID, Quantity: reservation.Quantity})
+			}
+		}
+		if len(items) == 0 {
+			return fmt.Errorf("order %s has no stock to commit", order.ID)
+		}
+		if _, err := s.reservationRepo.Reserve(orderID, items, time.Now().Add(s.reservationTTL)); err != nil {
+			return fmt.Errorf("failed to reserve stock again for order %s: %w", orderID, err)
+		}
+	}
+
+	_, err = s.reservationRepo.Commit(orderID)
+	return err
+}
+
+// ReleaseExpiredReservations returns the stock held by unpaid orders whose
+// reservations have expired and fails their payment. It returns the number of
+// orders affected.
+func (s *OrderService) ReleaseExpiredReservations() (int, error) {
+	orderIDs, err := s.reservationRepo.ListExpiredOrderIDs(time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	releasedOrders := 0
+	for _, orderID := range orderIDs {
+		released, err := s.reservationRepo.Release(orderID, models.ReservationStatusExpired)
+		if err != nil {
+			log.Printf("Error releasing expired reservations for order %s: %v", orderID, err)
+			continue
+		}
+		if released > 0 {
+			releasedOrders++
+			publishEvent(context.Background(), s.mqClient, "order", "order.reservation_expired", map[string]interface{}{
+				"orderID": orderID,
+			})
+			if _, err := s.FailPayment(context.Background(), orderID, PaymentFailureTimeout); err != nil {
+				log.Printf("Error failing payment of expired order %s: %v", orderID, err)
+			}
+		}
+	}
+	return releasedOrders, nil
+}
+
+// maxBulkOrders and maxBulkConcurrency bound a single bulk order request.
+const (
+	maxBulkOrders      = 1000
+	maxBulkConcurrency = 32
+)
+
+// BulkOrderFailure is an order of a bulk request that could not be created.
+type BulkOrderFailure struct {
+	Index int    `json:"index"` // Position of the order in the request
+	Error string `json:"error"`
+}
+
+// BulkOrderResult reports what a bulk order request created.
+type BulkOrderResult struct {
+	Created   int                `json:"created"`
+	Paid      int                `json:"paid"`
+	OrderIDs  []string           `json:"order_ids"`
+	Failures  []BulkOrderFailure `json:"failures"`
+	ElapsedMS int64              `json:"elapsed_ms"`
+}
+
+// CreateOrdersBulk places many orders through the regular checkout path, so
+// stock is reserved and events are published as for any order, using up to
+// concurrency workers. With markPaid the orders skip payment and are
+// confirmed right away. It is meant for benchmarking the order pipeline.
+func (s *OrderService) CreateOrdersBulk(ctx context.Context, orderRequests []models.Order, markPaid bool, concurrency int) (*BulkOrderResult, error) {
+	if len(orderRequests) == 0 || len(orderRequests) > maxBulkOrders {
+		return nil, fmt.Errorf("invalid bulk request: between 1 and %d orders are allowed", maxBulkOrders)
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if concurrency > maxBulkConcurrency {
+		concurrency = maxBulkConcurrency
+	}
+
+	started := time.Now()
+	orderIDs := make([]string, len(orderRequests))
+	paid := make([]bool, len(orderRequests))
+	errs := make([]error, len(orderRequests))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				order, err := s.CreateOrder(ctx, orderRequests[i])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				orderIDs[i] = order.ID
+				if !markPaid {
+					continue
+				}
+				if err := s.UpdateOrderStatus(ctx, order.ID, "processing"); err != nil {
+					errs[i] = fmt.Errorf("order %s was created but not confirmed: %w", order.ID, err)
+					continue
+				}
+				paid[i] = true
+			}
+		}()
+	}
+	for i := range orderRequests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	result := &BulkOrderResult{OrderIDs: []string{}, Failures: []BulkOrderFailure{}}
+	for i := range orderRequests {
+		if orderIDs[i] != "" {
+			result.Created++
+			result.OrderIDs = append(result.OrderIDs, orderIDs[i])
+		}
+		if paid[i] {
+			result.Paid++
+		}
+		if errs[i] != nil {
+			result.Failures = append(result.Failures, BulkOrderFailure{Index: i, Error: errs[i].Error()})
+		}
+	}
+	result.ElapsedMS = time.Since(started).Milliseconds()
+	return result, nil
+}
@@ -0,0 +1,697 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"toko/internal/events"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderService_FailPaymentReleasesStockAndCoupon(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	couponRepo := repositories.NewMockCouponRepository()
+	coupons := services.NewCouponService(couponRepo)
+	orderService.SetCoupons(coupons)
+	require.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "ONCE", Type: models.CouponTypeFixed, Value: 5, UsageLimit: 1, Active: true}))
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 3}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+		CouponCode:      "ONCE",
+	})
+	require.NoError(t, err)
+	stocked, _ := productRepo.GetByID(product.ID)
+	assert.Equal(t, 7, stocked.Stock)
+
+	failed, err := orderService.FailPayment(context.Background(), order.ID, services.PaymentFailureDeclined)
+	require.NoError(t, err)
+	assert.Equal(t, "payment_failed", failed.Status)
+	stocked, _ = productRepo.GetByID(product.ID)
+	assert.Equal(t, 10, stocked.Stock)
+	_, _, err = coupons.Evaluate("ONCE", "user-2", 40, time.Now())
+	assert.NoError(t, err, "the coupon use is given back")
+
+	// Repeated notifications change nothing
+	_, err = orderService.FailPayment(context.Background(), order.ID, services.PaymentFailureDeclined)
+	require.NoError(t, err)
+	stocked, _ = productRepo.GetByID(product.ID)
+	assert.Equal(t, 10, stocked.Stock)
+
+	// A late payment takes the stock again
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	stocked, _ = productRepo.GetByID(product.ID)
+	assert.Equal(t, 7, stocked.Stock)
+
+	_, err = orderService.FailPayment(context.Background(), order.ID, services.PaymentFailureDeclined)
+	assert.ErrorContains(t, err, "invalid order state")
+	_, err = orderService.FailPayment(context.Background(), "missing", services.PaymentFailureDeclined)
+	assert.ErrorContains(t, err, "not found")
+}
+
+// recordingPublisher keeps the messages published through it.
+type recordingPublisher struct {
+	routingKeys []string
+	bodies      [][]byte
+}
+
+func (p *recordingPublisher) PublishWithHeaders(exchange, routingKey string, body []byte, headers amqp.Table) error {
+	p.routingKeys = append(p.routingKeys, routingKey)
+	p.bodies = append(p.bodies, body)
+	return nil
+}
+
+func TestOrderService_PublishesOrderEvents(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	publisher := &recordingPublisher{}
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), publisher, time.Hour)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"order.created"}, publisher.routingKeys)
+	assert.Contains(t, string(publisher.bodies[0]), order.ID)
+
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	assert.Contains(t, publisher.routingKeys, "order.status_updated")
+}
+
+func TestOrderService_PropagatesCorrelation(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	broker := rabbitmq.NewMemoryBroker()
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), broker, time.Hour)
+
+	ctx := events.WithCorrelation(context.Background(), events.Correlation{CorrelationID: "req-1", CausationID: "req-1"})
+	order, err := orderService.CreateOrder(ctx, models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	created := broker.Published("order.created")
+	require.Len(t, created, 1)
+	assert.Equal(t, "req-1", created[0].Headers[events.CorrelationIDHeader])
+	assert.Equal(t, "req-1", created[0].Headers[events.CausationIDHeader])
+
+	// Events published outside a request, e.g. by jobs, carry no correlation
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	updated := broker.Published("order.status_updated")
+	require.Len(t, updated, 1)
+	assert.NotContains(t, updated[0].Headers, events.CorrelationIDHeader)
+}
+
+func TestOrderService_ReleaseExpiredReservationsFailsPayment(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	// Reservations that are already expired when they are made
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, -time.Minute)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 2}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+
+	released, err := orderService.ReleaseExpiredReservations()
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	stored, err := orderRepo.GetByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "payment_failed", stored.Status)
+	stocked, _ := productRepo.GetByID(product.ID)
+	assert.Equal(t, 10, stocked.Stock)
+}
+
+func TestOrderService_CancelUnpaidOrders(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	request := models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 2}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	}
+	unpaid, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	paid, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), paid.ID, "processing"))
+
+	// Nothing is old enough yet
+	cancelled, err := orderService.CancelUnpaidOrders(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, cancelled)
+
+	cancelled, err = orderService.CancelUnpaidOrders(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+
+	stored, err := orderRepo.GetByID(unpaid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", stored.Status)
+	stored, err = orderRepo.GetByID(paid.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "processing", stored.Status)
+}
+
+func TestOrderService_Reorder(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	chair := &models.Product{Name: "Chair", Price: 100, Stock: 5}
+	vase := &models.Product{Name: "Vase", Price: 15, Stock: 5}
+	for _, product := range []*models.Product{lamp, chair, vase} {
+		require.NoError(t, productRepo.Create(product))
+	}
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID: "user-1",
+		Items: []models.OrderItem{
+			{ProductID: lamp.ID, Quantity: 2},
+			{ProductID: chair.ID, Quantity: 4},
+			{ProductID: vase.ID, Quantity: 1},
+		},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+
+	// Since then the lamp got dearer, the chairs sold out to one and the vase was removed
+	lampNow, _ := productRepo.GetByID(lamp.ID)
+	lampNow.Price = 45
+	require.NoError(t, productRepo.Update(lampNow))
+	chairNow, _ := productRepo.GetByID(chair.ID)
+	chairNow.Stock = 1
+	require.NoError(t, productRepo.Update(chairNow))
+	require.NoError(t, productRepo.Delete(vase.ID))
+
+	draft, err := orderService.Reorder(order.ID, "user-1")
+	require.NoError(t, err)
+	require.Len(t, draft.Items, 3)
+	assert.Equal(t, services.ReorderItem{ProductID: lamp.ID, Name: "Lamp", Quantity: 2, PreviousQuantity: 2, Price: 45, PreviousPrice: 40, Issue: services.ReorderIssuePriceChanged}, draft.Items[0])
+	assert.Equal(t, 1, draft.Items[1].Quantity)
+	assert.Equal(t, services.ReorderIssueReduced, draft.Items[1].Issue)
+	assert.Equal(t, 0, draft.Items[2].Quantity)
+	assert.Equal(t, services.ReorderIssueUnavailable, draft.Items[2].Issue)
+	assert.Equal(t, 190.0, draft.Subtotal)
+	assert.Equal(t, "Jakarta", draft.ShippingAddress.City)
+
+	_, err = orderService.Reorder(order.ID, "user-2")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestOrderService_CreateOrderBooksDeliverySlot(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	slots := services.NewDeliverySlotService(repositories.NewMockDeliverySlotRepository())
+	orderService.SetDeliverySlots(slots)
+
+	start := time.Now().Add(24 * time.Hour)
+	slot := &models.DeliverySlot{StartsAt: start, EndsAt: start.Add(2 * time.Hour), Capacity: 1, Active: true}
+	require.NoError(t, slots.CreateSlot(slot))
+	past := &models.DeliverySlot{StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour), Capacity: 5, Active: true}
+	require.NoError(t, slots.CreateSlot(past))
+
+	request := models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+		DeliverySlotID:  slot.ID,
+	}
+	order, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, slot.ID, order.DeliverySlotID)
+	require.NotNil(t, order.DeliveryStart)
+
+	available, err := slots.AvailableSlots(time.Now().Add(-2*time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, available, "the full and the started slot are not offered")
+
+	_, err = orderService.CreateOrder(context.Background(), request)
+	assert.ErrorContains(t, err, "invalid delivery slot")
+	stocked, _ := productRepo.GetByID(product.ID)
+	assert.Equal(t, 9, stocked.Stock, "the stock of the rejected order is released")
+
+	request.DeliverySlotID = past.ID
+	_, err = orderService.CreateOrder(context.Background(), request)
+	assert.ErrorContains(t, err, "already started")
+
+	// Cancelling frees the window for someone else
+	_, err = orderService.CancelOrder(context.Background(), order.ID, "user-1", false, "", "")
+	require.NoError(t, err)
+	request.DeliverySlotID = slot.ID
+	_, err = orderService.CreateOrder(context.Background(), request)
+	assert.NoError(t, err)
+}
+
+func TestOrderService_CancelOrderRequiresReason(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetCancellationReasons(services.NewReasonList([]string{" Changed_Mind", "other", "other", ""}))
+	assert.Equal(t, services.ReasonList{"changed_mind", "other"}, orderService.CancellationReasons())
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+
+	_, err = orderService.CancelOrder(context.Background(), order.ID, "user-1", false, "", "")
+	assert.ErrorContains(t, err, "invalid cancellation reason")
+	_, err = orderService.CancelOrder(context.Background(), order.ID, "user-1", false, "too_slow", "")
+	assert.ErrorContains(t, err, "invalid cancellation reason")
+
+	cancelled, err := orderService.CancelOrder(context.Background(), order.ID, "user-1", false, "CHANGED_MIND", " Found it in a shop ")
+	require.NoError(t, err)
+	assert.Equal(t, "changed_mind", cancelled.CancelReason)
+	stored, err := orderRepo.GetByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "changed_mind", stored.CancelReason)
+	assert.Equal(t, "Found it in a shop", stored.CancelNote)
+	assert.NotNil(t, stored.CancelledAt)
+}
+
+func TestOrderService_ItemStatusesDriveOrderStatus(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	desk := &models.Product{Name: "Desk", Price: 200, Stock: 10}
+	require.NoError(t, productRepo.Create(lamp))
+	require.NoError(t, productRepo.Create(desk))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetCancellationReasons(services.NewReasonList([]string{"out_of_stock"}))
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: lamp.ID, Quantity: 2}, {ProductID: desk.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	lampItem, deskItem := order.Items[0].ID, order.Items[1].ID
+
+	_, err = orderService.UpdateItemStatus(context.Background(), order.ID, lampItem, models.ItemStatusShipped, "")
+	assert.ErrorContains(t, err, "invalid order state", "unpaid orders are not fulfilled")
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	stored, _ := orderRepo.GetByID(order.ID)
+	assert.Equal(t, models.ItemStatusProcessing, stored.Items[0].Status)
+
+	_, err = orderService.UpdateItemStatus(context.Background(), order.ID, deskItem, models.ItemStatusBackordered, "")
+	require.NoError(t, err)
+	updated, err := orderService.UpdateItemStatus(context.Background(), order.ID, lampItem, models.ItemStatusShipped, "")
+	require.NoError(t, err)
+	assert.Equal(t, "partially_shipped", updated.Status)
+
+	_, err = orderService.UpdateItemStatus(context.Background(), order.ID, lampItem, models.ItemStatusCancelled, "out_of_stock")
+	assert.ErrorContains(t, err, "invalid item status", "shipped items cannot be cancelled")
+	_, err = orderService.UpdateItemStatus(context.Background(), order.ID, 999, models.ItemStatusShipped, "")
+	assert.ErrorContains(t, err, "not found")
+	assert.ErrorContains(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "partially_shipped"), "invalid order status")
+
+	updated, err = orderService.UpdateItemStatus(context.Background(), order.ID, deskItem, models.ItemStatusShipped, "")
+	require.NoError(t, err)
+	assert.Equal(t, "shipped", updated.Status)
+
+	// Delivering the whole order delivers every item
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "delivered"))
+	stored, _ = orderRepo.GetByID(order.ID)
+	for _, item := range stored.Items {
+		assert.Equal(t, models.ItemStatusDelivered, item.Status)
+	}
+}
+
+func TestOrderService_CancellingEveryItemCancelsOrder(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	desk := &models.Product{Name: "Desk", Price: 200, Stock: 10}
+	require.NoError(t, productRepo.Create(lamp))
+	require.NoError(t, productRepo.Create(desk))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetCancellationReasons(services.NewReasonList([]string{"out_of_stock"}))
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: lamp.ID, Quantity: 2}, {ProductID: desk.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+
+	_, err = orderService.UpdateItemStatus(context.Background(), order.ID, order.Items[0].ID, models.ItemStatusCancelled, "")
+	assert.ErrorContains(t, err, "invalid cancellation reason")
+	updated, err := orderService.UpdateItemStatus(context.Background(), order.ID, order.Items[0].ID, models.ItemStatusCancelled, "out_of_stock")
+	require.NoError(t, err)
+	assert.Equal(t, "processing", updated.Status)
+	stocked, _ := productRepo.GetByID(lamp.ID)
+	assert.Equal(t, 10, stocked.Stock, "the cancelled item's stock is returned")
+	stocked, _ = productRepo.GetByID(desk.ID)
+	assert.Equal(t, 9, stocked.Stock)
+
+	updated, err = orderService.UpdateItemStatus(context.Background(), order.ID, order.Items[1].ID, models.ItemStatusCancelled, "out_of_stock")
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", updated.Status)
+	stored, _ := orderRepo.GetByID(order.ID)
+	assert.Equal(t, "cancelled", stored.Status)
+	assert.Equal(t, "out_of_stock", stored.CancelReason)
+}
+
+func TestOrderService_PurchaseLimits(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	sneaker := &models.Product{Name: "Limited sneaker", Price: 150, Stock: 100, MaxPerOrder: 2, MaxPerCustomer: 3, LimitWindowHours: 24}
+	require.NoError(t, productRepo.Create(sneaker))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetCancellationReasons(services.NewReasonList([]string{"changed_mind"}))
+
+	request := func(userID string, quantities ...int) models.Order {
+		order := models.Order{UserID: userID, ShippingAddress: testPostalAddress("Jakarta")}
+		for _, quantity := range quantities {
+			order.Items = append(order.Items, models.OrderItem{ProductID: sneaker.ID, Quantity: quantity})
+		}
+		return order
+	}
+
+	// Split lines of the same product count together
+	_, err := orderService.CreateOrder(context.Background(), request("user-1", 2, 1))
+	assert.ErrorContains(t, err, "limited to 2 per order")
+
+	first, err := orderService.CreateOrder(context.Background(), request("user-1", 2))
+	require.NoError(t, err)
+	_, err = orderService.CreateOrder(context.Background(), request("user-1", 2))
+	assert.ErrorContains(t, err, "you can order 1 more")
+	_, err = orderService.CreateOrder(context.Background(), request("user-1", 1))
+	require.NoError(t, err)
+
+	// Other customers have their own allowance
+	_, err = orderService.CreateOrder(context.Background(), request("user-2", 2))
+	require.NoError(t, err)
+
+	// Cancelled orders no longer count
+	_, err = orderService.CancelOrder(context.Background(), first.ID, "user-1", false, "changed_mind", "")
+	require.NoError(t, err)
+	_, err = orderService.CreateOrder(context.Background(), request("user-1", 2))
+	assert.NoError(t, err)
+}
+
+func TestOrderService_CheckoutFields(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	book := &models.Product{Name: "Book", Price: 20, Stock: 100}
+	require.NoError(t, productRepo.Create(book))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	_, err := services.NewCheckoutFields(map[string]string{"fax": "required"})
+	assert.Error(t, err)
+	_, err = services.NewCheckoutFields(map[string]string{"tax_id": "sometimes"})
+	assert.Error(t, err)
+
+	fields, err := services.NewCheckoutFields(map[string]string{"tax_id": "required", "company_name": "hidden"})
+	require.NoError(t, err)
+	orderService.SetCheckoutFields(fields)
+	assert.Len(t, fields.Fields(), 3)
+
+	request := func(taxID string) models.Order {
+		address := testPostalAddress("Jakarta")
+		address.Phone = ""
+		return models.Order{
+			UserID:          "user-1",
+			Items:           []models.OrderItem{{ProductID: book.ID, Quantity: 1}},
+			ShippingAddress: address,
+			CompanyName:     "Acme",
+			TaxID:           taxID,
+		}
+	}
+
+	_, err = orderService.CreateOrder(context.Background(), request("  "))
+	assert.ErrorContains(t, err, "invalid checkout field tax_id")
+	_, err = orderService.CreateOrder(context.Background(), request(strings.Repeat("1", 51)))
+	assert.ErrorContains(t, err, "at most 50 characters")
+
+	// Optional phone may be left blank and hidden company names are dropped
+	order, err := orderService.CreateOrder(context.Background(), request(" 01.234.567.8-901.000 "))
+	require.NoError(t, err)
+	assert.Equal(t, "01.234.567.8-901.000", order.TaxID)
+	assert.Empty(t, order.CompanyName)
+}
+
+func TestOrderService_PreordersWaitForRelease(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	release := time.Now().Add(48 * time.Hour)
+	game := &models.Product{Name: "New game", Price: 60, Stock: 10, Preorder: true, ReleaseDate: &release}
+	require.NoError(t, productRepo.Create(game))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: game.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, order.ReleaseAt)
+
+	// Paying puts the order on hold until the release date, even when confirmed twice
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	stored, err := orderRepo.GetByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "preorder", stored.Status)
+	assert.Error(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "preorder"))
+
+	released, err := orderService.ReleasePreorders(time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, released)
+
+	released, err = orderService.ReleasePreorders(release.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	stored, err = orderRepo.GetByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "processing", stored.Status)
+
+	_, err = orderService.ReleasePreorder(context.Background(), order.ID)
+	assert.ErrorContains(t, err, "invalid order state")
+}
+
+func TestOrderService_ExternalReferences(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Mug", Price: 10, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	newOrder := func(ref string) (*models.Order, error) {
+		return orderService.CreateOrder(context.Background(), models.Order{
+			UserID:          "user-1",
+			Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+			ShippingAddress: testPostalAddress("Jakarta"),
+			ExternalRef:     ref,
+			Metadata:        models.OrderMetadata{"channel": "marketplace", "fee": 1.5},
+		})
+	}
+	order, err := newOrder(" MP-1 ")
+	require.NoError(t, err)
+	assert.Equal(t, "MP-1", order.ExternalRef)
+
+	// References identify a single order
+	_, err = newOrder("MP-1")
+	assert.ErrorContains(t, err, "already used")
+
+	found, err := orderService.GetOrderByExternalRef("MP-1")
+	require.NoError(t, err)
+	assert.Equal(t, order.ID, found.ID)
+
+	// Metadata is merged; null removes a key and the reference is kept when not given
+	updated, err := orderService.UpdateOrderReferences(order.ID, services.OrderReferencesUpdate{
+		Metadata: models.OrderMetadata{"fee": nil, "erp_id": "SO-9"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "MP-1", updated.ExternalRef)
+	assert.Equal(t, models.OrderMetadata{"channel": "marketplace", "erp_id": "SO-9"}, updated.Metadata)
+
+	tooLong := strings.Repeat("x", 101)
+	_, err = orderService.UpdateOrderReferences(order.ID, services.OrderReferencesUpdate{ExternalRef: &tooLong})
+	assert.ErrorContains(t, err, "invalid external reference")
+}
+
+func TestOrderService_CreateOrdersBulk(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Mug", Price: 10, Stock: 50}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	requests := make([]models.Order, 60)
+	for i := range requests {
+		requests[i] = models.Order{
+			UserID:          fmt.Sprintf("user-%d", i),
+			Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+			ShippingAddress: testPostalAddress("Jakarta"),
+		}
+	}
+
+	result, err := orderService.CreateOrdersBulk(context.Background(), requests, true, 8)
+	require.NoError(t, err)
+	assert.Equal(t, 50, result.Created)
+	assert.Equal(t, 50, result.Paid)
+	assert.Len(t, result.OrderIDs, 50)
+	assert.Len(t, result.Failures, 10)
+
+	// Stock is never oversold however the orders interleave
+	stored, err := productRepo.GetByID(product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored.Stock)
+	for _, id := range result.OrderIDs {
+		order, err := orderRepo.GetByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, "processing", order.Status)
+	}
+
+	_, err = orderService.CreateOrdersBulk(context.Background(), nil, false, 1)
+	assert.ErrorContains(t, err, "invalid bulk request")
+}
+
+func TestOrderService_Quote(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	coupons := services.NewCouponService(repositories.NewMockCouponRepository())
+	orderService.SetCoupons(coupons)
+	require.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "ONCE", Type: models.CouponTypeFixed, Value: 5, UsageLimit: 1, Active: true}))
+
+	quote, err := orderService.Quote(services.QuoteRequest{
+		Items:       []models.OrderItem{{ProductID: product.ID, Quantity: 3}},
+		Destination: models.PostalAddress{Country: "id"},
+		CouponCode:  "ONCE",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 120.0, quote.Subtotal)
+	assert.Equal(t, 5.0, quote.DiscountTotal)
+	assert.Equal(t, 115.0, quote.GrandTotal)
+	assert.Equal(t, "ONCE", quote.CouponCode)
+	require.Len(t, quote.Items, 1)
+	assert.Equal(t, 40.0, quote.Items[0].Price)
+
+	// Nothing is reserved, redeemed or saved
+	stocked, _ := productRepo.GetByID(product.ID)
+	assert.Equal(t, 10, stocked.Stock)
+	orders, _, err := orderRepo.List(repositories.OrderListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, orders)
+	_, _, err = coupons.Evaluate("ONCE", "user-2", 40, time.Now())
+	assert.NoError(t, err)
+
+	_, err = orderService.Quote(services.QuoteRequest{
+		Items:       []models.OrderItem{{ProductID: product.ID, Quantity: 11}},
+		Destination: models.PostalAddress{Country: "ID"},
+	})
+	assert.ErrorContains(t, err, "insufficient stock")
+	_, err = orderService.Quote(services.QuoteRequest{
+		Items:       []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		Destination: models.PostalAddress{Country: "ID"},
+		CouponCode:  "NOPE",
+	})
+	assert.ErrorContains(t, err, "invalid coupon")
+	_, err = orderService.Quote(services.QuoteRequest{
+		Items: []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+	})
+	assert.ErrorContains(t, err, "invalid destination")
+	_, err = orderService.Quote(services.QuoteRequest{Destination: models.PostalAddress{Country: "ID"}})
+	assert.ErrorContains(t, err, "invalid quote")
+}
+
+func TestOrderService_UpdateOrderStatusRefusesCancellation(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 2}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "shipped"))
+
+	err = orderService.UpdateOrderStatus(context.Background(), order.ID, "cancelled")
+	assert.ErrorContains(t, err, "invalid order status")
+	stored, err := orderRepo.GetByID(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "shipped", stored.Status, "shipped orders cannot be cancelled")
+	assert.Empty(t, stored.CancelReason)
+}
+
+func TestOrderService_UpdateOrderStatusOnlyMovesForward(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderRepo := repositories.NewMockOrderRepository()
+	orderService := services.NewOrderService(orderRepo, productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetCancellationReasons(services.NewReasonList([]string{"changed_mind"}))
+	ctx := context.Background()
+	newOrder := func() *models.Order {
+		order, err := orderService.CreateOrder(ctx, models.Order{
+			UserID:          "user-1",
+			Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+			ShippingAddress: testPostalAddress("Jakarta"),
+		})
+		require.NoError(t, err)
+		return order
+	}
+
+	order := newOrder()
+	assert.ErrorContains(t, orderService.UpdateOrderStatus(ctx, order.ID, "shipped"), "invalid order state")
+	require.NoError(t, orderService.UpdateOrderStatus(ctx, order.ID, "processing"))
+	require.NoError(t, orderService.UpdateOrderStatus(ctx, order.ID, "processing"), "a replayed confirmation changes nothing")
+	require.NoError(t, orderService.UpdateOrderStatus(ctx, order.ID, "shipped"))
+	assert.ErrorContains(t, orderService.UpdateOrderStatus(ctx, order.ID, "pending"), "invalid order state")
+	assert.ErrorContains(t, orderService.UpdateOrderStatus(ctx, order.ID, "processing"), "invalid order state")
+	require.NoError(t, orderService.UpdateOrderStatus(ctx, order.ID, "delivered"))
+	assert.ErrorContains(t, orderService.UpdateOrderStatus(ctx, order.ID, "shipped"), "invalid order state")
+
+	cancelled := newOrder()
+	_, err := orderService.CancelOrder(ctx, cancelled.ID, "user-1", false, "changed_mind", "")
+	require.NoError(t, err)
+	for _, status := range []string{"pending", "processing", "shipped", "delivered"} {
+		assert.ErrorContains(t, orderService.UpdateOrderStatus(ctx, cancelled.ID, status), "invalid order state")
+	}
+	stored, err := orderRepo.GetByID(cancelled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", stored.Status, "late webhooks cannot revive a cancelled order")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"toko/internal/repositories"
+	"toko/pkg/signedurl"
+)
+
+// OrderStatusLink is a signed, expiring public link to an order's status page.
+type OrderStatusLink struct {
+	OrderID   string    `json:"order_id"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OrderStatusItem is a line item as shown on the public status page.
+type OrderStatusItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// OrderStatusView is what the public status page shows. It deliberately
+// leaves out customer details because anyone holding the link can see it.
+type OrderStatusView struct {
+	OrderID        string            `json:"order_id"`
+	Status         string            `json:"status"`
+	Items          []OrderStatusItem `json:"items"`
+	GrandTotal     float64           `json:"grand_total"`
+	Carrier        string            `json:"carrier,omitempty"`
+	TrackingNumber string            `json:"tracking_number,omitempty"`
+	PlacedAt       time.Time         `json:"placed_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// OrderStatusPageService issues and verifies signed links to the public order status page,
+// so customers can follow an order from an email or chat message without logging in.
+type OrderStatusPageService struct {
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	signer      *signedurl.Signer
+	baseURL     string // Public origin prepended to links, e.g. "https://shop.example.com"
+	basePath    string // Path prefix of the status route, e.g. "/api/v1/order-status"
+	linkTTL     time.Duration
+}
+
+// NewOrderStatusPageService creates a new OrderStatusPageService.
+func NewOrderStatusPageService(orderRepo repositories.OrderRepository, productRepo repositories.ProductRepository, signer *signedurl.Signer, baseURL, basePath string, linkTTL time.Duration) *OrderStatusPageService {
+	return &OrderStatusPageService{
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		signer:      signer,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		basePath:    basePath,
+		linkTTL:     linkTTL,
+	}
+}
+
+// Link returns a fresh signed link to the order's status page.
+func (s *OrderStatusPageService) Link(orderID string) *OrderStatusLink {
+	expiresAt := time.Now().Add(s.linkTTL)
+	return &OrderStatusLink{
+		OrderID:   orderID,
+		URL:       s.baseURL + s.signer.Sign(s.statusPath(orderID), expiresAt),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// GetStatus verifies a signed link and returns the order's public status.
+func (s *OrderStatusPageService) GetStatus(orderID, expires, signature string) (*OrderStatusView, error) {
+	if err := s.signer.Verify(s.statusPath(orderID), expires, signature); err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &OrderStatusView{
+		OrderID:        order.ID,
+		Status:         order.Status,
+		Items:          make([]OrderStatusItem, 0, len(order.Items)),
+		GrandTotal:     order.GrandTotal,
+		Carrier:        order.Carrier,
+		TrackingNumber: order.TrackingNumber,
+		PlacedAt:       order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+	}
+	for _, item := range order.Items {
+		name := item.ProductID
+		if product, err := s.productRepo.GetByID(item.ProductID); err == nil {
+			name = product.Name
+		}
+		view.Items = append(view.Items, OrderStatusItem{Name: name, Quantity: item.Quantity})
+	}
+	return view, nil
+}
+
+func (s *OrderStatusPageService) statusPath(orderID string) string {
+	return fmt.Sprintf("%s/%s", s.basePath, orderID)
+}
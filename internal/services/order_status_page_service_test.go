@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/signedurl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderStatusPageService_SignedLink(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Laptop", Price: 1200, Stock: 3}
+	require.NoError(t, productRepo.Create(product))
+
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "shipped", GrandTotal: 1200, Items: []models.OrderItem{{ProductID: product.ID, Quantity: 1, Price: 1200}}}
+	require.NoError(t, orderRepo.Create(order))
+	require.NoError(t, orderRepo.UpdateTracking(order.ID, "JNE", "JNE123"))
+
+	service := services.NewOrderStatusPageService(orderRepo, productRepo, signedurl.New("test_secret"), "https://shop.example.com/", "/api/v1/order-status", time.Hour)
+
+	link := service.Link(order.ID)
+	assert.True(t, strings.HasPrefix(link.URL, "https://shop.example.com/api/v1/order-status/"+order.ID+"?"))
+
+	parsed, err := url.Parse(link.URL)
+	require.NoError(t, err)
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+
+	view, err := service.GetStatus(order.ID, expires, signature)
+	require.NoError(t, err)
+	assert.Equal(t, "shipped", view.Status)
+	assert.Equal(t, "JNE123", view.TrackingNumber)
+	assert.Equal(t, []services.OrderStatusItem{{Name: "Laptop", Quantity: 1}}, view.Items)
+
+	// A link for one order cannot be reused for another
+	_, err = service.GetStatus("other-order", expires, signature)
+	assert.Error(t, err)
+}
@@ -1,24 +1,79 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
 	"toko/internal/models"
 	"toko/internal/repositories"
+	"toko/pkg/respcache"
+)
+
+// Response cache tags invalidated when the data behind cached routes changes.
+const (
+	CacheTagCatalog    = "catalog"
+	CacheTagCategories = "categories"
 )
 
 // ProductService handles business logic related to products.
 type ProductService struct {
-	repo repositories.ProductRepository
+	repo     repositories.ProductRepository
+	mqClient Publisher // RabbitMQ client for catalog events
+	webhooks *WebhookService
+	cache    *respcache.Cache
+	quotas   *QuotaService
 }
 
 // NewProductService creates a new ProductService.
-func NewProductService(repo repositories.ProductRepository) *ProductService {
+func NewProductService(repo repositories.ProductRepository, mqClient Publisher) *ProductService {
 	return &ProductService{
-		repo: repo,
+		repo:     repo,
+		mqClient: mqClient,
+	}
+}
+
+// SetWebhooks sends product.price_changed webhooks to subscribers.
+func (s *ProductService) SetWebhooks(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// SetResponseCache invalidates cached catalog responses when products change.
+func (s *ProductService) SetResponseCache(cache *respcache.Cache) {
+	s.cache = cache
+}
+
+// SetQuotas keeps the catalog within the plan's product limit.
+func (s *ProductService) SetQuotas(quotas *QuotaService) {
+	s.quotas = quotas
+}
+
+// invalidateCatalog drops cached catalog responses.
+func (s *ProductService) invalidateCatalog() {
+	if s.cache != nil {
+		s.cache.Invalidate(CacheTagCatalog)
 	}
 }
 
-// GetAllProducts retrieves all products.
+// GetAllProducts retrieves all active products.
 func (s *ProductService) GetAllProducts() ([]models.Product, error) {
+	products, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	active := make([]models.Product, 0, len(products))
+	for _, p := range products {
+		if !p.IsArchived() {
+			active = append(active, p)
+		}
+	}
+	return active, nil
+}
+
+// GetAllProductsIncludingArchived retrieves all products, archived ones included.
+func (s *ProductService) GetAllProductsIncludingArchived() ([]models.Product, error) {
 	return s.repo.GetAll()
 }
 
@@ -30,17 +85,163 @@ func (s *ProductService) GetProductByID(id string) (*models.Product, error) {
 // CreateProduct creates a new product.
 func (s *ProductService) CreateProduct(product *models.Product) error {
 	// Add any business logic here, e.g., validation, default values.
-	// For now, we'll just pass it to the repository.
-	return s.repo.Create(product)
+	if s.quotas != nil {
+		if err := s.quotas.CheckProducts(); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Create(product); err != nil {
+		return err
+	}
+	s.invalidateCatalog()
+	return nil
 }
 
-// UpdateProduct updates an existing product.
+// UpdateProduct updates an existing product. Subscribers are notified when its price changes.
 func (s *ProductService) UpdateProduct(product *models.Product) error {
 	// Add any business logic here, e.g., validation.
-	return s.repo.Update(product)
+	var oldPrice float64
+	if s.webhooks != nil {
+		existing, err := s.repo.GetByID(product.ID)
+		if err != nil {
+			return err
+		}
+		oldPrice = existing.Price
+	}
+	if err := s.repo.Update(product); err != nil {
+		return err
+	}
+	s.invalidateCatalog()
+	if s.webhooks != nil && product.Price != oldPrice {
+		s.webhooks.Dispatch(WebhookEventProductPriceChanged, map[string]interface{}{
+			"product_id": product.ID,
+			"sku":        product.SKU,
+			"name":       product.Name,
+			"old_price":  oldPrice,
+			"new_price":  product.Price,
+		})
+	}
+	return nil
 }
 
 // DeleteProduct deletes a product by its ID.
 func (s *ProductService) DeleteProduct(id string) error {
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidateCatalog()
+	return nil
+}
+
+// ArchiveProduct hides a product from the catalog and emits a product.archived event.
+func (s *ProductService) ArchiveProduct(ctx context.Context, id string, reason string) (*models.Product, error) {
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsArchived() {
+		return product, nil
+	}
+
+	now := time.Now()
+	product.Status = models.ProductStatusArchived
+	product.ArchivedAt = &now
+	if err := s.repo.Update(product); err != nil {
+		return nil, fmt.Errorf("failed to archive product %s: %w", id, err)
+	}
+	s.invalidateCatalog()
+
+	publishEvent(ctx, s.mqClient, "product", "product.archived", map[string]interface{}{
+		"productID":  product.ID,
+		"reason":     reason,
+		"archivedAt": now,
+	})
+	return product, nil
+}
+
+// UnarchiveProduct returns an archived product to the catalog and emits a product.unarchived event.
+func (s *ProductService) UnarchiveProduct(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsArchived() {
+		return product, nil
+	}
+
+	product.Status = models.ProductStatusActive
+	product.ArchivedAt = nil
+	if err := s.repo.Update(product); err != nil {
+		return nil, fmt.Errorf("failed to unarchive product %s: %w", id, err)
+	}
+	s.invalidateCatalog()
+
+	publishEvent(ctx, s.mqClient, "product", "product.unarchived", map[string]interface{}{
+		"productID": product.ID,
+	})
+	return product, nil
+}
+
+// ArchiveStaleProducts archives out-of-stock products without sales in the given period.
+// It returns the number of products archived.
+func (s *ProductService) ArchiveStaleProducts(inactiveFor time.Duration) (int, error) {
+	candidates, err := s.repo.ListArchiveCandidates(time.Now().Add(-inactiveFor))
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, candidate := range candidates {
+		if _, err := s.ArchiveProduct(context.Background(), candidate.ID, "stale"); err != nil {
+			log.Printf("Error archiving stale product %s: %v", candidate.ID, err)
+			continue
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+// catalogChangeSettle holds back the newest changes so that transactions
+// still committing with lower cursors are not skipped by consumers.
+const catalogChangeSettle = 5 * time.Second
+
+// maxCatalogChangePage is the most changes returned at once.
+const maxCatalogChangePage = 1000
+
+// CatalogChangePage is a page of the catalog change feed.
+type CatalogChangePage struct {
+	Changes    []models.CatalogChange `json:"changes"`
+	NextCursor string                 `json:"next_cursor"` // Pass as since to get the following changes
+	HasMore    bool                   `json:"has_more"`
+}
+
+// CatalogChanges returns the product creations, updates and deletions after
+// the cursor, oldest first. An empty cursor starts from the beginning.
+func (s *ProductService) CatalogChanges(since string, limit int, now time.Time) (*CatalogChangePage, error) {
+	var cursor uint64
+	if since != "" {
+		parsed, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %q", since)
+		}
+		cursor = parsed
+	}
+	if limit <= 0 || limit > maxCatalogChangePage {
+		limit = 100
+	}
+
+	// One extra change tells whether another page follows
+	changes, err := s.repo.ListChanges(uint(cursor), now.Add(-catalogChangeSettle), limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &CatalogChangePage{Changes: changes, NextCursor: strconv.FormatUint(cursor, 10)}
+	if len(changes) > limit {
+		page.Changes = changes[:limit]
+		page.HasMore = true
+	}
+	if n := len(page.Changes); n > 0 {
+		page.NextCursor = strconv.FormatUint(uint64(page.Changes[n-1].ID), 10)
+	}
+	return page, nil
 }
@@ -3,12 +3,15 @@ package services_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"toko/internal/models"
+	"toko/internal/repositories"
 	"toko/internal/services"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockProductRepository is a mock implementation of repositories.ProductRepository
@@ -21,6 +24,11 @@ func (m *MockProductRepository) GetAll() ([]models.Product, error) {
 	return args.Get(0).([]models.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) Count() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockProductRepository) GetByID(id string) (*models.Product, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -44,9 +52,29 @@ func (m *MockProductRepository) Delete(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockProductRepository) ListArchiveCandidates(cutoff time.Time) ([]models.Product, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateLastSoldAt(id string, soldAt time.Time) error {
+	args := m.Called(id, soldAt)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Search(termGroups [][]string, limit int) ([]models.Product, error) {
+	args := m.Called(termGroups, limit)
+	return args.Get(0).([]models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) ListChanges(cursor uint, until time.Time, limit int) ([]models.CatalogChange, error) {
+	args := m.Called(cursor, until, limit)
+	return args.Get(0).([]models.CatalogChange), args.Error(1)
+}
+
 func TestProductService_GetAllProducts(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	service := services.NewProductService(mockRepo)
+	service := services.NewProductService(mockRepo, nil)
 
 	expectedProducts := []models.Product{
 		{ID: "1", Name: "Product A", Price: 10.0, Stock: 100},
@@ -65,7 +93,7 @@ func TestProductService_GetAllProducts(t *testing.T) {
 
 func TestProductService_GetProductByID(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	service := services.NewProductService(mockRepo)
+	service := services.NewProductService(mockRepo, nil)
 
 	expectedProduct := &models.Product{ID: "1", Name: "Product A", Price: 10.0, Stock: 100}
 
@@ -87,7 +115,7 @@ func TestProductService_GetProductByID(t *testing.T) {
 
 func TestProductService_CreateProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	service := services.NewProductService(mockRepo)
+	service := services.NewProductService(mockRepo, nil)
 
 	newProduct := &models.Product{Name: "New Product", Price: 50.0, Stock: 20}
 
@@ -107,7 +135,7 @@ func TestProductService_CreateProduct(t *testing.T) {
 
 func TestProductService_UpdateProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	service := services.NewProductService(mockRepo)
+	service := services.NewProductService(mockRepo, nil)
 
 	updatedProduct := &models.Product{ID: "1", Name: "Product A Updated", Price: 12.0, Stock: 95}
 
@@ -127,7 +155,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 
 func TestProductService_DeleteProduct(t *testing.T) {
 	mockRepo := new(MockProductRepository)
-	service := services.NewProductService(mockRepo)
+	service := services.NewProductService(mockRepo, nil)
 
 	// Test successful deletion
 	mockRepo.On("Delete", "1").Return(nil).Once()
@@ -142,3 +170,60 @@ func TestProductService_DeleteProduct(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found for deletion")
 	mockRepo.AssertExpectations(t)
 }
+
+func TestProductService_ArchiveStaleProducts(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := services.NewProductService(mockRepo, nil)
+
+	stale := &models.Product{ID: "1", Name: "Old Product", Price: 5.0, Stock: 0}
+	mockRepo.On("ListArchiveCandidates", mock.AnythingOfType("time.Time")).Return([]models.Product{*stale}, nil).Once()
+	mockRepo.On("GetByID", "1").Return(stale, nil).Once()
+	mockRepo.On("Update", mock.MatchedBy(func(p *models.Product) bool {
+		return p.ID == "1" && p.IsArchived() && p.ArchivedAt != nil
+	})).Return(nil).Once()
+
+	archived, err := service.ArchiveStaleProducts(30 * 24 * time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	mockRepo.AssertExpectations(t)
+
+	// Archived products are hidden from the catalog listing
+	mockRepo.On("GetAll").Return([]models.Product{*stale, {ID: "2", Name: "Active", Price: 1.0, Stock: 3}}, nil).Once()
+	products, err := service.GetAllProducts()
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	assert.Equal(t, "2", products[0].ID)
+}
+
+func TestProductService_CatalogChanges(t *testing.T) {
+	service := services.NewProductService(repositories.NewMockProductRepository(), nil)
+	for _, name := range []string{"Mug", "Plate", "Bowl"} {
+		require.NoError(t, service.CreateProduct(&models.Product{Name: name, Price: 5}))
+	}
+	later := time.Now().Add(time.Minute)
+
+	page, err := service.CatalogChanges("", 2, later)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 2)
+	assert.True(t, page.HasMore)
+
+	page, err = service.CatalogChanges(page.NextCursor, 2, later)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 1)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, "3", page.NextCursor)
+
+	// Consumers that are caught up keep their cursor
+	page, err = service.CatalogChanges("3", 2, later)
+	require.NoError(t, err)
+	assert.Empty(t, page.Changes)
+	assert.Equal(t, "3", page.NextCursor)
+
+	// Changes younger than the settle delay are held back
+	page, err = service.CatalogChanges("", 10, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, page.Changes)
+
+	_, err = service.CatalogChanges("abc", 10, later)
+	assert.ErrorContains(t, err, "invalid cursor")
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// PlanLimits caps what the store may create on its plan. Zero means unlimited.
+type PlanLimits struct {
+	Products             int
+	AdminUsers           int
+	WebhookSubscriptions int
+}
+
+// QuotaUsage is how much of one limit is used. A zero Limit means unlimited.
+type QuotaUsage struct {
+	Used  int64 `json:"used"`
+	Limit int   `json:"limit"`
+}
+
+// PlanUsage shows usage against every limit of the plan.
+type PlanUsage struct {
+	Plan                 string     `json:"plan"`
+	Products             QuotaUsage `json:"products"`
+	AdminUsers           QuotaUsage `json:"admin_users"`
+	WebhookSubscriptions QuotaUsage `json:"webhook_subscriptions"`
+}
+
+// QuotaService enforces the limits of the store's plan. The quotas are soft:
+// usage is counted before creating, so concurrent requests may overshoot a
+// limit by a few.
+type QuotaService struct {
+	plan        string
+	limits      PlanLimits
+	productRepo repositories.ProductRepository
+	userRepo    repositories.UserRepository
+	webhookRepo repositories.WebhookRepository
+}
+
+// NewQuotaService creates a new QuotaService for the named plan.
+func NewQuotaService(plan string, limits PlanLimits, productRepo repositories.ProductRepository, userRepo repositories.UserRepository, webhookRepo repositories.WebhookRepository) *QuotaService {
+	return &QuotaService{
+		plan:        plan,
+		limits:      limits,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		webhookRepo: webhookRepo,
+	}
+}
+
+// CheckProducts returns a quota exceeded error when another product would go over the limit.
+func (s *QuotaService) CheckProducts() error {
+	if s.limits.Products <= 0 {
+		return nil
+	}
+	used, err := s.productRepo.Count()
+	if err != nil {
+		return err
+	}
+	return s.check(used, s.limits.Products, "products")
+}
+
+// CheckAdminUsers returns a quota exceeded error when another admin would go over the limit.
+func (s *QuotaService) CheckAdminUsers() error {
+	if s.limits.AdminUsers <= 0 {
+		return nil
+	}
+	used, err := s.userRepo.CountByRole(models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	return s.check(used, s.limits.AdminUsers, "admin users")
+}
+
+// CheckWebhookSubscriptions returns a quota exceeded error when another
+// subscription would go over the limit.
+func (s *QuotaService) CheckWebhookSubscriptions() error {
+	if s.limits.WebhookSubscriptions <= 0 {
+		return nil
+	}
+	used, err := s.countWebhookSubscriptions()
+	if err != nil {
+		return err
+	}
+	return s.check(used, s.limits.WebhookSubscriptions, "webhook subscriptions")
+}
+
+// Usage returns the plan's limits and how much of each is used.
+func (s *QuotaService) Usage() (*PlanUsage, error) {
+	products, err := s.productRepo.Count()
+	if err != nil {
+		return nil, err
+	}
+	admins, err := s.userRepo.CountByRole(models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.countWebhookSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	return &PlanUsage{
+		Plan:                 s.plan,
+		Products:             QuotaUsage{Used: products, Limit: s.limits.Products},
+		AdminUsers:           QuotaUsage{Used: admins, Limit: s.limits.AdminUsers},
+		WebhookSubscriptions: QuotaUsage{Used: subscriptions, Limit: s.limits.WebhookSubscriptions},
+	}, nil
+}
+
+func (s *QuotaService) countWebhookSubscriptions() (int64, error) {
+	subscriptions, err := s.webhookRepo.ListSubscriptions()
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(subscriptions)), nil
+}
+
+func (s *QuotaService) check(used int64, limit int, what string) error {
+	if used >= int64(limit) {
+		return fmt.Errorf("quota exceeded: the %s plan allows at most %d %s", s.plan, limit, what)
+	}
+	return nil
+}
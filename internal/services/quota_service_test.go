@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaService_EnforcesPlanLimits(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	webhookRepo := repositories.NewMockWebhookRepository()
+	userRepo := new(MockUserRepository)
+	userRepo.On("CountByRole", models.RoleAdmin).Return(int64(2), nil)
+
+	quotas := services.NewQuotaService("starter", services.PlanLimits{Products: 2, AdminUsers: 2}, productRepo, userRepo, webhookRepo)
+	productService := services.NewProductService(productRepo, nil)
+	productService.SetQuotas(quotas)
+	webhooks := services.NewWebhookService(webhookRepo, jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{}), httpclient.New(httpclient.Config{Name: "quota-test", Timeout: time.Second}))
+	webhooks.SetQuotas(quotas)
+
+	require.NoError(t, productService.CreateProduct(&models.Product{Name: "Mug", Price: 10}))
+	require.NoError(t, productService.CreateProduct(&models.Product{Name: "Cup", Price: 8}))
+	err := productService.CreateProduct(&models.Product{Name: "Plate", Price: 12})
+	assert.ErrorContains(t, err, "quota exceeded: the starter plan allows at most 2 products")
+
+	assert.ErrorContains(t, quotas.CheckAdminUsers(), "quota exceeded")
+
+	// Webhook subscriptions are unlimited on this plan
+	for i := 0; i < 3; i++ {
+		require.NoError(t, webhooks.CreateSubscription(&models.WebhookSubscription{URL: "https://example.com/hook"}))
+	}
+
+	usage, err := quotas.Usage()
+	require.NoError(t, err)
+	assert.Equal(t, "starter", usage.Plan)
+	assert.Equal(t, services.QuotaUsage{Used: 2, Limit: 2}, usage.Products)
+	assert.Equal(t, services.QuotaUsage{Used: 2, Limit: 2}, usage.AdminUsers)
+	assert.Equal(t, services.QuotaUsage{Used: 3, Limit: 0}, usage.WebhookSubscriptions)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReasonList is a configurable list of structured reason codes, such as
+// "changed_mind", that customers and admins choose from when cancelling or
+// returning an order. Codes are lowercase.
+type ReasonList []string
+
+// NewReasonList normalizes codes into a ReasonList, dropping blanks and duplicates.
+func NewReasonList(codes []string) ReasonList {
+	list := ReasonList{}
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code != "" && !seen[code] {
+			seen[code] = true
+			list = append(list, code)
+		}
+	}
+	return list
+}
+
+// Check normalizes code and returns an error naming kind if it is missing or
+// not in the list. An empty list accepts any code, including none.
+func (l ReasonList) Check(kind, code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(l) == 0 {
+		return code, nil
+	}
+	for _, allowed := range l {
+		if code == allowed {
+			return code, nil
+		}
+	}
+	if code == "" {
+		return "", fmt.Errorf("invalid %s reason: a reason is required (one of %s)", kind, strings.Join(l, ", "))
+	}
+	return "", fmt.Errorf("invalid %s reason: %q is not one of %s", kind, code, strings.Join(l, ", "))
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// ReportService handles business logic for sales reports.
+type ReportService struct {
+	repo repositories.ReportRepository
+}
+
+// NewReportService creates a new ReportService.
+func NewReportService(repo repositories.ReportRepository) *ReportService {
+	return &ReportService{
+		repo: repo,
+	}
+}
+
+// TopProducts returns the best-selling products in [from, to).
+func (s *ReportService) TopProducts(from, to time.Time, limit int) ([]models.ProductSales, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid report period: from must be before to")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	return s.repo.TopProducts(from, to, limit)
+}
+
+// Revenue returns the revenue summary for [from, to).
+func (s *ReportService) Revenue(from, to time.Time) (*models.RevenueSummary, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid report period: from must be before to")
+	}
+	return s.repo.Revenue(from, to)
+}
+
+// unspecifiedReason labels records made before reasons were required.
+const unspecifiedReason = "unspecified"
+
+// CancellationReasons returns the top cancellation and return reasons for [from, to).
+func (s *ReportService) CancellationReasons(from, to time.Time) (*models.CancellationReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid report period: from must be before to")
+	}
+	cancellations, err := s.repo.CancellationReasons(from, to)
+	if err != nil {
+		return nil, err
+	}
+	returns, err := s.repo.ReturnReasons(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CancellationReport{
+		From:          from,
+		To:            to,
+		Cancellations: withShares(cancellations),
+		Returns:       withShares(returns),
+	}, nil
+}
+
+// withShares labels blank reasons and sets each reason's share of the total.
+func withShares(counts []models.ReasonCount) []models.ReasonCount {
+	var total int64
+	for _, count := range counts {
+		total += count.Count
+	}
+	result := make([]models.ReasonCount, 0, len(counts))
+	for _, count := range counts {
+		if count.Reason == "" {
+			count.Reason = unspecifiedReason
+		}
+		count.Value = roundCents(count.Value)
+		if total > 0 {
+			count.Share = float64(count.Count) / float64(total)
+		}
+		result = append(result, count)
+	}
+	return result
+}
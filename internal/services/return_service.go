@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/courier"
+)
+
+// ReturnService handles customer return requests. Approving a return issues a
+// carrier drop-off code through the courier adapter and emails it to the customer.
+type ReturnService struct {
+	repo          repositories.ReturnRepository
+	orderRepo     repositories.OrderRepository
+	labeler       courier.ReturnLabeler
+	notifications *NotificationService // Optional; without it customers only see the code in the API
+	returnWindow  time.Duration        // How long after delivery a return can be requested
+	reasons       ReasonList           // Reasons a customer can give for a return
+}
+
+// NewReturnService creates a new ReturnService.
+func NewReturnService(repo repositories.ReturnRepository, orderRepo repositories.OrderRepository, labeler courier.ReturnLabeler, notifications *NotificationService, returnWindow time.Duration) *ReturnService {
+	return &ReturnService{
+		repo:          repo,
+		orderRepo:     orderRepo,
+		labeler:       labeler,
+		notifications: notifications,
+		returnWindow:  returnWindow,
+	}
+}
+
+// SetReasons requires return requests to give one of the reasons.
+func (s *ReturnService) SetReasons(reasons ReasonList) {
+	s.reasons = reasons
+}
+
+// Reasons returns the reasons a return request can give.
+func (s *ReturnService) Reasons() ReasonList {
+	return s.reasons
+}
+
+// RequestReturn opens a return for one of the customer's delivered orders.
+// The reason code is one of the configured return reasons; details are the
+// customer's own words.
+func (s *ReturnService) RequestReturn(orderID, userID, reasonCode, details string) (*models.ReturnRequest, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.UserID != userID {
+		return nil, fmt.Errorf("order with ID %s not found", orderID)
+	}
+	reasonCode, err = s.reasons.Check("return", reasonCode)
+	if err != nil {
+		return nil, err
+	}
+	details = strings.TrimSpace(details)
+	if reasonCode == "" && details == "" {
+		return nil, fmt.Errorf("invalid return reason: a reason is required")
+	}
+	if order.Status != "delivered" {
+		return nil, fmt.Errorf("order %s cannot be returned until it is delivered", orderID)
+	}
+	// The last update of a delivered order is its delivery
+	if s.returnWindow > 0 && time.Since(order.UpdatedAt) > s.returnWindow {
+		return nil, fmt.Errorf("return window for order %s has closed", orderID)
+	}
+
+	existing, err := s.repo.ListByOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range existing {
+		if request.Status != models.ReturnStatusRejected {
+			return nil, fmt.Errorf("a return for order %s is already %s", orderID, request.Status)
+		}
+	}
+
+	request := &models.ReturnRequest{
+		OrderID:    orderID,
+		UserID:     userID,
+		ReasonCode: reasonCode,
+		Reason:     details,
+		Status:     models.ReturnStatusRequested,
+	}
+	if err := s.repo.Create(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListReturns returns requests newest first. An empty userID lists every customer's requests.
+func (s *ReturnService) ListReturns(userID, status string) ([]models.ReturnRequest, error) {
+	return s.repo.List(userID, status)
+}
+
+// GetReturn returns a request if it belongs to userID or the caller is an admin.
+func (s *ReturnService) GetReturn(id, userID string, isAdmin bool) (*models.ReturnRequest, error) {
+	request, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && request.UserID != userID {
+		return nil, fmt.Errorf("return request with ID %s not found", id)
+	}
+	return request, nil
+}
+
+// Approve accepts a return, issues the carrier drop-off code and queues the
+// email that delivers it to the customer.
+func (s *ReturnService) Approve(ctx context.Context, id, note string) (*models.ReturnRequest, error) {
+	request, err := s.pendingRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderRepo.GetByID(request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := s.labeler.CreateReturnLabel(ctx, courier.ReturnLabelRequest{
+		Reference: request.ID,
+		Sender:    courierAddress(order.ShippingAddress),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create return label: %w", err)
+	}
+
+	now := time.Now()
+	request.Status = models.ReturnStatusApproved
+	request.ResolutionNote = note
+	request.Carrier = label.Carrier
+	request.DropOffCode = label.DropOffCode
+	request.LabelURL = label.LabelURL
+	request.LabelExpiresAt = &label.ExpiresAt
+	request.ResolvedAt = &now
+	if err := s.repo.Resolve(request); err != nil {
+		return nil, err
+	}
+
+	if s.notifications != nil {
+		if err := s.notifications.QueueReturnLabel(request); err != nil {
+			// The code is saved on the request, so the customer can still see it
+			log.Printf("Warning: %v", err)
+		}
+	}
+	return request, nil
+}
+
+// Reject declines a return with a note for the customer.
+func (s *ReturnService) Reject(id, note string) (*models.ReturnRequest, error) {
+	request, err := s.pendingRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	request.Status = models.ReturnStatusRejected
+	request.ResolutionNote = note
+	request.ResolvedAt = &now
+	if err := s.repo.Resolve(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (s *ReturnService) pendingRequest(id string) (*models.ReturnRequest, error) {
+	request, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.ReturnStatusRequested {
+		return nil, fmt.Errorf("return request %s has already been resolved", id)
+	}
+	return request, nil
+}
@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/courier"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReturnService_ApprovalEmailsDropOffCode(t *testing.T) {
+	orderRepo := repositories.NewMockOrderRepository()
+	order := &models.Order{UserID: "user-1", Status: "delivered", ShippingAddress: testPostalAddress("Jakarta")}
+	require.NoError(t, orderRepo.Create(order))
+	pending := &models.Order{UserID: "user-1", Status: "shipped"}
+	require.NoError(t, orderRepo.Create(pending))
+
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByID", "user-1").Return(&models.User{ID: "user-1", Username: "budi", Email: "budi@example.com"}, nil)
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	m := &flakyMailer{}
+	notifications := services.NewNotificationService(orderRepo, repositories.NewMockProductRepository(), userRepo, nil, nil, m, queue, "Toko")
+	service := services.NewReturnService(repositories.NewMockReturnRepository(), orderRepo, courier.NewDropOffLabeler("JNE", 24*time.Hour), notifications, 30*24*time.Hour)
+
+	service.SetReasons(services.NewReasonList([]string{"does_not_fit", "Damaged"}))
+
+	// Only the owner of a delivered order can request a return, once
+	_, err := service.RequestReturn(order.ID, "user-2", "does_not_fit", "Wrong size")
+	assert.Error(t, err)
+	_, err = service.RequestReturn(pending.ID, "user-1", "does_not_fit", "Changed my mind")
+	assert.Error(t, err)
+	_, err = service.RequestReturn(order.ID, "user-1", "", "Wrong size")
+	assert.ErrorContains(t, err, "invalid return reason")
+	_, err = service.RequestReturn(order.ID, "user-1", "too_expensive", "")
+	assert.ErrorContains(t, err, "invalid return reason")
+	request, err := service.RequestReturn(order.ID, "user-1", "Does_Not_Fit", "Wrong size")
+	require.NoError(t, err)
+	assert.Equal(t, "does_not_fit", request.ReasonCode)
+	_, err = service.RequestReturn(order.ID, "user-1", "damaged", "")
+	assert.Error(t, err)
+
+	approved, err := service.Approve(context.Background(), request.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.ReturnStatusApproved, approved.Status)
+	assert.NotEmpty(t, approved.DropOffCode)
+	_, err = service.Reject(request.ID, "Too late")
+	assert.Error(t, err)
+
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, []string{"budi@example.com"}, m.sent[0].To)
+	assert.Contains(t, m.sent[0].Text, approved.DropOffCode)
+	assert.Contains(t, m.sent[0].HTML, approved.DropOffCode)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// ScrapingConfig sets the catalog scraping detection thresholds. A client
+// viewing more than ThrottleAfter distinct products within Window is slowed
+// down progressively, up to MaxDelay per request; beyond BlockAfter it is
+// rejected for BlockFor.
+type ScrapingConfig struct {
+	Window        time.Duration
+	ThrottleAfter int // 0 disables detection
+	BlockAfter    int
+	MaxDelay      time.Duration
+	BlockFor      time.Duration
+}
+
+// ScrapingVerdict tells the caller how to treat a catalog request.
+type ScrapingVerdict struct {
+	Delay      time.Duration // Wait this long before serving the request
+	Blocked    bool
+	RetryAfter time.Duration // Set when Blocked
+}
+
+// clientActivity is the product access history of one client within the window.
+type clientActivity struct {
+	seen         map[string]time.Time // Product ID to last access
+	throttledAt  time.Time            // Last time a throttled event was recorded
+	blockedUntil time.Time
+	lastSeen     time.Time
+}
+
+// ScrapingService detects clients walking the catalog product by product.
+// Activity is tracked in memory per instance; crossings of a threshold are
+// logged and recorded for review.
+type ScrapingService struct {
+	repo repositories.ScrapingEventRepository
+	cfg  ScrapingConfig
+
+	mu        sync.Mutex
+	clients   map[string]*clientActivity
+	lastSweep time.Time
+}
+
+// NewScrapingService creates a new ScrapingService.
+func NewScrapingService(repo repositories.ScrapingEventRepository, cfg ScrapingConfig) *ScrapingService {
+	if cfg.BlockAfter <= cfg.ThrottleAfter {
+		cfg.BlockAfter = cfg.ThrottleAfter * 2
+	}
+	return &ScrapingService{
+		repo:    repo,
+		cfg:     cfg,
+		clients: make(map[string]*clientActivity),
+	}
+}
+
+// Enabled reports whether scraping detection is on.
+func (s *ScrapingService) Enabled() bool {
+	return s.cfg.ThrottleAfter > 0 && s.cfg.Window > 0
+}
+
+// Check records that the client requested productID (empty for listings,
+// which are not counted) and returns how the request should be treated.
+// clientKey identifies the client, e.g. "user:<id>" or "ip:<address>".
+func (s *ScrapingService) Check(clientKey, ip, userID, productID string, now time.Time) ScrapingVerdict {
+	if !s.Enabled() {
+		return ScrapingVerdict{}
+	}
+
+	s.mu.Lock()
+	s.sweep(now)
+	client, ok := s.clients[clientKey]
+	if !ok {
+		client = &clientActivity{seen: make(map[string]time.Time)}
+		s.clients[clientKey] = client
+	}
+	client.lastSeen = now
+
+	if now.Before(client.blockedUntil) {
+		retryAfter := client.blockedUntil.Sub(now)
+		s.mu.Unlock()
+		return ScrapingVerdict{Blocked: true, RetryAfter: retryAfter}
+	}
+
+	cutoff := now.Add(-s.cfg.Window)
+	for id, at := range client.seen {
+		if at.Before(cutoff) {
+			delete(client.seen, id)
+		}
+	}
+	if productID != "" {
+		client.seen[productID] = now
+	}
+	distinct := len(client.seen)
+
+	var verdict ScrapingVerdict
+	var action string
+	switch {
+	case distinct > s.cfg.BlockAfter:
+		client.blockedUntil = now.Add(s.cfg.BlockFor)
+		client.seen = make(map[string]time.Time) // Start over once the block ends
+		verdict = ScrapingVerdict{Blocked: true, RetryAfter: s.cfg.BlockFor}
+		action = models.ScrapingActionBlocked
+	case distinct > s.cfg.ThrottleAfter:
+		// The delay grows linearly from nothing at the throttle threshold to MaxDelay at the block threshold
+		excess := float64(distinct-s.cfg.ThrottleAfter) / float64(s.cfg.BlockAfter-s.cfg.ThrottleAfter)
+		verdict = ScrapingVerdict{Delay: time.Duration(excess * float64(s.cfg.MaxDelay))}
+		if now.Sub(client.throttledAt) >= s.cfg.Window {
+			client.throttledAt = now
+			action = models.ScrapingActionThrottled
+		}
+	}
+	s.mu.Unlock()
+
+	if action != "" {
+		s.record(&models.ScrapingEvent{
+			ClientKey:        clientKey,
+			IP:               ip,
+			UserID:           userID,
+			DistinctProducts: distinct,
+			Action:           action,
+			CreatedAt:        now,
+		})
+	}
+	return verdict
+}
+
+// ListEvents returns the most recent detection events.
+func (s *ScrapingService) ListEvents(limit int) ([]models.ScrapingEvent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.List(limit)
+}
+
+func (s *ScrapingService) record(event *models.ScrapingEvent) {
+	log.Printf("Scraping detection: %s client %s (ip %s) after viewing %d distinct products within %s",
+		event.Action, event.ClientKey, event.IP, event.DistinctProducts, s.cfg.Window)
+	if err := s.repo.Create(event); err != nil {
+		log.Printf("Warning: Failed to record scraping event for %s: %v", event.ClientKey, err)
+	}
+}
+
+// sweep forgets clients idle for a whole window so memory stays bounded.
+// Callers must hold s.mu.
+func (s *ScrapingService) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.cfg.Window {
+		return
+	}
+	s.lastSweep = now
+	cutoff := now.Add(-s.cfg.Window)
+	for key, client := range s.clients {
+		if client.lastSeen.Before(cutoff) && now.After(client.blockedUntil) {
+			delete(s.clients, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapingService_ThrottlesThenBlocks(t *testing.T) {
+	repo := repositories.NewMockScrapingEventRepository()
+	service := services.NewScrapingService(repo, services.ScrapingConfig{
+		Window:        time.Minute,
+		ThrottleAfter: 10,
+		BlockAfter:    20,
+		MaxDelay:      time.Second,
+		BlockFor:      5 * time.Minute,
+	})
+	now := time.Now()
+	view := func(i int) services.ScrapingVerdict {
+		return service.Check("ip:10.0.0.1", "10.0.0.1", "", fmt.Sprintf("product-%d", i), now)
+	}
+
+	// Revisiting the same products and browsing listings is never throttled
+	for i := 0; i < 50; i++ {
+		assert.Zero(t, view(i%10))
+		assert.Zero(t, service.Check("ip:10.0.0.1", "10.0.0.1", "", "", now))
+	}
+
+	verdict := view(10)
+	assert.False(t, verdict.Blocked)
+	assert.Equal(t, 100*time.Millisecond, verdict.Delay)
+	for i := 11; i < 20; i++ {
+		verdict = view(i)
+	}
+	assert.Equal(t, time.Second, verdict.Delay)
+
+	verdict = view(20)
+	assert.True(t, verdict.Blocked)
+	assert.Equal(t, 5*time.Minute, verdict.RetryAfter)
+
+	// Other clients are unaffected, and the block lifts after BlockFor
+	assert.Zero(t, service.Check("ip:10.0.0.2", "10.0.0.2", "", "product-1", now))
+	assert.True(t, view(1).Blocked)
+	now = now.Add(6 * time.Minute)
+	assert.Zero(t, view(1))
+
+	events, err := service.ListEvents(10)
+	require.NoError(t, err)
+	require.Len(t, events, 2) // Throttling is recorded once per window
+	assert.Equal(t, models.ScrapingActionBlocked, events[0].Action)
+	assert.Equal(t, 21, events[0].DistinctProducts)
+	assert.Equal(t, models.ScrapingActionThrottled, events[1].Action)
+}
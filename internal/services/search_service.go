@@ -0,0 +1,388 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// maxSearchQueryLength bounds stored queries so analytics cannot be flooded with huge strings.
+const maxSearchQueryLength = 255
+
+// SearchConfig tunes query expansion.
+type SearchConfig struct {
+	Fuzzy           bool          // Correct misspelled words against the catalog vocabulary
+	FuzzyMinLength  int           // Shorter words are never corrected
+	FuzzyMaxEdits   int           // Edits allowed for words of 8 or more letters; shorter ones allow one
+	RefreshInterval time.Duration // How often the vocabulary and synonyms are reloaded
+}
+
+// SearchResult is one page of catalog search results.
+type SearchResult struct {
+	SearchID   string           `json:"search_id,omitempty"` // Pass back when reporting a click on a result
+	Query      string           `json:"query"`
+	DidYouMean string           `json:"did_you_mean,omitempty"` // The query with misspelled words corrected
+	Products   []models.Product `json:"products"`
+}
+
+// SearchService searches the catalog and records queries, result counts and
+// click-throughs to show what customers look for and do not find. Query words
+// are expanded with admin-managed synonyms and, when enabled, with the
+// catalog words they are likely misspellings of.
+type SearchService struct {
+	productRepo   repositories.ProductRepository
+	analyticsRepo repositories.SearchAnalyticsRepository
+	synonymRepo   repositories.SearchSynonymRepository
+	cfg           SearchConfig
+
+	mu          sync.RWMutex
+	vocabulary  map[string]struct{}
+	synonyms    map[string][]string // Term to all terms of its synonym sets
+	lastRefresh time.Time
+}
+
+// NewSearchService creates a new SearchService.
+func NewSearchService(productRepo repositories.ProductRepository, analyticsRepo repositories.SearchAnalyticsRepository, synonymRepo repositories.SearchSynonymRepository, cfg SearchConfig) *SearchService {
+	return &SearchService{
+		productRepo:   productRepo,
+		analyticsRepo: analyticsRepo,
+		synonymRepo:   synonymRepo,
+		cfg:           cfg,
+	}
+}
+
+// NormalizeSearchQuery trims, lowercases and collapses whitespace so that
+// equivalent queries are aggregated together.
+func NormalizeSearchQuery(query string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if len(normalized) > maxSearchQueryLength {
+		normalized = normalized[:maxSearchQueryLength]
+	}
+	return normalized
+}
+
+// Search returns active products matching every word of the query, or one
+// of its synonyms or corrections. The search is recorded for analytics;
+// failing to record it does not fail the search.
+func (s *SearchService) Search(query, userID string, limit int) (*SearchResult, error) {
+	normalized := NormalizeSearchQuery(query)
+	if normalized == "" {
+		return nil, fmt.Errorf("invalid search: query is required")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	termGroups, corrected := s.expand(strings.Fields(normalized))
+	products, err := s.productRepo.Search(termGroups, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &SearchResult{Query: normalized, Products: products}
+	if corrected != normalized {
+		result.DidYouMean = corrected
+	}
+	if result.Products == nil {
+		result.Products = []models.Product{}
+	}
+
+	record := &models.SearchQuery{
+		Query:       normalized,
+		UserID:      userID,
+		ResultCount: len(products),
+		CreatedAt:   time.Now(),
+	}
+	if err := s.analyticsRepo.CreateQuery(record); err != nil {
+		log.Printf("Warning: Failed to record search %q: %v", normalized, err)
+	} else {
+		result.SearchID = record.ID
+	}
+	return result, nil
+}
+
+// RecordClick records that a product was opened from a search's results.
+func (s *SearchService) RecordClick(searchID, productID string, position int) error {
+	if productID == "" || position < 1 {
+		return fmt.Errorf("invalid click: product_id and a position of at least 1 are required")
+	}
+	if _, err := s.analyticsRepo.GetQuery(searchID); err != nil {
+		return err
+	}
+	return s.analyticsRepo.CreateClick(&models.SearchClick{
+		SearchID:  searchID,
+		ProductID: productID,
+		Position:  position,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Report returns the top and zero-result queries in [from, to).
+func (s *SearchService) Report(from, to time.Time, limit int) (*models.SearchReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid report period: from must be before to")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	top, err := s.analyticsRepo.QueryStats(from, to, false, limit)
+	if err != nil {
+		return nil, err
+	}
+	zero, err := s.analyticsRepo.QueryStats(from, to, true, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SearchReport{From: from, To: to, TopQueries: top, ZeroResultQueries: zero}, nil
+}
+
+// expand turns each query word into its alternatives: the word itself, its
+// closest catalog words when it looks misspelled, and the synonyms of all of
+// them. It also returns the query with misspelled words corrected.
+func (s *SearchService) expand(words []string) ([][]string, string) {
+	s.refreshIfStale()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make([][]string, 0, len(words))
+	corrected := make([]string, 0, len(words))
+	for _, word := range words {
+		alternatives := []string{word}
+		corrections := s.corrections(word)
+		alternatives = append(alternatives, corrections...)
+		if len(corrections) > 0 {
+			corrected = append(corrected, corrections[0])
+		} else {
+			corrected = append(corrected, word)
+		}
+
+		seen := make(map[string]bool)
+		var group []string
+		for _, alternative := range alternatives {
+			for _, term := range append([]string{alternative}, s.synonyms[alternative]...) {
+				if !seen[term] {
+					seen[term] = true
+					group = append(group, term)
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, strings.Join(corrected, " ")
+}
+
+// corrections returns the catalog words closest to word when word itself is
+// not in the catalog, best first. Callers must hold s.mu.
+func (s *SearchService) corrections(word string) []string {
+	if !s.cfg.Fuzzy || len(word) < s.cfg.FuzzyMinLength || len(s.vocabulary) == 0 {
+		return nil
+	}
+	if _, ok := s.vocabulary[word]; ok {
+		return nil
+	}
+	if _, ok := s.synonyms[word]; ok {
+		return nil
+	}
+
+	maxEdits := 1
+	if len(word) >= 8 && s.cfg.FuzzyMaxEdits > 1 {
+		maxEdits = s.cfg.FuzzyMaxEdits
+	}
+	best := maxEdits + 1
+	var matches []string
+	for candidate := range s.vocabulary {
+		if abs(len(candidate)-len(word)) > maxEdits {
+			continue
+		}
+		distance := editDistance(word, candidate)
+		switch {
+		case distance < best:
+			best = distance
+			matches = []string{candidate}
+		case distance == best:
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	if len(matches) > 3 {
+		matches = matches[:3]
+	}
+	return matches
+}
+
+// refreshIfStale reloads the vocabulary and synonyms once RefreshInterval has passed.
+func (s *SearchService) refreshIfStale() {
+	s.mu.RLock()
+	stale := time.Since(s.lastRefresh) > s.cfg.RefreshInterval
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := s.Refresh(); err != nil {
+		// Keep searching with what was loaded last
+		log.Printf("Warning: Failed to refresh search vocabulary: %v", err)
+	}
+}
+
+// Refresh reloads the catalog vocabulary and the synonym dictionary.
+func (s *SearchService) Refresh() error {
+	var vocabulary map[string]struct{}
+	if s.cfg.Fuzzy {
+		products, err := s.productRepo.GetAll()
+		if err != nil {
+			s.markRefreshed() // Back off until the next interval
+			return err
+		}
+		vocabulary = make(map[string]struct{})
+		for _, product := range products {
+			if product.IsArchived() {
+				continue
+			}
+			for _, word := range searchWords(product.Name + " " + product.SKU + " " + product.Description) {
+				vocabulary[word] = struct{}{}
+			}
+		}
+	}
+
+	entries, err := s.synonymRepo.List()
+	if err != nil {
+		s.markRefreshed()
+		return err
+	}
+	synonyms := make(map[string][]string)
+	for _, entry := range entries {
+		terms := entry.TermList()
+		for _, term := range terms {
+			synonyms[term] = append(synonyms[term], terms...)
+		}
+	}
+
+	s.mu.Lock()
+	s.vocabulary = vocabulary
+	s.synonyms = synonyms
+	s.lastRefresh = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *SearchService) markRefreshed() {
+	s.mu.Lock()
+	s.lastRefresh = time.Now()
+	s.mu.Unlock()
+}
+
+// ListSynonyms returns the synonym dictionary.
+func (s *SearchService) ListSynonyms() ([]models.SearchSynonym, error) {
+	return s.synonymRepo.List()
+}
+
+// CreateSynonym adds a set of equivalent terms.
+func (s *SearchService) CreateSynonym(terms string) (*models.SearchSynonym, error) {
+	synonym := &models.SearchSynonym{}
+	var err error
+	if synonym.Terms, err = normalizeSynonymTerms(terms); err != nil {
+		return nil, err
+	}
+	if err := s.synonymRepo.Create(synonym); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return synonym, nil
+}
+
+// UpdateSynonym replaces the terms of a synonym set.
+func (s *SearchService) UpdateSynonym(id uint, terms string) (*models.SearchSynonym, error) {
+	synonym, err := s.synonymRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if synonym.Terms, err = normalizeSynonymTerms(terms); err != nil {
+		return nil, err
+	}
+	if err := s.synonymRepo.Update(synonym); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return synonym, nil
+}
+
+// DeleteSynonym removes a synonym set.
+func (s *SearchService) DeleteSynonym(id uint) error {
+	if err := s.synonymRepo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// invalidate makes the next search reload the dictionary.
+func (s *SearchService) invalidate() {
+	s.mu.Lock()
+	s.lastRefresh = time.Time{}
+	s.mu.Unlock()
+}
+
+// normalizeSynonymTerms lowercases and deduplicates comma-separated terms.
+func normalizeSynonymTerms(terms string) (string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, term := range strings.Split(terms, ",") {
+		term = NormalizeSearchQuery(term)
+		if term != "" && !seen[term] {
+			seen[term] = true
+			normalized = append(normalized, term)
+		}
+	}
+	if len(normalized) < 2 {
+		return "", fmt.Errorf("invalid synonym: at least two different terms are required")
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// searchWords splits text into lowercase words of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: insertions, deletions, substitutions and swaps of adjacent letters each
+// count as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
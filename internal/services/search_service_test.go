@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_RecordsQueriesAndClicks(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	keyboard := &models.Product{Name: "Mechanical Keyboard", Price: 90, Stock: 5}
+	require.NoError(t, productRepo.Create(keyboard))
+	require.NoError(t, productRepo.Create(&models.Product{Name: "Wireless Mouse", Price: 25, Stock: 5}))
+	require.NoError(t, productRepo.Create(&models.Product{Name: "Old Keyboard", Price: 10, Status: models.ProductStatusArchived}))
+
+	service := services.NewSearchService(productRepo, repositories.NewMockSearchAnalyticsRepository(), repositories.NewMockSearchSynonymRepository(), services.SearchConfig{})
+
+	_, err := service.Search("   ", "user-1", 20)
+	assert.Error(t, err)
+
+	result, err := service.Search("  Keyboard  MECHANICAL ", "user-1", 20)
+	require.NoError(t, err)
+	assert.Equal(t, "keyboard mechanical", result.Query)
+	require.Len(t, result.Products, 1) // Archived products are never found
+	assert.Equal(t, keyboard.ID, result.Products[0].ID)
+	require.NoError(t, service.RecordClick(result.SearchID, keyboard.ID, 1))
+
+	_, err = service.Search("keyboard mechanical", "user-2", 20)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = service.Search("keybord", "user-2", 20)
+		require.NoError(t, err)
+	}
+
+	assert.Error(t, service.RecordClick("missing", keyboard.ID, 1))
+	assert.Error(t, service.RecordClick(result.SearchID, keyboard.ID, 0))
+
+	report, err := service.Report(time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, report.TopQueries, 2)
+	assert.Equal(t, models.SearchQueryStats{Query: "keybord", Searches: 3}, report.TopQueries[0])
+	assert.Equal(t, models.SearchQueryStats{Query: "keyboard mechanical", Searches: 2, AvgResults: 1, Clicks: 1, ClickThroughRate: 0.5}, report.TopQueries[1])
+	require.Len(t, report.ZeroResultQueries, 1)
+	assert.Equal(t, "keybord", report.ZeroResultQueries[0].Query)
+}
+
+func TestSearchService_ExpandsSynonymsAndCorrectsTypos(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	keyboard := &models.Product{Name: "Mechanical Keyboard", Price: 90, Stock: 5}
+	phone := &models.Product{Name: "Android Handphone", Price: 200, Stock: 5}
+	require.NoError(t, productRepo.Create(keyboard))
+	require.NoError(t, productRepo.Create(phone))
+
+	service := services.NewSearchService(productRepo, repositories.NewMockSearchAnalyticsRepository(), repositories.NewMockSearchSynonymRepository(), services.SearchConfig{
+		Fuzzy:          true,
+		FuzzyMinLength: 4,
+		FuzzyMaxEdits:  2,
+	})
+
+	result, err := service.Search("keybord", "", 20)
+	require.NoError(t, err)
+	assert.Equal(t, "keyboard", result.DidYouMean)
+	require.Len(t, result.Products, 1)
+	assert.Equal(t, keyboard.ID, result.Products[0].ID)
+
+	result, err = service.Search("mechanicla keyboard", "", 20)
+	require.NoError(t, err)
+	assert.Equal(t, "mechanical keyboard", result.DidYouMean) // Swapped letters count as one edit
+	require.Len(t, result.Products, 1)
+
+	// Short words are never corrected
+	result, err = service.Search("kex", "", 20)
+	require.NoError(t, err)
+	assert.Empty(t, result.DidYouMean)
+	assert.Empty(t, result.Products)
+
+	_, err = service.CreateSynonym("hp, HP ,")
+	assert.Error(t, err)
+	synonym, err := service.CreateSynonym("hp, Handphone")
+	require.NoError(t, err)
+	assert.Equal(t, "hp,handphone", synonym.Terms)
+
+	result, err = service.Search("hp", "", 20)
+	require.NoError(t, err)
+	assert.Empty(t, result.DidYouMean)
+	require.Len(t, result.Products, 1)
+	assert.Equal(t, phone.ID, result.Products[0].ID)
+
+	require.NoError(t, service.DeleteSynonym(synonym.ID))
+	result, err = service.Search("hp", "", 20)
+	require.NoError(t, err)
+	assert.Empty(t, result.Products)
+	assert.Error(t, service.DeleteSynonym(synonym.ID))
+}
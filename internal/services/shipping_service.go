@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/courier"
+)
+
+// ShippingQuoteRequest describes the cart and destination to quote shipping for.
+type ShippingQuoteRequest struct {
+	Items             []models.OrderItem
+	Destination       models.PostalAddress
+	DestinationAreaID string // Courier city or area ID, needed by providers such as RajaOngkir
+}
+
+// ShippingQuote lists the shipping options for a cart, cheapest first.
+type ShippingQuote struct {
+	RequiresShipping bool           `json:"requires_shipping"`
+	WeightGrams      int            `json:"weight_grams"`
+	Subtotal         float64        `json:"subtotal"`
+	Surcharge        float64        `json:"surcharge"` // Shipping class surcharges included in every rate
+	Zone             string         `json:"zone,omitempty"`
+	ZoneFee          float64        `json:"zone_fee,omitempty"` // Delivery zone fee included in every rate
+	Rates            []courier.Rate `json:"rates"`
+}
+
+// ShippingService calculates shipping costs with the configured rate providers.
+type ShippingService struct {
+	productRepo repositories.ProductRepository
+	classes     *ClassService
+	providers   []courier.RateProvider
+	origin      courier.Address
+	zones       *DeliveryZoneService
+}
+
+// NewShippingService creates a new ShippingService that ships parcels from
+// origin. Products' shipping classes are resolved through classes; nil
+// disables class surcharges.
+func NewShippingService(productRepo repositories.ProductRepository, classes *ClassService, providers []courier.RateProvider, origin courier.Address) *ShippingService {
+	return &ShippingService{
+		productRepo: productRepo,
+		classes:     classes,
+		providers:   providers,
+		origin:      origin,
+	}
+}
+
+// SetZones restricts delivery to the store's delivery zones and adds their fees.
+func (s *ShippingService) SetZones(zones *DeliveryZoneService) {
+	s.zones = zones
+}
+
+// Quote returns the rates of every provider for the items, plus the
+// surcharges of their shipping classes. Providers that fail are skipped so
+// one unavailable courier does not block checkout; an error is only returned
+// when none of them could quote.
+func (s *ShippingService) Quote(ctx context.Context, req ShippingQuoteRequest) (*ShippingQuote, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("invalid quote: no items")
+	}
+
+	quote := &ShippingQuote{Rates: []courier.Rate{}}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity %d for product %s", item.Quantity, item.ProductID)
+		}
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if product.IsArchived() {
+			return nil, fmt.Errorf("product %s is no longer available", item.ProductID)
+		}
+		if product.IsDigital() {
+			continue // Downloads are not shipped
+		}
+		quote.RequiresShipping = true
+		quote.WeightGrams += product.WeightGrams * item.Quantity
+		quote.Subtotal += product.Price * float64(item.Quantity)
+		if s.classes != nil {
+			class, err := s.classes.ResolveShippingClass(product)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve shipping class of product %s: %w", product.ID, err)
+			}
+			quote.Surcharge += class.Surcharge * float64(item.Quantity)
+		}
+	}
+	if !quote.RequiresShipping {
+		return quote, nil
+	}
+
+	destination := courierAddress(req.Destination)
+	destination.AreaID = req.DestinationAreaID
+	if strings.TrimSpace(destination.City) == "" && strings.TrimSpace(destination.PostalCode) == "" && destination.AreaID == "" {
+		return nil, fmt.Errorf("invalid quote: a destination city, postal code or area is required")
+	}
+	if s.zones != nil {
+		zone, err := s.zones.Evaluate(req.Destination, quote.Subtotal)
+		if err != nil {
+			return nil, err
+		}
+		if zone != nil {
+			quote.Zone, quote.ZoneFee = zone.Name, zone.Fee
+		}
+	}
+
+	rateReq := courier.RateRequest{
+		Origin:      s.origin,
+		Destination: destination,
+		WeightGrams: quote.WeightGrams,
+		Subtotal:    quote.Subtotal,
+	}
+	var lastErr error
+	for _, provider := range s.providers {
+		rates, err := provider.Rates(ctx, rateReq)
+		if err != nil {
+			log.Printf("Warning: Shipping provider %s could not quote: %v", provider.Name(), err)
+			lastErr = err
+			continue
+		}
+		for _, rate := range rates {
+			rate.Amount += quote.Surcharge + quote.ZoneFee
+			quote.Rates = append(quote.Rates, rate)
+		}
+	}
+	if len(quote.Rates) == 0 {
+		if lastErr != nil {
+			return nil, fmt.Errorf("no shipping rates available: %w", lastErr)
+		}
+		return nil, fmt.Errorf("no shipping rates available")
+	}
+
+	sort.SliceStable(quote.Rates, func(i, j int) bool {
+		return quote.Rates[i].Amount < quote.Rates[j].Amount
+	})
+	return quote, nil
+}
+
+// courierAddress converts a postal address to the courier package's address.
+func courierAddress(address models.PostalAddress) courier.Address {
+	return courier.Address{
+		Name:       address.RecipientName,
+		Phone:      address.Phone,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
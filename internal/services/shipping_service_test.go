@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/courier"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateProvider struct{}
+
+func (failingRateProvider) Name() string { return "down" }
+
+func (failingRateProvider) Rates(ctx context.Context, req courier.RateRequest) ([]courier.Rate, error) {
+	return nil, fmt.Errorf("courier unavailable")
+}
+
+func TestShippingService_QuotesCheapestFirst(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	shirt := &models.Product{Name: "Shirt", Price: 20, Stock: 10, WeightGrams: 300}
+	ebook := &models.Product{Name: "E-book", Price: 5, Type: models.ProductTypeDigital}
+	require.NoError(t, productRepo.Create(shirt))
+	require.NoError(t, productRepo.Create(ebook))
+
+	service := services.NewShippingService(productRepo, nil, []courier.RateProvider{
+		&courier.WeightBased{Base: 5, PerKg: 2, Currency: "USD"},
+		failingRateProvider{},
+		&courier.FlatRate{Amount: 6, Currency: "USD"},
+	}, courier.Address{City: "Jakarta"})
+
+	quote, err := service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items:       []models.OrderItem{{ProductID: shirt.ID, Quantity: 4}, {ProductID: ebook.ID, Quantity: 1}},
+		Destination: testPostalAddress("Bandung"),
+	})
+	require.NoError(t, err)
+	assert.True(t, quote.RequiresShipping)
+	assert.Equal(t, 1200, quote.WeightGrams)
+	assert.Equal(t, 80.0, quote.Subtotal)
+	require.Len(t, quote.Rates, 2)
+	assert.Equal(t, "flat", quote.Rates[0].Provider)
+	assert.Equal(t, 9.0, quote.Rates[1].Amount) // Two started kilograms
+
+	// Carts of downloads need no shipping
+	quote, err = service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items: []models.OrderItem{{ProductID: ebook.ID, Quantity: 1}},
+	})
+	require.NoError(t, err)
+	assert.False(t, quote.RequiresShipping)
+	assert.Empty(t, quote.Rates)
+
+	_, err = service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items: []models.OrderItem{{ProductID: shirt.ID, Quantity: 1}},
+	})
+	assert.ErrorContains(t, err, "destination")
+}
+
+func TestShippingService_FailsWhenNoProviderQuotes(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	shirt := &models.Product{Name: "Shirt", Price: 20, Stock: 10}
+	require.NoError(t, productRepo.Create(shirt))
+	service := services.NewShippingService(productRepo, nil, []courier.RateProvider{failingRateProvider{}}, courier.Address{})
+
+	_, err := service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items:       []models.OrderItem{{ProductID: shirt.ID, Quantity: 1}},
+		Destination: testPostalAddress("Bandung"),
+	})
+	assert.ErrorContains(t, err, "no shipping rates available")
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a key access tokens are signed and validated with. Its ID
+// is sent as the kid header of the tokens it signs; keys without an ID sign
+// tokens without one.
+type SigningKey struct {
+	ID        string            `json:"kid"`
+	Method    jwt.SigningMethod `json:"-"`
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey returns an HS256 key using the secret.
+func NewHMACKey(id, secret string) SigningKey {
+	return SigningKey{ID: id, Method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+}
+
+// NewRSAKey returns an RS256 key using the private key.
+func NewRSAKey(id string, key *rsa.PrivateKey) SigningKey {
+	return SigningKey{ID: id, Method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}
+}
+
+// SigningKeyInfo describes a key of the keyset without its key material.
+type SigningKeyInfo struct {
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Signing   bool   `json:"signing"` // New tokens are signed with this key
+}
+
+// keySet holds the keys in use, oldest first. New tokens are signed with the
+// newest key; tokens signed with any of them stay valid, so adding a key
+// rotates signing without logging anybody out.
+type keySet struct {
+	mu   sync.RWMutex
+	keys []SigningKey
+	load func() ([]SigningKey, error)
+}
+
+func (k *keySet) set(keys []SigningKey) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("invalid signing keys: at least one key is required")
+	}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key.ID] {
+			return fmt.Errorf("invalid signing keys: duplicate kid %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	return nil
+}
+
+// signing returns the newest key.
+func (k *keySet) signing() SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[len(k.keys)-1]
+}
+
+// verifying returns the key a token was signed with: the one with its kid,
+// or the oldest key for tokens without one, which predate key rotation.
+func (k *keySet) verifying(token *jwt.Token) (interface{}, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	kid, _ := token.Header["kid"].(string)
+	key := k.keys[0]
+	if kid != "" {
+		found := false
+		for _, candidate := range k.keys {
+			if candidate.ID == kid {
+				key, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+	// Checking the alg per key keeps an RSA public key from being used as an HMAC secret
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+func (k *keySet) info() []SigningKeyInfo {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	infos := make([]SigningKeyInfo, len(k.keys))
+	for i, key := range k.keys {
+		infos[i] = SigningKeyInfo{ID: key.ID, Algorithm: key.Method.Alg(), Signing: i == len(k.keys)-1}
+	}
+	return infos
+}
+
+// SetSigningKeys replaces the keys tokens are signed and validated with,
+// oldest first; new tokens are signed with the last one.
+func (s *AuthService) SetSigningKeys(keys []SigningKey) error {
+	return s.keys.set(keys)
+}
+
+// SetSigningKeySource loads the signing keys from load, now and on every
+// ReloadSigningKeys.
+func (s *AuthService) SetSigningKeySource(load func() ([]SigningKey, error)) error {
+	s.keys.load = load
+	_, err := s.ReloadSigningKeys()
+	return err
+}
+
+// ReloadSigningKeys loads the signing keys from their source again, so keys
+// can be added and retired without a restart. The keys in use are kept when
+// loading fails.
+func (s *AuthService) ReloadSigningKeys() ([]SigningKeyInfo, error) {
+	if s.keys.load == nil {
+		return nil, fmt.Errorf("invalid signing keys: keys are not loaded from a source")
+	}
+	keys, err := s.keys.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	if err := s.keys.set(keys); err != nil {
+		return nil, err
+	}
+	return s.keys.info(), nil
+}
+
+// SigningKeys describes the signing keys in use.
+func (s *AuthService) SigningKeys() []SigningKeyInfo {
+	return s.keys.info()
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// Fulfillment stages whose durations are tracked against SLAs.
+const (
+	SLAStagePayment     = "payment"     // Placed to paid
+	SLAStageFulfillment = "fulfillment" // Paid to shipped
+	SLAStageDelivery    = "delivery"    // Shipped to delivered
+	SLAStageTotal       = "total"       // Placed to delivered
+)
+
+// SLAThresholds are the longest each stage should take; zero means the stage has no SLA.
+type SLAThresholds struct {
+	Payment     time.Duration
+	Fulfillment time.Duration
+	Delivery    time.Duration
+	Total       time.Duration
+}
+
+// slaStage tells when an order started and completed a stage.
+type slaStage struct {
+	name      string
+	start     func(m *models.OrderMilestones) *time.Time
+	end       func(m *models.OrderMilestones) *time.Time
+	threshold time.Duration
+}
+
+// SLAService measures how long orders take from checkout to the customer's
+// door. Reports are computed on demand; the Prometheus metrics are refreshed
+// periodically over a rolling window and served from memory.
+type SLAService struct {
+	reportRepo repositories.ReportRepository
+	stages     []slaStage
+	window     time.Duration
+
+	mu          sync.Mutex
+	metrics     *models.SLAReport
+	breaches    map[string]int64 // Since the process started, for the Prometheus counter
+	lastRefresh time.Time
+}
+
+// NewSLAService creates a new SLAService whose metrics cover the orders
+// placed within window.
+func NewSLAService(reportRepo repositories.ReportRepository, thresholds SLAThresholds, window time.Duration) *SLAService {
+	placed := func(m *models.OrderMilestones) *time.Time { return &m.CreatedAt }
+	paid := func(m *models.OrderMilestones) *time.Time { return m.PaidAt }
+	shipped := func(m *models.OrderMilestones) *time.Time { return m.ShippedAt }
+	delivered := func(m *models.OrderMilestones) *time.Time { return m.DeliveredAt }
+	return &SLAService{
+		reportRepo: reportRepo,
+		stages: []slaStage{
+			{name: SLAStagePayment, start: placed, end: paid, threshold: thresholds.Payment},
+			{name: SLAStageFulfillment, start: paid, end: shipped, threshold: thresholds.Fulfillment},
+			{name: SLAStageDelivery, start: shipped, end: delivered, threshold: thresholds.Delivery},
+			{name: SLAStageTotal, start: placed, end: delivered, threshold: thresholds.Total},
+		},
+		window:   window,
+		breaches: make(map[string]int64),
+	}
+}
+
+// Report returns the stage durations of the orders placed in [from, to),
+// and how many open orders are breaching each SLA right now.
+func (s *SLAService) Report(from, to time.Time) (*models.SLAReport, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid report period: from must be before to")
+	}
+	report, _, err := s.report(from, to, time.Now())
+	return report, err
+}
+
+func (s *SLAService) report(from, to, now time.Time) (*models.SLAReport, []models.OrderMilestones, error) {
+	placed, err := s.reportRepo.OrderMilestones(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	open, err := s.reportRepo.OpenOrderMilestones()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &models.SLAReport{From: from, To: to, Stages: make([]models.StageSLA, 0, len(s.stages))}
+	for _, stage := range s.stages {
+		var durations []float64
+		summary := models.StageSLA{Stage: stage.name, Threshold: stage.threshold.Seconds()}
+		for i := range placed {
+			start, end := stage.start(&placed[i]), stage.end(&placed[i])
+			if start == nil || end == nil {
+				continue
+			}
+			duration := end.Sub(*start)
+			durations = append(durations, duration.Seconds())
+			if stage.threshold > 0 && duration > stage.threshold {
+				summary.Breaches++
+			}
+		}
+		if stage.threshold > 0 {
+			for i := range open {
+				start, end := stage.start(&open[i]), stage.end(&open[i])
+				if start != nil && end == nil && now.Sub(*start) > stage.threshold {
+					summary.OpenBreaches++
+				}
+			}
+		}
+
+		sort.Float64s(durations)
+		summary.Count = len(durations)
+		if len(durations) > 0 {
+			var sum float64
+			for _, d := range durations {
+				sum += d
+			}
+			summary.Mean = sum / float64(len(durations))
+			summary.P50 = percentile(durations, 0.50)
+			summary.P90 = percentile(durations, 0.90)
+			summary.P95 = percentile(durations, 0.95)
+			summary.P99 = percentile(durations, 0.99)
+		}
+		report.Stages = append(report.Stages, summary)
+	}
+	return report, placed, nil
+}
+
+// Refresh recomputes the metrics over the window ending at now, and adds
+// the orders that completed a stage late since the previous refresh to the
+// breach counters.
+func (s *SLAService) Refresh(now time.Time) error {
+	report, placed, err := s.report(now.Add(-s.window), now, now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.lastRefresh.IsZero() {
+		for _, stage := range s.stages {
+			if stage.threshold <= 0 {
+				continue
+			}
+			for i := range placed {
+				start, end := stage.start(&placed[i]), stage.end(&placed[i])
+				if start == nil || end == nil || !end.After(s.lastRefresh) || end.After(now) {
+					continue
+				}
+				if end.Sub(*start) > stage.threshold {
+					s.breaches[stage.name]++
+				}
+			}
+		}
+	}
+	s.metrics = report
+	s.lastRefresh = now
+	return nil
+}
+
+// WriteMetrics writes the metrics of the last refresh in the Prometheus text
+// exposition format. Nothing is written before the first refresh.
+func (s *SLAService) WriteMetrics(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.metrics == nil {
+		return nil
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "# HELP toko_order_stage_duration_seconds Time the orders placed in the last %s took to complete each fulfillment stage.\n", s.window)
+	fmt.Fprintln(out, "# TYPE toko_order_stage_duration_seconds summary")
+	for _, stage := range s.metrics.Stages {
+		for _, q := range []struct {
+			quantile string
+			value    float64
+		}{{"0.5", stage.P50}, {"0.9", stage.P90}, {"0.95", stage.P95}, {"0.99", stage.P99}} {
+			fmt.Fprintf(out, "toko_order_stage_duration_seconds{stage=%q,quantile=%q} %g\n", stage.Stage, q.quantile, q.value)
+		}
+		fmt.Fprintf(out, "toko_order_stage_duration_seconds_sum{stage=%q} %g\n", stage.Stage, stage.Mean*float64(stage.Count))
+		fmt.Fprintf(out, "toko_order_stage_duration_seconds_count{stage=%q} %d\n", stage.Stage, stage.Count)
+	}
+
+	fmt.Fprintln(out, "# HELP toko_order_sla_threshold_seconds Configured SLA of each fulfillment stage.")
+	fmt.Fprintln(out, "# TYPE toko_order_sla_threshold_seconds gauge")
+	for _, stage := range s.metrics.Stages {
+		if stage.Threshold > 0 {
+			fmt.Fprintf(out, "toko_order_sla_threshold_seconds{stage=%q} %g\n", stage.Stage, stage.Threshold)
+		}
+	}
+	fmt.Fprintln(out, "# HELP toko_order_sla_breaches_total Orders that completed a fulfillment stage later than its SLA.")
+	fmt.Fprintln(out, "# TYPE toko_order_sla_breaches_total counter")
+	for _, stage := range s.metrics.Stages {
+		if stage.Threshold > 0 {
+			fmt.Fprintf(out, "toko_order_sla_breaches_total{stage=%q} %d\n", stage.Stage, s.breaches[stage.Stage])
+		}
+	}
+	fmt.Fprintln(out, "# HELP toko_order_sla_open_breaches Open orders that have been in a fulfillment stage longer than its SLA.")
+	fmt.Fprintln(out, "# TYPE toko_order_sla_open_breaches gauge")
+	for _, stage := range s.metrics.Stages {
+		if stage.Threshold > 0 {
+			fmt.Fprintf(out, "toko_order_sla_open_breaches{stage=%q} %d\n", stage.Stage, stage.OpenBreaches)
+		}
+	}
+	return out.Flush()
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// milestoneReportRepository returns fixed order milestones.
+type milestoneReportRepository struct {
+	stubReportRepository
+	placed []models.OrderMilestones
+	open   []models.OrderMilestones
+}
+
+func (r *milestoneReportRepository) OrderMilestones(from, to time.Time) ([]models.OrderMilestones, error) {
+	return r.placed, nil
+}
+
+func (r *milestoneReportRepository) OpenOrderMilestones() ([]models.OrderMilestones, error) {
+	return r.open, nil
+}
+
+func milestones(created time.Time, delays ...time.Duration) models.OrderMilestones {
+	m := models.OrderMilestones{CreatedAt: created}
+	fields := []**time.Time{&m.PaidAt, &m.ShippedAt, &m.DeliveredAt}
+	at := created
+	for i, delay := range delays {
+		at = at.Add(delay)
+		reached := at
+		*fields[i] = &reached
+	}
+	return m
+}
+
+func TestSLAService_Report(t *testing.T) {
+	now := time.Now()
+	day := now.Add(-48 * time.Hour)
+	repo := &milestoneReportRepository{
+		placed: []models.OrderMilestones{
+			milestones(day, time.Hour, 10*time.Hour, 24*time.Hour),
+			milestones(day, 2*time.Hour, 20*time.Hour),
+			milestones(day, 3*time.Hour, 30*time.Hour),
+			milestones(day, 4*time.Hour),
+		},
+		open: []models.OrderMilestones{
+			milestones(now.Add(-30*time.Hour), time.Hour),
+			milestones(now.Add(-2 * time.Hour)),
+		},
+	}
+	service := services.NewSLAService(repo, services.SLAThresholds{Fulfillment: 24 * time.Hour}, 7*24*time.Hour)
+
+	report, err := service.Report(now.Add(-72*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, report.Stages, 4)
+
+	payment := report.Stages[0]
+	assert.Equal(t, services.SLAStagePayment, payment.Stage)
+	assert.Equal(t, 4, payment.Count)
+	assert.Equal(t, (2 * time.Hour).Seconds(), payment.P50)
+	assert.Equal(t, (4 * time.Hour).Seconds(), payment.P99)
+	assert.Zero(t, payment.Breaches, "stages without an SLA have no breaches")
+
+	fulfillment := report.Stages[1]
+	assert.Equal(t, 3, fulfillment.Count)
+	assert.Equal(t, (20 * time.Hour).Seconds(), fulfillment.P50)
+	assert.Equal(t, 1, fulfillment.Breaches)
+	assert.Equal(t, 1, fulfillment.OpenBreaches, "paid 29 hours ago and not shipped")
+
+	assert.Equal(t, 1, report.Stages[2].Count)
+	assert.Equal(t, 1, report.Stages[3].Count)
+
+	_, err = service.Report(now, now)
+	assert.ErrorContains(t, err, "invalid report period")
+}
+
+func TestSLAService_Metrics(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	repo := &milestoneReportRepository{}
+	service := services.NewSLAService(repo, services.SLAThresholds{Fulfillment: 24 * time.Hour}, 7*24*time.Hour)
+
+	var out strings.Builder
+	require.NoError(t, service.WriteMetrics(&out))
+	assert.Empty(t, out.String(), "nothing is exposed before the first refresh")
+
+	require.NoError(t, service.Refresh(start))
+	// An order shipped late after the first refresh counts as a breach
+	repo.placed = []models.OrderMilestones{milestones(start.Add(-30*time.Hour), time.Hour, 29*time.Hour+30*time.Minute)}
+	require.NoError(t, service.Refresh(start.Add(time.Hour)))
+
+	require.NoError(t, service.WriteMetrics(&out))
+	metrics := out.String()
+	assert.Contains(t, metrics, "# TYPE toko_order_stage_duration_seconds summary")
+	assert.Contains(t, metrics, `toko_order_stage_duration_seconds_count{stage="fulfillment"} 1`)
+	assert.Contains(t, metrics, `toko_order_sla_breaches_total{stage="fulfillment"} 1`)
+	assert.Contains(t, metrics, `toko_order_sla_threshold_seconds{stage="fulfillment"} 86400`)
+	assert.NotContains(t, metrics, `toko_order_sla_breaches_total{stage="payment"}`)
+
+	// The same order is not counted again
+	require.NoError(t, service.Refresh(start.Add(2*time.Hour)))
+	out.Reset()
+	require.NoError(t, service.WriteMetrics(&out))
+	assert.Contains(t, out.String(), `toko_order_sla_breaches_total{stage="fulfillment"} 1`)
+}
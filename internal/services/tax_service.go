@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// TaxLine is the tax owed for the items of one tax class.
+type TaxLine struct {
+	TaxClass string  `json:"tax_class"`
+	Rate     float64 `json:"rate"`
+	Taxable  float64 `json:"taxable"`
+	Tax      float64 `json:"tax"`
+}
+
+// TaxBreakdown is the tax owed on a set of items, per tax class.
+type TaxBreakdown struct {
+	Lines []TaxLine `json:"lines"`
+	Total float64   `json:"total"`
+}
+
+// TaxService calculates taxes with the rate of each product's tax class.
+// Prices are net, so tax is added on top of them.
+type TaxService struct {
+	productRepo repositories.ProductRepository
+	classes     *ClassService
+}
+
+// NewTaxService creates a new TaxService.
+func NewTaxService(productRepo repositories.ProductRepository, classes *ClassService) *TaxService {
+	return &TaxService{
+		productRepo: productRepo,
+		classes:     classes,
+	}
+}
+
+// Calculate returns the tax on the items. Items priced at zero are taxed at
+// the product's current price, so carts can be quoted before ordering.
+func (s *TaxService) Calculate(items []models.OrderItem) (*TaxBreakdown, error) {
+	lines := make(map[string]*TaxLine)
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity %d for product %s", item.Quantity, item.ProductID)
+		}
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		class, err := s.classes.ResolveTaxClass(product)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tax class of product %s: %w", product.ID, err)
+		}
+		price := item.Price
+		if price == 0 {
+			price = product.Price
+		}
+
+		line, ok := lines[class.Code]
+		if !ok {
+			line = &TaxLine{TaxClass: class.Code, Rate: class.Rate}
+			lines[class.Code] = line
+		}
+		line.Taxable += price * float64(item.Quantity)
+	}
+
+	breakdown := &TaxBreakdown{Lines: []TaxLine{}}
+	for _, line := range lines {
+		line.Tax = roundCents(line.Taxable * line.Rate)
+		breakdown.Lines = append(breakdown.Lines, *line)
+		breakdown.Total += line.Tax
+	}
+	sort.Slice(breakdown.Lines, func(i, j int) bool {
+		return breakdown.Lines[i].TaxClass < breakdown.Lines[j].TaxClass
+	})
+	breakdown.Total = roundCents(breakdown.Total)
+	return breakdown, nil
+}
+
+// roundCents rounds an amount to two decimal places.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
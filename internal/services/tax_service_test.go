@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/courier"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxService_ResolvesRatesByClass(t *testing.T) {
+	classRepo := repositories.NewMockClassRepository()
+	classes := services.NewClassService(repositories.NewMockCategoryRepository(), classRepo, "standard", "standard")
+	require.NoError(t, classes.EnsureDefaults(0.11))
+	require.NoError(t, classes.SaveTaxClass("Food", &models.TaxClass{Name: "Groceries", Rate: 0}))
+	require.NoError(t, classes.SaveTaxClass("luxury", &models.TaxClass{Name: "Luxury goods", Rate: 0.2}))
+
+	// Categories must reference existing classes
+	assert.Error(t, classes.CreateCategory(&models.Category{Name: "Toys", TaxClass: "missing"}))
+	groceries := &models.Category{Name: "Groceries", TaxClass: "food"}
+	require.NoError(t, classes.CreateCategory(groceries))
+
+	productRepo := repositories.NewMockProductRepository()
+	rice := &models.Product{Name: "Rice", Price: 10, Stock: 100, CategoryID: groceries.ID}
+	caviar := &models.Product{Name: "Caviar", Price: 100, Stock: 5, CategoryID: groceries.ID, TaxClass: "luxury"}
+	mug := &models.Product{Name: "Mug", Price: 15, Stock: 20}
+	for _, product := range []*models.Product{rice, caviar, mug} {
+		require.NoError(t, productRepo.Create(product))
+	}
+
+	service := services.NewTaxService(productRepo, classes)
+	breakdown, err := service.Calculate([]models.OrderItem{
+		{ProductID: rice.ID, Quantity: 3},
+		{ProductID: caviar.ID, Quantity: 1},
+		{ProductID: mug.ID, Quantity: 2, Price: 12.5}, // Price at the time of order wins
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []services.TaxLine{
+		{TaxClass: "food", Rate: 0, Taxable: 30, Tax: 0},
+		{TaxClass: "luxury", Rate: 0.2, Taxable: 100, Tax: 20},
+		{TaxClass: "standard", Rate: 0.11, Taxable: 25, Tax: 2.75},
+	}, breakdown.Lines)
+	assert.Equal(t, 22.75, breakdown.Total)
+
+	assert.Error(t, classes.DeleteTaxClass("standard"))
+}
+
+func TestShippingService_AddsClassSurcharges(t *testing.T) {
+	classes := services.NewClassService(repositories.NewMockCategoryRepository(), repositories.NewMockClassRepository(), "standard", "standard")
+	require.NoError(t, classes.EnsureDefaults(0))
+	require.NoError(t, classes.SaveShippingClass("bulky", &models.ShippingClass{Name: "Bulky", Surcharge: 25}))
+	furniture := &models.Category{Name: "Furniture", ShippingClass: "bulky"}
+	require.NoError(t, classes.CreateCategory(furniture))
+
+	productRepo := repositories.NewMockProductRepository()
+	chair := &models.Product{Name: "Chair", Price: 80, Stock: 10, CategoryID: furniture.ID}
+	pen := &models.Product{Name: "Pen", Price: 2, Stock: 100}
+	require.NoError(t, productRepo.Create(chair))
+	require.NoError(t, productRepo.Create(pen))
+
+	service := services.NewShippingService(productRepo, classes, []courier.RateProvider{&courier.FlatRate{Amount: 10}}, courier.Address{})
+	quote, err := service.Quote(context.Background(), services.ShippingQuoteRequest{
+		Items:       []models.OrderItem{{ProductID: chair.ID, Quantity: 2}, {ProductID: pen.ID, Quantity: 5}},
+		Destination: testPostalAddress("Bandung"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, quote.Surcharge)
+	require.Len(t, quote.Rates, 1)
+	assert.Equal(t, 60.0, quote.Rates[0].Amount)
+}
+
+func TestOrderService_CreateOrderTotalsBreakdown(t *testing.T) {
+	classes := services.NewClassService(repositories.NewMockCategoryRepository(), repositories.NewMockClassRepository(), "standard", "standard")
+	require.NoError(t, classes.EnsureDefaults(0.1))
+
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 10, WeightGrams: 500}
+	require.NoError(t, productRepo.Create(lamp))
+
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetTaxes(services.NewTaxService(productRepo, classes))
+	orderService.SetShipping(services.NewShippingService(productRepo, classes, []courier.RateProvider{
+		&courier.FlatRate{Amount: 9, Service: "standard"},
+		&courier.FlatRate{Amount: 5, Service: "economy"},
+	}, courier.Address{City: "Jakarta"}))
+	coupons := services.NewCouponService(repositories.NewMockCouponRepository())
+	require.NoError(t, coupons.CreateCoupon(&models.Coupon{Code: "TENOFF", Type: models.CouponTypeFixed, Value: 10, Active: true}))
+	orderService.SetCoupons(coupons)
+
+	request := models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: lamp.ID, Quantity: 2}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+		CouponCode:      "TENOFF",
+	}
+	order, err := orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, order.Subtotal)
+	assert.Equal(t, 10.0, order.DiscountTotal)
+	assert.Equal(t, 7.0, order.TaxTotal) // Tax on the discounted items
+	assert.Equal(t, 5.0, order.ShippingTotal)
+	assert.Equal(t, "flat/economy", order.ShippingMethod)
+	assert.Equal(t, 82.0, order.GrandTotal)
+
+	request.CouponCode = ""
+	request.ShippingMethod = "flat/standard"
+	order, err = orderService.CreateOrder(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, order.ShippingTotal)
+	assert.Equal(t, 97.0, order.GrandTotal)
+
+	request.ShippingMethod = "flat/overnight"
+	_, err = orderService.CreateOrder(context.Background(), request)
+	assert.ErrorContains(t, err, "invalid shipping method")
+}
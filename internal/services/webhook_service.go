@@ -0,0 +1,305 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/httpclient"
+	"toko/pkg/webhooksig"
+)
+
+// webhookDeliverTaskType is the task queue type used to send outbound webhooks.
+const webhookDeliverTaskType = "webhook.deliver"
+
+// maxStoredResponseBody caps how much of a subscriber's response is kept for the dashboard.
+const maxStoredResponseBody = 2048
+
+// Headers added to outbound webhook requests besides the signature headers.
+const (
+	WebhookEventHeader    = "X-Webhook-Event"
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+)
+
+// Outbound webhook events.
+const (
+	WebhookEventProductPriceChanged = "product.price_changed"
+	WebhookEventProductLowStock     = "product.low_stock"
+	WebhookEventOrderCreated        = "order.created"
+	WebhookEventOrderUpdated        = "order.updated"
+	WebhookEventOrderCancelled      = "order.cancelled"
+)
+
+// WebhookEventType describes an event subscribers can receive.
+type WebhookEventType struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// webhookEventCatalog lists the events sent to subscribers.
+var webhookEventCatalog = []WebhookEventType{
+	{Name: WebhookEventProductPriceChanged, Description: "A product's price was changed"},
+	{Name: WebhookEventProductLowStock, Description: "A product fell to its reorder point"},
+	{Name: WebhookEventOrderCreated, Description: "An order was placed"},
+	{Name: WebhookEventOrderUpdated, Description: "An order's status, items or tracking changed"},
+	{Name: WebhookEventOrderCancelled, Description: "An order was cancelled"},
+}
+
+// webhookDeliverTask is the payload of a delivery task.
+type webhookDeliverTask struct {
+	DeliveryID string `json:"delivery_id"`
+	Manual     bool   `json:"manual,omitempty"`
+}
+
+// webhookEnvelope is the JSON body sent to subscribers.
+type webhookEnvelope struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService manages outbound webhook subscriptions and delivers events
+// to them through the task queue, which retries failed deliveries with backoff.
+type WebhookService struct {
+	repo   repositories.WebhookRepository
+	queue  *jobs.Queue
+	client *httpclient.Client
+	quotas *QuotaService
+}
+
+// NewWebhookService creates a new WebhookService and registers its task handler on queue.
+func NewWebhookService(repo repositories.WebhookRepository, queue *jobs.Queue, client *httpclient.Client) *WebhookService {
+	s := &WebhookService{
+		repo:   repo,
+		queue:  queue,
+		client: client,
+	}
+	jobs.Handle(queue, webhookDeliverTaskType, s.runDelivery)
+	return s
+}
+
+// SetQuotas limits the number of subscriptions to the plan's allowance.
+func (s *WebhookService) SetQuotas(quotas *QuotaService) {
+	s.quotas = quotas
+}
+
+// CreateSubscription registers an endpoint. A signing secret is generated when none is given.
+func (s *WebhookService) CreateSubscription(subscription *models.WebhookSubscription) error {
+	if s.quotas != nil {
+		if err := s.quotas.CheckWebhookSubscriptions(); err != nil {
+			return err
+		}
+	}
+	if subscription.Secret == "" {
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		subscription.Secret = "whsec_" + hex.EncodeToString(secret)
+	}
+	if subscription.Events == "" {
+		subscription.Events = "*"
+	}
+	subscription.Active = true
+	return s.repo.CreateSubscription(subscription)
+}
+
+// EventCatalog returns the events subscribers can receive.
+func (s *WebhookService) EventCatalog() []WebhookEventType {
+	return webhookEventCatalog
+}
+
+// ListSubscriptions returns all subscriptions.
+func (s *WebhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions()
+}
+
+// UpdateSubscription changes a subscription's endpoint, events or active flag.
+func (s *WebhookService) UpdateSubscription(id string, url, events *string, active *bool) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	if url != nil {
+		subscription.URL = *url
+	}
+	if events != nil {
+		subscription.Events = *events
+	}
+	if active != nil {
+		subscription.Active = *active
+	}
+	if err := s.repo.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// DeleteSubscription removes a subscription.
+func (s *WebhookService) DeleteSubscription(id string) error {
+	return s.repo.DeleteSubscription(id)
+}
+
+// Dispatch queues the event for every active subscription that wants it.
+// Failures are logged so that webhook delivery never blocks the business
+// operation that triggered it.
+func (s *WebhookService) Dispatch(event string, data interface{}) {
+	subscriptions, err := s.repo.ListSubscriptions()
+	if err != nil {
+		log.Printf("Error loading webhook subscriptions for %s: %v", event, err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.Active || !subscription.Subscribes(event) {
+			continue
+		}
+		if _, err := s.queueDelivery(&subscription, event, data); err != nil {
+			log.Printf("Error queueing %s webhook for subscription %s: %v", event, subscription.ID, err)
+		}
+	}
+}
+
+func (s *WebhookService) queueDelivery(subscription *models.WebhookSubscription, event string, data interface{}) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		Event:          event,
+		Status:         models.WebhookDeliveryPending,
+	}
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	// The envelope is stored as sent so that redeliveries are byte-for-byte identical
+	body, err := json.Marshal(webhookEnvelope{ID: delivery.ID, Event: event, CreatedAt: delivery.CreatedAt, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s webhook: %w", event, err)
+	}
+	delivery.Payload = string(body)
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	if _, err := s.queue.Enqueue(webhookDeliverTaskType, webhookDeliverTask{DeliveryID: delivery.ID}); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ListDeliveries returns a page of deliveries for the dashboard.
+func (s *WebhookService) ListDeliveries(opts repositories.WebhookDeliveryListOptions) ([]models.WebhookDelivery, int64, error) {
+	return s.repo.ListDeliveries(opts)
+}
+
+// GetDelivery returns a delivery with its payload and attempts.
+func (s *WebhookService) GetDelivery(id string) (*models.WebhookDelivery, error) {
+	return s.repo.GetDelivery(id)
+}
+
+// Redeliver sends a delivery again, e.g. after the subscriber fixed their
+// endpoint. The same payload and delivery ID are used so subscribers can
+// deduplicate.
+func (s *WebhookService) Redeliver(id string) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetSubscription(delivery.SubscriptionID); err != nil {
+		return nil, fmt.Errorf("cannot redeliver %s: %w", id, err)
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	if _, err := s.queue.Enqueue(webhookDeliverTaskType, webhookDeliverTask{DeliveryID: id, Manual: true}); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// runDelivery sends one attempt of a delivery. Returning an error makes the
+// task queue retry it; the delivery is marked failed on the last attempt.
+func (s *WebhookService) runDelivery(ctx context.Context, task *models.Task, payload webhookDeliverTask) error {
+	delivery, err := s.repo.GetDelivery(payload.DeliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == models.WebhookDeliverySucceeded && !payload.Manual {
+		return nil
+	}
+	subscription, err := s.repo.GetSubscription(delivery.SubscriptionID)
+	if err != nil {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		return s.repo.UpdateDelivery(delivery)
+	}
+
+	attempt := s.send(ctx, subscription, delivery)
+	attempt.Manual = payload.Manual
+	if err := s.repo.AddAttempt(attempt); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	delivery.AttemptCount++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	var sendErr error
+	if attempt.Error == "" {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	} else {
+		sendErr = fmt.Errorf("webhook delivery %s failed: %s", delivery.ID, attempt.Error)
+		if task.Attempts >= task.MaxAttempts {
+			delivery.Status = models.WebhookDeliveryFailed
+		}
+	}
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// send makes the signed HTTP request and records the outcome.
+func (s *WebhookService) send(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) *models.WebhookAttempt {
+	attempt := &models.WebhookAttempt{DeliveryID: delivery.ID}
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(webhooksig.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooksig.SignatureHeader, webhooksig.Sign(subscription.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxStoredResponseBody))
+	attempt.StatusCode = resp.StatusCode
+	attempt.ResponseBody = string(responseBody)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return attempt
+}
@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"toko/internal/jobs"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+	"toko/pkg/httpclient"
+	"toko/pkg/webhooksig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestWebhookService_RetryThenManualRedelivery(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.WebhookAttempt{}))
+
+	var healthy atomic.Bool
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhooksig.TimestampHeader), 10, 64)
+		assert.Equal(t, webhooksig.Sign("test_secret_0123456789", ts, body), r.Header.Get(webhooksig.SignatureHeader))
+		assert.Equal(t, "order.created", r.Header.Get(services.WebhookEventHeader))
+		bodies = append(bodies, string(body))
+		if !healthy.Load() {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{MaxAttempts: 2})
+	service := services.NewWebhookService(
+		repositories.NewGORMWebhookRepository(db),
+		queue,
+		httpclient.New(httpclient.Config{Name: "webhooks-test", Timeout: time.Second}),
+	)
+
+	require.NoError(t, service.CreateSubscription(&models.WebhookSubscription{URL: server.URL, Events: "order.created", Secret: "test_secret_0123456789"}))
+	require.NoError(t, service.CreateSubscription(&models.WebhookSubscription{URL: server.URL, Events: "product.archived"}))
+
+	service.Dispatch("order.created", map[string]string{"order_id": "order-1"})
+
+	// Both automatic attempts fail, so the delivery ends up failed
+	for i := 0; i < 2; i++ {
+		found, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, found)
+	}
+	found, err := queue.RunNext(context.Background())
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	deliveries, total, err := service.ListDeliveries(repositories.WebhookDeliveryListOptions{Status: models.WebhookDeliveryFailed})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	delivery, err := service.GetDelivery(deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivery.AttemptCount)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+	require.Len(t, delivery.Attempts, 2)
+	assert.Contains(t, delivery.Attempts[0].ResponseBody, "maintenance")
+
+	// The subscriber recovers and an admin redelivers the same payload
+	healthy.Store(true)
+	_, err = service.Redeliver(delivery.ID)
+	require.NoError(t, err)
+	found, err = queue.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, found)
+
+	delivery, err = service.GetDelivery(delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.NotNil(t, delivery.DeliveredAt)
+	require.Len(t, delivery.Attempts, 3)
+	assert.True(t, delivery.Attempts[2].Manual)
+	require.Len(t, bodies, 3)
+	assert.Equal(t, bodies[0], bodies[2])
+	assert.Equal(t, delivery.Payload, bodies[2])
+}
+
+func TestProductService_PriceChangeDispatchesWebhook(t *testing.T) {
+	var events []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		events = append(events, r.Header.Get(services.WebhookEventHeader))
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	webhooks := services.NewWebhookService(
+		repositories.NewMockWebhookRepository(),
+		queue,
+		httpclient.New(httpclient.Config{Name: "webhooks-test", Timeout: time.Second}),
+	)
+	require.NoError(t, webhooks.CreateSubscription(&models.WebhookSubscription{URL: server.URL, Events: services.WebhookEventProductPriceChanged}))
+
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Kopi", SKU: "KOPI-1", Price: 50, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	service := services.NewProductService(productRepo, nil)
+	service.SetWebhooks(webhooks)
+
+	// Changes that keep the price do not notify subscribers
+	require.NoError(t, service.UpdateProduct(&models.Product{ID: product.ID, Name: "Kopi Arabika", SKU: "KOPI-1", Price: 50, Stock: 8}))
+	require.NoError(t, service.UpdateProduct(&models.Product{ID: product.ID, Name: "Kopi Arabika", SKU: "KOPI-1", Price: 55, Stock: 8}))
+	for {
+		found, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		if !found {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{services.WebhookEventProductPriceChanged}, events)
+	require.Len(t, bodies, 1)
+	assert.Contains(t, bodies[0], `"old_price":50`)
+	assert.Contains(t, bodies[0], `"new_price":55`)
+	assert.Contains(t, webhooks.EventCatalog(), services.WebhookEventType{Name: services.WebhookEventProductLowStock, Description: "A product fell to its reorder point"})
+}
+
+func TestOrderService_DispatchesOrderWebhooks(t *testing.T) {
+	var events []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		events = append(events, r.Header.Get(services.WebhookEventHeader))
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	queue := jobs.NewQueue(repositories.NewMockTaskRepository(), jobs.QueueConfig{})
+	webhooks := services.NewWebhookService(
+		repositories.NewMockWebhookRepository(),
+		queue,
+		httpclient.New(httpclient.Config{Name: "webhooks-test", Timeout: time.Second}),
+	)
+	require.NoError(t, webhooks.CreateSubscription(&models.WebhookSubscription{URL: server.URL, Events: services.WebhookEventOrderCreated + "," + services.WebhookEventOrderCancelled}))
+
+	productRepo := repositories.NewMockProductRepository()
+	product := &models.Product{Name: "Lamp", Price: 40, Stock: 10}
+	require.NoError(t, productRepo.Create(product))
+	orderService := services.NewOrderService(repositories.NewMockOrderRepository(), productRepo, repositories.NewMockStockReservationRepository(productRepo), nil, time.Hour)
+	orderService.SetWebhooks(webhooks)
+
+	order, err := orderService.CreateOrder(context.Background(), models.Order{
+		UserID:          "user-1",
+		Items:           []models.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddress: testPostalAddress("Jakarta"),
+	})
+	require.NoError(t, err)
+	// The subscription does not want order.updated
+	require.NoError(t, orderService.UpdateOrderStatus(context.Background(), order.ID, "processing"))
+	_, err = orderService.CancelOrder(context.Background(), order.ID, "user-1", false, "", "")
+	require.NoError(t, err)
+	for {
+		found, err := queue.RunNext(context.Background())
+		require.NoError(t, err)
+		if !found {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{services.WebhookEventOrderCreated, services.WebhookEventOrderCancelled}, events)
+	require.Len(t, bodies, 2)
+	assert.Contains(t, bodies[0], `"status":"pending"`)
+	assert.Contains(t, bodies[1], `"status":"cancelled"`)
+	assert.Contains(t, bodies[1], order.ID)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+)
+
+// maxWishlistItems is how many products a wishlist holds.
+const maxWishlistItems = 200
+
+// WishlistService manages the products customers save to buy later.
+type WishlistService struct {
+	wishlistRepo repositories.WishlistRepository
+	productRepo  repositories.ProductRepository
+	carts        *CartService
+}
+
+// NewWishlistService creates a new WishlistService. Items are moved to the
+// user's cart through carts.
+func NewWishlistService(wishlistRepo repositories.WishlistRepository, productRepo repositories.ProductRepository, carts *CartService) *WishlistService {
+	return &WishlistService{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		carts:        carts,
+	}
+}
+
+// GetWishlist returns the user's wishlist with the current name, price and
+// availability of each product. Products removed from the catalog stay
+// listed as out of stock until the user removes them.
+func (s *WishlistService) GetWishlist(userID string) ([]models.WishlistItem, error) {
+	items, err := s.wishlistRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		product, err := s.productRepo.GetByID(items[i].ProductID)
+		if err != nil {
+			continue
+		}
+		items[i].Name = product.Name
+		items[i].Price = product.Price
+		items[i].InStock = !product.IsArchived() && (product.IsDigital() || product.Stock > 0)
+	}
+	return items, nil
+}
+
+// AddItem saves a product to the user's wishlist. Adding a product that is
+// already listed changes nothing.
+func (s *WishlistService) AddItem(userID, productID string) (*models.WishlistItem, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsArchived() {
+		return nil, fmt.Errorf("product %s not found", productID)
+	}
+	count, err := s.wishlistRepo.Count(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWishlistItems {
+		return nil, fmt.Errorf("invalid wishlist: at most %d products are allowed", maxWishlistItems)
+	}
+
+	item := &models.WishlistItem{UserID: userID, ProductID: productID}
+	if err := s.wishlistRepo.Add(item); err != nil {
+		return nil, err
+	}
+	item.Name = product.Name
+	item.Price = product.Price
+	item.InStock = product.IsDigital() || product.Stock > 0
+	return item, nil
+}
+
+// RemoveItem removes a product from the user's wishlist.
+func (s *WishlistService) RemoveItem(userID, productID string) error {
+	return s.wishlistRepo.Remove(userID, productID)
+}
+
+// MoveToCart adds units of a listed product to the user's cart and removes
+// it from the wishlist. The product stays listed when the cart rejects it,
+// e.g. because it is out of stock.
+func (s *WishlistService) MoveToCart(userID, productID string, quantity int) (*models.Cart, error) {
+	items, err := s.wishlistRepo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	listed := false
+	for _, item := range items {
+		if item.ProductID == productID {
+			listed = true
+			break
+		}
+	}
+	if !listed {
+		return nil, fmt.Errorf("product %s not found in wishlist", productID)
+	}
+
+	cart, err := s.carts.AddItem(userID, "", productID, quantity)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.wishlistRepo.Remove(userID, productID); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
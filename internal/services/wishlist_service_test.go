@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"testing"
+
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWishlistService_AddAndMoveToCart(t *testing.T) {
+	productRepo := repositories.NewMockProductRepository()
+	lamp := &models.Product{Name: "Lamp", Price: 40, Stock: 2, Status: models.ProductStatusActive}
+	mug := &models.Product{Name: "Mug", Price: 8, Stock: 0, Status: models.ProductStatusActive}
+	for _, product := range []*models.Product{lamp, mug} {
+		require.NoError(t, productRepo.Create(product))
+	}
+	carts := services.NewCartService(repositories.NewMockCartRepository(), productRepo)
+	service := services.NewWishlistService(repositories.NewMockWishlistRepository(), productRepo, carts)
+
+	_, err := service.AddItem("user-1", lamp.ID)
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", lamp.ID) // Listing a product twice changes nothing
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", mug.ID)
+	require.NoError(t, err)
+	_, err = service.AddItem("user-1", "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	items, err := service.GetWishlist("user-1")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	inStock := map[string]bool{}
+	for _, item := range items {
+		inStock[item.Name] = item.InStock
+	}
+	assert.Equal(t, map[string]bool{"Lamp": true, "Mug": false}, inStock)
+
+	// Products the cart rejects stay on the wishlist
+	_, err = service.MoveToCart("user-1", mug.ID, 1)
+	assert.ErrorContains(t, err, "insufficient stock")
+	cart, err := service.MoveToCart("user-1", lamp.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	assert.Equal(t, 2, cart.Items[0].Quantity)
+
+	items, err = service.GetWishlist("user-1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, mug.ID, items[0].ProductID)
+	_, err = service.MoveToCart("user-1", lamp.ID, 1)
+	assert.ErrorContains(t, err, "not found in wishlist")
+	assert.ErrorContains(t, service.RemoveItem("user-2", mug.ID), "not found")
+}
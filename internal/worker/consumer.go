@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"toko/pkg/rabbitmq"
+)
+
+// Source delivers the messages of a queue to a handler until ctx is
+// cancelled. *rabbitmq.Client and *rabbitmq.MemoryBroker implement it.
+type Source interface {
+	Consume(ctx context.Context, cfg rabbitmq.ConsumerConfig, handler rabbitmq.DeliveryHandler) error
+}
+
+var (
+	_ Source = (*rabbitmq.Client)(nil)
+	_ Source = (*rabbitmq.MemoryBroker)(nil)
+)
+
+// Consumer consumes a queue in the background for as long as the context
+// passed to Start lives. When consuming fails, e.g. while the broker is
+// unreachable, it tries again every retryInterval.
+type Consumer struct {
+	source        Source
+	cfg           rabbitmq.ConsumerConfig
+	handler       rabbitmq.DeliveryHandler
+	retryInterval time.Duration
+	wg            sync.WaitGroup
+}
+
+// NewConsumer creates a Consumer passing the deliveries of cfg.Queue to handler.
+func NewConsumer(source Source, cfg rabbitmq.ConsumerConfig, handler rabbitmq.DeliveryHandler, retryInterval time.Duration) *Consumer {
+	return &Consumer{
+		source:        source,
+		cfg:           cfg,
+		handler:       handler,
+		retryInterval: retryInterval,
+	}
+}
+
+// Start begins consuming until ctx is cancelled.
+func (c *Consumer) Start(ctx context.Context) {
+	c.wg.Add(1)
+	go c.run(ctx)
+}
+
+// Wait blocks until the consumer has stopped after ctx is cancelled, with
+// the deliveries it had received handled.
+func (c *Consumer) Wait() {
+	c.wg.Wait()
+}
+
+func (c *Consumer) run(ctx context.Context) {
+	defer c.wg.Done()
+
+	for {
+		err := c.source.Consume(ctx, c.cfg, c.handler)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Consumer of %s stopped, retrying in %v: %v", c.cfg.Queue, c.retryInterval, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.retryInterval):
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"toko/internal/worker"
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakySource fails the first Consume call, then delivers one message and
+// blocks until the context is cancelled.
+type flakySource struct {
+	mu    sync.Mutex
+	calls int
+	cfg   rabbitmq.ConsumerConfig
+}
+
+func (s *flakySource) Consume(ctx context.Context, cfg rabbitmq.ConsumerConfig, handler rabbitmq.DeliveryHandler) error {
+	s.mu.Lock()
+	s.calls++
+	s.cfg = cfg
+	first := s.calls == 1
+	s.mu.Unlock()
+	if first {
+		return errors.New("not connected to RabbitMQ")
+	}
+	if err := handler(cfg.Queue, amqp.Delivery{RoutingKey: "order.created", Body: []byte(`{"orderID":"order-1"}`)}); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestConsumer_RetriesUntilStopped(t *testing.T) {
+	router := worker.NewRouter()
+	handled := make(chan string, 1)
+	router.On("order.created", func(body []byte) error {
+		handled <- string(body)
+		return nil
+	})
+	router.On("order.cancelled", func(body []byte) error { return nil })
+	assert.Equal(t, []string{"order.cancelled", "order.created"}, router.RoutingKeys())
+
+	source := &flakySource{}
+	consumer := worker.NewConsumer(source, rabbitmq.ConsumerConfig{
+		Exchange:    "order",
+		Queue:       "order_events.app",
+		RoutingKeys: router.RoutingKeys(),
+	}, router.Handle, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.Start(ctx)
+	select {
+	case body := <-handled:
+		assert.JSONEq(t, `{"orderID":"order-1"}`, body)
+	case <-time.After(time.Second):
+		t.Fatal("the consumer did not try again after failing")
+	}
+
+	cancel()
+	consumer.Wait()
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	assert.Equal(t, 2, source.calls)
+	assert.Equal(t, "order_events.app", source.cfg.Queue)
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"log"
+	"toko/internal/events"
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+)
+
+// LogCorrelation wraps a delivery handler so that every message is logged
+// with its correlation and causation IDs, tying its processing back to the
+// HTTP request that published it. Messages published outside a request,
+// e.g. by jobs, have neither.
+func LogCorrelation(next rabbitmq.DeliveryHandler) rabbitmq.DeliveryHandler {
+	return func(tier string, d amqp.Delivery) error {
+		correlation := events.CorrelationFromHeaders(d.Headers)
+		log.Printf("Handling %s message %s from %s (correlation_id=%s causation_id=%s)",
+			d.RoutingKey, d.MessageId, tier, correlation.CorrelationID, correlation.CausationID)
+		err := next(tier, d)
+		if err != nil {
+			log.Printf("Failed to handle %s message %s (correlation_id=%s causation_id=%s): %v",
+				d.RoutingKey, d.MessageId, correlation.CorrelationID, correlation.CausationID, err)
+		}
+		return err
+	}
+}
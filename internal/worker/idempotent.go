@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+	"toko/internal/models"
+	"toko/internal/repositories"
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+)
+
+// IdempotencyConfig configures the Idempotent wrapper.
+type IdempotencyConfig struct {
+	Consumer string        // Name of the consumer; dedupe keys are scoped per consumer
+	Lease    time.Duration // How long a claim blocks redeliveries while the message is being processed
+	TTL      time.Duration // How long processed message IDs are remembered
+}
+
+// Idempotent wraps a delivery handler so that each message is processed at
+// most once per consumer, even when RabbitMQ redelivers it. A redelivery of a
+// message that is still being processed is requeued without counting as a
+// failed attempt; one that was already processed is acknowledged without
+// running the handler again. Messages without a message ID are identified by
+// a hash of their routing key and body.
+func Idempotent(store repositories.ProcessedMessageRepository, cfg IdempotencyConfig, next rabbitmq.DeliveryHandler) rabbitmq.DeliveryHandler {
+	return func(tier string, d amqp.Delivery) error {
+		key := cfg.Consumer + ":" + messageID(d)
+
+		state, err := store.Begin(key, cfg.Lease)
+		if err != nil {
+			return err
+		}
+		switch state {
+		case models.MessageStateDone:
+			log.Printf("Skipping duplicate %s message %s", d.RoutingKey, key)
+			return nil
+		case models.MessageStateProcessing:
+			return fmt.Errorf("message %s is already being processed: %w", key, rabbitmq.ErrRequeue)
+		}
+
+		if err := next(tier, d); err != nil {
+			if abandonErr := store.Abandon(key); abandonErr != nil {
+				log.Printf("Warning: Failed to release claim for message %s: %v", key, abandonErr)
+			}
+			return err
+		}
+		if err := store.Complete(key, cfg.TTL); err != nil {
+			// The work is done; only a redelivery after the lease expires would run it again
+			log.Printf("Warning: Failed to record message %s as processed: %v", key, err)
+		}
+		return nil
+	}
+}
+
+// messageID returns the publisher-assigned message ID, or a content hash.
+func messageID(d amqp.Delivery) string {
+	if d.MessageId != "" {
+		return d.MessageId
+	}
+	sum := sha256.Sum256(append([]byte(d.RoutingKey+"\n"), d.Body...))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
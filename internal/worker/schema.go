@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"log"
+	"toko/internal/events"
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+)
+
+// ValidateSchema wraps a delivery handler so that messages violating their
+// event schema are dropped instead of reaching the handler. Redelivering a
+// malformed message would never succeed, so it is acknowledged after logging.
+func ValidateSchema(registry *events.Registry, next rabbitmq.DeliveryHandler) rabbitmq.DeliveryHandler {
+	return func(tier string, d amqp.Delivery) error {
+		if err := registry.Validate(events.DirectionConsume, d.RoutingKey, schemaVersion(d), d.Body); err != nil {
+			log.Printf("Dropping %s message %s: %v", d.RoutingKey, d.MessageId, err)
+			return nil
+		}
+		return next(tier, d)
+	}
+}
+
+// schemaVersion reads the schema version header; 0 means the latest version.
+func schemaVersion(d amqp.Delivery) int {
+	switch v := d.Headers[events.SchemaVersionHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
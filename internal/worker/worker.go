@@ -0,0 +1,58 @@
+// Package worker processes asynchronous events consumed from RabbitMQ.
+package worker
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// EventHandler processes the body of one event.
+type EventHandler func(body []byte) error
+
+// Router dispatches deliveries to handlers by routing key.
+type Router struct {
+	mu       sync.RWMutex
+	handlers map[string]EventHandler
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[string]EventHandler),
+	}
+}
+
+// On registers the handler for a routing key, replacing any previous one.
+func (r *Router) On(routingKey string, handler EventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[routingKey] = handler
+}
+
+// RoutingKeys returns the routing keys with a handler, sorted.
+func (r *Router) RoutingKeys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.handlers))
+	for key := range r.handlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Handle processes a delivery from the given priority tier. Events without a
+// handler are acknowledged so they do not block the queue.
+func (r *Router) Handle(tier string, d amqp.Delivery) error {
+	r.mu.RLock()
+	handler, ok := r.handlers[d.RoutingKey]
+	r.mu.RUnlock()
+	if !ok {
+		log.Printf("No handler for %s event from %s tier; acknowledging", d.RoutingKey, tier)
+		return nil
+	}
+	return handler(d.Body)
+}
@@ -34,6 +34,11 @@ func (m *MockRabbitMQClient) Publish(exchange, routingKey string, body []byte) e
 	return args.Error(0)
 }
 
+func (m *MockRabbitMQClient) PublishWithHeaders(exchange, routingKey string, body []byte, headers amqp.Table) error {
+	args := m.Called(exchange, routingKey, body, headers)
+	return args.Error(0)
+}
+
 func (m *MockRabbitMQClient) PublishOrderCreated(messageBody map[string]interface{}) error {
 	args := m.Called(messageBody)
 	return args.Error(0)
@@ -77,7 +82,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// Auto-migrate models
-	err = db.AutoMigrate(&models.Product{}, &models.User{})
+	err = db.AutoMigrate(&models.Product{}, &models.CatalogChange{}, &models.User{}, &models.Order{}, &models.OrderItem{}, &models.StockReservation{})
 	if err != nil {
 		log.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -93,10 +98,11 @@ func TestMain(m *testing.M) {
 	// Mock RabbitMQ client
 	mockMQ = new(MockRabbitMQClient)
 	mockMQ.On("PublishOrderCreated", mock.Anything).Return(nil)
+	mockMQ.On("PublishWithHeaders", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mockMQ.On("Close").Return(nil)
 
 	// Initialize the app, injecting the mock MQ client
-	app, authService, err = mainapp.NewApp()
+	app, authService, err = mainapp.NewApp(mockMQ)
 	if err != nil {
 		log.Fatalf("Failed to create app: %v", err)
 	}
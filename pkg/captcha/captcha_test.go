@@ -0,0 +1,50 @@
+package captcha_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"toko/pkg/captcha"
+	"toko/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecaptcha_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+		switch r.PostForm.Get("response") {
+		case "human":
+			w.Write([]byte(`{"success":true,"score":0.9}`))
+		case "bot":
+			w.Write([]byte(`{"success":true,"score":0.1}`))
+		default:
+			w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		}
+	}))
+	defer server.Close()
+
+	verifier := captcha.NewRecaptcha(captcha.Config{Secret: "secret", VerifyURL: server.URL, MinScore: 0.5}, httpclient.New(httpclient.Config{Name: "recaptcha"}))
+
+	assert.NoError(t, verifier.Verify(context.Background(), "human", "203.0.113.7"))
+	assert.ErrorContains(t, verifier.Verify(context.Background(), "bot", "203.0.113.7"), "invalid captcha: score")
+	assert.ErrorContains(t, verifier.Verify(context.Background(), "forged", "203.0.113.7"), "invalid-input-response")
+	assert.ErrorContains(t, verifier.Verify(context.Background(), "", "203.0.113.7"), "token is required")
+}
+
+func TestTurnstile_IgnoresScores(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"score":0.1}`))
+	}))
+	defer server.Close()
+
+	verifier := captcha.NewTurnstile(captcha.Config{Secret: "secret", VerifyURL: server.URL, MinScore: 0.5}, httpclient.New(httpclient.Config{Name: "turnstile"}))
+
+	assert.NoError(t, verifier.Verify(context.Background(), "token", ""))
+	assert.Equal(t, "turnstile", verifier.Name())
+}
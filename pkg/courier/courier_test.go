@@ -0,0 +1,93 @@
+package courier_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"toko/pkg/courier"
+	"toko/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropOffLabeler_IssuesUniqueCodes(t *testing.T) {
+	labeler := courier.NewDropOffLabeler("JNE", 14*24*time.Hour)
+
+	first, err := labeler.CreateReturnLabel(context.Background(), courier.ReturnLabelRequest{Reference: "return-1"})
+	require.NoError(t, err)
+	second, err := labeler.CreateReturnLabel(context.Background(), courier.ReturnLabelRequest{Reference: "return-2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "JNE", first.Carrier)
+	assert.Regexp(t, regexp.MustCompile(`^RT-[A-HJ-NP-Z2-9]{4}-[A-HJ-NP-Z2-9]{4}$`), first.DropOffCode)
+	assert.NotEqual(t, first.DropOffCode, second.DropOffCode)
+	assert.WithinDuration(t, time.Now().Add(14*24*time.Hour), first.ExpiresAt, time.Minute)
+}
+
+func TestFlatRate_WaivesShippingAboveThreshold(t *testing.T) {
+	provider := &courier.FlatRate{Amount: 15000, Currency: "IDR", FreeOver: 500000}
+
+	rates, err := provider.Rates(context.Background(), courier.RateRequest{Subtotal: 100000})
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, 15000.0, rates[0].Amount)
+	assert.Equal(t, "flat", rates[0].Provider)
+
+	rates, err = provider.Rates(context.Background(), courier.RateRequest{Subtotal: 500000})
+	require.NoError(t, err)
+	assert.Zero(t, rates[0].Amount)
+}
+
+func TestWeightBased_ChargesPerStartedKilogram(t *testing.T) {
+	provider := &courier.WeightBased{Base: 5000, PerKg: 9000, Currency: "IDR"}
+
+	for grams, want := range map[int]float64{0: 14000, 1000: 14000, 1001: 23000, 2500: 32000} {
+		rates, err := provider.Rates(context.Background(), courier.RateRequest{WeightGrams: grams})
+		require.NoError(t, err)
+		assert.Equal(t, want, rates[0].Amount, "weight %dg", grams)
+	}
+}
+
+func TestRajaOngkir_ParsesCourierCosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cost", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("key"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "501", r.PostForm.Get("origin"))
+		assert.Equal(t, "114", r.PostForm.Get("destination"))
+		assert.Equal(t, "1700", r.PostForm.Get("weight"))
+		assert.Equal(t, "jne", r.PostForm.Get("courier"))
+		w.Write([]byte(`{"rajaongkir":{"status":{"code":200,"description":"OK"},"results":[{"code":"jne","costs":[
+			{"service":"OKE","cost":[{"value":38000,"etd":"4-5"}]},
+			{"service":"REG","cost":[{"value":44000,"etd":"2-3"}]}]}]}}`))
+	}))
+	defer server.Close()
+
+	provider := courier.NewRajaOngkir(courier.RajaOngkirConfig{
+		BaseURL:  server.URL,
+		APIKey:   "secret",
+		Couriers: []string{"jne"},
+	}, httpclient.New(httpclient.Config{Name: "rajaongkir"}))
+
+	rates, err := provider.Rates(context.Background(), courier.RateRequest{
+		Origin:      courier.Address{AreaID: "501"},
+		Destination: courier.Address{AreaID: "114"},
+		WeightGrams: 1700,
+	})
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, courier.Rate{Provider: "JNE", Service: "OKE", Amount: 38000, Currency: "IDR", EstimatedDays: "4-5"}, rates[0])
+	assert.Equal(t, "REG", rates[1].Service)
+}
+
+func TestRajaOngkir_RequiresCityIDs(t *testing.T) {
+	provider := courier.NewRajaOngkir(courier.RajaOngkirConfig{Couriers: []string{"jne"}}, httpclient.New(httpclient.Config{Name: "rajaongkir"}))
+
+	_, err := provider.Rates(context.Background(), courier.RateRequest{WeightGrams: 1000})
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package courier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"toko/pkg/httpclient"
+)
+
+// RajaOngkirConfig configures the RajaOngkir rate provider.
+type RajaOngkirConfig struct {
+	BaseURL  string // e.g. https://api.rajaongkir.com/starter
+	APIKey   string
+	Couriers []string // Courier codes to quote, e.g. jne, pos, tiki
+}
+
+// RajaOngkir quotes rates of Indonesian couriers such as JNE through the
+// RajaOngkir cost API. Origin and destination must carry RajaOngkir city IDs
+// in AreaID.
+type RajaOngkir struct {
+	cfg    RajaOngkirConfig
+	client *httpclient.Client
+}
+
+// NewRajaOngkir creates a RajaOngkir provider that sends requests through client.
+func NewRajaOngkir(cfg RajaOngkirConfig, client *httpclient.Client) *RajaOngkir {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &RajaOngkir{cfg: cfg, client: client}
+}
+
+// Name returns the provider name.
+func (p *RajaOngkir) Name() string {
+	return "rajaongkir"
+}
+
+type rajaOngkirResponse struct {
+	RajaOngkir struct {
+		Status struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"status"`
+		Results []struct {
+			Code  string `json:"code"`
+			Costs []struct {
+				Service string `json:"service"`
+				Cost    []struct {
+					Value float64 `json:"value"`
+					ETD   string  `json:"etd"`
+				} `json:"cost"`
+			} `json:"costs"`
+		} `json:"results"`
+	} `json:"rajaongkir"`
+}
+
+// Rates returns the services of every configured courier.
+func (p *RajaOngkir) Rates(ctx context.Context, req RateRequest) ([]Rate, error) {
+	if req.Origin.AreaID == "" || req.Destination.AreaID == "" {
+		return nil, fmt.Errorf("rajaongkir: origin and destination city IDs are required")
+	}
+	weight := req.WeightGrams
+	if weight < 1 {
+		weight = 1 // The API rejects zero weights
+	}
+
+	var rates []Rate
+	for _, code := range p.cfg.Couriers {
+		form := url.Values{
+			"origin":      {req.Origin.AreaID},
+			"destination": {req.Destination.AreaID},
+			"weight":      {strconv.Itoa(weight)},
+			"courier":     {code},
+		}
+		courierRates, err := p.quote(ctx, form)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, courierRates...)
+	}
+	return rates, nil
+}
+
+func (p *RajaOngkir) quote(ctx context.Context, form url.Values) ([]Rate, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+"/cost", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("rajaongkir: failed to build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("key", p.cfg.APIKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("rajaongkir: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body rajaOngkirResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("rajaongkir: failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+	if status := body.RajaOngkir.Status; status.Code != http.StatusOK {
+		return nil, fmt.Errorf("rajaongkir: %s (code %d)", status.Description, status.Code)
+	}
+
+	var rates []Rate
+	for _, result := range body.RajaOngkir.Results {
+		for _, cost := range result.Costs {
+			for _, option := range cost.Cost {
+				rates = append(rates, Rate{
+					Provider:      strings.ToUpper(result.Code),
+					Service:       cost.Service,
+					Amount:        option.Value,
+					Currency:      "IDR",
+					EstimatedDays: option.ETD,
+				})
+			}
+		}
+	}
+	return rates, nil
+}
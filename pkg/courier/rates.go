@@ -0,0 +1,96 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// RateRequest describes a parcel to quote shipping for.
+type RateRequest struct {
+	Origin      Address
+	Destination Address
+	WeightGrams int
+	Subtotal    float64 // Value of the goods, used for free-shipping thresholds
+}
+
+// Rate is one shipping option offered by a provider.
+type Rate struct {
+	Provider      string  `json:"provider"`
+	Service       string  `json:"service"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency"`
+	EstimatedDays string  `json:"estimated_days,omitempty"`
+}
+
+// RateProvider quotes shipping rates for a parcel.
+type RateProvider interface {
+	Name() string
+	Rates(ctx context.Context, req RateRequest) ([]Rate, error)
+}
+
+// FlatRate charges the same amount for every parcel, optionally waiving it
+// for orders above a threshold.
+type FlatRate struct {
+	Amount        float64
+	Currency      string
+	FreeOver      float64 // Subtotal at or above which shipping is free; zero disables it
+	Service       string
+	EstimatedDays string
+}
+
+// Name returns the provider name.
+func (p *FlatRate) Name() string {
+	return "flat"
+}
+
+// Rates returns the single flat rate.
+func (p *FlatRate) Rates(ctx context.Context, req RateRequest) ([]Rate, error) {
+	amount := p.Amount
+	if p.FreeOver > 0 && req.Subtotal >= p.FreeOver {
+		amount = 0
+	}
+	return []Rate{{
+		Provider:      p.Name(),
+		Service:       serviceOrDefault(p.Service, "standard"),
+		Amount:        amount,
+		Currency:      p.Currency,
+		EstimatedDays: p.EstimatedDays,
+	}}, nil
+}
+
+// WeightBased charges a base amount plus a price for every started kilogram.
+type WeightBased struct {
+	Base          float64
+	PerKg         float64
+	Currency      string
+	Service       string
+	EstimatedDays string
+}
+
+// Name returns the provider name.
+func (p *WeightBased) Name() string {
+	return "weight"
+}
+
+// Rates returns the weight-based rate; parcels are billed for at least one kilogram.
+func (p *WeightBased) Rates(ctx context.Context, req RateRequest) ([]Rate, error) {
+	if req.WeightGrams < 0 {
+		return nil, fmt.Errorf("invalid parcel weight %d", req.WeightGrams)
+	}
+	kilograms := math.Max(1, math.Ceil(float64(req.WeightGrams)/1000))
+	return []Rate{{
+		Provider:      p.Name(),
+		Service:       serviceOrDefault(p.Service, "standard"),
+		Amount:        p.Base + kilograms*p.PerKg,
+		Currency:      p.Currency,
+		EstimatedDays: p.EstimatedDays,
+	}}, nil
+}
+
+func serviceOrDefault(service, fallback string) string {
+	if service == "" {
+		return fallback
+	}
+	return service
+}
@@ -0,0 +1,99 @@
+package erp
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// CSVAdapter exchanges CSV files through a file transport such as an SFTP
+// server. It reads <entity>.csv from InboundDir and writes one
+// <entity>-<timestamp>.csv per push to OutboundDir.
+type CSVAdapter struct {
+	Transport   FileTransport
+	InboundDir  string
+	OutboundDir string
+}
+
+// Name returns the adapter name.
+func (a *CSVAdapter) Name() string {
+	return "csv"
+}
+
+// Pull reads every row of the entity's inbound file. A missing file means
+// the external system has nothing to send.
+func (a *CSVAdapter) Pull(ctx context.Context, entity string, since time.Time) ([]Record, error) {
+	data, err := a.Transport.ReadFile(ctx, path.Join(a.InboundDir, entity+".csv"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s file: %w", entity, err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s file: %w", entity, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := rows[0]
+	records := make([]Record, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(Record, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Push writes the records to a new outbound file. The columns are the union
+// of the records' fields in alphabetical order.
+func (a *CSVAdapter) Push(ctx context.Context, entity string, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	columns := map[string]bool{}
+	for _, record := range records {
+		for field := range record {
+			columns[field] = true
+		}
+	}
+	header := make([]string, 0, len(columns))
+	for column := range columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	for _, record := range records {
+		row := make([]string, len(header))
+		for i, column := range header {
+			row[i] = record[column]
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.csv", entity, time.Now().UTC().Format("20060102T150405.000Z"))
+	if err := a.Transport.WriteFile(ctx, path.Join(a.OutboundDir, name), buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write %s file: %w", entity, err)
+	}
+	return nil
+}
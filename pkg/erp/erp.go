@@ -0,0 +1,29 @@
+// Package erp exchanges catalog, stock and order records with external ERP
+// and accounting systems. Adapters only move flat records; mapping them onto
+// store data is left to the caller.
+package erp
+
+import (
+	"context"
+	"time"
+)
+
+// Synced entities.
+const (
+	EntityProducts = "products"
+	EntityStock    = "stock"
+	EntityOrders   = "orders"
+)
+
+// Record is one flat record keyed by field name, e.g. a CSV row.
+type Record map[string]string
+
+// Adapter moves records between the store and an external system.
+type Adapter interface {
+	Name() string
+	// Pull returns the entity's records in the external system. Adapters may
+	// use since to skip older records, but callers must not rely on it.
+	Pull(ctx context.Context, entity string, since time.Time) ([]Record, error)
+	// Push sends records to the external system.
+	Push(ctx context.Context, entity string, records []Record) error
+}
@@ -0,0 +1,81 @@
+package erp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"toko/pkg/erp"
+	"toko/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVAdapter_PullAndPush(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "in"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "in", "stock.csv"), []byte("ItemCode,QtyOnHand\nSKU-1,12\nSKU-2,0\n"), 0o644))
+	adapter := &erp.CSVAdapter{Transport: &erp.DirTransport{Root: root}, InboundDir: "in", OutboundDir: "out"}
+
+	records, err := adapter.Pull(context.Background(), erp.EntityStock, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []erp.Record{{"ItemCode": "SKU-1", "QtyOnHand": "12"}, {"ItemCode": "SKU-2", "QtyOnHand": "0"}}, records)
+
+	// A missing inbound file is not an error
+	records, err = adapter.Pull(context.Background(), erp.EntityProducts, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, adapter.Push(context.Background(), erp.EntityOrders, []erp.Record{{"order_id": "1", "sku": "SKU-1"}, {"order_id": "2", "quantity": "3"}}))
+	files, err := filepath.Glob(filepath.Join(root, "out", "orders-*.csv"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "order_id,quantity,sku\n1,,SKU-1\n2,3,\n", string(data))
+}
+
+func TestRESTAdapter_PullAndPush(t *testing.T) {
+	var pushed []erp.Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "/products", r.URL.Path)
+			assert.Equal(t, "2026-01-02T03:04:05Z", r.URL.Query().Get("updated_since"))
+			w.Write([]byte(`[{"sku":"SKU-1","price":1500.5,"active":true,"note":null}]`))
+		case http.MethodPost:
+			assert.Equal(t, "/orders", r.URL.Path)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pushed))
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	adapter := erp.NewRESTAdapter(erp.RESTConfig{BaseURL: server.URL, Token: "secret"}, httpclient.New(httpclient.Config{Name: "erp-test", Timeout: time.Second}))
+
+	records, err := adapter.Pull(context.Background(), erp.EntityProducts, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []erp.Record{{"sku": "SKU-1", "price": "1500.5", "active": "true", "note": ""}}, records)
+
+	require.NoError(t, adapter.Push(context.Background(), erp.EntityOrders, []erp.Record{{"order_id": "7"}}))
+	assert.Equal(t, []erp.Record{{"order_id": "7"}}, pushed)
+}
+
+func TestRESTAdapter_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	adapter := erp.NewRESTAdapter(erp.RESTConfig{BaseURL: server.URL}, httpclient.New(httpclient.Config{Name: "erp-test", Timeout: time.Second}))
+	_, err := adapter.Pull(context.Background(), erp.EntityStock, time.Time{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+}
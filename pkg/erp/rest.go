@@ -0,0 +1,116 @@
+package erp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"toko/pkg/httpclient"
+)
+
+// RESTConfig holds the settings of a REST ERP endpoint.
+type RESTConfig struct {
+	BaseURL string // Entities live at BaseURL/<entity>
+	Token   string // Sent as a bearer token when set
+}
+
+// RESTAdapter exchanges JSON arrays of flat objects with a REST API:
+// GET <base>/<entity>?updated_since=<RFC 3339> returns records and
+// POST <base>/<entity> accepts them.
+type RESTAdapter struct {
+	cfg    RESTConfig
+	client *httpclient.Client
+}
+
+// NewRESTAdapter creates a RESTAdapter that sends requests with client.
+func NewRESTAdapter(cfg RESTConfig, client *httpclient.Client) *RESTAdapter {
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &RESTAdapter{cfg: cfg, client: client}
+}
+
+// Name returns the adapter name.
+func (a *RESTAdapter) Name() string {
+	return "rest"
+}
+
+// Pull fetches the entity's records changed since the given time. Values of
+// any JSON type are converted to strings; nulls become empty strings.
+func (a *RESTAdapter) Pull(ctx context.Context, entity string, since time.Time) ([]Record, error) {
+	endpoint := a.cfg.BaseURL + "/" + entity
+	if !since.IsZero() {
+		endpoint += "?updated_since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var objects []map[string]interface{}
+	if err := a.do(req, &objects); err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(objects))
+	for _, object := range objects {
+		record := make(Record, len(object))
+		for field, value := range object {
+			switch v := value.(type) {
+			case nil:
+				record[field] = ""
+			case string:
+				record[field] = v
+			default:
+				encoded, _ := json.Marshal(v)
+				record[field] = string(encoded)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Push posts the records as a JSON array.
+func (a *RESTAdapter) Push(ctx context.Context, entity string, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", entity, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.BaseURL+"/"+entity, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return a.do(req, nil)
+}
+
+func (a *RESTAdapter) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	if a.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.cfg.Token)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ERP returned status %d: %s", resp.StatusCode, snippet)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode ERP response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package erp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// FileTransport reads and writes files exchanged with an external system.
+// Missing files are reported with an error wrapping fs.ErrNotExist.
+type FileTransport interface {
+	ReadFile(ctx context.Context, name string) ([]byte, error)
+	WriteFile(ctx context.Context, name string, data []byte) error
+}
+
+// DirTransport exchanges files in a local directory, e.g. a mounted share.
+type DirTransport struct {
+	Root string
+}
+
+// ReadFile reads a file below the root.
+func (t *DirTransport) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(t.Root, filepath.FromSlash(name)))
+}
+
+// WriteFile writes a file below the root, creating its directory.
+func (t *DirTransport) WriteFile(ctx context.Context, name string, data []byte) error {
+	file := filepath.Join(t.Root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return writeAtomically(file, data)
+}
+
+// writeAtomically writes to a temporary file first so readers never see a partial file.
+func writeAtomically(file string, data []byte) error {
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// SFTPConfig holds the settings of an SFTP server.
+type SFTPConfig struct {
+	Addr     string // host:port
+	User     string
+	Password string
+	// HostKey is the server's public key in authorized_keys format. Without
+	// it any host key is accepted, which is only suitable for testing.
+	HostKey string
+	Timeout time.Duration
+}
+
+// SFTPTransport exchanges files on an SFTP server. A connection is opened for
+// every file, as syncs are infrequent.
+type SFTPTransport struct {
+	cfg SFTPConfig
+}
+
+// NewSFTPTransport creates an SFTPTransport.
+func NewSFTPTransport(cfg SFTPConfig) *SFTPTransport {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SFTPTransport{cfg: cfg}
+}
+
+// ReadFile downloads a file.
+func (t *SFTPTransport) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := t.session(ctx, func(client *sftp.Client) error {
+		file, err := client.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+		return err
+	})
+	return data, err
+}
+
+// WriteFile uploads a file under a temporary name and renames it when complete.
+func (t *SFTPTransport) WriteFile(ctx context.Context, name string, data []byte) error {
+	return t.session(ctx, func(client *sftp.Client) error {
+		if err := client.MkdirAll(path.Dir(name)); err != nil {
+			return err
+		}
+		tmp := name + ".tmp"
+		file, err := client.Create(tmp)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+		return client.PosixRename(tmp, name)
+	})
+}
+
+func (t *SFTPTransport) session(ctx context.Context, fn func(client *sftp.Client) error) error {
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if t.cfg.HostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(t.cfg.HostKey))
+		if err != nil {
+			return fmt.Errorf("invalid SFTP host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(key)
+	}
+
+	dialer := net.Dialer{Timeout: t.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", t.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SFTP server %s: %w", t.cfg.Addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.cfg.Addr, &ssh.ClientConfig{
+		User:            t.cfg.User,
+		Auth:            []ssh.AuthMethod{ssh.Password(t.cfg.Password)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         t.cfg.Timeout,
+	})
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to log in to SFTP server %s: %w", t.cfg.Addr, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	defer sshClient.Close()
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+	defer client.Close()
+	return fn(client)
+}
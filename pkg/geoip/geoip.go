@@ -0,0 +1,120 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// Location holds the regional defaults derived from a client's country.
+type Location struct {
+	Country  string `json:"country"`
+	Currency string `json:"currency"`
+	Locale   string `json:"locale"`
+}
+
+// Resolver maps an IP address to an ISO 3166-1 alpha-2 country code.
+type Resolver interface {
+	Country(ip net.IP) (string, bool)
+}
+
+// countryDefaults lists the currency and locale used for each supported country.
+var countryDefaults = map[string]Location{
+	"ID": {Country: "ID", Currency: "IDR", Locale: "id-ID"},
+	"MY": {Country: "MY", Currency: "MYR", Locale: "ms-MY"},
+	"SG": {Country: "SG", Currency: "SGD", Locale: "en-SG"},
+	"US": {Country: "US", Currency: "USD", Locale: "en-US"},
+	"GB": {Country: "GB", Currency: "GBP", Locale: "en-GB"},
+	"DE": {Country: "DE", Currency: "EUR", Locale: "de-DE"},
+	"FR": {Country: "FR", Currency: "EUR", Locale: "fr-FR"},
+	"NL": {Country: "NL", Currency: "EUR", Locale: "nl-NL"},
+	"JP": {Country: "JP", Currency: "JPY", Locale: "ja-JP"},
+	"AU": {Country: "AU", Currency: "AUD", Locale: "en-AU"},
+}
+
+// DefaultsFor returns the regional defaults for a country code.
+func DefaultsFor(country string) (Location, bool) {
+	loc, ok := countryDefaults[strings.ToUpper(country)]
+	return loc, ok
+}
+
+type network struct {
+	ipNet   *net.IPNet
+	country string
+}
+
+// CIDRResolver resolves countries from a static list of CIDR ranges.
+type CIDRResolver struct {
+	networks []network
+}
+
+// NewCIDRResolver creates a resolver from a map of CIDR ranges to country codes.
+func NewCIDRResolver(ranges map[string]string) (*CIDRResolver, error) {
+	r := &CIDRResolver{}
+	for cidr, country := range ranges {
+		if err := r.add(cidr, country); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// LoadCIDRFile loads a resolver from a CSV file with "cidr,country" rows.
+// Lines starting with '#' are treated as comments.
+func LoadCIDRFile(path string) (*CIDRResolver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+
+	r := &CIDRResolver{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GeoIP database %s: %w", path, err)
+		}
+		if err := r.add(strings.TrimSpace(record[0]), strings.TrimSpace(record[1])); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *CIDRResolver) add(cidr, country string) error {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	r.networks = append(r.networks, network{ipNet: ipNet, country: strings.ToUpper(country)})
+	return nil
+}
+
+// Country returns the country of the most specific range containing ip.
+func (r *CIDRResolver) Country(ip net.IP) (string, bool) {
+	if r == nil || ip == nil {
+		return "", false
+	}
+	bestOnes := -1
+	country := ""
+	for _, n := range r.networks {
+		if !n.ipNet.Contains(ip) {
+			continue
+		}
+		if ones, _ := n.ipNet.Mask.Size(); ones > bestOnes {
+			bestOnes = ones
+			country = n.country
+		}
+	}
+	return country, bestOnes >= 0
+}
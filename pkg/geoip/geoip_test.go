@@ -0,0 +1,57 @@
+package geoip_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"toko/pkg/geoip"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIDRResolver_Country(t *testing.T) {
+	resolver, err := geoip.NewCIDRResolver(map[string]string{
+		"10.0.0.0/8":  "us",
+		"10.1.0.0/16": "ID",
+	})
+	assert.NoError(t, err)
+
+	// Most specific range wins
+	country, ok := resolver.Country(net.ParseIP("10.1.2.3"))
+	assert.True(t, ok)
+	assert.Equal(t, "ID", country)
+
+	country, ok = resolver.Country(net.ParseIP("10.2.0.1"))
+	assert.True(t, ok)
+	assert.Equal(t, "US", country)
+
+	_, ok = resolver.Country(net.ParseIP("192.168.0.1"))
+	assert.False(t, ok)
+
+	// Invalid CIDR
+	_, err = geoip.NewCIDRResolver(map[string]string{"not-a-cidr": "US"})
+	assert.Error(t, err)
+}
+
+func TestLoadCIDRFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	content := "# cidr,country\n203.0.113.0/24,SG\n2001:db8::/32,JP\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	resolver, err := geoip.LoadCIDRFile(path)
+	assert.NoError(t, err)
+
+	country, ok := resolver.Country(net.ParseIP("203.0.113.10"))
+	assert.True(t, ok)
+	assert.Equal(t, "SG", country)
+
+	country, ok = resolver.Country(net.ParseIP("2001:db8::1"))
+	assert.True(t, ok)
+	assert.Equal(t, "JP", country)
+
+	loc, ok := geoip.DefaultsFor(country)
+	assert.True(t, ok)
+	assert.Equal(t, "JPY", loc.Currency)
+}
@@ -0,0 +1,215 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker is rejecting requests.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrTooManyRequests is returned when the client's concurrency limit is reached.
+var ErrTooManyRequests = errors.New("too many concurrent requests")
+
+// Config holds the settings of an outbound HTTP client.
+type Config struct {
+	Name             string        // Integration name used in logs and metrics, e.g. "payments"
+	Timeout          time.Duration // Per-attempt timeout
+	MaxRetries       int           // Retries after the first attempt
+	BaseBackoff      time.Duration // Initial backoff, doubled on each retry
+	MaxBackoff       time.Duration // Upper bound for a single backoff
+	BreakerThreshold int           // Consecutive failures that open the circuit; 0 disables the breaker
+	BreakerCooldown  time.Duration // How long the circuit stays open before a trial request
+	MaxConcurrent    int           // Maximum in-flight requests; 0 means unlimited
+}
+
+// DefaultConfig returns sensible defaults for the named integration.
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:             name,
+		Timeout:          5 * time.Second,
+		MaxRetries:       2,
+		BaseBackoff:      200 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+		MaxConcurrent:    20,
+	}
+}
+
+// Client is an HTTP client for external integrations with timeouts, retries,
+// circuit breaking, request ID propagation and expvar metrics.
+type Client struct {
+	cfg     Config
+	http    *http.Client
+	breaker *breaker
+	sem     chan struct{}
+	metrics *metrics
+}
+
+// New creates a Client from cfg.
+func New(cfg Config) *Client {
+	c := &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		metrics: newMetrics(cfg.Name),
+	}
+	if cfg.MaxConcurrent > 0 {
+		c.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return c
+}
+
+// Do sends req, retrying transient failures with exponential backoff.
+// Requests with a body are only retried when req.GetBody is set, which is the
+// case for requests created by http.NewRequest with a bytes or strings reader.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.sem != nil {
+		select {
+		case c.sem <- struct{}{}:
+			defer func() { <-c.sem }()
+		default:
+			c.metrics.rejected.Add(1)
+			return nil, fmt.Errorf("%s: %w", c.cfg.Name, ErrTooManyRequests)
+		}
+	}
+
+	if requestID := RequestIDFromContext(req.Context()); requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if req.Body != nil && req.GetBody == nil {
+				break // The body has been consumed and cannot be replayed
+			}
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("%s: failed to rewind request body: %w", c.cfg.Name, err)
+				}
+				req.Body = body
+			}
+			c.metrics.retries.Add(1)
+			if err := sleep(req.Context(), c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		if !c.breaker.allow() {
+			c.metrics.breakerRejected.Add(1)
+			return nil, fmt.Errorf("%s: %w", c.cfg.Name, ErrCircuitOpen)
+		}
+
+		start := time.Now()
+		resp, err := c.http.Do(req)
+		c.metrics.requests.Add(1)
+		c.metrics.latencyMs.Add(time.Since(start).Milliseconds())
+
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			c.breaker.success()
+			return resp, nil
+		}
+
+		c.metrics.failures.Add(1)
+		c.breaker.failure()
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			if attempt == c.cfg.MaxRetries {
+				return resp, nil // Let the caller inspect the final response
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if req.Context().Err() != nil {
+			break
+		}
+		log.Printf("%s request to %s failed (attempt %d): %v", c.cfg.Name, req.URL.Host, attempt+1, lastErr)
+	}
+	return nil, fmt.Errorf("%s request failed: %w", c.cfg.Name, lastErr)
+}
+
+// GetJSON performs a GET request and decodes the JSON response into out.
+func (c *Client) GetJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.doJSON(req, out)
+}
+
+// PostJSON encodes in as JSON, POSTs it and decodes the JSON response into out (if non-nil).
+func (c *Client) PostJSON(ctx context.Context, url string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return c.doJSON(req, out)
+}
+
+func (c *Client) doJSON(req *http.Request, out interface{}) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", c.cfg.Name, resp.StatusCode, snippet)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.cfg.Name, err)
+	}
+	return nil
+}
+
+// backoff returns the jittered delay before the given retry attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.BaseBackoff << (attempt - 1)
+	if c.cfg.MaxBackoff > 0 && d > c.cfg.MaxBackoff {
+		d = c.cfg.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Full jitter between d/2 and d spreads out retries from concurrent callers
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
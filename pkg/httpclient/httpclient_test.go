@@ -0,0 +1,70 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"toko/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig(name string) httpclient.Config {
+	cfg := httpclient.DefaultConfig(name)
+	cfg.BaseBackoff = time.Millisecond
+	cfg.MaxBackoff = 5 * time.Millisecond
+	return cfg
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "req-123", r.Header.Get(httpclient.RequestIDHeader))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"rate":15500}`))
+	}))
+	defer server.Close()
+
+	client := httpclient.New(testConfig("retry-test"))
+	var out struct {
+		Rate float64 `json:"rate"`
+	}
+	ctx := httpclient.WithRequestID(context.Background(), "req-123")
+	err := client.GetJSON(ctx, server.URL, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, 15500.0, out.Rate)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestClient_CircuitBreakerOpens(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cfg := testConfig("breaker-test")
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = time.Hour
+	client := httpclient.New(cfg)
+
+	for i := 0; i < 2; i++ {
+		assert.Error(t, client.GetJSON(context.Background(), server.URL, nil))
+	}
+
+	// The circuit is open now, so the provider is not called again
+	err := client.GetJSON(context.Background(), server.URL, nil)
+	assert.True(t, errors.Is(err, httpclient.ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
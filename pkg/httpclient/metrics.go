@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"context"
+	"expvar"
+	"sync"
+)
+
+// RequestIDHeader is the header used to propagate request IDs to providers.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID to propagate.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// clientMetrics is published at /debug/vars under "httpclient".
+var clientMetrics = expvar.NewMap("httpclient")
+
+var metricsMu sync.Mutex
+
+type metrics struct {
+	requests        *expvar.Int
+	failures        *expvar.Int
+	retries         *expvar.Int
+	rejected        *expvar.Int
+	breakerRejected *expvar.Int
+	latencyMs       *expvar.Int
+}
+
+// newMetrics returns the counters for the named client, creating them once.
+func newMetrics(name string) *metrics {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	m, ok := clientMetrics.Get(name).(*expvar.Map)
+	if !ok {
+		m = new(expvar.Map).Init()
+		clientMetrics.Set(name, m)
+	}
+	counter := func(key string) *expvar.Int {
+		if v, ok := m.Get(key).(*expvar.Int); ok {
+			return v
+		}
+		v := new(expvar.Int)
+		m.Set(key, v)
+		return v
+	}
+	return &metrics{
+		requests:        counter("requests"),
+		failures:        counter("failures"),
+		retries:         counter("retries"),
+		rejected:        counter("rejected_concurrency"),
+		breakerRejected: counter("rejected_circuit_open"),
+		latencyMs:       counter("latency_ms_total"),
+	}
+}
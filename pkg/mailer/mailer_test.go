@@ -0,0 +1,106 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"toko/pkg/mailer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one message and sends its DATA on the returned channel.
+func fakeSMTPServer(t *testing.T) (string, int, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		io.WriteString(conn, "220 localhost ESMTP\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "DATA"):
+				io.WriteString(conn, "354 go ahead\r\n")
+				var body strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					body.WriteString(line)
+				}
+				data <- body.String()
+				io.WriteString(conn, "250 queued\r\n")
+			case strings.HasPrefix(cmd, "QUIT"):
+				io.WriteString(conn, "221 bye\r\n")
+				return
+			default:
+				io.WriteString(conn, "250 ok\r\n")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, data
+}
+
+func TestSMTPMailer_SendsAttachments(t *testing.T) {
+	host, port, data := fakeSMTPServer(t)
+	m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port, From: "shop@example.com"})
+
+	err := m.Send(context.Background(), mailer.Message{
+		To:          []string{"budi@example.com"},
+		Subject:     "Your invoice",
+		Text:        "Thanks for your order.",
+		HTML:        "<p>Thanks for your order.</p>",
+		Attachments: []mailer.Attachment{{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}},
+	})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-data))
+	require.NoError(t, err)
+	assert.Equal(t, "Your invoice", msg.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	body, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(body.Header.Get("Content-Type"), "multipart/alternative"))
+
+	attachment, err := parts.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "invoice.pdf", attachment.FileName())
+	assert.Equal(t, "application/pdf", attachment.Header.Get("Content-Type"))
+}
+
+func TestSMTPMailer_Verify(t *testing.T) {
+	host, port, _ := fakeSMTPServer(t)
+	m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port, From: "shop@example.com"})
+	assert.NoError(t, m.Verify(context.Background()))
+
+	// Nothing listens on port 1
+	m = mailer.NewSMTPMailer(mailer.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "shop@example.com"})
+	assert.Error(t, m.Verify(context.Background()))
+}
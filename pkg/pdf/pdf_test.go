@@ -0,0 +1,38 @@
+package pdf_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"toko/pkg/pdf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_WritesValidStructure(t *testing.T) {
+	doc := pdf.New("Invoice (test)")
+	doc.Text(50, 50, 18, true, "Invoice")
+	doc.Line(50, 60, 545, 60)
+	doc.AddPage()
+	doc.TextRight(545, 80, 10, false, "Total: 1,200.00 (paid)")
+	body := doc.Bytes()
+
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(body, []byte("%%EOF\n")))
+	assert.Contains(t, string(body), "/Count 2")
+	// Parentheses in text are escaped so they cannot end the string early
+	assert.Contains(t, string(body), `(Total: 1,200.00 \(paid\)) Tj`)
+
+	// startxref points at the cross-reference table, and each entry at its object
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(body)
+	require.NotNil(t, match)
+	xref, _ := strconv.Atoi(string(match[1]))
+	assert.True(t, bytes.HasPrefix(body[xref:], []byte("xref\n")))
+	for i, entry := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(body, -1) {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(body[offset:], []byte(strconv.Itoa(i+1)+" 0 obj")), "object %d", i+1)
+	}
+}
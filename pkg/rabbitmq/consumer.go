@@ -0,0 +1,95 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ConsumerConfig configures Consume.
+type ConsumerConfig struct {
+	Exchange    string
+	Queue       string // Durable queue bound to RoutingKeys on Exchange
+	RoutingKeys []string
+	Concurrency int // Deliveries handled at once, which is also the prefetch; defaults to 1
+	Retry       RetryPolicy
+}
+
+// Consume handles the deliveries of a queue with up to cfg.Concurrency
+// handlers at once, acknowledging each once its handler returns. The handler
+// gets the queue name as its tier. Consume blocks until the connection fails,
+// or until ctx is cancelled, after which it takes no new deliveries and
+// returns once those already received are handled.
+func (c *Client) Consume(ctx context.Context, cfg ConsumerConfig, handler DeliveryHandler) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(cfg.Exchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare an exchange: %w", err)
+	}
+	if cfg.Retry.Enabled() {
+		if err := DeclareRetryTopology(ch, cfg.Queue, cfg.Retry); err != nil {
+			return err
+		}
+	} else if _, err := ch.QueueDeclare(cfg.Queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", cfg.Queue, err)
+	}
+	for _, key := range cfg.RoutingKeys {
+		if err := ch.QueueBind(cfg.Queue, key, cfg.Exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind %s to %s: %w", key, cfg.Queue, err)
+		}
+	}
+
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if err := ch.Qos(concurrency, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch for %s: %w", cfg.Queue, err)
+	}
+	consumerTag := "toko-" + uuid.New().String()
+	msgs, err := ch.Consume(cfg.Queue, consumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", cfg.Queue, err)
+	}
+
+	tier := Tier{Name: cfg.Queue, Queue: cfg.Queue}
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range msgs {
+				dispatch(ch, cfg.Retry, tier, d, handler)
+			}
+		}()
+	}
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	log.Printf(" [*] Consuming %s from exchange %s with %d handlers", cfg.Queue, cfg.Exchange, concurrency)
+	select {
+	case <-ctx.Done():
+		// The broker stops sending; msgs closes once the deliveries it holds are taken
+		if err := ch.Cancel(consumerTag, false); err != nil {
+			log.Printf(" [ERROR] Failed to cancel consumer of %s: %v", cfg.Queue, err)
+		}
+		<-drained
+		return ctx.Err()
+	case <-drained:
+		return fmt.Errorf("delivery channel for %s closed", cfg.Queue)
+	}
+}
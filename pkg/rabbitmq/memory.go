@@ -0,0 +1,280 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/streadway/amqp"
+)
+
+// Message is a message published to a MemoryBroker.
+type Message struct {
+	Exchange   string
+	RoutingKey string
+	Body       []byte
+	Headers    amqp.Table
+	MessageID  string
+	Timestamp  time.Time
+}
+
+// memoryConsumer is a consumer registered with a MemoryBroker and the
+// messages waiting for it.
+type memoryConsumer struct {
+	cfg      ConsumerConfig
+	handler  DeliveryHandler
+	pending  []memoryDelivery
+	notify   chan struct{} // Signalled when a message is queued; nil unless consuming in the background
+	draining sync.Mutex    // Held while the consumer's messages are handled
+}
+
+// memoryDelivery is a queued message and how often it was handled before.
+type memoryDelivery struct {
+	msg      Message
+	attempts int
+}
+
+// MemoryBroker is a broker kept in memory, for tests and for running
+// locally without RabbitMQ. It records every published message and routes
+// it to the consumers bound to its exchange and routing key at the time.
+// Consumers registered with Subscribe are driven by calling Drain; those
+// running Consume handle their messages in the background.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	published []Message
+	consumers []*memoryConsumer
+	parked    []Message
+}
+
+// NewMemoryBroker creates an empty MemoryBroker.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// PublishWithHeaders records the message and queues it for the consumers
+// bound to it. It never fails.
+func (b *MemoryBroker) PublishWithHeaders(exchange, routingKey string, body []byte, headers amqp.Table) error {
+	msg := Message{
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		Body:       append([]byte(nil), body...),
+		Headers:    amqp.Table{},
+		MessageID:  uuid.New().String(),
+		Timestamp:  time.Now(),
+	}
+	for key, value := range headers {
+		msg.Headers[key] = value
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, msg)
+	for _, consumer := range b.consumers {
+		if !consumer.binds(exchange, routingKey) {
+			continue
+		}
+		consumer.pending = append(consumer.pending, memoryDelivery{msg: msg})
+		if consumer.notify != nil {
+			select {
+			case consumer.notify <- struct{}{}:
+			default: // A wakeup is already pending
+			}
+		}
+	}
+	return nil
+}
+
+// Published returns the messages published with the routing key, oldest
+// first, or every message when routingKey is empty.
+func (b *MemoryBroker) Published(routingKey string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	messages := []Message{}
+	for _, msg := range b.published {
+		if routingKey == "" || msg.RoutingKey == routingKey {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// Parked returns the messages consumers gave up on after their retry
+// policy's MaxAttempts.
+func (b *MemoryBroker) Parked() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message{}, b.parked...)
+}
+
+// Reset forgets the published and parked messages and drops those waiting
+// for consumers.
+func (b *MemoryBroker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published, b.parked = nil, nil
+	for _, consumer := range b.consumers {
+		consumer.pending = nil
+	}
+}
+
+// Subscribe registers handler for the messages published to cfg.Exchange
+// with one of cfg.RoutingKeys from now on. They wait until Drain is called.
+// The returned function removes the consumer.
+func (b *MemoryBroker) Subscribe(cfg ConsumerConfig, handler DeliveryHandler) (unsubscribe func()) {
+	return b.subscribe(&memoryConsumer{cfg: cfg, handler: handler})
+}
+
+// Drain handles the messages waiting for the subscribed consumers in the
+// calling goroutine, round after round for as long as some succeed, since
+// handlers may publish more. Failed messages are tried again in the next
+// round or Drain, and parked once the consumer's retry policy gives up on
+// them. It returns how many messages were handled successfully, and the
+// handlers' errors.
+func (b *MemoryBroker) Drain() (int, error) {
+	b.mu.Lock()
+	consumers := append([]*memoryConsumer{}, b.consumers...)
+	b.mu.Unlock()
+
+	handled := 0
+	var errs []error
+	for {
+		progressed := false
+		for _, consumer := range consumers {
+			n, failed := b.drainConsumer(consumer)
+			handled += n
+			errs = append(errs, failed...)
+			progressed = progressed || n > 0
+		}
+		if !progressed {
+			return handled, errors.Join(errs...)
+		}
+	}
+}
+
+// Consume handles the messages for cfg in the background until ctx is
+// cancelled, so the broker can stand in for RabbitMQ in a running app.
+// Failed messages are retried after cfg.Retry.Delay.
+func (b *MemoryBroker) Consume(ctx context.Context, cfg ConsumerConfig, handler DeliveryHandler) error {
+	consumer := &memoryConsumer{cfg: cfg, handler: handler, notify: make(chan struct{}, 1)}
+	unsubscribe := b.subscribe(consumer)
+	defer unsubscribe()
+
+	retryDelay := cfg.Retry.Delay
+	if retryDelay <= 0 {
+		retryDelay = time.Second
+	}
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-consumer.notify:
+		case <-retry:
+		}
+		retry = nil
+		if _, failed := b.drainConsumer(consumer); len(failed) > 0 {
+			retry = time.After(retryDelay)
+		}
+	}
+}
+
+func (b *MemoryBroker) subscribe(consumer *memoryConsumer) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consumers = append(b.consumers, consumer)
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, c := range b.consumers {
+			if c == consumer {
+				b.consumers = append(b.consumers[:i], b.consumers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// drainConsumer handles each message queued for the consumer once. Messages
+// published meanwhile wait for the next call.
+func (b *MemoryBroker) drainConsumer(consumer *memoryConsumer) (int, []error) {
+	consumer.draining.Lock()
+	defer consumer.draining.Unlock()
+
+	b.mu.Lock()
+	deliveries := consumer.pending
+	consumer.pending = nil
+	b.mu.Unlock()
+
+	handled := 0
+	var errs []error
+	var retries []memoryDelivery
+	for _, delivery := range deliveries {
+		delivery.attempts++
+		err := consumer.handler(consumer.cfg.Queue, delivery.amqp())
+		if err == nil {
+			handled++
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s message %s: %w", delivery.msg.RoutingKey, delivery.msg.MessageID, err))
+		if errors.Is(err, ErrRequeue) {
+			delivery.attempts-- // Not an attempt; tried again as it is
+			retries = append(retries, delivery)
+			continue
+		}
+		if consumer.cfg.Retry.Enabled() && delivery.attempts >= consumer.cfg.Retry.MaxAttempts {
+			parked := delivery.msg
+			parked.Headers = amqp.Table{ParkedReasonHeader: err.Error()}
+			for key, value := range delivery.msg.Headers {
+				parked.Headers[key] = value
+			}
+			b.mu.Lock()
+			b.parked = append(b.parked, parked)
+			b.mu.Unlock()
+			continue
+		}
+		retries = append(retries, delivery)
+	}
+
+	if len(retries) > 0 {
+		b.mu.Lock()
+		consumer.pending = append(retries, consumer.pending...)
+		b.mu.Unlock()
+	}
+	return handled, errs
+}
+
+// binds reports whether the consumer receives messages published to the
+// exchange with the routing key.
+func (c *memoryConsumer) binds(exchange, routingKey string) bool {
+	if c.cfg.Exchange != exchange {
+		return false
+	}
+	for _, key := range c.cfg.RoutingKeys {
+		if key == routingKey {
+			return true
+		}
+	}
+	return false
+}
+
+// amqp returns the delivery as a consumer of RabbitMQ would receive it.
+// Acknowledgements are not needed, so the delivery has no acknowledger.
+func (d memoryDelivery) amqp() amqp.Delivery {
+	headers := amqp.Table{}
+	for key, value := range d.msg.Headers {
+		headers[key] = value
+	}
+	return amqp.Delivery{
+		Headers:     headers,
+		ContentType: "application/json",
+		MessageId:   d.msg.MessageID,
+		Timestamp:   d.msg.Timestamp,
+		Exchange:    d.msg.Exchange,
+		RoutingKey:  d.msg.RoutingKey,
+		Redelivered: d.attempts > 1,
+		Body:        d.msg.Body,
+	}
+}
@@ -0,0 +1,114 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBroker_RecordsAndDrains(t *testing.T) {
+	broker := rabbitmq.NewMemoryBroker()
+	var received []string
+	unsubscribe := broker.Subscribe(rabbitmq.ConsumerConfig{
+		Exchange:    "order",
+		Queue:       "order_events.test",
+		RoutingKeys: []string{"order.created"},
+	}, func(tier string, d amqp.Delivery) error {
+		assert.Equal(t, "order_events.test", tier)
+		assert.NotEmpty(t, d.MessageId)
+		received = append(received, string(d.Body))
+		// Handlers publishing further events are driven by the same Drain
+		return broker.PublishWithHeaders("order", "order.status_updated", d.Body, nil)
+	})
+
+	require.NoError(t, broker.PublishWithHeaders("order", "order.created", []byte(`{"orderID":"order-1"}`), amqp.Table{"schema_version": int32(1)}))
+	require.NoError(t, broker.PublishWithHeaders("product", "order.created", []byte(`{}`), nil))
+	assert.Empty(t, received, "consumers only run when drained")
+
+	handled, err := broker.Drain()
+	require.NoError(t, err)
+	assert.Equal(t, 1, handled)
+	assert.Equal(t, []string{`{"orderID":"order-1"}`}, received)
+	created := broker.Published("order.created")
+	require.Len(t, created, 2)
+	assert.Equal(t, int32(1), created[0].Headers["schema_version"])
+	assert.Len(t, broker.Published("order.status_updated"), 1)
+	assert.Len(t, broker.Published(""), 3)
+
+	unsubscribe()
+	require.NoError(t, broker.PublishWithHeaders("order", "order.created", []byte(`{}`), nil))
+	handled, err = broker.Drain()
+	require.NoError(t, err)
+	assert.Zero(t, handled)
+
+	broker.Reset()
+	assert.Empty(t, broker.Published(""))
+}
+
+func TestMemoryBroker_ParksAfterMaxAttempts(t *testing.T) {
+	broker := rabbitmq.NewMemoryBroker()
+	attempts := 0
+	broker.Subscribe(rabbitmq.ConsumerConfig{
+		Exchange:    "order",
+		Queue:       "order_events.test",
+		RoutingKeys: []string{"order.created"},
+		Retry:       rabbitmq.RetryPolicy{MaxAttempts: 2},
+	}, func(tier string, d amqp.Delivery) error {
+		attempts++
+		assert.Equal(t, attempts > 1, d.Redelivered)
+		return errors.New("smtp unavailable")
+	})
+	require.NoError(t, broker.PublishWithHeaders("order", "order.created", []byte(`{}`), nil))
+
+	_, err := broker.Drain()
+	assert.ErrorContains(t, err, "smtp unavailable")
+	assert.Empty(t, broker.Parked())
+	_, err = broker.Drain()
+	assert.Error(t, err)
+	parked := broker.Parked()
+	require.Len(t, parked, 1)
+	assert.Equal(t, "smtp unavailable", parked[0].Headers[rabbitmq.ParkedReasonHeader])
+
+	handled, err := broker.Drain()
+	assert.NoError(t, err)
+	assert.Zero(t, handled)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestMemoryBroker_Consume(t *testing.T) {
+	broker := rabbitmq.NewMemoryBroker()
+	received := make(chan string, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- broker.Consume(ctx, rabbitmq.ConsumerConfig{
+			Exchange:    "order",
+			Queue:       "order_events.app",
+			RoutingKeys: []string{"order.created"},
+		}, func(tier string, d amqp.Delivery) error {
+			received <- string(d.Body)
+			return nil
+		})
+	}()
+
+	// The consumer is registered once Consume runs
+	require.Eventually(t, func() bool {
+		_ = broker.PublishWithHeaders("order", "order.created", []byte(`{"orderID":"order-1"}`), nil)
+		select {
+		case body := <-received:
+			return body == `{"orderID":"order-1"}`
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,154 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// ErrNotConnected is returned when the broker cannot be reached.
+var ErrNotConnected = errors.New("not connected to RabbitMQ")
+
+// OutboxMessage is a message kept in the outbox until the broker takes it.
+type OutboxMessage struct {
+	ID         uint
+	Exchange   string
+	RoutingKey string
+	// MessageID is assigned when the message is first published and kept
+	// for every attempt, so consumers recognise it if it is published twice
+	MessageID string
+	Body      []byte
+	Headers   []byte // JSON object of the AMQP headers
+}
+
+// Publishing returns the message as it is published to the broker.
+func (m OutboxMessage) Publishing() (amqp.Publishing, error) {
+	headers, err := decodeHeaders(m.Headers)
+	if err != nil {
+		return amqp.Publishing{}, err
+	}
+	messageID := m.MessageID
+	if messageID == "" {
+		// Kept before message IDs were stored; still the same on every attempt
+		messageID = fmt.Sprintf("outbox-%d", m.ID)
+	}
+	return amqp.Publishing{
+		ContentType: "application/json",
+		MessageId:   messageID,
+		Timestamp:   time.Now(),
+		Headers:     headers,
+		Body:        m.Body,
+	}, nil
+}
+
+// Outbox durably stores messages while the broker is unreachable.
+type Outbox interface {
+	Add(msg OutboxMessage) error
+	// Pending returns the oldest messages first.
+	Pending(limit int) ([]OutboxMessage, error)
+	// Remove deletes a message once it is published.
+	Remove(id uint) error
+	Count() (int64, error)
+}
+
+// flushBatchSize is how many outbox messages Flush reads at a time.
+const flushBatchSize = 100
+
+// Flush reconnects to the broker if needed and publishes the messages
+// waiting in the outbox, oldest first. It returns how many were published
+// and stops at the first message the broker does not take. While the broker
+// is unreachable the error wraps ErrNotConnected.
+func (c *Client) Flush() (int, error) {
+	if c.outbox == nil {
+		return 0, nil
+	}
+	if !c.Connected() {
+		if err := c.connect(); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrNotConnected, err)
+		}
+		log.Println("RabbitMQ is reachable again, publishing the outbox")
+	}
+
+	published := 0
+	for {
+		messages, err := c.outbox.Pending(flushBatchSize)
+		if err != nil {
+			return published, err
+		}
+		if len(messages) == 0 {
+			c.backlog.Store(false)
+			return published, nil
+		}
+		for _, msg := range messages {
+			publishing, err := msg.Publishing()
+			if err != nil {
+				// A message that can never be published must not block the ones after it
+				log.Printf("Error: Dropping outbox message %d with unreadable headers: %v", msg.ID, err)
+			} else if err := c.publish(msg.Exchange, msg.RoutingKey, publishing); err != nil {
+				return published, err
+			}
+			if err := c.outbox.Remove(msg.ID); err != nil {
+				return published, err
+			}
+			published++
+		}
+	}
+}
+
+// PendingMessages returns how many messages wait in the outbox.
+func (c *Client) PendingMessages() (int64, error) {
+	if c.outbox == nil {
+		return 0, nil
+	}
+	return c.outbox.Count()
+}
+
+func (c *Client) enqueue(exchange, routingKey string, msg amqp.Publishing) error {
+	encoded, err := json.Marshal(msg.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode headers of %s: %w", routingKey, err)
+	}
+	c.backlog.Store(true)
+	err = c.outbox.Add(OutboxMessage{
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		MessageID:  msg.MessageId,
+		Body:       msg.Body,
+		Headers:    encoded,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to keep %s in the outbox: %w", routingKey, err)
+	}
+	return nil
+}
+
+// decodeHeaders restores headers written by enqueue. JSON has no integer
+// type, so whole numbers come back as int64, which AMQP tables support.
+func decodeHeaders(data []byte) (amqp.Table, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	headers := amqp.Table{}
+	for key, value := range raw {
+		if number, ok := value.(json.Number); ok {
+			if i, err := number.Int64(); err == nil {
+				value = i
+			} else if f, err := number.Float64(); err == nil {
+				value = f
+			}
+		}
+		headers[key] = value
+	}
+	return headers, nil
+}
@@ -0,0 +1,192 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/streadway/amqp"
+)
+
+// Tier is one priority level of a PriorityConsumer. Each tier has its own
+// durable queue bound to the routing keys it is responsible for.
+type Tier struct {
+	Name        string
+	Queue       string
+	RoutingKeys []string
+	// Weight is the number of messages taken from this tier per scheduling
+	// round while it has a backlog. Tiers listed first are served first.
+	Weight int
+}
+
+// PriorityConfig configures ConsumePriority.
+type PriorityConfig struct {
+	Exchange string
+	Tiers    []Tier // Highest priority first
+	Prefetch int    // Unacknowledged messages per tier; defaults to the tier's weight
+	// Retry sends failed messages through each tier's retry queue and parks
+	// them after too many attempts; when disabled they are requeued at once
+	Retry RetryPolicy
+}
+
+// DefaultOrderTiers splits order events so that payment outcomes and
+// cancellations are never starved by bulk analytics traffic.
+var DefaultOrderTiers = []Tier{
+	{
+		Name:        "critical",
+		Queue:       "order_events.critical",
+		RoutingKeys: []string{"order.payment_confirmed", "order.payment_failed", "order.cancelled"},
+		Weight:      6,
+	},
+	{
+		Name:        "standard",
+		Queue:       "order_events.standard",
+		RoutingKeys: []string{"order.created", "order.receipt_requested", "order.status_updated", "order.item_status_updated", "order.reservation_expired"},
+		Weight:      3,
+	},
+	{
+		Name:        "bulk",
+		Queue:       "order_events.bulk",
+		RoutingKeys: []string{"order.analytics"},
+		Weight:      1,
+	},
+}
+
+// DeliveryHandler processes one message of the named tier. Returning an error
+// requeues the message, or retries it according to the consumer's policy;
+// errors wrapping ErrRequeue always requeue it.
+type DeliveryHandler func(tier string, d amqp.Delivery) error
+
+// ConsumePriority consumes every tier's queue with weighted round robin:
+// each round takes up to Weight messages from each tier in priority order, so
+// higher tiers get a larger share under load and lower tiers still progress.
+// It blocks until ctx is cancelled or the connection fails.
+func (c *Client) ConsumePriority(ctx context.Context, cfg PriorityConfig, handler DeliveryHandler) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(cfg.Exchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare an exchange: %w", err)
+	}
+
+	deliveries := make([]<-chan amqp.Delivery, len(cfg.Tiers))
+	for i, tier := range cfg.Tiers {
+		if tier.Weight < 1 {
+			return fmt.Errorf("tier %s must have a positive weight", tier.Name)
+		}
+		if cfg.Retry.Enabled() {
+			if err := DeclareRetryTopology(ch, tier.Queue, cfg.Retry); err != nil {
+				return err
+			}
+		} else if _, err := ch.QueueDeclare(tier.Queue, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", tier.Queue, err)
+		}
+		for _, key := range tier.RoutingKeys {
+			if err := ch.QueueBind(tier.Queue, key, cfg.Exchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind %s to %s: %w", key, tier.Queue, err)
+			}
+		}
+
+		// A separate channel per tier gives each queue its own prefetch window
+		tierCh, err := conn.Channel()
+		if err != nil {
+			return fmt.Errorf("failed to open a channel: %w", err)
+		}
+		defer tierCh.Close()
+		prefetch := cfg.Prefetch
+		if prefetch <= 0 {
+			prefetch = tier.Weight
+		}
+		if err := tierCh.Qos(prefetch, 0, false); err != nil {
+			return fmt.Errorf("failed to set prefetch for %s: %w", tier.Queue, err)
+		}
+		msgs, err := tierCh.Consume(tier.Queue, "", false, false, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("failed to consume %s: %w", tier.Queue, err)
+		}
+		deliveries[i] = msgs
+	}
+
+	log.Printf(" [*] Consuming %d priority tiers from exchange %s", len(cfg.Tiers), cfg.Exchange)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		handled := 0
+		for i, tier := range cfg.Tiers {
+			for n := 0; n < tier.Weight; n++ {
+				select {
+				case d, ok := <-deliveries[i]:
+					if !ok {
+						return fmt.Errorf("delivery channel for %s closed", tier.Queue)
+					}
+					dispatch(ch, cfg.Retry, tier, d, handler)
+					handled++
+					continue
+				default:
+				}
+				break // Tier has no backlog; move on to the next one
+			}
+		}
+		if handled > 0 {
+			continue
+		}
+
+		// Nothing is waiting in any tier: block until something arrives
+		tier, d, err := waitForDelivery(ctx, cfg.Tiers, deliveries)
+		if err != nil {
+			return err
+		}
+		dispatch(ch, cfg.Retry, tier, d, handler)
+	}
+}
+
+// waitForDelivery blocks until any tier receives a message or ctx is done.
+func waitForDelivery(ctx context.Context, tiers []Tier, deliveries []<-chan amqp.Delivery) (Tier, amqp.Delivery, error) {
+	cases := make([]reflect.SelectCase, 0, len(deliveries)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	for _, msgs := range deliveries {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(msgs)})
+	}
+	chosen, value, ok := reflect.Select(cases)
+	if chosen == 0 {
+		return Tier{}, amqp.Delivery{}, ctx.Err()
+	}
+	tier := tiers[chosen-1]
+	if !ok {
+		return Tier{}, amqp.Delivery{}, fmt.Errorf("delivery channel for %s closed", tier.Queue)
+	}
+	return tier, value.Interface().(amqp.Delivery), nil
+}
+
+// dispatch runs the handler and acknowledges the delivery accordingly.
+// Failed deliveries go through the tier's retry queue when policy is
+// enabled, publishing parked messages on ch.
+func dispatch(ch *amqp.Channel, policy RetryPolicy, tier Tier, d amqp.Delivery, handler DeliveryHandler) {
+	if err := handler(tier.Name, d); err != nil {
+		log.Printf(" [ERROR] Failed to handle %s message %s: %v", tier.Name, d.RoutingKey, err)
+		if policy.Enabled() && !errors.Is(err, ErrRequeue) {
+			retryErr := retry(ch, tier.Queue, policy, d, err)
+			if retryErr == nil {
+				return
+			}
+			log.Printf(" [ERROR] Failed to retry message: %v", retryErr)
+		}
+		if nackErr := d.Nack(false, true); nackErr != nil {
+			log.Printf(" [ERROR] Failed to requeue message: %v", nackErr)
+		}
+		return
+	}
+	if err := d.Ack(false); err != nil {
+		log.Printf(" [ERROR] Failed to acknowledge message: %v", err)
+	}
+}
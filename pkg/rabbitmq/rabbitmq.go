@@ -4,16 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/streadway/amqp"
 )
 
 // Client holds the RabbitMQ connection and channel.
 type Client struct {
+	url     string
+	mu      sync.RWMutex
 	conn    *amqp.Connection
 	channel *amqp.Channel
-	// Add mutex for channel if multiple goroutines access it concurrently without proper synchronization
-	// mu sync.Mutex
+	// outbox keeps messages the broker could not take; nil drops them with an error
+	outbox  Outbox
+	backlog atomic.Bool // The outbox holds messages not yet published
 }
 
 // Config holds RabbitMQ connection details.
@@ -24,15 +31,39 @@ type Config struct {
 // NewClient creates a new RabbitMQ client.
 // It connects to RabbitMQ and sets up a channel.
 func NewClient(cfg Config) (*Client, error) {
-	conn, err := amqp.Dial(cfg.URL)
+	c := &Client{url: cfg.URL}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewResilientClient creates a client that keeps working while the broker is
+// unreachable: messages it cannot publish are written to the outbox and
+// published by Flush once the broker is back. It never fails, starting
+// disconnected when the broker cannot be reached.
+func NewResilientClient(cfg Config, outbox Outbox) *Client {
+	c := &Client{url: cfg.URL, outbox: outbox}
+	if pending, err := outbox.Count(); err != nil || pending > 0 {
+		c.backlog.Store(true)
+	}
+	if err := c.connect(); err != nil {
+		log.Printf("Warning: RabbitMQ is unreachable, events are kept in the outbox until it is back: %v", err)
+	}
+	return c
+}
+
+// connect dials the broker and replaces any previous connection.
+func (c *Client) connect() error {
+	conn, err := amqp.Dial(c.url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
 	ch, err := conn.Channel()
 	if err != nil {
 		conn.Close() // Close connection if channel creation fails
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+		return fmt.Errorf("failed to open channel: %w", err)
 	}
 
 	// Declare the order queue upfront. In a real app, you might want to do this
@@ -48,19 +79,40 @@ func NewClient(cfg Config) (*Client, error) {
 	if err != nil {
 		ch.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to declare order_queue: %w", err)
+		return fmt.Errorf("failed to declare order_queue: %w", err)
 	}
 
 	log.Println("RabbitMQ client connected and order_queue declared.")
 
-	return &Client{
-		conn:    conn,
-		channel: ch,
-	}, nil
+	c.mu.Lock()
+	if c.conn != nil {
+		c.conn.Close() // The previous connection was lost; this only releases it
+	}
+	c.conn, c.channel = conn, ch
+	c.mu.Unlock()
+	return nil
+}
+
+// connection returns the open connection, or an error when the client is disconnected.
+func (c *Client) connection() (*amqp.Connection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.conn == nil || c.conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+	return c.conn, nil
+}
+
+// Connected reports whether the client has an open connection to the broker.
+func (c *Client) Connected() bool {
+	_, err := c.connection()
+	return err == nil
 }
 
 // Close closes the RabbitMQ connection and channel.
 func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	var errs []error
 	if c.channel != nil {
 		if err := c.channel.Close(); err != nil {
@@ -80,7 +132,38 @@ func (c *Client) Close() error {
 
 // Publish publishes a message to rabbitmq
 func (c *Client) Publish(exchange, routingKey string, body []byte) error {
-	ch, err := c.conn.Channel()
+	return c.PublishWithHeaders(exchange, routingKey, body, nil)
+}
+
+// PublishWithHeaders publishes a message with additional AMQP headers. When
+// the broker is unreachable, or older messages are still waiting in the
+// outbox, the message is written to the outbox instead so that it is
+// published later and in order.
+func (c *Client) PublishWithHeaders(exchange, routingKey string, body []byte, headers amqp.Table) error {
+	msg := amqp.Publishing{
+		ContentType: "application/json",
+		MessageId:   uuid.New().String(), // Lets consumers detect redeliveries
+		Timestamp:   time.Now(),
+		Headers:     headers,
+		Body:        body,
+	}
+	if c.outbox != nil && c.backlog.Load() {
+		return c.enqueue(exchange, routingKey, msg)
+	}
+	err := c.publish(exchange, routingKey, msg)
+	if err != nil && c.outbox != nil {
+		log.Printf("Warning: Failed to publish %s, keeping it in the outbox: %v", routingKey, err)
+		return c.enqueue(exchange, routingKey, msg)
+	}
+	return err
+}
+
+func (c *Client) publish(exchange, routingKey string, msg amqp.Publishing) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+	ch, err := conn.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open a channel: %w", err)
 	}
@@ -104,10 +187,7 @@ func (c *Client) Publish(exchange, routingKey string, body []byte) error {
 		routingKey, // routing key
 		false,      // mandatory
 		false,      // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
+		msg)
 	if err != nil {
 		return fmt.Errorf("failed to publish a message: %w", err)
 	}
@@ -115,56 +195,6 @@ func (c *Client) Publish(exchange, routingKey string, body []byte) error {
 	return nil
 }
 
-// ConsumeOrderEvents consumes order events
-func (c *Client) ConsumeOrderEvents(messageHandler func(amqp.Delivery) error) error {
-	ch, err := c.conn.Channel()
-	if err != nil {
-		return fmt.Errorf("failed to open a channel: %w", err)
-	}
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		"orders", // name
-		false,    // durable
-		false,    // delete when unused
-		false,    // exclusive
-		false,    // no-wait
-		nil,      // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to declare a queue: %w", err)
-	}
-
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register a consumer: %w", err)
-	}
-
-	forever := make(chan bool)
-
-	go func() {
-		for d := range msgs {
-			err := messageHandler(d)
-			if err != nil {
-				log.Printf(" [ERROR] Failed to handle message: %v", err)
-			}
-		}
-	}()
-
-	log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
-	<-forever
-
-	return nil
-}
-
 // PublishOrderCreated publishes the order created event
 func (c *Client) PublishOrderCreated(messageBody map[string]interface{}) error {
 	body, err := json.Marshal(messageBody)
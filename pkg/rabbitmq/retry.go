@@ -0,0 +1,136 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// DeadLetterExchange receives the messages consumers reject and routes them
+// to the retry or parking-lot queue of the queue they came from.
+const DeadLetterExchange = "dlx"
+
+// Suffixes of the queues DeclareRetryTopology declares next to a work queue.
+const (
+	RetryQueueSuffix   = ".retry"
+	ParkingQueueSuffix = ".parking"
+)
+
+// ParkedReasonHeader carries the error of the last attempt on parked messages.
+const ParkedReasonHeader = "x-parked-reason"
+
+// ErrRequeue is wrapped by handler errors that should put the message straight
+// back on its queue without counting an attempt, e.g. because another
+// consumer is still processing it.
+var ErrRequeue = errors.New("requeue")
+
+// RetryPolicy configures how failed deliveries are retried. A failed message
+// is dead-lettered to the retry queue of its work queue, which holds it for
+// Delay and then sends it back. After MaxAttempts deliveries it is moved to
+// the parking-lot queue instead, where it stays until someone deals with it.
+type RetryPolicy struct {
+	MaxAttempts int // 0 requeues failed messages right away, without limit
+	Delay       time.Duration
+}
+
+// Enabled reports whether failed messages go through the retry queue.
+func (p RetryPolicy) Enabled() bool {
+	return p.MaxAttempts > 0
+}
+
+// QueueArgs returns the arguments a work queue is declared with so that the
+// messages rejected from it are dead-lettered to its retry queue.
+func QueueArgs(queue string) amqp.Table {
+	return amqp.Table{
+		"x-dead-letter-exchange":    DeadLetterExchange,
+		"x-dead-letter-routing-key": queue + RetryQueueSuffix,
+	}
+}
+
+// DeclareRetryTopology declares a durable work queue along with its retry and
+// parking-lot queues and the dead-letter exchange they are bound to. Expired
+// messages of the retry queue go back to the work queue through the default
+// exchange. RabbitMQ refuses to redeclare a queue with other arguments, so a
+// work queue declared before retries were enabled has to be deleted first.
+func DeclareRetryTopology(ch *amqp.Channel, queue string, policy RetryPolicy) error {
+	if err := ch.ExchangeDeclare(DeadLetterExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, QueueArgs(queue)); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+
+	retryQueue := queue + RetryQueueSuffix
+	_, err := ch.QueueDeclare(retryQueue, true, false, false, false, amqp.Table{
+		"x-message-ttl":             policy.Delay.Milliseconds(),
+		"x-dead-letter-exchange":    "", // The default exchange routes by queue name
+		"x-dead-letter-routing-key": queue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", retryQueue, err)
+	}
+	if err := ch.QueueBind(retryQueue, retryQueue, DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind %s: %w", retryQueue, err)
+	}
+
+	parkingQueue := queue + ParkingQueueSuffix
+	if _, err := ch.QueueDeclare(parkingQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", parkingQueue, err)
+	}
+	if err := ch.QueueBind(parkingQueue, parkingQueue, DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind %s: %w", parkingQueue, err)
+	}
+	return nil
+}
+
+// Attempts returns how many times the delivery has been delivered from
+// queue, counting this one. RabbitMQ records each rejection in the x-death
+// header when it dead-letters the message.
+func Attempts(d amqp.Delivery, queue string) int {
+	deaths, _ := d.Headers["x-death"].([]interface{})
+	for _, death := range deaths {
+		entry, ok := death.(amqp.Table)
+		if !ok || entry["queue"] != queue || entry["reason"] != "rejected" {
+			continue
+		}
+		switch count := entry["count"].(type) {
+		case int64:
+			return int(count) + 1
+		case int32:
+			return int(count) + 1
+		}
+	}
+	return 1
+}
+
+// retry hands a failed delivery of queue to the retry queue, or to the
+// parking-lot queue once it has been attempted policy.MaxAttempts times.
+func retry(ch *amqp.Channel, queue string, policy RetryPolicy, d amqp.Delivery, cause error) error {
+	attempts := Attempts(d, queue)
+	if attempts < policy.MaxAttempts {
+		return d.Nack(false, false) // Dead-lettered to the retry queue
+	}
+	log.Printf(" [WARN] Parking %s message %s after %d attempts: %v", d.RoutingKey, d.MessageId, attempts, cause)
+
+	headers := amqp.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	headers[ParkedReasonHeader] = cause.Error()
+	err := ch.Publish(DeadLetterExchange, queue+ParkingQueueSuffix, false, false, amqp.Publishing{
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Headers:      headers,
+		Body:         d.Body,
+	})
+	if err != nil {
+		// Requeue rather than lose the message; it is parked on a later attempt
+		return fmt.Errorf("failed to park message %s: %w", d.MessageId, err)
+	}
+	return d.Ack(false)
+}
@@ -0,0 +1,27 @@
+package rabbitmq_test
+
+import (
+	"testing"
+
+	"toko/pkg/rabbitmq"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttempts(t *testing.T) {
+	assert.Equal(t, 1, rabbitmq.Attempts(amqp.Delivery{}, "order_events.standard"))
+
+	retried := amqp.Delivery{Headers: amqp.Table{"x-death": []interface{}{
+		amqp.Table{"queue": "order_events.standard.retry", "reason": "expired", "count": int64(2)},
+		amqp.Table{"queue": "order_events.standard", "reason": "rejected", "count": int64(2)},
+	}}}
+	assert.Equal(t, 3, rabbitmq.Attempts(retried, "order_events.standard"))
+	assert.Equal(t, 1, rabbitmq.Attempts(retried, "order_events.critical"), "rejections from other queues do not count")
+}
+
+func TestQueueArgs(t *testing.T) {
+	args := rabbitmq.QueueArgs("order_events.critical")
+	assert.Equal(t, rabbitmq.DeadLetterExchange, args["x-dead-letter-exchange"])
+	assert.Equal(t, "order_events.critical"+rabbitmq.RetryQueueSuffix, args["x-dead-letter-routing-key"])
+}
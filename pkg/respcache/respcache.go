@@ -0,0 +1,82 @@
+package respcache
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a cached HTTP response.
+type Entry struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// Store keeps cached entries and the generation counter of every tag.
+type Store interface {
+	// Get returns the entry stored under key, or nil when there is none.
+	Get(key string) (*Entry, error)
+	// Set stores entry under key until ttl elapses.
+	Set(key string, entry *Entry, ttl time.Duration) error
+	// Generation returns the current generation of tag, zero if it was never bumped.
+	Generation(tag string) (int64, error)
+	// Bump advances the generation of tag.
+	Bump(tag string) error
+}
+
+// Cache stores responses under keys that embed the generation of their tags.
+// Invalidating a tag bumps its generation, so every response cached under the
+// old generation stops matching and simply expires.
+type Cache struct {
+	store Store
+}
+
+// New creates a new Cache backed by store.
+func New(store Store) *Cache {
+	return &Cache{store: store}
+}
+
+// Lookup returns the versioned key for base and tags, and the entry cached
+// under it if any. The key must be passed to Save so that a response rendered
+// while a tag was being invalidated is not stored under the new generation.
+func (c *Cache) Lookup(base string, tags ...string) (string, *Entry, error) {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	b.WriteString(base)
+	for _, tag := range sorted {
+		generation, err := c.store.Generation(tag)
+		if err != nil {
+			return "", nil, err
+		}
+		b.WriteString("|")
+		b.WriteString(tag)
+		b.WriteString("=")
+		b.WriteString(strconv.FormatInt(generation, 10))
+	}
+	key := b.String()
+
+	entry, err := c.store.Get(key)
+	if err != nil {
+		return key, nil, err
+	}
+	return key, entry, nil
+}
+
+// Save caches entry under a key returned by Lookup.
+func (c *Cache) Save(key string, entry *Entry, ttl time.Duration) error {
+	return c.store.Set(key, entry, ttl)
+}
+
+// Invalidate drops every response cached with any of the tags.
+func (c *Cache) Invalidate(tags ...string) {
+	for _, tag := range tags {
+		if err := c.store.Bump(tag); err != nil {
+			log.Printf("Error invalidating response cache tag %s: %v", tag, err)
+		}
+	}
+}
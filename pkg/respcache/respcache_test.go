@@ -0,0 +1,63 @@
+package respcache_test
+
+import (
+	"testing"
+	"time"
+
+	"toko/pkg/respcache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_LookupSaveInvalidate(t *testing.T) {
+	cache := respcache.New(respcache.NewMemoryStore(10))
+	entry := &respcache.Entry{Status: 200, ContentType: "application/json", Body: []byte(`[{"id":"1"}]`)}
+
+	// Nothing cached yet
+	key, cached, err := cache.Lookup("/api/v1/categories", "categories")
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+
+	assert.NoError(t, cache.Save(key, entry, time.Minute))
+	_, cached, err = cache.Lookup("/api/v1/categories", "categories")
+	assert.NoError(t, err)
+	assert.Equal(t, entry, cached)
+
+	// Other tags are not affected
+	cache.Invalidate("catalog")
+	_, cached, _ = cache.Lookup("/api/v1/categories", "categories")
+	assert.NotNil(t, cached)
+
+	// Invalidating the tag drops the response
+	cache.Invalidate("categories")
+	newKey, cached, err := cache.Lookup("/api/v1/categories", "categories")
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+	assert.NotEqual(t, key, newKey)
+
+	// A response rendered before the invalidation is saved under the old key and never served
+	assert.NoError(t, cache.Save(key, entry, time.Minute))
+	_, cached, _ = cache.Lookup("/api/v1/categories", "categories")
+	assert.Nil(t, cached)
+}
+
+func TestMemoryStore_ExpiryAndLimit(t *testing.T) {
+	store := respcache.NewMemoryStore(1)
+	entry := &respcache.Entry{Status: 200, Body: []byte("ok")}
+
+	assert.NoError(t, store.Set("a", entry, 20*time.Millisecond))
+	cached, _ := store.Get("a")
+	assert.NotNil(t, cached)
+
+	// The store is full, so new keys are not cached until "a" expires
+	assert.NoError(t, store.Set("b", entry, time.Minute))
+	cached, _ = store.Get("b")
+	assert.Nil(t, cached)
+
+	time.Sleep(30 * time.Millisecond)
+	cached, _ = store.Get("a")
+	assert.Nil(t, cached)
+	assert.NoError(t, store.Set("b", entry, time.Minute))
+	cached, _ = store.Get("b")
+	assert.NotNil(t, cached)
+}
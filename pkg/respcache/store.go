@@ -0,0 +1,154 @@
+package respcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultMaxEntries bounds the memory store when no limit is given.
+const defaultMaxEntries = 10000
+
+type memoryEntry struct {
+	entry     *Entry
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process Store. Invalidations only reach the instance
+// they happen on, so it is only suitable for a single API instance.
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]memoryEntry
+	generations map[string]int64
+	maxEntries  int
+	now         func() time.Time
+}
+
+// NewMemoryStore creates a new MemoryStore holding at most maxEntries responses.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	return &MemoryStore{
+		entries:     make(map[string]memoryEntry),
+		generations: make(map[string]int64),
+		maxEntries:  maxEntries,
+		now:         time.Now,
+	}
+}
+
+// Get returns the entry stored under key, or nil when there is none.
+func (s *MemoryStore) Get(key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !s.now().Before(cached.expiresAt) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return cached.entry, nil
+}
+
+// Set stores entry under key until ttl elapses. When the store is full and
+// nothing has expired yet, the entry is not cached.
+func (s *MemoryStore) Set(key string, entry *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		for k, cached := range s.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		if len(s.entries) >= s.maxEntries {
+			return nil
+		}
+	}
+	s.entries[key] = memoryEntry{entry: entry, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Generation returns the current generation of tag.
+func (s *MemoryStore) Generation(tag string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[tag], nil
+}
+
+// Bump advances the generation of tag.
+func (s *MemoryStore) Bump(tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generations[tag]++
+	return nil
+}
+
+// RedisStore is a Store shared by all instances using the same Redis.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a new RedisStore.
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Get returns the entry stored under key, or nil when there is none.
+func (s *RedisStore) Get(key string) (*Entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := s.client.Get(ctx, "toko:respcache:entry:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Set stores entry under key until ttl elapses.
+func (s *RedisStore) Set(key string, entry *Entry, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, "toko:respcache:entry:"+key, data, ttl).Err()
+}
+
+// Generation returns the current generation of tag.
+func (s *RedisStore) Generation(tag string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	generation, err := s.client.Get(ctx, "toko:respcache:tag:"+tag).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Bump advances the generation of tag.
+func (s *RedisStore) Bump(tag string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.client.Incr(ctx, "toko:respcache:tag:"+tag).Err()
+}
@@ -0,0 +1,353 @@
+package sdkgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// goInitialisms are written in capitals in Go names, as in ProductID.
+var goInitialisms = map[string]bool{"id": true, "ip": true, "sku": true, "url": true, "api": true, "json": true}
+
+// goImports are the packages the Go client may use. Those the generated
+// code does not refer to are left out.
+var goImports = []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "net/url", "strconv", "strings", "time"}
+
+// goName turns a snake_case or camelCase name into an exported Go name.
+func goName(name string) string {
+	var words []string
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r):
+			flush()
+			word = append(word, unicode.ToLower(r))
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if goInitialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// goParamName turns a parameter name into an unexported Go identifier.
+func goParamName(name string) string {
+	exported := goName(name)
+	if exported == strings.ToUpper(exported) {
+		return strings.ToLower(exported)
+	}
+	return strings.ToLower(exported[:1]) + exported[1:]
+}
+
+// goType returns the Go type of a schema. Optional values of object and
+// time types are pointers so they can be left out.
+func goType(s *Schema, optional bool) string {
+	if s == nil {
+		return "json.RawMessage"
+	}
+	var typ string
+	switch {
+	case s.Ref != "":
+		typ = goName(refName(s.Ref))
+	case s.Type == "array":
+		return "[]" + goType(s.Items, false)
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "float64"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "string" && s.Format == "date-time":
+		typ = "time.Time"
+	case s.Type == "string":
+		return "string"
+	default:
+		return "json.RawMessage"
+	}
+	if optional {
+		return "*" + typ
+	}
+	return typ
+}
+
+// goComment writes text as a Go comment with the given indentation.
+func goComment(b *bytes.Buffer, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(b, "%s\n", strings.TrimRight(indent+"// "+strings.TrimSpace(line), " "))
+	}
+}
+
+// GenerateGo returns the source of a Go client package for the spec.
+func GenerateGo(spec *Spec, pkg, source string) ([]byte, error) {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// BasePath is the path the API is served under.\nconst BasePath = %q\n\n", spec.basePath())
+	for _, entry := range spec.Components.Schemas {
+		writeGoSchema(&body, goName(entry.Key), entry.Value)
+	}
+	body.WriteString(goRuntime)
+	for _, op := range spec.operations() {
+		if err := writeGoOperation(&body, spec, op); err != nil {
+			return nil, err
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by toko sdk from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "// Package %s is a client of the %s %s.\npackage %s\n\nimport (\n", pkg, spec.Info.Title, spec.Info.Version, pkg)
+	for _, imp := range goImports {
+		name := imp[strings.LastIndex(imp, "/")+1:]
+		if regexp.MustCompile(`\b` + name + `\.[A-Z]`).Match(body.Bytes()) {
+			fmt.Fprintf(&b, "\t%q\n", imp)
+		}
+	}
+	b.WriteString(")\n\n")
+	b.Write(body.Bytes())
+
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the Go client: %w", err)
+	}
+	return formatted, nil
+}
+
+// writeGoSchema writes the type of a component schema.
+func writeGoSchema(b *bytes.Buffer, name string, s *Schema) {
+	if s.Description != "" {
+		goComment(b, "", name+" is "+lowerFirst(s.Description))
+	} else {
+		fmt.Fprintf(b, "// %s is the %s schema of the API.\n", name, name)
+	}
+	if s.Type != "object" {
+		fmt.Fprintf(b, "type %s %s\n\n", name, goType(s, false))
+		return
+	}
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, prop := range s.Properties {
+		required := s.required(prop.Key)
+		if prop.Value.Description != "" {
+			goComment(b, "\t", prop.Value.Description)
+		}
+		tag := prop.Key
+		if !required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`\n", goName(prop.Key), goType(prop.Value, !required), tag)
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeGoOperation writes the params type and method of an operation.
+func writeGoOperation(b *bytes.Buffer, spec *Spec, op operation) error {
+	name := goName(op.OperationID)
+	pathParams, queryParams, err := spec.parameters(op)
+	if err != nil {
+		return err
+	}
+	response, err := spec.responseSchema(op)
+	if err != nil {
+		return err
+	}
+	body := requestSchema(op)
+
+	if len(queryParams) > 0 {
+		fmt.Fprintf(b, "// %sParams are the query parameters of %s.\n", name, name)
+		fmt.Fprintf(b, "type %sParams struct {\n", name)
+		for _, p := range queryParams {
+			if p.Description != "" {
+				goComment(b, "\t", p.Description)
+			}
+			fmt.Fprintf(b, "\t%s %s\n", goName(p.Name), goType(p.Schema, false))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range pathParams {
+		args = append(args, goParamName(p.Name)+" string")
+	}
+	if body != nil {
+		args = append(args, "body "+goType(body, false))
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+name+"Params")
+	}
+	results := "error"
+	if response != nil {
+		results = "(" + goResultType(response) + ", error)"
+	}
+
+	doc := op.Description
+	if doc == "" {
+		doc = op.Summary
+	}
+	fmt.Fprintf(b, "\n// %s calls %s %s.\n", name, op.Method, op.Path)
+	if doc != "" {
+		b.WriteString("//\n")
+		goComment(b, "", doc)
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), results)
+
+	literals, params := pathSegments(op.Path)
+	parts := []string{fmt.Sprintf("%q", literals[0])}
+	for i, param := range params {
+		parts = append(parts, "url.PathEscape("+goParamName(param)+")")
+		if literals[i+1] != "" {
+			parts = append(parts, fmt.Sprintf("%q", literals[i+1]))
+		}
+	}
+	fmt.Fprintf(b, "\tpath := %s\n", strings.Join(parts, " + "))
+
+	b.WriteString("\tquery := url.Values{}\n")
+	if len(queryParams) > 0 {
+		b.WriteString("\tif params != nil {\n")
+		for _, p := range queryParams {
+			field := "params." + goName(p.Name)
+			switch goType(p.Schema, false) {
+			case "int":
+				fmt.Fprintf(b, "\t\tif %s != 0 {\n\t\t\tquery.Set(%q, strconv.Itoa(%s))\n\t\t}\n", field, p.Name, field)
+			case "bool":
+				fmt.Fprintf(b, "\t\tif %s {\n\t\t\tquery.Set(%q, \"true\")\n\t\t}\n", field, p.Name)
+			default:
+				fmt.Fprintf(b, "\t\tif %s != \"\" {\n\t\t\tquery.Set(%q, %s)\n\t\t}\n", field, p.Name, field)
+			}
+		}
+		b.WriteString("\t}\n")
+	}
+
+	bodyArg := "nil"
+	if body != nil {
+		bodyArg = "body"
+	}
+	if response == nil {
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, path, query, %s, nil)\n}\n", op.Method, bodyArg)
+		return nil
+	}
+	fmt.Fprintf(b, "\tvar out %s\n", goType(response, false))
+	fmt.Fprintf(b, "\tif err := c.do(ctx, %q, path, query, %s, &out); err != nil {\n", op.Method, bodyArg)
+	if strings.HasPrefix(goResultType(response), "*") {
+		b.WriteString("\t\treturn nil, err\n\t}\n\treturn &out, nil\n}\n")
+	} else {
+		b.WriteString("\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n")
+	}
+	return nil
+}
+
+// goResultType is the type an operation returns its response body as:
+// a pointer for objects, the value for arrays.
+func goResultType(s *Schema) string {
+	typ := goType(s, false)
+	if strings.HasPrefix(typ, "[]") || typ == "json.RawMessage" {
+		return typ
+	}
+	return "*" + typ
+}
+
+// lowerFirst lowercases the first letter of a sentence.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// goRuntime is the part of the Go client that does not depend on the spec.
+const goRuntime = `// Client calls the API. Set Token to call it as a logged-in user or guest.
+type Client struct {
+	BaseURL    string       // Scheme and host, e.g. "https://shop.example.com"
+	Token      string       // Sent as a bearer token when set
+	HTTPClient *http.Client // Defaults to http.DefaultClient
+}
+
+// NewClient returns a client of the API served at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// APIError is returned for responses outside the 2xx range.
+type APIError struct {
+	StatusCode int
+	Message    string // The message field of the response, if any
+	Body       []byte
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("api: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("api: status %d: %s", e.StatusCode, e.Message)
+}
+
+// do sends a request and decodes a successful JSON response into out.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := c.BaseURL + BasePath + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		apiErr.Body, _ = io.ReadAll(resp.Body)
+		var message struct {
+			Message string ` + "`json:\"message\"`" + `
+		}
+		if json.Unmarshal(apiErr.Body, &message) == nil {
+			apiErr.Message = message.Message
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+`
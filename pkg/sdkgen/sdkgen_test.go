@@ -0,0 +1,122 @@
+package sdkgen_test
+
+import (
+	"os"
+	"testing"
+
+	"toko/pkg/sdkgen"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info:
+  title: Test API
+  version: 0.1.0
+servers:
+  - url: /api/v1
+paths:
+  /items/{item_id}:
+    get:
+      operationId: getItem
+      parameters:
+        - name: item_id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: page_size
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: The item.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Item"
+    delete:
+      operationId: deleteItem
+      parameters:
+        - name: item_id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: Deleted.
+components:
+  schemas:
+    Item:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+        kind:
+          type: string
+          enum: [book, ebook]
+        created_at:
+          type: string
+          format: date-time
+`
+
+func TestParse_RequiresUniqueOperationIDs(t *testing.T) {
+	_, err := sdkgen.Parse([]byte("paths:\n  /a:\n    get:\n      summary: A\n"))
+	assert.ErrorContains(t, err, "has no operationId")
+
+	_, err = sdkgen.Parse([]byte("paths:\n  /a:\n    get:\n      operationId: a\n  /b:\n    get:\n      operationId: a\n"))
+	assert.ErrorContains(t, err, "used twice")
+}
+
+func TestGenerateGo(t *testing.T) {
+	spec, err := sdkgen.Parse([]byte(testSpec))
+	require.NoError(t, err)
+
+	source, err := sdkgen.GenerateGo(spec, "client", "test.yaml")
+	require.NoError(t, err)
+	code := string(source)
+	assert.Contains(t, code, `const BasePath = "/api/v1"`)
+	assert.Contains(t, code, "ID        string     `json:\"id\"`")
+	assert.Contains(t, code, "CreatedAt *time.Time `json:\"created_at,omitempty\"`")
+	assert.Contains(t, code, "func (c *Client) GetItem(ctx context.Context, itemID string, params *GetItemParams) (*Item, error)")
+	assert.Contains(t, code, `path := "/items/" + url.PathEscape(itemID)`)
+	assert.Contains(t, code, `query.Set("page_size", strconv.Itoa(params.PageSize))`)
+	assert.Contains(t, code, "func (c *Client) DeleteItem(ctx context.Context, itemID string) error")
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	spec, err := sdkgen.Parse([]byte(testSpec))
+	require.NoError(t, err)
+
+	source, err := sdkgen.GenerateTypeScript(spec, "test.yaml")
+	require.NoError(t, err)
+	code := string(source)
+	assert.Contains(t, code, `kind?: "book" | "ebook";`)
+	assert.Contains(t, code, "  id: string;")
+	assert.Contains(t, code, "getItem(itemId: string, params?: GetItemParams): Promise<Item>")
+	assert.Contains(t, code, "`/items/${encodeURIComponent(itemId)}`")
+	assert.Contains(t, code, "deleteItem(itemId: string): Promise<void>")
+}
+
+// The committed clients must match the spec; run "make clients" after changing it.
+func TestCommittedClientsAreUpToDate(t *testing.T) {
+	spec, err := sdkgen.Load("../../api/openapi.yaml")
+	require.NoError(t, err)
+
+	goClient, err := sdkgen.GenerateGo(spec, "toko", "api/openapi.yaml")
+	require.NoError(t, err)
+	committed, err := os.ReadFile("../../clients/go/client.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(goClient), string(committed), "clients/go is out of date; run make clients")
+
+	tsClient, err := sdkgen.GenerateTypeScript(spec, "api/openapi.yaml")
+	require.NoError(t, err)
+	committed, err = os.ReadFile("../../clients/typescript/src/index.ts")
+	require.NoError(t, err)
+	assert.Equal(t, string(tsClient), string(committed), "clients/typescript is out of date; run make clients")
+}
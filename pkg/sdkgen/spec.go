@@ -0,0 +1,275 @@
+// Package sdkgen generates typed Go and TypeScript API clients from an
+// OpenAPI 3 spec. It covers the parts of OpenAPI the Toko spec uses: JSON
+// request and response bodies, path and query parameters, object, array and
+// primitive schemas, and $ref to components.
+package sdkgen
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is an OpenAPI document.
+type Spec struct {
+	Info       Info              `yaml:"info"`
+	Servers    []Server          `yaml:"servers"`
+	Paths      Ordered[PathItem] `yaml:"paths"`
+	Components Components        `yaml:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+// Server is a base URL of the API.
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+// Components holds the definitions operations refer to.
+type Components struct {
+	Schemas    Ordered[*Schema]     `yaml:"schemas"`
+	Parameters map[string]Parameter `yaml:"parameters"`
+	Responses  map[string]Response  `yaml:"responses"`
+}
+
+// PathItem maps HTTP methods to the operations of a path.
+type PathItem struct {
+	Get    *Operation `yaml:"get"`
+	Put    *Operation `yaml:"put"`
+	Post   *Operation `yaml:"post"`
+	Patch  *Operation `yaml:"patch"`
+	Delete *Operation `yaml:"delete"`
+}
+
+// Operation is an API call.
+type Operation struct {
+	OperationID string            `yaml:"operationId"`
+	Summary     string            `yaml:"summary"`
+	Description string            `yaml:"description"`
+	Parameters  []Parameter       `yaml:"parameters"`
+	RequestBody *RequestBody      `yaml:"requestBody"`
+	Responses   Ordered[Response] `yaml:"responses"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+// RequestBody is the body of an operation's request.
+type RequestBody struct {
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+// Response is a possible response of an operation.
+type Response struct {
+	Ref         string               `yaml:"$ref"`
+	Description string               `yaml:"description"`
+	Content     map[string]MediaType `yaml:"content"`
+}
+
+// MediaType holds the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+// Schema describes a JSON value.
+type Schema struct {
+	Ref         string           `yaml:"$ref"`
+	Type        string           `yaml:"type"`
+	Format      string           `yaml:"format"`
+	Description string           `yaml:"description"`
+	Enum        []string         `yaml:"enum"`
+	Required    []string         `yaml:"required"`
+	Properties  Ordered[*Schema] `yaml:"properties"`
+	Items       *Schema          `yaml:"items"`
+}
+
+// Entry is a key and value of an Ordered map.
+type Entry[T any] struct {
+	Key   string
+	Value T
+}
+
+// Ordered is a YAML mapping that keeps the order of its keys, so the
+// generated code follows the order of the spec.
+type Ordered[T any] []Entry[T]
+
+// UnmarshalYAML decodes a mapping in document order.
+func (o *Ordered[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		*o = append(*o, Entry[T]{Key: node.Content[i].Value, Value: value})
+	}
+	return nil
+}
+
+// Load reads and checks an OpenAPI spec.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes and checks an OpenAPI spec.
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, op := range spec.operations() {
+		if op.OperationID == "" {
+			return nil, fmt.Errorf("invalid spec: %s %s has no operationId", op.Method, op.Path)
+		}
+		if seen[op.OperationID] {
+			return nil, fmt.Errorf("invalid spec: operationId %s is used twice", op.OperationID)
+		}
+		seen[op.OperationID] = true
+	}
+	return &spec, nil
+}
+
+// basePath is the path of the first server, which the operation paths are relative to.
+func (s *Spec) basePath() string {
+	if len(s.Servers) == 0 {
+		return ""
+	}
+	return strings.TrimRight(s.Servers[0].URL, "/")
+}
+
+// operation is an Operation with the method and path it is called with.
+type operation struct {
+	*Operation
+	Method string
+	Path   string
+}
+
+// operations lists the operations in the order of the spec.
+func (s *Spec) operations() []operation {
+	var ops []operation
+	for _, path := range s.Paths {
+		for _, method := range []struct {
+			name string
+			op   *Operation
+		}{
+			{"GET", path.Value.Get},
+			{"PUT", path.Value.Put},
+			{"POST", path.Value.Post},
+			{"PATCH", path.Value.Patch},
+			{"DELETE", path.Value.Delete},
+		} {
+			if method.op != nil {
+				ops = append(ops, operation{Operation: method.op, Method: method.name, Path: path.Key})
+			}
+		}
+	}
+	return ops
+}
+
+// parameter resolves a parameter $ref.
+func (s *Spec) parameter(p Parameter) (Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name := strings.TrimPrefix(p.Ref, "#/components/parameters/")
+	resolved, ok := s.Components.Parameters[name]
+	if !ok {
+		return Parameter{}, fmt.Errorf("invalid spec: unknown parameter %s", p.Ref)
+	}
+	return resolved, nil
+}
+
+// parameters returns the resolved path and query parameters of an operation.
+func (s *Spec) parameters(op operation) (path, query []Parameter, err error) {
+	for _, p := range op.Parameters {
+		if p, err = s.parameter(p); err != nil {
+			return nil, nil, err
+		}
+		switch p.In {
+		case "path":
+			path = append(path, p)
+		case "query":
+			query = append(query, p)
+		}
+	}
+	return path, query, nil
+}
+
+// requestSchema returns the schema of an operation's JSON request body, if any.
+func requestSchema(op operation) *Schema {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Content["application/json"].Schema
+}
+
+// responseSchema returns the schema of an operation's first successful
+// JSON response, or nil when it has no body.
+func (s *Spec) responseSchema(op operation) (*Schema, error) {
+	for _, entry := range op.Responses {
+		if !strings.HasPrefix(entry.Key, "2") {
+			continue
+		}
+		response := entry.Value
+		if response.Ref != "" {
+			name := strings.TrimPrefix(response.Ref, "#/components/responses/")
+			resolved, ok := s.Components.Responses[name]
+			if !ok {
+				return nil, fmt.Errorf("invalid spec: unknown response %s", response.Ref)
+			}
+			response = resolved
+		}
+		return response.Content["application/json"].Schema, nil
+	}
+	return nil, nil
+}
+
+// refName returns the component name a schema $ref points to.
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// pathSegments splits an operation path into literal text and {param} names.
+func pathSegments(path string) (literals, params []string) {
+	for {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			return append(literals, path), params
+		}
+		literals = append(literals, path[:start])
+		params = append(params, path[start+1:end])
+		path = path[end+1:]
+	}
+}
+
+// required reports whether name is one of the schema's required properties.
+func (s *Schema) required(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package sdkgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// tsType returns the TypeScript type of a schema.
+func tsType(s *Schema) string {
+	if s == nil {
+		return "unknown"
+	}
+	switch {
+	case s.Ref != "":
+		return refName(s.Ref)
+	case s.Type == "array":
+		item := tsType(s.Items)
+		if strings.Contains(item, " | ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case s.Type == "integer" || s.Type == "number":
+		return "number"
+	case s.Type == "boolean":
+		return "boolean"
+	case s.Type == "string" && len(s.Enum) > 0:
+		values := make([]string, len(s.Enum))
+		for i, value := range s.Enum {
+			values[i] = fmt.Sprintf("%q", value)
+		}
+		return strings.Join(values, " | ")
+	case s.Type == "string":
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// tsComment writes text as a JSDoc comment with the given indentation.
+func tsComment(b *bytes.Buffer, indent, text string) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, strings.TrimSpace(lines[0]))
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s\n", strings.TrimRight(indent+" * "+strings.TrimSpace(line), " "))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// tsParamName turns a parameter name into a camelCase TypeScript identifier.
+func tsParamName(name string) string {
+	return strings.ReplaceAll(goParamName(name), "ID", "Id")
+}
+
+// GenerateTypeScript returns the source of a TypeScript client module for the spec.
+func GenerateTypeScript(spec *Spec, source string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by toko sdk from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "/** The path the API is served under. */\nexport const BASE_PATH = %q;\n\n", spec.basePath())
+
+	for _, entry := range spec.Components.Schemas {
+		writeTSSchema(&b, entry.Key, entry.Value)
+	}
+
+	b.WriteString(tsRuntime)
+
+	var methods bytes.Buffer
+	for _, op := range spec.operations() {
+		if err := writeTSOperation(&b, &methods, spec, op); err != nil {
+			return nil, err
+		}
+	}
+
+	b.WriteString(tsClientHead)
+	b.Write(methods.Bytes())
+	b.WriteString(tsClientTail)
+	return b.Bytes(), nil
+}
+
+// writeTSSchema writes the type of a component schema.
+func writeTSSchema(b *bytes.Buffer, name string, s *Schema) {
+	if s.Description != "" {
+		tsComment(b, "", s.Description)
+	}
+	if s.Type != "object" {
+		fmt.Fprintf(b, "export type %s = %s;\n\n", name, tsType(s))
+		return
+	}
+	fmt.Fprintf(b, "export interface %s {\n", name)
+	for _, prop := range s.Properties {
+		if prop.Value.Description != "" {
+			tsComment(b, "  ", prop.Value.Description)
+		}
+		optional := "?"
+		if s.required(prop.Key) {
+			optional = ""
+		}
+		fmt.Fprintf(b, "  %s%s: %s;\n", prop.Key, optional, tsType(prop.Value))
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeTSOperation writes the params type of an operation to b and its
+// client method to methods.
+func writeTSOperation(b, methods *bytes.Buffer, spec *Spec, op operation) error {
+	pathParams, queryParams, err := spec.parameters(op)
+	if err != nil {
+		return err
+	}
+	response, err := spec.responseSchema(op)
+	if err != nil {
+		return err
+	}
+	body := requestSchema(op)
+	paramsType := goName(op.OperationID) + "Params"
+
+	if len(queryParams) > 0 {
+		fmt.Fprintf(b, "/** Query parameters of %s. */\n", op.OperationID)
+		fmt.Fprintf(b, "export interface %s {\n", paramsType)
+		for _, p := range queryParams {
+			if p.Description != "" {
+				tsComment(b, "  ", p.Description)
+			}
+			fmt.Fprintf(b, "  %s?: %s;\n", p.Name, tsType(p.Schema))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	var args []string
+	for _, p := range pathParams {
+		args = append(args, tsParamName(p.Name)+": string")
+	}
+	if body != nil {
+		args = append(args, "body: "+tsType(body))
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "params?: "+paramsType)
+	}
+	result := "void"
+	if response != nil {
+		result = tsType(response)
+	}
+
+	doc := fmt.Sprintf("%s %s", op.Method, op.Path)
+	if op.Description != "" {
+		doc += "\n\n" + strings.TrimSpace(op.Description)
+	} else if op.Summary != "" {
+		doc += "\n\n" + strings.TrimSpace(op.Summary)
+	}
+	methods.WriteString("\n")
+	tsComment(methods, "  ", doc)
+	fmt.Fprintf(methods, "  %s(%s): Promise<%s> {\n", op.OperationID, strings.Join(args, ", "), result)
+
+	literals, params := pathSegments(op.Path)
+	path := literals[0]
+	for i, param := range params {
+		path += "${encodeURIComponent(" + tsParamName(param) + ")}" + literals[i+1]
+	}
+	query, bodyArg := "undefined", "undefined"
+	if len(queryParams) > 0 {
+		query = "params"
+	}
+	if body != nil {
+		bodyArg = "body"
+	}
+	fmt.Fprintf(methods, "    return this.request<%s>(%q, `%s`, %s, %s);\n  }\n", result, op.Method, path, query, bodyArg)
+	return nil
+}
+
+// tsRuntime is the part of the TypeScript client that does not depend on the spec.
+const tsRuntime = `/** Thrown for responses outside the 2xx range. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(
+      typeof body === "object" && body !== null && "message" in body
+        ? ` + "`api: status ${status}: ${String((body as { message: unknown }).message)}`" + `
+        : ` + "`api: status ${status}`" + `,
+    );
+    this.name = "ApiError";
+  }
+}
+
+/** Options of a TokoClient. */
+export interface ClientOptions {
+  /** Sent as a bearer token when set. */
+  token?: string;
+  /** Defaults to the global fetch. */
+  fetch?: typeof fetch;
+}
+
+`
+
+const tsClientHead = `/** Calls the API. Set token to call it as a logged-in user or guest. */
+export class TokoClient {
+  token?: string;
+  private readonly baseUrl: string;
+  private readonly fetchImpl: typeof fetch;
+
+  /** baseUrl is the scheme and host, e.g. "https://shop.example.com". */
+  constructor(baseUrl: string, options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.token = options.token;
+    this.fetchImpl = options.fetch ?? fetch.bind(globalThis);
+  }
+`
+
+const tsClientTail = `
+  private async request<T>(method: string, path: string, query?: object, body?: unknown): Promise<T> {
+    let url = this.baseUrl + BASE_PATH + path;
+    if (query) {
+      const search = new URLSearchParams();
+      for (const [key, value] of Object.entries(query)) {
+        if (value !== undefined && value !== null && value !== "") {
+          search.set(key, String(value));
+        }
+      }
+      const encoded = search.toString();
+      if (encoded) {
+        url += "?" + encoded;
+      }
+    }
+
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    if (this.token) {
+      headers["Authorization"] = "Bearer " + this.token;
+    }
+    const response = await this.fetchImpl(url, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+
+    const text = await response.text();
+    let data: unknown = undefined;
+    if (text) {
+      try {
+        data = JSON.parse(text);
+      } catch {
+        data = text;
+      }
+    }
+    if (!response.ok) {
+      throw new ApiError(response.status, data);
+    }
+    return data as T;
+  }
+}
+`
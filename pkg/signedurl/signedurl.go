@@ -0,0 +1,80 @@
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer creates and verifies expiring HMAC-signed URLs.
+type Signer struct {
+	secret []byte
+}
+
+// New creates a Signer using the given secret.
+func New(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign returns path with "expires" and "signature" query parameters appended.
+func (s *Signer) Sign(path string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.signature(path, expires))
+	return path + "?" + query.Encode()
+}
+
+// Verify checks that signature matches path and expires, and that the link has not expired.
+func (s *Signer) Verify(path, expires, signature string) error {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid link expiry")
+	}
+	expected := s.signature(path, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid link signature")
+	}
+	if time.Now().Unix() > expiresUnix {
+		return fmt.Errorf("link has expired")
+	}
+	return nil
+}
+
+// SignToken returns a URL-safe token carrying payload until expiresAt. The
+// payload is signed, not encrypted, so anyone holding the token can read it.
+func (s *Signer) SignToken(payload []byte, expiresAt time.Time) string {
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return encoded + "." + expires + "." + s.signature(encoded, expires)
+}
+
+// VerifyToken checks a token created by SignToken and returns its payload.
+func (s *Signer) VerifyToken(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token format")
+	}
+	if err := s.Verify(parts[0], parts[1], parts[2]); err != nil {
+		return nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token payload")
+	}
+	return payload, nil
+}
+
+func (s *Signer) signature(path, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,68 @@
+package signedurl_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"toko/pkg/signedurl"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	signer := signedurl.New("test_secret")
+	path := "/api/v1/downloads/order-1/product-1"
+
+	link := signer.Sign(path, time.Now().Add(time.Hour))
+	assert.True(t, strings.HasPrefix(link, path+"?"))
+
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	expires := parsed.Query().Get("expires")
+	signature := parsed.Query().Get("signature")
+
+	// Valid link
+	assert.NoError(t, signer.Verify(path, expires, signature))
+
+	// Tampered path
+	assert.Error(t, signer.Verify("/api/v1/downloads/order-2/product-1", expires, signature))
+
+	// Different secret
+	assert.Error(t, signedurl.New("other_secret").Verify(path, expires, signature))
+
+	// Expired link
+	expired, _ := url.Parse(signer.Sign(path, time.Now().Add(-time.Minute)))
+	err = signer.Verify(path, expired.Query().Get("expires"), expired.Query().Get("signature"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expired")
+}
+
+func TestSigner_SignAndVerifyToken(t *testing.T) {
+	signer := signedurl.New("test_secret")
+	payload := []byte(`{"items":[{"product_id":"p1","quantity":2}]}`)
+
+	token := signer.SignToken(payload, time.Now().Add(time.Hour))
+	assert.Equal(t, url.PathEscape(token), token, "tokens are URL-safe")
+	read, err := signer.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, read)
+
+	// Tampered payload
+	parts := strings.SplitN(token, ".", 2)
+	_, err = signer.VerifyToken(parts[0] + "x." + parts[1])
+	assert.Error(t, err)
+
+	// Different secret
+	_, err = signedurl.New("other_secret").VerifyToken(token)
+	assert.Error(t, err)
+
+	// Malformed token
+	_, err = signer.VerifyToken("not-a-token")
+	assert.ErrorContains(t, err, "invalid token")
+
+	// Expired token
+	_, err = signer.VerifyToken(signer.SignToken(payload, time.Now().Add(-time.Minute)))
+	assert.ErrorContains(t, err, "expired")
+}
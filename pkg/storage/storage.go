@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage abstracts where uploaded files are kept.
+type Storage interface {
+	// Put stores the content of r under key, replacing any existing object.
+	Put(key string, r io.Reader) error
+	// Open returns a reader for the object stored under key.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key.
+	Delete(key string) error
+}
+
+// LocalStorage stores objects as files below a base directory.
+type LocalStorage struct {
+	baseDir string
+}
+
+// NewLocalStorage creates a LocalStorage rooted at baseDir, creating it if needed.
+func NewLocalStorage(baseDir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory %s: %w", baseDir, err)
+	}
+	return &LocalStorage{baseDir: baseDir}, nil
+}
+
+// path resolves key to a file path, rejecting keys that escape the base directory.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.baseDir, cleaned), nil
+}
+
+// Put writes r to the file for key.
+func (s *LocalStorage) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create object %s: %w", key, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Open opens the file for key.
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("object %s not found", key)
+		}
+		return nil, fmt.Errorf("failed to open object %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete removes the file for key.
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	return nil
+}
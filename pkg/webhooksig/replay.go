@@ -0,0 +1,64 @@
+package webhooksig
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryReplayCache is an in-process ReplayCache. It is only suitable when a
+// single API instance receives webhooks.
+type MemoryReplayCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewMemoryReplayCache creates a new MemoryReplayCache.
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Remember records key until ttl elapses and reports whether it was already present.
+func (c *MemoryReplayCache) Remember(key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, expiresAt := range c.entries {
+		if now.After(expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	if _, ok := c.entries[key]; ok {
+		return true, nil
+	}
+	c.entries[key] = now.Add(ttl)
+	return false, nil
+}
+
+// RedisReplayCache is a ReplayCache shared by all instances using the same Redis.
+type RedisReplayCache struct {
+	client *redis.Client
+}
+
+// NewRedisReplayCache creates a new RedisReplayCache.
+func NewRedisReplayCache(client *redis.Client) *RedisReplayCache {
+	return &RedisReplayCache{client: client}
+}
+
+// Remember records key until ttl elapses and reports whether it was already present.
+func (c *RedisReplayCache) Remember(key string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stored, err := c.client.SetNX(ctx, "toko:webhook_replay:"+key, 1, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return !stored, nil
+}
@@ -0,0 +1,48 @@
+package webhooksig_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"toko/pkg/webhooksig"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	secret := "test_webhook_secret"
+	verifier := webhooksig.NewVerifier("payments", secret, 5*time.Minute, webhooksig.NewMemoryReplayCache())
+	body := []byte(`{"event":"payment.succeeded","order_id":"order-1"}`)
+
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := webhooksig.Sign(secret, now, body)
+
+	// Valid request
+	assert.NoError(t, verifier.Verify(timestamp, signature, body))
+
+	// Same request again is a replay
+	err := verifier.Verify(timestamp, signature, body)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "replay")
+
+	// Tampered body
+	err = verifier.Verify(timestamp, signature, []byte(`{"event":"payment.succeeded","order_id":"order-2"}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook signature")
+
+	// Timestamp outside tolerance
+	old := time.Now().Add(-10 * time.Minute).Unix()
+	err = verifier.Verify(strconv.FormatInt(old, 10), webhooksig.Sign(secret, old, body), body)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tolerance")
+
+	// Missing headers
+	err = verifier.Verify("", "", body)
+	assert.Error(t, err)
+
+	// Unconfigured secret rejects everything
+	unconfigured := webhooksig.NewVerifier("partners", "", 5*time.Minute, nil)
+	assert.Error(t, unconfigured.Verify(timestamp, webhooksig.Sign("", now, body), body))
+}